@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/rand"
+	"net/http"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -32,6 +33,7 @@ import (
 	cmapi "github.com/cubefs/cubefs/blobstore/api/clustermgr"
 	"github.com/cubefs/cubefs/blobstore/api/proxy"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/rpc"
 	"github.com/cubefs/cubefs/blobstore/common/trace"
 	"github.com/cubefs/cubefs/blobstore/util/defaulter"
 	"github.com/cubefs/cubefs/blobstore/util/errors"
@@ -107,8 +109,21 @@ var (
 	ErrNoSuchCluster      = errors.New("controller: no such cluster")
 	ErrNoClusterAvailable = errors.New("controller: no cluster available")
 	ErrInvalidChooseAlg   = errors.New("controller: invalid cluster chosen algorithm")
+	ErrNoMigratedVolume   = errors.New("controller: no migrated volume")
+	ErrNoCompactedVolume  = errors.New("controller: no compacted volume")
 )
 
+// KVClient kv operations of cluster manager
+type KVClient interface {
+	GetKV(ctx context.Context, key string) (cmapi.GetKvRet, error)
+	SetKV(ctx context.Context, key string, value []byte) error
+	DeleteKV(ctx context.Context, key string) error
+	// RefKV increases reference count of key once by holder, the value is set by the first reference
+	RefKV(ctx context.Context, key, holder string, value []byte) (cmapi.RefKvRet, error)
+	// UnrefKV decreases reference count of key once by holder, the key is deleted when it reaches zero
+	UnrefKV(ctx context.Context, key, holder string) (cmapi.RefKvRet, error)
+}
+
 // ClusterController controller of clusters in one region
 type ClusterController interface {
 	// Region returns region in configuration
@@ -123,6 +138,16 @@ type ClusterController interface {
 	GetVolumeGetter(clusterID proto.ClusterID) (VolumeGetter, error)
 	// GetConfig get specified config of key from cluster manager
 	GetConfig(ctx context.Context, key string) (string, error)
+	// GetConvertedVolume returns converted volume of vid in specified cluster
+	GetConvertedVolume(ctx context.Context, clusterID proto.ClusterID, vid proto.Vid) (*proto.ConvertedVolume, error)
+	// GetConvertedTargetVolume returns converted volume which target volume is vid in specified cluster
+	GetConvertedTargetVolume(ctx context.Context, clusterID proto.ClusterID, vid proto.Vid) (*proto.ConvertedVolume, error)
+	// GetMigratedVolume returns migrated volume of vid in retired cluster from the other clusters
+	GetMigratedVolume(ctx context.Context, clusterID proto.ClusterID, vid proto.Vid) (*proto.MigratedVolume, error)
+	// GetCompactedVolume returns compacted volume of vid in specified cluster
+	GetCompactedVolume(ctx context.Context, clusterID proto.ClusterID, vid proto.Vid) (*proto.CompactedVolume, error)
+	// GetKVClient return KVClient in specified cluster
+	GetKVClient(clusterID proto.ClusterID) (KVClient, error)
 	// ChangeChooseAlg change alloc algorithm
 	ChangeChooseAlg(alg AlgChoose) error
 }
@@ -141,6 +166,8 @@ type ClusterConfig struct {
 
 	ServicePunishThreshold      uint32 `json:"service_punish_threshold"`
 	ServicePunishValidIntervalS int    `json:"service_punish_valid_interval_s"`
+	// not compacted volume is cached in seconds, the compacted never changes
+	CompactedVolumeExpirationS int `json:"compacted_volume_expiration_s"`
 
 	ConsulAgentAddr string    `json:"consul_agent_addr"`
 	ConsulToken     string    `json:"consul_token"`
@@ -172,7 +199,10 @@ type clusterControllerImpl struct {
 	available       atomic.Value // available clusters
 	serviceMgrs     sync.Map
 	volumeGetters   sync.Map
-	roundRobinCount uint64 // a count for round robin
+	migratedVolumes sync.Map // cache of migrated volumes, they are never changed
+	convertVolumes  sync.Map // cache of converted volumes which conversion is finished
+	compactVolumes  sync.Map // cache of compacted or not compacted volumes
+	roundRobinCount uint64   // a count for round robin
 	proxy           proxy.Cacher
 	stopCh          <-chan struct{}
 
@@ -182,6 +212,7 @@ type clusterControllerImpl struct {
 // NewClusterController returns a cluster controller
 func NewClusterController(cfg *ClusterConfig, proxy proxy.Cacher, stopCh <-chan struct{}) (ClusterController, error) {
 	defaulter.LessOrEqual(&cfg.ClusterReloadSecs, int(3))
+	defaulter.LessOrEqual(&cfg.CompactedVolumeExpirationS, int(60))
 
 	consulConf := api.DefaultConfig()
 	consulConf.Address = cfg.ConsulAgentAddr
@@ -502,3 +533,135 @@ func (c *clusterControllerImpl) GetConfig(ctx context.Context, key string) (ret
 	}
 	return
 }
+
+func (c *clusterControllerImpl) GetConvertedVolume(ctx context.Context, clusterID proto.ClusterID,
+	vid proto.Vid,
+) (*proto.ConvertedVolume, error) {
+	return c.getConvertedVolume(ctx, clusterID, proto.ConvertedVolumeKey(vid))
+}
+
+func (c *clusterControllerImpl) GetConvertedTargetVolume(ctx context.Context, clusterID proto.ClusterID,
+	vid proto.Vid,
+) (*proto.ConvertedVolume, error) {
+	return c.getConvertedVolume(ctx, clusterID, proto.ConvertedTargetVolumeKey(vid))
+}
+
+// getConvertedVolume caches the finished records only, the converting record
+// is changed when the conversion is finished.
+func (c *clusterControllerImpl) getConvertedVolume(ctx context.Context, clusterID proto.ClusterID,
+	key string,
+) (*proto.ConvertedVolume, error) {
+	cacheKey := fmt.Sprintf("%d-%s", clusterID, key)
+	if val, ok := c.convertVolumes.Load(cacheKey); ok {
+		return val.(*proto.ConvertedVolume), nil
+	}
+
+	allClusters := c.clusters.Load().(clusterMap)
+	cluster, ok := allClusters[clusterID]
+	if !ok {
+		return nil, ErrNoSuchCluster
+	}
+
+	val, err := cluster.client.GetKV(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	converted := new(proto.ConvertedVolume)
+	if err = json.Unmarshal(val.Value, converted); err != nil {
+		return nil, err
+	}
+	if !converted.Converting {
+		c.convertVolumes.Store(cacheKey, converted)
+	}
+	return converted, nil
+}
+
+func (c *clusterControllerImpl) GetMigratedVolume(ctx context.Context, clusterID proto.ClusterID,
+	vid proto.Vid,
+) (*proto.MigratedVolume, error) {
+	span := trace.SpanFromContextSafe(ctx)
+
+	key := proto.MigratedVolumeKey(clusterID, vid)
+	if val, ok := c.migratedVolumes.Load(key); ok {
+		return val.(*proto.MigratedVolume), nil
+	}
+
+	allClusters := c.clusters.Load().(clusterMap)
+	for id, cluster := range allClusters {
+		if id == clusterID {
+			continue
+		}
+		val, err := cluster.client.GetKV(ctx, key)
+		if err != nil {
+			if rpc.DetectStatusCode(err) == http.StatusNotFound {
+				continue
+			}
+			span.Warnf("get migrated volume[%s] from cluster[%d] failed, err: %v", key, id, err)
+			return nil, err
+		}
+		migrated := new(proto.MigratedVolume)
+		if err = json.Unmarshal(val.Value, migrated); err != nil {
+			return nil, err
+		}
+		c.migratedVolumes.Store(key, migrated)
+		return migrated, nil
+	}
+	return nil, ErrNoMigratedVolume
+}
+
+type compactedCache struct {
+	volume *proto.CompactedVolume
+	expire time.Time
+}
+
+func (c *clusterControllerImpl) GetCompactedVolume(ctx context.Context, clusterID proto.ClusterID,
+	vid proto.Vid,
+) (*proto.CompactedVolume, error) {
+	key := addCVid(clusterID, vid)
+	if val, ok := c.compactVolumes.Load(key); ok {
+		cached := val.(compactedCache)
+		if cached.volume != nil {
+			return cached.volume, nil
+		}
+		if time.Now().Before(cached.expire) {
+			return nil, ErrNoCompactedVolume
+		}
+	}
+
+	allClusters := c.clusters.Load().(clusterMap)
+	cluster, ok := allClusters[clusterID]
+	if !ok {
+		return nil, ErrNoSuchCluster
+	}
+
+	var compacted *proto.CompactedVolume
+	val, err := cluster.client.GetKV(ctx, proto.CompactedVolumeKey(vid))
+	if err != nil {
+		if rpc.DetectStatusCode(err) != http.StatusNotFound {
+			return nil, err
+		}
+	} else {
+		compacted = new(proto.CompactedVolume)
+		if err = json.Unmarshal(val.Value, compacted); err != nil {
+			return nil, err
+		}
+	}
+
+	c.compactVolumes.Store(key, compactedCache{
+		volume: compacted,
+		expire: time.Now().Add(time.Duration(c.config.CompactedVolumeExpirationS) * time.Second),
+	})
+	if compacted == nil {
+		return nil, ErrNoCompactedVolume
+	}
+	return compacted, nil
+}
+
+func (c *clusterControllerImpl) GetKVClient(clusterID proto.ClusterID) (KVClient, error) {
+	allClusters := c.clusters.Load().(clusterMap)
+	cluster, ok := allClusters[clusterID]
+	if !ok {
+		return nil, ErrNoSuchCluster
+	}
+	return cluster.client, nil
+}
@@ -59,6 +59,7 @@ func initCluster() {
 	mux.HandleFunc("/", consul)
 	mux.HandleFunc("/service/get", serviceGet)
 	mux.HandleFunc("/stat", stat)
+	mux.HandleFunc("/kv/get/", kvGet)
 
 	testServer := httptest.NewServer(mux)
 	hostAddr = testServer.URL
@@ -155,6 +156,23 @@ func stat(w http.ResponseWriter, req *http.Request) {
 	w.Write(bytes)
 }
 
+func kvGet(w http.ResponseWriter, req *http.Request) {
+	switch req.URL.Path {
+	case "/kv/get/" + proto.MigratedVolumeKey(2, 100):
+		val, _ := json.Marshal(proto.MigratedVolume{SourceClusterID: 2, SourceVid: 100, TargetClusterID: 9, TargetVid: 200})
+		data, _ := json.Marshal(clustermgr.GetKvRet{Value: val})
+		w.Write(data)
+	case "/kv/get/" + proto.CompactedVolumeKey(300):
+		val, _ := json.Marshal(proto.CompactedVolume{SourceVid: 300, TargetVid: 301})
+		data, _ := json.Marshal(clustermgr.GetKvRet{Value: val})
+		w.Write(data)
+	case "/kv/get/" + proto.MigratedVolumeKey(2, 500), "/kv/get/" + proto.CompactedVolumeKey(500):
+		w.WriteHeader(http.StatusInternalServerError)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
 func initCC() {
 	defer func() {
 		var data []byte
@@ -314,6 +332,10 @@ func TestAccessClusterGetHandler(t *testing.T) {
 		require.Error(t, err)
 		require.Equal(t, nil, getter)
 
+		kvClient, err := cc2.GetKVClient(1)
+		require.ErrorIs(t, err, controller.ErrNoSuchCluster)
+		require.Nil(t, kvClient)
+
 		_, err = cc2.GetConfig(context.TODO(), "key")
 		require.Error(t, err)
 	}
@@ -326,6 +348,10 @@ func TestAccessClusterGetHandler(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, getter)
 
+		kvClient, err := cc1.GetKVClient(1)
+		require.NoError(t, err)
+		require.NotNil(t, kvClient)
+
 		_, err = cc1.GetConfig(context.TODO(), "key")
 		require.Error(t, err)
 	}
@@ -368,3 +394,48 @@ func TestAccessClusterChooseBalance(t *testing.T) {
 		t.Logf("balance with algorithm %s: %+v", alg, m)
 	}
 }
+
+func TestAccessClusterGetMigratedVolume(t *testing.T) {
+	ctx := context.TODO()
+
+	_, err := cc1.GetMigratedVolume(ctx, 1, 100)
+	require.ErrorIs(t, err, controller.ErrNoMigratedVolume)
+
+	migrated, err := cc19.GetMigratedVolume(ctx, 2, 100)
+	require.NoError(t, err)
+	require.Equal(t, proto.ClusterID(9), migrated.TargetClusterID)
+	require.Equal(t, proto.Vid(200), migrated.TargetVid)
+	// cached
+	migrated, err = cc19.GetMigratedVolume(ctx, 2, 100)
+	require.NoError(t, err)
+	require.Equal(t, proto.Vid(200), migrated.TargetVid)
+
+	_, err = cc19.GetMigratedVolume(ctx, 2, 101)
+	require.ErrorIs(t, err, controller.ErrNoMigratedVolume)
+	_, err = cc19.GetMigratedVolume(ctx, 2, 500)
+	require.Error(t, err)
+	require.NotErrorIs(t, err, controller.ErrNoMigratedVolume)
+}
+
+func TestAccessClusterGetCompactedVolume(t *testing.T) {
+	ctx := context.TODO()
+
+	_, err := cc1.GetCompactedVolume(ctx, 7, 300)
+	require.ErrorIs(t, err, controller.ErrNoSuchCluster)
+
+	compacted, err := cc1.GetCompactedVolume(ctx, 1, 300)
+	require.NoError(t, err)
+	require.Equal(t, proto.Vid(301), compacted.TargetVid)
+	// cached
+	compacted, err = cc1.GetCompactedVolume(ctx, 1, 300)
+	require.NoError(t, err)
+	require.Equal(t, proto.Vid(301), compacted.TargetVid)
+
+	for range [2]struct{}{} {
+		_, err = cc1.GetCompactedVolume(ctx, 1, 301)
+		require.ErrorIs(t, err, controller.ErrNoCompactedVolume)
+	}
+	_, err = cc1.GetCompactedVolume(ctx, 1, 500)
+	require.Error(t, err)
+	require.NotErrorIs(t, err, controller.ErrNoCompactedVolume)
+}
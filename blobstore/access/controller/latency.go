@@ -0,0 +1,97 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package controller
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// weight of the newest sample in ewma latency
+	latencyEWMAAlpha = 0.2
+	// samples of the sliding window to calculate p99 latency
+	latencyWindowSize = 128
+	// recalculate p99 latency every n samples
+	latencyQuantileEvery = 16
+	// latency is unknown if no sample in the interval, the disk will be explored again
+	latencyValidInterval = 30 * time.Second
+	// a failed or timed out reading is recorded as a sample of at least the penalty
+	latencyFailurePenalty = time.Second
+)
+
+// Latency latency statistics of a disk, zero value means unknown
+type Latency struct {
+	EWMA time.Duration
+	P99  time.Duration
+}
+
+type latencyStat struct {
+	mu sync.Mutex
+
+	ewma    float64
+	p99     time.Duration
+	window  [latencyWindowSize]time.Duration
+	count   int
+	updated time.Time
+}
+
+func (l *latencyStat) add(latency time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.count == 0 || time.Since(l.updated) > latencyValidInterval {
+		l.ewma = float64(latency)
+		l.p99 = latency
+		l.count = 0
+	} else {
+		l.ewma = latencyEWMAAlpha*float64(latency) + (1-latencyEWMAAlpha)*l.ewma
+	}
+	l.window[l.count%latencyWindowSize] = latency
+	l.count++
+	l.updated = time.Now()
+
+	if l.count%latencyQuantileEvery == 0 {
+		n := l.count
+		if n > latencyWindowSize {
+			n = latencyWindowSize
+		}
+		samples := make([]time.Duration, n)
+		copy(samples, l.window[:n])
+		sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+		l.p99 = samples[(n*99-1)/100]
+	} else if latency > l.p99 && l.count < latencyQuantileEvery {
+		l.p99 = latency
+	}
+}
+
+// addFailure records the failed reading, the disk is slowed down by the penalty
+// even though it failed fast.
+func (l *latencyStat) addFailure(elapsed time.Duration) {
+	if elapsed < latencyFailurePenalty {
+		elapsed = latencyFailurePenalty
+	}
+	l.add(elapsed)
+}
+
+func (l *latencyStat) get() Latency {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.count == 0 || time.Since(l.updated) > latencyValidInterval {
+		return Latency{}
+	}
+	return Latency{EWMA: time.Duration(l.ewma), P99: l.p99}
+}
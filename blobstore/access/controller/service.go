@@ -48,6 +48,7 @@ type HostIDC struct {
 	Host     string
 	IDC      string
 	Punished bool
+	Latency  Latency
 }
 
 // ServiceController support for both data node discovery and normal service discovery
@@ -68,6 +69,10 @@ type ServiceController interface {
 	// PunishDiskWithThreshold will punish a disk host for
 	// an punishTimeSec interval if disk host failed times satisfied with threshold
 	PunishDiskWithThreshold(ctx context.Context, diskID proto.DiskID, punishTimeSec int)
+	// ReportDiskLatency records a latency sample of reading on the disk
+	ReportDiskLatency(ctx context.Context, diskID proto.DiskID, latency time.Duration)
+	// ReportDiskFailure records a failed or timed out reading on the disk
+	ReportDiskFailure(ctx context.Context, diskID proto.DiskID, elapsed time.Duration)
 }
 
 type (
@@ -86,6 +91,8 @@ type hostItem struct {
 	lastModifyTime int64
 	// failedTimes record the service host failed times during some interval
 	failedTimes uint32
+	// latency of reading on disk host
+	latency latencyStat
 }
 
 func (h *hostItem) isPunish() bool {
@@ -338,6 +345,7 @@ func (s *serviceControllerImpl) GetDiskHost(ctx context.Context, diskID proto.Di
 			Host:     item.host,
 			IDC:      item.idc,
 			Punished: broken || item.isPunish(),
+			Latency:  item.latency.get(),
 		}, nil
 	}
 	ret, err, _ := s.group.Do("get-diskinfo-"+diskID.ToString(), func() (interface{}, error) {
@@ -413,6 +421,22 @@ func (s *serviceControllerImpl) PunishServiceWithThreshold(ctx context.Context,
 	atomic.StoreInt64(&item.lastModifyTime, time.Now().Unix())
 }
 
+// ReportDiskLatency records a latency sample of reading on the disk,
+// the sample is dropped if the disk host has not been loaded.
+func (s *serviceControllerImpl) ReportDiskLatency(ctx context.Context, diskID proto.DiskID, latency time.Duration) {
+	if v, ok := s.allServices.Load(_diskHostServicePrefix + diskID.ToString()); ok {
+		v.(*hostItem).latency.add(latency)
+	}
+}
+
+// ReportDiskFailure records a failed or timed out reading on the disk as a slow sample,
+// the sample is dropped if the disk host has not been loaded.
+func (s *serviceControllerImpl) ReportDiskFailure(ctx context.Context, diskID proto.DiskID, elapsed time.Duration) {
+	if v, ok := s.allServices.Load(_diskHostServicePrefix + diskID.ToString()); ok {
+		v.(*hostItem).latency.addFailure(elapsed)
+	}
+}
+
 func (s *serviceControllerImpl) getServiceLock(name string) *sync.RWMutex {
 	return s.serviceLocks[name]
 }
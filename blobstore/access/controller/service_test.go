@@ -328,3 +328,46 @@ func TestAccessServicePunishDisk(t *testing.T) {
 		require.False(t, host.Punished)
 	}
 }
+
+func TestAccessServiceDiskLatency(t *testing.T) {
+	sc, err := controller.NewServiceController(
+		controller.ServiceConfig{IDC: idc, ReloadSec: 1}, cmcli, proxycli, nil)
+	require.NoError(t, err)
+
+	// not loaded disk
+	sc.ReportDiskLatency(serviceCtx, 10002, time.Millisecond)
+
+	host, err := sc.GetDiskHost(serviceCtx, proto.DiskID(10001))
+	require.NoError(t, err)
+	require.Equal(t, controller.Latency{}, host.Latency)
+
+	sc.ReportDiskLatency(serviceCtx, 10001, 10*time.Millisecond)
+	host, _ = sc.GetDiskHost(serviceCtx, proto.DiskID(10001))
+	require.Equal(t, 10*time.Millisecond, host.Latency.EWMA)
+	require.Equal(t, 10*time.Millisecond, host.Latency.P99)
+
+	for range [127]struct{}{} {
+		sc.ReportDiskLatency(serviceCtx, 10001, time.Millisecond)
+	}
+	host, _ = sc.GetDiskHost(serviceCtx, proto.DiskID(10001))
+	require.InDelta(t, float64(time.Millisecond), float64(host.Latency.EWMA), float64(time.Microsecond))
+	require.Equal(t, time.Millisecond, host.Latency.P99)
+
+	for range [16]struct{}{} {
+		sc.ReportDiskLatency(serviceCtx, 10001, 100*time.Millisecond)
+	}
+	host, _ = sc.GetDiskHost(serviceCtx, proto.DiskID(10001))
+	require.Less(t, 90*time.Millisecond, host.Latency.EWMA)
+	require.Equal(t, 100*time.Millisecond, host.Latency.P99)
+
+	// fast failure is recorded with the penalty, timeout is recorded as it is
+	sc.ReportDiskFailure(serviceCtx, 10002, time.Millisecond)
+	sc.ReportDiskFailure(serviceCtx, 10001, time.Millisecond)
+	host, _ = sc.GetDiskHost(serviceCtx, proto.DiskID(10001))
+	ewma := host.Latency.EWMA
+	require.Less(t, 200*time.Millisecond, ewma)
+	require.Greater(t, 300*time.Millisecond, ewma)
+	sc.ReportDiskFailure(serviceCtx, 10001, 3*time.Second)
+	host, _ = sc.GetDiskHost(serviceCtx, proto.DiskID(10001))
+	require.InDelta(t, float64(600*time.Millisecond+ewma*4/5), float64(host.Latency.EWMA), float64(time.Microsecond))
+}
@@ -40,12 +40,13 @@ import (
 )
 
 const (
-	limitNameAlloc  = "alloc"
-	limitNamePut    = "put"
-	limitNamePutAt  = "putat"
-	limitNameGet    = "get"
-	limitNameDelete = "delete"
-	limitNameSign   = "sign"
+	limitNameAlloc   = "alloc"
+	limitNamePut     = "put"
+	limitNamePutAt   = "putat"
+	limitNameGet     = "get"
+	limitNameDelete  = "delete"
+	limitNameSign    = "sign"
+	limitNameConvert = "convert"
 )
 
 func initWithRegionMagic(regionMagic string) {
@@ -204,6 +205,8 @@ func (s *Service) Limit(c *rpc.Context) {
 		name = limitNameDelete
 	case "/sign":
 		name = limitNameSign
+	case "/convert":
+		name = limitNameConvert
 	default:
 	}
 	if name == "" {
@@ -245,7 +248,7 @@ func (s *Service) Put(c *rpc.Context) {
 	}
 
 	rc := s.limiter.Reader(ctx, c.Request.Body)
-	loc, err := s.streamHandler.Put(ctx, rc, args.Size, hasherMap)
+	loc, err := s.streamHandler.Put(ctx, rc, args.Size, hasherMap, args.Expire)
 	if err != nil {
 		span.Error("stream put failed", errors.Detail(err))
 		c.RespondError(httpError(err))
@@ -309,7 +312,7 @@ func (s *Service) PutAt(c *rpc.Context) {
 	}
 
 	rc := s.limiter.Reader(ctx, c.Request.Body)
-	err := s.streamHandler.PutAt(ctx, rc, args.ClusterID, args.Vid, args.BlobID, args.Size, hasherMap)
+	err := s.streamHandler.PutAt(ctx, rc, args.ClusterID, args.Vid, args.BlobID, args.Size, hasherMap, args.Expire)
 	if err != nil {
 		span.Error("stream putat failed", errors.Detail(err))
 		c.RespondError(httpError(err))
@@ -342,7 +345,7 @@ func (s *Service) Alloc(c *rpc.Context) {
 		return
 	}
 
-	location, err := s.streamHandler.Alloc(ctx, args.Size, args.BlobSize, args.AssignClusterID, args.CodeMode)
+	location, err := s.streamHandler.Alloc(ctx, args.Size, args.BlobSize, args.AssignClusterID, args.CodeMode, args.Expire)
 	if err != nil {
 		span.Error("stream alloc failed", errors.Detail(err))
 		c.RespondError(httpError(err))
@@ -607,3 +610,41 @@ func httpError(err error) error {
 	}
 	return errcode.ErrUnexpected
 }
+
+// Convert convert location into another codemode
+func (s *Service) Convert(c *rpc.Context) {
+	args := new(access.ConvertArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+
+	span.Debugf("accept /convert request args: %+v", args)
+	if !args.IsValid() {
+		c.RespondError(errcode.ErrIllegalArguments)
+		return
+	}
+	if !stream.LocationCrcVerify(&args.Location) {
+		span.Infof("invalid crc %+v", args.Location)
+		c.RespondError(errcode.ErrIllegalArguments)
+		return
+	}
+
+	loc, err := s.streamHandler.Convert(ctx, &args.Location, args.CodeMode)
+	if err != nil {
+		span.Error("stream convert failed", errors.Detail(err))
+		c.RespondError(httpError(err))
+		return
+	}
+	if err := stream.LocationCrcFill(loc); err != nil {
+		span.Error("stream convert fill location crc", err)
+		c.RespondError(httpError(err))
+		return
+	}
+
+	c.RespondJSON(access.ConvertResp{Location: *loc})
+	span.Infof("done /convert request location:%+v", loc)
+}
@@ -71,9 +71,9 @@ func newService() *Service {
 	ctr := gomock.NewController(&testing.T{})
 	s := mocks.NewMockStreamHandler(ctr)
 
-	s.EXPECT().Alloc(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+	s.EXPECT().Alloc(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
 		func(ctx context.Context, size uint64, blobSize uint32,
-			assignClusterID proto.ClusterID, codeMode codemode.CodeMode,
+			assignClusterID proto.ClusterID, codeMode codemode.CodeMode, expire int64,
 		) (*access.Location, error) {
 			if size < 1024 {
 				return nil, errors.New("fake alloc location")
@@ -85,9 +85,9 @@ func newService() *Service {
 		})
 
 	s.EXPECT().PutAt(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
-		gomock.Any()).AnyTimes().DoAndReturn(
+		gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
 		func(ctx context.Context, rc io.Reader,
-			clusterID proto.ClusterID, vid proto.Vid, bid proto.BlobID, size int64, hasherMap access.HasherMap,
+			clusterID proto.ClusterID, vid proto.Vid, bid proto.BlobID, size int64, hasherMap access.HasherMap, expire int64,
 		) error {
 			if size < 1024 {
 				return errcode.ErrAccessLimited
@@ -95,8 +95,8 @@ func newService() *Service {
 			return nil
 		})
 
-	s.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
-		func(ctx context.Context, rc io.Reader, size int64, hasherMap access.HasherMap) (*access.Location, error) {
+	s.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(ctx context.Context, rc io.Reader, size int64, hasherMap access.HasherMap, expire int64) (*access.Location, error) {
 			if size < 1024 {
 				return nil, errors.New("fake put nil body")
 			}
@@ -122,6 +122,15 @@ func newService() *Service {
 			}
 			return nil
 		})
+	s.EXPECT().Convert(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(ctx context.Context, location *access.Location, codeMode codemode.CodeMode) (*access.Location, error) {
+			if location.Size < 1024 {
+				return nil, errors.New("fake convert error")
+			}
+			loc := location.Copy()
+			loc.CodeMode = codeMode
+			return &loc, nil
+		})
 
 	return &Service{
 		streamHandler: s,
@@ -527,6 +536,43 @@ func TestAccessServiceSign(t *testing.T) {
 	}
 }
 
+func TestAccessServiceConvert(t *testing.T) {
+	host := runMockService(newService())
+	cli := newClient()
+
+	url := func() string {
+		return fmt.Sprintf("%s/convert", host)
+	}
+	loc := location.Copy()
+	loc.Size = 1024
+	args := access.ConvertArgs{Location: loc, CodeMode: codemode.EC6P10L2}
+	{
+		resp := &access.ConvertResp{}
+		err := cli.PostWith(ctx, url(), resp, access.ConvertArgs{Location: loc})
+		assertErrorCode(t, 400, err)
+	}
+	{
+		resp := &access.ConvertResp{}
+		err := cli.PostWith(ctx, url(), resp, args)
+		assertErrorCode(t, 400, err)
+	}
+	{
+		stream.LocationCrcFill(&args.Location)
+		resp := &access.ConvertResp{}
+		err := cli.PostWith(ctx, url(), resp, args)
+		require.NoError(t, err)
+		require.Equal(t, codemode.EC6P10L2, resp.Location.CodeMode)
+		require.True(t, stream.LocationCrcVerify(&resp.Location))
+	}
+	{
+		args.Location.Size = 1
+		stream.LocationCrcFill(&args.Location)
+		resp := &access.ConvertResp{}
+		err := cli.PostWith(ctx, url(), resp, args)
+		assertErrorCode(t, 500, err)
+	}
+}
+
 func assertErrorCode(t *testing.T, code int, err error) {
 	require.Error(t, err)
 	codeActual := rpc.DetectStatusCode(err)
@@ -100,5 +100,10 @@ func NewHandler(service *Service) *rpc.Router {
 	// response body:  json
 	rpc.POST("/sign", service.Sign, rpc.OptArgsBody())
 
+	// POST /convert
+	// request  body:  json
+	// response body:  json
+	rpc.POST("/convert", service.Convert, rpc.OptArgsBody())
+
 	return rpc.DefaultRouter
 }
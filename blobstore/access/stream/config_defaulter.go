@@ -24,6 +24,10 @@ const (
 	defaultEncoderConcurrency     int = 1000
 	defaultMinReadShardsX         int = 1
 
+	defaultHedgedReadMinDelayMs int = 10
+	defaultHedgedReadMaxDelayMs int = 500
+	defaultHedgedReadMaxShards  int = 2
+
 	// client timeout ms
 	defaultTimeoutClusterMgr int64 = 1000 * 3
 	defaultTimeoutProxy      int64 = 1000 * 5
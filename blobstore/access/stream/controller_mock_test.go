@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/cubefs/cubefs/blobstore/access/controller (interfaces: ClusterController,ServiceController,VolumeGetter)
+// Source: github.com/cubefs/cubefs/blobstore/access/controller (interfaces: ClusterController,ServiceController,VolumeGetter,KVClient)
 
 // Package stream is a generated GoMock package.
 package stream
@@ -7,6 +7,7 @@ package stream
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	controller "github.com/cubefs/cubefs/blobstore/access/controller"
 	clustermgr "github.com/cubefs/cubefs/blobstore/api/clustermgr"
@@ -80,6 +81,21 @@ func (mr *MockClusterControllerMockRecorder) ChooseOne() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChooseOne", reflect.TypeOf((*MockClusterController)(nil).ChooseOne))
 }
 
+// GetCompactedVolume mocks base method.
+func (m *MockClusterController) GetCompactedVolume(arg0 context.Context, arg1 proto.ClusterID, arg2 proto.Vid) (*proto.CompactedVolume, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCompactedVolume", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*proto.CompactedVolume)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCompactedVolume indicates an expected call of GetCompactedVolume.
+func (mr *MockClusterControllerMockRecorder) GetCompactedVolume(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompactedVolume", reflect.TypeOf((*MockClusterController)(nil).GetCompactedVolume), arg0, arg1, arg2)
+}
+
 // GetConfig mocks base method.
 func (m *MockClusterController) GetConfig(arg0 context.Context, arg1 string) (string, error) {
 	m.ctrl.T.Helper()
@@ -95,6 +111,66 @@ func (mr *MockClusterControllerMockRecorder) GetConfig(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfig", reflect.TypeOf((*MockClusterController)(nil).GetConfig), arg0, arg1)
 }
 
+// GetConvertedVolume mocks base method.
+func (m *MockClusterController) GetConvertedVolume(arg0 context.Context, arg1 proto.ClusterID, arg2 proto.Vid) (*proto.ConvertedVolume, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConvertedVolume", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*proto.ConvertedVolume)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConvertedVolume indicates an expected call of GetConvertedVolume.
+func (mr *MockClusterControllerMockRecorder) GetConvertedVolume(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConvertedVolume", reflect.TypeOf((*MockClusterController)(nil).GetConvertedVolume), arg0, arg1, arg2)
+}
+
+// GetConvertedTargetVolume mocks base method.
+func (m *MockClusterController) GetConvertedTargetVolume(arg0 context.Context, arg1 proto.ClusterID, arg2 proto.Vid) (*proto.ConvertedVolume, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConvertedTargetVolume", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*proto.ConvertedVolume)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConvertedTargetVolume indicates an expected call of GetConvertedTargetVolume.
+func (mr *MockClusterControllerMockRecorder) GetConvertedTargetVolume(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConvertedTargetVolume", reflect.TypeOf((*MockClusterController)(nil).GetConvertedTargetVolume), arg0, arg1, arg2)
+}
+
+// GetKVClient mocks base method.
+func (m *MockClusterController) GetKVClient(arg0 proto.ClusterID) (controller.KVClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKVClient", arg0)
+	ret0, _ := ret[0].(controller.KVClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKVClient indicates an expected call of GetKVClient.
+func (mr *MockClusterControllerMockRecorder) GetKVClient(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKVClient", reflect.TypeOf((*MockClusterController)(nil).GetKVClient), arg0)
+}
+
+// GetMigratedVolume mocks base method.
+func (m *MockClusterController) GetMigratedVolume(arg0 context.Context, arg1 proto.ClusterID, arg2 proto.Vid) (*proto.MigratedVolume, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMigratedVolume", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*proto.MigratedVolume)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMigratedVolume indicates an expected call of GetMigratedVolume.
+func (mr *MockClusterControllerMockRecorder) GetMigratedVolume(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMigratedVolume", reflect.TypeOf((*MockClusterController)(nil).GetMigratedVolume), arg0, arg1, arg2)
+}
+
 // GetServiceController mocks base method.
 func (m *MockClusterController) GetServiceController(arg0 proto.ClusterID) (controller.ServiceController, error) {
 	m.ctrl.T.Helper()
@@ -255,6 +331,30 @@ func (mr *MockServiceControllerMockRecorder) PunishServiceWithThreshold(arg0, ar
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PunishServiceWithThreshold", reflect.TypeOf((*MockServiceController)(nil).PunishServiceWithThreshold), arg0, arg1, arg2, arg3)
 }
 
+// ReportDiskFailure mocks base method.
+func (m *MockServiceController) ReportDiskFailure(arg0 context.Context, arg1 proto.DiskID, arg2 time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReportDiskFailure", arg0, arg1, arg2)
+}
+
+// ReportDiskFailure indicates an expected call of ReportDiskFailure.
+func (mr *MockServiceControllerMockRecorder) ReportDiskFailure(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportDiskFailure", reflect.TypeOf((*MockServiceController)(nil).ReportDiskFailure), arg0, arg1, arg2)
+}
+
+// ReportDiskLatency mocks base method.
+func (m *MockServiceController) ReportDiskLatency(arg0 context.Context, arg1 proto.DiskID, arg2 time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReportDiskLatency", arg0, arg1, arg2)
+}
+
+// ReportDiskLatency indicates an expected call of ReportDiskLatency.
+func (mr *MockServiceControllerMockRecorder) ReportDiskLatency(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportDiskLatency", reflect.TypeOf((*MockServiceController)(nil).ReportDiskLatency), arg0, arg1, arg2)
+}
+
 // MockVolumeGetter is a mock of VolumeGetter interface.
 type MockVolumeGetter struct {
 	ctrl     *gomock.Controller
@@ -303,3 +403,99 @@ func (mr *MockVolumeGetterMockRecorder) Punish(arg0, arg1, arg2 interface{}) *go
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Punish", reflect.TypeOf((*MockVolumeGetter)(nil).Punish), arg0, arg1, arg2)
 }
+
+// MockKVClient is a mock of KVClient interface.
+type MockKVClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockKVClientMockRecorder
+}
+
+// MockKVClientMockRecorder is the mock recorder for MockKVClient.
+type MockKVClientMockRecorder struct {
+	mock *MockKVClient
+}
+
+// NewMockKVClient creates a new mock instance.
+func NewMockKVClient(ctrl *gomock.Controller) *MockKVClient {
+	mock := &MockKVClient{ctrl: ctrl}
+	mock.recorder = &MockKVClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKVClient) EXPECT() *MockKVClientMockRecorder {
+	return m.recorder
+}
+
+// DeleteKV mocks base method.
+func (m *MockKVClient) DeleteKV(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteKV", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteKV indicates an expected call of DeleteKV.
+func (mr *MockKVClientMockRecorder) DeleteKV(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteKV", reflect.TypeOf((*MockKVClient)(nil).DeleteKV), arg0, arg1)
+}
+
+// GetKV mocks base method.
+func (m *MockKVClient) GetKV(arg0 context.Context, arg1 string) (clustermgr.GetKvRet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKV", arg0, arg1)
+	ret0, _ := ret[0].(clustermgr.GetKvRet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKV indicates an expected call of GetKV.
+func (mr *MockKVClientMockRecorder) GetKV(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKV", reflect.TypeOf((*MockKVClient)(nil).GetKV), arg0, arg1)
+}
+
+// RefKV mocks base method.
+func (m *MockKVClient) RefKV(arg0 context.Context, arg1, arg2 string, arg3 []byte) (clustermgr.RefKvRet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefKV", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(clustermgr.RefKvRet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefKV indicates an expected call of RefKV.
+func (mr *MockKVClientMockRecorder) RefKV(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefKV", reflect.TypeOf((*MockKVClient)(nil).RefKV), arg0, arg1, arg2, arg3)
+}
+
+// SetKV mocks base method.
+func (m *MockKVClient) SetKV(arg0 context.Context, arg1 string, arg2 []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetKV", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetKV indicates an expected call of SetKV.
+func (mr *MockKVClientMockRecorder) SetKV(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetKV", reflect.TypeOf((*MockKVClient)(nil).SetKV), arg0, arg1, arg2)
+}
+
+// UnrefKV mocks base method.
+func (m *MockKVClient) UnrefKV(arg0 context.Context, arg1, arg2 string) (clustermgr.RefKvRet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnrefKV", arg0, arg1, arg2)
+	ret0, _ := ret[0].(clustermgr.RefKvRet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnrefKV indicates an expected call of UnrefKV.
+func (mr *MockKVClientMockRecorder) UnrefKV(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnrefKV", reflect.TypeOf((*MockKVClient)(nil).UnrefKV), arg0, arg1, arg2)
+}
@@ -40,11 +40,22 @@ var downloadMetric = prometheus.NewCounterVec(
 	[]string{"cluster", "way", "reason"},
 )
 
+var hedgedReadMetric = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "blobstore",
+		Subsystem: "access",
+		Name:      "hedged_read",
+		Help:      "hedged read of shards on access",
+	},
+	[]string{"cluster", "status"},
+)
+
 var SteamReportDownload = reportDownload
 
 func init() {
 	prometheus.MustRegister(unhealthMetric)
 	prometheus.MustRegister(downloadMetric)
+	prometheus.MustRegister(hedgedReadMetric)
 }
 
 func reportUnhealth(cid proto.ClusterID, action, module, host, reason string) {
@@ -54,3 +65,8 @@ func reportUnhealth(cid proto.ClusterID, action, module, host, reason string) {
 func reportDownload(cid proto.ClusterID, way, reason string) {
 	downloadMetric.WithLabelValues(cid.ToString(), way, reason).Inc()
 }
+
+// status: sent, the hedged shard read; done or failed, the blob read with hedged shards
+func reportHedgedRead(cid proto.ClusterID, status string) {
+	hedgedReadMetric.WithLabelValues(cid.ToString(), status).Inc()
+}
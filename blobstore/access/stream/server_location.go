@@ -126,7 +126,8 @@ func signCrc(loc *access.Location, locs []access.Location) error {
 
 	if loc.ClusterID != first.ClusterID ||
 		loc.CodeMode != first.CodeMode ||
-		loc.BlobSize != first.BlobSize {
+		loc.BlobSize != first.BlobSize ||
+		loc.Expire != first.Expire {
 		return fmt.Errorf("not equal in constant field")
 	}
 
@@ -54,22 +54,25 @@ type StreamHandler interface {
 	//     optional: blobSize > 0, alloc with blobSize
 	//               assignClusterID > 0, assign to alloc in this cluster certainly
 	//               codeMode > 0, alloc in this codemode
+	//               expire > 0, the blobs are deleted after the unix time
 	//     return: a location of file
 	Alloc(ctx context.Context, size uint64, blobSize uint32,
-		assignClusterID proto.ClusterID, codeMode codemode.CodeMode) (*access.Location, error)
+		assignClusterID proto.ClusterID, codeMode codemode.CodeMode, expire int64) (*access.Location, error)
 
 	// PutAt access interface /putat, put one blob
 	//     required: rc file reader
 	//     required: clusterID VolumeID BlobID
 	//     required: size, one blob size
 	//     optional: hasherMap, computing hash
+	//               expire, the expiry of allocated location recorded in shards
 	PutAt(ctx context.Context, rc io.Reader,
-		clusterID proto.ClusterID, vid proto.Vid, bid proto.BlobID, size int64, hasherMap access.HasherMap) error
+		clusterID proto.ClusterID, vid proto.Vid, bid proto.BlobID, size int64, hasherMap access.HasherMap, expire int64) error
 
 	// Put put one object
 	//     required: size, file size
 	//     optional: hasher map to calculate hash.Hash
-	Put(ctx context.Context, rc io.Reader, size int64, hasherMap access.HasherMap) (*access.Location, error)
+	//               expire > 0, the blobs are deleted after the unix time
+	Put(ctx context.Context, rc io.Reader, size int64, hasherMap access.HasherMap, expire int64) (*access.Location, error)
 
 	// Get read file
 	//     required: location, readSize
@@ -98,6 +101,16 @@ type StreamHandler interface {
 	// Delete delete all blobs in this location
 	Delete(ctx context.Context, location *access.Location) error
 
+	// Convert convert location into codemode
+	//     required: location, codeMode
+	//
+	//  Rewrite volume of blobs if all volumes of location have been converted
+	//  into codemode by scheduler, otherwise copy data into a new location.
+	//  The rewritten location holds the same blobs as the old one, deleting
+	//  either of them deletes both, so only one of them should be deleted.
+	//  The old location is not deleted if the data is copied.
+	Convert(ctx context.Context, location *access.Location, codeMode codemode.CodeMode) (*access.Location, error)
+
 	// Admin returns internal admin interface.
 	Admin() interface{}
 }
@@ -129,6 +142,9 @@ type StreamConfig struct {
 	// just for one AZ is down, cant write quorum in all AZs
 	CodeModesPutQuorums map[codemode.CodeMode]int `json:"code_mode_put_quorums"`
 
+	Dedup      DedupConfig      `json:"dedup"`
+	HedgedRead HedgedReadConfig `json:"hedged_read"`
+
 	ClusterConfig  controller.ClusterConfig `json:"cluster_config"`
 	BlobnodeConfig blobnode.Config          `json:"blobnode_config"`
 	ProxyConfig    proxy.Config             `json:"proxy_config"`
@@ -203,6 +219,12 @@ func confCheck(cfg *StreamConfig) error {
 	}
 	defaulter.LessOrEqual(&cfg.EncoderConcurrency, defaultEncoderConcurrency)
 	defaulter.LessOrEqual(&cfg.MinReadShardsX, defaultMinReadShardsX)
+	defaulter.LessOrEqual(&cfg.HedgedRead.MinDelayMs, defaultHedgedReadMinDelayMs)
+	defaulter.LessOrEqual(&cfg.HedgedRead.MaxDelayMs, defaultHedgedReadMaxDelayMs)
+	defaulter.LessOrEqual(&cfg.HedgedRead.MaxShards, defaultHedgedReadMaxShards)
+	if cfg.HedgedRead.MaxDelayMs < cfg.HedgedRead.MinDelayMs {
+		return errors.Newf("invalid hedged read delay: %+v", cfg.HedgedRead)
+	}
 
 	defaulter.LessOrEqual(&cfg.ClusterConfig.CMClientConfig.Config.ClientTimeoutMs, defaultTimeoutClusterMgr)
 	defaulter.LessOrEqual(&cfg.BlobnodeConfig.ClientTimeoutMs, defaultTimeoutBlobnode)
@@ -309,7 +331,39 @@ func NewStreamHandler(cfg *StreamConfig, stopCh <-chan struct{}) (h StreamHandle
 func (h *Handler) Delete(ctx context.Context, location *access.Location) error {
 	span := trace.SpanFromContextSafe(ctx)
 	span.Debugf("to delete %+v", location)
-	return h.clearGarbage(ctx, location)
+	origin := location
+	if h.Dedup.Enable {
+		loc, err := h.dedupRetained(ctx, location)
+		if err != nil {
+			return err
+		}
+		if len(loc.Blobs) == 0 {
+			span.Debug("all blobs are referenced by others")
+			return nil
+		}
+		location = loc
+	}
+
+	redirected, err := h.redirectMigrated(ctx, location)
+	if err != nil {
+		return err
+	}
+	if redirected, err = h.redirectCompacted(ctx, redirected); err != nil {
+		return err
+	}
+	loc, err := h.appendConverted(ctx, redirected)
+	if err != nil {
+		return err
+	}
+	if err = h.clearGarbage(ctx, loc); err != nil {
+		return err
+	}
+	h.unrecordExpiring(ctx, origin)
+	// scheduler moves the records along with the migrated or compacted volumes
+	if redirected != location {
+		h.unrecordExpiring(ctx, redirected)
+	}
+	return nil
 }
 
 // Admin returns internal admin interface.
@@ -38,18 +38,22 @@ var errAllocatePunishedVolume = errors.New("allocate punished volume")
 //	optional: blobSize > 0, alloc with blobSize
 //	          assignClusterID > 0, assign to alloc in this cluster certainly
 //	          codeMode > 0, alloc in this codemode
+//	          expire > 0, the blobs are deleted after the unix time
 //	return: a location of file
 func (h *Handler) Alloc(ctx context.Context, size uint64, blobSize uint32,
-	assignClusterID proto.ClusterID, codeMode codemode.CodeMode,
+	assignClusterID proto.ClusterID, codeMode codemode.CodeMode, expire int64,
 ) (*access.Location, error) {
 	span := trace.SpanFromContextSafe(ctx)
-	span.Debugf("alloc request with size:%d blobsize:%d cluster:%d codemode:%d",
-		size, blobSize, assignClusterID, codeMode)
+	span.Debugf("alloc request with size:%d blobsize:%d cluster:%d codemode:%d expire:%d",
+		size, blobSize, assignClusterID, codeMode, expire)
 
 	if int64(size) > h.maxObjectSize {
 		span.Info("exceed max object size", h.maxObjectSize)
 		return nil, errcode.ErrAccessExceedSize
 	}
+	if err := checkExpire(expire); err != nil {
+		return nil, err
+	}
 
 	if blobSize == 0 {
 		blobSize = atomic.LoadUint32(&h.MaxBlobSize)
@@ -78,6 +82,11 @@ func (h *Handler) Alloc(ctx context.Context, size uint64, blobSize uint32,
 		Size:      size,
 		BlobSize:  blobSize,
 		Blobs:     blobs,
+		Expire:    expire,
+	}
+	// the allocated blobs are deleted after expired even if never put
+	if err = h.recordExpiring(ctx, location); err != nil {
+		return nil, err
 	}
 	span.Debugf("alloc ok %+v", location)
 	return location, nil
@@ -28,7 +28,7 @@ func TestAccessStreamAllocBase(t *testing.T) {
 	ctx := ctxWithName("TestAccessStreamAllocBase")
 	// 4M blobsize
 	{
-		loc, err := streamer.Alloc(ctx(), 1<<30, 0, 0, 0)
+		loc, err := streamer.Alloc(ctx(), 1<<30, 0, 0, 0, 0)
 		require.NoError(t, err)
 		require.Equal(t, clusterID, loc.ClusterID)
 		require.Equal(t, codemode.EC6P6, loc.CodeMode)
@@ -39,7 +39,7 @@ func TestAccessStreamAllocBase(t *testing.T) {
 		require.Equal(t, uint32((1<<8)-1), loc.Blobs[1].Count)
 	}
 	{
-		loc, err := streamer.Alloc(ctx(), (1<<30)+1, 0, 0, 0)
+		loc, err := streamer.Alloc(ctx(), (1<<30)+1, 0, 0, 0, 0)
 		require.NoError(t, err)
 		require.Equal(t, 2, len(loc.Blobs))
 		require.Equal(t, uint32(1), loc.Blobs[0].Count)
@@ -47,7 +47,7 @@ func TestAccessStreamAllocBase(t *testing.T) {
 	}
 	// 1M blobsize
 	{
-		loc, err := streamer.Alloc(ctx(), 1<<30, 1<<20, 0, 0)
+		loc, err := streamer.Alloc(ctx(), 1<<30, 1<<20, 0, 0, 0)
 		require.NoError(t, err)
 		require.Equal(t, 2, len(loc.Blobs))
 		require.Equal(t, uint32(1), loc.Blobs[0].Count)
@@ -55,7 +55,7 @@ func TestAccessStreamAllocBase(t *testing.T) {
 	}
 	// max size + 1
 	{
-		_, err := streamer.Alloc(ctx(), uint64(defaultMaxObjectSize+1), 1<<20, 0, 0)
+		_, err := streamer.Alloc(ctx(), uint64(defaultMaxObjectSize+1), 1<<20, 0, 0, 0)
 		require.EqualError(t, errcode.ErrAccessExceedSize, err.Error())
 	}
 
@@ -64,7 +64,7 @@ func TestAccessStreamAllocBase(t *testing.T) {
 		defer func() {
 			time.Sleep(time.Second)
 		}()
-		_, err := streamer.Alloc(ctx(), allocTimeoutSize+1, 0, 0, 0)
+		_, err := streamer.Alloc(ctx(), allocTimeoutSize+1, 0, 0, 0, 0)
 		require.Error(t, err)
 	}
 }
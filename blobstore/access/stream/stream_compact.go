@@ -0,0 +1,67 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"context"
+
+	"github.com/cubefs/cubefs/blobstore/access/controller"
+	"github.com/cubefs/cubefs/blobstore/api/access"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+)
+
+// maxCompactedDepth the most times of a blob could be compacted into another volume
+const maxCompactedDepth = 8
+
+// redirectCompacted returns the location with volumes which live blobs have been
+// compacted into, blobs keep their bids in the target volume.
+// The location is returned as it is if no volume of it has been compacted.
+func (h *Handler) redirectCompacted(ctx context.Context, location *access.Location) (*access.Location, error) {
+	span := trace.SpanFromContextSafe(ctx)
+
+	redirected := make(map[proto.Vid]proto.Vid)
+	for _, blob := range location.Blobs {
+		if _, ok := redirected[blob.Vid]; ok {
+			continue
+		}
+		vid := blob.Vid
+		for range [maxCompactedDepth]struct{}{} {
+			volume, err := h.clusterController.GetCompactedVolume(ctx, location.ClusterID, vid)
+			if err != nil {
+				if err == controller.ErrNoCompactedVolume {
+					break
+				}
+				return nil, err
+			}
+			vid = volume.TargetVid
+		}
+		redirected[blob.Vid] = vid
+	}
+
+	changed := false
+	loc := location.Copy()
+	for idx, blob := range loc.Blobs {
+		if vid := redirected[blob.Vid]; vid != blob.Vid {
+			loc.Blobs[idx].Vid = vid
+			changed = true
+		}
+	}
+	if !changed {
+		return location, nil
+	}
+	span.Debugf("redirect location of compacted volumes to %+v", loc)
+	return &loc, nil
+}
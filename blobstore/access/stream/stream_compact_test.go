@@ -0,0 +1,67 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+)
+
+func TestAccessStreamRedirectCompacted(t *testing.T) {
+	ctx := ctxWithName("TestAccessStreamRedirectCompacted")
+
+	dataShards.clean()
+	data := []byte("compacted data")
+	loc, err := streamer.Put(ctx(), bytes.NewReader(data), int64(len(data)), nil, 0)
+	require.NoError(t, err)
+
+	// volume has not been compacted
+	{
+		newLoc, err := streamer.redirectCompacted(ctx(), loc)
+		require.NoError(t, err)
+		require.Equal(t, loc, newLoc)
+	}
+
+	// compacted twice: 9002 -> 9003 -> volume of the location
+	var sourceVid, middleVid proto.Vid = 9002, 9003
+	compactedLoc := loc.Copy()
+	compactedLoc.Blobs[0].Vid = sourceVid
+	compactedVolumes[sourceVid] = &proto.CompactedVolume{SourceVid: sourceVid, TargetVid: middleVid}
+	compactedVolumes[middleVid] = &proto.CompactedVolume{SourceVid: middleVid, TargetVid: loc.Blobs[0].Vid}
+	defer func() {
+		delete(compactedVolumes, sourceVid)
+		delete(compactedVolumes, middleVid)
+	}()
+
+	{
+		newLoc, err := streamer.redirectCompacted(ctx(), &compactedLoc)
+		require.NoError(t, err)
+		require.Equal(t, *loc, *newLoc)
+		require.Equal(t, sourceVid, compactedLoc.Blobs[0].Vid)
+	}
+	// read data of compacted volume from the target volume
+	{
+		buff := bytes.NewBuffer(nil)
+		transfer, err := streamer.Get(ctx(), buff, compactedLoc, uint64(len(data)), 0)
+		require.NoError(t, err)
+		require.NoError(t, transfer())
+		require.True(t, dataEqual(data, buff.Bytes()))
+	}
+	require.NoError(t, streamer.Delete(ctx(), &compactedLoc))
+}
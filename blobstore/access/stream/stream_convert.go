@@ -0,0 +1,175 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/cubefs/cubefs/blobstore/api/access"
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/rpc"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/util/errors"
+)
+
+// Convert convert location into codemode
+func (h *Handler) Convert(ctx context.Context, location *access.Location,
+	codeMode codemode.CodeMode,
+) (*access.Location, error) {
+	span := trace.SpanFromContextSafe(ctx)
+	span.Debugf("convert location:%+v into codemode:%d", location, codeMode)
+
+	if location.CodeMode == codeMode {
+		loc := location.Copy()
+		return &loc, nil
+	}
+	if _, ok := h.encoder[codeMode]; !ok {
+		span.Infof("codemode %d is not supported", codeMode)
+		return nil, errcode.ErrIllegalArguments
+	}
+
+	loc, err := h.rewriteConverted(ctx, location, codeMode)
+	if err != nil {
+		return nil, err
+	}
+	if loc != nil {
+		span.Debugf("rewrite converted location:%+v", loc)
+		return loc, nil
+	}
+
+	// copy the data into new allocated location
+	pr, pw := io.Pipe()
+	transfer, err := h.Get(ctx, pw, *location, location.Size, 0)
+	if err != nil {
+		pw.Close()
+		return nil, err
+	}
+	go func() {
+		pw.CloseWithError(transfer())
+	}()
+
+	loc, err = h.putWithCodeMode(ctx, pr, int64(location.Size), codeMode, location.Expire)
+	pr.CloseWithError(io.ErrClosedPipe)
+	if err != nil {
+		span.Error("copy location failed", errors.Detail(err))
+		return nil, err
+	}
+	span.Debugf("copy into location:%+v", loc)
+	return loc, nil
+}
+
+// rewriteConverted returns nil location if any blob has not been converted.
+func (h *Handler) rewriteConverted(ctx context.Context, location *access.Location,
+	codeMode codemode.CodeMode,
+) (*access.Location, error) {
+	converted := make(map[proto.Vid]*proto.ConvertedVolume)
+	for _, blob := range location.Blobs {
+		if _, ok := converted[blob.Vid]; ok {
+			continue
+		}
+		volume, err := h.clusterController.GetConvertedVolume(ctx, location.ClusterID, blob.Vid)
+		if err != nil {
+			if rpc.DetectStatusCode(err) == http.StatusNotFound {
+				return nil, nil
+			}
+			return nil, err
+		}
+		if volume.Converting || volume.TargetCodeMode != codeMode {
+			return nil, nil
+		}
+		converted[blob.Vid] = volume
+	}
+
+	loc := location.Copy()
+	loc.CodeMode = codeMode
+	for idx, blob := range loc.Blobs {
+		volume := converted[blob.Vid]
+		for bid := blob.MinBid; bid < blob.MinBid+proto.BlobID(blob.Count); bid++ {
+			if !volume.Converted(bid) {
+				return nil, nil
+			}
+		}
+		loc.Blobs[idx].Vid = volume.TargetVid
+	}
+	return &loc, nil
+}
+
+// appendConverted returns the location appended with the other copies of blobs
+// in the converted volumes, the blob and its copy are deleted together.
+// The blobs in the converting volume are deleted in target volume too, the copy
+// may be written after the deletion.
+func (h *Handler) appendConverted(ctx context.Context, location *access.Location) (*access.Location, error) {
+	span := trace.SpanFromContextSafe(ctx)
+
+	type aliasVolume struct {
+		vid     proto.Vid
+		deleted func(bid proto.BlobID) bool
+	}
+	aliases := make(map[proto.Vid]*aliasVolume)
+	for _, blob := range location.Blobs {
+		if _, ok := aliases[blob.Vid]; ok {
+			continue
+		}
+		aliases[blob.Vid] = nil
+
+		volume, err := h.clusterController.GetConvertedVolume(ctx, location.ClusterID, blob.Vid)
+		if err == nil {
+			aliases[blob.Vid] = &aliasVolume{vid: volume.TargetVid, deleted: volume.MayConverted}
+			continue
+		}
+		if rpc.DetectStatusCode(err) != http.StatusNotFound {
+			return nil, err
+		}
+		volume, err = h.clusterController.GetConvertedTargetVolume(ctx, location.ClusterID, blob.Vid)
+		if err == nil {
+			aliases[blob.Vid] = &aliasVolume{vid: volume.SourceVid, deleted: volume.Converted}
+			continue
+		}
+		if rpc.DetectStatusCode(err) != http.StatusNotFound {
+			return nil, err
+		}
+	}
+
+	var appended []access.SliceInfo
+	for _, blob := range location.Blobs {
+		alias := aliases[blob.Vid]
+		if alias == nil {
+			continue
+		}
+		for bid := blob.MinBid; bid < blob.MinBid+proto.BlobID(blob.Count); bid++ {
+			if !alias.deleted(bid) {
+				continue
+			}
+			if n := len(appended); n > 0 && appended[n-1].Vid == alias.vid &&
+				appended[n-1].MinBid+proto.BlobID(appended[n-1].Count) == bid {
+				appended[n-1].Count++
+				continue
+			}
+			appended = append(appended, access.SliceInfo{MinBid: bid, Vid: alias.vid, Count: 1})
+		}
+	}
+	if len(appended) == 0 {
+		return location, nil
+	}
+
+	loc := location.Copy()
+	loc.Blobs = append(loc.Blobs, appended...)
+	span.Debugf("append blobs of converted volumes %+v", appended)
+	return &loc, nil
+}
@@ -0,0 +1,169 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/api/access"
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+)
+
+func TestAccessStreamConvert(t *testing.T) {
+	ctx := ctxWithName("TestAccessStreamConvert")
+	loc := &access.Location{
+		ClusterID: clusterID,
+		CodeMode:  codemode.EC6P6,
+		Size:      1 << 23,
+		BlobSize:  1 << 22,
+		Blobs:     []access.SliceInfo{{MinBid: 100, Vid: volumeID, Count: 2}},
+	}
+
+	// the same codemode
+	{
+		newLoc, err := streamer.Convert(ctx(), loc, codemode.EC6P6)
+		require.NoError(t, err)
+		require.Equal(t, *loc, *newLoc)
+	}
+	// not supported codemode
+	{
+		_, err := streamer.Convert(ctx(), loc, codemode.EC12P4)
+		require.ErrorIs(t, err, errcode.ErrIllegalArguments)
+	}
+
+	var targetVid proto.Vid = 1001
+	convertedVolumes[volumeID] = &proto.ConvertedVolume{
+		SourceVid:      volumeID,
+		TargetVid:      targetVid,
+		TargetCodeMode: codemode.EC6P10L2,
+		MaxBid:         1000,
+		SkippedBids:    []proto.BlobID{200},
+	}
+	defer delete(convertedVolumes, volumeID)
+
+	// rewrite converted volume
+	{
+		newLoc, err := streamer.Convert(ctx(), loc, codemode.EC6P10L2)
+		require.NoError(t, err)
+		require.Equal(t, codemode.EC6P10L2, newLoc.CodeMode)
+		require.Equal(t, targetVid, newLoc.Blobs[0].Vid)
+		require.Equal(t, loc.Blobs[0].MinBid, newLoc.Blobs[0].MinBid)
+		require.Equal(t, codemode.EC6P6, loc.CodeMode)
+		require.Equal(t, volumeID, loc.Blobs[0].Vid)
+	}
+	// converted into another codemode
+	{
+		newLoc, err := streamer.rewriteConverted(ctx(), loc, codemode.EC15P12)
+		require.NoError(t, err)
+		require.Nil(t, newLoc)
+	}
+	// skipped blob is copied
+	{
+		skippedLoc := loc.Copy()
+		skippedLoc.Blobs[0].MinBid = 199
+		newLoc, err := streamer.rewriteConverted(ctx(), &skippedLoc, codemode.EC6P10L2)
+		require.NoError(t, err)
+		require.Nil(t, newLoc)
+	}
+	// new blob written after conversion is copied
+	{
+		newBlobLoc := loc.Copy()
+		newBlobLoc.Blobs[0].MinBid = 1000
+		newLoc, err := streamer.rewriteConverted(ctx(), &newBlobLoc, codemode.EC6P10L2)
+		require.NoError(t, err)
+		require.Nil(t, newLoc)
+	}
+	// not converted volume is copied
+	{
+		otherLoc := loc.Copy()
+		otherLoc.Blobs[0].Vid = volumeID + 1
+		newLoc, err := streamer.rewriteConverted(ctx(), &otherLoc, codemode.EC6P10L2)
+		require.NoError(t, err)
+		require.Nil(t, newLoc)
+
+		_, err = streamer.Convert(ctx(), &otherLoc, codemode.EC6P10L2)
+		require.Error(t, err)
+	}
+}
+
+func TestAccessStreamDeleteConverted(t *testing.T) {
+	ctx := ctxWithName("TestAccessStreamDeleteConverted")
+	var targetVid proto.Vid = 1001
+	loc := &access.Location{
+		ClusterID: clusterID,
+		CodeMode:  codemode.EC6P6,
+		Blobs: []access.SliceInfo{
+			{MinBid: 198, Vid: volumeID, Count: 4},
+			{MinBid: 1000, Vid: volumeID, Count: 2},
+			{MinBid: 10, Vid: volumeID + 1, Count: 1},
+		},
+	}
+
+	// not converted volume
+	newLoc, err := streamer.appendConverted(ctx(), loc)
+	require.NoError(t, err)
+	require.Equal(t, loc, newLoc)
+
+	// blobs are deleted in the target volume when converting
+	convertedVolumes[volumeID] = &proto.ConvertedVolume{
+		SourceVid:      volumeID,
+		TargetVid:      targetVid,
+		TargetCodeMode: codemode.EC6P10L2,
+		Converting:     true,
+	}
+	defer delete(convertedVolumes, volumeID)
+	newLoc, err = streamer.appendConverted(ctx(), loc)
+	require.NoError(t, err)
+	require.Equal(t, append(loc.Copy().Blobs,
+		access.SliceInfo{MinBid: 198, Vid: targetVid, Count: 4},
+		access.SliceInfo{MinBid: 1000, Vid: targetVid, Count: 2},
+	), newLoc.Blobs)
+	require.NoError(t, streamer.Delete(ctx(), loc))
+
+	// converted blobs only, skipped and new blobs are not in the target volume
+	convertedVolumes[volumeID] = &proto.ConvertedVolume{
+		SourceVid:      volumeID,
+		TargetVid:      targetVid,
+		TargetCodeMode: codemode.EC6P10L2,
+		MaxBid:         1000,
+		SkippedBids:    []proto.BlobID{200},
+	}
+	newLoc, err = streamer.appendConverted(ctx(), loc)
+	require.NoError(t, err)
+	require.Equal(t, append(loc.Copy().Blobs,
+		access.SliceInfo{MinBid: 198, Vid: targetVid, Count: 2},
+		access.SliceInfo{MinBid: 201, Vid: targetVid, Count: 1},
+		access.SliceInfo{MinBid: 1000, Vid: targetVid, Count: 1},
+	), newLoc.Blobs)
+
+	// the rewritten location deletes the blobs in the source volume
+	rewritten, err := streamer.Convert(ctx(), &access.Location{
+		ClusterID: clusterID,
+		CodeMode:  codemode.EC6P6,
+		Blobs:     []access.SliceInfo{{MinBid: 100, Vid: volumeID, Count: 2}},
+	}, codemode.EC6P10L2)
+	require.NoError(t, err)
+	newLoc, err = streamer.appendConverted(ctx(), rewritten)
+	require.NoError(t, err)
+	require.Equal(t, []access.SliceInfo{
+		{MinBid: 100, Vid: targetVid, Count: 2},
+		{MinBid: 100, Vid: volumeID, Count: 2},
+	}, newLoc.Blobs)
+	require.NoError(t, streamer.Delete(ctx(), rewritten))
+}
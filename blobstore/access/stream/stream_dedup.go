@@ -0,0 +1,259 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/cubefs/cubefs/blobstore/access/controller"
+	"github.com/cubefs/cubefs/blobstore/api/access"
+	"github.com/cubefs/cubefs/blobstore/common/rpc"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/util/errors"
+)
+
+// DedupConfig content-addressed deduplication of put.
+//
+// Objects with the same sha256 and size in one cluster share the location
+// of the first put, the location is kept in kv of cluster manager with
+// reference count. Every slice of the shared location is marked with the
+// content key, delete decreases the reference count of the content and
+// deletes blobs only if the count reaches zero.
+//
+// Each holder references the content with the first slice it allocated, the
+// shared location returned to the later holders is tagged with that slice of
+// no blob, so that a retried delete of the same location dereferences nothing.
+type DedupConfig struct {
+	Enable bool `json:"enable"`
+	// objects smaller than MinSize are not deduplicated
+	MinSize int64 `json:"min_size"`
+}
+
+func dedupContentKey(sum []byte, size int64) string {
+	return fmt.Sprintf("dedup-content-%x-%d", sum, size)
+}
+
+func dedupSliceKey(slice access.SliceInfo) string {
+	return fmt.Sprintf("dedup-slice-%d-%d", slice.Vid, slice.MinBid)
+}
+
+func isSameSlice(a, b access.SliceInfo) bool {
+	return a.Vid == b.Vid && a.MinBid == b.MinBid
+}
+
+func (h *Handler) dedupEnabled(size int64) bool {
+	return h.Dedup.Enable && size >= h.Dedup.MinSize
+}
+
+// dedupLocation references the content of the new put location,
+// returns the shared location tagged with the holder if the content
+// has been put before. The new location is returned as it is if
+// deduplication failed.
+func (h *Handler) dedupLocation(ctx context.Context, location *access.Location, sum []byte) *access.Location {
+	span := trace.SpanFromContextSafe(ctx)
+	kvClient, err := h.clusterController.GetKVClient(location.ClusterID)
+	if err != nil {
+		span.Warn("dedup get kv client failed", errors.Detail(err))
+		return location
+	}
+
+	key := dedupContentKey(sum, int64(location.Size))
+	value, err := json.Marshal(location)
+	if err != nil {
+		span.Warn("dedup marshal location failed", err)
+		return location
+	}
+
+	// mark slices before referenced, so that any holder of the
+	// shared location could find the content key when delete.
+	for _, slice := range location.Blobs {
+		if err = kvClient.SetKV(ctx, dedupSliceKey(slice), []byte(key)); err != nil {
+			span.Warnf("dedup mark slice %+v failed %s", slice, err.Error())
+			h.unmarkDedupSlices(ctx, kvClient, location)
+			return location
+		}
+	}
+
+	tag := access.SliceInfo{MinBid: location.Blobs[0].MinBid, Vid: location.Blobs[0].Vid}
+	holder := dedupSliceKey(tag)
+	ret, err := kvClient.RefKV(ctx, key, holder, value)
+	if err != nil {
+		// stale marks are ignored when delete if the content is not referenced
+		span.Warnf("dedup reference %s failed %s", key, err.Error())
+		return location
+	}
+	if ret.RefCount == 1 {
+		span.Debugf("dedup first reference %s", key)
+		return location
+	}
+
+	shared := new(access.Location)
+	if err = json.Unmarshal(ret.Value, shared); err != nil || len(shared.Blobs) == 0 {
+		span.Warnf("dedup invalid shared location of %s", key)
+		if _, err = kvClient.UnrefKV(ctx, key, holder); err != nil {
+			span.Warnf("dedup rollback reference %s failed %s", key, err.Error())
+		}
+		return location
+	}
+	if isSameSlice(shared.Blobs[0], tag) {
+		span.Debugf("dedup first reference %s", key)
+		return location
+	}
+
+	span.Debugf("dedup reference %s count %d, location %+v is replaced with %+v",
+		key, ret.RefCount, location, shared)
+	h.unmarkDedupSlices(ctx, kvClient, location)
+	if err = h.clearGarbage(ctx, location); err != nil {
+		span.Warn(errors.Detail(err))
+	}
+	shared.Blobs = append(shared.Blobs, tag)
+	return shared
+}
+
+// dedupHolder returns the holder of the shared location at idx of the location,
+// the first holder has no tag after the shared location.
+func dedupHolder(location *access.Location, idx int, shared *access.Location) string {
+	if end := idx + len(shared.Blobs); end < len(location.Blobs) && location.Blobs[end].Count == 0 {
+		return dedupSliceKey(location.Blobs[end])
+	}
+	return dedupSliceKey(shared.Blobs[0])
+}
+
+// dedupRetained dereferences shared locations in the location, returns the
+// location without slices which are still referenced by others.
+func (h *Handler) dedupRetained(ctx context.Context, location *access.Location) (*access.Location, error) {
+	span := trace.SpanFromContextSafe(ctx)
+	kvClient, err := h.clusterController.GetKVClient(location.ClusterID)
+	if err != nil {
+		span.Error("dedup get kv client failed", errors.Detail(err))
+		return nil, err
+	}
+
+	type content struct {
+		key     string
+		shared  *access.Location
+		holders []string
+	}
+	// the location may be merged of several locations in the same cluster
+	contents := make(map[string]*content)
+	contentKeys := make([]string, 0)
+	for idx, slice := range location.Blobs {
+		if slice.Count == 0 { // tag of holder
+			continue
+		}
+		val, err := kvClient.GetKV(ctx, dedupSliceKey(slice))
+		if err != nil {
+			if rpc.DetectStatusCode(err) == http.StatusNotFound {
+				continue
+			}
+			span.Warnf("dedup get mark of slice %+v failed %s", slice, err.Error())
+			return nil, err
+		}
+		key := string(val.Value)
+
+		c, ok := contents[key]
+		if !ok {
+			contents[key] = nil
+			val, err = kvClient.GetKV(ctx, key)
+			if err != nil {
+				if rpc.DetectStatusCode(err) == http.StatusNotFound {
+					continue
+				}
+				span.Warnf("dedup get content %s failed %s", key, err.Error())
+				return nil, err
+			}
+			shared := new(access.Location)
+			if err = json.Unmarshal(val.Value, shared); err != nil || len(shared.Blobs) == 0 {
+				span.Warnf("dedup invalid content %s", key)
+				continue
+			}
+			c = &content{key: key, shared: shared}
+			contents[key] = c
+			contentKeys = append(contentKeys, key)
+		}
+		// each holder of the shared location has all slices of it,
+		// stale marks of the location are ignored.
+		if c == nil || !isSameSlice(slice, c.shared.Blobs[0]) {
+			continue
+		}
+		c.holders = append(c.holders, dedupHolder(location, idx, c.shared))
+	}
+
+	retained := make([]access.SliceInfo, 0)
+	for _, key := range contentKeys {
+		c := contents[key]
+		if len(c.holders) == 0 {
+			continue
+		}
+
+		var refCount int64
+		for _, holder := range c.holders {
+			if refCount, err = h.unrefDedupContent(ctx, kvClient, key, holder); err != nil {
+				return nil, err
+			}
+		}
+		if refCount > 0 {
+			span.Debugf("dedup content %s is still referenced by %d", key, refCount)
+			retained = append(retained, c.shared.Blobs...)
+			continue
+		}
+		h.unmarkDedupSlices(ctx, kvClient, c.shared)
+	}
+
+	if len(retained) == 0 {
+		return location, nil
+	}
+	loc := location.Copy()
+	loc.Blobs = loc.Blobs[:0]
+	for _, slice := range location.Blobs {
+		isRetained := false
+		for _, r := range retained {
+			if isSameSlice(slice, r) {
+				isRetained = true
+				break
+			}
+		}
+		if !isRetained {
+			loc.Blobs = append(loc.Blobs, slice)
+		}
+	}
+	return &loc, nil
+}
+
+// unrefDedupContent returns reference count of the content after dereferenced
+func (h *Handler) unrefDedupContent(ctx context.Context, kvClient controller.KVClient, key, holder string) (int64, error) {
+	ret, err := kvClient.UnrefKV(ctx, key, holder)
+	if err != nil {
+		// dereferenced by others concurrently
+		if rpc.DetectStatusCode(err) == http.StatusNotFound {
+			return 0, nil
+		}
+		trace.SpanFromContextSafe(ctx).Warnf("dedup dereference %s failed %s", key, err.Error())
+		return 0, err
+	}
+	return ret.RefCount, nil
+}
+
+func (h *Handler) unmarkDedupSlices(ctx context.Context, kvClient controller.KVClient, location *access.Location) {
+	span := trace.SpanFromContextSafe(ctx)
+	for _, slice := range location.Blobs {
+		if err := kvClient.DeleteKV(ctx, dedupSliceKey(slice)); err != nil {
+			span.Warnf("dedup unmark slice %+v failed %s", slice, err.Error())
+		}
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/api/access"
+	cmapi "github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
+)
+
+var dedupKV = &memKVClient{
+	kvs:     make(map[string][]byte),
+	refs:    make(map[string]int64),
+	holders: make(map[string]map[string]bool),
+}
+
+type memKVClient struct {
+	sync.Mutex
+	kvs     map[string][]byte
+	refs    map[string]int64
+	holders map[string]map[string]bool
+}
+
+func (m *memKVClient) GetKV(ctx context.Context, key string) (cmapi.GetKvRet, error) {
+	m.Lock()
+	defer m.Unlock()
+	val, ok := m.kvs[key]
+	if !ok {
+		return cmapi.GetKvRet{}, errcode.ErrNotFound
+	}
+	return cmapi.GetKvRet{Value: val}, nil
+}
+
+func (m *memKVClient) SetKV(ctx context.Context, key string, value []byte) error {
+	m.Lock()
+	defer m.Unlock()
+	m.kvs[key] = value
+	return nil
+}
+
+func (m *memKVClient) DeleteKV(ctx context.Context, key string) error {
+	m.Lock()
+	defer m.Unlock()
+	delete(m.kvs, key)
+	delete(m.refs, key)
+	delete(m.holders, key)
+	return nil
+}
+
+func (m *memKVClient) RefKV(ctx context.Context, key, holder string, value []byte) (cmapi.RefKvRet, error) {
+	m.Lock()
+	defer m.Unlock()
+	if _, ok := m.kvs[key]; !ok {
+		m.kvs[key] = value
+		m.holders[key] = make(map[string]bool)
+	}
+	if holder == "" || !m.holders[key][holder] {
+		m.refs[key]++
+	}
+	if holder != "" {
+		m.holders[key][holder] = true
+	}
+	return cmapi.RefKvRet{Value: m.kvs[key], RefCount: m.refs[key]}, nil
+}
+
+func (m *memKVClient) UnrefKV(ctx context.Context, key, holder string) (cmapi.RefKvRet, error) {
+	m.Lock()
+	defer m.Unlock()
+	val, ok := m.kvs[key]
+	if !ok {
+		return cmapi.RefKvRet{}, errcode.ErrNotFound
+	}
+	if holder == "" || m.holders[key][holder] {
+		delete(m.holders[key], holder)
+		m.refs[key]--
+	}
+	ret := cmapi.RefKvRet{Value: val, RefCount: m.refs[key]}
+	if ret.RefCount <= 0 {
+		delete(m.kvs, key)
+		delete(m.refs, key)
+		delete(m.holders, key)
+	}
+	return ret, nil
+}
+
+func (m *memKVClient) exist(key string) bool {
+	m.Lock()
+	defer m.Unlock()
+	_, ok := m.kvs[key]
+	return ok
+}
+
+func (m *memKVClient) refCount(key string) int64 {
+	m.Lock()
+	defer m.Unlock()
+	return m.refs[key]
+}
+
+func TestAccessStreamDedup(t *testing.T) {
+	ctx := ctxWithName("TestAccessStreamDedup")
+	streamer.Dedup = DedupConfig{Enable: true, MinSize: 2}
+	defer func() {
+		streamer.Dedup = DedupConfig{}
+	}()
+
+	data := []byte("dedup content")
+	sum := sha256.Sum256(data)
+	key := dedupContentKey(sum[:], int64(len(data)))
+
+	// not deduplicated smaller object
+	_, err := streamer.Put(ctx(), bytes.NewReader(data[:1]), 1, nil, 0)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(dedupKV.kvs))
+
+	loc1, err := streamer.Put(ctx(), bytes.NewReader(data), int64(len(data)), nil, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), dedupKV.refCount(key))
+	sliceKey := dedupSliceKey(loc1.Blobs[0])
+	require.True(t, dedupKV.exist(sliceKey))
+
+	// the same content is replaced with the shared location tagged with the holder
+	loc2 := loc1.Copy()
+	loc2.Blobs = []access.SliceInfo{{MinBid: 90000, Vid: loc1.Blobs[0].Vid, Count: 1}}
+	tag2 := access.SliceInfo{MinBid: 90000, Vid: loc1.Blobs[0].Vid}
+	shared := streamer.dedupLocation(ctx(), &loc2, sum[:])
+	require.Equal(t, append(loc1.Copy().Blobs, tag2), shared.Blobs)
+	require.Equal(t, int64(2), dedupKV.refCount(key))
+	require.False(t, dedupKV.exist(dedupSliceKey(loc2.Blobs[0])))
+
+	loc3 := loc1.Copy()
+	loc3.Blobs = []access.SliceInfo{{MinBid: 70000, Vid: loc1.Blobs[0].Vid, Count: 1}}
+	loc3 = *streamer.dedupLocation(ctx(), &loc3, sum[:])
+	require.Equal(t, append(loc1.Copy().Blobs, access.SliceInfo{MinBid: 70000, Vid: loc1.Blobs[0].Vid}), loc3.Blobs)
+	require.Equal(t, int64(3), dedupKV.refCount(key))
+	// the retried reference of the first holder
+	require.Equal(t, loc1, streamer.dedupLocation(ctx(), loc1, sum[:]))
+	require.Equal(t, int64(3), dedupKV.refCount(key))
+
+	// retained if referenced by others
+	retained, err := streamer.dedupRetained(ctx(), loc1)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(retained.Blobs))
+	require.Equal(t, int64(2), dedupKV.refCount(key))
+	// the retried delete dereferences nothing
+	require.NoError(t, streamer.Delete(ctx(), loc1))
+	require.Equal(t, int64(2), dedupKV.refCount(key))
+
+	// merged location with other blobs
+	merged := shared.Copy()
+	other := access.SliceInfo{MinBid: 80000, Vid: loc1.Blobs[0].Vid, Count: 1}
+	merged.Blobs = append(merged.Blobs, other)
+	for range [2]struct{}{} {
+		retained, err = streamer.dedupRetained(ctx(), &merged)
+		require.NoError(t, err)
+		require.Equal(t, []access.SliceInfo{tag2, other}, retained.Blobs)
+		require.Equal(t, int64(1), dedupKV.refCount(key))
+	}
+
+	// the last reference
+	retained, err = streamer.dedupRetained(ctx(), &loc3)
+	require.NoError(t, err)
+	require.Equal(t, loc3.Blobs, retained.Blobs)
+	require.False(t, dedupKV.exist(key))
+	require.False(t, dedupKV.exist(sliceKey))
+
+	// stale mark of slice
+	value, _ := json.Marshal(&loc2)
+	dedupKV.RefKV(ctx(), key, dedupSliceKey(loc2.Blobs[0]), value)
+	dedupKV.SetKV(ctx(), sliceKey, []byte(key))
+	retained, err = streamer.dedupRetained(ctx(), loc1)
+	require.NoError(t, err)
+	require.Equal(t, loc1.Blobs, retained.Blobs)
+	require.Equal(t, int64(1), dedupKV.refCount(key))
+	dedupKV.DeleteKV(ctx(), key)
+	dedupKV.DeleteKV(ctx(), sliceKey)
+}
+
+func TestAccessStreamDedupReplayedDelete(t *testing.T) {
+	ctx := ctxWithName("TestAccessStreamDedupReplayedDelete")
+	streamer.Dedup = DedupConfig{Enable: true, MinSize: 2}
+	defer func() {
+		streamer.Dedup = DedupConfig{}
+	}()
+
+	data := []byte("dedup replayed delete")
+	sum := sha256.Sum256(data)
+	key := dedupContentKey(sum[:], int64(len(data)))
+
+	loc1, err := streamer.Put(ctx(), bytes.NewReader(data), int64(len(data)), nil, 0)
+	require.NoError(t, err)
+	// the second holder puts the same content into another blob
+	loc2 := loc1.Copy()
+	loc2.Blobs = []access.SliceInfo{{MinBid: 91000, Vid: loc1.Blobs[0].Vid, Count: 1}}
+	loc2 = *streamer.dedupLocation(ctx(), &loc2, sum[:])
+	require.Equal(t, loc1.Blobs, loc2.Blobs[:len(loc1.Blobs)])
+	require.Equal(t, int64(2), dedupKV.refCount(key))
+
+	// the same delete of the first holder twice
+	require.NoError(t, streamer.Delete(ctx(), loc1))
+	require.NoError(t, streamer.Delete(ctx(), loc1))
+	require.Equal(t, int64(1), dedupKV.refCount(key))
+	require.True(t, dedupKV.exist(dedupSliceKey(loc1.Blobs[0])))
+
+	// the second holder still reads the content
+	buff := bytes.NewBuffer(nil)
+	transfer, err := streamer.Get(ctx(), buff, loc2, uint64(len(data)), 0)
+	require.NoError(t, err)
+	require.NoError(t, transfer())
+	require.True(t, dataEqual(data, buff.Bytes()))
+
+	require.NoError(t, streamer.Delete(ctx(), &loc2))
+	require.NoError(t, streamer.Delete(ctx(), &loc2))
+	require.False(t, dedupKV.exist(key))
+	require.False(t, dedupKV.exist(dedupSliceKey(loc1.Blobs[0])))
+}
@@ -0,0 +1,90 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/cubefs/cubefs/blobstore/api/access"
+	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/util/errors"
+)
+
+func checkExpire(expire int64) error {
+	if expire < 0 || (expire > 0 && expire <= time.Now().Unix()) {
+		return errcode.ErrIllegalArguments
+	}
+	return nil
+}
+
+// recordExpiring records slices of the expiring location into kv of cluster manager,
+// scheduler deletes the blobs once the expiry passes.
+func (h *Handler) recordExpiring(ctx context.Context, location *access.Location) error {
+	if location.Expire <= 0 {
+		return nil
+	}
+
+	span := trace.SpanFromContextSafe(ctx)
+	kvClient, err := h.clusterController.GetKVClient(location.ClusterID)
+	if err != nil {
+		span.Error("expire get kv client failed", errors.Detail(err))
+		return err
+	}
+
+	for _, slice := range location.Blobs {
+		blob := proto.ExpiringBlob{
+			Vid:    slice.Vid,
+			MinBid: slice.MinBid,
+			Count:  slice.Count,
+			Expire: location.Expire,
+		}
+		value, err := json.Marshal(blob)
+		if err != nil {
+			return err
+		}
+		if err = kvClient.SetKV(ctx, blob.Key(), value); err != nil {
+			span.Errorf("expire record %+v failed %s", blob, err.Error())
+			return err
+		}
+	}
+	span.Debugf("expire recorded %+v", location)
+	return nil
+}
+
+// unrecordExpiring removes records of the expiring location deleted before the expiry,
+// the stale records only make scheduler deleting the deleted blobs again.
+func (h *Handler) unrecordExpiring(ctx context.Context, location *access.Location) {
+	if location.Expire <= 0 {
+		return
+	}
+
+	span := trace.SpanFromContextSafe(ctx)
+	kvClient, err := h.clusterController.GetKVClient(location.ClusterID)
+	if err != nil {
+		span.Warn("expire get kv client failed", errors.Detail(err))
+		return
+	}
+
+	for _, slice := range location.Blobs {
+		blob := proto.ExpiringBlob{Vid: slice.Vid, MinBid: slice.MinBid, Expire: location.Expire}
+		if err = kvClient.DeleteKV(ctx, blob.Key()); err != nil {
+			span.Warnf("expire unrecord %+v failed %s", blob, err.Error())
+		}
+	}
+}
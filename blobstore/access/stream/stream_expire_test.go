@@ -0,0 +1,100 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+)
+
+func TestAccessStreamExpire(t *testing.T) {
+	ctx := ctxWithName("TestAccessStreamExpire")
+
+	dataShards.clean()
+	data := []byte("expiring data")
+
+	_, err := streamer.Put(ctx(), bytes.NewReader(data), int64(len(data)), nil, -1)
+	require.ErrorIs(t, err, errcode.ErrIllegalArguments)
+	_, err = streamer.Put(ctx(), bytes.NewReader(data), int64(len(data)), nil, time.Now().Unix()-1)
+	require.ErrorIs(t, err, errcode.ErrIllegalArguments)
+	_, err = streamer.Alloc(ctx(), 1<<20, 0, 0, 0, time.Now().Unix()-1)
+	require.ErrorIs(t, err, errcode.ErrIllegalArguments)
+
+	expire := time.Now().Add(time.Hour).Unix()
+	loc, err := streamer.Put(ctx(), bytes.NewReader(data), int64(len(data)), nil, expire)
+	require.NoError(t, err)
+	require.Equal(t, expire, loc.Expire)
+
+	blob := proto.ExpiringBlob{Vid: loc.Blobs[0].Vid, MinBid: loc.Blobs[0].MinBid, Expire: expire}
+	require.True(t, dedupKV.exist(blob.Key()))
+	ret, err := dedupKV.GetKV(ctx(), blob.Key())
+	require.NoError(t, err)
+	var recorded proto.ExpiringBlob
+	require.NoError(t, json.Unmarshal(ret.Value, &recorded))
+	require.Equal(t, loc.Blobs[0].Count, recorded.Count)
+
+	// readable before expiry
+	{
+		buff := bytes.NewBuffer(nil)
+		transfer, err := streamer.Get(ctx(), buff, *loc, uint64(len(data)), 0)
+		require.NoError(t, err)
+		require.NoError(t, transfer())
+		require.True(t, dataEqual(data, buff.Bytes()))
+	}
+	// refused after expiry
+	{
+		expired := loc.Copy()
+		expired.Expire = time.Now().Unix()
+		_, err := streamer.Get(ctx(), bytes.NewBuffer(nil), expired, uint64(len(data)), 0)
+		require.ErrorIs(t, err, errcode.ErrAccessBlobExpired)
+	}
+
+	require.NoError(t, streamer.Delete(ctx(), loc))
+	require.False(t, dedupKV.exist(blob.Key()))
+
+	// the record has been moved with the compacted volume
+	{
+		loc, err := streamer.Put(ctx(), bytes.NewReader(data), int64(len(data)), nil, expire)
+		require.NoError(t, err)
+		moved := proto.ExpiringBlob{Vid: loc.Blobs[0].Vid, MinBid: loc.Blobs[0].MinBid, Expire: expire}
+		require.True(t, dedupKV.exist(moved.Key()))
+
+		var sourceVid proto.Vid = 9004
+		compactedLoc := loc.Copy()
+		compactedLoc.Blobs[0].Vid = sourceVid
+		compactedVolumes[sourceVid] = &proto.CompactedVolume{SourceVid: sourceVid, TargetVid: loc.Blobs[0].Vid}
+		defer delete(compactedVolumes, sourceVid)
+
+		require.NoError(t, streamer.Delete(ctx(), &compactedLoc))
+		require.False(t, dedupKV.exist(moved.Key()))
+	}
+
+	// allocated location
+	loc, err = streamer.Alloc(ctx(), 1<<20, 0, 0, 0, expire)
+	require.NoError(t, err)
+	require.Equal(t, expire, loc.Expire)
+	for _, slice := range loc.Blobs {
+		blob := proto.ExpiringBlob{Vid: slice.Vid, MinBid: slice.MinBid, Expire: expire}
+		require.True(t, dedupKV.exist(blob.Key()))
+	}
+	require.NoError(t, streamer.Delete(ctx(), loc))
+}
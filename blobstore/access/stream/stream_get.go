@@ -69,10 +69,11 @@ type shardData struct {
 }
 
 type sortedVuid struct {
-	index  int
-	vuid   proto.Vuid
-	diskID proto.DiskID
-	host   string
+	index   int
+	vuid    proto.Vuid
+	diskID  proto.DiskID
+	host    string
+	latency controller.Latency
 }
 
 func (vuid *sortedVuid) ID() string {
@@ -114,6 +115,22 @@ func (h *Handler) Get(ctx context.Context, w io.Writer, location access.Location
 	span := trace.SpanFromContextSafe(ctx)
 	span.Debugf("get request cluster:%d size:%d offset:%d", location.ClusterID, readSize, offset)
 
+	if location.IsExpired(time.Now()) {
+		span.Infof("location has expired at %d", location.Expire)
+		return func() error { return nil }, errcode.ErrAccessBlobExpired
+	}
+
+	loc, err := h.redirectMigrated(ctx, &location)
+	if err != nil {
+		span.Error("redirect migrated location", errors.Detail(err))
+		return func() error { return nil }, err
+	}
+	if loc, err = h.redirectCompacted(ctx, loc); err != nil {
+		span.Error("redirect compacted location", errors.Detail(err))
+		return func() error { return nil }, err
+	}
+	location = *loc
+
 	blobs, err := genLocationBlobs(&location, readSize, offset)
 	if err != nil {
 		span.Info("illegal argument", err)
@@ -319,8 +336,28 @@ func (h *Handler) readOneBlob(ctx context.Context, getTime *timeReadWrite,
 	}
 	shardSize, shardOffset, shardReadSize := blob.ShardSize, blob.ShardOffset, blob.ShardReadSize
 
+	// hedged shards are read from the rest vuids if exceeded the latency budget
+	var (
+		hedgeTimer *time.Timer
+		hedgeC     <-chan time.Time
+		hedgeDelay time.Duration
+	)
+	hedged, maxHedged := 0, 0
+	if h.HedgedRead.Enable && h.HedgedRead.MaxShards > 0 {
+		maxHedged = len(sortedVuids) - minShardsRead
+		if h.HedgedRead.MaxShards < maxHedged {
+			maxHedged = h.HedgedRead.MaxShards
+		}
+	}
+	if maxHedged > 0 {
+		hedgeDelay = h.hedgeDelay(sortedVuids[:minShardsRead])
+		hedgeTimer = time.NewTimer(hedgeDelay)
+		defer hedgeTimer.Stop()
+		hedgeC = hedgeTimer.C
+	}
+
 	stopChan := make(chan struct{})
-	nextChan := make(chan struct{}, len(sortedVuids))
+	nextChan := make(chan struct{}, len(sortedVuids)+maxHedged)
 	shardPipe := func() <-chan shardData {
 		ch := make(chan shardData)
 		go func() {
@@ -370,7 +407,29 @@ func (h *Handler) readOneBlob(ctx context.Context, getTime *timeReadWrite,
 
 	startRead := time.Now()
 	reconstructed := false
-	for shard := range shardPipe {
+	for {
+		var (
+			shard shardData
+			ok    bool
+		)
+		select {
+		case <-hedgeC:
+			hedged++
+			span.Debugf("%s hedged read %d after %s", blob.ID(), hedged, hedgeDelay)
+			reportHedgedRead(blob.Cid, "sent")
+			nextChan <- struct{}{}
+			if hedged < maxHedged {
+				hedgeTimer.Reset(hedgeDelay)
+			} else {
+				hedgeC = nil
+			}
+			continue
+		case shard, ok = <-shardPipe:
+		}
+		if !ok {
+			break
+		}
+
 		// swap shard buffer
 		if shard.status {
 			buf := shards[shard.index]
@@ -446,6 +505,13 @@ func (h *Handler) readOneBlob(ctx context.Context, getTime *timeReadWrite,
 		nextChan <- struct{}{}
 	}
 	getTime.IncR(time.Since(startRead))
+	if hedged > 0 {
+		if reconstructed {
+			reportHedgedRead(blob.Cid, "done")
+		} else {
+			reportHedgedRead(blob.Cid, "failed")
+		}
+	}
 
 	// release buffer of delayed shards
 	go func() {
@@ -641,13 +707,19 @@ func (h *Handler) getOneShardFromHost(ctx context.Context, serviceController con
 			context.Background(), "GetFromBlobnode", span.TraceID())
 		defer spanChild.Finish()
 
+		startGet := time.Now()
 		body, _, err := h.blobnodeClient.RangeGetShard(ctxChild, host, &args)
 		if err == nil {
+			serviceController.ReportDiskLatency(ctx, diskID, time.Since(startGet))
 			rbody = body
 			return true, nil
 		}
 
 		code := rpc.DetectStatusCode(err)
+		// failures and timeouts slow the disk down, except the outdated volume info
+		if code != errcode.CodeDiskNotFound && code != errcode.CodeVuidNotFound {
+			serviceController.ReportDiskFailure(ctx, diskID, time.Since(startGet))
+		}
 		switch code {
 		case errcode.CodeOverload:
 			return true, err
@@ -801,10 +873,11 @@ func genSortedVuidByIDC(ctx context.Context,
 			sortMap[dis] = make([]sortedVuid, 0, 8)
 		}
 		sortMap[dis] = append(sortMap[dis], sortedVuid{
-			index:  idx,
-			vuid:   phy.Vuid,
-			diskID: phy.DiskID,
-			host:   phy.Host,
+			index:   idx,
+			vuid:    phy.Vuid,
+			diskID:  phy.DiskID,
+			host:    phy.Host,
+			latency: hostIDC.Latency,
 		})
 	}
 
@@ -819,6 +892,7 @@ func genSortedVuidByIDC(ctx context.Context,
 		rand.Shuffle(len(ids), func(i, j int) {
 			ids[i], ids[j] = ids[j], ids[i]
 		})
+		sortByLatency(ids)
 		vuids = append(vuids, ids...)
 		if dis > 1 {
 			span.Debugf("distance: %d punished vuids: %+v", dis, ids)
@@ -828,6 +902,30 @@ func genSortedVuidByIDC(ctx context.Context,
 	return vuids
 }
 
+// sortByLatency sorts faster disks first, disks of unknown latency are scored by the
+// median latency of the others, so that they are neither preferred nor left behind.
+func sortByLatency(vuids []sortedVuid) {
+	known := make([]time.Duration, 0, len(vuids))
+	for _, vuid := range vuids {
+		if vuid.latency.EWMA > 0 {
+			known = append(known, vuid.latency.EWMA)
+		}
+	}
+	var neutral time.Duration
+	if len(known) > 0 {
+		sort.Slice(known, func(i, j int) bool { return known[i] < known[j] })
+		neutral = known[len(known)/2]
+	}
+
+	score := func(vuid sortedVuid) time.Duration {
+		if vuid.latency.EWMA > 0 {
+			return vuid.latency.EWMA
+		}
+		return neutral
+	}
+	sort.SliceStable(vuids, func(i, j int) bool { return score(vuids[i]) < score(vuids[j]) })
+}
+
 func distance(idc1, idc2 string, punished bool) int {
 	if punished {
 		if idc1 == idc2 {
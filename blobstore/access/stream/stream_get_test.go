@@ -24,6 +24,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/cubefs/cubefs/blobstore/access/controller"
 	"github.com/cubefs/cubefs/blobstore/api/access"
 	"github.com/cubefs/cubefs/blobstore/common/codemode"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
@@ -35,7 +36,7 @@ func TestAccessStreamGetBase(t *testing.T) {
 	{
 		dataShards.clean()
 		data := []byte("x")
-		loc, err := streamer.Put(ctx(), bytes.NewReader(data), int64(len(data)), nil)
+		loc, err := streamer.Put(ctx(), bytes.NewReader(data), int64(len(data)), nil, 0)
 		require.NoError(t, err)
 
 		buff := bytes.NewBuffer(nil)
@@ -48,7 +49,7 @@ func TestAccessStreamGetBase(t *testing.T) {
 	{
 		dataShards.clean()
 		data := []byte("x")
-		loc, err := streamer.Put(ctx(), bytes.NewReader(data), int64(len(data)), nil)
+		loc, err := streamer.Put(ctx(), bytes.NewReader(data), int64(len(data)), nil, 0)
 		require.NoError(t, err)
 
 		buff := bytes.NewBuffer(nil)
@@ -84,7 +85,7 @@ func TestAccessStreamGetBase(t *testing.T) {
 		size := cs.size
 		data := make([]byte, size)
 		rand.Read(data)
-		loc, err := streamer.Put(ctx(), bytes.NewReader(data), int64(size), nil)
+		loc, err := streamer.Put(ctx(), bytes.NewReader(data), int64(size), nil, 0)
 		require.NoError(t, err)
 
 		buff := bytes.NewBuffer(nil)
@@ -118,7 +119,7 @@ func TestAccessStreamGetBroken(t *testing.T) {
 	rand.Read(data)
 	// time wait the punished services
 	time.Sleep(time.Second * time.Duration(punishServiceS))
-	loc, err := streamer.Put(ctx(), bytes.NewReader(data), int64(size), nil)
+	loc, err := streamer.Put(ctx(), bytes.NewReader(data), int64(size), nil, 0)
 	require.NoError(t, err)
 
 	cases := []struct {
@@ -184,7 +185,7 @@ func TestAccessStreamGetOffset(t *testing.T) {
 		size := cs.size
 		data := make([]byte, size)
 		rand.Read(data)
-		loc, err := streamer.Put(ctx(), bytes.NewReader(data), size, nil)
+		loc, err := streamer.Put(ctx(), bytes.NewReader(data), size, nil, 0)
 		require.NoError(t, err)
 
 		buff := bytes.NewBuffer(nil)
@@ -211,7 +212,7 @@ func TestAccessStreamGetShardTimeout(t *testing.T) {
 	size := 1 << 22
 	buff := make([]byte, size)
 	rand.Read(buff)
-	loc, err := streamer.Put(ctx(), bytes.NewReader(buff), int64(size), nil)
+	loc, err := streamer.Put(ctx(), bytes.NewReader(buff), int64(size), nil, 0)
 	require.NoError(t, err)
 
 	// no delay when blocking one shard, cos MinReadShardsX = 1
@@ -263,7 +264,7 @@ func TestAccessStreamGetShardBroken(t *testing.T) {
 	size := 1 << 22
 	buff := make([]byte, size)
 	rand.Read(buff)
-	loc, err := streamer.Put(ctx(), bytes.NewReader(buff), int64(size), nil)
+	loc, err := streamer.Put(ctx(), bytes.NewReader(buff), int64(size), nil, 0)
 	require.NoError(t, err)
 
 	// no delay when blocking one shard, cos MinReadShardsX = 1
@@ -303,7 +304,7 @@ func TestAccessStreamGetLocalIDC(t *testing.T) {
 	size := 1 << 22
 	buff := make([]byte, size)
 	rand.Read(buff)
-	loc, err := streamer.Put(ctx(), bytes.NewReader(buff), int64(size), nil)
+	loc, err := streamer.Put(ctx(), bytes.NewReader(buff), int64(size), nil, 0)
 	require.NoError(t, err)
 
 	// no delay when blocking other idc all shards
@@ -414,7 +415,7 @@ func TestAccessStreamGetAligned(t *testing.T) {
 
 		data := make([]byte, cs.size)
 		rand.Read(data)
-		loc, err := streamer.Put(ctx(), bytes.NewReader(data), int64(cs.size), nil)
+		loc, err := streamer.Put(ctx(), bytes.NewReader(data), int64(cs.size), nil, 0)
 		require.NoError(t, err)
 
 		// cos put shards asynchronously, should wait all shard written
@@ -594,7 +595,7 @@ func BenchmarkAccessStreamGet(b *testing.B) {
 	for _, cs := range cases {
 		b.ResetTimer()
 		b.Run(cs.name, func(b *testing.B) {
-			loc, err := streamer.Put(ctx, newReader(cs.size), int64(cs.size), nil)
+			loc, err := streamer.Put(ctx, newReader(cs.size), int64(cs.size), nil, 0)
 			require.NoError(b, err)
 
 			b.ResetTimer()
@@ -605,3 +606,91 @@ func BenchmarkAccessStreamGet(b *testing.B) {
 		})
 	}
 }
+
+func TestAccessStreamGetHedged(t *testing.T) {
+	ctx := ctxWithName("TestAccessStreamGetHedged")
+	dataShards.clean()
+	vuidController.Unbreak(1005)
+	streamer.MinReadShardsX = defaultMinReadShardsX
+	streamer.HedgedRead = HedgedReadConfig{Enable: true, DelayMs: 20, MaxShards: 2}
+	defer func() {
+		vuidController.Break(1005)
+		streamer.MinReadShardsX = minReadShardsX
+		streamer.HedgedRead = HedgedReadConfig{}
+		dataShards.clean()
+	}()
+
+	size := 1 << 22
+	buff := make([]byte, size)
+	rand.Read(buff)
+	loc, err := streamer.Put(ctx(), bytes.NewReader(buff), int64(size), nil, 0)
+	require.NoError(t, err)
+
+	// no delay of blocked shards, cos hedged read two more shards
+	vuidController.Block(1001)
+	vuidController.Block(1002)
+	defer func() {
+		vuidController.Unblock(1001)
+		vuidController.Unblock(1002)
+	}()
+	{
+		startTime := time.Now()
+		buffer := bytes.NewBuffer(nil)
+		transfer, _ := streamer.Get(ctx(), buffer, *loc, uint64(size), 0)
+		require.NoError(t, transfer())
+		require.True(t, dataEqual(buff, buffer.Bytes()))
+
+		duration := time.Since(startTime)
+		t.Log(duration, vuidController.duration)
+		require.Greater(t, vuidController.duration, duration, "greater duration: ", duration)
+	}
+}
+
+func TestAccessStreamHedgeDelay(t *testing.T) {
+	h := &Handler{StreamConfig: StreamConfig{HedgedRead: HedgedReadConfig{
+		MinDelayMs: 10,
+		MaxDelayMs: 100,
+	}}}
+	ms := time.Millisecond
+	vuids := []sortedVuid{
+		{latency: controller.Latency{EWMA: ms, P99: 2 * ms}},
+		{latency: controller.Latency{EWMA: ms, P99: 20 * ms}},
+	}
+	require.Equal(t, 20*ms, h.hedgeDelay(vuids))
+	require.Equal(t, 10*ms, h.hedgeDelay(vuids[:1]))
+
+	vuids[0].latency.P99 = time.Second
+	require.Equal(t, 100*ms, h.hedgeDelay(vuids))
+	vuids = append(vuids, sortedVuid{})
+	vuids[0].latency.P99 = ms
+	require.Equal(t, 100*ms, h.hedgeDelay(vuids), "unknown latency")
+
+	h.HedgedRead.DelayMs = 50
+	require.Equal(t, 50*ms, h.hedgeDelay(vuids))
+}
+
+func TestAccessStreamSortByLatency(t *testing.T) {
+	ms := time.Millisecond
+	ids := func(vuids []sortedVuid) (r []proto.Vuid) {
+		for _, vuid := range vuids {
+			r = append(r, vuid.vuid)
+		}
+		return
+	}
+
+	// unknown latency is scored by the median of known latency
+	vuids := []sortedVuid{
+		{vuid: 1},
+		{vuid: 2, latency: controller.Latency{EWMA: 30 * ms}},
+		{vuid: 3, latency: controller.Latency{EWMA: 10 * ms}},
+		{vuid: 4, latency: controller.Latency{EWMA: 20 * ms}},
+		{vuid: 5},
+	}
+	sortByLatency(vuids)
+	require.Equal(t, []proto.Vuid{3, 1, 4, 5, 2}, ids(vuids))
+
+	// keep the order if all are unknown
+	vuids = []sortedVuid{{vuid: 3}, {vuid: 1}, {vuid: 2}}
+	sortByLatency(vuids)
+	require.Equal(t, []proto.Vuid{3, 1, 2}, ids(vuids))
+}
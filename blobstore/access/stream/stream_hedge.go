@@ -0,0 +1,61 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"time"
+)
+
+// HedgedReadConfig hedged read of shards, reads one more shard from the next
+// fastest disk if shards of a blob have not been read in the latency budget.
+//
+// The budget is DelayMs if configured, otherwise it is the max p99 latency of
+// the reading disks, bounded by MinDelayMs and MaxDelayMs.
+// MaxDelayMs is used if latency of any reading disk is unknown.
+type HedgedReadConfig struct {
+	Enable     bool `json:"enable"`
+	DelayMs    int  `json:"delay_ms"`
+	MinDelayMs int  `json:"min_delay_ms"`
+	MaxDelayMs int  `json:"max_delay_ms"`
+	// the most hedged shards of reading one blob
+	MaxShards int `json:"max_shards"`
+}
+
+// hedgeDelay returns the latency budget of reading shards on the vuids
+func (h *Handler) hedgeDelay(vuids []sortedVuid) time.Duration {
+	cfg := h.HedgedRead
+	if cfg.DelayMs > 0 {
+		return time.Duration(cfg.DelayMs) * time.Millisecond
+	}
+
+	minDelay := time.Duration(cfg.MinDelayMs) * time.Millisecond
+	maxDelay := time.Duration(cfg.MaxDelayMs) * time.Millisecond
+	delay := time.Duration(0)
+	for _, vuid := range vuids {
+		if vuid.latency.P99 <= 0 {
+			return maxDelay
+		}
+		if vuid.latency.P99 > delay {
+			delay = vuid.latency.P99
+		}
+	}
+	if delay < minDelay {
+		return minDelay
+	}
+	if delay > maxDelay {
+		return maxDelay
+	}
+	return delay
+}
@@ -0,0 +1,72 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"context"
+
+	"github.com/cubefs/cubefs/blobstore/access/controller"
+	"github.com/cubefs/cubefs/blobstore/api/access"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+)
+
+func (h *Handler) isRetiredCluster(clusterID proto.ClusterID) bool {
+	for _, cluster := range h.clusterController.All() {
+		if cluster.ClusterID == clusterID {
+			return false
+		}
+	}
+	return true
+}
+
+// redirectMigrated returns the location in target cluster if the cluster of
+// location has been retired and all volumes of it have been migrated,
+// otherwise the location is returned as it is.
+func (h *Handler) redirectMigrated(ctx context.Context, location *access.Location) (*access.Location, error) {
+	if !h.isRetiredCluster(location.ClusterID) {
+		return location, nil
+	}
+	span := trace.SpanFromContextSafe(ctx)
+
+	migrated := make(map[proto.Vid]*proto.MigratedVolume)
+	for _, blob := range location.Blobs {
+		if _, ok := migrated[blob.Vid]; ok {
+			continue
+		}
+		volume, err := h.clusterController.GetMigratedVolume(ctx, location.ClusterID, blob.Vid)
+		if err != nil {
+			if err == controller.ErrNoMigratedVolume {
+				span.Warnf("volume %d of retired cluster %d has not been migrated", blob.Vid, location.ClusterID)
+				return location, nil
+			}
+			return nil, err
+		}
+		migrated[blob.Vid] = volume
+	}
+
+	loc := location.Copy()
+	for idx, blob := range loc.Blobs {
+		volume := migrated[blob.Vid]
+		if idx > 0 && volume.TargetClusterID != loc.ClusterID {
+			span.Warnf("volumes of location %+v are migrated into different clusters", location)
+			return location, nil
+		}
+		loc.ClusterID = volume.TargetClusterID
+		loc.Blobs[idx].Vid = volume.TargetVid
+	}
+	span.Debugf("redirect location of retired cluster %d to %+v", location.ClusterID, loc)
+	return &loc, nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+)
+
+func TestAccessStreamRedirectMigrated(t *testing.T) {
+	ctx := ctxWithName("TestAccessStreamRedirectMigrated")
+
+	dataShards.clean()
+	data := []byte("migrated data")
+	loc, err := streamer.Put(ctx(), bytes.NewReader(data), int64(len(data)), nil, 0)
+	require.NoError(t, err)
+
+	// location in cluster which is not retired
+	{
+		newLoc, err := streamer.redirectMigrated(ctx(), loc)
+		require.NoError(t, err)
+		require.Equal(t, loc, newLoc)
+	}
+
+	var retiredClusterID proto.ClusterID = 9
+	var sourceVid proto.Vid = 9001
+	retiredLoc := loc.Copy()
+	retiredLoc.ClusterID = retiredClusterID
+	retiredLoc.Blobs[0].Vid = sourceVid
+
+	// volume has not been migrated
+	{
+		newLoc, err := streamer.redirectMigrated(ctx(), &retiredLoc)
+		require.NoError(t, err)
+		require.Equal(t, retiredLoc, *newLoc)
+	}
+
+	migratedVolumes[sourceVid] = &proto.MigratedVolume{
+		SourceClusterID: retiredClusterID,
+		SourceVid:       sourceVid,
+		TargetClusterID: clusterID,
+		TargetVid:       loc.Blobs[0].Vid,
+	}
+	defer delete(migratedVolumes, sourceVid)
+
+	{
+		newLoc, err := streamer.redirectMigrated(ctx(), &retiredLoc)
+		require.NoError(t, err)
+		require.Equal(t, *loc, *newLoc)
+		require.Equal(t, retiredClusterID, retiredLoc.ClusterID)
+		require.Equal(t, sourceVid, retiredLoc.Blobs[0].Vid)
+	}
+	// read data of retired cluster from the target cluster
+	{
+		buff := bytes.NewBuffer(nil)
+		transfer, err := streamer.Get(ctx(), buff, retiredLoc, uint64(len(data)), 0)
+		require.NoError(t, err)
+		require.NoError(t, transfer())
+		require.True(t, dataEqual(data, buff.Bytes()))
+	}
+	require.NoError(t, streamer.Delete(ctx(), &retiredLoc))
+}
@@ -15,7 +15,7 @@
 package stream
 
 // github.com/cubefs/cubefs/blobstore/access/... module access interfaces
-//go:generate mockgen -destination=./controller_mock_test.go -package=stream -mock_names ClusterController=MockClusterController,ServiceController=MockServiceController,VolumeGetter=MockVolumeGetter github.com/cubefs/cubefs/blobstore/access/controller ClusterController,ServiceController,VolumeGetter
+//go:generate mockgen -destination=./controller_mock_test.go -package=stream -mock_names ClusterController=MockClusterController,ServiceController=MockServiceController,VolumeGetter=MockVolumeGetter,KVClient=MockKVClient github.com/cubefs/cubefs/blobstore/access/controller ClusterController,ServiceController,VolumeGetter,KVClient
 
 import (
 	"bytes"
@@ -78,6 +78,9 @@ var (
 	volumeGetter      controller.VolumeGetter
 	serviceController controller.ServiceController
 	cc                controller.ClusterController
+	convertedVolumes  = make(map[proto.Vid]*proto.ConvertedVolume)
+	migratedVolumes   = make(map[proto.Vid]*proto.MigratedVolume)
+	compactedVolumes  = make(map[proto.Vid]*proto.CompactedVolume)
 
 	clusterInfo *clustermgr.ClusterInfo
 	dataVolume  *proxy.VersionVolume
@@ -375,6 +378,7 @@ func initMockData() {
 	ctr = gomock.NewController(&testing.T{})
 	c := NewMockClusterController(ctr)
 	c.EXPECT().Region().AnyTimes().Return("test-region")
+	c.EXPECT().All().AnyTimes().Return([]*clustermgr.ClusterInfo{clusterInfo})
 	c.EXPECT().ChooseOne().AnyTimes().Return(clusterInfo, nil)
 	c.EXPECT().GetServiceController(gomock.Any()).AnyTimes().Return(serviceController, nil)
 	c.EXPECT().GetVolumeGetter(gomock.Any()).AnyTimes().Return(volumeGetter, nil)
@@ -385,6 +389,37 @@ func initMockData() {
 			}
 			return controller.ErrInvalidChooseAlg
 		})
+	c.EXPECT().GetKVClient(gomock.Any()).AnyTimes().Return(dedupKV, nil)
+	c.EXPECT().GetConvertedVolume(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(_ context.Context, _ proto.ClusterID, vid proto.Vid) (*proto.ConvertedVolume, error) {
+			if converted, ok := convertedVolumes[vid]; ok {
+				return converted, nil
+			}
+			return nil, errcode.ErrNotFound
+		})
+	c.EXPECT().GetConvertedTargetVolume(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(_ context.Context, _ proto.ClusterID, vid proto.Vid) (*proto.ConvertedVolume, error) {
+			for _, converted := range convertedVolumes {
+				if converted.TargetVid == vid {
+					return converted, nil
+				}
+			}
+			return nil, errcode.ErrNotFound
+		})
+	c.EXPECT().GetMigratedVolume(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(_ context.Context, _ proto.ClusterID, vid proto.Vid) (*proto.MigratedVolume, error) {
+			if migrated, ok := migratedVolumes[vid]; ok {
+				return migrated, nil
+			}
+			return nil, controller.ErrNoMigratedVolume
+		})
+	c.EXPECT().GetCompactedVolume(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(_ context.Context, _ proto.ClusterID, vid proto.Vid) (*proto.CompactedVolume, error) {
+			if compacted, ok := compactedVolumes[vid]; ok {
+				return compacted, nil
+			}
+			return nil, controller.ErrNoCompactedVolume
+		})
 	cc = c
 
 	ctr = gomock.NewController(&testing.T{})
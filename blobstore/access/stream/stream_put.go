@@ -17,6 +17,7 @@ package stream
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"hash/crc32"
 	"io"
@@ -28,6 +29,7 @@ import (
 
 	"github.com/cubefs/cubefs/blobstore/api/access"
 	"github.com/cubefs/cubefs/blobstore/api/blobnode"
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
 	"github.com/cubefs/cubefs/blobstore/common/ec"
 	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
 	"github.com/cubefs/cubefs/blobstore/common/rpc"
@@ -43,15 +45,19 @@ import (
 //
 //	required: size, file size
 //	optional: hasher map to calculate hash.Hash
+//	          expire > 0, the blobs are deleted after the unix time
 func (h *Handler) Put(ctx context.Context,
-	rc io.Reader, size int64, hasherMap access.HasherMap,
+	rc io.Reader, size int64, hasherMap access.HasherMap, expire int64,
 ) (*access.Location, error) {
 	span := trace.SpanFromContextSafe(ctx)
-	span.Debugf("put request size:%d hashes:b(%b)", size, hasherMap.ToHashAlgorithm())
+	span.Debugf("put request size:%d hashes:b(%b) expire:%d", size, hasherMap.ToHashAlgorithm(), expire)
 
 	if size <= 0 {
 		return nil, errcode.ErrIllegalArguments
 	}
+	if err := checkExpire(expire); err != nil {
+		return nil, err
+	}
 	if size > h.maxObjectSize {
 		span.Info("exceed max object size", h.maxObjectSize)
 		return nil, errcode.ErrAccessExceedSize
@@ -65,6 +71,23 @@ func (h *Handler) Put(ctx context.Context,
 	// 2.choose cluster and alloc volume from allocator
 	selectedCodeMode := h.allCodeModes.SelectCodeMode(size)
 	span.Debugf("select codemode %d", selectedCodeMode)
+	// the shared location of dedup has no expiry
+	if !h.dedupEnabled(size) || expire > 0 {
+		return h.putWithCodeMode(ctx, rc, size, selectedCodeMode, expire)
+	}
+
+	hasher := sha256.New()
+	location, err := h.putWithCodeMode(ctx, io.TeeReader(rc, hasher), size, selectedCodeMode, 0)
+	if err != nil {
+		return nil, err
+	}
+	return h.dedupLocation(ctx, location, hasher.Sum(nil)), nil
+}
+
+func (h *Handler) putWithCodeMode(ctx context.Context,
+	rc io.Reader, size int64, selectedCodeMode codemode.CodeMode, expire int64,
+) (*access.Location, error) {
+	span := trace.SpanFromContextSafe(ctx)
 
 	blobSize := atomic.LoadUint32(&h.MaxBlobSize)
 	clusterID, blobs, err := h.allocFromAllocatorWithHystrix(ctx, selectedCodeMode, uint64(size), blobSize, 0)
@@ -82,6 +105,7 @@ func (h *Handler) Put(ctx context.Context,
 		Size:      uint64(size),
 		BlobSize:  blobSize,
 		Blobs:     blobs,
+		Expire:    expire,
 	}
 
 	uploadSucc := false
@@ -91,9 +115,15 @@ func (h *Handler) Put(ctx context.Context,
 			if err := h.clearGarbage(ctx, location); err != nil {
 				span.Warn(errors.Detail(err))
 			}
+			h.unrecordExpiring(ctx, location)
 		}
 	}()
 
+	// record before writing, so that blobs of an interrupted put are deleted after all
+	if err = h.recordExpiring(ctx, location); err != nil {
+		return nil, err
+	}
+
 	var buffer *ec.Buffer
 	putTime := new(timeReadWrite)
 	defer func() {
@@ -154,7 +184,7 @@ func (h *Handler) Put(ctx context.Context,
 		buffer = nil
 		<-ready
 		startWrite := time.Now()
-		err = h.writeToBlobnodesWithHystrix(ctx, blobident, shards, func() {
+		err = h.writeToBlobnodesWithHystrix(ctx, blobident, expire, shards, func() {
 			takeoverBuffer.Release()
 			ready <- struct{}{}
 		})
@@ -169,12 +199,12 @@ func (h *Handler) Put(ctx context.Context,
 }
 
 func (h *Handler) writeToBlobnodesWithHystrix(ctx context.Context,
-	blob blobIdent, shards [][]byte, callback func(),
+	blob blobIdent, expire int64, shards [][]byte, callback func(),
 ) error {
 	safe := make(chan struct{}, 1)
 	err := hystrix.Do(rwCommand, func() error {
 		safe <- struct{}{}
-		return h.writeToBlobnodes(ctx, blob, shards, callback)
+		return h.writeToBlobnodes(ctx, blob, expire, shards, callback)
 	}, nil)
 
 	select {
@@ -194,7 +224,7 @@ type shardPutStatus struct {
 // takeover ec buffer release by callback.
 // return if had quorum successful shards, then wait all shards in background.
 func (h *Handler) writeToBlobnodes(ctx context.Context,
-	blob blobIdent, shards [][]byte, callback func(),
+	blob blobIdent, expire int64, shards [][]byte, callback func(),
 ) (err error) {
 	span := trace.SpanFromContextSafe(ctx)
 	clusterID, vid, bid := blob.cid, blob.vid, blob.bid
@@ -248,6 +278,7 @@ func (h *Handler) writeToBlobnodes(ctx context.Context,
 				Bid:    bid,
 				Size:   int64(len(shards[index])),
 				Type:   blobnode.NormalIO,
+				Expire: expire,
 			}
 
 			crcDisabled := h.ShardCrcDisabled
@@ -32,13 +32,14 @@ import (
 //	required: clusterID VolumeID BlobID
 //	required: size, one blob size
 //	optional: hasherMap, computing hash
+//	          expire, the expiry of allocated location recorded in shards
 func (h *Handler) PutAt(ctx context.Context, rc io.Reader,
 	clusterID proto.ClusterID, vid proto.Vid, bid proto.BlobID,
-	size int64, hasherMap access.HasherMap,
+	size int64, hasherMap access.HasherMap, expire int64,
 ) error {
 	span := trace.SpanFromContextSafe(ctx)
-	span.Debugf("putat request cluster:%d vid:%d bid:%d size:%d hashes:b(%b)",
-		clusterID, vid, bid, size, hasherMap.ToHashAlgorithm())
+	span.Debugf("putat request cluster:%d vid:%d bid:%d size:%d hashes:b(%b) expire:%d",
+		clusterID, vid, bid, size, hasherMap.ToHashAlgorithm(), expire)
 
 	if len(hasherMap) > 0 {
 		rc = io.TeeReader(rc, hasherMap.ToWriter())
@@ -91,7 +92,7 @@ func (h *Handler) PutAt(ctx context.Context, rc io.Reader,
 	takeoverBuffer := buffer
 	buffer = nil
 	startWrite := time.Now()
-	err = h.writeToBlobnodesWithHystrix(ctx, blobident, shards, func() {
+	err = h.writeToBlobnodesWithHystrix(ctx, blobident, expire, shards, func() {
 		takeoverBuffer.Release()
 	})
 	putTime.IncW(time.Since(startWrite))
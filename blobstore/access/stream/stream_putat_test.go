@@ -34,7 +34,7 @@ func TestAccessStreamPutAtBase(t *testing.T) {
 			access.HashAlgCRC32: access.HashAlgCRC32.ToHasher(),
 		}
 		hashSumMap := make(access.HashSumMap, len(hasherMap))
-		err := streamer.PutAt(ctx(), bytes.NewReader(data), clusterID, 1, 10000, int64(size), hasherMap)
+		err := streamer.PutAt(ctx(), bytes.NewReader(data), clusterID, 1, 10000, int64(size), hasherMap, 0)
 		require.Nil(t, err)
 		for alg, hasher := range hasherMap {
 			hashSumMap[alg] = hasher.Sum(nil)
@@ -44,7 +44,7 @@ func TestAccessStreamPutAtBase(t *testing.T) {
 	// 0
 	{
 		size := 0
-		err := streamer.PutAt(ctx(), newReader(size), clusterID, 1, 10000, int64(size), nil)
+		err := streamer.PutAt(ctx(), newReader(size), clusterID, 1, 10000, int64(size), nil, 0)
 		require.NotNil(t, err)
 	}
 	// 1 byte
@@ -88,7 +88,7 @@ func TestAccessStreamPutAtBase(t *testing.T) {
 	{
 		dataShards.clean()
 		size := 1 << 22
-		err := streamer.PutAt(ctx(), newReader(size), clusterID, 1, 10000, int64(size)+1, nil)
+		err := streamer.PutAt(ctx(), newReader(size), clusterID, 1, 10000, int64(size)+1, nil, 0)
 		require.NotNil(t, err)
 		require.Equal(t, 0, len(dataShards.get(1001, 10000)))
 	}
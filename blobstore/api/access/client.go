@@ -218,6 +218,10 @@ type API interface {
 	// Delete all blobs in these locations.
 	// return failed locations which have yet been deleted if error is not nil.
 	Delete(ctx context.Context, args *DeleteArgs) (failedLocations []Location, err error)
+	// Convert location into another codemode, location is not changed if
+	// it is already in this codemode.
+	// the old location should be deleted after the new one has been persisted.
+	Convert(ctx context.Context, args *ConvertArgs) (location Location, err error)
 }
 
 var _ API = (*client)(nil)
@@ -407,6 +411,9 @@ func (c *client) putObject(ctx context.Context, args *PutArgs) (location Locatio
 	rpcClient := c.rpcClient.Load().(rpc.Client)
 
 	urlStr := fmt.Sprintf("/put?size=%d&hashes=%d", args.Size, args.Hashes)
+	if args.Expire > 0 {
+		urlStr += fmt.Sprintf("&expire=%d", args.Expire)
+	}
 	req, err := http.NewRequest(http.MethodPut, urlStr, args.Body)
 	if err != nil {
 		return
@@ -424,12 +431,13 @@ func (c *client) putObject(ctx context.Context, args *PutArgs) (location Locatio
 }
 
 type blobPart struct {
-	cid   proto.ClusterID
-	vid   proto.Vid
-	bid   proto.BlobID
-	size  int
-	token string
-	buf   []byte
+	cid    proto.ClusterID
+	vid    proto.Vid
+	bid    proto.BlobID
+	size   int
+	token  string
+	expire int64
+	buf    []byte
 }
 
 func (c *client) putPartsBatch(ctx context.Context, parts []blobPart) error {
@@ -441,6 +449,9 @@ func (c *client) putPartsBatch(ctx context.Context, parts []blobPart) error {
 		tasks = append(tasks, func() error {
 			urlStr := fmt.Sprintf("/putat?clusterid=%d&volumeid=%d&blobid=%d&size=%d&hashes=%d&token=%s",
 				part.cid, part.vid, part.bid, part.size, 0, part.token)
+			if part.expire > 0 {
+				urlStr += fmt.Sprintf("&expire=%d", part.expire)
+			}
 			req, err := http.NewRequest(http.MethodPut, urlStr, bytes.NewReader(part.buf))
 			if err != nil {
 				return err
@@ -549,7 +560,7 @@ func (c *client) putParts(ctx context.Context, args *PutArgs) (Location, HashSum
 
 	// alloc
 	allocResp := &AllocResp{}
-	if err := rpcClient.PostWith(ctx, "/alloc", allocResp, AllocArgs{Size: uint64(args.Size)}); err != nil {
+	if err := rpcClient.PostWith(ctx, "/alloc", allocResp, AllocArgs{Size: uint64(args.Size), Expire: args.Expire}); err != nil {
 		return allocResp.Location, nil, err
 	}
 	loc = allocResp.Location
@@ -628,6 +639,7 @@ func (c *client) putParts(ctx context.Context, args *PutArgs) (Location, HashSum
 				}
 				parts[i].token = token
 				parts[i].cid = loc.ClusterID
+				parts[i].expire = loc.Expire
 				parts[i].vid = loc.Blobs[currIdx].Vid
 				parts[i].bid = loc.Blobs[currIdx].MinBid + proto.BlobID(currCount)
 
@@ -672,6 +684,7 @@ func (c *client) putParts(ctx context.Context, args *PutArgs) (Location, HashSum
 					BlobSize:        loc.BlobSize,
 					CodeMode:        loc.CodeMode,
 					AssignClusterID: loc.ClusterID,
+					Expire:          loc.Expire,
 				}); err != nil {
 					return true, err
 				}
@@ -783,6 +796,20 @@ func (c *client) Delete(ctx context.Context, args *DeleteArgs) ([]Location, erro
 	return nil, nil
 }
 
+func (c *client) Convert(ctx context.Context, args *ConvertArgs) (Location, error) {
+	if !args.IsValid() {
+		return Location{}, errcode.ErrIllegalArguments
+	}
+	rpcClient := c.rpcClient.Load().(rpc.Client)
+
+	ctx = withReqidContext(ctx)
+	resp := &ConvertResp{}
+	if err := rpcClient.PostWith(ctx, "/convert", resp, args); err != nil {
+		return Location{}, err
+	}
+	return resp.Location, nil
+}
+
 func shouldRetry(code int, err error) bool {
 	if err != nil {
 		if httpErr, ok := err.(rpc.HTTPError); ok {
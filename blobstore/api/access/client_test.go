@@ -35,6 +35,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/cubefs/cubefs/blobstore/api/access"
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
 	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
 	"github.com/cubefs/cubefs/blobstore/common/rpc"
@@ -112,6 +113,7 @@ func init() {
 	handler.Handle(http.MethodPost, "/get", handleGet, rpc.OptArgsBody())
 	handler.Handle(http.MethodPost, "/delete", handleDelete, rpc.OptArgsBody())
 	handler.Handle(http.MethodPost, "/sign", handleSign, rpc.OptArgsBody())
+	handler.Handle(http.MethodPost, "/convert", handleConvert, rpc.OptArgsBody())
 	handler.Router.NotFound = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})
@@ -359,6 +361,18 @@ func handleSign(c *rpc.Context) {
 	c.RespondJSON(access.SignResp{Location: args.Location})
 }
 
+func handleConvert(c *rpc.Context) {
+	args := new(access.ConvertArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+
+	loc := args.Location.Copy()
+	loc.CodeMode = args.CodeMode
+	c.RespondJSON(access.ConvertResp{Location: loc})
+}
+
 func calcCrc(loc *access.Location) (uint32, error) {
 	crcWriter := crc32.New(crc32.IEEETable)
 
@@ -825,6 +839,23 @@ func TestAccessClientDelete(t *testing.T) {
 	}
 }
 
+func TestAccessClientConvert(t *testing.T) {
+	{
+		_, err := client.Convert(randCtx(), nil)
+		require.ErrorIs(t, errcode.ErrIllegalArguments, err)
+	}
+	{
+		_, err := client.Convert(randCtx(), &access.ConvertArgs{CodeMode: codemode.EC6P6})
+		require.ErrorIs(t, errcode.ErrIllegalArguments, err)
+	}
+	{
+		loc := access.Location{Size: 100, CodeMode: codemode.EC6P6, Blobs: make([]access.SliceInfo, 0)}
+		newLoc, err := client.Convert(randCtx(), &access.ConvertArgs{Location: loc, CodeMode: codemode.EC6P10L2})
+		require.NoError(t, err)
+		require.Equal(t, codemode.EC6P10L2, newLoc.CodeMode)
+	}
+}
+
 func TestAccessClientRequestBody(t *testing.T) {
 	cfg := access.Config{}
 	cfg.MaxSizePutOnce = 1 << 20
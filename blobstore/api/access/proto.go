@@ -25,6 +25,7 @@ import (
 	"hash"
 	"hash/crc32"
 	"io"
+	"time"
 
 	"github.com/cubefs/cubefs/blobstore/common/codemode"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
@@ -217,6 +218,7 @@ type Location struct {
 	BlobSize  uint32            `json:"blob_size"`
 	Crc       uint32            `json:"crc"`
 	Blobs     []SliceInfo       `json:"blobs"`
+	Expire    int64             `json:"expire,omitempty"` // unix seconds, 0 means never expire
 }
 
 // SliceInfo blobs info, 8 + 4 + 4 bytes
@@ -253,6 +255,7 @@ func (loc *Location) Copy() Location {
 		BlobSize:  loc.BlobSize,
 		Crc:       loc.Crc,
 		Blobs:     make([]SliceInfo, len(loc.Blobs)),
+		Expire:    loc.Expire,
 	}
 	copy(dst.Blobs, loc.Blobs)
 	return dst
@@ -273,11 +276,13 @@ func (loc *Location) Copy() Location {
 //	- - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -
 //	| n-bytes |  (10)  | (5) |  (5)  | (20) | (20) |       ...         |
 //	- - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -
+//	| expire  | uvarint(10), only if the location expires            |
+//	- - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -
 func (loc *Location) Encode() []byte {
 	if loc == nil {
 		return nil
 	}
-	n := 25 + 5 + len(loc.Blobs)*20
+	n := 25 + 5 + len(loc.Blobs)*20 + 10
 	buf := make([]byte, n)
 	n = loc.Encode2(buf)
 	return buf[:n]
@@ -306,10 +311,18 @@ func (loc *Location) Encode2(buf []byte) int {
 		n += binary.PutUvarint(buf[n:], uint64(blob.Vid))
 		n += binary.PutUvarint(buf[n:], uint64(blob.Count))
 	}
+	if loc.Expire > 0 {
+		n += binary.PutUvarint(buf[n:], uint64(loc.Expire))
+	}
 
 	return n
 }
 
+// IsExpired returns true if the location has expired at now
+func (loc *Location) IsExpired(now time.Time) bool {
+	return loc.Expire > 0 && now.Unix() >= loc.Expire
+}
+
 // Decode parse location from buf
 // Returns the number of bytes read
 // Error is not nil when parsing failed
@@ -445,6 +458,14 @@ func DecodeLocation(buf []byte) (Location, int, error) {
 		loc.Blobs = append(loc.Blobs, blob)
 	}
 
+	// optional expire at the tail
+	if len(buf) > 0 {
+		if val, nn = next(); nn <= 0 {
+			return loc, n, fmt.Errorf("bytes expire %d", nn)
+		}
+		loc.Expire = int64(val)
+	}
+
 	return loc, n, nil
 }
 
@@ -487,6 +508,7 @@ func DecodeLocationFromBase64(s string) (Location, error) {
 type PutArgs struct {
 	Size   int64         `json:"size"`
 	Hashes HashAlgorithm `json:"hashes,omitempty"`
+	Expire int64         `json:"expire,omitempty"` // unix seconds, deleted after expired
 	Body   io.Reader     `json:"-"`
 
 	// GetBody defines an optional func to return a new copy of Body.
@@ -502,7 +524,7 @@ func (args *PutArgs) IsValid() bool {
 	if args == nil {
 		return false
 	}
-	return args.Size > 0
+	return args.Size > 0 && args.Expire >= 0
 }
 
 // PutResp put response result
@@ -519,6 +541,7 @@ type PutAtArgs struct {
 	Size      int64           `json:"size"`
 	Hashes    HashAlgorithm   `json:"hashes,omitempty"`
 	Token     string          `json:"token"`
+	Expire    int64           `json:"expire,omitempty"` // expire of the allocated location
 	Body      io.Reader       `json:"-"`
 }
 
@@ -544,6 +567,7 @@ type AllocArgs struct {
 	BlobSize        uint32            `json:"blob_size"`
 	AssignClusterID proto.ClusterID   `json:"assign_cluster_id"`
 	CodeMode        codemode.CodeMode `json:"code_mode"`
+	Expire          int64             `json:"expire,omitempty"` // unix seconds, deleted after expired
 }
 
 // IsValid is valid alloc args
@@ -551,6 +575,9 @@ func (args *AllocArgs) IsValid() bool {
 	if args == nil {
 		return false
 	}
+	if args.Expire < 0 {
+		return false
+	}
 	if args.AssignClusterID > 0 {
 		return args.Size > 0 && args.BlobSize > 0 && args.BlobSize <= MaxBlobSize &&
 			args.CodeMode.IsValid()
@@ -622,6 +649,27 @@ func (args *DeleteBlobArgs) IsValid() bool {
 		args.Size > 0
 }
 
+// ConvertArgs for service /convert
+// Location is converted into CodeMode, caller should persist the returned
+// location before deleting the old one if they are different.
+type ConvertArgs struct {
+	Location Location          `json:"location"`
+	CodeMode codemode.CodeMode `json:"code_mode"`
+}
+
+// IsValid is valid convert args
+func (args *ConvertArgs) IsValid() bool {
+	if args == nil {
+		return false
+	}
+	return args.Location.Size > 0 && args.CodeMode.IsValid()
+}
+
+// ConvertResp convert response with the converted location
+type ConvertResp struct {
+	Location Location `json:"location"`
+}
+
 // SignArgs for service /sign
 // Locations are signed location getting from /alloc
 // Location is to be signed location which merged by yourself
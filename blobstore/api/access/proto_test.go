@@ -311,6 +311,9 @@ func TestLocationEncodeDecode(t *testing.T) {
 				Count:  mrand.Uint32(),
 			})
 		}
+		if mrand.Intn(2) == 0 {
+			loc.Expire = mrand.Int63()
+		}
 
 		buf := loc.Encode()
 		bufx := make([]byte, len(buf))
@@ -376,6 +379,25 @@ func TestLocationDecodeError(t *testing.T) {
 		require.Error(t, err)
 		t.Log(err)
 	}
+
+	loc.Expire = math.MaxInt64
+	buf = loc.Encode()
+	require.Equal(t, 25+1+20+9, len(buf))
+	for _, n := range []int{47, 50, 54} {
+		_, _, err := access.DecodeLocation(buf[:n])
+		require.Error(t, err)
+		t.Log(err)
+	}
+}
+
+func TestLocationExpired(t *testing.T) {
+	now := time.Now()
+	loc := &access.Location{}
+	require.False(t, loc.IsExpired(now))
+	loc.Expire = now.Unix() + 1
+	require.False(t, loc.IsExpired(now))
+	loc.Expire = now.Unix()
+	require.True(t, loc.IsExpired(now))
 }
 
 func TestLocationSpread(t *testing.T) {
@@ -64,6 +64,7 @@ type ShardInfo struct {
 	Crc    uint32       `json:"crc"`
 	Flag   ShardStatus  `json:"flag"` // 1:normal,2:markDelete
 	Inline bool         `json:"inline"`
+	Expire int64        `json:"expire,omitempty"` // unix seconds the shard expires
 }
 
 type NodeInfo struct {
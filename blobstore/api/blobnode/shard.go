@@ -50,6 +50,7 @@ type PutShardArgs struct {
 	Bid    proto.BlobID `json:"bid"`
 	Size   int64        `json:"size"`
 	Type   IOType       `json:"iotype,omitempty"`
+	Expire int64        `json:"expire,omitempty"` // unix seconds the shard expires
 	Body   io.Reader    `json:"-"`
 }
 
@@ -77,6 +78,9 @@ func (c *client) PutShard(ctx context.Context, host string, args *PutShardArgs)
 	}
 	urlStr := fmt.Sprintf("%v/shard/put/diskid/%v/vuid/%v/bid/%v/size/%v?iotype=%d",
 		host, args.DiskID, args.Vuid, args.Bid, args.Size, args.Type)
+	if args.Expire > 0 {
+		urlStr += fmt.Sprintf("&expire=%d", args.Expire)
+	}
 	req, err := http.NewRequest(http.MethodPost, urlStr, args.Body)
 	if err != nil {
 		return
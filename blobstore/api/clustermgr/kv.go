@@ -38,6 +38,27 @@ type DeleteKvArgs struct {
 	Key string `json:"key"`
 }
 
+// RefKvArgs references key, the key is set with value if not exists.
+// The key is referenced once by the same holder if Holder is not empty.
+type RefKvArgs struct {
+	Key    string `json:"key"`
+	Holder string `json:"holder,omitempty"`
+	Value  []byte `json:"value"`
+}
+
+// UnrefKvArgs dereferences key, the key is deleted if reference count reaches zero.
+// Dereference of the holder which does not reference the key changes nothing.
+type UnrefKvArgs struct {
+	Key    string `json:"key"`
+	Holder string `json:"holder,omitempty"`
+}
+
+// RefKvRet value and reference count of key after referenced or dereferenced
+type RefKvRet struct {
+	Value    []byte `json:"value"`
+	RefCount int64  `json:"ref_count"`
+}
+
 type ListKvOpts struct {
 	Prefix string `json:"prefix,omitempty"`
 	Marker string `json:"marker,omitempty"`
@@ -69,6 +90,22 @@ func (c *Client) SetKV(ctx context.Context, key string, value []byte) (err error
 	return
 }
 
+// RefKV increases reference count of key atomically, returns the value
+// which is set by the first reference. The not empty holder references
+// the key only once.
+func (c *Client) RefKV(ctx context.Context, key, holder string, value []byte) (ret RefKvRet, err error) {
+	err = c.PostWith(ctx, "/kv/ref", &ret, &RefKvArgs{Key: key, Holder: holder, Value: value})
+	return
+}
+
+// UnrefKV decreases reference count of key atomically, the key is deleted
+// when reference count reaches zero. The not empty holder dereferences the
+// key only if it has referenced, so that a replayed dereference does nothing.
+func (c *Client) UnrefKV(ctx context.Context, key, holder string) (ret RefKvRet, err error) {
+	err = c.PostWith(ctx, "/kv/unref", &ret, &UnrefKvArgs{Key: key, Holder: holder})
+	return
+}
+
 func (c *Client) ListKV(ctx context.Context, args *ListKvOpts) (ret ListKvRet, err error) {
 	err = c.GetWith(ctx, fmt.Sprintf(
 		"/kv/list?prefix=%s&marker=%s&count=%d",
@@ -32,6 +32,7 @@ type client struct {
 
 type Client interface {
 	MsgSender
+	MQClient
 	Allocator
 	Cacher
 }
@@ -59,6 +60,19 @@ func (c *client) SendDeleteMsg(ctx context.Context, host string, args *DeleteArg
 	return c.PostWith(ctx, host+"/deletemsg", nil, args)
 }
 
+func (c *client) ProduceMsg(ctx context.Context, host string, args *ProduceMsgArgs) error {
+	return c.PostWith(ctx, host+"/mq/produce", nil, args)
+}
+
+func (c *client) FetchMsg(ctx context.Context, host string, args *FetchMsgArgs) (ret FetchMsgRet, err error) {
+	err = c.PostWith(ctx, host+"/mq/fetch", &ret, args)
+	return
+}
+
+func (c *client) CommitMsg(ctx context.Context, host string, args *CommitMsgArgs) error {
+	return c.PostWith(ctx, host+"/mq/commit", nil, args)
+}
+
 func (c *client) GetCacheVolume(ctx context.Context, host string, args *CacheVolumeArgs) (volume *VersionVolume, err error) {
 	volume = new(VersionVolume)
 	url := fmt.Sprintf("%s/cache/volume/%d?flush=%v&version=%d", host, args.Vid, args.Flush, args.Version)
@@ -236,3 +236,37 @@ func TestCacher_DiskvPathTransform(t *testing.T) {
 		require.Equal(t, cs.paths, DiskvPathTransform(cs.key))
 	}
 }
+
+func TestLbClient_RaftMQ(t *testing.T) {
+	mqproxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(200)
+		if req.URL.Path == "/mq/fetch" {
+			w.Write([]byte(`{"msgs":[{"offset":10,"value":"YQ==","timestamp":1}]}`))
+		}
+	}))
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(200)
+		w.Write([]byte(fmt.Sprintf(`{"nodes":[{"cluster_id":1,"name":"PROXY","host":"%s","idc":"z0"}]}`, mqproxyServer.URL)))
+	}))
+	defer func() {
+		s.Close()
+		mqproxyServer.Close()
+	}()
+
+	cmCfg := clustermgr.Config{LbConfig: rpc.LbConfig{
+		Hosts: []string{s.URL},
+	}}
+	cm := clustermgr.New(&cmCfg)
+	cli := NewRaftMQLbClient(&LbConfig{}, cm, 1)
+
+	ctx := context.Background()
+	err := cli.ProduceMsg(ctx, &ProduceMsgArgs{Topic: "topic", Msgs: [][]byte{[]byte("a")}})
+	require.NoError(t, err)
+	ret, err := cli.FetchMsg(ctx, &FetchMsgArgs{Topic: "topic", Group: "group", Count: 1})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(ret.Msgs))
+	require.Equal(t, int64(10), ret.Msgs[0].Offset)
+	require.Equal(t, []byte("a"), ret.Msgs[0].Value)
+	err = cli.CommitMsg(ctx, &CommitMsgArgs{Topic: "topic", Group: "group", Offset: 11})
+	require.NoError(t, err)
+}
@@ -41,6 +41,15 @@ type lbClient struct {
 }
 
 func NewMQLbClient(cfg *LbConfig, service clustermgr.APIService, clusterID proto.ClusterID) LbMsgSender {
+	return newLbClient(cfg, service, clusterID)
+}
+
+// NewRaftMQLbClient returns client of raft message queue embedded in proxies
+func NewRaftMQLbClient(cfg *LbConfig, service clustermgr.APIService, clusterID proto.ClusterID) LbMQClient {
+	return newLbClient(cfg, service, clusterID)
+}
+
+func newLbClient(cfg *LbConfig, service clustermgr.APIService, clusterID proto.ClusterID) *lbClient {
 	hostGetter := func() ([]string, error) {
 		svrInfos, err := service.GetService(context.Background(), clustermgr.GetServiceArgs{Name: proto.ServiceNameProxy})
 		if err != nil {
@@ -111,6 +120,60 @@ func (c *lbClient) SendShardRepairMsg(ctx context.Context, args *ShardRepairArgs
 	return err
 }
 
+func (c *lbClient) ProduceMsg(ctx context.Context, args *ProduceMsgArgs) (err error) {
+	span := trace.SpanFromContextSafe(ctx)
+
+	hosts := c.selector.GetRandomN(c.hostRetry)
+	if len(hosts) == 0 {
+		return errNoServiceAvailable
+	}
+	for _, h := range hosts {
+		err = c.Client.ProduceMsg(ctx, h, args)
+		if err == nil || !shouldRetry(err) {
+			return err
+		}
+		span.Errorf("produce message failed, host: %s, topic: %s, err:%+v", h, args.Topic, err)
+	}
+
+	return err
+}
+
+func (c *lbClient) FetchMsg(ctx context.Context, args *FetchMsgArgs) (ret FetchMsgRet, err error) {
+	span := trace.SpanFromContextSafe(ctx)
+
+	hosts := c.selector.GetRandomN(c.hostRetry)
+	if len(hosts) == 0 {
+		return ret, errNoServiceAvailable
+	}
+	for _, h := range hosts {
+		ret, err = c.Client.FetchMsg(ctx, h, args)
+		if err == nil || !shouldRetry(err) {
+			return ret, err
+		}
+		span.Errorf("fetch message failed, host: %s, args: %+v, err:%+v", h, args, err)
+	}
+
+	return ret, err
+}
+
+func (c *lbClient) CommitMsg(ctx context.Context, args *CommitMsgArgs) (err error) {
+	span := trace.SpanFromContextSafe(ctx)
+
+	hosts := c.selector.GetRandomN(c.hostRetry)
+	if len(hosts) == 0 {
+		return errNoServiceAvailable
+	}
+	for _, h := range hosts {
+		err = c.Client.CommitMsg(ctx, h, args)
+		if err == nil || !shouldRetry(err) {
+			return err
+		}
+		span.Errorf("commit message failed, host: %s, args: %+v, err:%+v", h, args, err)
+	}
+
+	return err
+}
+
 func shouldRetry(err error) bool {
 	if err == nil {
 		return false // success
@@ -47,3 +47,43 @@ type ShardRepairArgs struct {
 	BadIdxes  []uint8         `json:"bad_idxes"`
 	Reason    string          `json:"reason"`
 }
+
+// MQClient is the client of raft message queue embedded in proxy
+type MQClient interface {
+	ProduceMsg(ctx context.Context, host string, args *ProduceMsgArgs) error
+	FetchMsg(ctx context.Context, host string, args *FetchMsgArgs) (FetchMsgRet, error)
+	CommitMsg(ctx context.Context, host string, args *CommitMsgArgs) error
+}
+
+type LbMQClient interface {
+	ProduceMsg(ctx context.Context, args *ProduceMsgArgs) error
+	FetchMsg(ctx context.Context, args *FetchMsgArgs) (FetchMsgRet, error)
+	CommitMsg(ctx context.Context, args *CommitMsgArgs) error
+}
+
+type ProduceMsgArgs struct {
+	Topic string   `json:"topic"`
+	Msgs  [][]byte `json:"msgs"`
+}
+
+type FetchMsgArgs struct {
+	Topic string `json:"topic"`
+	Group string `json:"group"`
+	Count int    `json:"count"`
+}
+
+type Message struct {
+	Offset    int64  `json:"offset"`
+	Value     []byte `json:"value"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+type FetchMsgRet struct {
+	Msgs []Message `json:"msgs"`
+}
+
+type CommitMsgArgs struct {
+	Topic  string `json:"topic"`
+	Group  string `json:"group"`
+	Offset int64  `json:"offset"` // next offset to consume
+}
@@ -40,6 +40,21 @@ const (
 	PathInspectAcquire       = "/inspect/acquire"
 	PathManualMigrateTaskAdd = "/manual/migrate/task/add"
 
+	PathConvertTaskAdd  = "/convert/task/add"
+	PathConvertTaskList = "/convert/task/list"
+	PathConvertAcquire  = "/convert/acquire"
+	PathConvertComplete = "/convert/complete"
+	PathConvertedVolume = "/converted/volume"
+
+	PathClusterMigrateTaskList = "/cluster/migrate/task/list"
+	PathClusterMigrateAcquire  = "/cluster/migrate/acquire"
+	PathClusterMigrateComplete = "/cluster/migrate/complete"
+	PathMigratedVolume         = "/migrated/volume"
+
+	PathVolumeCompactTaskList = "/volume/compact/task/list"
+	PathVolumeCompactAcquire  = "/volume/compact/acquire"
+	PathVolumeCompactComplete = "/volume/compact/complete"
+
 	PathTaskDetail    = "/task/detail"
 	PathTaskDetailURI = PathTaskDetail + "/:type/:id" // "/task/detail/:type/:id"
 	PathUpdateVolume  = "/update/vol"
@@ -78,6 +93,30 @@ type IManualMigrator interface {
 	AddManualMigrateTask(ctx context.Context, args *AddManualMigrateArgs) (err error)
 }
 
+// IConverter codemode convert task.
+type IConverter interface {
+	AddConvertTask(ctx context.Context, args *AddConvertTaskArgs) (ret *AddConvertTaskRet, err error)
+	ListConvertTasks(ctx context.Context) (ret *ListConvertTasksRet, err error)
+	AcquireConvertTask(ctx context.Context) (ret *proto.CodeModeConvertTask, err error)
+	CompleteConvertTask(ctx context.Context, args *proto.CodeModeConvertRet) (err error)
+	GetConvertedVolume(ctx context.Context, vid proto.Vid) (ret *proto.ConvertedVolume, err error)
+}
+
+// IClusterMigrator cluster migrate task.
+type IClusterMigrator interface {
+	ListClusterMigrateTasks(ctx context.Context) (ret *ListClusterMigrateTasksRet, err error)
+	AcquireClusterMigrateTask(ctx context.Context) (ret *proto.ClusterMigrateTask, err error)
+	CompleteClusterMigrateTask(ctx context.Context, args *proto.ClusterMigrateRet) (err error)
+	GetMigratedVolume(ctx context.Context, vid proto.Vid) (ret *proto.MigratedVolume, err error)
+}
+
+// IVolumeCompactor volume compact task.
+type IVolumeCompactor interface {
+	ListVolumeCompactTasks(ctx context.Context) (ret *ListVolumeCompactTasksRet, err error)
+	AcquireVolumeCompactTask(ctx context.Context) (ret *proto.VolumeCompactTask, err error)
+	CompleteVolumeCompactTask(ctx context.Context, args *proto.VolumeCompactRet) (err error)
+}
+
 // IVolumeUpdater volume updater.
 type IVolumeUpdater interface {
 	UpdateVolume(ctx context.Context, host string, vid proto.Vid) (err error)
@@ -89,6 +128,9 @@ type IScheduler interface {
 	IInspector
 	ISchedulerStatus
 	IManualMigrator
+	IConverter
+	IClusterMigrator
+	IVolumeCompactor
 	IVolumeUpdater
 }
 
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"net/url"
 
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
 	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
 )
@@ -57,6 +58,8 @@ type TaskReportArgs struct {
 	TaskStats            proto.TaskStatistics `json:"task_stats"`
 	IncreaseDataSizeByte int                  `json:"increase_data_size_byte"`
 	IncreaseShardCnt     int                  `json:"increase_shard_cnt"`
+	// bytes of shards downloaded across AZs
+	IncreaseCrossAZDataSizeByte int `json:"increase_cross_az_data_size_byte"`
 }
 
 func (c *client) ReportTask(ctx context.Context, args *TaskReportArgs) (err error) {
@@ -121,6 +124,121 @@ func (c *client) AddManualMigrateTask(ctx context.Context, args *AddManualMigrat
 	})
 }
 
+// AddConvertTaskArgs converts the volume into code mode.
+type AddConvertTaskArgs struct {
+	Vid      proto.Vid         `json:"vid"`
+	CodeMode codemode.CodeMode `json:"code_mode"`
+}
+
+func (args *AddConvertTaskArgs) Valid() bool {
+	return args.Vid != proto.InvalidVid && args.CodeMode.IsValid()
+}
+
+type AddConvertTaskRet struct {
+	TaskID string `json:"task_id"`
+}
+
+func (c *client) AddConvertTask(ctx context.Context, args *AddConvertTaskArgs) (ret *AddConvertTaskRet, err error) {
+	err = c.request(func(host string) error {
+		return c.PostWith(ctx, host+PathConvertTaskAdd, &ret, args)
+	})
+	return
+}
+
+type ListConvertTasksRet struct {
+	Tasks []*proto.CodeModeConvertTask `json:"tasks"`
+}
+
+func (c *client) ListConvertTasks(ctx context.Context) (ret *ListConvertTasksRet, err error) {
+	err = c.request(func(host string) error {
+		return c.GetWith(ctx, host+PathConvertTaskList, &ret)
+	})
+	return
+}
+
+func (c *client) AcquireConvertTask(ctx context.Context) (ret *proto.CodeModeConvertTask, err error) {
+	err = c.request(func(host string) error {
+		return c.GetWith(ctx, host+PathConvertAcquire, &ret)
+	})
+	return
+}
+
+func (c *client) CompleteConvertTask(ctx context.Context, args *proto.CodeModeConvertRet) (err error) {
+	return c.request(func(host string) error {
+		return c.PostWith(ctx, host+PathConvertComplete, nil, args)
+	})
+}
+
+type ConvertedVolumeArgs struct {
+	Vid proto.Vid `json:"vid"`
+}
+
+func (c *client) GetConvertedVolume(ctx context.Context, vid proto.Vid) (ret *proto.ConvertedVolume, err error) {
+	err = c.request(func(host string) error {
+		return c.GetWith(ctx, fmt.Sprintf("%s%s?vid=%d", host, PathConvertedVolume, vid), &ret)
+	})
+	return
+}
+
+type ListClusterMigrateTasksRet struct {
+	Tasks []*proto.ClusterMigrateTask `json:"tasks"`
+}
+
+func (c *client) ListClusterMigrateTasks(ctx context.Context) (ret *ListClusterMigrateTasksRet, err error) {
+	err = c.request(func(host string) error {
+		return c.GetWith(ctx, host+PathClusterMigrateTaskList, &ret)
+	})
+	return
+}
+
+func (c *client) AcquireClusterMigrateTask(ctx context.Context) (ret *proto.ClusterMigrateTask, err error) {
+	err = c.request(func(host string) error {
+		return c.GetWith(ctx, host+PathClusterMigrateAcquire, &ret)
+	})
+	return
+}
+
+func (c *client) CompleteClusterMigrateTask(ctx context.Context, args *proto.ClusterMigrateRet) (err error) {
+	return c.request(func(host string) error {
+		return c.PostWith(ctx, host+PathClusterMigrateComplete, nil, args)
+	})
+}
+
+type MigratedVolumeArgs struct {
+	Vid proto.Vid `json:"vid"`
+}
+
+func (c *client) GetMigratedVolume(ctx context.Context, vid proto.Vid) (ret *proto.MigratedVolume, err error) {
+	err = c.request(func(host string) error {
+		return c.GetWith(ctx, fmt.Sprintf("%s%s?vid=%d", host, PathMigratedVolume, vid), &ret)
+	})
+	return
+}
+
+type ListVolumeCompactTasksRet struct {
+	Tasks []*proto.VolumeCompactTask `json:"tasks"`
+}
+
+func (c *client) ListVolumeCompactTasks(ctx context.Context) (ret *ListVolumeCompactTasksRet, err error) {
+	err = c.request(func(host string) error {
+		return c.GetWith(ctx, host+PathVolumeCompactTaskList, &ret)
+	})
+	return
+}
+
+func (c *client) AcquireVolumeCompactTask(ctx context.Context) (ret *proto.VolumeCompactTask, err error) {
+	err = c.request(func(host string) error {
+		return c.GetWith(ctx, host+PathVolumeCompactAcquire, &ret)
+	})
+	return
+}
+
+func (c *client) CompleteVolumeCompactTask(ctx context.Context, args *proto.VolumeCompactRet) (err error) {
+	return c.request(func(host string) error {
+		return c.PostWith(ctx, host+PathVolumeCompactComplete, nil, args)
+	})
+}
+
 // MigrateTaskDetailArgs migrate task detail args.
 type MigrateTaskDetailArgs struct {
 	Type proto.TaskType `json:"type"`
@@ -171,12 +289,58 @@ type ManualMigrateTasksStat struct {
 	MigrateTasksStat
 }
 
+type CodeModeConvertTasksStat struct {
+	Enable         bool `json:"enable"`
+	PreparingCnt   int  `json:"preparing_cnt"`
+	WorkerDoingCnt int  `json:"worker_doing_cnt"`
+	FinishedCnt    int  `json:"finished_cnt"`
+}
+
+// ClusterMigrateTasksStat progress of migrating all volumes into target cluster,
+// the source cluster is drained if all volumes have been migrated.
+type ClusterMigrateTasksStat struct {
+	Enable            bool            `json:"enable"`
+	TargetClusterID   proto.ClusterID `json:"target_cluster_id"`
+	TotalVolumeCnt    int             `json:"total_volume_cnt"`
+	PreparingCnt      int             `json:"preparing_cnt"`
+	WorkerDoingCnt    int             `json:"worker_doing_cnt"`
+	MigratedVolumeCnt int             `json:"migrated_volume_cnt"`
+	MigratedBlobCnt   int             `json:"migrated_blob_cnt"`
+	Drained           bool            `json:"drained"`
+}
+
+// VolumeCompactTasksStat progress of compacting volumes, the units of
+// compacted volumes are released back to clustermgr.
+type VolumeCompactTasksStat struct {
+	Enable             bool `json:"enable"`
+	PreparingCnt       int  `json:"preparing_cnt"`
+	WorkerDoingCnt     int  `json:"worker_doing_cnt"`
+	ReleasingCnt       int  `json:"releasing_cnt"`
+	CompactedVolumeCnt int  `json:"compacted_volume_cnt"`
+	CompactedBlobCnt   int  `json:"compacted_blob_cnt"`
+}
+
 type VolumeInspectTasksStat struct {
 	Enable         bool   `json:"enable"`
 	FinishedPerMin string `json:"finished_per_min"`
 	TimeOutPerMin  string `json:"time_out_per_min"`
 }
 
+// VolumeRisk durability risk of volume
+type VolumeRisk struct {
+	Vid      proto.Vid         `json:"vid"`
+	CodeMode codemode.CodeMode `json:"code_mode"`
+	BadCnt   int               `json:"bad_cnt"`
+	// count of units could be lost more without data loss, negative means data may be lost
+	FaultTolerance int `json:"fault_tolerance"`
+}
+
+// VolumesAtRiskStat volumes at risk in repairing, sorted by fault tolerance
+type VolumesAtRiskStat struct {
+	AtRiskCnt int          `json:"at_risk_cnt"`
+	Volumes   []VolumeRisk `json:"volumes"`
+}
+
 // RunnerStat shard repair and blob delete stat
 type RunnerStat struct {
 	Enable        bool     `json:"enable"`
@@ -187,13 +351,17 @@ type RunnerStat struct {
 }
 
 type TasksStat struct {
-	DiskRepair    *DiskRepairTasksStat    `json:"disk_repair,omitempty"`
-	DiskDrop      *DiskDropTasksStat      `json:"disk_drop,omitempty"`
-	Balance       *BalanceTasksStat       `json:"balance,omitempty"`
-	ManualMigrate *ManualMigrateTasksStat `json:"manual_migrate,omitempty"`
-	VolumeInspect *VolumeInspectTasksStat `json:"volume_inspect,omitempty"`
-	ShardRepair   *RunnerStat             `json:"shard_repair"`
-	BlobDelete    *RunnerStat             `json:"blob_delete"`
+	DiskRepair      *DiskRepairTasksStat      `json:"disk_repair,omitempty"`
+	DiskDrop        *DiskDropTasksStat        `json:"disk_drop,omitempty"`
+	Balance         *BalanceTasksStat         `json:"balance,omitempty"`
+	ManualMigrate   *ManualMigrateTasksStat   `json:"manual_migrate,omitempty"`
+	VolumeInspect   *VolumeInspectTasksStat   `json:"volume_inspect,omitempty"`
+	CodeModeConvert *CodeModeConvertTasksStat `json:"codemode_convert,omitempty"`
+	ClusterMigrate  *ClusterMigrateTasksStat  `json:"cluster_migrate,omitempty"`
+	VolumeCompact   *VolumeCompactTasksStat   `json:"volume_compact,omitempty"`
+	ShardRepair     *RunnerStat               `json:"shard_repair"`
+	BlobDelete      *RunnerStat               `json:"blob_delete"`
+	VolumesAtRisk   *VolumesAtRiskStat        `json:"volumes_at_risk,omitempty"`
 }
 
 func (c *client) DetailMigrateTask(ctx context.Context, args *MigrateTaskDetailArgs) (detail MigrateTaskDetail, err error) {
@@ -0,0 +1,56 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package workutils
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+)
+
+// CrossAZRepairLimiter limits bandwidth of shards downloaded across AZs
+// in repair and migrate, nil means no limit.
+var CrossAZRepairLimiter *rate.Limiter
+
+// SetCrossAZRepairRate sets bytes per second of cross AZ repair bandwidth,
+// non-positive bps removes the limit.
+func SetCrossAZRepairRate(bps int) {
+	if bps <= 0 {
+		CrossAZRepairLimiter = nil
+		return
+	}
+	CrossAZRepairLimiter = rate.NewLimiter(rate.Limit(bps), bps)
+}
+
+// WaitCrossAZRepair blocks until size bytes could be downloaded across AZs,
+// the size larger than burst is waited in slices of burst.
+func WaitCrossAZRepair(ctx context.Context, size int) error {
+	limiter := CrossAZRepairLimiter
+	if limiter == nil {
+		return nil
+	}
+	burst := limiter.Burst()
+	for size > 0 {
+		n := size
+		if n > burst {
+			n = burst
+		}
+		if err := limiter.WaitN(ctx, n); err != nil {
+			return err
+		}
+		size -= n
+	}
+	return nil
+}
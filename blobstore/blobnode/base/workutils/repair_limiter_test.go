@@ -0,0 +1,42 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package workutils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCrossAZRepairLimiter(t *testing.T) {
+	ctx := context.Background()
+	SetCrossAZRepairRate(0)
+	require.Nil(t, CrossAZRepairLimiter)
+	require.NoError(t, WaitCrossAZRepair(ctx, 1<<30))
+
+	SetCrossAZRepairRate(100 << 10)
+	defer SetCrossAZRepairRate(0)
+	require.NotNil(t, CrossAZRepairLimiter)
+	// larger than burst is waited in slices of burst
+	start := time.Now()
+	require.NoError(t, WaitCrossAZRepair(ctx, 150<<10))
+	require.GreaterOrEqual(t, time.Since(start), 400*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	require.Error(t, WaitCrossAZRepair(ctx, 50<<10))
+}
@@ -79,3 +79,20 @@ func IdxSplitByLocalStripe(idxs []uint8, mode codemode.CodeMode) [][]uint8 {
 	}
 	return ret
 }
+
+// SameAZIdxes returns all shard indexes in the same AZs with idxs
+func SameAZIdxes(idxs []uint8, mode codemode.CodeMode) map[uint8]struct{} {
+	tactic := mode.Tactic()
+	azStripes := tactic.GetECLayoutByAZ()
+	ret := make(map[uint8]struct{})
+	for _, idx := range idxs {
+		azIdx := tactic.AZIndex(int(idx))
+		if azIdx < 0 {
+			continue
+		}
+		for _, i := range azStripes[azIdx] {
+			ret[uint8(i)] = struct{}{}
+		}
+	}
+	return ret
+}
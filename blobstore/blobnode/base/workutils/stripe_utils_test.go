@@ -191,3 +191,22 @@ LoopTest:
 	}
 	require.Equal(t, false, status.CanRecover())
 }
+
+func TestSameAZIdxes(t *testing.T) {
+	testWithAllMode(t, testSameAZIdxes)
+}
+
+func testSameAZIdxes(t *testing.T, mode codemode.CodeMode) {
+	stripe := allModeStripe[mode]
+	idxes := SameAZIdxes([]uint8{0}, mode)
+	var compareStripe []int
+	compareStripe = append(compareStripe, stripe.N[0]...)
+	compareStripe = append(compareStripe, stripe.M[0]...)
+	compareStripe = append(compareStripe, stripe.L[0]...)
+	require.Equal(t, len(compareStripe), len(idxes))
+	for _, idx := range compareStripe {
+		_, ok := idxes[uint8(idx)]
+		require.True(t, ok)
+	}
+	require.Equal(t, 0, len(SameAZIdxes([]uint8{uint8(mode.GetShardNum())}, mode)))
+}
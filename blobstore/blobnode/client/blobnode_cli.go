@@ -34,7 +34,8 @@ type IBlobNode interface {
 	StatShard(ctx context.Context, location proto.VunitLocation, bid proto.BlobID) (si *ShardInfo, err error)
 	ListShards(ctx context.Context, location proto.VunitLocation) (shards []*ShardInfo, err error)
 	GetShard(ctx context.Context, location proto.VunitLocation, bid proto.BlobID, ioType api.IOType) (body io.ReadCloser, crc32 uint32, err error)
-	PutShard(ctx context.Context, location proto.VunitLocation, bid proto.BlobID, size int64, body io.Reader, ioType api.IOType) (err error)
+	PutShard(ctx context.Context, location proto.VunitLocation, bid proto.BlobID, size, expire int64, body io.Reader, ioType api.IOType) (err error)
+	DeleteShard(ctx context.Context, location proto.VunitLocation, bid proto.BlobID) (err error)
 }
 
 // BlobNodeClient blobnode client
@@ -151,11 +152,11 @@ func (c *BlobNodeClient) ListShards(ctx context.Context, location proto.VunitLoc
 }
 
 // PutShard put data to shard
-func (c *BlobNodeClient) PutShard(ctx context.Context, location proto.VunitLocation, bid proto.BlobID, size int64, body io.Reader, ioType api.IOType) (err error) {
+func (c *BlobNodeClient) PutShard(ctx context.Context, location proto.VunitLocation, bid proto.BlobID, size, expire int64, body io.Reader, ioType api.IOType) (err error) {
 	pSpan := trace.SpanFromContextSafe(ctx)
 	_, ctx = trace.StartSpanFromContextWithTraceID(context.Background(), "PutShard", pSpan.TraceID())
 
-	_, err = c.cli.PutShard(ctx, location.Host, &api.PutShardArgs{DiskID: location.DiskID, Vuid: location.Vuid, Bid: bid, Body: body, Size: size, Type: ioType})
+	_, err = c.cli.PutShard(ctx, location.Host, &api.PutShardArgs{DiskID: location.DiskID, Vuid: location.Vuid, Bid: bid, Body: body, Size: size, Expire: expire, Type: ioType})
 	if err != nil {
 		pSpan.Errorf("PutShard failed: location[%+v], bid[%d], code[%d], err[%+v]", location, bid, rpc.DetectStatusCode(err), err)
 		errMsg := err.Error()
@@ -165,3 +166,30 @@ func (c *BlobNodeClient) PutShard(ctx context.Context, location proto.VunitLocat
 	}
 	return
 }
+
+// DeleteShard marks the shard deleted and deletes it, the shard not found is deleted already
+func (c *BlobNodeClient) DeleteShard(ctx context.Context, location proto.VunitLocation, bid proto.BlobID) (err error) {
+	pSpan := trace.SpanFromContextSafe(ctx)
+	_, ctx = trace.StartSpanFromContextWithTraceID(context.Background(), "DeleteShard", pSpan.TraceID())
+
+	args := &api.DeleteShardArgs{DiskID: location.DiskID, Vuid: location.Vuid, Bid: bid}
+	err = c.cli.MarkDeleteShard(ctx, location.Host, args)
+	if err != nil {
+		switch rpc.DetectStatusCode(err) {
+		case errcode.CodeBidNotFound:
+			return nil
+		case errcode.CodeShardMarkDeleted:
+		default:
+			pSpan.Errorf("MarkDeleteShard failed: location[%+v], bid[%d], err[%+v]", location, bid, err)
+			return
+		}
+	}
+	err = c.cli.DeleteShard(ctx, location.Host, args)
+	if err != nil {
+		if rpc.DetectStatusCode(err) == errcode.CodeBidNotFound {
+			return nil
+		}
+		pSpan.Errorf("DeleteShard failed: location[%+v], bid[%d], err[%+v]", location, bid, err)
+	}
+	return
+}
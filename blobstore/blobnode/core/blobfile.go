@@ -50,6 +50,7 @@ type blobFile struct {
 	handleIOError func(err error)
 	readPool      taskpool.IoPool // io schedulers
 	writePool     taskpool.IoPool
+	ring          IORing // read and write through io_uring if not nil
 }
 
 func (ef *blobFile) Name() string {
@@ -61,6 +62,12 @@ func (ef *blobFile) Fd() uintptr {
 }
 
 func (ef *blobFile) ReadAt(b []byte, off int64) (n int, err error) {
+	if ef.ring != nil {
+		n, err = ef.ring.ReadAt(ef.file.Fd(), b, off)
+		ef.handleError(err)
+		return
+	}
+
 	task := taskpool.IoPoolTaskArgs{
 		BucketId: ef.chunk,
 		Tm:       time.Now(),
@@ -73,6 +80,12 @@ func (ef *blobFile) ReadAt(b []byte, off int64) (n int, err error) {
 }
 
 func (ef *blobFile) WriteAt(b []byte, off int64) (n int, err error) {
+	if ef.ring != nil {
+		n, err = ef.ring.WriteAt(ef.file.Fd(), b, off)
+		ef.handleError(err)
+		return
+	}
+
 	task := taskpool.IoPoolTaskArgs{
 		BucketId: ef.chunk,
 		Tm:       time.Now(),
@@ -190,3 +203,11 @@ func NewBlobFile(file RawFile, handleIOError func(err error), chunkId uint64, re
 	})
 	return ef
 }
+
+// NewRingBlobFile returns blob file which reads and writes through io_uring,
+// the other operations are still scheduled by io pools.
+func NewRingBlobFile(file RawFile, handleIOError func(err error), chunkId uint64, ring IORing, readPool taskpool.IoPool, writePool taskpool.IoPool) BlobFile {
+	ef := NewBlobFile(file, handleIOError, chunkId, readPool, writePool).(*blobFile)
+	ef.ring = ring
+	return ef
+}
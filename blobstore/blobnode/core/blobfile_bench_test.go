@@ -0,0 +1,103 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package core
+
+import (
+	"crypto/rand"
+	"fmt"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/blobnode/sys"
+	"github.com/cubefs/cubefs/blobstore/util/taskpool"
+)
+
+const benchFileSize = int64(64 << 20)
+
+func newBenchBlobFile(b *testing.B, engine string) (BlobFile, func()) {
+	f, err := OpenFile(filepath.Join(b.TempDir(), "bench"), true)
+	require.NoError(b, err)
+	require.NoError(b, f.Truncate(benchFileSize))
+
+	metricConf := taskpool.IoPoolMetricConf{Namespace: "blobstore", Subsystem: "blobnode_bench"}
+	writePool := taskpool.NewWritePool(defaultWriteThreadCnt, defaultIOQueueDepth, metricConf)
+	readPool := taskpool.NewReadPool(defaultReadThreadCnt, defaultIOQueueDepth, metricConf)
+	closeAll := func() {
+		writePool.Close()
+		readPool.Close()
+		f.Close()
+	}
+
+	if engine == IOEngineSync {
+		return NewBlobFile(f, nil, 1, readPool, writePool), closeAll
+	}
+	ring, err := sys.NewIORing(defaultIORingEntries, defaultIORingBufferCnt, defaultIORingBufferSize)
+	if err != nil {
+		closeAll()
+		b.Skipf("io_uring is not supported: %v", err)
+	}
+	return NewRingBlobFile(f, nil, 1, ring, readPool, writePool), func() {
+		ring.Close()
+		closeAll()
+	}
+}
+
+func benchmarkBlobFile(b *testing.B, engine string, write bool, size int) {
+	ef, closeFn := newBenchBlobFile(b, engine)
+	defer closeFn()
+
+	data := make([]byte, size)
+	rand.Read(data)
+	slots := benchFileSize / int64(size)
+	for i := int64(0); i < slots; i++ {
+		_, err := ef.WriteAt(data, i*int64(size))
+		require.NoError(b, err)
+	}
+
+	var seq int64
+	b.SetBytes(int64(size))
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		buf := make([]byte, size)
+		for pb.Next() {
+			off := (atomic.AddInt64(&seq, 1) % slots) * int64(size)
+			var err error
+			if write {
+				_, err = ef.WriteAt(data, off)
+			} else {
+				_, err = ef.ReadAt(buf, off)
+			}
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkBlobFile(b *testing.B) {
+	for _, engine := range []string{IOEngineSync, IOEngineIOUring} {
+		for _, size := range []int{4 << 10, 64 << 10, 1 << 20} {
+			b.Run(fmt.Sprintf("%s/read/%dk", engine, size>>10), func(b *testing.B) {
+				benchmarkBlobFile(b, engine, false, size)
+			})
+			b.Run(fmt.Sprintf("%s/write/%dk", engine, size>>10), func(b *testing.B) {
+				benchmarkBlobFile(b, engine, true, size)
+			})
+		}
+	}
+}
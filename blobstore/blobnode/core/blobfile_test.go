@@ -53,7 +53,7 @@ func TestBlobFile_Op(t *testing.T) {
 	ioPool.EXPECT().Submit(gomock.Any()).Do(func(args taskpool.IoPoolTaskArgs) {
 		args.TaskFn()
 	}).AnyTimes()
-	ef := blobFile{f, 1, syncWorker, nil, ioPool, ioPool}
+	ef := blobFile{f, 1, syncWorker, nil, ioPool, ioPool, nil}
 	log.Info(ef.Name())
 	fd := ef.Fd()
 	require.NotNil(t, fd)
@@ -664,6 +664,7 @@ func (cs *chunk) ListShards(ctx context.Context, startBid proto.BlobID, cnt int,
 			Crc:    shard.Crc,
 			Flag:   shard.Flag,
 			Inline: shard.Inline,
+			Expire: shard.Expire,
 		})
 
 		next = bid
@@ -47,6 +47,14 @@ const (
 	defaultWriteThreadCnt               = 1
 	defaultReadThreadCnt                = 4
 	defaultIOQueueDepth                 = 512
+	defaultIORingEntries                = 256
+	defaultIORingBufferCnt              = 64
+	defaultIORingBufferSize             = 64 * 1024 // 64k
+)
+
+const (
+	IOEngineSync    = "sync"     // positional io in io pools
+	IOEngineIOUring = "io_uring" // io_uring with registered buffers and batched submission
 )
 
 // Config for disk
@@ -55,6 +63,7 @@ type BaseConfig struct {
 	AutoFormat  bool   `json:"auto_format"`
 	MaxChunks   int32  `json:"max_chunks"`
 	DisableSync bool   `json:"disable_sync"`
+	IOEngine    string `json:"io_engine"`
 }
 
 type RuntimeConfig struct {
@@ -85,6 +94,9 @@ type RuntimeConfig struct {
 	ReadThreadCnt                int     `json:"read_thread_cnt"`
 	WriteQueueDepth              int     `json:"write_queue_depth"`
 	ReadQueueDepth               int     `json:"read_queue_depth"`
+	IORingEntries                int     `json:"io_ring_entries"`     // queue depth of io_uring
+	IORingBufferCnt              int     `json:"io_ring_buffer_cnt"`  // registered buffers of io_uring
+	IORingBufferSize             int     `json:"io_ring_buffer_size"` // size of each registered buffer
 
 	DataQos qos.Config `json:"data_qos"`
 }
@@ -108,6 +120,16 @@ type Config struct {
 	AllocDiskID      func(ctx context.Context) (proto.DiskID, error)
 	HandleIOError    func(ctx context.Context, diskID proto.DiskID, diskErr error)
 	NotifyCompacting func(ctx context.Context, args *cmapi.SetCompactChunkArgs) (err error)
+
+	// data io through io_uring of the disk if not nil
+	IORing IORing
+}
+
+// IORing positional io of data file, which is shared by all chunks of a disk
+type IORing interface {
+	ReadAt(fd uintptr, b []byte, off int64) (n int, err error)
+	WriteAt(fd uintptr, b []byte, off int64) (n int, err error)
+	Close() error
 }
 
 func InitConfig(conf *Config) error {
@@ -123,6 +145,13 @@ func InitConfig(conf *Config) error {
 	if conf.CompactReservedSpaceB > conf.DiskReservedSpaceB {
 		return errors.New("CompactReservedSpaceB is larger than DiskReservedSpaceB")
 	}
+	switch conf.IOEngine {
+	case "":
+		conf.IOEngine = IOEngineSync
+	case IOEngineSync, IOEngineIOUring:
+	default:
+		return errors.New("io engine is not supported")
+	}
 	defaulter.LessOrEqual(&conf.DiskReservedSpaceB, DefaultDiskReservedSpaceB)
 	defaulter.LessOrEqual(&conf.CompactReservedSpaceB, DefaultCompactReservedSpaceB)
 	defaulter.LessOrEqual(&conf.MaxChunks, DefaultMaxChunks)
@@ -151,6 +180,9 @@ func InitConfig(conf *Config) error {
 	defaulter.LessOrEqual(&conf.ReadThreadCnt, defaultReadThreadCnt)
 	defaulter.LessOrEqual(&conf.WriteQueueDepth, defaultIOQueueDepth)
 	defaulter.LessOrEqual(&conf.ReadQueueDepth, defaultIOQueueDepth)
+	defaulter.LessOrEqual(&conf.IORingEntries, defaultIORingEntries)
+	defaulter.LessOrEqual(&conf.IORingBufferCnt, defaultIORingBufferCnt)
+	defaulter.LessOrEqual(&conf.IORingBufferSize, defaultIORingBufferSize)
 	conf.DataQos.ReadQueueDepth = conf.ReadQueueDepth
 	conf.DataQos.WriteQueueDepth = conf.WriteQueueDepth
 	conf.DataQos.WriteChanQueCnt = conf.WriteThreadCnt // $WriteChanQueCnt is equal to $WriteThreadCnt, one-to-one
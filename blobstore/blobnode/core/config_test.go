@@ -44,3 +44,22 @@ func TestInitConfig(t *testing.T) {
 	err = InitConfig(conf)
 	require.Error(t, err)
 }
+
+func TestInitConfigIOEngine(t *testing.T) {
+	conf := &Config{
+		BaseConfig:    BaseConfig{Path: "/home"},
+		HandleIOError: func(ctx context.Context, diskID proto.DiskID, diskErr error) {},
+		AllocDiskID: func(ctx context.Context) (proto.DiskID, error) {
+			return proto.DiskID(1), nil
+		},
+	}
+	require.NoError(t, InitConfig(conf))
+	require.Equal(t, IOEngineSync, conf.IOEngine)
+	require.Equal(t, defaultIORingEntries, conf.IORingEntries)
+
+	conf.IOEngine = IOEngineIOUring
+	require.NoError(t, InitConfig(conf))
+
+	conf.IOEngine = "aio"
+	require.Error(t, InitConfig(conf))
+}
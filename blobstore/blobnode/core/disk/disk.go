@@ -172,6 +172,9 @@ func (ds *DiskStorage) Close(ctx context.Context) {
 
 	ds.writePool.Close()
 	ds.readPool.Close()
+	if ds.Conf.IORing != nil {
+		ds.Conf.IORing.Close()
+	}
 	ds.dataQos.Close()
 }
 
@@ -502,6 +505,15 @@ func newDiskStorage(ctx context.Context, conf core.Config) (ds *DiskStorage, err
 	writePool := taskpool.NewWritePool(conf.WriteThreadCnt, conf.WriteQueueDepth, metricConf)
 	readPool := taskpool.NewReadPool(conf.ReadThreadCnt, conf.ReadQueueDepth, metricConf)
 
+	if conf.IOEngine == core.IOEngineIOUring {
+		if ring, err := myos.NewIORing(conf.IORingEntries, conf.IORingBufferCnt, conf.IORingBufferSize); err != nil {
+			// fall back to io pools if kernel lacks support of io_uring
+			span.Warnf("disk:%d new io_uring failed, fall back to io engine %s, err:%v", dm.DiskID, core.IOEngineSync, err)
+		} else {
+			conf.IORing = ring
+		}
+	}
+
 	ds = &DiskStorage{
 		DiskID:           dm.DiskID,
 		SuperBlock:       sb,
@@ -131,7 +131,7 @@ type ShardMeta struct {
 	Offset  int64
 	Size    uint32
 	Crc     uint32
-	Padding [8]byte
+	Expire  int64 // unix seconds the shard expires, 0 means never
 	Inline  bool
 	Buffer  []byte
 }
@@ -145,6 +145,7 @@ type Shard struct {
 	Offset int64             // offset in data file. align when write
 	Crc    uint32            // crc for shard data
 	Flag   bnapi.ShardStatus // shard status
+	Expire int64             // unix seconds the shard expires, 0 means never
 
 	Inline bool   // shard data inline
 	Buffer []byte // inline data
@@ -178,7 +179,7 @@ func (sm *ShardMeta) Marshal() ([]byte, error) {
 	binary.LittleEndian.PutUint32(buf[16:20], uint32(sm.Size))
 	binary.LittleEndian.PutUint32(buf[20:24], uint32(sm.Crc))
 
-	copy(buf[24:32], sm.Padding[:])
+	binary.LittleEndian.PutUint64(buf[24:32], uint64(sm.Expire))
 
 	if sm.Inline && sm.Buffer != nil {
 		copy(buf[32:32+sm.Size], sm.Buffer)
@@ -199,7 +200,7 @@ func (sm *ShardMeta) Unmarshal(data []byte) error {
 	sm.Size = binary.LittleEndian.Uint32(data[16:20])
 	sm.Crc = binary.LittleEndian.Uint32(data[20:24])
 
-	copy(sm.Padding[:], data[24:32])
+	sm.Expire = int64(binary.LittleEndian.Uint64(data[24:32]))
 
 	sm.Inline = sm.Flag&bnapi.ShardDataInline != 0
 	if sm.Inline {
@@ -304,6 +305,7 @@ func (b *Shard) FillMeta(meta ShardMeta) {
 	b.Size = meta.Size
 	b.Crc = meta.Crc
 	b.Flag = meta.Flag
+	b.Expire = meta.Expire
 
 	b.Inline = meta.Inline
 	b.Buffer = meta.Buffer
@@ -350,6 +352,7 @@ func ShardCopy(src *Shard) (dest *Shard) {
 	dest.Offset = src.Offset
 	dest.Crc = src.Crc
 	dest.Flag = src.Flag
+	dest.Expire = src.Expire
 
 	dest.Body = src.Body
 	dest.From, dest.To = src.From, src.To
@@ -112,6 +112,7 @@ func TestShardMeta_Marshal(t *testing.T) {
 		Offset:  1024,
 		Size:    2048,
 		Crc:     4096,
+		Expire:  1700000000,
 	}
 
 	require.Equal(t, int(unsafe.Sizeof(ShardMeta{})) >= _ShardMetaSize, true)
@@ -143,5 +144,6 @@ func TestShardMeta_Marshal(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, true, sm1.Inline)
 	require.Equal(t, int(1), int(sm1.Flag))
+	require.Equal(t, sm.Expire, sm1.Expire)
 	require.Equal(t, sm.Buffer, sm1.Buffer)
 }
@@ -169,7 +169,12 @@ func NewChunkData(ctx context.Context, vm core.VuidMeta, file string, conf *core
 		conf.HandleIOError(context.Background(), vm.DiskID, err)
 	}
 
-	ef := core.NewBlobFile(fd, handleIOError, uint64(vm.ChunkId.VolumeUnitId()), readPool, writePool)
+	var ef core.BlobFile
+	if conf.IORing != nil {
+		ef = core.NewRingBlobFile(fd, handleIOError, uint64(vm.ChunkId.VolumeUnitId()), conf.IORing, readPool, writePool)
+	} else {
+		ef = core.NewBlobFile(fd, handleIOError, uint64(vm.ChunkId.VolumeUnitId()), readPool, writePool)
+	}
 
 	cd = &datafile{
 		File:   file,
@@ -90,6 +90,7 @@ func (stg *storage) Write(ctx context.Context, b *core.Shard) (err error) {
 		Crc:     b.Crc,
 		Offset:  b.Offset,
 		Flag:    b.Flag,
+		Expire:  b.Expire,
 	})
 }
 
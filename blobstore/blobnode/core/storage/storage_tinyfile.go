@@ -89,6 +89,7 @@ func (stg *tinyfileStorage) Write(ctx context.Context, b *core.Shard) (err error
 		Crc:     b.Crc,
 		Offset:  b.Offset,
 		Flag:    b.Flag,
+		Expire:  b.Expire,
 		Inline:  true,
 		Buffer:  buffer,
 	})
@@ -259,6 +259,7 @@ func (s *Service) ShardStat(c *rpc.Context) {
 		Crc:    sm.Crc,
 		Flag:   sm.Flag,
 		Inline: sm.Inline,
+		Expire: sm.Expire,
 	}
 	c.RespondJSON(stat)
 }
@@ -493,6 +494,7 @@ func (s *Service) ShardPut(c *rpc.Context) {
 	}
 
 	shard := core.NewShardWriter(args.Bid, args.Vuid, uint32(args.Size), c.Request.Body)
+	shard.Expire = args.Expire
 
 	start := time.Now()
 
@@ -0,0 +1,516 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build linux
+// +build linux
+
+package sys
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"unsafe"
+)
+
+// io_uring syscall numbers are the same on all architectures
+const (
+	sysIOUringSetup    = 425
+	sysIOUringEnter    = 426
+	sysIOUringRegister = 427
+)
+
+const (
+	ioringOffSQRing = 0
+	ioringOffCQRing = 0x8000000
+	ioringOffSQEs   = 0x10000000
+
+	ioringFeatSingleMmap = 1 << 0
+	ioringEnterGetEvents = 1 << 0
+	ioringRegisterBufs   = 0
+
+	ioringOpNop        = 0
+	ioringOpReadv      = 1
+	ioringOpWritev     = 2
+	ioringOpReadFixed  = 4
+	ioringOpWriteFixed = 5
+
+	sqeSize = 64
+	cqeSize = 16
+
+	// user data of the nop to stop the reaper
+	closeUserData = ^uint64(0)
+)
+
+type sqringOffsets struct {
+	head, tail, ringMask, ringEntries, flags, dropped, array, resv1 uint32
+	resv2                                                           uint64
+}
+
+type cqringOffsets struct {
+	head, tail, ringMask, ringEntries, overflow, cqes, flags, resv1 uint32
+	resv2                                                           uint64
+}
+
+type ioUringParams struct {
+	sqEntries, cqEntries, flags, sqThreadCPU, sqThreadIdle, features, wqFd uint32
+	resv                                                                   [3]uint32
+	sqOff                                                                  sqringOffsets
+	cqOff                                                                  cqringOffsets
+}
+
+// sqe is the submission queue entry of io_uring
+type sqe struct {
+	opcode      uint8
+	flags       uint8
+	ioprio      uint16
+	fd          int32
+	off         uint64
+	addr        uint64
+	len         uint32
+	rwFlags     uint32
+	userData    uint64
+	bufIndex    uint16
+	personality uint16
+	spliceFdIn  int32
+	addr3       uint64
+	pad         uint64
+}
+
+// cqe is the completion queue entry of io_uring
+type cqe struct {
+	userData uint64
+	res      int32
+	flags    uint32
+}
+
+type ioRequest struct {
+	opcode uint8
+	fd     int32
+	buf    []byte
+	off    int64
+	bufIdx int
+	iov    syscall.Iovec
+
+	res  int32
+	done chan struct{}
+}
+
+// IORing positional file io through io_uring, the requests are submitted in batch
+// by one goroutine and reaped by another one. Small requests are copied through
+// the registered buffers to save the cost of mapping user pages in kernel.
+type IORing struct {
+	fd      int
+	entries uint32
+
+	sqRing   []byte
+	cqRing   []byte
+	sqesMem  []byte
+	sqHead   *uint32
+	sqTail   *uint32
+	sqMask   uint32
+	sqArray  unsafe.Pointer
+	cqHead   *uint32
+	cqTail   *uint32
+	cqMask   uint32
+	cqesAddr unsafe.Pointer
+
+	bufMem   []byte
+	bufSize  int
+	freeBufs chan int
+
+	slotMu    sync.Mutex
+	slots     []*ioRequest
+	freeSlots chan uint64
+
+	reqCh     chan *ioRequest
+	failed    uint32 // errno of the reaper which stopped on a fatal error
+	closeMu   sync.RWMutex
+	isClosed  bool
+	closeOnce sync.Once
+	closed    chan struct{}
+	reaped    chan struct{}
+}
+
+// NewIORing returns io_uring with entries queue depth, and bufCnt buffers of
+// bufSize are registered for fixed io. Error is returned if the kernel lacks
+// support of io_uring or it is forbidden.
+func NewIORing(entries, bufCnt, bufSize int) (ring *IORing, err error) {
+	params := ioUringParams{}
+	fd, _, errno := syscall.Syscall(sysIOUringSetup, uintptr(entries), uintptr(unsafe.Pointer(&params)), 0)
+	if errno != 0 {
+		return nil, errno
+	}
+
+	ring = &IORing{fd: int(fd), entries: params.sqEntries, bufSize: bufSize}
+	defer func() {
+		if err != nil {
+			ring.release()
+			ring = nil
+		}
+	}()
+
+	sqRingSize := int(params.sqOff.array + params.sqEntries*4)
+	cqRingSize := int(params.cqOff.cqes + params.cqEntries*cqeSize)
+	if params.features&ioringFeatSingleMmap != 0 && cqRingSize > sqRingSize {
+		sqRingSize = cqRingSize
+	}
+	if ring.sqRing, err = mmapRing(ring.fd, ioringOffSQRing, sqRingSize); err != nil {
+		return
+	}
+	if params.features&ioringFeatSingleMmap != 0 {
+		ring.cqRing = ring.sqRing
+	} else if ring.cqRing, err = mmapRing(ring.fd, ioringOffCQRing, cqRingSize); err != nil {
+		return
+	}
+	if ring.sqesMem, err = mmapRing(ring.fd, ioringOffSQEs, int(params.sqEntries)*sqeSize); err != nil {
+		return
+	}
+
+	sq, cq := unsafe.Pointer(&ring.sqRing[0]), unsafe.Pointer(&ring.cqRing[0])
+	ring.sqHead = (*uint32)(unsafe.Add(sq, params.sqOff.head))
+	ring.sqTail = (*uint32)(unsafe.Add(sq, params.sqOff.tail))
+	ring.sqMask = *(*uint32)(unsafe.Add(sq, params.sqOff.ringMask))
+	ring.sqArray = unsafe.Add(sq, params.sqOff.array)
+	ring.cqHead = (*uint32)(unsafe.Add(cq, params.cqOff.head))
+	ring.cqTail = (*uint32)(unsafe.Add(cq, params.cqOff.tail))
+	ring.cqMask = *(*uint32)(unsafe.Add(cq, params.cqOff.ringMask))
+	ring.cqesAddr = unsafe.Add(cq, params.cqOff.cqes)
+
+	if bufCnt > 0 && bufSize > 0 {
+		if err = ring.registerBuffers(bufCnt, bufSize); err != nil {
+			return
+		}
+	}
+
+	// no more requests than sq entries are inflight, so that sq and cq never overflow
+	ring.slots = make([]*ioRequest, ring.entries)
+	ring.freeSlots = make(chan uint64, ring.entries)
+	for i := uint64(0); i < uint64(ring.entries); i++ {
+		ring.freeSlots <- i
+	}
+	ring.reqCh = make(chan *ioRequest, ring.entries)
+	ring.closed = make(chan struct{})
+	ring.reaped = make(chan struct{})
+
+	go ring.submitLoop()
+	go ring.reapLoop()
+	return ring, nil
+}
+
+func mmapRing(fd int, offset int64, size int) ([]byte, error) {
+	return syscall.Mmap(fd, offset, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED|syscall.MAP_POPULATE)
+}
+
+// registerBuffers registers anonymous mapped memory, which is never moved by go runtime.
+func (r *IORing) registerBuffers(bufCnt, bufSize int) (err error) {
+	r.bufMem, err = syscall.Mmap(-1, 0, bufCnt*bufSize, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+	if err != nil {
+		return
+	}
+	iovecs := make([]syscall.Iovec, bufCnt)
+	for i := range iovecs {
+		iovecs[i].Base = &r.bufMem[i*bufSize]
+		iovecs[i].SetLen(bufSize)
+	}
+	_, _, errno := syscall.Syscall6(sysIOUringRegister, uintptr(r.fd), ioringRegisterBufs,
+		uintptr(unsafe.Pointer(&iovecs[0])), uintptr(bufCnt), 0, 0)
+	if errno != 0 {
+		return errno
+	}
+
+	r.freeBufs = make(chan int, bufCnt)
+	for i := 0; i < bufCnt; i++ {
+		r.freeBufs <- i
+	}
+	return nil
+}
+
+func (r *IORing) enter(toSubmit, minComplete, flags uint32) (int, error) {
+	for {
+		n, _, errno := syscall.Syscall6(sysIOUringEnter, uintptr(r.fd), uintptr(toSubmit),
+			uintptr(minComplete), uintptr(flags), 0, 0)
+		if errno == syscall.EINTR || errno == syscall.EAGAIN || errno == syscall.EBUSY {
+			continue
+		}
+		if errno != 0 {
+			return 0, errno
+		}
+		return int(n), nil
+	}
+}
+
+func (r *IORing) sqeAt(idx uint32) *sqe {
+	return (*sqe)(unsafe.Pointer(&r.sqesMem[idx*sqeSize]))
+}
+
+func (r *IORing) cqeAt(idx uint32) *cqe {
+	return (*cqe)(unsafe.Add(r.cqesAddr, idx*cqeSize))
+}
+
+// submitLoop fills sqes with all pending requests and submits them in one syscall.
+func (r *IORing) submitLoop() {
+	batch := make([]*ioRequest, 0, r.entries)
+	for {
+		stop := false
+		select {
+		case req := <-r.reqCh:
+			batch = append(batch, req)
+		case <-r.closed:
+			stop = true
+		}
+	drain:
+		for len(batch) < cap(batch) {
+			select {
+			case req := <-r.reqCh:
+				batch = append(batch, req)
+			default:
+				break drain
+			}
+		}
+
+		if len(batch) > 0 {
+			userData := make([]uint64, len(batch))
+			for i, req := range batch {
+				userData[i] = <-r.freeSlots
+				r.slotMu.Lock()
+				r.slots[userData[i]] = req
+				r.slotMu.Unlock()
+			}
+			if errno := syscall.Errno(atomic.LoadUint32(&r.failed)); errno != 0 {
+				// nobody reaps the completions of a failed ring
+				for _, ud := range userData {
+					r.complete(ud, -int32(errno))
+				}
+			} else {
+				r.submit(batch, userData)
+			}
+			batch = batch[:0]
+		}
+
+		// no more request could be sent after closed, wake up the reaper at last
+		if stop && len(r.reqCh) == 0 {
+			r.submit([]*ioRequest{{opcode: ioringOpNop, fd: -1}}, []uint64{closeUserData})
+			return
+		}
+	}
+}
+
+func (r *IORing) submit(batch []*ioRequest, userData []uint64) {
+	tail := atomic.LoadUint32(r.sqTail)
+	for i, req := range batch {
+		idx := tail & r.sqMask
+		entry := r.sqeAt(idx)
+		*entry = sqe{opcode: req.opcode, fd: req.fd, off: uint64(req.off), userData: userData[i]}
+		switch req.opcode {
+		case ioringOpReadFixed, ioringOpWriteFixed:
+			entry.addr = uint64(uintptr(unsafe.Pointer(&r.bufMem[req.bufIdx*r.bufSize])))
+			entry.len = uint32(len(req.buf))
+			entry.bufIndex = uint16(req.bufIdx)
+		case ioringOpReadv, ioringOpWritev:
+			req.iov.Base = &req.buf[0]
+			req.iov.SetLen(len(req.buf))
+			entry.addr = uint64(uintptr(unsafe.Pointer(&req.iov)))
+			entry.len = 1
+		}
+		*(*uint32)(unsafe.Add(r.sqArray, idx*4)) = idx
+		tail++
+	}
+	atomic.StoreUint32(r.sqTail, tail)
+
+	for submitted := 0; submitted < len(batch); {
+		n, err := r.enter(uint32(len(batch)-submitted), 0, 0)
+		if err != nil {
+			// the left requests are not consumed by kernel, fail them
+			atomic.StoreUint32(r.sqTail, tail-uint32(len(batch)-submitted))
+			errno := err.(syscall.Errno)
+			for _, ud := range userData[submitted:] {
+				r.complete(ud, -int32(errno))
+			}
+			return
+		}
+		submitted += n
+	}
+}
+
+// reapLoop waits completions and wakes up the requests.
+func (r *IORing) reapLoop() {
+	defer close(r.reaped)
+	stop := false
+	for {
+		if _, err := r.enter(0, 1, ioringEnterGetEvents); err != nil {
+			r.fail(err.(syscall.Errno))
+			return
+		}
+		head := atomic.LoadUint32(r.cqHead)
+		tail := atomic.LoadUint32(r.cqTail)
+		for ; head != tail; head++ {
+			entry := r.cqeAt(head & r.cqMask)
+			if entry.userData == closeUserData {
+				stop = true
+				continue
+			}
+			r.complete(entry.userData, entry.res)
+		}
+		atomic.StoreUint32(r.cqHead, head)
+		// exits after all inflight requests completed
+		if stop && len(r.freeSlots) == cap(r.freeSlots) {
+			return
+		}
+	}
+}
+
+// fail marks the ring failed if the reaper cannot wait completions any more,
+// the inflight requests are completed with errno as they are never reaped,
+// and the new requests are rejected.
+func (r *IORing) fail(errno syscall.Errno) {
+	atomic.StoreUint32(&r.failed, uint32(errno))
+	for ud := range r.slots {
+		r.complete(uint64(ud), -int32(errno))
+	}
+}
+
+// complete wakes up the request of userData, it's a noop if the request has been completed.
+func (r *IORing) complete(userData uint64, res int32) {
+	if userData == closeUserData {
+		return
+	}
+	r.slotMu.Lock()
+	req := r.slots[userData]
+	r.slots[userData] = nil
+	r.slotMu.Unlock()
+	if req == nil {
+		return
+	}
+	r.freeSlots <- userData
+
+	req.res = res
+	close(req.done)
+}
+
+func (r *IORing) do(req *ioRequest) (int, error) {
+	req.done = make(chan struct{})
+	r.closeMu.RLock()
+	if r.isClosed {
+		r.closeMu.RUnlock()
+		return 0, syscall.EBADF
+	}
+	if errno := syscall.Errno(atomic.LoadUint32(&r.failed)); errno != 0 {
+		r.closeMu.RUnlock()
+		return 0, errno
+	}
+	r.reqCh <- req
+	r.closeMu.RUnlock()
+	<-req.done
+	if req.res < 0 {
+		return 0, syscall.Errno(-req.res)
+	}
+	return int(req.res), nil
+}
+
+// rw does one io of b, the registered buffer is used if b is small enough and
+// any buffer is free, otherwise b is mapped by the kernel directly.
+func (r *IORing) rw(write bool, fd uintptr, b []byte, off int64) (int, error) {
+	req := &ioRequest{fd: int32(fd), buf: b, off: off, bufIdx: -1}
+	if len(b) <= r.bufSize {
+		select {
+		case req.bufIdx = <-r.freeBufs:
+			defer func() { r.freeBufs <- req.bufIdx }()
+		default:
+		}
+	}
+
+	if req.bufIdx < 0 {
+		req.opcode = ioringOpReadv
+		if write {
+			req.opcode = ioringOpWritev
+		}
+		return r.do(req)
+	}
+
+	fixed := r.bufMem[req.bufIdx*r.bufSize : req.bufIdx*r.bufSize+len(b)]
+	req.opcode = ioringOpReadFixed
+	if write {
+		req.opcode = ioringOpWriteFixed
+		copy(fixed, b)
+	}
+	n, err := r.do(req)
+	if !write && n > 0 {
+		copy(b, fixed[:n])
+	}
+	return n, err
+}
+
+// ReadAt reads len(b) bytes from the file at offset off like os.File.ReadAt,
+// io.EOF is returned if fewer bytes are read.
+func (r *IORing) ReadAt(fd uintptr, b []byte, off int64) (n int, err error) {
+	for len(b) > 0 {
+		m, e := r.rw(false, fd, b, off)
+		if e != nil {
+			err = e
+			break
+		}
+		if m == 0 {
+			return n, io.EOF
+		}
+		n += m
+		b = b[m:]
+		off += int64(m)
+	}
+	return
+}
+
+// WriteAt writes len(b) bytes to the file at offset off like os.File.WriteAt.
+func (r *IORing) WriteAt(fd uintptr, b []byte, off int64) (n int, err error) {
+	for len(b) > 0 {
+		m, e := r.rw(true, fd, b, off)
+		if e != nil {
+			err = e
+			break
+		}
+		if m == 0 {
+			return n, io.ErrShortWrite
+		}
+		n += m
+		b = b[m:]
+		off += int64(m)
+	}
+	return
+}
+
+// Close stops the ring after inflight requests completed.
+func (r *IORing) Close() error {
+	r.closeOnce.Do(func() {
+		r.closeMu.Lock()
+		r.isClosed = true
+		close(r.closed)
+		r.closeMu.Unlock()
+		<-r.reaped
+		r.release()
+	})
+	return nil
+}
+
+func (r *IORing) release() {
+	if r.cqRing != nil && r.sqRing != nil && &r.cqRing[0] == &r.sqRing[0] {
+		r.cqRing = nil
+	}
+	for _, mem := range [][]byte{r.sqesMem, r.bufMem, r.cqRing, r.sqRing} {
+		if mem != nil {
+			syscall.Munmap(mem)
+		}
+	}
+	syscall.Close(r.fd)
+}
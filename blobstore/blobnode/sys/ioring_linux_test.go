@@ -0,0 +1,119 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build linux
+// +build linux
+
+package sys
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIORing(t *testing.T) {
+	ring, err := NewIORing(8, 2, 4096)
+	if err != nil {
+		t.Skipf("io_uring is not supported: %v", err)
+	}
+	defer ring.Close()
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "ioring"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	// fixed buffer and vectored io
+	small := bytes.Repeat([]byte("a"), 1024)
+	large := bytes.Repeat([]byte("b"), 8192)
+	n, err := ring.WriteAt(f.Fd(), small, 0)
+	require.NoError(t, err)
+	require.Equal(t, len(small), n)
+	n, err = ring.WriteAt(f.Fd(), large, int64(len(small)))
+	require.NoError(t, err)
+	require.Equal(t, len(large), n)
+
+	buf := make([]byte, len(small)+len(large))
+	n, err = ring.ReadAt(f.Fd(), buf, 0)
+	require.NoError(t, err)
+	require.Equal(t, len(buf), n)
+	require.Equal(t, small, buf[:len(small)])
+	require.Equal(t, large, buf[len(small):])
+
+	// read beyond file size
+	n, err = ring.ReadAt(f.Fd(), make([]byte, 100), int64(len(buf)-10))
+	require.ErrorIs(t, err, io.EOF)
+	require.Equal(t, 10, n)
+
+	// concurrent requests more than queue depth
+	var wg sync.WaitGroup
+	for i := 0; i < 64; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			data := bytes.Repeat([]byte{byte(i)}, 512)
+			_, err := ring.WriteAt(f.Fd(), data, int64(i*512))
+			require.NoError(t, err)
+			got := make([]byte, 512)
+			_, err = ring.ReadAt(f.Fd(), got, int64(i*512))
+			require.NoError(t, err)
+			require.Equal(t, data, got)
+		}(i)
+	}
+	wg.Wait()
+
+	// bad fd
+	_, err = ring.ReadAt(uintptr(0x12345), buf, 0)
+	require.Error(t, err)
+
+	require.NoError(t, ring.Close())
+	_, err = ring.ReadAt(f.Fd(), buf, 0)
+	require.Error(t, err)
+}
+
+func TestIORingFailed(t *testing.T) {
+	ring, err := NewIORing(8, 2, 4096)
+	if err != nil {
+		t.Skipf("io_uring is not supported: %v", err)
+	}
+	pr, pw, err := os.Pipe()
+	require.NoError(t, err)
+	defer pr.Close()
+	defer pw.Close()
+
+	// the read of empty pipe is inflight until the ring failed
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := ring.ReadAt(pr.Fd(), make([]byte, 16), 0)
+		errCh <- err
+	}()
+	require.Eventually(t, func() bool { return len(ring.freeSlots) < cap(ring.freeSlots) },
+		time.Second, 10*time.Millisecond)
+	ring.fail(syscall.EIO)
+	require.ErrorIs(t, <-errCh, syscall.EIO)
+	_, err = ring.ReadAt(pr.Fd(), make([]byte, 16), 0)
+	require.ErrorIs(t, err, syscall.EIO)
+
+	// the late completion is dropped
+	_, err = pw.Write(make([]byte, 16))
+	require.NoError(t, err)
+	require.NoError(t, ring.Close())
+}
@@ -0,0 +1,38 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build !linux
+// +build !linux
+
+package sys
+
+import "syscall"
+
+type IORing struct{}
+
+func NewIORing(entries, bufCnt, bufSize int) (*IORing, error) {
+	return nil, syscall.ENOTSUP
+}
+
+func (r *IORing) ReadAt(fd uintptr, b []byte, off int64) (int, error) {
+	return 0, syscall.ENOTSUP
+}
+
+func (r *IORing) WriteAt(fd uintptr, b []byte, off int64) (int, error) {
+	return 0, syscall.ENOTSUP
+}
+
+func (r *IORing) Close() error {
+	return nil
+}
@@ -0,0 +1,151 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package blobnode
+
+import (
+	"bytes"
+	"context"
+
+	bnapi "github.com/cubefs/cubefs/blobstore/api/blobnode"
+	"github.com/cubefs/cubefs/blobstore/api/scheduler"
+	"github.com/cubefs/cubefs/blobstore/blobnode/base"
+	"github.com/cubefs/cubefs/blobstore/blobnode/base/workutils"
+	"github.com/cubefs/cubefs/blobstore/blobnode/client"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/util/errors"
+	"github.com/cubefs/cubefs/blobstore/util/retry"
+)
+
+// ErrClusterMigrateTaskStopped cluster migrate task stopped by renewal failure
+var ErrClusterMigrateTaskStopped = errors.New("cluster migrate task stopped")
+
+// ClusterMigrateTaskMgr cluster migrate task manager
+type ClusterMigrateTaskMgr struct {
+	*volumeTaskRunner
+	downloadShardConcurrency int
+
+	blobnode client.IBlobNode
+	reporter scheduler.IClusterMigrator
+}
+
+// NewClusterMigrateTaskMgr returns cluster migrate task manager
+func NewClusterMigrateTaskMgr(idc string, concurrency, downloadShardConcurrency int, blobnode client.IBlobNode,
+	reporter scheduler.IClusterMigrator, renewalCli scheduler.IMigrator,
+) *ClusterMigrateTaskMgr {
+	return &ClusterMigrateTaskMgr{
+		volumeTaskRunner:         newVolumeTaskRunner(idc, proto.TaskTypeClusterMigrate, concurrency, renewalCli),
+		downloadShardConcurrency: downloadShardConcurrency,
+		blobnode:                 blobnode,
+		reporter:                 reporter,
+	}
+}
+
+// AddTask adds cluster migrate task
+func (mgr *ClusterMigrateTaskMgr) AddTask(ctx context.Context, task *proto.ClusterMigrateTask) error {
+	span := trace.SpanFromContextSafe(ctx)
+	if err := base.ValidateCodeMode(task.CodeMode); err != nil {
+		return err
+	}
+
+	var ret *proto.ClusterMigrateRet
+	return mgr.runTask(ctx, task.TaskID, func(taskCtx context.Context) {
+		ret = mgr.doMigrate(taskCtx, task)
+	}, func() {
+		if err := mgr.reporter.CompleteClusterMigrateTask(ctx, ret); err != nil {
+			span.Errorf("report cluster migrate result failed: result[%+v], err[%+v]", ret, err)
+		}
+		span.Infof("finish cluster migrate: taskID[%s], migrated[%d], err[%s]",
+			task.TaskID, ret.MigratedCnt, ret.MigrateErrStr)
+	})
+}
+
+func (mgr *ClusterMigrateTaskMgr) doMigrate(ctx context.Context, task *proto.ClusterMigrateTask) *proto.ClusterMigrateRet {
+	span := trace.SpanFromContextSafe(ctx)
+	ret := &proto.ClusterMigrateRet{TaskID: task.TaskID}
+
+	if !task.IsValid() {
+		ret.MigrateErrStr = "unexpect:invalid cluster migrate task"
+		return ret
+	}
+
+	bids, err := GetBenchmarkBids(ctx, mgr.blobnode, task.Sources, task.CodeMode, nil)
+	if err != nil {
+		span.Errorf("get benchmark bids failed: taskID[%s], err[%+v]", task.TaskID, err)
+		ret.MigrateErrStr = err.Error()
+		return ret
+	}
+	span.Infof("start cluster migrate: taskID[%s], vid[%d] -> cluster[%d] vid[%d], bids len[%d]",
+		task.TaskID, task.SourceVid, task.TargetClusterID, task.TargetVid, len(bids))
+
+	tasklets, wErr := BidsSplit(ctx, bids, workutils.TaskBufPool.GetMigrateBufSize())
+	if wErr != nil {
+		ret.MigrateErrStr = wErr.Error()
+		return ret
+	}
+	for _, tasklet := range tasklets {
+		if ctx.Err() != nil {
+			ret.MigrateErrStr = ErrClusterMigrateTaskStopped.Error()
+			return ret
+		}
+		if err = mgr.migrateTasklet(ctx, task, tasklet); err != nil {
+			span.Errorf("migrate tasklet failed: taskID[%s], err[%+v]", task.TaskID, err)
+			ret.MigrateErrStr = err.Error()
+			return ret
+		}
+		ret.MigratedCnt += len(tasklet.bids)
+	}
+	return ret
+}
+
+// migrateTasklet copies shards of the source volume into the same index of
+// target volume one by one, the blob keeps its bid in the target volume.
+// The shard is recovered by the stripe if it is missing in source volume.
+func (mgr *ClusterMigrateTaskMgr) migrateTasklet(ctx context.Context, task *proto.ClusterMigrateTask, tasklet Tasklet) error {
+	for idx, dest := range task.Destinations {
+		if ctx.Err() != nil {
+			return ErrClusterMigrateTaskStopped
+		}
+		if err := mgr.migrateShards(ctx, task, tasklet, uint8(idx), dest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (mgr *ClusterMigrateTaskMgr) migrateShards(ctx context.Context, task *proto.ClusterMigrateTask,
+	tasklet Tasklet, idx uint8, dest proto.VunitLocation,
+) error {
+	shardRecover := NewShardRecover(task.Sources, task.CodeMode, tasklet.bids,
+		mgr.blobnode, mgr.downloadShardConcurrency, proto.TaskTypeClusterMigrate)
+	defer shardRecover.ReleaseBuf()
+	if err := shardRecover.RecoverShards(ctx, []uint8{idx}, true); err != nil {
+		return err
+	}
+
+	for _, bid := range tasklet.bids {
+		shard, err := shardRecover.GetShard(idx, bid.Bid)
+		if err != nil {
+			return err
+		}
+		err = retry.Timed(3, 1000).On(func() error {
+			return mgr.blobnode.PutShard(ctx, dest, bid.Bid, int64(len(shard)), bid.Expire, bytes.NewReader(shard), bnapi.BackgroundIO)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
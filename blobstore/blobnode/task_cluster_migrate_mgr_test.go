@@ -0,0 +1,118 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package blobnode
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/api/scheduler"
+	"github.com/cubefs/cubefs/blobstore/blobnode/base/workutils"
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/testing/mocks"
+)
+
+func newMockClusterMigrateReporter(t *testing.T) *mocks.MockIScheduler {
+	cli := mocks.NewMockIScheduler(C(t))
+	cli.EXPECT().CompleteClusterMigrateTask(A, A).AnyTimes().Return(nil)
+	cli.EXPECT().RenewalTask(A, A).AnyTimes().Return(&scheduler.TaskRenewalRet{}, nil)
+	return cli
+}
+
+func TestClusterMigrateTaskMgrDo(t *testing.T) {
+	workutils.TaskBufPool = workutils.NewBufPool(&workutils.BufConfig{
+		MigrateBufSize:     4 * 1024,
+		MigrateBufCapacity: 100,
+		RepairBufSize:      1,
+		RepairBufCapacity:  1,
+	})
+	defer func() { workutils.TaskBufPool = nil }()
+
+	mode := codemode.EC6P6
+	sources := genMockVol(1, mode)
+	destinations := genMockVol(2, mode)
+	bids := []proto.BlobID{1, 2, 3}
+	sizes := []int64{10, 1024, 2048}
+	getter := NewMockGetterWithBids(append(sources, destinations...), mode, bids, sizes)
+
+	// the missing shard is recovered by the stripe
+	crc := getter.getShardCrc32(sources[0].Vuid, 2)
+	getter.MissSomeReplicaBid(sources, map[proto.BlobID][]int{2: {0}})
+
+	reporter := newMockClusterMigrateReporter(t)
+	mgr := NewClusterMigrateTaskMgr("z0", 1, 1, getter, reporter, reporter)
+	task := &proto.ClusterMigrateTask{
+		TaskID:          "cluster_migrate-1-xxx",
+		State:           proto.MigrateStatePrepared,
+		CodeMode:        mode,
+		SourceVid:       1,
+		Sources:         sources,
+		TargetClusterID: 2,
+		TargetVid:       2,
+		Destinations:    destinations,
+	}
+	ret := mgr.doMigrate(context.Background(), task)
+	require.NoError(t, ret.Err())
+	require.Equal(t, len(bids), ret.MigratedCnt)
+
+	for _, bid := range bids {
+		for idx, dest := range destinations {
+			if bid == 2 && idx == 0 {
+				require.Equal(t, crc, getter.getShardCrc32(dest.Vuid, bid))
+				continue
+			}
+			require.Equal(t, getter.getShardCrc32(sources[idx].Vuid, bid), getter.getShardCrc32(dest.Vuid, bid))
+		}
+	}
+
+	// stopped
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ret = mgr.doMigrate(ctx, task)
+	require.Error(t, ret.Err())
+
+	// invalid task
+	task.Destinations = destinations[:1]
+	ret = mgr.doMigrate(context.Background(), task)
+	require.Error(t, ret.Err())
+}
+
+func TestClusterMigrateTaskMgrAddTask(t *testing.T) {
+	reported := make(chan *proto.ClusterMigrateRet, 1)
+	reporter := mocks.NewMockIScheduler(C(t))
+	reporter.EXPECT().CompleteClusterMigrateTask(A, A).DoAndReturn(
+		func(_ context.Context, ret *proto.ClusterMigrateRet) error {
+			reported <- ret
+			return errMock
+		})
+	mgr := NewClusterMigrateTaskMgr("z0", 1, 1, NewMockGetter(genMockVol(1, codemode.EC6P6), codemode.EC6P6), reporter, reporter)
+
+	// replicate codemode is rejected before running
+	err := mgr.AddTask(context.Background(), &proto.ClusterMigrateTask{TaskID: "cluster_migrate-1-xxx", CodeMode: codemode.Replica3})
+	require.Error(t, err)
+	require.Equal(t, 0, mgr.RunningTaskSize())
+
+	// the result of invalid task is reported even if report failed
+	err = mgr.AddTask(context.Background(), &proto.ClusterMigrateTask{TaskID: "cluster_migrate-1-xxx", CodeMode: codemode.EC6P6})
+	require.NoError(t, err)
+	ret := <-reported
+	require.Equal(t, "cluster_migrate-1-xxx", ret.TaskID)
+	require.Error(t, ret.Err())
+	require.Eventually(t, func() bool { return mgr.RunningTaskSize() == 0 }, time.Second, 10*time.Millisecond)
+}
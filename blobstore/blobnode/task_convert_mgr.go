@@ -0,0 +1,277 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package blobnode
+
+import (
+	"bytes"
+	"context"
+
+	bnapi "github.com/cubefs/cubefs/blobstore/api/blobnode"
+	"github.com/cubefs/cubefs/blobstore/api/scheduler"
+	"github.com/cubefs/cubefs/blobstore/blobnode/base"
+	"github.com/cubefs/cubefs/blobstore/blobnode/base/workutils"
+	"github.com/cubefs/cubefs/blobstore/blobnode/client"
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+	"github.com/cubefs/cubefs/blobstore/common/ec"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/util/errors"
+	"github.com/cubefs/cubefs/blobstore/util/retry"
+)
+
+// ErrConvertTaskStopped convert task stopped by renewal failure
+var ErrConvertTaskStopped = errors.New("convert task stopped")
+
+// ConvertTaskMgr codemode convert task manager
+type ConvertTaskMgr struct {
+	*volumeTaskRunner
+	downloadShardConcurrency int
+
+	blobnode client.IBlobNode
+	reporter scheduler.IConverter
+}
+
+// NewConvertTaskMgr returns codemode convert task manager
+func NewConvertTaskMgr(idc string, concurrency, downloadShardConcurrency int, blobnode client.IBlobNode,
+	reporter scheduler.IConverter, renewalCli scheduler.IMigrator,
+) *ConvertTaskMgr {
+	return &ConvertTaskMgr{
+		volumeTaskRunner:         newVolumeTaskRunner(idc, proto.TaskTypeCodeModeConvert, concurrency, renewalCli),
+		downloadShardConcurrency: downloadShardConcurrency,
+		blobnode:                 blobnode,
+		reporter:                 reporter,
+	}
+}
+
+// AddTask adds convert task
+func (mgr *ConvertTaskMgr) AddTask(ctx context.Context, task *proto.CodeModeConvertTask) error {
+	span := trace.SpanFromContextSafe(ctx)
+	if err := base.ValidateCodeMode(task.SourceCodeMode); err != nil {
+		return err
+	}
+	if err := base.ValidateCodeMode(task.TargetCodeMode); err != nil {
+		return err
+	}
+
+	var ret *proto.CodeModeConvertRet
+	return mgr.runTask(ctx, task.TaskID, func(taskCtx context.Context) {
+		ret = mgr.doConvert(taskCtx, task)
+	}, func() {
+		if err := mgr.reporter.CompleteConvertTask(ctx, ret); err != nil {
+			span.Errorf("report convert result failed: result[%+v], err[%+v]", ret, err)
+		}
+		span.Infof("finish convert: taskID[%s], converted[%d], skipped[%d], err[%s]",
+			task.TaskID, ret.ConvertedCnt, len(ret.SkippedBids), ret.ConvertErrStr)
+	})
+}
+
+func (mgr *ConvertTaskMgr) doConvert(ctx context.Context, task *proto.CodeModeConvertTask) *proto.CodeModeConvertRet {
+	span := trace.SpanFromContextSafe(ctx)
+	ret := &proto.CodeModeConvertRet{TaskID: task.TaskID}
+
+	if !task.IsValid() {
+		ret.ConvertErrStr = "unexpect:invalid convert task"
+		return ret
+	}
+
+	bids, err := GetBenchmarkBids(ctx, mgr.blobnode, task.Sources, task.SourceCodeMode, nil)
+	if err != nil {
+		span.Errorf("get benchmark bids failed: taskID[%s], err[%+v]", task.TaskID, err)
+		ret.ConvertErrStr = err.Error()
+		return ret
+	}
+	span.Infof("start convert: taskID[%s], vid[%d] -> vid[%d], bids len[%d]",
+		task.TaskID, task.SourceVid, task.TargetVid, len(bids))
+	for _, bid := range bids {
+		if bid.Bid > ret.MaxBid {
+			ret.MaxBid = bid.Bid
+		}
+	}
+
+	tasklets, wErr := BidsSplit(ctx, bids, workutils.TaskBufPool.GetMigrateBufSize())
+	if wErr != nil {
+		ret.ConvertErrStr = wErr.Error()
+		return ret
+	}
+	var convertedBids []proto.BlobID
+	for _, tasklet := range tasklets {
+		if ctx.Err() != nil {
+			ret.ConvertErrStr = ErrConvertTaskStopped.Error()
+			return ret
+		}
+		converted, skipped, err := mgr.convertTasklet(ctx, task, tasklet)
+		if err != nil {
+			span.Errorf("convert tasklet failed: taskID[%s], err[%+v]", task.TaskID, err)
+			ret.ConvertErrStr = err.Error()
+			return ret
+		}
+		convertedBids = append(convertedBids, converted...)
+		ret.SkippedBids = append(ret.SkippedBids, skipped...)
+	}
+
+	cleared, err := mgr.clearDeleted(ctx, task, convertedBids)
+	if err != nil {
+		span.Errorf("clear deleted blobs failed: taskID[%s], err[%+v]", task.TaskID, err)
+		ret.ConvertErrStr = err.Error()
+		return ret
+	}
+	ret.ConvertedCnt = len(convertedBids) - cleared
+	return ret
+}
+
+// clearDeleted deletes the converted copies of blobs which have been deleted from the source
+// volume when converting. The deletes after the blobs listed again are sent to the target
+// volume by access with the converting record, the blob is never leaked in target volume.
+func (mgr *ConvertTaskMgr) clearDeleted(ctx context.Context, task *proto.CodeModeConvertTask,
+	converted []proto.BlobID,
+) (cleared int, err error) {
+	span := trace.SpanFromContextSafe(ctx)
+	if len(converted) == 0 {
+		return
+	}
+
+	bids, err := GetBenchmarkBids(ctx, mgr.blobnode, task.Sources, task.SourceCodeMode, nil)
+	if err != nil {
+		return
+	}
+	alive := make(map[proto.BlobID]struct{}, len(bids))
+	for _, bid := range bids {
+		alive[bid.Bid] = struct{}{}
+	}
+	for _, bid := range converted {
+		if _, ok := alive[bid]; ok {
+			continue
+		}
+		for _, dest := range task.Destinations {
+			err = retry.Timed(3, 1000).On(func() error {
+				return mgr.blobnode.DeleteShard(ctx, dest, bid)
+			})
+			if err != nil {
+				return
+			}
+		}
+		span.Infof("clear blob deleted when converting: taskID[%s], vid[%d], bid[%d]", task.TaskID, task.TargetVid, bid)
+		cleared++
+	}
+	return
+}
+
+// convertTasklet reads data shards of the source stripe and re-encodes the blobs
+// with the target codemode, the blob keeps its bid in the target volume.
+func (mgr *ConvertTaskMgr) convertTasklet(ctx context.Context, task *proto.CodeModeConvertTask,
+	tasklet Tasklet,
+) (converted, skipped []proto.BlobID, err error) {
+	srcTactic := task.SourceCodeMode.Tactic()
+	dataIdxs := make([]uint8, srcTactic.N)
+	for idx := range dataIdxs {
+		dataIdxs[idx] = uint8(idx)
+	}
+
+	shardRecover := NewShardRecover(task.Sources, task.SourceCodeMode, tasklet.bids,
+		mgr.blobnode, mgr.downloadShardConcurrency, proto.TaskTypeCodeModeConvert)
+	defer shardRecover.ReleaseBuf()
+	if err = shardRecover.RecoverShards(ctx, dataIdxs, true); err != nil {
+		return
+	}
+
+	encoder, err := ec.NewEncoder(ec.Config{CodeMode: task.TargetCodeMode.Tactic()})
+	if err != nil {
+		return
+	}
+
+	data := make([]byte, 0, int64(srcTactic.N)*tasklet.bids[0].Size)
+	for _, bid := range tasklet.bids {
+		data = data[:0]
+		for _, idx := range dataIdxs {
+			shard, errGet := shardRecover.GetShard(idx, bid.Bid)
+			if errGet != nil {
+				return converted, skipped, errGet
+			}
+			data = append(data, shard...)
+		}
+
+		size, ok := convertedBlobSize(data, task.SourceCodeMode, task.TargetCodeMode)
+		if !ok {
+			skipped = append(skipped, bid.Bid)
+			continue
+		}
+		if err = mgr.putBlob(ctx, encoder, task, bid, data[:size]); err != nil {
+			return
+		}
+		converted = append(converted, bid.Bid)
+	}
+	return
+}
+
+func (mgr *ConvertTaskMgr) putBlob(ctx context.Context, encoder ec.Encoder, task *proto.CodeModeConvertTask,
+	bid *ShardInfoSimple, data []byte,
+) error {
+	sizes, err := ec.GetBufferSizes(len(data), task.TargetCodeMode.Tactic())
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, sizes.ECDataSize, sizes.ECSize)
+	copy(buf, data)
+
+	shards, err := encoder.Split(buf)
+	if err != nil {
+		return err
+	}
+	if err = encoder.Encode(shards); err != nil {
+		return err
+	}
+
+	for idx, dest := range task.Destinations {
+		shard := shards[idx]
+		err = retry.Timed(3, 1000).On(func() error {
+			return mgr.blobnode.PutShard(ctx, dest, bid.Bid, int64(len(shard)), bid.Expire, bytes.NewReader(shard), bnapi.BackgroundIO)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// convertedBlobSize returns the data size to re-encode of the padded source blob.
+// The real blob size is unknown on blobnode, it is in range of [lo, hi], lo is the
+// end of last nonzero byte or the smallest size which has the same shard size, hi is
+// the padded size. Blob could be re-encoded only if all sizes in the range have the
+// same target shard size, the zero padding tail makes the same shards.
+func convertedBlobSize(data []byte, source, target codemode.CodeMode) (int, bool) {
+	srcTactic := source.Tactic()
+	shardSize := len(data) / srcTactic.N
+
+	lo := len(data)
+	for lo > 1 && data[lo-1] == 0 {
+		lo--
+	}
+	if shardSize > srcTactic.MinShardSize {
+		if minSize := srcTactic.N*(shardSize-1) + 1; lo < minSize {
+			lo = minSize
+		}
+	}
+	hi := len(data)
+
+	loSizes, err := ec.GetBufferSizes(lo, target.Tactic())
+	if err != nil {
+		return 0, false
+	}
+	hiSizes, err := ec.GetBufferSizes(hi, target.Tactic())
+	if err != nil {
+		return 0, false
+	}
+	return lo, loSizes.ShardSize == hiSizes.ShardSize
+}
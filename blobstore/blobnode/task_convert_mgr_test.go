@@ -0,0 +1,154 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package blobnode
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/api/scheduler"
+	"github.com/cubefs/cubefs/blobstore/blobnode/base/workutils"
+	"github.com/cubefs/cubefs/blobstore/blobnode/client"
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/testing/mocks"
+)
+
+func newMockConvertReporter(t *testing.T) *mocks.MockIScheduler {
+	cli := mocks.NewMockIScheduler(C(t))
+	cli.EXPECT().CompleteConvertTask(A, A).AnyTimes().Return(nil)
+	cli.EXPECT().RenewalTask(A, A).AnyTimes().Return(&scheduler.TaskRenewalRet{}, nil)
+	return cli
+}
+
+func TestConvertedBlobSize(t *testing.T) {
+	// small blob with min shard size
+	data := make([]byte, 6*2048)
+	data[100] = 1
+	size, ok := convertedBlobSize(data, codemode.EC6P6, codemode.EC6P10L2)
+	require.True(t, ok)
+	require.Equal(t, 101, size)
+
+	// all zero blob
+	size, ok = convertedBlobSize(make([]byte, 6*2048), codemode.EC6P6, codemode.EC12P4)
+	require.True(t, ok)
+	require.Equal(t, 1, size)
+
+	// source range [6*4095+1, 6*4096] keeps the same shard size of 12 data shards
+	data = make([]byte, 6*4096)
+	size, ok = convertedBlobSize(data, codemode.EC6P6, codemode.EC12P4)
+	require.True(t, ok)
+	require.Equal(t, 6*4095+1, size)
+
+	// source range [12*4095+1, 12*4096] has two shard sizes of 6 data shards
+	data = make([]byte, 12*4096)
+	_, ok = convertedBlobSize(data, codemode.EC12P4, codemode.EC6P6)
+	require.False(t, ok)
+	data[len(data)-1] = 1
+	size, ok = convertedBlobSize(data, codemode.EC12P4, codemode.EC6P6)
+	require.True(t, ok)
+	require.Equal(t, 12*4096, size)
+}
+
+func TestConvertTaskMgrDo(t *testing.T) {
+	workutils.TaskBufPool = workutils.NewBufPool(&workutils.BufConfig{
+		MigrateBufSize:     4 * 1024,
+		MigrateBufCapacity: 100,
+		RepairBufSize:      1,
+		RepairBufCapacity:  1,
+	})
+	defer func() { workutils.TaskBufPool = nil }()
+
+	srcMode, dstMode := codemode.EC6P6, codemode.EC6P10L2
+	sources := genMockVol(1, srcMode)
+	destinations := genMockVol(2, dstMode)
+	bids := []proto.BlobID{1, 2, 3}
+	sizes := []int64{10, 1024, 2048}
+	getter := NewMockGetterWithBids(append(sources, destinations...), srcMode, bids, sizes)
+
+	reporter := newMockConvertReporter(t)
+	mgr := NewConvertTaskMgr("z0", 1, 1, getter, reporter, reporter)
+	task := &proto.CodeModeConvertTask{
+		TaskID:         "codemode_convert-1-xxx",
+		State:          proto.MigrateStatePrepared,
+		SourceVid:      1,
+		SourceCodeMode: srcMode,
+		Sources:        sources,
+		TargetVid:      2,
+		TargetCodeMode: dstMode,
+		Destinations:   destinations,
+	}
+	ret := mgr.doConvert(context.Background(), task)
+	require.NoError(t, ret.Err())
+	require.Equal(t, len(bids), ret.ConvertedCnt)
+	require.Equal(t, 0, len(ret.SkippedBids))
+	require.Equal(t, proto.BlobID(3), ret.MaxBid)
+
+	for _, bid := range bids {
+		for _, dest := range destinations {
+			info, err := getter.StatShard(context.Background(), dest, bid)
+			require.NoError(t, err)
+			require.Equal(t, int64(2048), info.Size)
+		}
+	}
+
+	// the blob deleted from the source when converting is cleared in the target
+	for _, src := range sources {
+		getter.Delete(context.Background(), src.Vuid, 2)
+	}
+	cleared, err := mgr.clearDeleted(context.Background(), task, bids)
+	require.NoError(t, err)
+	require.Equal(t, 1, cleared)
+	for _, dest := range destinations {
+		info, err := getter.StatShard(context.Background(), dest, 2)
+		require.NoError(t, err)
+		require.Equal(t, client.ShardStatusNotExist, info.Flag)
+		info, err = getter.StatShard(context.Background(), dest, 1)
+		require.NoError(t, err)
+		require.NotEqual(t, client.ShardStatusNotExist, info.Flag)
+	}
+
+	// invalid task
+	task.Destinations = destinations[:1]
+	ret = mgr.doConvert(context.Background(), task)
+	require.Error(t, ret.Err())
+}
+
+func TestConvertTaskMgrAddTask(t *testing.T) {
+	reported := make(chan *proto.CodeModeConvertRet, 1)
+	reporter := mocks.NewMockIScheduler(C(t))
+	reporter.EXPECT().CompleteConvertTask(A, A).DoAndReturn(
+		func(_ context.Context, ret *proto.CodeModeConvertRet) error {
+			reported <- ret
+			return errMock
+		})
+	mgr := NewConvertTaskMgr("z0", 1, 1, NewMockGetter(genMockVol(1, codemode.EC6P6), codemode.EC6P6), reporter, reporter)
+
+	// replicate codemode is rejected before running
+	err := mgr.AddTask(context.Background(), &proto.CodeModeConvertTask{TaskID: "codemode_convert-1-xxx", SourceCodeMode: codemode.EC6P6, TargetCodeMode: codemode.Replica3})
+	require.Error(t, err)
+	require.Equal(t, 0, mgr.RunningTaskSize())
+
+	// the result of invalid task is reported even if report failed
+	err = mgr.AddTask(context.Background(), &proto.CodeModeConvertTask{TaskID: "codemode_convert-1-xxx", SourceCodeMode: codemode.EC6P6, TargetCodeMode: codemode.EC6P10L2})
+	require.NoError(t, err)
+	ret := <-reported
+	require.Equal(t, "codemode_convert-1-xxx", ret.TaskID)
+	require.Error(t, ret.Err())
+	require.Eventually(t, func() bool { return mgr.RunningTaskSize() == 0 }, time.Second, 10*time.Millisecond)
+}
@@ -151,6 +151,8 @@ type ITaskWorker interface {
 	OperateArgs() scheduler.OperateTaskArgs
 	TaskType() (taskType proto.TaskType)
 	GetBenchmarkBids() []*ShardInfoSimple
+	// total bytes of shards downloaded across AZs
+	CrossAZBytes() uint64
 }
 
 // Tasklet is the smallest unit of task exe
@@ -186,6 +188,9 @@ type TaskRunner struct {
 	schedulerCli scheduler.IMigrator
 	stats        proto.TaskProgress // task progress statics
 	taskCounter  *taskCounter
+
+	crossAZMu            sync.Mutex
+	reportedCrossAZBytes uint64
 }
 
 // NewTaskRunner return task runner
@@ -353,12 +358,19 @@ func (r *TaskRunner) completeTask() {
 func (r *TaskRunner) statsAndReportTask(increaseDataSize, increaseShardCnt uint64) {
 	r.stats.Do(increaseDataSize, increaseShardCnt)
 
+	r.crossAZMu.Lock()
+	crossAZBytes := r.w.CrossAZBytes()
+	increaseCrossAZBytes := crossAZBytes - r.reportedCrossAZBytes
+	r.reportedCrossAZBytes = crossAZBytes
+	r.crossAZMu.Unlock()
+
 	reportArgs := scheduler.TaskReportArgs{
-		TaskID:               r.taskID,
-		TaskType:             r.w.TaskType(),
-		TaskStats:            r.stats.Done(),
-		IncreaseDataSizeByte: int(increaseDataSize),
-		IncreaseShardCnt:     int(increaseShardCnt),
+		TaskID:                      r.taskID,
+		TaskType:                    r.w.TaskType(),
+		TaskStats:                   r.stats.Done(),
+		IncreaseDataSizeByte:        int(increaseDataSize),
+		IncreaseShardCnt:            int(increaseShardCnt),
+		IncreaseCrossAZDataSizeByte: int(increaseCrossAZBytes),
 	}
 	err := r.schedulerCli.ReportTask(r.newCtx(), &reportArgs)
 	if err != nil {
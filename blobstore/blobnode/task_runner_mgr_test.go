@@ -62,6 +62,7 @@ func (w *mockMigrateWorker) ExecTasklet(ctx context.Context, t Tasklet) *WorkErr
 func (w *mockMigrateWorker) Check(ctx context.Context) *WorkError                  { return nil }
 func (w *mockMigrateWorker) TaskType() proto.TaskType                              { return proto.TaskTypeBalance }
 func (w *mockMigrateWorker) GetBenchmarkBids() []*ShardInfoSimple                  { return nil }
+func (w *mockMigrateWorker) CrossAZBytes() uint64                                  { return 0 }
 func (w *mockMigrateWorker) OperateArgs() scheduler.OperateTaskArgs {
 	return scheduler.OperateTaskArgs{TaskID: "test_mock_task", TaskType: w.TaskType()}
 }
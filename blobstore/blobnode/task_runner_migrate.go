@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"sync"
+	"sync/atomic"
 
 	"github.com/cubefs/cubefs/blobstore/api/scheduler"
 	"github.com/cubefs/cubefs/blobstore/blobnode/base/workutils"
@@ -44,6 +45,7 @@ type MigrateWorker struct {
 	benchmarkBids            []*ShardInfoSimple
 	downloadShardConcurrency int
 	forbiddenDirectDownload  bool
+	crossAZBytes             uint64
 }
 
 // MigrateTaskEx migrate task execution machine
@@ -107,7 +109,10 @@ func (w *MigrateWorker) ExecTasklet(ctx context.Context, tasklet Tasklet) *WorkE
 	replicas := w.t.Sources
 	mode := w.t.CodeMode
 	shardRecover := NewShardRecover(replicas, mode, tasklet.bids, w.bolbNodeCli, w.downloadShardConcurrency, w.t.TaskType)
-	defer shardRecover.ReleaseBuf()
+	defer func() {
+		atomic.AddUint64(&w.crossAZBytes, shardRecover.CrossAZBytes())
+		shardRecover.ReleaseBuf()
+	}()
 
 	return MigrateBids(ctx,
 		shardRecover,
@@ -128,6 +133,11 @@ func (w *MigrateWorker) GetBenchmarkBids() []*ShardInfoSimple {
 	return w.benchmarkBids
 }
 
+// CrossAZBytes returns bytes of shards downloaded across AZs
+func (w *MigrateWorker) CrossAZBytes() uint64 {
+	return atomic.LoadUint64(&w.crossAZBytes)
+}
+
 // OperateArgs args for cancel, complete, reclaim.
 func (w *MigrateWorker) OperateArgs() scheduler.OperateTaskArgs {
 	return scheduler.OperateTaskArgs{
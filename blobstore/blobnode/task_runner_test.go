@@ -86,6 +86,10 @@ func (w *mockWorker) GetBenchmarkBids() (bids []*ShardInfoSimple) {
 	return
 }
 
+func (w *mockWorker) CrossAZBytes() uint64 {
+	return 0
+}
+
 type mockStats struct {
 	wg   sync.WaitGroup
 	step string
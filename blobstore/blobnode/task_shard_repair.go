@@ -65,6 +65,11 @@ func (shard *ShardInfoEx) ShardSize() int64 {
 	return shard.info.Size
 }
 
+// ShardExpire returns unix seconds the shard expires, 0 if never
+func (shard *ShardInfoEx) ShardExpire() int64 {
+	return shard.info.Expire
+}
+
 // ShardRepairer used to repair shard data
 type ShardRepairer struct {
 	cli client.IBlobNode
@@ -111,7 +116,8 @@ func (repairer *ShardRepairer) RepairShard(ctx context.Context, task *proto.Shar
 	}
 
 	span.Infof("start recover blob: bid[%d], badIdx[%+v]", task.Bid, task.BadIdxs)
-	bidInfos := []*ShardInfoSimple{{Bid: task.Bid, Size: shardSize}}
+	expire := getShardsExpire(shardInfos)
+	bidInfos := []*ShardInfoSimple{{Bid: task.Bid, Size: shardSize, Expire: expire}}
 	shardRecover := NewShardRecover(task.Sources, task.CodeMode, bidInfos, repairer.cli, 1, proto.TaskTypeShardRepair)
 	defer shardRecover.ReleaseBuf()
 	err = shardRecover.RecoverShards(ctx, task.BadIdxs, false)
@@ -119,6 +125,7 @@ func (repairer *ShardRepairer) RepairShard(ctx context.Context, task *proto.Shar
 		span.Errorf("recover blob failed: err[%+v]", err)
 		return err
 	}
+	span.Infof("recover blob success: bid[%d], cross az bytes[%d]", task.Bid, shardRecover.CrossAZBytes())
 
 	// put shards to dest
 	span.Infof("data has prepared and put data to dest")
@@ -134,7 +141,7 @@ func (repairer *ShardRepairer) RepairShard(ctx context.Context, task *proto.Shar
 		wg.Add(1)
 		go func(i int) {
 			defer wg.Done()
-			err = repairer.cli.PutShard(ctx, dstLocation, task.Bid, shardSize, bytes.NewReader(data), api.BackgroundIO)
+			err = repairer.cli.PutShard(ctx, dstLocation, task.Bid, shardSize, expire, bytes.NewReader(data), api.BackgroundIO)
 			retErrs[i] = err
 		}(badi)
 	}
@@ -156,6 +163,17 @@ func (repairer *ShardRepairer) RepairShard(ctx context.Context, task *proto.Shar
 	return err
 }
 
+// getShardsExpire returns the latest expiry of the normal shards, the repaired
+// shards expire together with the rest of the blob
+func getShardsExpire(shardInfos []*ShardInfoEx) (expire int64) {
+	for _, shard := range shardInfos {
+		if shard.Normal() && shard.ShardExpire() > expire {
+			expire = shard.ShardExpire()
+		}
+	}
+	return
+}
+
 func hasRepaired(shardInfos []*ShardInfoEx, repairIdxs []int) (bool, error) {
 	var repairCnt int
 	for idx, shard := range shardInfos {
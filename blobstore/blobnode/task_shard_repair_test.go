@@ -17,6 +17,7 @@ package blobnode
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -203,11 +204,15 @@ func testShardRepair(t *testing.T, mode codemode.CodeMode) {
 	err := repairer.RepairShard(context.Background(), task)
 	require.NoError(t, err)
 
+	expire := time.Now().Add(time.Hour).Unix()
+	getter.setExpire(replicas, 1, expire)
 	getter.Delete(context.Background(), replicas[0].Vuid, 1)
 	getter.Delete(context.Background(), replicas[1].Vuid, 1)
 	err = repairer.RepairShard(context.Background(), task)
 	require.NoError(t, err)
 	checkRepairShardResult(t, getter, replicas, replicasCrc32)
+	require.Equal(t, expire, getter.getExpire(replicas[0].Vuid, 1))
+	require.Equal(t, expire, getter.getExpire(replicas[1].Vuid, 1))
 
 	var localIdxs []int
 	if codeInfo.L != 0 {
@@ -0,0 +1,151 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package blobnode
+
+import (
+	"bytes"
+	"context"
+
+	bnapi "github.com/cubefs/cubefs/blobstore/api/blobnode"
+	"github.com/cubefs/cubefs/blobstore/api/scheduler"
+	"github.com/cubefs/cubefs/blobstore/blobnode/base"
+	"github.com/cubefs/cubefs/blobstore/blobnode/base/workutils"
+	"github.com/cubefs/cubefs/blobstore/blobnode/client"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/util/errors"
+	"github.com/cubefs/cubefs/blobstore/util/retry"
+)
+
+// ErrVolumeCompactTaskStopped volume compact task stopped by renewal failure
+var ErrVolumeCompactTaskStopped = errors.New("volume compact task stopped")
+
+// VolumeCompactTaskMgr volume compact task manager
+type VolumeCompactTaskMgr struct {
+	*volumeTaskRunner
+	downloadShardConcurrency int
+
+	blobnode client.IBlobNode
+	reporter scheduler.IVolumeCompactor
+}
+
+// NewVolumeCompactTaskMgr returns volume compact task manager
+func NewVolumeCompactTaskMgr(idc string, concurrency, downloadShardConcurrency int, blobnode client.IBlobNode,
+	reporter scheduler.IVolumeCompactor, renewalCli scheduler.IMigrator,
+) *VolumeCompactTaskMgr {
+	return &VolumeCompactTaskMgr{
+		volumeTaskRunner:         newVolumeTaskRunner(idc, proto.TaskTypeVolumeCompact, concurrency, renewalCli),
+		downloadShardConcurrency: downloadShardConcurrency,
+		blobnode:                 blobnode,
+		reporter:                 reporter,
+	}
+}
+
+// AddTask adds volume compact task
+func (mgr *VolumeCompactTaskMgr) AddTask(ctx context.Context, task *proto.VolumeCompactTask) error {
+	span := trace.SpanFromContextSafe(ctx)
+	if err := base.ValidateCodeMode(task.CodeMode); err != nil {
+		return err
+	}
+
+	var ret *proto.VolumeCompactRet
+	return mgr.runTask(ctx, task.TaskID, func(taskCtx context.Context) {
+		ret = mgr.doCompact(taskCtx, task)
+	}, func() {
+		if err := mgr.reporter.CompleteVolumeCompactTask(ctx, ret); err != nil {
+			span.Errorf("report volume compact result failed: result[%+v], err[%+v]", ret, err)
+		}
+		span.Infof("finish volume compact: taskID[%s], compacted[%d], err[%s]",
+			task.TaskID, ret.CompactedCnt, ret.CompactErrStr)
+	})
+}
+
+func (mgr *VolumeCompactTaskMgr) doCompact(ctx context.Context, task *proto.VolumeCompactTask) *proto.VolumeCompactRet {
+	span := trace.SpanFromContextSafe(ctx)
+	ret := &proto.VolumeCompactRet{TaskID: task.TaskID}
+
+	if !task.IsValid() {
+		ret.CompactErrStr = "unexpect:invalid volume compact task"
+		return ret
+	}
+
+	bids, err := GetBenchmarkBids(ctx, mgr.blobnode, task.Sources, task.CodeMode, nil)
+	if err != nil {
+		span.Errorf("get benchmark bids failed: taskID[%s], err[%+v]", task.TaskID, err)
+		ret.CompactErrStr = err.Error()
+		return ret
+	}
+	span.Infof("start volume compact: taskID[%s], vid[%d] -> vid[%d], live bids len[%d]",
+		task.TaskID, task.SourceVid, task.TargetVid, len(bids))
+
+	tasklets, wErr := BidsSplit(ctx, bids, workutils.TaskBufPool.GetMigrateBufSize())
+	if wErr != nil {
+		ret.CompactErrStr = wErr.Error()
+		return ret
+	}
+	for _, tasklet := range tasklets {
+		if ctx.Err() != nil {
+			ret.CompactErrStr = ErrVolumeCompactTaskStopped.Error()
+			return ret
+		}
+		if err = mgr.compactTasklet(ctx, task, tasklet); err != nil {
+			span.Errorf("compact tasklet failed: taskID[%s], err[%+v]", task.TaskID, err)
+			ret.CompactErrStr = err.Error()
+			return ret
+		}
+		ret.CompactedCnt += len(tasklet.bids)
+	}
+	return ret
+}
+
+// compactTasklet copies live shards of the source volume into the same index of
+// target volume, deleted blobs are not listed in the benchmark bids so they are
+// left behind. The shard is recovered by the stripe if it is missing in source volume.
+func (mgr *VolumeCompactTaskMgr) compactTasklet(ctx context.Context, task *proto.VolumeCompactTask, tasklet Tasklet) error {
+	for idx, dest := range task.Destinations {
+		if ctx.Err() != nil {
+			return ErrVolumeCompactTaskStopped
+		}
+		if err := mgr.compactShards(ctx, task, tasklet, uint8(idx), dest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (mgr *VolumeCompactTaskMgr) compactShards(ctx context.Context, task *proto.VolumeCompactTask,
+	tasklet Tasklet, idx uint8, dest proto.VunitLocation,
+) error {
+	shardRecover := NewShardRecover(task.Sources, task.CodeMode, tasklet.bids,
+		mgr.blobnode, mgr.downloadShardConcurrency, proto.TaskTypeVolumeCompact)
+	defer shardRecover.ReleaseBuf()
+	if err := shardRecover.RecoverShards(ctx, []uint8{idx}, true); err != nil {
+		return err
+	}
+
+	for _, bid := range tasklet.bids {
+		shard, err := shardRecover.GetShard(idx, bid.Bid)
+		if err != nil {
+			return err
+		}
+		err = retry.Timed(3, 1000).On(func() error {
+			return mgr.blobnode.PutShard(ctx, dest, bid.Bid, int64(len(shard)), bid.Expire, bytes.NewReader(shard), bnapi.BackgroundIO)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package blobnode
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/blobstore/api/scheduler"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/util/limit"
+	"github.com/cubefs/cubefs/blobstore/util/limit/count"
+)
+
+// volumeTaskRunner runs the volume tasks leased from scheduler in background, it is
+// shared by codemode convert, cluster migrate and volume compact.
+// The leases of running tasks are renewed periodically, the task is stopped once
+// its lease failed to renew, and the result of stopped task is not reported.
+type volumeTaskRunner struct {
+	idc        string
+	taskType   proto.TaskType
+	taskLimit  limit.Limiter
+	renewalCli scheduler.IMigrator
+
+	mu      sync.Mutex
+	running map[string]context.CancelFunc
+}
+
+func newVolumeTaskRunner(idc string, taskType proto.TaskType, concurrency int,
+	renewalCli scheduler.IMigrator,
+) *volumeTaskRunner {
+	return &volumeTaskRunner{
+		idc:        idc,
+		taskType:   taskType,
+		taskLimit:  count.New(concurrency),
+		renewalCli: renewalCli,
+		running:    make(map[string]context.CancelFunc),
+	}
+}
+
+// runTask runs the task in background, do is canceled by the context if the lease of task
+// failed to renew, report is called after do unless the task has been stopped.
+func (r *volumeTaskRunner) runTask(ctx context.Context, taskID string,
+	do func(ctx context.Context), report func(),
+) error {
+	if err := r.taskLimit.Acquire(); err != nil {
+		return err
+	}
+
+	taskCtx, cancel := context.WithCancel(ctx)
+	r.mu.Lock()
+	r.running[taskID] = cancel
+	r.mu.Unlock()
+
+	go func() {
+		defer func() {
+			r.mu.Lock()
+			delete(r.running, taskID)
+			r.mu.Unlock()
+			cancel()
+			r.taskLimit.Release()
+		}()
+
+		do(taskCtx)
+		if taskCtx.Err() != nil {
+			trace.SpanFromContextSafe(ctx).Warnf("%s task has been stopped: taskID[%s]", r.taskType, taskID)
+			return
+		}
+		report()
+	}()
+	return nil
+}
+
+// RunningTaskSize returns running task size
+func (r *volumeTaskRunner) RunningTaskSize() int {
+	return r.taskLimit.Running()
+}
+
+// RenewalTaskLoop renewal running tasks
+func (r *volumeTaskRunner) RenewalTaskLoop(stopCh <-chan struct{}) {
+	go func() {
+		ticker := time.NewTicker(time.Duration(proto.TaskRenewalPeriodS) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				r.renewalTask()
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+}
+
+func (r *volumeTaskRunner) renewalTask() {
+	r.mu.Lock()
+	ids := make([]string, 0, len(r.running))
+	for taskID := range r.running {
+		ids = append(ids, taskID)
+	}
+	r.mu.Unlock()
+	if len(ids) == 0 {
+		return
+	}
+
+	span, ctx := trace.StartSpanFromContext(context.Background(), "renewalVolumeTask")
+	ret, err := r.renewalCli.RenewalTask(ctx, &scheduler.TaskRenewalArgs{
+		IDC: r.idc,
+		IDs: map[proto.TaskType][]string{r.taskType: ids},
+	})
+	if err != nil {
+		span.Errorf("renewal %s task failed and stop all: err[%+v]", r.taskType, err)
+		r.stopTasks(ids)
+		return
+	}
+
+	var failed []string
+	for taskID, errMsg := range ret.Errors[r.taskType] {
+		span.Warnf("renewal fail so stop %s: taskID[%s], error[%s]", r.taskType, taskID, errMsg)
+		failed = append(failed, taskID)
+	}
+	r.stopTasks(failed)
+}
+
+func (r *volumeTaskRunner) stopTasks(ids []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, taskID := range ids {
+		if cancel, ok := r.running[taskID]; ok {
+			cancel()
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package blobnode
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/api/scheduler"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/testing/mocks"
+)
+
+func TestVolumeTaskRunnerRun(t *testing.T) {
+	r := newVolumeTaskRunner("z0", proto.TaskTypeVolumeCompact, 1, mocks.NewMockIScheduler(C(t)))
+
+	// completed task is reported and releases the limit
+	reported := make(chan struct{})
+	require.NoError(t, r.runTask(context.Background(), "volume_compact-1-a", func(context.Context) {}, func() {
+		close(reported)
+	}))
+	<-reported
+	require.Eventually(t, func() bool { return r.RunningTaskSize() == 0 }, time.Second, 10*time.Millisecond)
+
+	// limited by concurrency
+	block := make(chan struct{})
+	done := make(chan struct{})
+	require.NoError(t, r.runTask(context.Background(), "volume_compact-1-b", func(ctx context.Context) {
+		<-block
+	}, func() { close(done) }))
+	require.Error(t, r.runTask(context.Background(), "volume_compact-1-c", func(context.Context) {}, func() {}))
+	require.Equal(t, 1, r.RunningTaskSize())
+	close(block)
+	<-done
+
+	// stopped task is not reported
+	stopped := make(chan struct{})
+	require.Eventually(t, func() bool { return r.RunningTaskSize() == 0 }, time.Second, 10*time.Millisecond)
+	require.NoError(t, r.runTask(context.Background(), "volume_compact-1-d", func(ctx context.Context) {
+		<-ctx.Done()
+		close(stopped)
+	}, func() { t.Error("stopped task reported") }))
+	r.stopTasks([]string{"volume_compact-1-d"})
+	<-stopped
+	require.Eventually(t, func() bool { return r.RunningTaskSize() == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestVolumeTaskRunnerRenewal(t *testing.T) {
+	cli := mocks.NewMockIScheduler(C(t))
+	r := newVolumeTaskRunner("z0", proto.TaskTypeClusterMigrate, 3, cli)
+	// nothing to renewal
+	r.renewalTask()
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	r.running["cluster_migrate-1-a"] = cancel1
+	r.running["cluster_migrate-2-a"] = cancel2
+
+	// only the tasks failed to renewal are stopped
+	cli.EXPECT().RenewalTask(A, A).DoAndReturn(
+		func(_ context.Context, args *scheduler.TaskRenewalArgs) (*scheduler.TaskRenewalRet, error) {
+			require.Equal(t, "z0", args.IDC)
+			require.ElementsMatch(t, []string{"cluster_migrate-1-a", "cluster_migrate-2-a"},
+				args.IDs[proto.TaskTypeClusterMigrate])
+			return &scheduler.TaskRenewalRet{Errors: map[proto.TaskType]map[string]string{
+				proto.TaskTypeClusterMigrate: {"cluster_migrate-1-a": "lease expired"},
+			}}, nil
+		})
+	r.renewalTask()
+	require.Error(t, ctx1.Err())
+	require.NoError(t, ctx2.Err())
+
+	// all tasks are stopped if failed to renewal
+	cli.EXPECT().RenewalTask(A, A).Return(nil, errMock)
+	r.renewalTask()
+	require.Error(t, ctx2.Err())
+}
@@ -43,8 +43,9 @@ var (
 
 // ShardInfoSimple with blob id and size
 type ShardInfoSimple struct {
-	Bid  proto.BlobID
-	Size int64
+	Bid    proto.BlobID
+	Size   int64
+	Expire int64 // unix seconds the blob expires, 0 if never
 }
 
 // ShardInfoWithCrc with blob id and size and crc
@@ -114,6 +115,10 @@ func MergeBids(replicasBids map[proto.Vuid]*ReplicaBidsRet) []*ShardInfoSimple {
 	for _, info := range replicasBids {
 		if info.RetErr == nil {
 			for _, bidInfo := range info.Bids {
+				// keep the latest expiry, shards rewritten before it was carried may miss it
+				if exist, ok := allBidsMap[bidInfo.Bid]; ok && exist.Expire > bidInfo.Expire {
+					continue
+				}
 				allBidsMap[bidInfo.Bid] = bidInfo
 			}
 		}
@@ -121,7 +126,7 @@ func MergeBids(replicasBids map[proto.Vuid]*ReplicaBidsRet) []*ShardInfoSimple {
 
 	var allBidsList []*ShardInfoSimple
 	for _, bid := range allBidsMap {
-		bidInfo := ShardInfoSimple{Bid: bid.Bid, Size: bid.Size}
+		bidInfo := ShardInfoSimple{Bid: bid.Bid, Size: bid.Size, Expire: bid.Expire}
 		allBidsList = append(allBidsList, &bidInfo)
 	}
 	return allBidsList
@@ -183,7 +188,7 @@ func GetBenchmarkBids(ctx context.Context, cli client.IBlobNode, replicas Vunits
 		}
 
 		if existStatus.CanRecover() {
-			bidInfo := ShardInfoSimple{Bid: bid.Bid, Size: bid.Size}
+			bidInfo := ShardInfoSimple{Bid: bid.Bid, Size: bid.Size, Expire: bid.Expire}
 			benchMark = append(benchMark, &bidInfo)
 			continue
 		}
@@ -20,7 +20,9 @@ import (
 	"hash/crc32"
 	"io"
 	"math/rand"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"unsafe"
 
 	"github.com/cubefs/cubefs/blobstore/api/blobnode"
@@ -127,6 +129,26 @@ func (stripe *repairStripe) genDownloadPlans() []downloadPlan {
 		}
 		wellReplications = append(wellReplications, replica)
 	}
+	// prefer replicas in the same AZ with bad shards, and replicas out of
+	// n+m of stripe (local parity in global stripe) are useless to reconstruct,
+	// so that the fewest shards are downloaded across AZs
+	stripePos := make(map[uint8]int, len(stripe.replicas))
+	for pos, replica := range stripe.replicas {
+		stripePos[replica.Vuid.Index()] = pos
+	}
+	rank := func(replica proto.VunitLocation) int {
+		idx := replica.Vuid.Index()
+		if stripePos[idx] >= stripe.n+stripe.m {
+			return 2
+		}
+		if !stripe.isLocal(idx) {
+			return 1
+		}
+		return 0
+	}
+	sort.SliceStable(wellReplications, func(i, j int) bool {
+		return rank(wellReplications[i]) < rank(wellReplications[j])
+	})
 
 	planCnt := len(wellReplications) - int(n) + 1
 	for i := 0; i < planCnt; i++ {
@@ -146,6 +168,21 @@ type repairStripe struct {
 	n        int
 	m        int
 	badIdxes []uint8
+	// localIdxes indexes in the same AZ with bad shards
+	localIdxes map[uint8]struct{}
+}
+
+func (stripe *repairStripe) isLocal(idx uint8) bool {
+	return isLocalIdx(stripe.localIdxes, idx)
+}
+
+// isLocalIdx returns true if localIdxes is not planned
+func isLocalIdx(localIdxes map[uint8]struct{}, idx uint8) bool {
+	if localIdxes == nil {
+		return true
+	}
+	_, ok := localIdxes[idx]
+	return ok
 }
 
 // duties：repair shard data
@@ -264,6 +301,15 @@ func (shards *ShardsBuf) setShardBuf(ctx context.Context, bid proto.BlobID, buf
 	return errIllegalBuf
 }
 
+func (shards *ShardsBuf) shardSize(bid proto.BlobID) int64 {
+	shards.mu.Lock()
+	defer shards.mu.Unlock()
+	if shard, ok := shards.shards[bid]; ok {
+		return shard.size
+	}
+	return 0
+}
+
 // ShardSizeIsZero return true if shard size is zero
 func (shards *ShardsBuf) ShardSizeIsZero(bid proto.BlobID) bool {
 	shards.mu.Lock()
@@ -394,6 +440,10 @@ type ShardRecover struct {
 	ioType                   blobnode.IOType
 	taskType                 proto.TaskType
 	ds                       *downloadStatus
+
+	// localIdxes indexes in the same AZ with repaired shards
+	localIdxes   map[uint8]struct{}
+	crossAZBytes uint64
 }
 
 // NewShardRecover returns shard recover
@@ -425,6 +475,7 @@ func (r *ShardRecover) RecoverShards(ctx context.Context, repairIdxs []uint8, di
 	if !r.replicas.IsValid() {
 		return errInvalidReplicas
 	}
+	r.localIdxes = workutils.SameAZIdxes(repairIdxs, r.codeMode)
 
 	// direct download shard
 	repairBids := GetBids(r.repairBidsReadOnly)
@@ -550,10 +601,11 @@ func (r *ShardRecover) recoverByGlobalStripe(ctx context.Context, repairBids []p
 	span.Infof("start recoverByGlobalStripe: repairIdxs[%+v]", repairIdxs)
 
 	stripe := repairStripe{
-		replicas: r.replicas,
-		n:        r.codeMode.T().N,
-		m:        r.codeMode.T().M,
-		badIdxes: repairIdxs,
+		replicas:   r.replicas,
+		n:          r.codeMode.T().N,
+		m:          r.codeMode.T().M,
+		badIdxes:   repairIdxs,
+		localIdxes: r.localIdxes,
 	}
 	idxs := stripe.replicas.Indexes()
 	err = r.allocBuf(ctx, idxs)
@@ -652,6 +704,16 @@ func (r *ShardRecover) downloadShard(ctx context.Context, replica proto.VunitLoc
 		span.Infof("download cancel: replica[%+v],  bid[%d]", replica, bid)
 		return nil
 	default:
+		idx := replica.Vuid.Index()
+		crossAZ := !isLocalIdx(r.localIdxes, idx)
+		size := r.chunksShardsBuf[idx].shardSize(bid)
+		if crossAZ {
+			if err := workutils.WaitCrossAZRepair(ctx, int(size)); err != nil {
+				span.Warnf("wait cross az repair bandwidth: replica[%+v], bid[%d], err[%+v]", replica, bid, err)
+				return err
+			}
+		}
+
 		data, crc1, err := r.shardGetter.GetShard(ctx, replica, bid, r.ioType)
 		r.ds.downloaded(replica.Vuid)
 		if err != nil {
@@ -684,6 +746,9 @@ func (r *ShardRecover) downloadShard(ctx context.Context, replica proto.VunitLoc
 			span.Errorf("shard crc32 not match: replica[%+v], bid[%d], crc1[%d], crc2[%d]", replica, bid, crc1, crc2)
 			return errCrcNotMatch
 		}
+		if crossAZ {
+			atomic.AddUint64(&r.crossAZBytes, uint64(size))
+		}
 		return nil
 	}
 }
@@ -818,6 +883,11 @@ func (r *ShardRecover) GetShard(idx uint8, bid proto.BlobID) ([]byte, error) {
 	return r.chunksShardsBuf[idx].FetchShard(bid)
 }
 
+// CrossAZBytes returns bytes of shards downloaded across AZs
+func (r *ShardRecover) CrossAZBytes() uint64 {
+	return atomic.LoadUint64(&r.crossAZBytes)
+}
+
 // ReleaseBuf release chunks shards buffer
 func (r *ShardRecover) ReleaseBuf() {
 	for idx := range r.chunksShardsBuf {
@@ -840,7 +910,8 @@ func (r *ShardRecover) allocBuf(ctx context.Context, vunitIdxs []uint8) error {
 			switch r.taskType {
 			case proto.TaskTypeShardRepair:
 				buf, err = workutils.TaskBufPool.GetRepairBuf()
-			case proto.TaskTypeDiskRepair, proto.TaskTypeBalance, proto.TaskTypeManualMigrate, proto.TaskTypeDiskDrop:
+			case proto.TaskTypeDiskRepair, proto.TaskTypeBalance, proto.TaskTypeManualMigrate, proto.TaskTypeDiskDrop,
+				proto.TaskTypeCodeModeConvert, proto.TaskTypeClusterMigrate, proto.TaskTypeVolumeCompact:
 				buf, err = workutils.TaskBufPool.GetMigrateBuf()
 			default:
 				err = errors.New("unknown type")
@@ -337,6 +337,54 @@ func TestLocalStripes(t *testing.T) {
 	}
 }
 
+func TestGenDownloadPlansPreferLocal(t *testing.T) {
+	mode := codemode.EC6P10L2
+	replicas := genMockVol(1, mode)
+	badi := []uint8{0}
+	stripe := repairStripe{
+		replicas:   replicas,
+		n:          mode.T().N,
+		m:          mode.T().M,
+		badIdxes:   badi,
+		localIdxes: workutils.SameAZIdxes(badi, mode),
+	}
+	plans := stripe.genDownloadPlans()
+	require.Equal(t, len(replicas)-len(badi)-mode.T().N+1, len(plans))
+	// well global shards in the same az are enough to repair by the first plan
+	for _, replica := range plans[0].downloadReplicas {
+		require.True(t, stripe.isLocal(replica.Vuid.Index()))
+		require.Less(t, int(replica.Vuid.Index()), mode.T().N+mode.T().M)
+	}
+	// local parity shards are the last to download
+	last := plans[len(plans)-1].downloadReplicas
+	require.GreaterOrEqual(t, int(last[len(last)-1].Vuid.Index()), mode.T().N+mode.T().M)
+}
+
+func TestRecoverShardsCrossAZBytes(t *testing.T) {
+	ctx := context.Background()
+	workutils.SetCrossAZRepairRate(1 << 30)
+	defer workutils.SetCrossAZRepairRate(0)
+
+	repair, bidInfos, getter, _ := InitMockRepair(codemode.EC6P10L2)
+	badi := []uint8{0, 1}
+	require.NoError(t, repair.RecoverShards(ctx, badi, false))
+	testCheckData(t, repair, getter, badi)
+	require.Equal(t, uint64(0), repair.CrossAZBytes())
+	repair.ReleaseBuf()
+
+	// 4 well global shards in the same az, the other 2 are downloaded across az
+	repair, bidInfos, getter, _ = InitMockRepair(codemode.EC6P10L2)
+	badi = []uint8{0, 1, 2, 6}
+	require.NoError(t, repair.RecoverShards(ctx, badi, false))
+	testCheckData(t, repair, getter, badi)
+	var size uint64
+	for _, bid := range bidInfos {
+		size += uint64(bid.Size)
+	}
+	require.Equal(t, 2*size, repair.CrossAZBytes())
+	repair.ReleaseBuf()
+}
+
 func testCheckData(t *testing.T, repairer *ShardRecover, getter *MockGetter, badi []uint8) {
 	for _, bidInfo := range repairer.repairBidsReadOnly {
 		for _, repl := range repairer.replicas {
@@ -182,7 +182,7 @@ func (getter *MockGetter) setVunitStatus(vuid proto.Vuid, status api.ChunkStatus
 	}
 }
 
-func (getter *MockGetter) PutShard(ctx context.Context, location proto.VunitLocation, bid proto.BlobID, size int64, body io.Reader, ioType api.IOType) (err error) {
+func (getter *MockGetter) PutShard(ctx context.Context, location proto.VunitLocation, bid proto.BlobID, size, expire int64, body io.Reader, ioType api.IOType) (err error) {
 	getter.mu.Lock()
 	defer getter.mu.Unlock()
 	if err, ok := getter.failVuid[location.Vuid]; ok {
@@ -191,6 +191,31 @@ func (getter *MockGetter) PutShard(ctx context.Context, location proto.VunitLoca
 	data := make([]byte, size)
 	body.Read(data)
 	getter.vunits[location.Vuid].putShard(bid, data)
+	getter.vunits[location.Vuid].setExpire(bid, expire)
+	return
+}
+
+func (getter *MockGetter) setExpire(replicas []proto.VunitLocation, bid proto.BlobID, expire int64) {
+	getter.mu.Lock()
+	defer getter.mu.Unlock()
+	for _, replica := range replicas {
+		getter.vunits[replica.Vuid].setExpire(bid, expire)
+	}
+}
+
+func (getter *MockGetter) getExpire(vuid proto.Vuid, bid proto.BlobID) int64 {
+	getter.mu.Lock()
+	defer getter.mu.Unlock()
+	return getter.vunits[vuid].getExpire(bid)
+}
+
+func (getter *MockGetter) DeleteShard(ctx context.Context, location proto.VunitLocation, bid proto.BlobID) (err error) {
+	getter.mu.Lock()
+	defer getter.mu.Unlock()
+	if err, ok := getter.failVuid[location.Vuid]; ok {
+		return err
+	}
+	getter.vunits[location.Vuid].delete(bid)
 	return
 }
 
@@ -381,6 +406,23 @@ func (m *mockVunit) markDelete(bid proto.BlobID) {
 	m.bidInfos[bid].Flag = api.ShardStatusMarkDelete
 }
 
+func (m *mockVunit) setExpire(bid proto.BlobID, expire int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if info, ok := m.bidInfos[bid]; ok {
+		info.Expire = expire
+	}
+}
+
+func (m *mockVunit) getExpire(bid proto.BlobID) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if info, ok := m.bidInfos[bid]; ok {
+		return info.Expire
+	}
+	return 0
+}
+
 func (m *mockVunit) recover(bid proto.BlobID) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -50,9 +50,17 @@ type WorkerConfigMeter struct {
 	ShardRepairConcurrency int `json:"shard_repair_concurrency"`
 	// volume inspect concurrency
 	InspectConcurrency int `json:"inspect_concurrency"`
+	// volume codemode convert concurrency
+	ConvertConcurrency int `json:"convert_concurrency"`
+	// cluster migrate concurrency
+	ClusterMigrateConcurrency int `json:"cluster_migrate_concurrency"`
+	// volume compact concurrency
+	VolumeCompactConcurrency int `json:"volume_compact_concurrency"`
 
 	// batch download concurrency of single tasklet
 	DownloadShardConcurrency int `json:"download_shard_concurrency"`
+	// bandwidth of shards downloaded across AZs in repair and migrate, zero means no limit
+	CrossAZRepairRateMBPS int `json:"cross_az_repair_rate_mbps"`
 }
 
 func (meter *WorkerConfigMeter) concurrencyByType(taskType proto.TaskType) int {
@@ -95,6 +103,10 @@ type WorkerService struct {
 
 	taskRunnerMgr  *TaskRunnerMgr
 	inspectTaskMgr *InspectTaskMgr
+	convertTaskMgr *ConvertTaskMgr
+
+	clusterMigrateTaskMgr *ClusterMigrateTaskMgr
+	volumeCompactTaskMgr  *VolumeCompactTaskMgr
 
 	shardRepairLimit limit.Limiter
 	shardRepairer    *ShardRepairer
@@ -112,6 +124,9 @@ func (cfg *WorkerConfig) checkAndFix() {
 	fixConfigItemInt(&cfg.ManualMigrateConcurrency, 10)
 	fixConfigItemInt(&cfg.ShardRepairConcurrency, 1)
 	fixConfigItemInt(&cfg.InspectConcurrency, 1)
+	fixConfigItemInt(&cfg.ConvertConcurrency, 1)
+	fixConfigItemInt(&cfg.ClusterMigrateConcurrency, 1)
+	fixConfigItemInt(&cfg.VolumeCompactConcurrency, 1)
 	fixConfigItemInt(&cfg.DownloadShardConcurrency, 10)
 	fixConfigItemInt64(&cfg.Scheduler.ClientTimeoutMs, 1000)
 	fixConfigItemInt64(&cfg.Scheduler.HostSyncIntervalMs, 1000)
@@ -135,6 +150,7 @@ func NewWorkerService(cfg *WorkerConfig, service cmapi.APIService, clusterID pro
 	cfg.checkAndFix()
 
 	base.TaskBufPool = base.NewBufPool(&cfg.BufPoolConf)
+	base.SetCrossAZRepairRate(cfg.CrossAZRepairRateMBPS * 1024 * 1024)
 
 	schedulerCli := scheduler.New(&cfg.Scheduler, service, clusterID)
 	blobNodeCli := client.NewBlobNodeClient(&cfg.BlobNode)
@@ -144,6 +160,12 @@ func NewWorkerService(cfg *WorkerConfig, service cmapi.APIService, clusterID pro
 	renewalCli := scheduler.New(&renewalConfig, service, clusterID)
 	taskRunnerMgr := NewTaskRunnerMgr(idc, cfg.WorkerConfigMeter, NewMigrateWorker, renewalCli, schedulerCli)
 	inspectTaskMgr := NewInspectTaskMgr(cfg.InspectConcurrency, blobNodeCli, schedulerCli)
+	convertTaskMgr := NewConvertTaskMgr(idc, cfg.ConvertConcurrency, cfg.DownloadShardConcurrency,
+		blobNodeCli, schedulerCli, renewalCli)
+	clusterMigrateTaskMgr := NewClusterMigrateTaskMgr(idc, cfg.ClusterMigrateConcurrency, cfg.DownloadShardConcurrency,
+		blobNodeCli, schedulerCli, renewalCli)
+	volumeCompactTaskMgr := NewVolumeCompactTaskMgr(idc, cfg.VolumeCompactConcurrency, cfg.DownloadShardConcurrency,
+		blobNodeCli, schedulerCli, renewalCli)
 
 	shardRepairLimit := count.New(cfg.ShardRepairConcurrency)
 	shardRepairer := NewShardRepairer(blobNodeCli)
@@ -163,6 +185,10 @@ func NewWorkerService(cfg *WorkerConfig, service cmapi.APIService, clusterID pro
 		blobNodeCli:    blobNodeCli,
 		taskRunnerMgr:  taskRunnerMgr,
 		inspectTaskMgr: inspectTaskMgr,
+		convertTaskMgr: convertTaskMgr,
+
+		clusterMigrateTaskMgr: clusterMigrateTaskMgr,
+		volumeCompactTaskMgr:  volumeCompactTaskMgr,
 
 		shardRepairLimit: shardRepairLimit,
 		shardRepairer:    shardRepairer,
@@ -204,6 +230,9 @@ func (s *WorkerService) WorkerStats(c *rpc.Context) {
 func (s *WorkerService) Run() {
 	// task lease
 	s.taskRunnerMgr.RenewalTaskLoop(s.Done())
+	s.convertTaskMgr.RenewalTaskLoop(s.Done())
+	s.clusterMigrateTaskMgr.RenewalTaskLoop(s.Done())
+	s.volumeCompactTaskMgr.RenewalTaskLoop(s.Done())
 	s.loopAcquireTask()
 }
 
@@ -228,6 +257,18 @@ func (s *WorkerService) tryAcquireTask() {
 	if s.hasInspectTaskResource() {
 		s.acquireInspectTask()
 	}
+
+	if s.hasConvertTaskResource() {
+		s.acquireConvertTask()
+	}
+
+	if s.hasClusterMigrateTaskResource() {
+		s.acquireClusterMigrateTask()
+	}
+
+	if s.hasVolumeCompactTaskResource() {
+		s.acquireVolumeCompactTask()
+	}
 }
 
 func (s *WorkerService) hasTaskRunnerResource() bool {
@@ -246,6 +287,24 @@ func (s *WorkerService) hasInspectTaskResource() bool {
 	return inspectCnt < s.InspectConcurrency
 }
 
+func (s *WorkerService) hasConvertTaskResource() bool {
+	convertCnt := s.convertTaskMgr.RunningTaskSize()
+	log.Infof("convert running task %d / %d", convertCnt, s.ConvertConcurrency)
+	return convertCnt < s.ConvertConcurrency
+}
+
+func (s *WorkerService) hasClusterMigrateTaskResource() bool {
+	migrateCnt := s.clusterMigrateTaskMgr.RunningTaskSize()
+	log.Infof("cluster migrate running task %d / %d", migrateCnt, s.ClusterMigrateConcurrency)
+	return migrateCnt < s.ClusterMigrateConcurrency
+}
+
+func (s *WorkerService) hasVolumeCompactTaskResource() bool {
+	compactCnt := s.volumeCompactTaskMgr.RunningTaskSize()
+	log.Infof("volume compact running task %d / %d", compactCnt, s.VolumeCompactConcurrency)
+	return compactCnt < s.VolumeCompactConcurrency
+}
+
 // acquire:disk repair & balance & disk drop task
 func (s *WorkerService) acquireTask() {
 	span, ctx := trace.StartSpanFromContext(context.Background(), "acquireTask")
@@ -300,3 +359,84 @@ func (s *WorkerService) acquireInspectTask() {
 
 	span.Infof("acquire inspect task success: taskID[%s] task[%+v]", t.TaskID, t)
 }
+
+// acquire codemode convert task
+func (s *WorkerService) acquireConvertTask() {
+	span, ctx := trace.StartSpanFromContext(context.Background(), "acquireConvertTask")
+
+	t, err := s.schedulerCli.AcquireConvertTask(ctx)
+	if err != nil {
+		code := rpc.DetectStatusCode(err)
+		if code != errcode.CodeNotingTodo {
+			span.Errorf("acquire convert task failed: code[%d], err[%v]", code, err)
+		}
+		return
+	}
+
+	if !t.IsValid() {
+		span.Errorf("convert task is illegal: task[%+v]", t)
+		return
+	}
+
+	err = s.convertTaskMgr.AddTask(ctx, t)
+	if err != nil {
+		span.Errorf("add convert task failed: taskID[%s], err[%v]", t.TaskID, err)
+		return
+	}
+
+	span.Infof("acquire convert task success: taskID[%s] task[%+v]", t.TaskID, t)
+}
+
+// acquire cluster migrate task
+func (s *WorkerService) acquireClusterMigrateTask() {
+	span, ctx := trace.StartSpanFromContext(context.Background(), "acquireClusterMigrateTask")
+
+	t, err := s.schedulerCli.AcquireClusterMigrateTask(ctx)
+	if err != nil {
+		code := rpc.DetectStatusCode(err)
+		if code != errcode.CodeNotingTodo {
+			span.Errorf("acquire cluster migrate task failed: code[%d], err[%v]", code, err)
+		}
+		return
+	}
+
+	if !t.IsValid() {
+		span.Errorf("cluster migrate task is illegal: task[%+v]", t)
+		return
+	}
+
+	err = s.clusterMigrateTaskMgr.AddTask(ctx, t)
+	if err != nil {
+		span.Errorf("add cluster migrate task failed: taskID[%s], err[%v]", t.TaskID, err)
+		return
+	}
+
+	span.Infof("acquire cluster migrate task success: taskID[%s] task[%+v]", t.TaskID, t)
+}
+
+// acquire volume compact task
+func (s *WorkerService) acquireVolumeCompactTask() {
+	span, ctx := trace.StartSpanFromContext(context.Background(), "acquireVolumeCompactTask")
+
+	t, err := s.schedulerCli.AcquireVolumeCompactTask(ctx)
+	if err != nil {
+		code := rpc.DetectStatusCode(err)
+		if code != errcode.CodeNotingTodo {
+			span.Errorf("acquire volume compact task failed: code[%d], err[%v]", code, err)
+		}
+		return
+	}
+
+	if !t.IsValid() {
+		span.Errorf("volume compact task is illegal: task[%+v]", t)
+		return
+	}
+
+	err = s.volumeCompactTaskMgr.AddTask(ctx, t)
+	if err != nil {
+		span.Errorf("add volume compact task failed: taskID[%s], err[%v]", t.TaskID, err)
+		return
+	}
+
+	span.Infof("acquire volume compact task success: taskID[%s] task[%+v]", t.TaskID, t)
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/cubefs/cubefs/blobstore/api/scheduler"
 	"github.com/cubefs/cubefs/blobstore/blobnode/client"
 	"github.com/cubefs/cubefs/blobstore/common/codemode"
+	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
 	"github.com/cubefs/cubefs/blobstore/common/rpc"
 	"github.com/cubefs/cubefs/blobstore/testing/mocks"
@@ -60,7 +61,11 @@ func (m *mBlobNodeCli) GetShard(ctx context.Context, location proto.VunitLocatio
 	return nil, 0, nil
 }
 
-func (m *mBlobNodeCli) PutShard(ctx context.Context, location proto.VunitLocation, bid proto.BlobID, size int64, body io.Reader, ioType bnapi.IOType) (err error) {
+func (m *mBlobNodeCli) PutShard(ctx context.Context, location proto.VunitLocation, bid proto.BlobID, size, expire int64, body io.Reader, ioType bnapi.IOType) (err error) {
+	return
+}
+
+func (m *mBlobNodeCli) DeleteShard(ctx context.Context, location proto.VunitLocation, bid proto.BlobID) (err error) {
 	return
 }
 
@@ -127,6 +132,9 @@ func newMockWorkService(t *testing.T) (*Service, *mockScheCli) {
 	cli := mocks.NewMockIScheduler(C(t))
 	schedulerCli := &mockScheCli{MockIScheduler: cli}
 	schedulerCli.EXPECT().CompleteInspectTask(A, A).AnyTimes().Return(nil)
+	schedulerCli.EXPECT().AcquireConvertTask(A).AnyTimes().Return(nil, errcode.ErrNothingTodo)
+	schedulerCli.EXPECT().AcquireClusterMigrateTask(A).AnyTimes().Return(nil, errcode.ErrNothingTodo)
+	schedulerCli.EXPECT().AcquireVolumeCompactTask(A).AnyTimes().Return(nil, errcode.ErrNothingTodo)
 	blobnodeCli := &mBlobNodeCli{}
 
 	workSvr := &WorkerService{
@@ -135,6 +143,10 @@ func newMockWorkService(t *testing.T) (*Service, *mockScheCli) {
 			WorkerConfigMeter: WorkerConfigMeter{
 				MaxTaskRunnerCnt:   100,
 				InspectConcurrency: 1,
+				ConvertConcurrency: 1,
+
+				ClusterMigrateConcurrency: 1,
+				VolumeCompactConcurrency:  1,
 			},
 			AcquireIntervalMs: 1,
 		},
@@ -145,6 +157,10 @@ func newMockWorkService(t *testing.T) (*Service, *mockScheCli) {
 
 		taskRunnerMgr:  NewTaskRunnerMgr("z0", getDefaultConfig().WorkerConfigMeter, NewMockMigrateWorker, schedulerCli, schedulerCli),
 		inspectTaskMgr: NewInspectTaskMgr(1, blobnodeCli, schedulerCli),
+		convertTaskMgr: NewConvertTaskMgr("z0", 1, 1, blobnodeCli, schedulerCli, schedulerCli),
+
+		clusterMigrateTaskMgr: NewClusterMigrateTaskMgr("z0", 1, 1, blobnodeCli, schedulerCli, schedulerCli),
+		volumeCompactTaskMgr:  NewVolumeCompactTaskMgr("z0", 1, 1, blobnodeCli, schedulerCli, schedulerCli),
 	}
 	return &Service{WorkerService: workSvr}, schedulerCli
 }
@@ -0,0 +1,93 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package scheduler
+
+import (
+	"github.com/desertbit/grumble"
+
+	"github.com/cubefs/cubefs/blobstore/cli/common"
+	"github.com/cubefs/cubefs/blobstore/cli/common/fmt"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+)
+
+func addCmdClusterMigrate(cmd *grumble.Command) {
+	migrateCommand := &grumble.Command{
+		Name:     "cluster_migrate",
+		Help:     "cluster migrate tools",
+		LongHelp: "migrate volumes into target cluster to retire this cluster",
+	}
+	cmd.AddCommand(migrateCommand)
+
+	migrateCommand.AddCommand(&grumble.Command{
+		Name:  "list",
+		Help:  "list cluster migrate tasks",
+		Run:   cmdListClusterMigrateTasks,
+		Flags: clusterFlags,
+	})
+	migrateCommand.AddCommand(&grumble.Command{
+		Name:  "volume",
+		Help:  "get migrated volume",
+		Run:   cmdGetMigratedVolume,
+		Flags: clusterFlags,
+		Args: func(a *grumble.Args) {
+			a.Uint64("volume_id", "source volume id")
+		},
+	})
+	migrateCommand.AddCommand(&grumble.Command{
+		Name:  "stat",
+		Help:  "show cluster migrate stat",
+		Run:   cmdClusterMigrateStat,
+		Flags: clusterFlags,
+	})
+}
+
+func cmdListClusterMigrateTasks(c *grumble.Context) error {
+	ret, err := newSchedulerClient(c).ListClusterMigrateTasks(common.CmdContext())
+	if err != nil {
+		return err
+	}
+	for _, task := range ret.Tasks {
+		fmt.Printf("%s vid:%d -> cluster:%d vid:%d codemode:%s state:%d migrated:%d redo:%d\n",
+			task.TaskID, task.SourceVid, task.TargetClusterID, task.TargetVid,
+			task.CodeMode.Name(), task.State, task.MigratedCnt, task.WorkerRedoCnt)
+	}
+	return nil
+}
+
+func cmdGetMigratedVolume(c *grumble.Context) error {
+	vid := proto.Vid(c.Args.Uint64("volume_id"))
+	migrated, err := newSchedulerClient(c).GetMigratedVolume(common.CmdContext(), vid)
+	if err != nil {
+		return err
+	}
+	fmt.Println(common.Readable(migrated))
+	return nil
+}
+
+func cmdClusterMigrateStat(c *grumble.Context) error {
+	stat, err := newSchedulerClient(c).LeaderStats(common.CmdContext())
+	if err != nil {
+		return err
+	}
+	if stat.ClusterMigrate == nil || stat.ClusterMigrate.TargetClusterID == 0 {
+		fmt.Println("cluster migrate is not configured")
+		return nil
+	}
+	fmt.Println(common.Readable(stat.ClusterMigrate))
+	if stat.ClusterMigrate.Drained {
+		fmt.Println("all volumes have been migrated, the cluster can be retired")
+	}
+	return nil
+}
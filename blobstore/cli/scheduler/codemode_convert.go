@@ -0,0 +1,128 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package scheduler
+
+import (
+	"github.com/desertbit/grumble"
+
+	"github.com/cubefs/cubefs/blobstore/api/scheduler"
+	"github.com/cubefs/cubefs/blobstore/cli/common"
+	"github.com/cubefs/cubefs/blobstore/cli/common/fmt"
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+)
+
+func addCmdCodeModeConvert(cmd *grumble.Command) {
+	convertCommand := &grumble.Command{
+		Name:     "convert",
+		Help:     "codemode convert tools",
+		LongHelp: "convert volumes into another codemode",
+	}
+	cmd.AddCommand(convertCommand)
+
+	convertCommand.AddCommand(&grumble.Command{
+		Name:  "add",
+		Help:  "add codemode convert task",
+		Run:   cmdAddConvertTask,
+		Flags: clusterFlags,
+		Args: func(a *grumble.Args) {
+			a.Uint64("volume_id", "volume id to convert")
+			a.String("codemode", "target codemode name, such as EC6P10L2")
+		},
+	})
+	convertCommand.AddCommand(&grumble.Command{
+		Name:  "list",
+		Help:  "list codemode convert tasks",
+		Run:   cmdListConvertTasks,
+		Flags: clusterFlags,
+	})
+	convertCommand.AddCommand(&grumble.Command{
+		Name:  "volume",
+		Help:  "get converted volume",
+		Run:   cmdGetConvertedVolume,
+		Flags: clusterFlags,
+		Args: func(a *grumble.Args) {
+			a.Uint64("volume_id", "source volume id")
+		},
+	})
+	convertCommand.AddCommand(&grumble.Command{
+		Name:  "stat",
+		Help:  "show codemode convert stat",
+		Run:   cmdConvertStat,
+		Flags: clusterFlags,
+	})
+}
+
+func newSchedulerClient(c *grumble.Context) scheduler.IScheduler {
+	clusterID := getClusterID(c.Flags)
+	return scheduler.New(&scheduler.Config{}, newClusterMgrClient(clusterID), clusterID)
+}
+
+func cmdAddConvertTask(c *grumble.Context) error {
+	vid := proto.Vid(c.Args.Uint64("volume_id"))
+	mode := codemode.CodeModeName(c.Args.String("codemode")).GetCodeMode()
+	if !mode.IsValid() {
+		return errcode.ErrIllegalArguments
+	}
+	if !common.Confirm(fmt.Sprintf("convert volume %d into codemode %s ?", vid, mode.Name())) {
+		return nil
+	}
+
+	ret, err := newSchedulerClient(c).AddConvertTask(common.CmdContext(),
+		&scheduler.AddConvertTaskArgs{Vid: vid, CodeMode: mode})
+	if err != nil {
+		return err
+	}
+	fmt.Println("add codemode convert task successfully:", ret.TaskID)
+	return nil
+}
+
+func cmdListConvertTasks(c *grumble.Context) error {
+	ret, err := newSchedulerClient(c).ListConvertTasks(common.CmdContext())
+	if err != nil {
+		return err
+	}
+	for _, task := range ret.Tasks {
+		fmt.Printf("%s vid:%d(%s) -> vid:%d(%s) state:%d converted:%d skipped:%d redo:%d\n",
+			task.TaskID, task.SourceVid, task.SourceCodeMode.Name(),
+			task.TargetVid, task.TargetCodeMode.Name(), task.State,
+			task.ConvertedCnt, len(task.SkippedBids), task.WorkerRedoCnt)
+	}
+	return nil
+}
+
+func cmdGetConvertedVolume(c *grumble.Context) error {
+	vid := proto.Vid(c.Args.Uint64("volume_id"))
+	converted, err := newSchedulerClient(c).GetConvertedVolume(common.CmdContext(), vid)
+	if err != nil {
+		return err
+	}
+	fmt.Println(common.Readable(converted))
+	return nil
+}
+
+func cmdConvertStat(c *grumble.Context) error {
+	stat, err := newSchedulerClient(c).LeaderStats(common.CmdContext())
+	if err != nil {
+		return err
+	}
+	if stat.CodeModeConvert == nil {
+		fmt.Println("codemode convert is not running")
+		return nil
+	}
+	fmt.Println(common.Readable(stat.CodeModeConvert))
+	return nil
+}
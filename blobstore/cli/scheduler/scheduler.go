@@ -80,10 +80,19 @@ func Register(app *grumble.App) {
 		Run:   leaderStat,
 		Flags: clusterFlags,
 	})
+	schedulerCommand.AddCommand(&grumble.Command{
+		Name:  "risk",
+		Help:  "show volumes at risk in repairing",
+		Run:   volumesAtRisk,
+		Flags: clusterFlags,
+	})
 
 	addCmdMigrateTask(schedulerCommand)
 	addCmdVolumeInspectCheckpointTask(schedulerCommand)
 	addCmdKafkaConsumer(schedulerCommand)
+	addCmdCodeModeConvert(schedulerCommand)
+	addCmdClusterMigrate(schedulerCommand)
+	addCmdVolumeCompact(schedulerCommand)
 }
 
 func leaderStat(c *grumble.Context) error {
@@ -97,3 +106,19 @@ func leaderStat(c *grumble.Context) error {
 	fmt.Println(common.Readable(stat))
 	return nil
 }
+
+func volumesAtRisk(c *grumble.Context) error {
+	clusterID := getClusterID(c.Flags)
+	clusterMgrCli := newClusterMgrClient(clusterID)
+	cli := scheduler.New(&scheduler.Config{}, clusterMgrCli, clusterID)
+	stat, err := cli.LeaderStats(common.CmdContext())
+	if err != nil {
+		return err
+	}
+	if stat.VolumesAtRisk == nil {
+		fmt.Println("no volumes at risk")
+		return nil
+	}
+	fmt.Println(common.Readable(stat.VolumesAtRisk))
+	return nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package scheduler
+
+import (
+	"github.com/desertbit/grumble"
+
+	"github.com/cubefs/cubefs/blobstore/cli/common"
+	"github.com/cubefs/cubefs/blobstore/cli/common/fmt"
+)
+
+func addCmdVolumeCompact(cmd *grumble.Command) {
+	compactCommand := &grumble.Command{
+		Name:     "volume_compact",
+		Help:     "volume compact tools",
+		LongHelp: "compact volumes which most blobs have been deleted and release their units",
+	}
+	cmd.AddCommand(compactCommand)
+
+	compactCommand.AddCommand(&grumble.Command{
+		Name:  "list",
+		Help:  "list volume compact tasks",
+		Run:   cmdListVolumeCompactTasks,
+		Flags: clusterFlags,
+	})
+	compactCommand.AddCommand(&grumble.Command{
+		Name:  "stat",
+		Help:  "show volume compact stat",
+		Run:   cmdVolumeCompactStat,
+		Flags: clusterFlags,
+	})
+}
+
+func cmdListVolumeCompactTasks(c *grumble.Context) error {
+	ret, err := newSchedulerClient(c).ListVolumeCompactTasks(common.CmdContext())
+	if err != nil {
+		return err
+	}
+	for _, task := range ret.Tasks {
+		fmt.Printf("%s vid:%d -> vid:%d codemode:%s state:%d compacted:%d released:%v redo:%d\n",
+			task.TaskID, task.SourceVid, task.TargetVid, task.CodeMode.Name(),
+			task.State, task.CompactedCnt, task.Released, task.WorkerRedoCnt)
+	}
+	return nil
+}
+
+func cmdVolumeCompactStat(c *grumble.Context) error {
+	stat, err := newSchedulerClient(c).LeaderStats(common.CmdContext())
+	if err != nil {
+		return err
+	}
+	if stat.VolumeCompact == nil {
+		fmt.Println("volume compact is not running")
+		return nil
+	}
+	fmt.Println(common.Readable(stat.VolumeCompact))
+	return nil
+}
@@ -152,5 +152,9 @@ func NewHandler(service *Service) *rpc.Router {
 
 	rpc.GET("/kv/list", service.KvList, rpc.OptArgsQuery())
 
+	rpc.POST("/kv/ref", service.KvRef, rpc.OptArgsBody())
+
+	rpc.POST("/kv/unref", service.KvUnref, rpc.OptArgsBody())
+
 	return rpc.DefaultRouter
 }
@@ -147,3 +147,66 @@ func (s *Service) KvList(c *rpc.Context) {
 
 	c.RespondJSON(ret)
 }
+
+// KvRef increases reference count of key, the value is set by the first reference
+func (s *Service) KvRef(c *rpc.Context) {
+	args := new(clustermgr.RefKvArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	if args.Key == "" || args.Value == nil || proto.IsSysConfigKey(args.Key) {
+		c.RespondError(apierrors.ErrIllegalArguments)
+		return
+	}
+	s.proposeRefKv(c, kvmgr.OperTypeRefKv, &kvmgr.RefKvCtx{Key: args.Key, Holder: args.Holder, Value: args.Value})
+}
+
+// KvUnref decreases reference count of key, the key is deleted when it reaches zero
+func (s *Service) KvUnref(c *rpc.Context) {
+	args := new(clustermgr.UnrefKvArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	if args.Key == "" || proto.IsSysConfigKey(args.Key) {
+		c.RespondError(apierrors.ErrIllegalArguments)
+		return
+	}
+	s.proposeRefKv(c, kvmgr.OperTypeUnrefKv, &kvmgr.RefKvCtx{Key: args.Key, Holder: args.Holder})
+}
+
+func (s *Service) proposeRefKv(c *rpc.Context, operType int32, args *kvmgr.RefKvCtx) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContextSafe(ctx)
+	span.Debugf("accept reference kv request, operation: %d, key: %s", operType, args.Key)
+
+	args.PendingKey = s.KvMgr.NewPendingKey()
+	defer s.KvMgr.DeletePendingKey(args.PendingKey)
+	data, err := json.Marshal(args)
+	if err != nil {
+		span.Errorf("marshal failed, error:%v", err)
+		c.RespondError(err)
+		return
+	}
+
+	err = s.raftNode.Propose(ctx, base.EncodeProposeInfo(s.KvMgr.GetModuleName(), operType, data, base.ProposeContext{ReqID: span.TraceID()}))
+	if err != nil {
+		span.Errorf("raft propose failed, error:%v", err)
+		c.RespondError(apierrors.ErrRaftPropose)
+		return
+	}
+
+	result, ok := s.KvMgr.LoadPendingResult(args.PendingKey)
+	if !ok {
+		span.Error("propose success without set pending key")
+		c.RespondError(apierrors.ErrCMUnexpect)
+		return
+	}
+	if result.Err != nil {
+		span.Warnf("reference kv failed, key: %s, error: %v", args.Key, result.Err)
+		c.RespondError(errors.Cause(result.Err))
+		return
+	}
+	c.RespondJSON(&result.Ret)
+}
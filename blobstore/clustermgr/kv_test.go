@@ -76,6 +76,46 @@ func TestKV(t *testing.T) {
 		_, err := testClusterClient.ListKV(ctx, &clustermgr.ListKvOpts{})
 		require.NoError(t, err)
 	}
+
+	{
+		ret, err := testClusterClient.RefKV(ctx, "ref1", "", []byte("value1"))
+		require.NoError(t, err)
+		require.Equal(t, clustermgr.RefKvRet{Value: []byte("value1"), RefCount: 1}, ret)
+		ret, err = testClusterClient.RefKV(ctx, "ref1", "", []byte("value2"))
+		require.NoError(t, err)
+		require.Equal(t, clustermgr.RefKvRet{Value: []byte("value1"), RefCount: 2}, ret)
+
+		ret, err = testClusterClient.UnrefKV(ctx, "ref1", "")
+		require.NoError(t, err)
+		require.Equal(t, int64(1), ret.RefCount)
+		ret, err = testClusterClient.UnrefKV(ctx, "ref1", "")
+		require.NoError(t, err)
+		require.Equal(t, int64(0), ret.RefCount)
+		_, err = testClusterClient.GetKV(ctx, "ref1")
+		require.Error(t, err)
+
+		_, err = testClusterClient.UnrefKV(ctx, "ref1", "")
+		require.Error(t, err)
+
+		// the replayed dereference of holder does nothing
+		_, err = testClusterClient.RefKV(ctx, "ref2", "holder1", []byte("value"))
+		require.NoError(t, err)
+		ret, err = testClusterClient.RefKV(ctx, "ref2", "holder2", []byte("value"))
+		require.NoError(t, err)
+		require.Equal(t, int64(2), ret.RefCount)
+		for range [2]struct{}{} {
+			ret, err = testClusterClient.UnrefKV(ctx, "ref2", "holder1")
+			require.NoError(t, err)
+			require.Equal(t, int64(1), ret.RefCount)
+		}
+		ret, err = testClusterClient.UnrefKV(ctx, "ref2", "holder2")
+		require.NoError(t, err)
+		require.Equal(t, int64(0), ret.RefCount)
+		_, err = testClusterClient.RefKV(ctx, "", "", []byte("value"))
+		require.Error(t, err)
+		_, err = testClusterClient.RefKV(ctx, proto.CodeModeConfigKey, "", []byte("value"))
+		require.Error(t, err)
+	}
 }
 
 func BenchmarkService_KvSet(b *testing.B) {
@@ -24,6 +24,7 @@ import (
 
 	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
 	"github.com/cubefs/cubefs/blobstore/clustermgr/base"
+	apierrors "github.com/cubefs/cubefs/blobstore/common/errors"
 	"github.com/cubefs/cubefs/blobstore/common/trace"
 	"github.com/cubefs/cubefs/blobstore/util/errors"
 )
@@ -31,6 +32,8 @@ import (
 const (
 	OperTypeSetKv = iota + 1
 	OperTypeDeleteKv
+	OperTypeRefKv
+	OperTypeUnrefKv
 )
 
 func (t *KvMgr) LoadData(ctx context.Context) error {
@@ -82,6 +85,34 @@ func (t *KvMgr) Apply(ctx context.Context, operTypes []int32, datas [][]byte, co
 				errs[idx] = t.Delete(kvDeleteArgs.Key)
 				wg.Done()
 			})
+
+		case OperTypeRefKv, OperTypeUnrefKv:
+			args := &RefKvCtx{}
+			err = json.Unmarshal(datas[idx], args)
+			if err != nil {
+				errs[idx] = errors.Info(err, "json unmarshal failed, data: ", datas[idx]).Detail(err)
+				wg.Done()
+				continue
+			}
+			operType := tp
+			t.taskPool.Run(t.getTaskIdx(args.Key), func() {
+				defer wg.Done()
+				var ret clustermgr.RefKvRet
+				var applyErr error
+				if operType == OperTypeRefKv {
+					ret, applyErr = t.Ref(args.Key, args.Holder, args.Value)
+				} else {
+					ret, applyErr = t.Unref(args.Key, args.Holder)
+				}
+				// error of illegal key is responded to the proposer rather than failing apply
+				if _, ok := t.pendingEntries.Load(args.PendingKey); ok {
+					t.pendingEntries.Store(args.PendingKey, &RefKvResult{Ret: ret, Err: applyErr})
+				}
+				if applyErr != nil && !isRefArgumentErr(applyErr) {
+					errs[idx] = applyErr
+				}
+			})
+
 		default:
 			err = errors.New("unsupported operation")
 			return
@@ -112,6 +143,11 @@ func (t *KvMgr) NotifyLeaderChange(ctx context.Context, leader uint64, host stri
 	// Do nothing.
 }
 
+func isRefArgumentErr(err error) bool {
+	cause := errors.Cause(err)
+	return cause == apierrors.ErrNotFound || cause == apierrors.ErrIllegalArguments
+}
+
 func (t *KvMgr) getTaskIdx(key string) int {
 	h := fnv.New64()
 	h.Write([]byte(key))
@@ -15,9 +15,17 @@
 package kvmgr
 
 import (
+	"encoding/json"
+	"sync"
+
+	"github.com/google/uuid"
+
 	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
 	"github.com/cubefs/cubefs/blobstore/clustermgr/base"
 	"github.com/cubefs/cubefs/blobstore/clustermgr/persistence/kvdb"
+	apierrors "github.com/cubefs/cubefs/blobstore/common/errors"
+	"github.com/cubefs/cubefs/blobstore/common/kvstore"
+	"github.com/cubefs/cubefs/blobstore/util/errors"
 )
 
 const moduleName = "kv manager"
@@ -39,6 +47,40 @@ type KvMgr struct {
 	applyConcurrency uint64
 	tbl              *kvdb.KvTable
 	taskPool         *base.TaskDistribution
+
+	// pendingEntries keeps apply results of reference operations
+	pendingEntries sync.Map
+}
+
+// RefKvCtx is the propose data of reference and dereference operations
+type RefKvCtx struct {
+	PendingKey string `json:"pending_key"`
+	Key        string `json:"key"`
+	Holder     string `json:"holder,omitempty"`
+	Value      []byte `json:"value,omitempty"`
+}
+
+// RefKvResult is the apply result of reference and dereference operations
+type RefKvResult struct {
+	Ret clustermgr.RefKvRet
+	Err error
+}
+
+// refValue is the stored value of reference counted key,
+// RefCount includes the references without holder.
+type refValue struct {
+	Value    []byte   `json:"value"`
+	RefCount int64    `json:"ref_count"`
+	Holders  []string `json:"holders,omitempty"`
+}
+
+func (ref *refValue) holderIndex(holder string) int {
+	for idx := range ref.Holders {
+		if ref.Holders[idx] == holder {
+			return idx
+		}
+	}
+	return -1
 }
 
 func NewKvMgr(db *kvdb.KvDB) (*KvMgr, error) {
@@ -94,3 +136,95 @@ func (t *KvMgr) List(opts *clustermgr.ListKvOpts) (ret *clustermgr.ListKvRet, er
 func (t *KvMgr) Delete(key string) (err error) {
 	return t.tbl.Delete([]byte(key))
 }
+
+// NewPendingKey returns a pending key which receives the apply result of
+// reference operation, the key must be released by DeletePendingKey.
+func (t *KvMgr) NewPendingKey() string {
+	key := uuid.New().String()
+	t.pendingEntries.Store(key, nil)
+	return key
+}
+
+// LoadPendingResult returns the apply result of pending key
+func (t *KvMgr) LoadPendingResult(key string) (*RefKvResult, bool) {
+	value, ok := t.pendingEntries.Load(key)
+	if !ok || value == nil {
+		return nil, false
+	}
+	return value.(*RefKvResult), true
+}
+
+func (t *KvMgr) DeletePendingKey(key string) {
+	t.pendingEntries.Delete(key)
+}
+
+// Ref increases reference count of key, the key is set with value
+// if not exists, otherwise the value keeps the first referenced one.
+// The not empty holder is counted once.
+func (t *KvMgr) Ref(key, holder string, value []byte) (ret clustermgr.RefKvRet, err error) {
+	ref, err := t.getRef(key)
+	if err == kvstore.ErrNotFound {
+		ref, err = &refValue{Value: value}, nil
+	}
+	if err != nil {
+		return
+	}
+	if holder != "" {
+		if ref.holderIndex(holder) >= 0 {
+			return clustermgr.RefKvRet{Value: ref.Value, RefCount: ref.RefCount}, nil
+		}
+		ref.Holders = append(ref.Holders, holder)
+	}
+	ref.RefCount++
+	if err = t.setRef(key, ref); err != nil {
+		return
+	}
+	return clustermgr.RefKvRet{Value: ref.Value, RefCount: ref.RefCount}, nil
+}
+
+// Unref decreases reference count of key, the key is deleted when
+// reference count reaches zero. The not empty holder which has not
+// referenced the key changes nothing.
+func (t *KvMgr) Unref(key, holder string) (ret clustermgr.RefKvRet, err error) {
+	ref, err := t.getRef(key)
+	if err == kvstore.ErrNotFound {
+		return ret, apierrors.ErrNotFound
+	}
+	if err != nil {
+		return
+	}
+	if holder != "" {
+		idx := ref.holderIndex(holder)
+		if idx < 0 {
+			return clustermgr.RefKvRet{Value: ref.Value, RefCount: ref.RefCount}, nil
+		}
+		ref.Holders = append(ref.Holders[:idx], ref.Holders[idx+1:]...)
+	}
+	ref.RefCount--
+	ret = clustermgr.RefKvRet{Value: ref.Value, RefCount: ref.RefCount}
+	if ref.RefCount <= 0 {
+		ret.RefCount = 0
+		return ret, t.Delete(key)
+	}
+	return ret, t.setRef(key, ref)
+}
+
+func (t *KvMgr) getRef(key string) (*refValue, error) {
+	data, err := t.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	ref := &refValue{}
+	if err = json.Unmarshal(data, ref); err != nil || ref.RefCount <= 0 {
+		return nil, errors.Info(apierrors.ErrIllegalArguments, "key is not reference counted: ", key)
+	}
+	return ref, nil
+}
+
+func (t *KvMgr) setRef(key string, ref *refValue) error {
+	data, err := json.Marshal(ref)
+	if err != nil {
+		return err
+	}
+	return t.Set(key, data)
+}
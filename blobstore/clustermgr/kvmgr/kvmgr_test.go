@@ -28,6 +28,7 @@ import (
 	"github.com/cubefs/cubefs/blobstore/api/clustermgr"
 	"github.com/cubefs/cubefs/blobstore/clustermgr/base"
 	"github.com/cubefs/cubefs/blobstore/clustermgr/persistence/kvdb"
+	apierrors "github.com/cubefs/cubefs/blobstore/common/errors"
 	"github.com/cubefs/cubefs/blobstore/common/trace"
 	_ "github.com/cubefs/cubefs/blobstore/testing/nolog"
 )
@@ -197,7 +198,7 @@ func TestKvMgr_Apply(t *testing.T) {
 			datas     [][]byte
 		}{
 			{
-				operTypes: []int32{3},
+				operTypes: []int32{100},
 				ctxs:      []base.ProposeContext{{ReqID: span.TraceID()}},
 				datas:     [][]byte{data},
 			},
@@ -220,3 +221,72 @@ func TestKvMgr_Apply(t *testing.T) {
 
 	}
 }
+
+func TestKvMgr_Ref(t *testing.T) {
+	tmpKvDBPath := "/tmp/tmpKvDBPath" + strconv.Itoa(rand.Intn(1000000000))
+	defer os.RemoveAll(tmpKvDBPath)
+
+	kvDB, _ := kvdb.Open(tmpKvDBPath)
+	kvMgr, err := NewKvMgr(kvDB)
+	require.NoError(t, err)
+	_, ctx := trace.StartSpanFromContext(context.Background(), "")
+
+	applyHolder := func(operType int32, key, holder string, value []byte) *RefKvResult {
+		args := &RefKvCtx{PendingKey: kvMgr.NewPendingKey(), Key: key, Holder: holder, Value: value}
+		defer kvMgr.DeletePendingKey(args.PendingKey)
+		data, err := json.Marshal(args)
+		require.NoError(t, err)
+		require.NoError(t, kvMgr.Apply(ctx, []int32{operType}, [][]byte{data}, nil))
+		result, ok := kvMgr.LoadPendingResult(args.PendingKey)
+		require.True(t, ok)
+		return result
+	}
+	apply := func(operType int32, key string, value []byte) *RefKvResult {
+		return applyHolder(operType, key, "", value)
+	}
+
+	result := apply(OperTypeRefKv, "ref-key", []byte("value-1"))
+	require.NoError(t, result.Err)
+	require.Equal(t, clustermgr.RefKvRet{Value: []byte("value-1"), RefCount: 1}, result.Ret)
+	// the value keeps the first referenced one
+	result = apply(OperTypeRefKv, "ref-key", []byte("value-2"))
+	require.NoError(t, result.Err)
+	require.Equal(t, clustermgr.RefKvRet{Value: []byte("value-1"), RefCount: 2}, result.Ret)
+
+	result = apply(OperTypeUnrefKv, "ref-key", nil)
+	require.NoError(t, result.Err)
+	require.Equal(t, int64(1), result.Ret.RefCount)
+	result = apply(OperTypeUnrefKv, "ref-key", nil)
+	require.NoError(t, result.Err)
+	require.Equal(t, int64(0), result.Ret.RefCount)
+	require.Equal(t, []byte("value-1"), result.Ret.Value)
+	_, err = kvMgr.Get("ref-key")
+	require.Error(t, err)
+
+	// not found or not reference counted key
+	result = apply(OperTypeUnrefKv, "ref-key", nil)
+	require.ErrorIs(t, result.Err, apierrors.ErrNotFound)
+	require.NoError(t, kvMgr.Set("plain-key", []byte("plain-value")))
+	result = apply(OperTypeRefKv, "plain-key", []byte("value"))
+	require.Error(t, result.Err)
+
+	// the holder references and dereferences once
+	for _, holder := range []string{"holder-1", "holder-1", "holder-2"} {
+		require.NoError(t, applyHolder(OperTypeRefKv, "holder-key", holder, []byte("value")).Err)
+	}
+	result = apply(OperTypeRefKv, "holder-key", nil)
+	require.NoError(t, result.Err)
+	require.Equal(t, int64(3), result.Ret.RefCount)
+	for _, holder := range []string{"holder-1", "holder-1", "holder-3"} {
+		result = applyHolder(OperTypeUnrefKv, "holder-key", holder, nil)
+		require.NoError(t, result.Err)
+		require.Equal(t, int64(2), result.Ret.RefCount)
+	}
+	require.Equal(t, int64(1), apply(OperTypeUnrefKv, "holder-key", nil).Ret.RefCount)
+	require.Equal(t, int64(0), applyHolder(OperTypeUnrefKv, "holder-key", "holder-2", nil).Ret.RefCount)
+	_, err = kvMgr.Get("holder-key")
+	require.Error(t, err)
+
+	// bad data
+	require.Error(t, kvMgr.Apply(ctx, []int32{OperTypeRefKv}, [][]byte{[]byte("{")}, nil))
+}
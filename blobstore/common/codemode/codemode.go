@@ -363,6 +363,23 @@ func (c *Tactic) LocalStripeInAZ(azIndex int) (localStripe []int, n, m int) {
 	return azStripes[azIndex][:], n + m, l
 }
 
+// AZIndex returns az index of the shard index in ec layout, returns -1 if index is invalid
+func (c *Tactic) AZIndex(index int) int {
+	n, m, l := c.N/c.AZCount, c.M/c.AZCount, c.L/c.AZCount
+	switch {
+	case index < 0:
+		return -1
+	case index < c.N:
+		return index / n
+	case index < c.N+c.M:
+		return (index - c.N) / m
+	case index < c.N+c.M+c.L:
+		return (index - c.N - c.M) / l
+	default:
+		return -1
+	}
+}
+
 // IsReplicateMode return current mode tactic is replicate or not
 func (c *Tactic) IsReplicateMode() bool {
 	return c.M == 0 && c.L == 0
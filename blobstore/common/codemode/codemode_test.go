@@ -247,3 +247,16 @@ func BenchmarkLocalStripe(b *testing.B) {
 		tactic.LocalStripe(37)
 	}
 }
+
+func TestAZIndex(t *testing.T) {
+	for _, mode := range GetAllCodeModes() {
+		tactic := mode.T()
+		for azIdx, stripe := range tactic.GetECLayoutByAZ() {
+			for _, idx := range stripe {
+				require.Equal(t, azIdx, tactic.AZIndex(idx))
+			}
+		}
+		require.Equal(t, -1, tactic.AZIndex(-1))
+		require.Equal(t, -1, tactic.AZIndex(mode.GetShardNum()))
+	}
+}
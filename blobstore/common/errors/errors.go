@@ -53,6 +53,7 @@ var errCodeMap = map[int]string{
 	CodeAccessServiceDiscovery: "access client service discovery disconnect",
 	CodeAccessLimited:          "access limited",
 	CodeAccessExceedSize:       "access exceed object size",
+	CodeAccessBlobExpired:      "access blob has expired",
 
 	// clustermgr
 	CodeCMUnexpect:                   "cm: unexpected error",
@@ -15,6 +15,8 @@
 package proto
 
 import (
+	"fmt"
+	"sort"
 	"sync"
 
 	"github.com/cubefs/cubefs/blobstore/common/codemode"
@@ -43,12 +45,18 @@ const (
 	TaskTypeVolumeInspect TaskType = "volume_inspect"
 	TaskTypeShardRepair   TaskType = "shard_repair"
 	TaskTypeBlobDelete    TaskType = "blob_delete"
+	TaskTypeBlobExpire    TaskType = "blob_expire"
+
+	TaskTypeCodeModeConvert TaskType = "codemode_convert"
+	TaskTypeClusterMigrate  TaskType = "cluster_migrate"
+	TaskTypeVolumeCompact   TaskType = "volume_compact"
 )
 
 func (t TaskType) Valid() bool {
 	switch t {
 	case TaskTypeDiskRepair, TaskTypeBalance, TaskTypeDiskDrop, TaskTypeManualMigrate,
-		TaskTypeVolumeInspect, TaskTypeShardRepair, TaskTypeBlobDelete:
+		TaskTypeVolumeInspect, TaskTypeShardRepair, TaskTypeBlobDelete, TaskTypeBlobExpire,
+		TaskTypeCodeModeConvert, TaskTypeClusterMigrate, TaskTypeVolumeCompact:
 		return true
 	default:
 		return false
@@ -201,6 +209,367 @@ func (task *ShardRepairTask) IsValid() bool {
 	return task.CodeMode.IsValid() && CheckVunitLocations(task.Sources)
 }
 
+// VolumeTask is the task which copies blobs of the source volume into the target volume
+// on a worker, such as codemode convert, cluster migrate and volume compact.
+type VolumeTask interface {
+	GetTaskID() string
+	GetState() MigrateState
+	GetSourceVid() Vid
+	GetTargetVid() Vid
+	// SetVunitLocations refreshes locations of the source and target volume
+	SetVunitLocations(sources, destinations []VunitLocation)
+	Running() bool
+	CopyTask() VolumeTask
+}
+
+// CodeModeConvertTask re-encodes all blobs of the source volume into the target volume,
+// the blobs keep their bids so that a location only needs its vid and codemode rewritten.
+type CodeModeConvertTask struct {
+	TaskID string       `json:"task_id"`
+	State  MigrateState `json:"state"`
+
+	SourceVid      Vid               `json:"source_vid"`
+	SourceCodeMode codemode.CodeMode `json:"source_code_mode"`
+	Sources        []VunitLocation   `json:"sources"`
+
+	TargetVid      Vid               `json:"target_vid"`
+	TargetCodeMode codemode.CodeMode `json:"target_code_mode"`
+	Destinations   []VunitLocation   `json:"destinations"`
+
+	// blobs the worker can not re-encode in place, whose real size is unknown
+	// in the volume, they must be copied by access with the location
+	ConvertedCnt int      `json:"converted_cnt"`
+	SkippedBids  []BlobID `json:"skipped_bids"`
+
+	Ctime string `json:"ctime"`
+	MTime string `json:"mtime"`
+
+	WorkerRedoCnt uint8 `json:"worker_redo_cnt"`
+}
+
+func (t *CodeModeConvertTask) Running() bool {
+	return t.State == MigrateStatePrepared
+}
+
+func (t *CodeModeConvertTask) Copy() *CodeModeConvertTask {
+	task := &CodeModeConvertTask{}
+	*task = *t
+	task.Sources = append([]VunitLocation(nil), t.Sources...)
+	task.Destinations = append([]VunitLocation(nil), t.Destinations...)
+	task.SkippedBids = append([]BlobID(nil), t.SkippedBids...)
+	return task
+}
+
+func (t *CodeModeConvertTask) GetTaskID() string {
+	return t.TaskID
+}
+
+func (t *CodeModeConvertTask) GetState() MigrateState {
+	return t.State
+}
+
+func (t *CodeModeConvertTask) GetSourceVid() Vid {
+	return t.SourceVid
+}
+
+func (t *CodeModeConvertTask) GetTargetVid() Vid {
+	return t.TargetVid
+}
+
+func (t *CodeModeConvertTask) SetVunitLocations(sources, destinations []VunitLocation) {
+	t.Sources = sources
+	t.Destinations = destinations
+}
+
+func (t *CodeModeConvertTask) CopyTask() VolumeTask {
+	return t.Copy()
+}
+
+func (t *CodeModeConvertTask) IsValid() bool {
+	return t.SourceCodeMode.IsValid() && t.TargetCodeMode.IsValid() &&
+		t.SourceCodeMode != t.TargetCodeMode &&
+		len(t.Sources) == t.SourceCodeMode.GetShardNum() && CheckVunitLocations(t.Sources) &&
+		len(t.Destinations) == t.TargetCodeMode.GetShardNum() && CheckVunitLocations(t.Destinations)
+}
+
+type CodeModeConvertRet struct {
+	TaskID        string   `json:"task_id"`
+	ConvertErrStr string   `json:"convert_err_str"`
+	ConvertedCnt  int      `json:"converted_cnt"`
+	SkippedBids   []BlobID `json:"skipped_bids"`
+	// MaxBid is the largest bid of the source volume when converting,
+	// blobs written after the source volume unlocked are not converted
+	MaxBid BlobID `json:"max_bid"`
+}
+
+func (ret *CodeModeConvertRet) Err() error {
+	if len(ret.ConvertErrStr) == 0 {
+		return nil
+	}
+	return errors.New(ret.ConvertErrStr)
+}
+
+// ConvertedVolume records the blobs of source volume have been re-encoded into target volume.
+// The record is set once the task is prepared, the converted copy of a blob in target volume
+// is the same blob as the one in source volume, a delete removes both of them.
+type ConvertedVolume struct {
+	SourceVid      Vid               `json:"source_vid"`
+	TargetVid      Vid               `json:"target_vid"`
+	TargetCodeMode codemode.CodeMode `json:"target_code_mode"`
+	// Converting is true until the task finished, any blob may have been copied then
+	Converting  bool     `json:"converting"`
+	MaxBid      BlobID   `json:"max_bid"`
+	SkippedBids []BlobID `json:"skipped_bids"` // sorted
+	Ctime       string   `json:"ctime"`
+}
+
+// Skipped returns true if the blob was not re-encoded into target volume.
+func (v *ConvertedVolume) Skipped(bid BlobID) bool {
+	idx := sort.Search(len(v.SkippedBids), func(i int) bool { return v.SkippedBids[i] >= bid })
+	return idx < len(v.SkippedBids) && v.SkippedBids[idx] == bid
+}
+
+// Converted returns true if the blob of source volume has been re-encoded into target volume.
+func (v *ConvertedVolume) Converted(bid BlobID) bool {
+	return !v.Converting && bid <= v.MaxBid && !v.Skipped(bid)
+}
+
+// MayConverted returns true if the blob of source volume may have a copy in target volume.
+func (v *ConvertedVolume) MayConverted(bid BlobID) bool {
+	return v.Converting || v.Converted(bid)
+}
+
+// ConvertedVolumeKey returns the kv key of converted volume in clustermgr.
+func ConvertedVolumeKey(vid Vid) string {
+	return fmt.Sprintf("converted_volume-%d", vid)
+}
+
+// ConvertedTargetVolumeKey returns the kv key of converted volume by target vid in clustermgr.
+func ConvertedTargetVolumeKey(vid Vid) string {
+	return fmt.Sprintf("converted_target_volume-%d", vid)
+}
+
+// ClusterMigrateTask copies all blobs of the source volume into the target volume
+// of another cluster, shards are copied as they are, so the blobs keep their bids
+// and codemode, a location only needs its cluster and vid rewritten.
+type ClusterMigrateTask struct {
+	TaskID string       `json:"task_id"`
+	State  MigrateState `json:"state"`
+
+	CodeMode  codemode.CodeMode `json:"code_mode"`
+	SourceVid Vid               `json:"source_vid"`
+	Sources   []VunitLocation   `json:"sources"`
+
+	TargetClusterID ClusterID       `json:"target_cluster_id"`
+	TargetVid       Vid             `json:"target_vid"`
+	Destinations    []VunitLocation `json:"destinations"`
+
+	MigratedCnt int `json:"migrated_cnt"`
+
+	Ctime string `json:"ctime"`
+	MTime string `json:"mtime"`
+
+	WorkerRedoCnt uint8 `json:"worker_redo_cnt"`
+}
+
+func (t *ClusterMigrateTask) Running() bool {
+	return t.State == MigrateStatePrepared
+}
+
+func (t *ClusterMigrateTask) Copy() *ClusterMigrateTask {
+	task := &ClusterMigrateTask{}
+	*task = *t
+	task.Sources = append([]VunitLocation(nil), t.Sources...)
+	task.Destinations = append([]VunitLocation(nil), t.Destinations...)
+	return task
+}
+
+func (t *ClusterMigrateTask) GetTaskID() string {
+	return t.TaskID
+}
+
+func (t *ClusterMigrateTask) GetState() MigrateState {
+	return t.State
+}
+
+func (t *ClusterMigrateTask) GetSourceVid() Vid {
+	return t.SourceVid
+}
+
+func (t *ClusterMigrateTask) GetTargetVid() Vid {
+	return t.TargetVid
+}
+
+func (t *ClusterMigrateTask) SetVunitLocations(sources, destinations []VunitLocation) {
+	t.Sources = sources
+	t.Destinations = destinations
+}
+
+func (t *ClusterMigrateTask) CopyTask() VolumeTask {
+	return t.Copy()
+}
+
+func (t *ClusterMigrateTask) IsValid() bool {
+	return t.CodeMode.IsValid() && t.TargetClusterID != 0 &&
+		len(t.Sources) == t.CodeMode.GetShardNum() && CheckVunitLocations(t.Sources) &&
+		len(t.Destinations) == t.CodeMode.GetShardNum() && CheckVunitLocations(t.Destinations)
+}
+
+type ClusterMigrateRet struct {
+	TaskID        string `json:"task_id"`
+	MigrateErrStr string `json:"migrate_err_str"`
+	MigratedCnt   int    `json:"migrated_cnt"`
+}
+
+func (ret *ClusterMigrateRet) Err() error {
+	if len(ret.MigrateErrStr) == 0 {
+		return nil
+	}
+	return errors.New(ret.MigrateErrStr)
+}
+
+// MigratedVolume records the blobs of source volume have been copied into target
+// volume of another cluster, it is kept in the target cluster to outlive the source.
+type MigratedVolume struct {
+	SourceClusterID ClusterID         `json:"source_cluster_id"`
+	SourceVid       Vid               `json:"source_vid"`
+	TargetClusterID ClusterID         `json:"target_cluster_id"`
+	TargetVid       Vid               `json:"target_vid"`
+	CodeMode        codemode.CodeMode `json:"code_mode"`
+	Ctime           string            `json:"ctime"`
+}
+
+// MigratedVolumeKeyPrefix is the kv key prefix of migrated volumes.
+const MigratedVolumeKeyPrefix = "migrated_volume-"
+
+// MigratedVolumeKey returns the kv key of migrated volume in clustermgr of target cluster.
+func MigratedVolumeKey(clusterID ClusterID, vid Vid) string {
+	return fmt.Sprintf("%s%d-%d", MigratedVolumeKeyPrefix, clusterID, vid)
+}
+
+// ReservedVolume is the locked volume reserved by a running task, such as the target
+// volume of cluster migrate, the other tasks of the cluster never unlock it.
+type ReservedVolume struct {
+	Vid    Vid    `json:"vid"`
+	TaskID string `json:"task_id"`
+	Ctime  string `json:"ctime"`
+}
+
+// ReservedVolumeKey returns the kv key of reserved volume in clustermgr.
+func ReservedVolumeKey(vid Vid) string {
+	return fmt.Sprintf("reserved_volume-%d", vid)
+}
+
+// VolumeCompactTask copies live blobs of a mostly deleted volume into a new
+// allocated volume, shards are copied as they are, so the blobs keep their bids
+// and codemode. Volume units of the source are released after compacted.
+type VolumeCompactTask struct {
+	TaskID string       `json:"task_id"`
+	State  MigrateState `json:"state"`
+
+	CodeMode  codemode.CodeMode `json:"code_mode"`
+	SourceVid Vid               `json:"source_vid"`
+	Sources   []VunitLocation   `json:"sources"`
+
+	TargetVid    Vid             `json:"target_vid"`
+	Destinations []VunitLocation `json:"destinations"`
+
+	CompactedCnt int  `json:"compacted_cnt"`
+	Released     bool `json:"released"`
+
+	Ctime string `json:"ctime"`
+	MTime string `json:"mtime"`
+
+	WorkerRedoCnt uint8 `json:"worker_redo_cnt"`
+}
+
+func (t *VolumeCompactTask) Running() bool {
+	return t.State == MigrateStatePrepared
+}
+
+func (t *VolumeCompactTask) Copy() *VolumeCompactTask {
+	task := &VolumeCompactTask{}
+	*task = *t
+	task.Sources = append([]VunitLocation(nil), t.Sources...)
+	task.Destinations = append([]VunitLocation(nil), t.Destinations...)
+	return task
+}
+
+func (t *VolumeCompactTask) GetTaskID() string {
+	return t.TaskID
+}
+
+func (t *VolumeCompactTask) GetState() MigrateState {
+	return t.State
+}
+
+func (t *VolumeCompactTask) GetSourceVid() Vid {
+	return t.SourceVid
+}
+
+func (t *VolumeCompactTask) GetTargetVid() Vid {
+	return t.TargetVid
+}
+
+func (t *VolumeCompactTask) SetVunitLocations(sources, destinations []VunitLocation) {
+	t.Sources = sources
+	t.Destinations = destinations
+}
+
+func (t *VolumeCompactTask) CopyTask() VolumeTask {
+	return t.Copy()
+}
+
+func (t *VolumeCompactTask) IsValid() bool {
+	return t.CodeMode.IsValid() && t.SourceVid != t.TargetVid &&
+		len(t.Sources) == t.CodeMode.GetShardNum() && CheckVunitLocations(t.Sources) &&
+		len(t.Destinations) == t.CodeMode.GetShardNum() && CheckVunitLocations(t.Destinations)
+}
+
+type VolumeCompactRet struct {
+	TaskID        string `json:"task_id"`
+	CompactErrStr string `json:"compact_err_str"`
+	CompactedCnt  int    `json:"compacted_cnt"`
+}
+
+func (ret *VolumeCompactRet) Err() error {
+	if len(ret.CompactErrStr) == 0 {
+		return nil
+	}
+	return errors.New(ret.CompactErrStr)
+}
+
+// CompactedVolume records the live blobs of source volume have been copied into target volume,
+// the target volume may be compacted again, so a blob is found by following the records.
+type CompactedVolume struct {
+	SourceVid Vid    `json:"source_vid"`
+	TargetVid Vid    `json:"target_vid"`
+	Ctime     string `json:"ctime"`
+}
+
+// CompactedVolumeKey returns the kv key of compacted volume in clustermgr.
+func CompactedVolumeKey(vid Vid) string {
+	return fmt.Sprintf("compacted_volume-%d", vid)
+}
+
+// ExpiringBlobKeyPrefix is the kv key prefix of expiring blobs in clustermgr,
+// the keys are in order of expiry time.
+const ExpiringBlobKeyPrefix = "expiring_blob-"
+
+// ExpiringBlob records consecutive blobs of one volume which expire at the unix time,
+// the blobs are deleted by scheduler once the expiry passes.
+type ExpiringBlob struct {
+	Vid    Vid    `json:"vid"`
+	MinBid BlobID `json:"min_bid"`
+	Count  uint32 `json:"count"`
+	Expire int64  `json:"expire"`
+}
+
+// Key returns the kv key of expiring blobs in clustermgr.
+func (b *ExpiringBlob) Key() string {
+	return fmt.Sprintf("%s%016x-%d-%d", ExpiringBlobKeyPrefix, b.Expire, b.Vid, b.MinBid)
+}
+
 // TaskStatistics thread-unsafe task statistics.
 type TaskStatistics struct {
 	DoneSize   uint64 `json:"done_size"`
@@ -0,0 +1,79 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package raftmq
+
+import (
+	"path"
+
+	"github.com/cubefs/cubefs/blobstore/common/raftserver"
+	"github.com/cubefs/cubefs/blobstore/util/defaulter"
+	"github.com/cubefs/cubefs/blobstore/util/errors"
+)
+
+const (
+	defaultRetainMessages      = 1 << 20
+	defaultCheckpointIntervalS = 60
+	defaultFetchCount          = 100
+
+	walDirName     = "wal"
+	checkpointName = "checkpoint"
+)
+
+// Config is the config of raft message queue
+type Config struct {
+	NodeID     uint64 `json:"node_id"`
+	ListenPort int    `json:"listen_port"`
+	// Dir keeps raft wal and the checkpoint of queue
+	Dir     string `json:"dir"`
+	WalSync bool   `json:"wal_sync"`
+
+	TickIntervalMs int `json:"tick_interval_ms"`
+	HeartbeatTick  int `json:"heartbeat_tick"`
+	ElectionTick   int `json:"election_tick"`
+
+	Members []raftserver.Member `json:"members"`
+
+	// RetainMessages is the max messages of each topic, the messages are dropped
+	// after consumed by all groups, producing is rejected if the topic is full.
+	RetainMessages      int `json:"retain_messages"`
+	CheckpointIntervalS int `json:"checkpoint_interval_s"`
+}
+
+func (cfg *Config) checkAndFix() error {
+	if cfg.NodeID == 0 || cfg.ListenPort == 0 || cfg.Dir == "" {
+		return errors.New("invalid raft mq config")
+	}
+	if len(cfg.Members) == 0 {
+		return errors.New("empty raft mq members")
+	}
+	defaulter.LessOrEqual(&cfg.RetainMessages, defaultRetainMessages)
+	defaulter.LessOrEqual(&cfg.CheckpointIntervalS, defaultCheckpointIntervalS)
+	return nil
+}
+
+func (cfg *Config) raftConfig(sm raftserver.StateMachine, applied uint64) *raftserver.Config {
+	return &raftserver.Config{
+		NodeId:         cfg.NodeID,
+		ListenPort:     cfg.ListenPort,
+		WalDir:         path.Join(cfg.Dir, walDirName),
+		WalSync:        cfg.WalSync,
+		TickIntervalMs: cfg.TickIntervalMs,
+		HeartbeatTick:  cfg.HeartbeatTick,
+		ElectionTick:   cfg.ElectionTick,
+		Members:        cfg.Members,
+		Applied:        applied,
+		SM:             sm,
+	}
+}
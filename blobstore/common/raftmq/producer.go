@@ -0,0 +1,46 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package raftmq
+
+import (
+	"context"
+	"time"
+
+	"github.com/cubefs/cubefs/blobstore/common/kafka"
+)
+
+type producer struct {
+	queue   *Queue
+	timeout time.Duration
+}
+
+// NewProducer returns a kafka.MsgProducer which sends messages into raft queue
+func NewProducer(queue *Queue, timeoutMs int64) kafka.MsgProducer {
+	return &producer{queue: queue, timeout: time.Duration(timeoutMs) * time.Millisecond}
+}
+
+func (p *producer) SendMessage(topic string, msg []byte) error {
+	return p.SendMessages(topic, [][]byte{msg})
+}
+
+func (p *producer) SendMessages(topic string, msgs [][]byte) error {
+	ctx := context.Background()
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+	return p.queue.Produce(ctx, topic, msgs)
+}
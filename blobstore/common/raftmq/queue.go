@@ -0,0 +1,324 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package raftmq is a replicated and persistent message queue built on raft,
+// it keeps an offset indexed log of each topic and the consumed offsets of
+// consumer groups, so that blobstore could run without kafka.
+package raftmq
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"hash/crc32"
+	"io"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"go.etcd.io/etcd/raft/v3"
+
+	"github.com/cubefs/cubefs/blobstore/common/raftserver"
+	"github.com/cubefs/cubefs/blobstore/util/closer"
+	"github.com/cubefs/cubefs/blobstore/util/errors"
+	"github.com/cubefs/cubefs/blobstore/util/log"
+)
+
+var (
+	// ErrEmptyTopic topic or group is empty
+	ErrEmptyTopic = errors.New("empty topic or group")
+	// ErrTopicFull topic has retained too many messages not consumed
+	ErrTopicFull = errors.New("topic is full")
+)
+
+// Message is a message of topic
+type Message struct {
+	Offset    int64  `json:"offset"`
+	Value     []byte `json:"value"`
+	Timestamp int64  `json:"timestamp"` // unix nano
+}
+
+type topicLog struct {
+	StartOffset int64     `json:"start_offset"`
+	Messages    []Message `json:"messages"`
+}
+
+func (l *topicLog) nextOffset() int64 {
+	return l.StartOffset + int64(len(l.Messages))
+}
+
+// trim drops messages before offset
+func (l *topicLog) trim(offset int64) {
+	if offset <= l.StartOffset {
+		return
+	}
+	n := offset - l.StartOffset
+	if n > int64(len(l.Messages)) {
+		n = int64(len(l.Messages))
+	}
+	l.Messages = append([]Message(nil), l.Messages[n:]...)
+	l.StartOffset += n
+}
+
+type queueState struct {
+	Applied uint64               `json:"applied"`
+	Topics  map[string]*topicLog `json:"topics"`
+	// Offsets is the next offset to consume of topic and group
+	Offsets map[string]map[string]int64 `json:"offsets"`
+}
+
+func newQueueState() *queueState {
+	return &queueState{
+		Topics:  make(map[string]*topicLog),
+		Offsets: make(map[string]map[string]int64),
+	}
+}
+
+// Queue is a message queue replicated by raft
+type Queue struct {
+	cfg Config
+	closer.Closer
+
+	raft raftserver.RaftServer
+
+	mu    sync.RWMutex
+	state *queueState
+}
+
+// Open opens a raft message queue, it loads the last checkpoint
+// and replays the raft wal after the checkpoint.
+func Open(cfg Config) (*Queue, error) {
+	if err := cfg.checkAndFix(); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	state, err := loadCheckpoint(path.Join(cfg.Dir, checkpointName))
+	if err != nil {
+		return nil, err
+	}
+
+	q := &Queue{
+		cfg:    cfg,
+		Closer: closer.New(),
+		state:  state,
+	}
+	q.raft, err = raftserver.NewRaftServer(cfg.raftConfig(q, state.Applied))
+	if err != nil {
+		return nil, err
+	}
+
+	go q.checkpointLoop()
+	return q, nil
+}
+
+// Close stops the queue and saves checkpoint
+func (q *Queue) Close() {
+	q.Closer.Close()
+	q.raft.Stop()
+	if _, err := q.checkpoint(); err != nil {
+		log.Errorf("save raft mq checkpoint failed: err[%+v]", err)
+	}
+}
+
+// Produce appends messages into topic
+func (q *Queue) Produce(ctx context.Context, topic string, values [][]byte) error {
+	if topic == "" {
+		return ErrEmptyTopic
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	q.mu.RLock()
+	var retained int
+	if l, ok := q.state.Topics[topic]; ok {
+		retained = len(l.Messages)
+	}
+	q.mu.RUnlock()
+	if retained+len(values) > q.cfg.RetainMessages {
+		return ErrTopicFull
+	}
+	return q.propose(ctx, &proposal{
+		Op:        opProduce,
+		Topic:     topic,
+		Timestamp: time.Now().UnixNano(),
+		Values:    values,
+	})
+}
+
+// Fetch returns at most count messages of topic from the next offset of group,
+// messages are read after the linearizable read index, so any member could serve.
+func (q *Queue) Fetch(ctx context.Context, topic, group string, count int) ([]Message, error) {
+	if topic == "" || group == "" {
+		return nil, ErrEmptyTopic
+	}
+	if count <= 0 {
+		count = defaultFetchCount
+	}
+	if err := q.raft.ReadIndex(ctx); err != nil {
+		return nil, err
+	}
+
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	l, ok := q.state.Topics[topic]
+	if !ok {
+		return nil, nil
+	}
+	offset, ok := q.state.Offsets[topic][group]
+	if !ok || offset < l.StartOffset {
+		offset = l.StartOffset
+	}
+	start := offset - l.StartOffset
+	end := start + int64(count)
+	if end > int64(len(l.Messages)) {
+		end = int64(len(l.Messages))
+	}
+	if start >= end {
+		return nil, nil
+	}
+	return append([]Message(nil), l.Messages[start:end]...), nil
+}
+
+// Commit commits the next offset to consume of group
+func (q *Queue) Commit(ctx context.Context, topic, group string, offset int64) error {
+	if topic == "" || group == "" {
+		return ErrEmptyTopic
+	}
+	return q.propose(ctx, &proposal{
+		Op:     opCommit,
+		Topic:  topic,
+		Group:  group,
+		Offset: offset,
+	})
+}
+
+// IsLeader returns true if the local member is leader
+func (q *Queue) IsLeader() bool {
+	return q.raft.IsLeader()
+}
+
+func (q *Queue) propose(ctx context.Context, p *proposal) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return q.raft.Propose(ctx, data)
+}
+
+func (q *Queue) checkpointLoop() {
+	ticker := time.NewTicker(time.Duration(q.cfg.CheckpointIntervalS) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			applied, err := q.checkpoint()
+			if err != nil {
+				log.Errorf("save raft mq checkpoint failed: err[%+v]", err)
+				continue
+			}
+			// wal before checkpoint is useless, lagging members catch up with snapshot
+			if err := q.raft.Truncate(applied); err != nil && err != raft.ErrCompacted {
+				log.Warnf("truncate raft mq wal failed: applied[%d], err[%+v]", applied, err)
+			}
+		case <-q.Done():
+			return
+		}
+	}
+}
+
+// checkpoint saves state into file and returns the applied index of it,
+// the chunks of state are written with length and crc32 ahead.
+func (q *Queue) checkpoint() (uint64, error) {
+	q.mu.RLock()
+	applied := q.state.Applied
+	it, err := q.state.iterator()
+	q.mu.RUnlock()
+	if err != nil {
+		return 0, err
+	}
+
+	file := path.Join(q.cfg.Dir, checkpointName)
+	tmpFile := file + ".tmp"
+	f, err := os.OpenFile(tmpFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	var head [8]byte
+	for {
+		chunk, err := it.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		binary.BigEndian.PutUint32(head[:], uint32(len(chunk)))
+		binary.BigEndian.PutUint32(head[4:], crc32.ChecksumIEEE(chunk))
+		if _, err = w.Write(head[:]); err != nil {
+			return 0, err
+		}
+		if _, err = w.Write(chunk); err != nil {
+			return 0, err
+		}
+	}
+	if err = w.Flush(); err != nil {
+		return 0, err
+	}
+	if err = f.Sync(); err != nil {
+		return 0, err
+	}
+	return applied, os.Rename(tmpFile, file)
+}
+
+func loadCheckpoint(file string) (*queueState, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return newQueueState(), nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	ld := &stateLoader{}
+	var head [8]byte
+	for {
+		if _, err = io.ReadFull(r, head[:]); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, errors.Info(err, "read raft mq checkpoint").Detail(err)
+		}
+		chunk := make([]byte, binary.BigEndian.Uint32(head[:]))
+		if _, err = io.ReadFull(r, chunk); err != nil {
+			return nil, errors.Info(err, "read raft mq checkpoint").Detail(err)
+		}
+		if crc32.ChecksumIEEE(chunk) != binary.BigEndian.Uint32(head[4:]) {
+			return nil, errors.New("raft mq checkpoint checksum mismatch")
+		}
+		if err = ld.load(chunk); err != nil {
+			return nil, errors.Info(err, "load raft mq checkpoint").Detail(err)
+		}
+	}
+	return ld.loaded()
+}
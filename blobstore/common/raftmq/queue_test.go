@@ -0,0 +1,251 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package raftmq
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/common/raftserver"
+	_ "github.com/cubefs/cubefs/blobstore/testing/nolog"
+)
+
+func freePort(t *testing.T) int {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func newConfigs(t *testing.T, dir string, n int) []Config {
+	members := make([]raftserver.Member, n)
+	ports := make([]int, n)
+	for i := range members {
+		ports[i] = freePort(t)
+		members[i] = raftserver.Member{NodeID: uint64(i + 1), Host: fmt.Sprintf("127.0.0.1:%d", ports[i])}
+	}
+	cfgs := make([]Config, n)
+	for i := range cfgs {
+		cfgs[i] = Config{
+			NodeID:         uint64(i + 1),
+			ListenPort:     ports[i],
+			Dir:            path.Join(dir, fmt.Sprintf("node%d", i+1)),
+			TickIntervalMs: 50,
+			ElectionTick:   3,
+			Members:        members,
+		}
+	}
+	return cfgs
+}
+
+func waitLeader(t *testing.T, queues ...*Queue) *Queue {
+	for i := 0; i < 200; i++ {
+		for _, q := range queues {
+			if q.IsLeader() {
+				return q
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	require.FailNow(t, "no leader elected")
+	return nil
+}
+
+func TestRaftMQConfig(t *testing.T) {
+	_, err := Open(Config{})
+	require.Error(t, err)
+	_, err = Open(Config{NodeID: 1, ListenPort: 1, Dir: os.TempDir()})
+	require.Error(t, err)
+}
+
+func TestRaftMQSingleNode(t *testing.T) {
+	dir, err := os.MkdirTemp(os.TempDir(), "raftmq")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	cfg := newConfigs(t, dir, 1)[0]
+	cfg.RetainMessages = 8
+	q, err := Open(cfg)
+	require.NoError(t, err)
+	waitLeader(t, q)
+
+	require.ErrorIs(t, q.Produce(ctx, "", [][]byte{[]byte("a")}), ErrEmptyTopic)
+	require.NoError(t, q.Produce(ctx, "topic", nil))
+	msgs, err := q.Fetch(ctx, "topic", "group", 10)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(msgs))
+
+	producer := NewProducer(q, 1000)
+	require.NoError(t, producer.SendMessages("topic", [][]byte{[]byte("0"), []byte("1"), []byte("2")}))
+	require.NoError(t, producer.SendMessage("topic", []byte("3")))
+
+	msgs, err = q.Fetch(ctx, "topic", "group", 2)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(msgs))
+	require.Equal(t, int64(0), msgs[0].Offset)
+	require.Equal(t, []byte("1"), msgs[1].Value)
+
+	require.NoError(t, q.Commit(ctx, "topic", "group", msgs[1].Offset+1))
+	// stale commit is ignored
+	require.NoError(t, q.Commit(ctx, "topic", "group", 1))
+	msgs, err = q.Fetch(ctx, "topic", "group", 0)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(msgs))
+	require.Equal(t, int64(2), msgs[0].Offset)
+
+	// a new group starts from the oldest retained message
+	msgs, err = q.Fetch(ctx, "topic", "other", 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), msgs[0].Offset)
+
+	// retain messages not consumed
+	for i := 4; i < 10; i++ {
+		require.NoError(t, producer.SendMessage("topic", []byte(fmt.Sprint(i))))
+	}
+	require.ErrorIs(t, producer.SendMessage("topic", []byte("10")), ErrTopicFull)
+	msgs, err = q.Fetch(ctx, "topic", "group", 0)
+	require.NoError(t, err)
+	require.Equal(t, 8, len(msgs))
+	require.Equal(t, int64(2), msgs[0].Offset)
+	require.NoError(t, q.Commit(ctx, "topic", "group", 6))
+	for i := 10; i < 14; i++ {
+		require.NoError(t, producer.SendMessage("topic", []byte(fmt.Sprint(i))))
+	}
+	require.ErrorIs(t, producer.SendMessage("topic", []byte("14")), ErrTopicFull)
+	require.NoError(t, q.Commit(ctx, "topic", "group", 12))
+	for i := 14; i < 20; i++ {
+		require.NoError(t, producer.SendMessage("topic", []byte(fmt.Sprint(i))))
+	}
+	msgs, err = q.Fetch(ctx, "topic", "group", 0)
+	require.NoError(t, err)
+	require.Equal(t, 8, len(msgs))
+	require.Equal(t, int64(12), msgs[0].Offset)
+
+	// restart with checkpoint and wal
+	_, err = q.checkpoint()
+	require.NoError(t, err)
+	require.NoError(t, q.Commit(ctx, "topic", "group", 15))
+	q.Close()
+
+	q, err = Open(cfg)
+	require.NoError(t, err)
+	defer q.Close()
+	waitLeader(t, q)
+	msgs, err = q.Fetch(ctx, "topic", "group", 0)
+	require.NoError(t, err)
+	require.Equal(t, 5, len(msgs))
+	require.Equal(t, int64(15), msgs[0].Offset)
+	require.Equal(t, []byte("19"), msgs[4].Value)
+}
+
+func TestRaftMQReplicas(t *testing.T) {
+	dir, err := os.MkdirTemp(os.TempDir(), "raftmq")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	cfgs := newConfigs(t, dir, 3)
+	queues := make([]*Queue, len(cfgs))
+	for i := range cfgs {
+		queues[i], err = Open(cfgs[i])
+		require.NoError(t, err)
+		defer queues[i].Close()
+	}
+	leader := waitLeader(t, queues...)
+	var follower *Queue
+	for _, q := range queues {
+		if q != leader {
+			follower = q
+			break
+		}
+	}
+
+	// produce into follower and fetch from leader
+	require.NoError(t, follower.Produce(ctx, "topic", [][]byte{[]byte("a"), []byte("b")}))
+	msgs, err := leader.Fetch(ctx, "topic", "group", 0)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(msgs))
+
+	// commit into leader and fetch from follower
+	require.NoError(t, leader.Commit(ctx, "topic", "group", 1))
+	msgs, err = follower.Fetch(ctx, "topic", "group", 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(msgs))
+	require.Equal(t, []byte("b"), msgs[0].Value)
+
+	// snapshot of state
+	st, err := leader.Snapshot()
+	require.NoError(t, err)
+	q := &Queue{state: newQueueState()}
+	require.NoError(t, q.ApplySnapshot(raftserver.SnapshotMeta{Name: st.Name(), Index: st.Index()}, st))
+	require.Equal(t, 1, len(q.state.Topics["topic"].Messages))
+	require.Equal(t, int64(1), q.state.Offsets["topic"]["group"])
+}
+
+func TestRaftMQStateChunks(t *testing.T) {
+	state := newQueueState()
+	state.Applied = 10
+	state.Offsets["topic"] = map[string]int64{"group": 3}
+	state.Topics["empty"] = &topicLog{StartOffset: 5}
+	l := &topicLog{StartOffset: 3}
+	state.Topics["topic"] = l
+	value := make([]byte, 1<<20)
+	for i := 0; i < 10; i++ {
+		l.Messages = append(l.Messages, Message{Offset: l.nextOffset(), Value: value, Timestamp: int64(i)})
+	}
+
+	it, err := state.iterator()
+	require.NoError(t, err)
+	// messages are changed after the iterator created
+	l.trim(5)
+	l.Messages = append(l.Messages, Message{Offset: l.nextOffset()})
+
+	ld := &stateLoader{}
+	chunks := 0
+	for {
+		chunk, err := it.next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(chunk), snapshotBatchSize)
+		require.NoError(t, ld.load(chunk))
+		chunks++
+	}
+	require.Equal(t, 5, chunks)
+	loaded, err := ld.loaded()
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), loaded.Applied)
+	require.Equal(t, state.Offsets, loaded.Offsets)
+	require.Equal(t, &topicLog{StartOffset: 5}, loaded.Topics["empty"])
+	require.Equal(t, int64(3), loaded.Topics["topic"].StartOffset)
+	require.Equal(t, 10, len(loaded.Topics["topic"].Messages))
+	require.Equal(t, int64(12), loaded.Topics["topic"].Messages[9].Offset)
+	require.Equal(t, int64(9), loaded.Topics["topic"].Messages[9].Timestamp)
+	require.Equal(t, value, loaded.Topics["topic"].Messages[9].Value)
+
+	_, err = (&stateLoader{}).loaded()
+	require.Error(t, err)
+	require.Error(t, ld.load([]byte{0, 5, 'o', 't', 'h'}))
+}
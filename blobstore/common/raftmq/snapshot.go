@@ -0,0 +1,198 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package raftmq
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+/*
+	the state of queue is encoded into chunks, checkpoint and snapshot share the same chunks.
+
+	header chunk: json of stateHeader
+	batch chunk:  | topic len (2) | topic | first offset (8) | count (4) | messages |
+	message:      | timestamp (8) | value len (4) | value |
+*/
+
+const snapshotBatchSize = 4 << 20
+
+type stateHeader struct {
+	Applied uint64                      `json:"applied"`
+	Offsets map[string]map[string]int64 `json:"offsets"`
+	// Topics is the start offset of topics
+	Topics map[string]int64 `json:"topics"`
+}
+
+// stateIterator iterates the chunks of state, it is created with the copy of
+// topic logs, messages of the copy are never changed by applying.
+type stateIterator struct {
+	header []byte
+	topics []string
+	logs   []topicLog
+
+	topicIdx int
+	msgIdx   int
+}
+
+// iterator returns the iterator of state, it must be called with the lock held.
+func (s *queueState) iterator() (*stateIterator, error) {
+	header := stateHeader{
+		Applied: s.Applied,
+		Offsets: make(map[string]map[string]int64, len(s.Offsets)),
+		Topics:  make(map[string]int64, len(s.Topics)),
+	}
+	for topic, groups := range s.Offsets {
+		copied := make(map[string]int64, len(groups))
+		for group, offset := range groups {
+			copied[group] = offset
+		}
+		header.Offsets[topic] = copied
+	}
+
+	it := &stateIterator{}
+	for topic, l := range s.Topics {
+		header.Topics[topic] = l.StartOffset
+		it.topics = append(it.topics, topic)
+	}
+	sort.Strings(it.topics)
+	for _, topic := range it.topics {
+		it.logs = append(it.logs, *s.Topics[topic])
+	}
+
+	var err error
+	if it.header, err = json.Marshal(header); err != nil {
+		return nil, err
+	}
+	return it, nil
+}
+
+// next returns the next chunk of state, io.EOF is returned at the end.
+func (it *stateIterator) next() ([]byte, error) {
+	if it.header != nil {
+		header := it.header
+		it.header = nil
+		return header, nil
+	}
+
+	for it.topicIdx < len(it.topics) {
+		l := &it.logs[it.topicIdx]
+		if it.msgIdx >= len(l.Messages) {
+			it.topicIdx++
+			it.msgIdx = 0
+			continue
+		}
+
+		topic := it.topics[it.topicIdx]
+		chunk := make([]byte, 2+len(topic)+8+4, 64<<10)
+		binary.BigEndian.PutUint16(chunk, uint16(len(topic)))
+		copy(chunk[2:], topic)
+		binary.BigEndian.PutUint64(chunk[2+len(topic):], uint64(l.Messages[it.msgIdx].Offset))
+		count := 0
+		for ; it.msgIdx < len(l.Messages); it.msgIdx++ {
+			msg := l.Messages[it.msgIdx]
+			if count > 0 && len(chunk)+12+len(msg.Value) > snapshotBatchSize {
+				break
+			}
+			var head [12]byte
+			binary.BigEndian.PutUint64(head[:], uint64(msg.Timestamp))
+			binary.BigEndian.PutUint32(head[8:], uint32(len(msg.Value)))
+			chunk = append(append(chunk, head[:]...), msg.Value...)
+			count++
+		}
+		binary.BigEndian.PutUint32(chunk[2+len(topic)+8:], uint32(count))
+		return chunk, nil
+	}
+	return nil, io.EOF
+}
+
+// stateLoader rebuilds the state from chunks, the header chunk is the first one.
+type stateLoader struct {
+	state *queueState
+}
+
+func (ld *stateLoader) load(chunk []byte) error {
+	if ld.state == nil {
+		header := stateHeader{}
+		if err := json.Unmarshal(chunk, &header); err != nil {
+			return err
+		}
+		ld.state = newQueueState()
+		ld.state.Applied = header.Applied
+		for topic, groups := range header.Offsets {
+			ld.state.Offsets[topic] = groups
+		}
+		for topic, start := range header.Topics {
+			ld.state.Topics[topic] = &topicLog{StartOffset: start}
+		}
+		return nil
+	}
+
+	errInvalid := fmt.Errorf("invalid raft mq state chunk")
+	if len(chunk) < 2 {
+		return errInvalid
+	}
+	n := int(binary.BigEndian.Uint16(chunk))
+	if len(chunk) < 2+n+12 {
+		return errInvalid
+	}
+	topic := string(chunk[2 : 2+n])
+	offset := int64(binary.BigEndian.Uint64(chunk[2+n:]))
+	count := int(binary.BigEndian.Uint32(chunk[2+n+8:]))
+	chunk = chunk[2+n+12:]
+
+	l, ok := ld.state.Topics[topic]
+	if !ok || l.nextOffset() != offset {
+		return fmt.Errorf("unexpected raft mq state chunk: topic[%s], offset[%d]", topic, offset)
+	}
+	for i := 0; i < count; i++ {
+		if len(chunk) < 12 {
+			return errInvalid
+		}
+		timestamp := int64(binary.BigEndian.Uint64(chunk))
+		size := int(binary.BigEndian.Uint32(chunk[8:]))
+		if len(chunk) < 12+size {
+			return errInvalid
+		}
+		l.Messages = append(l.Messages, Message{
+			Offset:    l.nextOffset(),
+			Value:     chunk[12 : 12+size],
+			Timestamp: timestamp,
+		})
+		chunk = chunk[12+size:]
+	}
+	return nil
+}
+
+func (ld *stateLoader) loaded() (*queueState, error) {
+	if ld.state == nil {
+		return nil, fmt.Errorf("no header of raft mq state")
+	}
+	return ld.state, nil
+}
+
+type snapshot struct {
+	name  string
+	index uint64
+	it    *stateIterator
+}
+
+func (s *snapshot) Read() ([]byte, error) { return s.it.next() }
+func (s *snapshot) Name() string          { return s.name }
+func (s *snapshot) Index() uint64         { return s.index }
+func (s *snapshot) Close()                {}
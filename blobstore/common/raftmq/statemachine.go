@@ -0,0 +1,161 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package raftmq
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/cubefs/cubefs/blobstore/common/raftserver"
+	"github.com/cubefs/cubefs/blobstore/util/log"
+)
+
+/*
+	implements raftserver StateMachine
+*/
+
+type opType uint8
+
+const (
+	opProduce opType = iota + 1
+	opCommit
+)
+
+type proposal struct {
+	Op        opType   `json:"op"`
+	Topic     string   `json:"topic"`
+	Group     string   `json:"group,omitempty"`
+	Offset    int64    `json:"offset,omitempty"`
+	Timestamp int64    `json:"timestamp,omitempty"`
+	Values    [][]byte `json:"values,omitempty"`
+}
+
+// Apply applies produced messages and committed offsets
+func (q *Queue) Apply(data [][]byte, index uint64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i := range data {
+		p := &proposal{}
+		if err := json.Unmarshal(data[i], p); err != nil {
+			return fmt.Errorf("unmarshal raft mq proposal: %w", err)
+		}
+		switch p.Op {
+		case opProduce:
+			q.applyProduce(p)
+		case opCommit:
+			q.applyCommit(p)
+		default:
+			return fmt.Errorf("unknown raft mq proposal op: %d", p.Op)
+		}
+	}
+	q.state.Applied = index
+	return nil
+}
+
+func (q *Queue) applyProduce(p *proposal) {
+	l, ok := q.state.Topics[p.Topic]
+	if !ok {
+		l = &topicLog{}
+		q.state.Topics[p.Topic] = l
+	}
+	for _, value := range p.Values {
+		l.Messages = append(l.Messages, Message{
+			Offset:    l.nextOffset(),
+			Value:     value,
+			Timestamp: p.Timestamp,
+		})
+	}
+}
+
+func (q *Queue) applyCommit(p *proposal) {
+	groups, ok := q.state.Offsets[p.Topic]
+	if !ok {
+		groups = make(map[string]int64)
+		q.state.Offsets[p.Topic] = groups
+	}
+	if p.Offset <= groups[p.Group] {
+		return
+	}
+	groups[p.Group] = p.Offset
+
+	// messages consumed by all groups are useless, the others are never dropped
+	l, ok := q.state.Topics[p.Topic]
+	if !ok {
+		return
+	}
+	minOffset := p.Offset
+	for _, offset := range groups {
+		if offset < minOffset {
+			minOffset = offset
+		}
+	}
+	l.trim(minOffset)
+}
+
+// ApplyMemberChange members are persisted by raft storage
+func (q *Queue) ApplyMemberChange(cc raftserver.ConfChange, index uint64) error {
+	log.Infof("raft mq member change: type[%s], node[%d], index[%d]", cc.Type, cc.NodeID, index)
+	return nil
+}
+
+// Snapshot returns the whole state of queue, the messages are read in batches
+func (q *Queue) Snapshot() (raftserver.Snapshot, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	it, err := q.state.iterator()
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot{
+		name:  fmt.Sprintf("raftmq-%d", q.state.Applied),
+		index: q.state.Applied,
+		it:    it,
+	}, nil
+}
+
+// ApplySnapshot replaces state of queue with snapshot
+func (q *Queue) ApplySnapshot(meta raftserver.SnapshotMeta, st raftserver.Snapshot) error {
+	ld := &stateLoader{}
+	for {
+		chunk, err := st.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err = ld.load(chunk); err != nil {
+			return err
+		}
+	}
+
+	state, err := ld.loaded()
+	if err != nil {
+		return err
+	}
+	state.Applied = meta.Index
+
+	q.mu.Lock()
+	q.state = state
+	q.mu.Unlock()
+	log.Infof("raft mq apply snapshot: name[%s], index[%d]", meta.Name, meta.Index)
+	return nil
+}
+
+// LeaderChange notifies leader changed
+func (q *Queue) LeaderChange(leader uint64, host string) {
+	log.Infof("raft mq leader change: leader[%d], host[%s]", leader, host)
+}
@@ -15,12 +15,17 @@
 package proxy
 
 import (
+	"net/http"
+
 	api "github.com/cubefs/cubefs/blobstore/api/proxy"
 	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
 	"github.com/cubefs/cubefs/blobstore/common/rpc"
 	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/util/errors"
 )
 
+var errRaftMQDisabled = rpc.NewError(http.StatusNotImplemented, "RaftMQDisabled", errors.New("raft mq is disabled"))
+
 // SendRepairMessage send repair message to kafka
 // 1. message from access
 // 2. message from scheduler
@@ -79,3 +84,65 @@ func (s *Service) SendDeleteMessage(c *rpc.Context) {
 
 	c.Respond()
 }
+
+// ProduceMessage appends messages into topic of raft mq
+func (s *Service) ProduceMessage(c *rpc.Context) {
+	args := new(api.ProduceMsgArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	if s.raftQueue == nil {
+		c.RespondError(errRaftMQDisabled)
+		return
+	}
+
+	if err := s.raftQueue.Produce(c.Request.Context(), args.Topic, args.Msgs); err != nil {
+		c.RespondError(err)
+		return
+	}
+	c.Respond()
+}
+
+// FetchMessage fetches messages of consumer group from raft mq
+func (s *Service) FetchMessage(c *rpc.Context) {
+	args := new(api.FetchMsgArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	if s.raftQueue == nil {
+		c.RespondError(errRaftMQDisabled)
+		return
+	}
+
+	msgs, err := s.raftQueue.Fetch(c.Request.Context(), args.Topic, args.Group, args.Count)
+	if err != nil {
+		c.RespondError(err)
+		return
+	}
+	ret := api.FetchMsgRet{Msgs: make([]api.Message, 0, len(msgs))}
+	for _, msg := range msgs {
+		ret.Msgs = append(ret.Msgs, api.Message{Offset: msg.Offset, Value: msg.Value, Timestamp: msg.Timestamp})
+	}
+	c.RespondJSON(ret)
+}
+
+// CommitMessage commits consumed offset of consumer group into raft mq
+func (s *Service) CommitMessage(c *rpc.Context) {
+	args := new(api.CommitMsgArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	if s.raftQueue == nil {
+		c.RespondError(errRaftMQDisabled)
+		return
+	}
+
+	if err := s.raftQueue.Commit(c.Request.Context(), args.Topic, args.Group, args.Offset); err != nil {
+		c.RespondError(err)
+		return
+	}
+	c.Respond()
+}
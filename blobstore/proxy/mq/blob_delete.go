@@ -54,11 +54,15 @@ func NewBlobDeleteMgr(cfg BlobDeleteConfig) (*blobDeleteMgr, error) {
 	if err != nil {
 		return nil, err
 	}
+	return NewBlobDeleteMgrWithProducer(cfg, delMsgSender), nil
+}
 
+// NewBlobDeleteMgrWithProducer returns blob delete manager which sends message with producer
+func NewBlobDeleteMgrWithProducer(cfg BlobDeleteConfig, producer Producer) *blobDeleteMgr {
 	return &blobDeleteMgr{
 		topic:        cfg.Topic,
-		delMsgSender: delMsgSender,
-	}, nil
+		delMsgSender: producer,
+	}
 }
 
 // SendDeleteMsg sends delete message to kafka
@@ -44,13 +44,17 @@ func NewShardRepairMgr(cfg ShardRepairConfig) (*shardRepairMgr, error) {
 	if err != nil {
 		return nil, err
 	}
+	return NewShardRepairMgrWithProducer(cfg, shardRepairMsgSender), nil
+}
 
+// NewShardRepairMgrWithProducer returns shard repair manager which sends message with producer
+func NewShardRepairMgrWithProducer(cfg ShardRepairConfig, producer Producer) *shardRepairMgr {
 	return &shardRepairMgr{
 		topic:                cfg.Topic,
 		priorityTopic:        cfg.PriorityTopic,
 		topicSelector:        defaultTopicSelector,
-		shardRepairMsgSender: shardRepairMsgSender,
-	}, nil
+		shardRepairMsgSender: producer,
+	}
 }
 
 // shardRepairMgr is shard repair manager
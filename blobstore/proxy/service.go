@@ -26,6 +26,7 @@ import (
 	"github.com/cubefs/cubefs/blobstore/common/config"
 	"github.com/cubefs/cubefs/blobstore/common/kafka"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/raftmq"
 	"github.com/cubefs/cubefs/blobstore/common/rpc"
 	alloc "github.com/cubefs/cubefs/blobstore/proxy/allocator"
 	"github.com/cubefs/cubefs/blobstore/proxy/cacher"
@@ -41,6 +42,11 @@ const (
 	defaultHeartbeatTicks     = uint32(30)
 	defaultExpiresTicks       = uint32(60)
 	defaultTimeoutMS          = int64(1000)
+
+	// MQTypeKafka sends messages to kafka
+	MQTypeKafka = "kafka"
+	// MQTypeRaft sends messages to raft mq embedded in proxies
+	MQTypeRaft = "raft"
 )
 
 var (
@@ -50,6 +56,8 @@ var (
 	// ErrIllegalTopic illegal topic
 	ErrIllegalTopic = errors.New("illegal topic")
 	ErrIllegalKafka = errors.New("illegal kafka version")
+	// ErrIllegalMQType illegal mq type
+	ErrIllegalMQType = errors.New("illegal mq type")
 )
 
 // MQConfig is mq config
@@ -59,6 +67,10 @@ type MQConfig struct {
 	ShardRepairPriorityTopic string            `json:"shard_repair_priority_topic"`
 	MsgSender                kafka.ProducerCfg `json:"msg_sender"`
 	Version                  string            `json:"version"`
+
+	// Type is kafka or raft, defaults to kafka
+	Type string        `json:"type"`
+	Raft raftmq.Config `json:"raft"`
 }
 
 type Config struct {
@@ -96,6 +108,7 @@ type Service struct {
 	// mq
 	shardRepairMgr mq.ShardRepairHandler
 	blobDeleteMgr  mq.BlobDeleteHandler
+	raftQueue      *raftmq.Queue // nil if mq type is kafka
 	// allocator
 	volumeMgr alloc.VolumeMgr
 	// cacher
@@ -128,6 +141,9 @@ func setUp() (*rpc.Router, []rpc.ProgressHandler) {
 
 func tearDown() {
 	service.volumeMgr.Close()
+	if service.raftQueue != nil {
+		service.raftQueue.Close()
+	}
 }
 
 func New(cfg Config, cmcli clustermgr.APIProxy) *Service {
@@ -136,13 +152,29 @@ func New(cfg Config, cmcli clustermgr.APIProxy) *Service {
 	}
 
 	// mq
-	blobDeleteMgr, err := mq.NewBlobDeleteMgr(cfg.blobDeleteCfg())
-	if err != nil {
-		log.Fatalf("fail to new blobDeleteMgr, error: %s", err.Error())
-	}
-	shardRepairMgr, err := mq.NewShardRepairMgr(cfg.shardRepairCfg())
-	if err != nil {
-		log.Fatalf("fail to new shardRepairMgr, error: %s", err.Error())
+	var (
+		blobDeleteMgr  mq.BlobDeleteHandler
+		shardRepairMgr mq.ShardRepairHandler
+		raftQueue      *raftmq.Queue
+		err            error
+	)
+	if cfg.MQ.Type == MQTypeRaft {
+		raftQueue, err = raftmq.Open(cfg.MQ.Raft)
+		if err != nil {
+			log.Fatalf("fail to open raft mq, error: %s", err.Error())
+		}
+		producer := raftmq.NewProducer(raftQueue, cfg.MQ.MsgSender.TimeoutMs)
+		blobDeleteMgr = mq.NewBlobDeleteMgrWithProducer(cfg.blobDeleteCfg(), producer)
+		shardRepairMgr = mq.NewShardRepairMgrWithProducer(cfg.shardRepairCfg(), producer)
+	} else {
+		blobDeleteMgr, err = mq.NewBlobDeleteMgr(cfg.blobDeleteCfg())
+		if err != nil {
+			log.Fatalf("fail to new blobDeleteMgr, error: %s", err.Error())
+		}
+		shardRepairMgr, err = mq.NewShardRepairMgr(cfg.shardRepairCfg())
+		if err != nil {
+			log.Fatalf("fail to new shardRepairMgr, error: %s", err.Error())
+		}
 	}
 
 	// allocator
@@ -174,6 +206,7 @@ func New(cfg Config, cmcli clustermgr.APIProxy) *Service {
 		cacher:         cacher,
 		shardRepairMgr: shardRepairMgr,
 		blobDeleteMgr:  blobDeleteMgr,
+		raftQueue:      raftQueue,
 	}
 }
 
@@ -204,6 +237,17 @@ func NewHandler(service *Service) *rpc.Router {
 	// request body: json
 	router.Handle(http.MethodPost, "/deletemsg", service.SendDeleteMessage, rpc.OptArgsBody())
 
+	// POST /mq/produce
+	// request body: json
+	router.Handle(http.MethodPost, "/mq/produce", service.ProduceMessage, rpc.OptArgsBody())
+	// POST /mq/fetch
+	// request  body: json
+	// response body: json
+	router.Handle(http.MethodPost, "/mq/fetch", service.FetchMessage, rpc.OptArgsBody())
+	// POST /mq/commit
+	// request body: json
+	router.Handle(http.MethodPost, "/mq/commit", service.CommitMessage, rpc.OptArgsBody())
+
 	// GET /cache/volume/{vid}?flush={flush}&version={version}
 	// response body: json
 	router.Handle(http.MethodGet, "/cache/volume/:vid", service.GetCacheVolume, rpc.OptArgsURI(), rpc.OptArgsQuery())
@@ -229,6 +273,10 @@ func (c *Config) checkAndFix() (err error) {
 	defaulter.Equal(&c.ExpiresTicks, defaultExpiresTicks)
 	defaulter.LessOrEqual(&c.Clustermgr.Config.ClientTimeoutMs, defaultTimeoutMS)
 	defaulter.LessOrEqual(&c.MQ.MsgSender.TimeoutMs, defaultTimeoutMS)
+	defaulter.Empty(&c.MQ.Type, MQTypeKafka)
+	if c.MQ.Type != MQTypeKafka && c.MQ.Type != MQTypeRaft {
+		return ErrIllegalMQType
+	}
 	if c.MQ.Version != "" {
 		kafkaVersion, err := sarama.ParseKafkaVersion(c.MQ.Version)
 		if err != nil {
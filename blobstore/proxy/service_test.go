@@ -16,9 +16,14 @@ package proxy
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net"
 	"net/http/httptest"
+	"os"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/Shopify/sarama"
 	"github.com/golang/mock/gomock"
@@ -31,9 +36,12 @@ import (
 	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
 	"github.com/cubefs/cubefs/blobstore/common/kafka"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/raftmq"
+	"github.com/cubefs/cubefs/blobstore/common/raftserver"
 	"github.com/cubefs/cubefs/blobstore/common/rpc"
 	"github.com/cubefs/cubefs/blobstore/proxy/allocator"
 	"github.com/cubefs/cubefs/blobstore/proxy/mock"
+	"github.com/cubefs/cubefs/blobstore/proxy/mq"
 	_ "github.com/cubefs/cubefs/blobstore/testing/nolog"
 	"github.com/cubefs/cubefs/blobstore/util/errors"
 )
@@ -260,6 +268,66 @@ func TestService_MQ(t *testing.T) {
 	}
 }
 
+func TestService_RaftMQ(t *testing.T) {
+	runMockService(newMockService(t))
+	cli := proxy.New(&proxy.Config{})
+
+	// raft mq is disabled
+	err := cli.ProduceMsg(ctx, proxyServer.URL, &proxy.ProduceMsgArgs{Topic: "topic"})
+	require.Equal(t, 501, rpc.DetectStatusCode(err))
+
+	dir, err := os.MkdirTemp(os.TempDir(), "proxy_raftmq")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+
+	queue, err := raftmq.Open(raftmq.Config{
+		NodeID:         1,
+		ListenPort:     port,
+		Dir:            dir,
+		TickIntervalMs: 50,
+		ElectionTick:   3,
+		Members:        []raftserver.Member{{NodeID: 1, Host: fmt.Sprintf("127.0.0.1:%d", port)}},
+	})
+	require.NoError(t, err)
+	defer queue.Close()
+	for !queue.IsLeader() {
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	shardRepairMgr := mq.NewShardRepairMgrWithProducer(mq.ShardRepairConfig{Topic: "repair", PriorityTopic: "priority"},
+		raftmq.NewProducer(queue, 1000))
+	server := httptest.NewServer(NewHandler(&Service{
+		Config:         Config{VolConfig: allocator.VolConfig{ClusterID: 1}},
+		shardRepairMgr: shardRepairMgr,
+		raftQueue:      queue,
+	}))
+	defer server.Close()
+
+	err = cli.SendShardRepairMsg(ctx, server.URL, &proxy.ShardRepairArgs{ClusterID: 1, Bid: 1, Vid: 1, BadIdxes: []uint8{1}})
+	require.NoError(t, err)
+	require.NoError(t, cli.ProduceMsg(ctx, server.URL, &proxy.ProduceMsgArgs{Topic: "repair", Msgs: [][]byte{[]byte("a")}}))
+	require.Error(t, cli.ProduceMsg(ctx, server.URL, &proxy.ProduceMsgArgs{Msgs: [][]byte{[]byte("a")}}))
+
+	ret, err := cli.FetchMsg(ctx, server.URL, &proxy.FetchMsgArgs{Topic: "repair", Group: "group", Count: 10})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(ret.Msgs))
+	msg := &proto.ShardRepairMsg{}
+	require.NoError(t, json.Unmarshal(ret.Msgs[0].Value, msg))
+	require.Equal(t, proto.Vid(1), msg.Vid)
+	require.Equal(t, []byte("a"), ret.Msgs[1].Value)
+
+	err = cli.CommitMsg(ctx, server.URL, &proxy.CommitMsgArgs{Topic: "repair", Group: "group", Offset: ret.Msgs[0].Offset + 1})
+	require.NoError(t, err)
+	ret, err = cli.FetchMsg(ctx, server.URL, &proxy.FetchMsgArgs{Topic: "repair", Group: "group", Count: 10})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(ret.Msgs))
+	require.Equal(t, []byte("a"), ret.Msgs[0].Value)
+}
+
 func TestService_Allocator(t *testing.T) {
 	url := runMockService(newMockService(t))
 	cli := newClient()
@@ -418,6 +486,8 @@ func TestConfigFix(t *testing.T) {
 		{cfg: &Config{MQ: MQConfig{BlobDeleteTopic: "test", ShardRepairTopic: "test", ShardRepairPriorityTopic: "test3"}}, err: ErrIllegalTopic},
 		{cfg: &Config{MQ: MQConfig{BlobDeleteTopic: "test", ShardRepairTopic: "test1", ShardRepairPriorityTopic: "test"}}, err: ErrIllegalTopic},
 		{cfg: &Config{MQ: MQConfig{BlobDeleteTopic: "test", ShardRepairTopic: "test1", ShardRepairPriorityTopic: "test3"}}, err: nil},
+		{cfg: &Config{MQ: MQConfig{BlobDeleteTopic: "test", ShardRepairTopic: "test1", ShardRepairPriorityTopic: "test3", Type: "x"}}, err: ErrIllegalMQType},
+		{cfg: &Config{MQ: MQConfig{BlobDeleteTopic: "test", ShardRepairTopic: "test1", ShardRepairPriorityTopic: "test3", Type: MQTypeRaft}}, err: nil},
 	}
 
 	for _, tc := range testCases {
@@ -36,6 +36,20 @@ func NewMsgSender(cfg *kafka.ProducerCfg) (IProducer, error) {
 	return &msgSender{topic: cfg.Topic, producer: producer}, nil
 }
 
+// MsgSenderMaker makes message sender of the mq which consumer consumes from
+type MsgSenderMaker interface {
+	NewMsgSender(cfg *kafka.ProducerCfg) (IProducer, error)
+}
+
+// NewMsgSenderOf returns message sender of the same mq with consumer,
+// it sends messages to kafka if the consumer is not a MsgSenderMaker.
+func NewMsgSenderOf(consumer KafkaConsumer, cfg *kafka.ProducerCfg) (IProducer, error) {
+	if maker, ok := consumer.(MsgSenderMaker); ok {
+		return maker.NewMsgSender(cfg)
+	}
+	return NewMsgSender(cfg)
+}
+
 // SendMessage send message to mq
 func (sender *msgSender) SendMessage(msg []byte) error {
 	return sender.producer.SendMessage(sender.topic, msg)
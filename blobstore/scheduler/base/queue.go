@@ -71,12 +71,19 @@ func NewQueue(msgTimeout time.Duration) *Queue {
 type msgEx struct {
 	id       string
 	state    int
+	priority int
 	deadline time.Time
 	msg      interface{}
 }
 
 // Push push message to queue id is uniquely identifies。
 func (q *Queue) Push(id string, msg interface{}) error {
+	return q.PushWithPriority(id, msg, 0)
+}
+
+// PushWithPriority push message with priority, message with higher
+// priority is popped first, and fifo with the same priority.
+func (q *Queue) PushWithPriority(id string, msg interface{}, priority int) error {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
@@ -85,11 +92,21 @@ func (q *Queue) Push(id string, msg interface{}) error {
 	}
 
 	m := &msgEx{
-		id:    id,
-		state: msgStateTodo,
-		msg:   msg,
+		id:       id,
+		state:    msgStateTodo,
+		priority: priority,
+		msg:      msg,
+	}
+	var elem *list.Element
+	for ele := q.todo.Back(); ele != nil; ele = ele.Prev() {
+		if ele.Value.(*msgEx).priority >= priority {
+			elem = q.todo.InsertAfter(m, ele)
+			break
+		}
+	}
+	if elem == nil {
+		elem = q.todo.PushFront(m)
 	}
-	elem := q.todo.PushBack(m)
 	q.msgs[id] = elem
 
 	return nil
@@ -101,15 +118,19 @@ func (q *Queue) Pop() (string, interface{}, bool) {
 	defer q.mu.Unlock()
 
 	now := time.Now()
+	var timeout *msgEx
 	for ele := q.doing.Front(); ele != nil; ele = ele.Next() {
 		m := ele.Value.(*msgEx)
-		if m.deadline.Before(now) {
-			m.deadline = now.Add(q.msgTimeout)
-			return m.id, m.msg, true
+		if m.deadline.Before(now) && (timeout == nil || m.priority > timeout.priority) {
+			timeout = m
 		}
 	}
+	// timeout msg in doing first, unless msg in todo has higher priority
+	if timeout != nil && (q.todo.Len() == 0 || timeout.priority >= q.todo.Front().Value.(*msgEx).priority) {
+		timeout.deadline = now.Add(q.msgTimeout)
+		return timeout.id, timeout.msg, true
+	}
 
-	// no timeout msg in doing ,fetch from todo
 	if q.todo.Len() == 0 {
 		return "", nil, false
 	}
@@ -216,9 +237,14 @@ func NewTaskQueue(retryDelay time.Duration) *TaskQueue {
 
 // PushTask push task to queue
 func (q *TaskQueue) PushTask(taskID string, task WorkerTask) {
+	q.PushTaskWithPriority(taskID, task, 0)
+}
+
+// PushTaskWithPriority push task to queue with priority
+func (q *TaskQueue) PushTaskWithPriority(taskID string, task WorkerTask, priority int) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
-	err := q.queue.Push(taskID, task)
+	err := q.queue.PushWithPriority(taskID, task, priority)
 	if err != nil {
 		panic("unexpect push task fail " + err.Error())
 	}
@@ -294,6 +320,11 @@ func NewWorkerTaskQueue(cancelPunishDuration time.Duration) *WorkerTaskQueue {
 
 // AddPreparedTask add prepared task
 func (q *WorkerTaskQueue) AddPreparedTask(idc, taskID string, wtask WorkerTask) {
+	q.AddPreparedTaskWithPriority(idc, taskID, wtask, 0)
+}
+
+// AddPreparedTaskWithPriority add prepared task with priority
+func (q *WorkerTaskQueue) AddPreparedTaskWithPriority(idc, taskID string, wtask WorkerTask, priority int) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
@@ -302,7 +333,7 @@ func (q *WorkerTaskQueue) AddPreparedTask(idc, taskID string, wtask WorkerTask)
 		idcQueue = NewQueue(q.leaseExpiredS)
 		q.idcQueues[idc] = idcQueue
 	}
-	err := idcQueue.Push(taskID, wtask)
+	err := idcQueue.PushWithPriority(taskID, wtask, priority)
 	if err != nil {
 		panic("unexpect add prepared task fail:" + err.Error())
 	}
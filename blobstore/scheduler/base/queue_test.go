@@ -91,6 +91,33 @@ func TestQueue(t *testing.T) {
 	require.EqualError(t, err, ErrNoSuchMessageID.Error())
 }
 
+func TestQueuePriority(t *testing.T) {
+	q := NewQueue(0)
+	require.NoError(t, q.Push("msg_1", 1))
+	require.NoError(t, q.PushWithPriority("msg_2", 2, -1))
+	require.NoError(t, q.PushWithPriority("msg_3", 3, 1))
+	require.NoError(t, q.PushWithPriority("msg_4", 4, 1))
+	require.NoError(t, q.PushWithPriority("msg_5", 5, -2))
+
+	for _, expected := range []string{"msg_3", "msg_4", "msg_1", "msg_2", "msg_5"} {
+		id, _, exist := q.Pop()
+		require.True(t, exist)
+		require.Equal(t, expected, id)
+	}
+	_, _, exist := q.Pop()
+	require.False(t, exist)
+
+	// requeued msg with lower priority is popped after msg with higher priority
+	require.NoError(t, q.Requeue("msg_2", 0))
+	require.NoError(t, q.Requeue("msg_1", 0))
+	require.NoError(t, q.PushWithPriority("msg_6", 6, 0))
+	for _, expected := range []string{"msg_1", "msg_6", "msg_2"} {
+		id, _, exist := q.Pop()
+		require.True(t, exist)
+		require.Equal(t, expected, id)
+	}
+}
+
 type mockWorkerTask struct {
 	src []proto.VunitLocation
 	dst proto.VunitLocation
@@ -0,0 +1,157 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package base
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Shopify/sarama"
+
+	"github.com/cubefs/cubefs/blobstore/api/proxy"
+	"github.com/cubefs/cubefs/blobstore/common/kafka"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/util/closer"
+)
+
+type raftMQClient struct {
+	cli proxy.LbMQClient
+}
+
+// NewRaftMQConsumer returns consumer of raft mq embedded in proxies,
+// it also makes message sender of failed topics.
+func NewRaftMQConsumer(cli proxy.LbMQClient) KafkaConsumer {
+	return &raftMQClient{cli: cli}
+}
+
+func (c *raftMQClient) StartKafkaConsumer(cfg KafkaConsumerCfg, fn func(msg []*sarama.ConsumerMessage,
+	consumerPause ConsumerPause) bool,
+) (GroupConsumer, error) {
+	group := fmt.Sprintf("%s-%s", proto.ServiceNameScheduler, cfg.Topic)
+	consumer := &raftMQConsumer{
+		group:     group,
+		cfg:       cfg,
+		cli:       c.cli,
+		consumeFn: fn,
+		Closer:    closer.New(),
+	}
+	go consumer.run()
+	trace.SpanFromContextSafe(context.Background()).Infof("start raft mq consumer: group[%s]", group)
+	return consumer, nil
+}
+
+// NewMsgSender returns message sender of raft mq
+func (c *raftMQClient) NewMsgSender(cfg *kafka.ProducerCfg) (IProducer, error) {
+	return &raftMsgSender{
+		topic:   cfg.Topic,
+		timeout: time.Duration(cfg.TimeoutMs) * time.Millisecond,
+		cli:     c.cli,
+	}, nil
+}
+
+type raftMQConsumer struct {
+	group     string
+	cfg       KafkaConsumerCfg
+	cli       proxy.LbMQClient
+	consumeFn func(msg []*sarama.ConsumerMessage, consumerPause ConsumerPause) bool
+	closer.Closer
+}
+
+// Stop stops consuming, the consuming batch is paused
+func (c *raftMQConsumer) Stop() {
+	c.Close()
+}
+
+func (c *raftMQConsumer) run() {
+	span, ctx := trace.StartSpanFromContext(context.Background(), c.group)
+	waitTime := time.Duration(c.cfg.MaxWaitTimeS) * time.Second
+	for {
+		select {
+		case <-c.Done():
+			return
+		default:
+		}
+
+		ret, err := c.cli.FetchMsg(ctx, &proxy.FetchMsgArgs{Topic: c.cfg.Topic, Group: c.group, Count: c.cfg.MaxBatchSize})
+		if err != nil {
+			span.Errorf("fetch raft mq message failed and try again: topic[%s], err[%+v]", c.cfg.Topic, err)
+			c.wait(waitTime)
+			continue
+		}
+		if len(ret.Msgs) == 0 {
+			c.wait(waitTime)
+			continue
+		}
+
+		msgs := make([]*sarama.ConsumerMessage, 0, len(ret.Msgs))
+		for _, msg := range ret.Msgs {
+			msgs = append(msgs, &sarama.ConsumerMessage{
+				Topic:     c.cfg.Topic,
+				Offset:    msg.Offset,
+				Value:     msg.Value,
+				Timestamp: time.Unix(0, msg.Timestamp),
+			})
+		}
+		lastMsg := msgs[len(msgs)-1]
+		// messages not consumed are fetched again from committed offset
+		if success := c.consumeFn(msgs, c); !success {
+			span.Warnf("message not consume: topic[%s], offset[%d]", lastMsg.Topic, lastMsg.Offset)
+			c.wait(waitTime)
+			continue
+		}
+		err = c.cli.CommitMsg(ctx, &proxy.CommitMsgArgs{Topic: c.cfg.Topic, Group: c.group, Offset: lastMsg.Offset + 1})
+		if err != nil {
+			span.Errorf("commit raft mq offset failed: topic[%s], offset[%d], err[%+v]", lastMsg.Topic, lastMsg.Offset, err)
+		}
+
+		// wait for a full batch
+		if len(msgs) < c.cfg.MaxBatchSize {
+			c.wait(waitTime)
+		}
+	}
+}
+
+func (c *raftMQConsumer) wait(d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-c.Done():
+	}
+}
+
+type raftMsgSender struct {
+	topic   string
+	timeout time.Duration
+	cli     proxy.LbMQClient
+}
+
+// SendMessage send message to raft mq
+func (sender *raftMsgSender) SendMessage(msg []byte) error {
+	return sender.SendMessages([][]byte{msg})
+}
+
+// SendMessages send message batch to raft mq
+func (sender *raftMsgSender) SendMessages(msgs [][]byte) error {
+	ctx := context.Background()
+	if sender.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, sender.timeout)
+		defer cancel()
+	}
+	return sender.cli.ProduceMsg(ctx, &proxy.ProduceMsgArgs{Topic: sender.topic, Msgs: msgs})
+}
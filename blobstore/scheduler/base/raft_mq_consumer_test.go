@@ -0,0 +1,112 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package base
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/api/proxy"
+	"github.com/cubefs/cubefs/blobstore/common/kafka"
+)
+
+type memMQClient struct {
+	sync.Mutex
+	msgs    map[string][][]byte
+	offsets map[string]int64
+}
+
+func newMemMQClient() *memMQClient {
+	return &memMQClient{msgs: make(map[string][][]byte), offsets: make(map[string]int64)}
+}
+
+func (m *memMQClient) ProduceMsg(ctx context.Context, args *proxy.ProduceMsgArgs) error {
+	m.Lock()
+	defer m.Unlock()
+	m.msgs[args.Topic] = append(m.msgs[args.Topic], args.Msgs...)
+	return nil
+}
+
+func (m *memMQClient) FetchMsg(ctx context.Context, args *proxy.FetchMsgArgs) (ret proxy.FetchMsgRet, err error) {
+	m.Lock()
+	defer m.Unlock()
+	msgs := m.msgs[args.Topic]
+	for offset := m.offsets[args.Topic+args.Group]; offset < int64(len(msgs)) && len(ret.Msgs) < args.Count; offset++ {
+		ret.Msgs = append(ret.Msgs, proxy.Message{Offset: offset, Value: msgs[offset]})
+	}
+	return
+}
+
+func (m *memMQClient) CommitMsg(ctx context.Context, args *proxy.CommitMsgArgs) error {
+	m.Lock()
+	defer m.Unlock()
+	m.offsets[args.Topic+args.Group] = args.Offset
+	return nil
+}
+
+func TestRaftMQConsumer(t *testing.T) {
+	cli := newMemMQClient()
+	mqClient := NewRaftMQConsumer(cli)
+
+	sender, err := NewMsgSenderOf(mqClient, &kafka.ProducerCfg{Topic: testTopic, TimeoutMs: 1000})
+	require.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, sender.SendMessage([]byte{byte(i)}))
+	}
+	require.NoError(t, sender.SendMessages([][]byte{{5}, {6}}))
+
+	var (
+		mu       sync.Mutex
+		consumed []int64
+		failed   bool
+	)
+	done := make(chan struct{})
+	consumer, err := mqClient.StartKafkaConsumer(KafkaConsumerCfg{
+		Topic:        testTopic,
+		MaxBatchSize: 3,
+		MaxWaitTimeS: 1,
+	}, func(msgs []*sarama.ConsumerMessage, consumerPause ConsumerPause) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		// the first batch is failed and consumed again
+		if !failed {
+			failed = true
+			return false
+		}
+		for _, msg := range msgs {
+			require.Equal(t, testTopic, msg.Topic)
+			require.Equal(t, []byte{byte(msg.Offset)}, msg.Value)
+			consumed = append(consumed, msg.Offset)
+		}
+		if len(consumed) == 7 {
+			close(done)
+		}
+		return true
+	})
+	require.NoError(t, err)
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "consume timeout")
+	}
+	consumer.Stop()
+	require.Equal(t, []int64{0, 1, 2, 3, 4, 5, 6}, consumed)
+}
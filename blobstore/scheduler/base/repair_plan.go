@@ -0,0 +1,188 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package base
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+)
+
+// repair plan types
+const (
+	RepairPlanLocal  = "local"
+	RepairPlanGlobal = "global"
+)
+
+// RepairPlan plan of repairing bad shards in a stripe, the worker
+// of repair is expected in WorkerAZ to minimise cross AZ traffic
+type RepairPlan struct {
+	// all bad shards can be repaired by local stripes in their AZ
+	LocalRepairable bool
+	// az index of the worker which downloads fewest shards across AZs
+	WorkerAZ int
+	// estimated count of shards downloaded across AZs
+	CrossAZShards int
+}
+
+// Type returns type of repair plan
+func (p RepairPlan) Type() string {
+	if p.LocalRepairable {
+		return RepairPlanLocal
+	}
+	return RepairPlanGlobal
+}
+
+// NewRepairPlan returns repair plan of bad shards
+func NewRepairPlan(mode codemode.CodeMode, badIdxes []uint8) RepairPlan {
+	plan := RepairPlan{WorkerAZ: -1}
+	if !mode.IsValid() {
+		return plan
+	}
+
+	tactic := mode.Tactic()
+	bads := make(map[int]struct{}, len(badIdxes))
+	badAZs := make([]int, 0)
+	for _, idx := range badIdxes {
+		bads[int(idx)] = struct{}{}
+		azIdx := tactic.AZIndex(int(idx))
+		if azIdx < 0 {
+			continue
+		}
+		exist := false
+		for _, az := range badAZs {
+			if az == azIdx {
+				exist = true
+				break
+			}
+		}
+		if !exist {
+			badAZs = append(badAZs, azIdx)
+		}
+	}
+
+	if len(badAZs) == 0 {
+		return plan
+	}
+
+	// repaired by local stripe if bad shards in every AZ are no more than local parities
+	if tactic.L > 0 {
+		plan.LocalRepairable = true
+		for _, azIdx := range badAZs {
+			stripe, _, l := tactic.LocalStripeInAZ(azIdx)
+			badCnt := 0
+			for _, idx := range stripe {
+				if _, ok := bads[idx]; ok {
+					badCnt++
+				}
+			}
+			if badCnt > l {
+				plan.LocalRepairable = false
+				break
+			}
+		}
+		if plan.LocalRepairable {
+			plan.WorkerAZ = badAZs[0]
+			return plan
+		}
+	}
+
+	// repaired by global stripe, replicate mode needs only one well replica
+	need := tactic.N
+	if tactic.IsReplicateMode() {
+		need = 1
+	}
+	globalStripe, _, _ := tactic.GlobalStripe()
+	plan.CrossAZShards = need
+	for _, azIdx := range badAZs {
+		wellCnt := 0
+		for _, idx := range globalStripe {
+			if _, ok := bads[idx]; ok {
+				continue
+			}
+			if tactic.AZIndex(idx) == azIdx {
+				wellCnt++
+			}
+		}
+		crossAZ := need - wellCnt
+		if crossAZ < 0 {
+			crossAZ = 0
+		}
+		if plan.WorkerAZ < 0 || crossAZ < plan.CrossAZShards {
+			plan.WorkerAZ = azIdx
+			plan.CrossAZShards = crossAZ
+		}
+	}
+	return plan
+}
+
+// RepairPlanStats repair plan stats
+type RepairPlanStats struct {
+	planCounter         *prometheus.CounterVec
+	crossAZShardCounter prometheus.Counter
+}
+
+// NewRepairPlanStats returns repair plan stats
+func NewRepairPlanStats(clusterID proto.ClusterID, taskType string) *RepairPlanStats {
+	labels := map[string]string{
+		"cluster_id": fmt.Sprintf("%d", clusterID),
+		"task_type":  taskType,
+	}
+
+	planCounter := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   namespace,
+		Subsystem:   "repair_plan",
+		Name:        "plan_cnt",
+		Help:        "repair plan cnt",
+		ConstLabels: labels,
+	}, []string{"plan_type"})
+
+	crossAZShardCounter := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace:   namespace,
+		Subsystem:   "repair_plan",
+		Name:        "cross_az_shard_cnt",
+		Help:        "estimated shard cnt downloaded across az",
+		ConstLabels: labels,
+	})
+
+	if err := prometheus.Register(planCounter); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			planCounter = are.ExistingCollector.(*prometheus.CounterVec)
+		} else {
+			panic(err)
+		}
+	}
+	if err := prometheus.Register(crossAZShardCounter); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			crossAZShardCounter = are.ExistingCollector.(prometheus.Counter)
+		} else {
+			panic(err)
+		}
+	}
+
+	return &RepairPlanStats{
+		planCounter:         planCounter,
+		crossAZShardCounter: crossAZShardCounter,
+	}
+}
+
+// Report report repair plan
+func (s *RepairPlanStats) Report(plan RepairPlan) {
+	s.planCounter.WithLabelValues(plan.Type()).Inc()
+	s.crossAZShardCounter.Add(float64(plan.CrossAZShards))
+}
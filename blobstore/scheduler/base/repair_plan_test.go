@@ -0,0 +1,59 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package base
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+)
+
+func TestNewRepairPlan(t *testing.T) {
+	cases := []struct {
+		mode     codemode.CodeMode
+		bads     []uint8
+		local    bool
+		workerAZ int
+		crossAZ  int
+	}{
+		{codemode.CodeMode(0), []uint8{0}, false, -1, 0},
+		{codemode.EC6P10L2, nil, false, -1, 0},
+		{codemode.EC6P10L2, []uint8{0}, true, 0, 0},
+		{codemode.EC6P10L2, []uint8{0, 3}, true, 0, 0},
+		{codemode.EC6P10L2, []uint8{17}, true, 1, 0},
+		{codemode.EC6P10L2, []uint8{0, 1}, false, 0, 0},
+		{codemode.EC6P10L2, []uint8{0, 1, 2, 6}, false, 0, 2},
+		{codemode.EC6P10L2, []uint8{0, 1, 2, 6, 3, 4}, false, 1, 0},
+		{codemode.EC6P3L3, []uint8{0, 1}, false, 0, 5},
+		{codemode.EC6P6, []uint8{0}, false, 0, 3},
+		{codemode.EC12P4, []uint8{0}, false, 0, 0},
+		{codemode.Replica3, []uint8{0}, false, 0, 1},
+		{codemode.Replica3OneAZ, []uint8{0}, false, 0, 0},
+	}
+	for _, cs := range cases {
+		plan := NewRepairPlan(cs.mode, cs.bads)
+		require.Equal(t, cs.local, plan.LocalRepairable, "%s %v", cs.mode, cs.bads)
+		require.Equal(t, cs.workerAZ, plan.WorkerAZ, "%s %v", cs.mode, cs.bads)
+		require.Equal(t, cs.crossAZ, plan.CrossAZShards, "%s %v", cs.mode, cs.bads)
+	}
+
+	stats := NewRepairPlanStats(1, "shard_repair")
+	stats.Report(NewRepairPlan(codemode.EC6P10L2, []uint8{0}))
+	stats.Report(NewRepairPlan(codemode.EC6P10L2, []uint8{0, 1, 2, 6}))
+	// register again
+	NewRepairPlanStats(1, "shard_repair")
+}
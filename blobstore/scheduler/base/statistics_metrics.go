@@ -90,6 +90,8 @@ type TaskRunDetailInfo struct {
 	StartTime    time.Time            `json:"start_time"`
 	CompleteTime time.Time            `json:"complete_time"`
 	Completed    bool                 `json:"completed"`
+	// bytes of shards downloaded across AZs
+	CrossAZDataSize int `json:"cross_az_data_size"`
 }
 
 // TaskStatsMgr task stats manager
@@ -99,8 +101,9 @@ type TaskStatsMgr struct {
 	dataSizeByteCounter counter.Counter
 	shardCntCounter     counter.Counter
 
-	dataSizeProCounter prometheus.Counter
-	shardCntProCounter prometheus.Counter
+	dataSizeProCounter        prometheus.Counter
+	shardCntProCounter        prometheus.Counter
+	crossAZDataSizeProCounter prometheus.Counter
 
 	taskCntGauge *prometheus.GaugeVec
 
@@ -142,6 +145,14 @@ func NewTaskStatsMgr(clusterID proto.ClusterID, taskType proto.TaskType) *TaskSt
 		ConstLabels: labels,
 	})
 
+	crossAZDataSizeProCounter := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace:   namespace,
+		Subsystem:   "task",
+		Name:        "cross_az_data_size",
+		Help:        "data size downloaded across az",
+		ConstLabels: labels,
+	})
+
 	taskCntGauge := prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace:   namespace,
@@ -183,6 +194,13 @@ func NewTaskStatsMgr(clusterID proto.ClusterID, taskType proto.TaskType) *TaskSt
 			panic(err)
 		}
 	}
+	if err := prometheus.Register(crossAZDataSizeProCounter); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			crossAZDataSizeProCounter = are.ExistingCollector.(prometheus.Counter)
+		} else {
+			panic(err)
+		}
+	}
 	if err := prometheus.Register(taskCntGauge); err != nil {
 		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
 			taskCntGauge = are.ExistingCollector.(*prometheus.GaugeVec)
@@ -206,12 +224,13 @@ func NewTaskStatsMgr(clusterID proto.ClusterID, taskType proto.TaskType) *TaskSt
 	}
 
 	mgr := &TaskStatsMgr{
-		TaskRunInfos:       make(map[string]TaskRunDetailInfo),
-		dataSizeProCounter: dataSizeProCounter,
-		shardCntProCounter: shardCntProCounter,
-		taskCntGauge:       taskCntGauge,
-		reclaimCounter:     reclaimCounter,
-		cancelCounter:      cancelCounter,
+		TaskRunInfos:              make(map[string]TaskRunDetailInfo),
+		dataSizeProCounter:        dataSizeProCounter,
+		shardCntProCounter:        shardCntProCounter,
+		crossAZDataSizeProCounter: crossAZDataSizeProCounter,
+		taskCntGauge:              taskCntGauge,
+		reclaimCounter:            reclaimCounter,
+		cancelCounter:             cancelCounter,
 	}
 
 	return mgr
@@ -257,6 +276,22 @@ func (statsMgr *TaskStatsMgr) ReportWorkerTaskStats(taskID string, s proto.TaskS
 	statsMgr.shardCntProCounter.Add(float64(increaseShardCnt))
 }
 
+// ReportCrossAZDataSize report data size of task downloaded across AZs
+func (statsMgr *TaskStatsMgr) ReportCrossAZDataSize(taskID string, increaseDataSize int) {
+	if increaseDataSize <= 0 {
+		return
+	}
+
+	statsMgr.mu.Lock()
+	defer statsMgr.mu.Unlock()
+
+	if taskRunInfo, ok := statsMgr.TaskRunInfos[taskID]; ok {
+		taskRunInfo.CrossAZDataSize += increaseDataSize
+		statsMgr.TaskRunInfos[taskID] = taskRunInfo
+	}
+	statsMgr.crossAZDataSizeProCounter.Add(float64(increaseDataSize))
+}
+
 // ReclaimTask reclaim task
 func (statsMgr *TaskStatsMgr) ReclaimTask() {
 	statsMgr.reclaimCounter.Inc()
@@ -39,6 +39,13 @@ func TestTaskStatisticsMgr(t *testing.T) {
 	_, err := mgr.QueryTaskDetail("repair_task_1")
 	require.NoError(t, err)
 
+	mgr.ReportCrossAZDataSize("repair_task_1", 10)
+	mgr.ReportCrossAZDataSize("repair_task_1", 5)
+	mgr.ReportCrossAZDataSize("repair_task_2", 5)
+	detail, err := mgr.QueryTaskDetail("repair_task_1")
+	require.NoError(t, err)
+	require.Equal(t, 15, detail.CrossAZDataSize)
+
 	increaseDataSize, increaseShardCnt := mgr.Counters()
 	var increaseDataSizeVec [counter.SLOT]int
 	increaseDataSizeVec[counter.SLOT-1] = 10
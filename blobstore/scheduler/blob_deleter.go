@@ -229,7 +229,7 @@ func NewBlobDeleteMgr(
 	blobnodeCli client.BlobnodeAPI,
 	kafkaClient base.KafkaConsumer,
 ) (*BlobDeleteMgr, error) {
-	failMsgSender, err := base.NewMsgSender(cfg.failedProducerConfig())
+	failMsgSender, err := base.NewMsgSenderOf(kafkaClient, cfg.failedProducerConfig())
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,183 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/taskswitch"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/scheduler/client"
+	"github.com/cubefs/cubefs/blobstore/util/closer"
+)
+
+// IBlobExpirer define the interface of blob expire manager
+type IBlobExpirer interface {
+	Enabled() bool
+	Run()
+	closer.Closer
+}
+
+// BlobExpireMgrCfg blob expire manager config
+type BlobExpireMgrCfg struct {
+	ExpireIntervalS int `json:"expire_interval_s"`
+	ListBlobStep    int `json:"list_blob_step"`
+}
+
+// BlobExpireMgr manager of expiring blobs
+// step1.access records slices of location with expiry into kv of clustermgr
+// step2.list the records in order of expiry, stop at the first unexpired one
+// step3.send delete message of the expired blobs to mq proxy and remove the record
+//
+// Blobs are deleted by blob delete manager, so the delay of deleting is applied too.
+type BlobExpireMgr struct {
+	closer.Closer
+
+	taskSwitch    taskswitch.ISwitcher
+	clusterMgrCli client.ClusterMgrAPI
+	deleteSender  client.ProxyAPI
+
+	cfg *BlobExpireMgrCfg
+}
+
+// NewBlobExpireMgr returns blob expire manager
+func NewBlobExpireMgr(clusterMgrCli client.ClusterMgrAPI, deleteSender client.ProxyAPI,
+	taskSwitch taskswitch.ISwitcher, cfg *BlobExpireMgrCfg,
+) *BlobExpireMgr {
+	return &BlobExpireMgr{
+		Closer:        closer.New(),
+		taskSwitch:    taskSwitch,
+		clusterMgrCli: clusterMgrCli,
+		deleteSender:  deleteSender,
+		cfg:           cfg,
+	}
+}
+
+// Enabled returns true if task switch status
+func (mgr *BlobExpireMgr) Enabled() bool {
+	return mgr.taskSwitch.Enabled()
+}
+
+// Run run blob expire manager
+func (mgr *BlobExpireMgr) Run() {
+	go mgr.run()
+}
+
+func (mgr *BlobExpireMgr) run() {
+	t := time.NewTicker(time.Duration(mgr.cfg.ExpireIntervalS) * time.Second)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			mgr.taskSwitch.WaitEnable()
+			mgr.expireRun()
+		case <-mgr.Closer.Done():
+			return
+		}
+	}
+}
+
+func (mgr *BlobExpireMgr) expireRun() {
+	span, ctx := trace.StartSpanFromContext(context.Background(), "blob_expirer.run")
+	defer span.Finish()
+
+	now := time.Now().Unix()
+	expired := 0
+	defer func() {
+		if expired > 0 {
+			span.Infof("expired %d blob slices", expired)
+		}
+	}()
+
+	marker := ""
+	for {
+		blobs, nextMarker, err := mgr.clusterMgrCli.ListExpiringBlobs(ctx, marker, mgr.cfg.ListBlobStep)
+		if err != nil {
+			span.Errorf("list expiring blobs failed: marker[%s], err[%+v]", marker, err)
+			return
+		}
+		for _, blob := range blobs {
+			// records are ordered by expiry
+			if blob.Expire > now {
+				return
+			}
+			if !mgr.Enabled() {
+				return
+			}
+			select {
+			case <-mgr.Closer.Done():
+				return
+			default:
+			}
+
+			if err = mgr.expire(ctx, blob); err != nil {
+				span.Errorf("expire blobs failed: blob[%+v], err[%+v]", blob, err)
+				return
+			}
+			expired++
+		}
+		if len(blobs) == 0 || nextMarker == "" {
+			return
+		}
+		marker = nextMarker
+	}
+}
+
+func (mgr *BlobExpireMgr) expire(ctx context.Context, blob *proto.ExpiringBlob) error {
+	bids := make([]proto.BlobID, 0, blob.Count)
+	for i := uint32(0); i < blob.Count; i++ {
+		bids = append(bids, blob.MinBid+proto.BlobID(i))
+	}
+	if len(bids) > 0 {
+		if err := mgr.deleteSender.SendDeleteMsg(ctx, blob.Vid, bids); err != nil {
+			return err
+		}
+	}
+	return mgr.clusterMgrCli.DeleteExpiringBlob(ctx, blob)
+}
+
+// moveExpiringBlobs moves the expiry records of the source volume to the target volume, which the
+// blobs are moved into with the same bids, so that the moved blobs are still deleted once expired.
+func moveExpiringBlobs(ctx context.Context, src client.ClusterMgrAPI, srcVid proto.Vid,
+	dst client.ClusterMgrAPI, dstVid proto.Vid,
+) error {
+	marker := ""
+	for {
+		blobs, nextMarker, err := src.ListExpiringBlobs(ctx, marker, defaultListBlobStep)
+		if err != nil {
+			return err
+		}
+		for _, blob := range blobs {
+			if blob.Vid != srcVid {
+				continue
+			}
+			moved := *blob
+			moved.Vid = dstVid
+			if err = dst.SetExpiringBlob(ctx, &moved); err != nil {
+				return err
+			}
+			if err = src.DeleteExpiringBlob(ctx, blob); err != nil {
+				return err
+			}
+		}
+		if len(blobs) == 0 || nextMarker == "" {
+			return nil
+		}
+		marker = nextMarker
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/testing/mocks"
+)
+
+func newBlobExpireMgr(t *testing.T) *BlobExpireMgr {
+	ctr := gomock.NewController(t)
+	clusterMgr := NewMockClusterMgrAPI(ctr)
+	deleteSender := NewMockMqProxyAPI(ctr)
+	taskSwitch := mocks.NewMockSwitcher(ctr)
+	taskSwitch.EXPECT().Enabled().AnyTimes().Return(true)
+	conf := &BlobExpireMgrCfg{ExpireIntervalS: defaultBlobExpireIntervalS, ListBlobStep: 2}
+	return NewBlobExpireMgr(clusterMgr, deleteSender, taskSwitch, conf)
+}
+
+func TestBlobExpireRun(t *testing.T) {
+	now := time.Now().Unix()
+	blobs := []*proto.ExpiringBlob{
+		{Vid: 1, MinBid: 10, Count: 2, Expire: now - 100},
+		{Vid: 2, MinBid: 20, Count: 1, Expire: now - 10},
+		{Vid: 3, MinBid: 30, Count: 0, Expire: now - 1},
+		{Vid: 4, MinBid: 40, Count: 1, Expire: now + 100},
+	}
+
+	// expire all expired blobs and stop at the first unexpired one
+	{
+		mgr := newBlobExpireMgr(t)
+		clusterMgr := mgr.clusterMgrCli.(*MockClusterMgrAPI)
+		clusterMgr.EXPECT().ListExpiringBlobs(any, "", 2).Return(blobs[:2], blobs[1].Key(), nil)
+		clusterMgr.EXPECT().ListExpiringBlobs(any, blobs[1].Key(), 2).Return(blobs[2:], blobs[3].Key(), nil)
+
+		sender := mgr.deleteSender.(*MockMqProxyAPI)
+		sender.EXPECT().SendDeleteMsg(any, proto.Vid(1), []proto.BlobID{10, 11}).Return(nil)
+		sender.EXPECT().SendDeleteMsg(any, proto.Vid(2), []proto.BlobID{20}).Return(nil)
+
+		deleted := make([]proto.Vid, 0)
+		clusterMgr.EXPECT().DeleteExpiringBlob(any, any).Times(3).DoAndReturn(
+			func(_ context.Context, blob *proto.ExpiringBlob) error {
+				deleted = append(deleted, blob.Vid)
+				return nil
+			})
+		mgr.expireRun()
+		require.Equal(t, []proto.Vid{1, 2, 3}, deleted)
+	}
+	// keep the record if send failed
+	{
+		mgr := newBlobExpireMgr(t)
+		clusterMgr := mgr.clusterMgrCli.(*MockClusterMgrAPI)
+		clusterMgr.EXPECT().ListExpiringBlobs(any, "", 2).Return(blobs[:2], blobs[1].Key(), nil)
+		mgr.deleteSender.(*MockMqProxyAPI).EXPECT().SendDeleteMsg(any, any, any).Return(errMock)
+		mgr.expireRun()
+	}
+	// list failed or nothing to expire
+	{
+		mgr := newBlobExpireMgr(t)
+		clusterMgr := mgr.clusterMgrCli.(*MockClusterMgrAPI)
+		clusterMgr.EXPECT().ListExpiringBlobs(any, any, any).Return(nil, "", errMock)
+		mgr.expireRun()
+		clusterMgr.EXPECT().ListExpiringBlobs(any, any, any).Return(nil, "", nil)
+		mgr.expireRun()
+	}
+}
+
+func TestMoveExpiringBlobs(t *testing.T) {
+	ctx := context.Background()
+	ctr := gomock.NewController(t)
+	src := NewMockClusterMgrAPI(ctr)
+	dst := NewMockClusterMgrAPI(ctr)
+	blobs := []*proto.ExpiringBlob{
+		{Vid: 1, MinBid: 10, Count: 2, Expire: 100},
+		{Vid: 2, MinBid: 20, Count: 1, Expire: 100},
+		{Vid: 1, MinBid: 30, Count: 1, Expire: 200},
+	}
+
+	src.EXPECT().ListExpiringBlobs(any, "", defaultListBlobStep).Return(blobs[:2], blobs[1].Key(), nil)
+	src.EXPECT().ListExpiringBlobs(any, blobs[1].Key(), defaultListBlobStep).Return(blobs[2:], "", nil)
+	moved := make([]proto.ExpiringBlob, 0)
+	dst.EXPECT().SetExpiringBlob(any, any).Times(2).DoAndReturn(
+		func(_ context.Context, blob *proto.ExpiringBlob) error {
+			moved = append(moved, *blob)
+			return nil
+		})
+	src.EXPECT().DeleteExpiringBlob(any, blobs[0]).Return(nil)
+	src.EXPECT().DeleteExpiringBlob(any, blobs[2]).Return(nil)
+	require.NoError(t, moveExpiringBlobs(ctx, src, 1, dst, 5))
+	require.Equal(t, []proto.ExpiringBlob{
+		{Vid: 5, MinBid: 10, Count: 2, Expire: 100},
+		{Vid: 5, MinBid: 30, Count: 1, Expire: 200},
+	}, moved)
+
+	// the source record is kept if the target one is not set
+	src.EXPECT().ListExpiringBlobs(any, "", defaultListBlobStep).Return(blobs[:1], "", nil)
+	dst.EXPECT().SetExpiringBlob(any, any).Return(errMock)
+	require.ErrorIs(t, moveExpiringBlobs(ctx, src, 1, dst, 5), errMock)
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"strings"
 	"sync"
 	"time"
@@ -29,6 +30,7 @@ import (
 	"github.com/cubefs/cubefs/blobstore/common/codemode"
 	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/rpc"
 	"github.com/cubefs/cubefs/blobstore/common/trace"
 	"github.com/cubefs/cubefs/blobstore/util/log"
 )
@@ -47,6 +49,7 @@ type ClusterMgrVolumeAPI interface {
 	ReleaseVolumeUnit(ctx context.Context, vuid proto.Vuid, diskID proto.DiskID) (err error)
 	ListDiskVolumeUnits(ctx context.Context, diskID proto.DiskID) (ret []*VunitInfoSimple, err error)
 	ListVolume(ctx context.Context, marker proto.Vid, count int) (volInfo []*VolumeInfoSimple, retVid proto.Vid, err error)
+	AllocVolume(ctx context.Context, mode codemode.CodeMode) (ret *VolumeInfoSimple, err error)
 }
 
 type ClusterMgrDiskAPI interface {
@@ -81,6 +84,27 @@ type ClusterMgrTaskAPI interface {
 	SetVolumeInspectCheckPoint(ctx context.Context, startVid proto.Vid) (err error)
 	GetConsumeOffset(taskType proto.TaskType, topic string, partition int32) (offset int64, err error)
 	SetConsumeOffset(taskType proto.TaskType, topic string, partition int32, offset int64) (err error)
+	AddConvertTask(ctx context.Context, value *proto.CodeModeConvertTask) (err error)
+	UpdateConvertTask(ctx context.Context, value *proto.CodeModeConvertTask) (err error)
+	ListAllConvertTasks(ctx context.Context) (tasks []*proto.CodeModeConvertTask, err error)
+	SetConvertedVolume(ctx context.Context, value *proto.ConvertedVolume) (err error)
+	GetConvertedVolume(ctx context.Context, vid proto.Vid) (ret *proto.ConvertedVolume, err error)
+	AddClusterMigrateTask(ctx context.Context, value *proto.ClusterMigrateTask) (err error)
+	UpdateClusterMigrateTask(ctx context.Context, value *proto.ClusterMigrateTask) (err error)
+	ListAllClusterMigrateTasks(ctx context.Context) (tasks []*proto.ClusterMigrateTask, err error)
+	SetMigratedVolume(ctx context.Context, value *proto.MigratedVolume) (err error)
+	GetMigratedVolume(ctx context.Context, clusterID proto.ClusterID, vid proto.Vid) (ret *proto.MigratedVolume, err error)
+	ReserveVolume(ctx context.Context, value *proto.ReservedVolume) (err error)
+	ReleaseVolume(ctx context.Context, vid proto.Vid) (err error)
+	IsReservedVolume(ctx context.Context, vid proto.Vid) (reserved bool, err error)
+	AddVolumeCompactTask(ctx context.Context, value *proto.VolumeCompactTask) (err error)
+	UpdateVolumeCompactTask(ctx context.Context, value *proto.VolumeCompactTask) (err error)
+	ListAllVolumeCompactTasks(ctx context.Context) (tasks []*proto.VolumeCompactTask, err error)
+	SetCompactedVolume(ctx context.Context, value *proto.CompactedVolume) (err error)
+	GetCompactedVolume(ctx context.Context, vid proto.Vid) (ret *proto.CompactedVolume, err error)
+	ListExpiringBlobs(ctx context.Context, marker string, count int) (blobs []*proto.ExpiringBlob, nextMarker string, err error)
+	SetExpiringBlob(ctx context.Context, blob *proto.ExpiringBlob) (err error)
+	DeleteExpiringBlob(ctx context.Context, blob *proto.ExpiringBlob) (err error)
 }
 
 // ClusterMgrAPI define the interface of clustermgr used by scheduler
@@ -124,6 +148,44 @@ type ClusterMgrAPI interface {
 //	for example:
 //		blob_delete-consume_offset-blob_delete-1
 //		shard_repair-consume_offset-shard_repair-2
+//
+// codemode convert task key
+//  - - - - - - - - - - - - - - - - - - - -
+//  |  task_type  |  volume_id  | random_id |
+//  - - - - - - - - - - - - - - - - - - - -
+//	for example:
+//		codemode_convert-18-cbkgq9qc605btusi7gk0
+//
+// converted volume key, see proto.ConvertedVolumeKey
+//	for example:
+//		converted_volume-18
+//		converted_target_volume-19
+//
+// cluster migrate task key
+//  - - - - - - - - - - - - - - - - - - - -
+//  |  task_type  |  volume_id  | random_id |
+//  - - - - - - - - - - - - - - - - - - - -
+//	for example:
+//		cluster_migrate-18-cbkgq9qc605btusi7gk0
+//
+// migrated volume key in target cluster, see proto.MigratedVolumeKey
+//	for example:
+//		migrated_volume-1-18
+//
+// reserved volume key, see proto.ReservedVolumeKey
+//	for example:
+//		reserved_volume-19
+//
+// volume compact task key
+//  - - - - - - - - - - - - - - - - - - - -
+//  |  task_type  |  volume_id  | random_id |
+//  - - - - - - - - - - - - - - - - - - - -
+//	for example:
+//		volume_compact-18-cbkgq9qc605btusi7gk0
+//
+// compacted volume key, see proto.CompactedVolumeKey
+//	for example:
+//		compacted_volume-18
 
 const (
 	_delimiter           = "-"
@@ -170,6 +232,21 @@ func GenMigrateTaskPrefixByDiskID(taskType proto.TaskType, diskID proto.DiskID)
 	return fmt.Sprintf("%s%d%s", GenMigrateTaskPrefix(taskType), diskID, _delimiter)
 }
 
+// GenConvertTaskID return uniq codemode convert task id
+func GenConvertTaskID(volumeID proto.Vid) string {
+	return fmt.Sprintf("%s%d%s%s", GenMigrateTaskPrefix(proto.TaskTypeCodeModeConvert), volumeID, _delimiter, xid.New().String())
+}
+
+// GenClusterMigrateTaskID return uniq cluster migrate task id
+func GenClusterMigrateTaskID(volumeID proto.Vid) string {
+	return fmt.Sprintf("%s%d%s%s", GenMigrateTaskPrefix(proto.TaskTypeClusterMigrate), volumeID, _delimiter, xid.New().String())
+}
+
+// GenVolumeCompactTaskID return uniq volume compact task id
+func GenVolumeCompactTaskID(volumeID proto.Vid) string {
+	return fmt.Sprintf("%s%d%s%s", GenMigrateTaskPrefix(proto.TaskTypeVolumeCompact), volumeID, _delimiter, xid.New().String())
+}
+
 func ValidMigrateTask(taskType proto.TaskType, taskID string) bool {
 	return strings.HasPrefix(taskID, GenMigrateTaskPrefix(taskType))
 }
@@ -193,6 +270,9 @@ type VolumeInfoSimple struct {
 	Vid            proto.Vid             `json:"vid"`
 	CodeMode       codemode.CodeMode     `json:"code_mode"`
 	Status         proto.VolumeStatus    `json:"status"`
+	Used           uint64                `json:"used"`
+	Total          uint64                `json:"total"`
+	Free           uint64                `json:"free"`
 	VunitLocations []proto.VunitLocation `json:"vunit_locations"`
 }
 
@@ -228,6 +308,9 @@ func (vol *VolumeInfoSimple) set(info *cmapi.VolumeInfo) {
 	vol.Vid = info.Vid
 	vol.CodeMode = info.CodeMode
 	vol.Status = info.Status
+	vol.Used = info.Used
+	vol.Total = info.Total
+	vol.Free = info.Free
 	vol.VunitLocations = make([]proto.VunitLocation, len(info.Units))
 
 	// check volume info
@@ -367,6 +450,7 @@ type IClusterManager interface {
 	ReleaseVolumeUnit(ctx context.Context, args *cmapi.ReleaseVolumeUnitArgs) (err error)
 	ListVolumeUnit(ctx context.Context, args *cmapi.ListVolumeUnitArgs) ([]*cmapi.VolumeUnitInfo, error)
 	ListVolume(ctx context.Context, args *cmapi.ListVolumeArgs) (ret cmapi.ListVolumes, err error)
+	AllocVolume(ctx context.Context, args *cmapi.AllocVolumeArgs) (ret cmapi.AllocatedVolumeInfos, err error)
 	ListDisk(ctx context.Context, args *cmapi.ListOptionArgs) (ret cmapi.ListDiskRet, err error)
 	ListDroppingDisk(ctx context.Context) (ret []*blobnode.DiskInfo, err error)
 	SetDisk(ctx context.Context, id proto.DiskID, status proto.DiskStatus) (err error)
@@ -559,6 +643,28 @@ func (c *clustermgrClient) ListVolume(ctx context.Context, marker proto.Vid, cou
 	return
 }
 
+// AllocVolume alloc a new volume with code mode
+func (c *clustermgrClient) AllocVolume(ctx context.Context, mode codemode.CodeMode) (*VolumeInfoSimple, error) {
+	c.rwLock.Lock()
+	defer c.rwLock.Unlock()
+
+	span := trace.SpanFromContextSafe(ctx)
+
+	span.Debugf("alloc volume: args code_mode[%s]", mode)
+	ret, err := c.client.AllocVolume(ctx, &cmapi.AllocVolumeArgs{CodeMode: mode, Count: 1})
+	if err != nil {
+		span.Errorf("alloc volume failed: err[%+v]", err)
+		return nil, err
+	}
+	if len(ret.AllocVolumeInfos) == 0 {
+		return nil, errcode.ErrNoAvailableVolume
+	}
+	vol := &VolumeInfoSimple{}
+	vol.set(&ret.AllocVolumeInfos[0].VolumeInfo)
+	span.Debugf("alloc volume ret: vid[%d]", vol.Vid)
+	return vol, nil
+}
+
 // ListClusterDisks list all disks
 func (c *clustermgrClient) ListClusterDisks(ctx context.Context) (disks []*DiskInfoSimple, err error) {
 	c.rwLock.RLock()
@@ -990,3 +1096,282 @@ func (c *clustermgrClient) SetConsumeOffset(taskType proto.TaskType, topic strin
 	}
 	return c.client.SetKV(context.Background(), genConsumerOffsetKey(taskType, topic, partition), consumeOffsetBytes)
 }
+
+// AddConvertTask adds codemode convert task
+func (c *clustermgrClient) AddConvertTask(ctx context.Context, value *proto.CodeModeConvertTask) (err error) {
+	value.Ctime = time.Now().String()
+	value.MTime = value.Ctime
+	return c.setTask(ctx, value.TaskID, value)
+}
+
+// UpdateConvertTask updates codemode convert task
+func (c *clustermgrClient) UpdateConvertTask(ctx context.Context, value *proto.CodeModeConvertTask) (err error) {
+	value.MTime = time.Now().String()
+	return c.setTask(ctx, value.TaskID, value)
+}
+
+// ListAllConvertTasks returns all codemode convert tasks
+func (c *clustermgrClient) ListAllConvertTasks(ctx context.Context) (tasks []*proto.CodeModeConvertTask, err error) {
+	span := trace.SpanFromContextSafe(ctx)
+
+	marker := defaultListTaskMarker
+	for {
+		args := &cmapi.ListKvOpts{
+			Prefix: GenMigrateTaskPrefix(proto.TaskTypeCodeModeConvert),
+			Count:  defaultListTaskNum,
+			Marker: marker,
+		}
+		ret, err := c.client.ListKV(ctx, args)
+		if err != nil {
+			span.Errorf("list task failed: err[%+v]", err)
+			return nil, err
+		}
+
+		for _, v := range ret.Kvs {
+			var task *proto.CodeModeConvertTask
+			if err = json.Unmarshal(v.Value, &task); err != nil {
+				span.Errorf("unmarshal task failed: err[%+v]", err)
+				return nil, err
+			}
+			tasks = append(tasks, task)
+		}
+		marker = ret.Marker
+		if marker == defaultListTaskMarker {
+			break
+		}
+	}
+	return
+}
+
+// SetConvertedVolume records the volume is converting or has been converted,
+// the finished record is kept by the target vid as well.
+func (c *clustermgrClient) SetConvertedVolume(ctx context.Context, value *proto.ConvertedVolume) (err error) {
+	value.Ctime = time.Now().String()
+	if !value.Converting {
+		if err = c.setTask(ctx, proto.ConvertedTargetVolumeKey(value.TargetVid), value); err != nil {
+			return
+		}
+	}
+	return c.setTask(ctx, proto.ConvertedVolumeKey(value.SourceVid), value)
+}
+
+// GetConvertedVolume returns the converted record of volume
+func (c *clustermgrClient) GetConvertedVolume(ctx context.Context, vid proto.Vid) (ret *proto.ConvertedVolume, err error) {
+	val, err := c.client.GetKV(ctx, proto.ConvertedVolumeKey(vid))
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(val.Value, &ret)
+	return
+}
+
+// AddClusterMigrateTask adds cluster migrate task
+func (c *clustermgrClient) AddClusterMigrateTask(ctx context.Context, value *proto.ClusterMigrateTask) (err error) {
+	value.Ctime = time.Now().String()
+	value.MTime = value.Ctime
+	return c.setTask(ctx, value.TaskID, value)
+}
+
+// UpdateClusterMigrateTask updates cluster migrate task
+func (c *clustermgrClient) UpdateClusterMigrateTask(ctx context.Context, value *proto.ClusterMigrateTask) (err error) {
+	value.MTime = time.Now().String()
+	return c.setTask(ctx, value.TaskID, value)
+}
+
+// ListAllClusterMigrateTasks returns all cluster migrate tasks
+func (c *clustermgrClient) ListAllClusterMigrateTasks(ctx context.Context) (tasks []*proto.ClusterMigrateTask, err error) {
+	span := trace.SpanFromContextSafe(ctx)
+
+	marker := defaultListTaskMarker
+	for {
+		args := &cmapi.ListKvOpts{
+			Prefix: GenMigrateTaskPrefix(proto.TaskTypeClusterMigrate),
+			Count:  defaultListTaskNum,
+			Marker: marker,
+		}
+		ret, err := c.client.ListKV(ctx, args)
+		if err != nil {
+			span.Errorf("list task failed: err[%+v]", err)
+			return nil, err
+		}
+
+		for _, v := range ret.Kvs {
+			var task *proto.ClusterMigrateTask
+			if err = json.Unmarshal(v.Value, &task); err != nil {
+				span.Errorf("unmarshal task failed: err[%+v]", err)
+				return nil, err
+			}
+			tasks = append(tasks, task)
+		}
+		marker = ret.Marker
+		if marker == defaultListTaskMarker {
+			break
+		}
+	}
+	return
+}
+
+// SetMigratedVolume records the volume has been migrated, it should be called with target cluster
+func (c *clustermgrClient) SetMigratedVolume(ctx context.Context, value *proto.MigratedVolume) (err error) {
+	value.Ctime = time.Now().String()
+	return c.setTask(ctx, proto.MigratedVolumeKey(value.SourceClusterID, value.SourceVid), value)
+}
+
+// GetMigratedVolume returns the migrated record of volume in source cluster
+func (c *clustermgrClient) GetMigratedVolume(ctx context.Context, clusterID proto.ClusterID, vid proto.Vid) (ret *proto.MigratedVolume, err error) {
+	val, err := c.client.GetKV(ctx, proto.MigratedVolumeKey(clusterID, vid))
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(val.Value, &ret)
+	return
+}
+
+// ReserveVolume records the locked volume is reserved by task
+func (c *clustermgrClient) ReserveVolume(ctx context.Context, value *proto.ReservedVolume) (err error) {
+	value.Ctime = time.Now().String()
+	return c.setTask(ctx, proto.ReservedVolumeKey(value.Vid), value)
+}
+
+// ReleaseVolume removes the reserved record of volume
+func (c *clustermgrClient) ReleaseVolume(ctx context.Context, vid proto.Vid) (err error) {
+	return c.client.DeleteKV(ctx, proto.ReservedVolumeKey(vid))
+}
+
+// IsReservedVolume returns true if the volume is reserved by some running task,
+// or it is the target volume of a migrated volume which keeps bids of another cluster.
+func (c *clustermgrClient) IsReservedVolume(ctx context.Context, vid proto.Vid) (reserved bool, err error) {
+	_, err = c.client.GetKV(ctx, proto.ReservedVolumeKey(vid))
+	if err == nil {
+		return true, nil
+	}
+	if rpc.DetectStatusCode(err) != http.StatusNotFound {
+		return false, err
+	}
+	return c.isMigratedTargetVolume(ctx, vid)
+}
+
+func (c *clustermgrClient) isMigratedTargetVolume(ctx context.Context, vid proto.Vid) (bool, error) {
+	span := trace.SpanFromContextSafe(ctx)
+
+	marker := defaultListTaskMarker
+	for {
+		args := &cmapi.ListKvOpts{
+			Prefix: proto.MigratedVolumeKeyPrefix,
+			Count:  defaultListTaskNum,
+			Marker: marker,
+		}
+		ret, err := c.client.ListKV(ctx, args)
+		if err != nil {
+			span.Errorf("list migrated volume failed: err[%+v]", err)
+			return false, err
+		}
+
+		for _, v := range ret.Kvs {
+			var migrated *proto.MigratedVolume
+			if err = json.Unmarshal(v.Value, &migrated); err != nil {
+				span.Errorf("unmarshal migrated volume failed: err[%+v]", err)
+				return false, err
+			}
+			if migrated.TargetVid == vid {
+				return true, nil
+			}
+		}
+		marker = ret.Marker
+		if marker == defaultListTaskMarker {
+			return false, nil
+		}
+	}
+}
+
+// AddVolumeCompactTask adds volume compact task
+func (c *clustermgrClient) AddVolumeCompactTask(ctx context.Context, value *proto.VolumeCompactTask) (err error) {
+	value.Ctime = time.Now().String()
+	value.MTime = value.Ctime
+	return c.setTask(ctx, value.TaskID, value)
+}
+
+// UpdateVolumeCompactTask updates volume compact task
+func (c *clustermgrClient) UpdateVolumeCompactTask(ctx context.Context, value *proto.VolumeCompactTask) (err error) {
+	value.MTime = time.Now().String()
+	return c.setTask(ctx, value.TaskID, value)
+}
+
+// ListAllVolumeCompactTasks returns all volume compact tasks
+func (c *clustermgrClient) ListAllVolumeCompactTasks(ctx context.Context) (tasks []*proto.VolumeCompactTask, err error) {
+	span := trace.SpanFromContextSafe(ctx)
+
+	marker := defaultListTaskMarker
+	for {
+		args := &cmapi.ListKvOpts{
+			Prefix: GenMigrateTaskPrefix(proto.TaskTypeVolumeCompact),
+			Count:  defaultListTaskNum,
+			Marker: marker,
+		}
+		ret, err := c.client.ListKV(ctx, args)
+		if err != nil {
+			span.Errorf("list task failed: err[%+v]", err)
+			return nil, err
+		}
+
+		for _, v := range ret.Kvs {
+			var task *proto.VolumeCompactTask
+			if err = json.Unmarshal(v.Value, &task); err != nil {
+				span.Errorf("unmarshal task failed: err[%+v]", err)
+				return nil, err
+			}
+			tasks = append(tasks, task)
+		}
+		marker = ret.Marker
+		if marker == defaultListTaskMarker {
+			break
+		}
+	}
+	return
+}
+
+// SetCompactedVolume records the volume has been compacted
+func (c *clustermgrClient) SetCompactedVolume(ctx context.Context, value *proto.CompactedVolume) (err error) {
+	value.Ctime = time.Now().String()
+	return c.setTask(ctx, proto.CompactedVolumeKey(value.SourceVid), value)
+}
+
+// GetCompactedVolume returns the compacted record of volume
+func (c *clustermgrClient) GetCompactedVolume(ctx context.Context, vid proto.Vid) (ret *proto.CompactedVolume, err error) {
+	val, err := c.client.GetKV(ctx, proto.CompactedVolumeKey(vid))
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(val.Value, &ret)
+	return
+}
+
+// ListExpiringBlobs returns expiring blobs recorded by access in order of expiry
+func (c *clustermgrClient) ListExpiringBlobs(ctx context.Context, marker string, count int) (blobs []*proto.ExpiringBlob, nextMarker string, err error) {
+	ret, err := c.client.ListKV(ctx, &cmapi.ListKvOpts{
+		Prefix: proto.ExpiringBlobKeyPrefix,
+		Marker: marker,
+		Count:  count,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	for _, v := range ret.Kvs {
+		var blob *proto.ExpiringBlob
+		if err = json.Unmarshal(v.Value, &blob); err != nil {
+			return nil, "", err
+		}
+		blobs = append(blobs, blob)
+	}
+	return blobs, ret.Marker, nil
+}
+
+// SetExpiringBlob records the expiring blob
+func (c *clustermgrClient) SetExpiringBlob(ctx context.Context, blob *proto.ExpiringBlob) (err error) {
+	return c.setTask(ctx, blob.Key(), blob)
+}
+
+// DeleteExpiringBlob deletes the record of expiring blob
+func (c *clustermgrClient) DeleteExpiringBlob(ctx context.Context, blob *proto.ExpiringBlob) (err error) {
+	return c.client.DeleteKV(ctx, blob.Key())
+}
@@ -37,6 +37,21 @@ func (m *MockClusterManager) EXPECT() *MockClusterManagerMockRecorder {
 	return m.recorder
 }
 
+// AllocVolume mocks base method.
+func (m *MockClusterManager) AllocVolume(arg0 context.Context, arg1 *clustermgr.AllocVolumeArgs) (clustermgr.AllocatedVolumeInfos, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AllocVolume", arg0, arg1)
+	ret0, _ := ret[0].(clustermgr.AllocatedVolumeInfos)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AllocVolume indicates an expected call of AllocVolume.
+func (mr *MockClusterManagerMockRecorder) AllocVolume(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllocVolume", reflect.TypeOf((*MockClusterManager)(nil).AllocVolume), arg0, arg1)
+}
+
 // AllocVolumeUnit mocks base method.
 func (m *MockClusterManager) AllocVolumeUnit(arg0 context.Context, arg1 *clustermgr.AllocVolumeUnitArgs) (*clustermgr.AllocVolumeUnit, error) {
 	m.ctrl.T.Helper()
@@ -487,4 +487,119 @@ func TestClustermgrClient(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, offset, offset2)
 	}
+	{
+		// alloc volume
+		cli.client.(*MockClusterManager).EXPECT().AllocVolume(any, any).Return(cmapi.AllocatedVolumeInfos{}, errMock)
+		_, err := cli.AllocVolume(ctx, codemode.EC6P6)
+		require.True(t, errors.Is(err, errMock))
+
+		cli.client.(*MockClusterManager).EXPECT().AllocVolume(any, any).Return(cmapi.AllocatedVolumeInfos{}, nil)
+		_, err = cli.AllocVolume(ctx, codemode.EC6P6)
+		require.ErrorIs(t, err, errcode.ErrNoAvailableVolume)
+
+		volume := MockGenVolInfo(10, codemode.EC6P6, proto.VolumeStatusActive)
+		cli.client.(*MockClusterManager).EXPECT().AllocVolume(any, any).Return(
+			cmapi.AllocatedVolumeInfos{AllocVolumeInfos: []cmapi.AllocVolumeInfo{{VolumeInfo: *volume}}}, nil)
+		vol, err := cli.AllocVolume(ctx, codemode.EC6P6)
+		require.NoError(t, err)
+		require.Equal(t, proto.Vid(10), vol.Vid)
+		require.Equal(t, 12, len(vol.VunitLocations))
+	}
+	{
+		// add and update convert task
+		task := &proto.CodeModeConvertTask{TaskID: GenConvertTaskID(1), SourceVid: 1}
+		require.True(t, ValidMigrateTask(proto.TaskTypeCodeModeConvert, task.TaskID))
+		cli.client.(*MockClusterManager).EXPECT().SetKV(any, any, any).Return(nil)
+		err := cli.AddConvertTask(ctx, task)
+		require.NoError(t, err)
+		require.Equal(t, task.Ctime, task.MTime)
+
+		cli.client.(*MockClusterManager).EXPECT().SetKV(any, any, any).Return(nil)
+		err = cli.UpdateConvertTask(ctx, task)
+		require.NoError(t, err)
+
+		// list all convert tasks
+		taskBytes, _ := json.Marshal(task)
+		cli.client.(*MockClusterManager).EXPECT().ListKV(any, any).Return(cmapi.ListKvRet{
+			Kvs: []*cmapi.KeyValue{{Key: task.TaskID, Value: taskBytes}}, Marker: "marker",
+		}, nil)
+		cli.client.(*MockClusterManager).EXPECT().ListKV(any, any).Return(cmapi.ListKvRet{
+			Kvs: []*cmapi.KeyValue{{Key: task.TaskID, Value: taskBytes}},
+		}, nil)
+		tasks, err := cli.ListAllConvertTasks(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(tasks))
+
+		cli.client.(*MockClusterManager).EXPECT().ListKV(any, any).Return(cmapi.ListKvRet{
+			Kvs: []*cmapi.KeyValue{{Key: task.TaskID, Value: []byte("xxx")}},
+		}, nil)
+		_, err = cli.ListAllConvertTasks(ctx)
+		require.Error(t, err)
+	}
+	{
+		// set and get converted volume
+		vol := &proto.ConvertedVolume{SourceVid: 1, TargetVid: 2, Converting: true}
+		cli.client.(*MockClusterManager).EXPECT().SetKV(any, proto.ConvertedVolumeKey(1), any).Return(nil)
+		err := cli.SetConvertedVolume(ctx, vol)
+		require.NoError(t, err)
+
+		vol = &proto.ConvertedVolume{SourceVid: 1, TargetVid: 2, MaxBid: 10, SkippedBids: []proto.BlobID{3, 5}}
+		cli.client.(*MockClusterManager).EXPECT().SetKV(any, proto.ConvertedTargetVolumeKey(2), any).Return(nil)
+		cli.client.(*MockClusterManager).EXPECT().SetKV(any, proto.ConvertedVolumeKey(1), any).Return(nil)
+		err = cli.SetConvertedVolume(ctx, vol)
+		require.NoError(t, err)
+
+		volBytes, _ := json.Marshal(vol)
+		cli.client.(*MockClusterManager).EXPECT().GetKV(any, proto.ConvertedVolumeKey(1)).Return(cmapi.GetKvRet{Value: volBytes}, nil)
+		vol2, err := cli.GetConvertedVolume(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, vol.TargetVid, vol2.TargetVid)
+		require.True(t, vol2.Skipped(5))
+		require.False(t, vol2.Skipped(4))
+		require.True(t, vol2.Converted(4))
+		require.False(t, vol2.Converted(11))
+
+		cli.client.(*MockClusterManager).EXPECT().GetKV(any, any).Return(cmapi.GetKvRet{}, errMock)
+		_, err = cli.GetConvertedVolume(ctx, 1)
+		require.True(t, errors.Is(err, errMock))
+	}
+	{
+		// reserve, check and release volume
+		cli.client.(*MockClusterManager).EXPECT().SetKV(any, proto.ReservedVolumeKey(2), any).Return(nil)
+		err := cli.ReserveVolume(ctx, &proto.ReservedVolume{Vid: 2, TaskID: "cluster_migrate-1-a"})
+		require.NoError(t, err)
+
+		cli.client.(*MockClusterManager).EXPECT().GetKV(any, proto.ReservedVolumeKey(2)).Return(cmapi.GetKvRet{Value: []byte("{}")}, nil)
+		reserved, err := cli.IsReservedVolume(ctx, 2)
+		require.NoError(t, err)
+		require.True(t, reserved)
+
+		// target volume of migrated volume
+		migratedBytes, _ := json.Marshal(&proto.MigratedVolume{SourceVid: 1, TargetVid: 2})
+		cli.client.(*MockClusterManager).EXPECT().GetKV(any, any).Times(3).Return(cmapi.GetKvRet{}, errcode.ErrNotFound)
+		cli.client.(*MockClusterManager).EXPECT().ListKV(any, any).Return(cmapi.ListKvRet{
+			Kvs: []*cmapi.KeyValue{{Key: proto.MigratedVolumeKey(1, 1), Value: migratedBytes}},
+		}, nil)
+		reserved, err = cli.IsReservedVolume(ctx, 2)
+		require.NoError(t, err)
+		require.True(t, reserved)
+
+		cli.client.(*MockClusterManager).EXPECT().ListKV(any, any).Return(cmapi.ListKvRet{
+			Kvs: []*cmapi.KeyValue{{Key: proto.MigratedVolumeKey(1, 1), Value: migratedBytes}},
+		}, nil)
+		reserved, err = cli.IsReservedVolume(ctx, 3)
+		require.NoError(t, err)
+		require.False(t, reserved)
+
+		cli.client.(*MockClusterManager).EXPECT().ListKV(any, any).Return(cmapi.ListKvRet{}, errMock)
+		_, err = cli.IsReservedVolume(ctx, 3)
+		require.True(t, errors.Is(err, errMock))
+
+		cli.client.(*MockClusterManager).EXPECT().GetKV(any, any).Return(cmapi.GetKvRet{}, errMock)
+		_, err = cli.IsReservedVolume(ctx, 2)
+		require.True(t, errors.Is(err, errMock))
+
+		cli.client.(*MockClusterManager).EXPECT().DeleteKV(any, proto.ReservedVolumeKey(2)).Return(nil)
+		require.NoError(t, cli.ReleaseVolume(ctx, 2))
+	}
 }
@@ -26,6 +26,7 @@ import (
 // ProxyAPI define the interface of proxy used by scheduler
 type ProxyAPI interface {
 	SendShardRepairMsg(ctx context.Context, vid proto.Vid, bid proto.BlobID, badIdx []uint8) error
+	SendDeleteMsg(ctx context.Context, vid proto.Vid, bids []proto.BlobID) error
 }
 
 // proxyClient proxy client
@@ -56,3 +57,22 @@ func (c *proxyClient) SendShardRepairMsg(ctx context.Context, vid proto.Vid, bid
 	span.Debugf("send shard repair msg ret err %+v", err)
 	return err
 }
+
+// SendDeleteMsg send blob delete message
+func (c *proxyClient) SendDeleteMsg(ctx context.Context, vid proto.Vid, bids []proto.BlobID) error {
+	pSpan := trace.SpanFromContextSafe(ctx)
+	span, ctx := trace.StartSpanFromContextWithTraceID(context.Background(), "SendDeleteMsg", pSpan.TraceID())
+	span.Debugf("send delete msg vid %d bids %v", vid, bids)
+
+	blobs := make([]api.BlobDelete, 0, len(bids))
+	for _, bid := range bids {
+		blobs = append(blobs, api.BlobDelete{Bid: bid, Vid: vid})
+	}
+	err := c.client.SendDeleteMsg(ctx, &api.DeleteArgs{
+		ClusterID: c.clusterID,
+		Blobs:     blobs,
+	})
+
+	span.Debugf("send delete msg ret err %+v", err)
+	return err
+}
@@ -21,6 +21,8 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 
+	api "github.com/cubefs/cubefs/blobstore/api/proxy"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
 	"github.com/cubefs/cubefs/blobstore/testing/mocks"
 )
 
@@ -33,4 +35,13 @@ func TestMQProxy(t *testing.T) {
 	}
 	err := cli.SendShardRepairMsg(context.Background(), 0, 0, []uint8{0})
 	require.NoError(t, err)
+
+	mqcli.EXPECT().SendDeleteMsg(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, args *api.DeleteArgs) error {
+			require.Equal(t, proto.ClusterID(1), args.ClusterID)
+			require.Equal(t, []api.BlobDelete{{Bid: 10, Vid: 1}, {Bid: 11, Vid: 1}}, args.Blobs)
+			return nil
+		})
+	err = cli.SendDeleteMsg(context.Background(), 1, []proto.BlobID{10, 11})
+	require.NoError(t, err)
 }
@@ -9,6 +9,7 @@ import (
 	reflect "reflect"
 
 	clustermgr "github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	codemode "github.com/cubefs/cubefs/blobstore/common/codemode"
 	proto "github.com/cubefs/cubefs/blobstore/common/proto"
 	client "github.com/cubefs/cubefs/blobstore/scheduler/client"
 	gomock "github.com/golang/mock/gomock"
@@ -37,6 +38,34 @@ func (m *MockClusterMgrAPI) EXPECT() *MockClusterMgrAPIMockRecorder {
 	return m.recorder
 }
 
+// AddClusterMigrateTask mocks base method.
+func (m *MockClusterMgrAPI) AddClusterMigrateTask(arg0 context.Context, arg1 *proto.ClusterMigrateTask) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddClusterMigrateTask", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddClusterMigrateTask indicates an expected call of AddClusterMigrateTask.
+func (mr *MockClusterMgrAPIMockRecorder) AddClusterMigrateTask(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddClusterMigrateTask", reflect.TypeOf((*MockClusterMgrAPI)(nil).AddClusterMigrateTask), arg0, arg1)
+}
+
+// AddConvertTask mocks base method.
+func (m *MockClusterMgrAPI) AddConvertTask(arg0 context.Context, arg1 *proto.CodeModeConvertTask) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddConvertTask", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddConvertTask indicates an expected call of AddConvertTask.
+func (mr *MockClusterMgrAPIMockRecorder) AddConvertTask(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddConvertTask", reflect.TypeOf((*MockClusterMgrAPI)(nil).AddConvertTask), arg0, arg1)
+}
+
 // AddMigrateTask mocks base method.
 func (m *MockClusterMgrAPI) AddMigrateTask(arg0 context.Context, arg1 *proto.MigrateTask) error {
 	m.ctrl.T.Helper()
@@ -65,6 +94,35 @@ func (mr *MockClusterMgrAPIMockRecorder) AddMigratingDisk(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMigratingDisk", reflect.TypeOf((*MockClusterMgrAPI)(nil).AddMigratingDisk), arg0, arg1)
 }
 
+// AddVolumeCompactTask mocks base method.
+func (m *MockClusterMgrAPI) AddVolumeCompactTask(arg0 context.Context, arg1 *proto.VolumeCompactTask) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddVolumeCompactTask", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddVolumeCompactTask indicates an expected call of AddVolumeCompactTask.
+func (mr *MockClusterMgrAPIMockRecorder) AddVolumeCompactTask(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddVolumeCompactTask", reflect.TypeOf((*MockClusterMgrAPI)(nil).AddVolumeCompactTask), arg0, arg1)
+}
+
+// AllocVolume mocks base method.
+func (m *MockClusterMgrAPI) AllocVolume(arg0 context.Context, arg1 codemode.CodeMode) (*client.VolumeInfoSimple, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AllocVolume", arg0, arg1)
+	ret0, _ := ret[0].(*client.VolumeInfoSimple)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AllocVolume indicates an expected call of AllocVolume.
+func (mr *MockClusterMgrAPIMockRecorder) AllocVolume(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllocVolume", reflect.TypeOf((*MockClusterMgrAPI)(nil).AllocVolume), arg0, arg1)
+}
+
 // AllocVolumeUnit mocks base method.
 func (m *MockClusterMgrAPI) AllocVolumeUnit(arg0 context.Context, arg1 proto.Vuid) (*client.AllocVunitInfo, error) {
 	m.ctrl.T.Helper()
@@ -80,6 +138,34 @@ func (mr *MockClusterMgrAPIMockRecorder) AllocVolumeUnit(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllocVolumeUnit", reflect.TypeOf((*MockClusterMgrAPI)(nil).AllocVolumeUnit), arg0, arg1)
 }
 
+// DeleteExpiringBlob mocks base method.
+func (m *MockClusterMgrAPI) DeleteExpiringBlob(arg0 context.Context, arg1 *proto.ExpiringBlob) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiringBlob", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpiringBlob indicates an expected call of DeleteExpiringBlob.
+func (mr *MockClusterMgrAPIMockRecorder) DeleteExpiringBlob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiringBlob", reflect.TypeOf((*MockClusterMgrAPI)(nil).DeleteExpiringBlob), arg0, arg1)
+}
+
+// SetExpiringBlob mocks base method.
+func (m *MockClusterMgrAPI) SetExpiringBlob(arg0 context.Context, arg1 *proto.ExpiringBlob) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetExpiringBlob", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetExpiringBlob indicates an expected call of SetExpiringBlob.
+func (mr *MockClusterMgrAPIMockRecorder) SetExpiringBlob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetExpiringBlob", reflect.TypeOf((*MockClusterMgrAPI)(nil).SetExpiringBlob), arg0, arg1)
+}
+
 // DeleteMigrateTask mocks base method.
 func (m *MockClusterMgrAPI) DeleteMigrateTask(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -108,6 +194,21 @@ func (mr *MockClusterMgrAPIMockRecorder) DeleteMigratingDisk(arg0, arg1, arg2 in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMigratingDisk", reflect.TypeOf((*MockClusterMgrAPI)(nil).DeleteMigratingDisk), arg0, arg1, arg2)
 }
 
+// GetCompactedVolume mocks base method.
+func (m *MockClusterMgrAPI) GetCompactedVolume(arg0 context.Context, arg1 proto.Vid) (*proto.CompactedVolume, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCompactedVolume", arg0, arg1)
+	ret0, _ := ret[0].(*proto.CompactedVolume)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCompactedVolume indicates an expected call of GetCompactedVolume.
+func (mr *MockClusterMgrAPIMockRecorder) GetCompactedVolume(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompactedVolume", reflect.TypeOf((*MockClusterMgrAPI)(nil).GetCompactedVolume), arg0, arg1)
+}
+
 // GetConfig mocks base method.
 func (m *MockClusterMgrAPI) GetConfig(arg0 context.Context, arg1 string) (string, error) {
 	m.ctrl.T.Helper()
@@ -138,6 +239,21 @@ func (mr *MockClusterMgrAPIMockRecorder) GetConsumeOffset(arg0, arg1, arg2 inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConsumeOffset", reflect.TypeOf((*MockClusterMgrAPI)(nil).GetConsumeOffset), arg0, arg1, arg2)
 }
 
+// GetConvertedVolume mocks base method.
+func (m *MockClusterMgrAPI) GetConvertedVolume(arg0 context.Context, arg1 proto.Vid) (*proto.ConvertedVolume, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConvertedVolume", arg0, arg1)
+	ret0, _ := ret[0].(*proto.ConvertedVolume)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConvertedVolume indicates an expected call of GetConvertedVolume.
+func (mr *MockClusterMgrAPIMockRecorder) GetConvertedVolume(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConvertedVolume", reflect.TypeOf((*MockClusterMgrAPI)(nil).GetConvertedVolume), arg0, arg1)
+}
+
 // GetDiskInfo mocks base method.
 func (m *MockClusterMgrAPI) GetDiskInfo(arg0 context.Context, arg1 proto.DiskID) (*client.DiskInfoSimple, error) {
 	m.ctrl.T.Helper()
@@ -168,6 +284,21 @@ func (mr *MockClusterMgrAPIMockRecorder) GetMigrateTask(arg0, arg1, arg2 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMigrateTask", reflect.TypeOf((*MockClusterMgrAPI)(nil).GetMigrateTask), arg0, arg1, arg2)
 }
 
+// GetMigratedVolume mocks base method.
+func (m *MockClusterMgrAPI) GetMigratedVolume(arg0 context.Context, arg1 proto.ClusterID, arg2 proto.Vid) (*proto.MigratedVolume, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMigratedVolume", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*proto.MigratedVolume)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMigratedVolume indicates an expected call of GetMigratedVolume.
+func (mr *MockClusterMgrAPIMockRecorder) GetMigratedVolume(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMigratedVolume", reflect.TypeOf((*MockClusterMgrAPI)(nil).GetMigratedVolume), arg0, arg1, arg2)
+}
+
 // GetMigratingDisk mocks base method.
 func (m *MockClusterMgrAPI) GetMigratingDisk(arg0 context.Context, arg1 proto.TaskType, arg2 proto.DiskID) (*client.MigratingDiskMeta, error) {
 	m.ctrl.T.Helper()
@@ -228,6 +359,36 @@ func (mr *MockClusterMgrAPIMockRecorder) GetVolumeInspectCheckPoint(arg0 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVolumeInspectCheckPoint", reflect.TypeOf((*MockClusterMgrAPI)(nil).GetVolumeInspectCheckPoint), arg0)
 }
 
+// ListAllClusterMigrateTasks mocks base method.
+func (m *MockClusterMgrAPI) ListAllClusterMigrateTasks(arg0 context.Context) ([]*proto.ClusterMigrateTask, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAllClusterMigrateTasks", arg0)
+	ret0, _ := ret[0].([]*proto.ClusterMigrateTask)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAllClusterMigrateTasks indicates an expected call of ListAllClusterMigrateTasks.
+func (mr *MockClusterMgrAPIMockRecorder) ListAllClusterMigrateTasks(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAllClusterMigrateTasks", reflect.TypeOf((*MockClusterMgrAPI)(nil).ListAllClusterMigrateTasks), arg0)
+}
+
+// ListAllConvertTasks mocks base method.
+func (m *MockClusterMgrAPI) ListAllConvertTasks(arg0 context.Context) ([]*proto.CodeModeConvertTask, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAllConvertTasks", arg0)
+	ret0, _ := ret[0].([]*proto.CodeModeConvertTask)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAllConvertTasks indicates an expected call of ListAllConvertTasks.
+func (mr *MockClusterMgrAPIMockRecorder) ListAllConvertTasks(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAllConvertTasks", reflect.TypeOf((*MockClusterMgrAPI)(nil).ListAllConvertTasks), arg0)
+}
+
 // ListAllMigrateTasks mocks base method.
 func (m *MockClusterMgrAPI) ListAllMigrateTasks(arg0 context.Context, arg1 proto.TaskType) ([]*proto.MigrateTask, error) {
 	m.ctrl.T.Helper()
@@ -258,6 +419,21 @@ func (mr *MockClusterMgrAPIMockRecorder) ListAllMigrateTasksByDiskID(arg0, arg1,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAllMigrateTasksByDiskID", reflect.TypeOf((*MockClusterMgrAPI)(nil).ListAllMigrateTasksByDiskID), arg0, arg1, arg2)
 }
 
+// ListAllVolumeCompactTasks mocks base method.
+func (m *MockClusterMgrAPI) ListAllVolumeCompactTasks(arg0 context.Context) ([]*proto.VolumeCompactTask, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAllVolumeCompactTasks", arg0)
+	ret0, _ := ret[0].([]*proto.VolumeCompactTask)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAllVolumeCompactTasks indicates an expected call of ListAllVolumeCompactTasks.
+func (mr *MockClusterMgrAPIMockRecorder) ListAllVolumeCompactTasks(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAllVolumeCompactTasks", reflect.TypeOf((*MockClusterMgrAPI)(nil).ListAllVolumeCompactTasks), arg0)
+}
+
 // ListBrokenDisks mocks base method.
 func (m *MockClusterMgrAPI) ListBrokenDisks(arg0 context.Context) ([]*client.DiskInfoSimple, error) {
 	m.ctrl.T.Helper()
@@ -318,6 +494,22 @@ func (mr *MockClusterMgrAPIMockRecorder) ListDropDisks(arg0 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDropDisks", reflect.TypeOf((*MockClusterMgrAPI)(nil).ListDropDisks), arg0)
 }
 
+// ListExpiringBlobs mocks base method.
+func (m *MockClusterMgrAPI) ListExpiringBlobs(arg0 context.Context, arg1 string, arg2 int) ([]*proto.ExpiringBlob, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExpiringBlobs", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*proto.ExpiringBlob)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListExpiringBlobs indicates an expected call of ListExpiringBlobs.
+func (mr *MockClusterMgrAPIMockRecorder) ListExpiringBlobs(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExpiringBlobs", reflect.TypeOf((*MockClusterMgrAPI)(nil).ListExpiringBlobs), arg0, arg1, arg2)
+}
+
 // ListMigrateTasks mocks base method.
 func (m *MockClusterMgrAPI) ListMigrateTasks(arg0 context.Context, arg1 proto.TaskType, arg2 *clustermgr.ListKvOpts) ([]*proto.MigrateTask, string, error) {
 	m.ctrl.T.Helper()
@@ -422,6 +614,20 @@ func (mr *MockClusterMgrAPIMockRecorder) ReleaseVolumeUnit(arg0, arg1, arg2 inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseVolumeUnit", reflect.TypeOf((*MockClusterMgrAPI)(nil).ReleaseVolumeUnit), arg0, arg1, arg2)
 }
 
+// SetCompactedVolume mocks base method.
+func (m *MockClusterMgrAPI) SetCompactedVolume(arg0 context.Context, arg1 *proto.CompactedVolume) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCompactedVolume", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCompactedVolume indicates an expected call of SetCompactedVolume.
+func (mr *MockClusterMgrAPIMockRecorder) SetCompactedVolume(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCompactedVolume", reflect.TypeOf((*MockClusterMgrAPI)(nil).SetCompactedVolume), arg0, arg1)
+}
+
 // SetConfig mocks base method.
 func (m *MockClusterMgrAPI) SetConfig(arg0 context.Context, arg1, arg2 string) error {
 	m.ctrl.T.Helper()
@@ -450,6 +656,20 @@ func (mr *MockClusterMgrAPIMockRecorder) SetConsumeOffset(arg0, arg1, arg2, arg3
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetConsumeOffset", reflect.TypeOf((*MockClusterMgrAPI)(nil).SetConsumeOffset), arg0, arg1, arg2, arg3)
 }
 
+// SetConvertedVolume mocks base method.
+func (m *MockClusterMgrAPI) SetConvertedVolume(arg0 context.Context, arg1 *proto.ConvertedVolume) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetConvertedVolume", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetConvertedVolume indicates an expected call of SetConvertedVolume.
+func (mr *MockClusterMgrAPIMockRecorder) SetConvertedVolume(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetConvertedVolume", reflect.TypeOf((*MockClusterMgrAPI)(nil).SetConvertedVolume), arg0, arg1)
+}
+
 // SetDiskDropped mocks base method.
 func (m *MockClusterMgrAPI) SetDiskDropped(arg0 context.Context, arg1 proto.DiskID) error {
 	m.ctrl.T.Helper()
@@ -492,6 +712,63 @@ func (mr *MockClusterMgrAPIMockRecorder) SetDiskRepairing(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDiskRepairing", reflect.TypeOf((*MockClusterMgrAPI)(nil).SetDiskRepairing), arg0, arg1)
 }
 
+// IsReservedVolume mocks base method.
+func (m *MockClusterMgrAPI) IsReservedVolume(arg0 context.Context, arg1 proto.Vid) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsReservedVolume", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsReservedVolume indicates an expected call of IsReservedVolume.
+func (mr *MockClusterMgrAPIMockRecorder) IsReservedVolume(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsReservedVolume", reflect.TypeOf((*MockClusterMgrAPI)(nil).IsReservedVolume), arg0, arg1)
+}
+
+// ReleaseVolume mocks base method.
+func (m *MockClusterMgrAPI) ReleaseVolume(arg0 context.Context, arg1 proto.Vid) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseVolume", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseVolume indicates an expected call of ReleaseVolume.
+func (mr *MockClusterMgrAPIMockRecorder) ReleaseVolume(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseVolume", reflect.TypeOf((*MockClusterMgrAPI)(nil).ReleaseVolume), arg0, arg1)
+}
+
+// ReserveVolume mocks base method.
+func (m *MockClusterMgrAPI) ReserveVolume(arg0 context.Context, arg1 *proto.ReservedVolume) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveVolume", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReserveVolume indicates an expected call of ReserveVolume.
+func (mr *MockClusterMgrAPIMockRecorder) ReserveVolume(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveVolume", reflect.TypeOf((*MockClusterMgrAPI)(nil).ReserveVolume), arg0, arg1)
+}
+
+// SetMigratedVolume mocks base method.
+func (m *MockClusterMgrAPI) SetMigratedVolume(arg0 context.Context, arg1 *proto.MigratedVolume) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMigratedVolume", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetMigratedVolume indicates an expected call of SetMigratedVolume.
+func (mr *MockClusterMgrAPIMockRecorder) SetMigratedVolume(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMigratedVolume", reflect.TypeOf((*MockClusterMgrAPI)(nil).SetMigratedVolume), arg0, arg1)
+}
+
 // SetVolumeInspectCheckPoint mocks base method.
 func (m *MockClusterMgrAPI) SetVolumeInspectCheckPoint(arg0 context.Context, arg1 proto.Vid) error {
 	m.ctrl.T.Helper()
@@ -520,6 +797,34 @@ func (mr *MockClusterMgrAPIMockRecorder) UnlockVolume(arg0, arg1 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockVolume", reflect.TypeOf((*MockClusterMgrAPI)(nil).UnlockVolume), arg0, arg1)
 }
 
+// UpdateClusterMigrateTask mocks base method.
+func (m *MockClusterMgrAPI) UpdateClusterMigrateTask(arg0 context.Context, arg1 *proto.ClusterMigrateTask) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateClusterMigrateTask", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateClusterMigrateTask indicates an expected call of UpdateClusterMigrateTask.
+func (mr *MockClusterMgrAPIMockRecorder) UpdateClusterMigrateTask(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateClusterMigrateTask", reflect.TypeOf((*MockClusterMgrAPI)(nil).UpdateClusterMigrateTask), arg0, arg1)
+}
+
+// UpdateConvertTask mocks base method.
+func (m *MockClusterMgrAPI) UpdateConvertTask(arg0 context.Context, arg1 *proto.CodeModeConvertTask) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateConvertTask", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateConvertTask indicates an expected call of UpdateConvertTask.
+func (mr *MockClusterMgrAPIMockRecorder) UpdateConvertTask(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateConvertTask", reflect.TypeOf((*MockClusterMgrAPI)(nil).UpdateConvertTask), arg0, arg1)
+}
+
 // UpdateMigrateTask mocks base method.
 func (m *MockClusterMgrAPI) UpdateMigrateTask(arg0 context.Context, arg1 *proto.MigrateTask) error {
 	m.ctrl.T.Helper()
@@ -548,6 +853,20 @@ func (mr *MockClusterMgrAPIMockRecorder) UpdateVolume(arg0, arg1, arg2, arg3 int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateVolume", reflect.TypeOf((*MockClusterMgrAPI)(nil).UpdateVolume), arg0, arg1, arg2, arg3)
 }
 
+// UpdateVolumeCompactTask mocks base method.
+func (m *MockClusterMgrAPI) UpdateVolumeCompactTask(arg0 context.Context, arg1 *proto.VolumeCompactTask) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateVolumeCompactTask", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateVolumeCompactTask indicates an expected call of UpdateVolumeCompactTask.
+func (mr *MockClusterMgrAPIMockRecorder) UpdateVolumeCompactTask(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateVolumeCompactTask", reflect.TypeOf((*MockClusterMgrAPI)(nil).UpdateVolumeCompactTask), arg0, arg1)
+}
+
 // MockBlobnodeAPI is a mock of BlobnodeAPI interface.
 type MockBlobnodeAPI struct {
 	ctrl     *gomock.Controller
@@ -687,6 +1006,20 @@ func (m *MockMqProxyAPI) EXPECT() *MockMqProxyAPIMockRecorder {
 	return m.recorder
 }
 
+// SendDeleteMsg mocks base method.
+func (m *MockMqProxyAPI) SendDeleteMsg(arg0 context.Context, arg1 proto.Vid, arg2 []proto.BlobID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDeleteMsg", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendDeleteMsg indicates an expected call of SendDeleteMsg.
+func (mr *MockMqProxyAPIMockRecorder) SendDeleteMsg(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDeleteMsg", reflect.TypeOf((*MockMqProxyAPI)(nil).SendDeleteMsg), arg0, arg1, arg2)
+}
+
 // SendShardRepairMsg mocks base method.
 func (m *MockMqProxyAPI) SendShardRepairMsg(arg0 context.Context, arg1 proto.Vid, arg2 proto.BlobID, arg3 []byte) error {
 	m.ctrl.T.Helper()
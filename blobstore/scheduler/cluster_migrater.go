@@ -0,0 +1,503 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	cmapi "github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	api "github.com/cubefs/cubefs/blobstore/api/scheduler"
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/taskswitch"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/scheduler/base"
+	"github.com/cubefs/cubefs/blobstore/scheduler/client"
+	"github.com/cubefs/cubefs/blobstore/util/closer"
+)
+
+// IClusterMigrator define the interface of cluster migrate manager
+type IClusterMigrator interface {
+	AcquireTask(ctx context.Context) (*proto.ClusterMigrateTask, error)
+	RenewalTask(ctx context.Context, idc, taskID string) error
+	CompleteTask(ctx context.Context, ret *proto.ClusterMigrateRet) error
+	ListTasks(ctx context.Context) []*proto.ClusterMigrateTask
+	GetMigratedVolume(ctx context.Context, vid proto.Vid) (*proto.MigratedVolume, error)
+	Stats() api.ClusterMigrateTasksStat
+	Enabled() bool
+	Load() error
+	Run()
+	closer.Closer
+}
+
+var (
+	errClusterMigrateDisabled    = errors.New("cluster migrate is disabled")
+	errClusterMigrateLeaseExpire = errors.New("cluster migrate task lease expired")
+	errNoSuchClusterMigrateTask  = errors.New("no such cluster migrate task")
+	errNoEmptyTargetVolume       = errors.New("no empty volume in target cluster")
+)
+
+// ClusterMigrateMgrCfg cluster migrate manager config
+type ClusterMigrateMgrCfg struct {
+	ClusterID proto.ClusterID `json:"-"` // fill in config.go
+
+	// migration is disabled if target cluster is not configured
+	TargetClusterID  proto.ClusterID `json:"target_cluster_id"`
+	TargetClusterMgr cmapi.Config    `json:"target_clustermgr"`
+	CollectIntervalS int             `json:"collect_interval_s"`
+	PrepareIntervalS int             `json:"prepare_interval_s"`
+	PreparedLimit    int             `json:"prepared_limit"`
+	ListVolStep      int             `json:"list_vol_step"`
+}
+
+// Configured returns true if target cluster is configured
+func (cfg *ClusterMigrateMgrCfg) Configured() bool {
+	return cfg.TargetClusterID != 0
+}
+
+// ClusterMigrateMgr manager of cluster migrate, it copies all volumes of this cluster
+// into another cluster, so that this cluster can be retired.
+// step1.collect a task for every volume of this cluster
+// step2.lock the source volume to be read-only, lock an empty volume of target cluster
+// step3.worker copies shards of source volume into target volume with the same bid
+// step4.record the migrated volume in target cluster, access redirects locations
+// of the retired cluster with it
+//
+// The cluster is drained when all volumes have been migrated, it should be set
+// readonly before migrating, otherwise volumes in use can not be locked.
+type ClusterMigrateMgr struct {
+	closer.Closer
+	volumeTasks
+
+	// guarded by mu of volumeTasks
+	volumes   map[proto.Vid]string // source vid -> task id
+	collected bool
+
+	taskSwitch    taskswitch.ISwitcher
+	clusterMgrCli client.ClusterMgrAPI
+	targetCli     client.ClusterMgrAPI
+
+	cfg *ClusterMigrateMgrCfg
+}
+
+// NewClusterMigrateMgr returns cluster migrate manager
+func NewClusterMigrateMgr(clusterMgrCli, targetCli client.ClusterMgrAPI, taskSwitch taskswitch.ISwitcher,
+	cfg *ClusterMigrateMgrCfg,
+) *ClusterMigrateMgr {
+	mgr := &ClusterMigrateMgr{
+		Closer:        closer.New(),
+		volumes:       make(map[proto.Vid]string),
+		taskSwitch:    taskSwitch,
+		clusterMgrCli: clusterMgrCli,
+		targetCli:     targetCli,
+		cfg:           cfg,
+	}
+	// the target volume is kept locked by the reservation in target cluster
+	mgr.volumeTasks = newVolumeTasks(taskSwitch, errNoSuchClusterMigrateTask, errClusterMigrateLeaseExpire,
+		func(ctx context.Context, task proto.VolumeTask) error {
+			return clusterMgrCli.UpdateClusterMigrateTask(ctx, task.(*proto.ClusterMigrateTask))
+		},
+		func(ctx context.Context, task proto.VolumeTask) {
+			base.VolTaskLockerInst().Unlock(ctx, task.GetSourceVid())
+		})
+	return mgr
+}
+
+// Load load cluster migrate tasks from clustermgr
+func (mgr *ClusterMigrateMgr) Load() error {
+	if !mgr.cfg.Configured() {
+		return nil
+	}
+	span, ctx := trace.StartSpanFromContext(context.Background(), "ClusterMigrateMgr.Load")
+
+	tasks, err := mgr.clusterMgrCli.ListAllClusterMigrateTasks(ctx)
+	if err != nil {
+		span.Errorf("list all cluster migrate tasks failed: err[%+v]", err)
+		return err
+	}
+	for _, task := range tasks {
+		if task.Running() {
+			if err = base.VolTaskLockerInst().TryLock(ctx, task.SourceVid); err != nil {
+				return err
+			}
+		}
+		mgr.setTask(task)
+		mgr.volumes[task.SourceVid] = task.TaskID
+	}
+	span.Infof("load cluster migrate tasks success: len[%d]", len(tasks))
+	return nil
+}
+
+// Run run cluster migrate manager
+func (mgr *ClusterMigrateMgr) Run() {
+	if !mgr.cfg.Configured() {
+		return
+	}
+	go mgr.collectLoop()
+	go mgr.prepareLoop()
+}
+
+// Enabled returns true if cluster migrate is enabled
+func (mgr *ClusterMigrateMgr) Enabled() bool {
+	return mgr.cfg.Configured() && mgr.taskSwitch.Enabled()
+}
+
+func (mgr *ClusterMigrateMgr) collectLoop() {
+	ticker := time.NewTicker(time.Duration(mgr.cfg.CollectIntervalS) * time.Second)
+	defer ticker.Stop()
+	for {
+		mgr.taskSwitch.WaitEnable()
+		span, ctx := trace.StartSpanFromContext(context.Background(), "ClusterMigrateMgr.collect")
+		if err := mgr.collectTasks(ctx); err != nil {
+			span.Warnf("collect cluster migrate tasks failed: err[%+v]", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-mgr.Done():
+			return
+		}
+	}
+}
+
+// collectTasks adds a task for every volume which has not been collected,
+// volumes created after the last collection are collected in the next round.
+func (mgr *ClusterMigrateMgr) collectTasks(ctx context.Context) error {
+	span := trace.SpanFromContextSafe(ctx)
+
+	marker := proto.InvalidVid
+	for {
+		vols, next, err := mgr.clusterMgrCli.ListVolume(ctx, marker, mgr.cfg.ListVolStep)
+		if err != nil {
+			return err
+		}
+		if len(vols) == 0 {
+			break
+		}
+		for _, vol := range vols {
+			mgr.mu.Lock()
+			_, ok := mgr.volumes[vol.Vid]
+			mgr.mu.Unlock()
+			if ok {
+				continue
+			}
+
+			task := &proto.ClusterMigrateTask{
+				TaskID:          client.GenClusterMigrateTaskID(vol.Vid),
+				State:           proto.MigrateStateInited,
+				CodeMode:        vol.CodeMode,
+				SourceVid:       vol.Vid,
+				Sources:         vol.VunitLocations,
+				TargetClusterID: mgr.cfg.TargetClusterID,
+			}
+			if err = mgr.clusterMgrCli.AddClusterMigrateTask(ctx, task); err != nil {
+				span.Errorf("add cluster migrate task failed: task_id[%s], err[%+v]", task.TaskID, err)
+				return err
+			}
+
+			mgr.setTask(task)
+			mgr.mu.Lock()
+			mgr.volumes[task.SourceVid] = task.TaskID
+			mgr.mu.Unlock()
+			span.Debugf("add cluster migrate task success: task_id[%s]", task.TaskID)
+		}
+		marker = next
+	}
+
+	mgr.mu.Lock()
+	mgr.collected = true
+	mgr.mu.Unlock()
+	return nil
+}
+
+func (mgr *ClusterMigrateMgr) prepareLoop() {
+	ticker := time.NewTicker(time.Duration(mgr.cfg.PrepareIntervalS) * time.Second)
+	defer ticker.Stop()
+	for {
+		mgr.taskSwitch.WaitEnable()
+		select {
+		case <-ticker.C:
+			mgr.prepareTasks()
+		case <-mgr.Done():
+			return
+		}
+	}
+}
+
+// prepareTasks prepares inited tasks in order of vid, no more than
+// PreparedLimit volumes are migrating at the same time.
+func (mgr *ClusterMigrateMgr) prepareTasks() {
+	mgr.mu.Lock()
+	running := 0
+	var inited []*proto.ClusterMigrateTask
+	for _, info := range mgr.tasks {
+		switch {
+		case info.task.Running():
+			running++
+		case info.task.GetState() == proto.MigrateStateInited:
+			inited = append(inited, info.task.(*proto.ClusterMigrateTask).Copy())
+		}
+	}
+	mgr.mu.Unlock()
+	sort.Slice(inited, func(i, j int) bool { return inited[i].SourceVid < inited[j].SourceVid })
+
+	for _, task := range inited {
+		if running >= mgr.cfg.PreparedLimit {
+			return
+		}
+		span, ctx := trace.StartSpanFromContext(context.Background(), "ClusterMigrateMgr.prepare")
+		if err := mgr.prepareTask(ctx, task); err != nil {
+			span.Warnf("prepare cluster migrate task failed: task_id[%s], err[%+v]", task.TaskID, err)
+			continue
+		}
+		running++
+	}
+}
+
+// prepareTask locks the source volume to be read-only and locks an empty target volume.
+func (mgr *ClusterMigrateMgr) prepareTask(ctx context.Context, task *proto.ClusterMigrateTask) (err error) {
+	span := trace.SpanFromContextSafe(ctx)
+
+	if err = base.VolTaskLockerInst().TryLock(ctx, task.SourceVid); err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			base.VolTaskLockerInst().Unlock(ctx, task.SourceVid)
+		}
+	}()
+
+	if err = mgr.clusterMgrCli.LockVolume(ctx, task.SourceVid); err != nil {
+		return
+	}
+	source, err := mgr.clusterMgrCli.GetVolumeInfo(ctx, task.SourceVid)
+	if err != nil {
+		return
+	}
+
+	// keep the locked target volume in memory, in case of updating task failed
+	if task.TargetVid == proto.InvalidVid {
+		target, errLock := mgr.lockTargetVolume(ctx, task.TaskID, task.CodeMode)
+		if errLock != nil {
+			return errLock
+		}
+		task.TargetVid = target.Vid
+		task.Destinations = target.VunitLocations
+		mgr.setTask(task.Copy())
+	}
+
+	task.Sources = source.VunitLocations
+	task.State = proto.MigrateStatePrepared
+	if err = mgr.clusterMgrCli.UpdateClusterMigrateTask(ctx, task); err != nil {
+		return
+	}
+
+	mgr.setTask(task)
+	span.Infof("prepare cluster migrate task success: task_id[%s], vid[%d] -> cluster[%d] vid[%d]",
+		task.TaskID, task.SourceVid, task.TargetClusterID, task.TargetVid)
+	return nil
+}
+
+// lockTargetVolume locks an idle volume which has never been written in target cluster,
+// blobs keep their bids in target volume, so no bid of target cluster should be in it.
+// The volume is reserved in target cluster, so that the migrate tasks of target cluster
+// never unlock it.
+func (mgr *ClusterMigrateMgr) lockTargetVolume(ctx context.Context, taskID string,
+	mode codemode.CodeMode,
+) (*client.VolumeInfoSimple, error) {
+	span := trace.SpanFromContextSafe(ctx)
+
+	marker := proto.InvalidVid
+	for {
+		vols, next, err := mgr.targetCli.ListVolume(ctx, marker, mgr.cfg.ListVolStep)
+		if err != nil {
+			return nil, err
+		}
+		if len(vols) == 0 {
+			break
+		}
+		for _, vol := range vols {
+			if vol.CodeMode != mode || vol.Status != proto.VolumeStatusIdle || vol.Used > 0 || mgr.isTargetVolume(vol.Vid) {
+				continue
+			}
+			if err = mgr.targetCli.LockVolume(ctx, vol.Vid); err != nil {
+				span.Warnf("lock target volume failed: vid[%d], err[%+v]", vol.Vid, err)
+				continue
+			}
+			if err = mgr.targetCli.ReserveVolume(ctx, &proto.ReservedVolume{Vid: vol.Vid, TaskID: taskID}); err != nil {
+				mgr.unlockTargetVolume(ctx, vol.Vid)
+				return nil, err
+			}
+			// the volume may be allocated before locked, or unlocked before reserved
+			locked, err := mgr.targetCli.GetVolumeInfo(ctx, vol.Vid)
+			if err != nil {
+				return nil, err
+			}
+			if locked.Used > 0 || locked.Status != proto.VolumeStatusLock {
+				span.Warnf("target volume has been written: vid[%d], used[%d], status[%d]",
+					vol.Vid, locked.Used, locked.Status)
+				if err = mgr.targetCli.ReleaseVolume(ctx, vol.Vid); err != nil {
+					return nil, err
+				}
+				if locked.Status == proto.VolumeStatusLock {
+					mgr.unlockTargetVolume(ctx, vol.Vid)
+				}
+				continue
+			}
+			return locked, nil
+		}
+		marker = next
+	}
+	return nil, errNoEmptyTargetVolume
+}
+
+// unlockTargetVolume gives back the locked volume which is not taken as target volume,
+// the failure is only logged, the volume is left locked in target cluster then.
+func (mgr *ClusterMigrateMgr) unlockTargetVolume(ctx context.Context, vid proto.Vid) {
+	if err := mgr.targetCli.UnlockVolume(ctx, vid); err != nil {
+		trace.SpanFromContextSafe(ctx).Warnf("unlock target volume failed: vid[%d], err[%+v]", vid, err)
+	}
+}
+
+func (mgr *ClusterMigrateMgr) isTargetVolume(vid proto.Vid) bool {
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+	for _, info := range mgr.tasks {
+		if info.task.GetTargetVid() == vid {
+			return true
+		}
+	}
+	return false
+}
+
+// AcquireTask acquires a prepared task which is not running on any worker
+func (mgr *ClusterMigrateMgr) AcquireTask(ctx context.Context) (*proto.ClusterMigrateTask, error) {
+	if !mgr.cfg.Configured() {
+		return nil, proto.ErrTaskEmpty
+	}
+	task, err := mgr.acquireTask(ctx, mgr.clusterMgrCli, mgr.targetCli)
+	if err != nil {
+		return nil, err
+	}
+	return task.(*proto.ClusterMigrateTask), nil
+}
+
+// CompleteTask finishes the task if worker copied all blobs,
+// otherwise the task will be acquired again.
+func (mgr *ClusterMigrateMgr) CompleteTask(ctx context.Context, ret *proto.ClusterMigrateRet) error {
+	span := trace.SpanFromContextSafe(ctx)
+
+	running, err := mgr.runningTask(ret.TaskID)
+	if err != nil {
+		return err
+	}
+	task := running.(*proto.ClusterMigrateTask)
+
+	if err = ret.Err(); err != nil {
+		span.Warnf("worker migrate failed and redo: task_id[%s], err[%+v]", ret.TaskID, err)
+		task.WorkerRedoCnt++
+		return mgr.redoTask(ctx, task)
+	}
+
+	// blobs may be written if the source volume was unlocked when migrating
+	source, err := mgr.clusterMgrCli.GetVolumeInfo(ctx, task.SourceVid)
+	if err != nil {
+		return err
+	}
+	if source.Status != proto.VolumeStatusLock {
+		span.Warnf("source volume is not locked and redo: task_id[%s], status[%d]", task.TaskID, source.Status)
+		task.State = proto.MigrateStateInited
+		return mgr.redoTask(ctx, task)
+	}
+
+	migrated := &proto.MigratedVolume{
+		SourceClusterID: mgr.cfg.ClusterID,
+		SourceVid:       task.SourceVid,
+		TargetClusterID: task.TargetClusterID,
+		TargetVid:       task.TargetVid,
+		CodeMode:        task.CodeMode,
+	}
+	if err = mgr.targetCli.SetMigratedVolume(ctx, migrated); err != nil {
+		span.Errorf("set migrated volume failed: task_id[%s], err[%+v]", task.TaskID, err)
+		return err
+	}
+	if err = moveExpiringBlobs(ctx, mgr.clusterMgrCli, task.SourceVid, mgr.targetCli, task.TargetVid); err != nil {
+		span.Errorf("move expiring blobs failed: task_id[%s], err[%+v]", task.TaskID, err)
+		return err
+	}
+	// the migrated volume record keeps the target volume locked from now on
+	if err = mgr.targetCli.ReleaseVolume(ctx, task.TargetVid); err != nil {
+		span.Errorf("release target volume failed: task_id[%s], err[%+v]", task.TaskID, err)
+		return err
+	}
+
+	task.State = proto.MigrateStateFinished
+	task.MigratedCnt = ret.MigratedCnt
+	if err = mgr.clusterMgrCli.UpdateClusterMigrateTask(ctx, task); err != nil {
+		span.Errorf("update cluster migrate task failed: task_id[%s], err[%+v]", task.TaskID, err)
+		return err
+	}
+
+	mgr.setTask(task)
+	base.VolTaskLockerInst().Unlock(ctx, task.SourceVid)
+
+	span.Infof("cluster migrate task finished: task_id[%s], migrated[%d]", task.TaskID, task.MigratedCnt)
+	return nil
+}
+
+// ListTasks returns all cluster migrate tasks ordered by source vid
+func (mgr *ClusterMigrateMgr) ListTasks(ctx context.Context) []*proto.ClusterMigrateTask {
+	var tasks []*proto.ClusterMigrateTask
+	for _, task := range mgr.listTasks() {
+		tasks = append(tasks, task.(*proto.ClusterMigrateTask))
+	}
+	return tasks
+}
+
+// GetMigratedVolume returns the migrated record of volume in target cluster
+func (mgr *ClusterMigrateMgr) GetMigratedVolume(ctx context.Context, vid proto.Vid) (*proto.MigratedVolume, error) {
+	if !mgr.cfg.Configured() {
+		return nil, errClusterMigrateDisabled
+	}
+	return mgr.targetCli.GetMigratedVolume(ctx, mgr.cfg.ClusterID, vid)
+}
+
+// Stats returns progress of cluster migrate
+func (mgr *ClusterMigrateMgr) Stats() api.ClusterMigrateTasksStat {
+	stat := api.ClusterMigrateTasksStat{
+		Enable:          mgr.Enabled(),
+		TargetClusterID: mgr.cfg.TargetClusterID,
+	}
+
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+	stat.TotalVolumeCnt = len(mgr.tasks)
+	for _, info := range mgr.tasks {
+		task := info.task.(*proto.ClusterMigrateTask)
+		switch {
+		case task.State == proto.MigrateStateInited:
+			stat.PreparingCnt++
+		case task.Running() && info.leased():
+			stat.WorkerDoingCnt++
+		case task.State == proto.MigrateStateFinished:
+			stat.MigratedVolumeCnt++
+			stat.MigratedBlobCnt += task.MigratedCnt
+		}
+	}
+	stat.Drained = mgr.collected && stat.TotalVolumeCnt == stat.MigratedVolumeCnt
+	return stat
+}
@@ -0,0 +1,319 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/scheduler/base"
+	"github.com/cubefs/cubefs/blobstore/scheduler/client"
+	"github.com/cubefs/cubefs/blobstore/testing/mocks"
+)
+
+func newClusterMigrateMgr(t *testing.T) *ClusterMigrateMgr {
+	ctr := gomock.NewController(t)
+	clusterMgr := NewMockClusterMgrAPI(ctr)
+	targetMgr := NewMockClusterMgrAPI(ctr)
+	taskSwitch := mocks.NewMockSwitcher(ctr)
+	taskSwitch.EXPECT().Enabled().AnyTimes().Return(true)
+	conf := &ClusterMigrateMgrCfg{
+		ClusterID:       1,
+		TargetClusterID: 2,
+		PreparedLimit:   defaultClusterMigratePreparedLimit,
+		ListVolStep:     defaultListVolStep,
+	}
+	return NewClusterMigrateMgr(clusterMgr, targetMgr, taskSwitch, conf)
+}
+
+func TestClusterMigrateMgrNotConfigured(t *testing.T) {
+	ctx := context.Background()
+	mgr := newClusterMigrateMgr(t)
+	mgr.cfg.TargetClusterID = 0
+
+	require.NoError(t, mgr.Load())
+	mgr.Run()
+	require.False(t, mgr.Enabled())
+	_, err := mgr.AcquireTask(ctx)
+	require.ErrorIs(t, err, proto.ErrTaskEmpty)
+	_, err = mgr.GetMigratedVolume(ctx, 1)
+	require.ErrorIs(t, err, errClusterMigrateDisabled)
+	require.False(t, mgr.Stats().Enable)
+	mgr.Close()
+}
+
+func TestClusterMigrateMgrLoad(t *testing.T) {
+	ctx := context.Background()
+	mgr := newClusterMigrateMgr(t)
+	cmCli := mgr.clusterMgrCli.(*MockClusterMgrAPI)
+
+	cmCli.EXPECT().ListAllClusterMigrateTasks(any).Return(nil, errMock)
+	require.ErrorIs(t, mgr.Load(), errMock)
+
+	tasks := []*proto.ClusterMigrateTask{
+		{TaskID: "cluster_migrate-301-a", State: proto.MigrateStateInited, SourceVid: 301},
+		{TaskID: "cluster_migrate-302-b", State: proto.MigrateStatePrepared, SourceVid: 302, TargetVid: 402},
+		{TaskID: "cluster_migrate-303-c", State: proto.MigrateStateFinished, SourceVid: 303, TargetVid: 403, MigratedCnt: 10},
+	}
+	cmCli.EXPECT().ListAllClusterMigrateTasks(any).Return(tasks, nil)
+	require.NoError(t, mgr.Load())
+	require.Equal(t, 3, len(mgr.ListTasks(ctx)))
+	require.Error(t, base.VolTaskLockerInst().TryLock(ctx, 302))
+	require.NoError(t, base.VolTaskLockerInst().TryLock(ctx, 303))
+	base.VolTaskLockerInst().Unlock(ctx, 302)
+	base.VolTaskLockerInst().Unlock(ctx, 303)
+
+	stat := mgr.Stats()
+	require.True(t, stat.Enable)
+	require.Equal(t, 3, stat.TotalVolumeCnt)
+	require.Equal(t, 1, stat.PreparingCnt)
+	require.Equal(t, 1, stat.MigratedVolumeCnt)
+	require.Equal(t, 10, stat.MigratedBlobCnt)
+	require.False(t, stat.Drained)
+}
+
+func TestClusterMigrateMgrCollect(t *testing.T) {
+	ctx := context.Background()
+	mgr := newClusterMigrateMgr(t)
+	cmCli := mgr.clusterMgrCli.(*MockClusterMgrAPI)
+
+	vols := []*client.VolumeInfoSimple{
+		MockGenVolInfo(311, codemode.EC6P6, proto.VolumeStatusIdle),
+		MockGenVolInfo(312, codemode.EC6P6, proto.VolumeStatusActive),
+	}
+
+	cmCli.EXPECT().ListVolume(any, any, any).Return(nil, proto.InvalidVid, errMock)
+	require.ErrorIs(t, mgr.collectTasks(ctx), errMock)
+
+	cmCli.EXPECT().ListVolume(any, any, any).Return(vols, proto.Vid(312), nil)
+	cmCli.EXPECT().AddClusterMigrateTask(any, any).Return(nil)
+	cmCli.EXPECT().AddClusterMigrateTask(any, any).Return(errMock)
+	require.ErrorIs(t, mgr.collectTasks(ctx), errMock)
+	require.Equal(t, 1, len(mgr.ListTasks(ctx)))
+	require.False(t, mgr.Stats().Drained)
+
+	// collected volume is skipped
+	cmCli.EXPECT().ListVolume(any, any, any).Return(vols, proto.Vid(312), nil)
+	cmCli.EXPECT().ListVolume(any, any, any).Return(nil, proto.InvalidVid, nil)
+	cmCli.EXPECT().AddClusterMigrateTask(any, any).Return(nil)
+	require.NoError(t, mgr.collectTasks(ctx))
+	tasks := mgr.ListTasks(ctx)
+	require.Equal(t, 2, len(tasks))
+	require.Equal(t, proto.Vid(311), tasks[0].SourceVid)
+	require.Equal(t, proto.ClusterID(2), tasks[0].TargetClusterID)
+	require.True(t, client.ValidMigrateTask(proto.TaskTypeClusterMigrate, tasks[0].TaskID))
+	require.Equal(t, 2, mgr.Stats().PreparingCnt)
+}
+
+func TestClusterMigrateMgrLockTargetVolume(t *testing.T) {
+	ctx := context.Background()
+	mgr := newClusterMigrateMgr(t)
+	targetCli := mgr.targetCli.(*MockClusterMgrAPI)
+
+	mgr.setTask(&proto.ClusterMigrateTask{TaskID: "cluster_migrate-1-a", SourceVid: 1, TargetVid: 411})
+	used := MockGenVolInfo(412, codemode.EC6P6, proto.VolumeStatusIdle)
+	used.Used = 1
+	vols := []*client.VolumeInfoSimple{
+		MockGenVolInfo(410, codemode.EC6P10L2, proto.VolumeStatusIdle),
+		MockGenVolInfo(411, codemode.EC6P6, proto.VolumeStatusIdle),
+		used,
+		MockGenVolInfo(413, codemode.EC6P6, proto.VolumeStatusActive),
+		MockGenVolInfo(414, codemode.EC6P6, proto.VolumeStatusIdle),
+		MockGenVolInfo(415, codemode.EC6P6, proto.VolumeStatusIdle),
+		MockGenVolInfo(416, codemode.EC6P6, proto.VolumeStatusIdle),
+	}
+	written := MockGenVolInfo(415, codemode.EC6P6, proto.VolumeStatusLock)
+	written.Used = 1
+
+	targetCli.EXPECT().ListVolume(any, any, any).Return(vols, proto.Vid(416), nil)
+	// lock failed
+	targetCli.EXPECT().LockVolume(any, proto.Vid(414)).Return(errMock)
+	// written before locked
+	targetCli.EXPECT().LockVolume(any, proto.Vid(415)).Return(nil)
+	targetCli.EXPECT().ReserveVolume(any, any).Return(nil)
+	targetCli.EXPECT().GetVolumeInfo(any, proto.Vid(415)).Return(written, nil)
+	targetCli.EXPECT().ReleaseVolume(any, proto.Vid(415)).Return(nil)
+	targetCli.EXPECT().UnlockVolume(any, proto.Vid(415)).Return(nil)
+	targetCli.EXPECT().LockVolume(any, proto.Vid(416)).Return(nil)
+	targetCli.EXPECT().ReserveVolume(any, any).DoAndReturn(
+		func(_ context.Context, value *proto.ReservedVolume) error {
+			require.Equal(t, proto.Vid(416), value.Vid)
+			require.Equal(t, "cluster_migrate-1-b", value.TaskID)
+			return nil
+		})
+	targetCli.EXPECT().GetVolumeInfo(any, proto.Vid(416)).Return(
+		MockGenVolInfo(416, codemode.EC6P6, proto.VolumeStatusLock), nil)
+	vol, err := mgr.lockTargetVolume(ctx, "cluster_migrate-1-b", codemode.EC6P6)
+	require.NoError(t, err)
+	require.Equal(t, proto.Vid(416), vol.Vid)
+
+	// reserve failed
+	targetCli.EXPECT().ListVolume(any, any, any).Return(vols[6:], proto.Vid(416), nil)
+	targetCli.EXPECT().LockVolume(any, proto.Vid(416)).Return(nil)
+	targetCli.EXPECT().ReserveVolume(any, any).Return(errMock)
+	targetCli.EXPECT().UnlockVolume(any, proto.Vid(416)).Return(errMock)
+	_, err = mgr.lockTargetVolume(ctx, "cluster_migrate-1-b", codemode.EC6P6)
+	require.ErrorIs(t, err, errMock)
+
+	// unlocked by other task before reserved
+	targetCli.EXPECT().ListVolume(any, any, any).Return(vols[6:], proto.Vid(416), nil)
+	targetCli.EXPECT().ListVolume(any, any, any).Return(nil, proto.InvalidVid, nil)
+	targetCli.EXPECT().LockVolume(any, proto.Vid(416)).Return(nil)
+	targetCli.EXPECT().ReserveVolume(any, any).Return(nil)
+	targetCli.EXPECT().GetVolumeInfo(any, proto.Vid(416)).Return(
+		MockGenVolInfo(416, codemode.EC6P6, proto.VolumeStatusIdle), nil)
+	targetCli.EXPECT().ReleaseVolume(any, proto.Vid(416)).Return(nil)
+	_, err = mgr.lockTargetVolume(ctx, "cluster_migrate-1-b", codemode.EC6P6)
+	require.ErrorIs(t, err, errNoEmptyTargetVolume)
+
+	targetCli.EXPECT().ListVolume(any, any, any).Return(vols[:4], proto.Vid(413), nil)
+	targetCli.EXPECT().ListVolume(any, any, any).Return(nil, proto.InvalidVid, nil)
+	_, err = mgr.lockTargetVolume(ctx, "cluster_migrate-1-b", codemode.EC6P6)
+	require.ErrorIs(t, err, errNoEmptyTargetVolume)
+}
+
+func TestClusterMigrateMgrLifecycle(t *testing.T) {
+	ctx := context.Background()
+	mgr := newClusterMigrateMgr(t)
+	cmCli := mgr.clusterMgrCli.(*MockClusterMgrAPI)
+	targetCli := mgr.targetCli.(*MockClusterMgrAPI)
+
+	var srcVid, dstVid proto.Vid = 321, 421
+	source := MockGenVolInfo(srcVid, codemode.EC6P6, proto.VolumeStatusIdle)
+	lockedSource := MockGenVolInfo(srcVid, codemode.EC6P6, proto.VolumeStatusLock)
+	target := MockGenVolInfo(dstVid, codemode.EC6P6, proto.VolumeStatusLock)
+
+	cmCli.EXPECT().ListVolume(any, any, any).Return([]*client.VolumeInfoSimple{source}, srcVid, nil)
+	cmCli.EXPECT().ListVolume(any, any, any).Return(nil, proto.InvalidVid, nil)
+	cmCli.EXPECT().AddClusterMigrateTask(any, any).Return(nil)
+	require.NoError(t, mgr.collectTasks(ctx))
+	taskID := mgr.ListTasks(ctx)[0].TaskID
+
+	// nothing prepared
+	_, err := mgr.AcquireTask(ctx)
+	require.ErrorIs(t, err, proto.ErrTaskEmpty)
+
+	// no empty target volume
+	cmCli.EXPECT().LockVolume(any, any).Return(nil)
+	cmCli.EXPECT().GetVolumeInfo(any, any).Return(lockedSource, nil)
+	targetCli.EXPECT().ListVolume(any, any, any).Return(nil, proto.InvalidVid, nil)
+	mgr.prepareTasks()
+	require.Equal(t, 1, mgr.Stats().PreparingCnt)
+	require.NoError(t, base.VolTaskLockerInst().TryLock(ctx, srcVid))
+	base.VolTaskLockerInst().Unlock(ctx, srcVid)
+
+	// update task failed, the locked target volume is kept
+	cmCli.EXPECT().LockVolume(any, any).Times(2).Return(nil)
+	cmCli.EXPECT().GetVolumeInfo(any, any).Times(2).Return(lockedSource, nil)
+	targetCli.EXPECT().ListVolume(any, any, any).Return([]*client.VolumeInfoSimple{
+		MockGenVolInfo(dstVid, codemode.EC6P6, proto.VolumeStatusIdle),
+	}, dstVid, nil)
+	targetCli.EXPECT().LockVolume(any, any).Return(nil)
+	targetCli.EXPECT().ReserveVolume(any, any).Return(nil)
+	targetCli.EXPECT().GetVolumeInfo(any, any).Return(target, nil)
+	cmCli.EXPECT().UpdateClusterMigrateTask(any, any).Return(errMock)
+	mgr.prepareTasks()
+	cmCli.EXPECT().UpdateClusterMigrateTask(any, any).Return(nil)
+	mgr.prepareTasks()
+	require.Equal(t, 0, mgr.Stats().PreparingCnt)
+	require.Error(t, base.VolTaskLockerInst().TryLock(ctx, srcVid))
+
+	// destinations are refreshed by target cluster
+	cmCli.EXPECT().GetVolumeInfo(any, srcVid).Return(lockedSource, nil)
+	targetCli.EXPECT().GetVolumeInfo(any, dstVid).Return(target, nil)
+	task, err := mgr.AcquireTask(ctx)
+	require.NoError(t, err)
+	require.Equal(t, taskID, task.TaskID)
+	require.Equal(t, dstVid, task.TargetVid)
+	require.True(t, task.IsValid())
+	require.Equal(t, 1, mgr.Stats().WorkerDoingCnt)
+
+	// worker failed and redo, only the source volume is locked by the task
+	cmCli.EXPECT().UpdateClusterMigrateTask(any, any).Return(nil)
+	require.NoError(t, mgr.CompleteTask(ctx, &proto.ClusterMigrateRet{TaskID: taskID, MigrateErrStr: "mock"}))
+	require.Equal(t, uint8(1), mgr.ListTasks(ctx)[0].WorkerRedoCnt)
+	require.Error(t, base.VolTaskLockerInst().TryLock(ctx, srcVid))
+
+	// source volume unlocked and prepare again
+	cmCli.EXPECT().GetVolumeInfo(any, any).Return(source, nil)
+	cmCli.EXPECT().UpdateClusterMigrateTask(any, any).Return(nil)
+	require.NoError(t, mgr.CompleteTask(ctx, &proto.ClusterMigrateRet{TaskID: taskID}))
+	require.Equal(t, 1, mgr.Stats().PreparingCnt)
+	require.NoError(t, base.VolTaskLockerInst().TryLock(ctx, srcVid))
+	base.VolTaskLockerInst().Unlock(ctx, srcVid)
+
+	// target volume is kept when prepared again
+	cmCli.EXPECT().LockVolume(any, any).Return(nil)
+	cmCli.EXPECT().GetVolumeInfo(any, any).Return(lockedSource, nil)
+	cmCli.EXPECT().UpdateClusterMigrateTask(any, any).Return(nil)
+	mgr.prepareTasks()
+	require.Equal(t, dstVid, mgr.ListTasks(ctx)[0].TargetVid)
+
+	// finished
+	cmCli.EXPECT().GetVolumeInfo(any, any).Return(lockedSource, nil)
+	targetCli.EXPECT().GetVolumeInfo(any, any).Return(target, nil)
+	_, err = mgr.AcquireTask(ctx)
+	require.NoError(t, err)
+	cmCli.EXPECT().GetVolumeInfo(any, any).Return(lockedSource, nil)
+	targetCli.EXPECT().SetMigratedVolume(any, any).Return(errMock)
+	require.ErrorIs(t, mgr.CompleteTask(ctx, &proto.ClusterMigrateRet{TaskID: taskID, MigratedCnt: 5}), errMock)
+	cmCli.EXPECT().GetVolumeInfo(any, any).Return(lockedSource, nil)
+	targetCli.EXPECT().SetMigratedVolume(any, any).DoAndReturn(
+		func(_ context.Context, migrated *proto.MigratedVolume) error {
+			require.Equal(t, proto.ClusterID(1), migrated.SourceClusterID)
+			require.Equal(t, srcVid, migrated.SourceVid)
+			require.Equal(t, proto.ClusterID(2), migrated.TargetClusterID)
+			require.Equal(t, dstVid, migrated.TargetVid)
+			return nil
+		})
+	// expiry records of the source volume are moved into target cluster
+	expiring := &proto.ExpiringBlob{Vid: srcVid, MinBid: 10, Count: 2, Expire: 100}
+	cmCli.EXPECT().ListExpiringBlobs(any, "", any).Return([]*proto.ExpiringBlob{expiring}, "", nil)
+	targetCli.EXPECT().SetExpiringBlob(any, any).DoAndReturn(
+		func(_ context.Context, blob *proto.ExpiringBlob) error {
+			require.Equal(t, dstVid, blob.Vid)
+			require.Equal(t, expiring.MinBid, blob.MinBid)
+			require.Equal(t, expiring.Expire, blob.Expire)
+			return nil
+		})
+	cmCli.EXPECT().DeleteExpiringBlob(any, expiring).Return(nil)
+	targetCli.EXPECT().ReleaseVolume(any, dstVid).Return(errMock)
+	require.ErrorIs(t, mgr.CompleteTask(ctx, &proto.ClusterMigrateRet{TaskID: taskID, MigratedCnt: 5}), errMock)
+	cmCli.EXPECT().GetVolumeInfo(any, any).Return(lockedSource, nil)
+	targetCli.EXPECT().SetMigratedVolume(any, any).Return(nil)
+	cmCli.EXPECT().ListExpiringBlobs(any, "", any).Return(nil, "", nil)
+	// the reservation is released after finished
+	targetCli.EXPECT().ReleaseVolume(any, dstVid).Return(nil)
+	cmCli.EXPECT().UpdateClusterMigrateTask(any, any).Return(nil)
+	require.NoError(t, mgr.CompleteTask(ctx, &proto.ClusterMigrateRet{TaskID: taskID, MigratedCnt: 5}))
+	require.ErrorIs(t, mgr.CompleteTask(ctx, &proto.ClusterMigrateRet{TaskID: taskID}), errNoSuchClusterMigrateTask)
+	require.NoError(t, base.VolTaskLockerInst().TryLock(ctx, srcVid))
+	base.VolTaskLockerInst().Unlock(ctx, srcVid)
+
+	stat := mgr.Stats()
+	require.Equal(t, 1, stat.MigratedVolumeCnt)
+	require.Equal(t, 5, stat.MigratedBlobCnt)
+	require.True(t, stat.Drained)
+
+	targetCli.EXPECT().GetMigratedVolume(any, proto.ClusterID(1), srcVid).Return(nil, errors.New("not found"))
+	_, err = mgr.GetMigratedVolume(ctx, srcVid)
+	require.Error(t, err)
+}
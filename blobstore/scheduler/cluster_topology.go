@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"sort"
 	"sync"
 	"time"
@@ -52,6 +53,7 @@ type IClusterTopology interface {
 	GetIDCDisks(idc string) (disks []*client.DiskInfoSimple)
 	MaxFreeChunksDisk(idc string) *client.DiskInfoSimple
 	IsBrokenDisk(diskID proto.DiskID) bool
+	GetCompactedVolume(vid proto.Vid) (*proto.CompactedVolume, error)
 	IVolumeCache
 	closer.Closer
 }
@@ -175,6 +177,8 @@ type ClusterTopologyMgr struct {
 	brokenDisks     *sync.Map
 	volumeCache     IVolumeCache
 
+	compactedVolumes sync.Map // vid -> compactedTime
+
 	cfg *clusterTopologyConfig
 
 	taskStatsMgr *base.ClusterTopologyStatsMgr
@@ -277,6 +281,32 @@ func (m *ClusterTopologyMgr) LoadVolumes() error {
 	return m.volumeCache.LoadVolumes()
 }
 
+type compactedTime struct {
+	time   time.Time
+	volume *proto.CompactedVolume
+}
+
+// GetCompactedVolume returns the compacted record of volume, nil if the volume has not been compacted.
+// The record never changes once set, the result of not compacted is cached for a volume update interval.
+func (m *ClusterTopologyMgr) GetCompactedVolume(vid proto.Vid) (*proto.CompactedVolume, error) {
+	if val, ok := m.compactedVolumes.Load(vid); ok {
+		cached := val.(compactedTime)
+		if cached.volume != nil || time.Since(cached.time) < m.cfg.VolumeUpdateInterval {
+			return cached.volume, nil
+		}
+	}
+
+	compacted, err := m.clusterMgrCli.GetCompactedVolume(context.Background(), vid)
+	if err != nil {
+		if rpc.DetectStatusCode(err) != http.StatusNotFound {
+			return nil, err
+		}
+		compacted = nil
+	}
+	m.compactedVolumes.Store(vid, compactedTime{time: time.Now(), volume: compacted})
+	return compacted, nil
+}
+
 // GetIDCs returns IDCs
 func (m *ClusterTopologyMgr) GetIDCs() map[string]*IDC {
 	return m.clusterTopology.idcMap
@@ -488,6 +518,9 @@ func DoubleCheckedRun(ctx context.Context, c IClusterTopology, vid proto.Vid, ta
 	if err != nil {
 		return err
 	}
+	if volume, err = redirectCompacted(ctx, c, volume); err != nil {
+		return err
+	}
 
 	for range [3]struct{}{} {
 		taskDoneVolume, err := task(volume)
@@ -508,3 +541,29 @@ func DoubleCheckedRun(ctx context.Context, c IClusterTopology, vid proto.Vid, ta
 	}
 	return errVolumeMissmatch
 }
+
+// maxCompactedDepth the most times of a blob could be compacted into another volume
+const maxCompactedDepth = 8
+
+// redirectCompacted returns the volume which live blobs of the locked volume have
+// been compacted into, the blobs keep their bids in the target volume.
+func redirectCompacted(ctx context.Context, c IClusterTopology, volume *client.VolumeInfoSimple) (*client.VolumeInfoSimple, error) {
+	span := trace.SpanFromContextSafe(ctx)
+	for range [maxCompactedDepth]struct{}{} {
+		if volume.Status != proto.VolumeStatusLock {
+			return volume, nil
+		}
+		compacted, err := c.GetCompactedVolume(volume.Vid)
+		if err != nil {
+			return nil, err
+		}
+		if compacted == nil {
+			return volume, nil
+		}
+		span.Debugf("volume has been compacted: vid[%d] -> vid[%d]", compacted.SourceVid, compacted.TargetVid)
+		if volume, err = c.GetVolume(compacted.TargetVid); err != nil {
+			return nil, err
+		}
+	}
+	return volume, nil
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 
+	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
 	"github.com/cubefs/cubefs/blobstore/scheduler/base"
 	"github.com/cubefs/cubefs/blobstore/scheduler/client"
@@ -286,3 +287,74 @@ func BenchmarkVolumeCache(b *testing.B) {
 		})
 	}
 }
+
+func TestRedirectCompacted(t *testing.T) {
+	ctr := gomock.NewController(t)
+	ctx := context.Background()
+	locked := &client.VolumeInfoSimple{Vid: 1, Status: proto.VolumeStatusLock}
+	target := &client.VolumeInfoSimple{Vid: 2, Status: proto.VolumeStatusIdle}
+	{
+		// volume is not locked
+		c := NewMockClusterTopology(ctr)
+		volume, err := redirectCompacted(ctx, c, target)
+		require.NoError(t, err)
+		require.Equal(t, target, volume)
+	}
+	{
+		// get compacted volume failed
+		c := NewMockClusterTopology(ctr)
+		c.EXPECT().GetCompactedVolume(any).Return(nil, errMock)
+		_, err := redirectCompacted(ctx, c, locked)
+		require.ErrorIs(t, err, errMock)
+	}
+	{
+		// locked volume is not compacted
+		c := NewMockClusterTopology(ctr)
+		c.EXPECT().GetCompactedVolume(any).Return(nil, nil)
+		volume, err := redirectCompacted(ctx, c, locked)
+		require.NoError(t, err)
+		require.Equal(t, locked, volume)
+	}
+	{
+		// redirect to the target volume
+		c := NewMockClusterTopology(ctr)
+		c.EXPECT().GetVolume(proto.Vid(1)).Return(locked, nil)
+		c.EXPECT().GetCompactedVolume(proto.Vid(1)).Return(&proto.CompactedVolume{SourceVid: 1, TargetVid: 2}, nil)
+		c.EXPECT().GetVolume(proto.Vid(2)).Return(target, nil)
+		c.EXPECT().GetVolume(proto.Vid(2)).Return(target, nil)
+		err := DoubleCheckedRun(ctx, c, 1, func(info *client.VolumeInfoSimple) (*client.VolumeInfoSimple, error) {
+			require.Equal(t, proto.Vid(2), info.Vid)
+			return info, nil
+		})
+		require.NoError(t, err)
+	}
+}
+
+func TestGetCompactedVolume(t *testing.T) {
+	cmClient := NewMockClusterMgrAPI(gomock.NewController(t))
+	mgr := &ClusterTopologyMgr{
+		clusterMgrCli: cmClient,
+		cfg:           &clusterTopologyConfig{VolumeUpdateInterval: time.Hour},
+	}
+
+	cmClient.EXPECT().GetCompactedVolume(any, proto.Vid(1)).Return(nil, errMock)
+	_, err := mgr.GetCompactedVolume(1)
+	require.ErrorIs(t, err, errMock)
+
+	// not compacted is cached
+	cmClient.EXPECT().GetCompactedVolume(any, proto.Vid(1)).Return(nil, errcode.ErrNotFound)
+	compacted, err := mgr.GetCompactedVolume(1)
+	require.NoError(t, err)
+	require.Nil(t, compacted)
+	compacted, err = mgr.GetCompactedVolume(1)
+	require.NoError(t, err)
+	require.Nil(t, compacted)
+
+	cmClient.EXPECT().GetCompactedVolume(any, proto.Vid(2)).Return(&proto.CompactedVolume{SourceVid: 2, TargetVid: 3}, nil)
+	compacted, err = mgr.GetCompactedVolume(2)
+	require.NoError(t, err)
+	require.Equal(t, proto.Vid(3), compacted.TargetVid)
+	compacted, err = mgr.GetCompactedVolume(2)
+	require.NoError(t, err)
+	require.Equal(t, proto.Vid(3), compacted.TargetVid)
+}
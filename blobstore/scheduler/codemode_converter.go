@@ -0,0 +1,363 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	api "github.com/cubefs/cubefs/blobstore/api/scheduler"
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/taskswitch"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/scheduler/base"
+	"github.com/cubefs/cubefs/blobstore/scheduler/client"
+	"github.com/cubefs/cubefs/blobstore/util/closer"
+)
+
+// ICodeModeConverter define the interface of codemode convert manager
+type ICodeModeConverter interface {
+	AddTask(ctx context.Context, vid proto.Vid, mode codemode.CodeMode) (taskID string, err error)
+	AcquireTask(ctx context.Context) (*proto.CodeModeConvertTask, error)
+	RenewalTask(ctx context.Context, idc, taskID string) error
+	CompleteTask(ctx context.Context, ret *proto.CodeModeConvertRet) error
+	ListTasks(ctx context.Context) []*proto.CodeModeConvertTask
+	Stats() api.CodeModeConvertTasksStat
+	Enabled() bool
+	Load() error
+	Run()
+	closer.Closer
+}
+
+var (
+	errVolumeConverting    = errors.New("volume is converting")
+	errConvertLeaseExpired = errors.New("convert task lease expired")
+	errNoSuchConvertTask   = errors.New("no such convert task")
+)
+
+// CodeModeConvertMgrCfg codemode convert manager config
+type CodeModeConvertMgrCfg struct {
+	PrepareIntervalS int `json:"prepare_interval_s"`
+}
+
+// CodeModeConvertMgr manager of codemode convert, it re-encodes all blobs of a volume
+// into a new allocated volume with the target codemode.
+// step1.lock the source volume to be read-only, alloc a target volume and record the converting volume
+// step2.worker reads and re-encodes blobs of source volume into target volume with the same bid
+// step3.record the converted volume, so that access can rewrite locations to the target volume
+// step4.unlock the source volume
+//
+// A blob and its converted copy are deleted together by access with the converted record,
+// the blobs written into the source volume after unlocked are beyond the converted max bid.
+type CodeModeConvertMgr struct {
+	closer.Closer
+	volumeTasks
+
+	taskSwitch    taskswitch.ISwitcher
+	clusterMgrCli client.ClusterMgrAPI
+
+	cfg *CodeModeConvertMgrCfg
+}
+
+// NewCodeModeConvertMgr returns codemode convert manager
+func NewCodeModeConvertMgr(clusterMgrCli client.ClusterMgrAPI, taskSwitch taskswitch.ISwitcher,
+	cfg *CodeModeConvertMgrCfg,
+) *CodeModeConvertMgr {
+	mgr := &CodeModeConvertMgr{
+		Closer:        closer.New(),
+		taskSwitch:    taskSwitch,
+		clusterMgrCli: clusterMgrCli,
+		cfg:           cfg,
+	}
+	mgr.volumeTasks = newVolumeTasks(taskSwitch, errNoSuchConvertTask, errConvertLeaseExpired,
+		func(ctx context.Context, task proto.VolumeTask) error {
+			return clusterMgrCli.UpdateConvertTask(ctx, task.(*proto.CodeModeConvertTask))
+		},
+		func(ctx context.Context, task proto.VolumeTask) {
+			base.VolTaskLockerInst().Unlock(ctx, task.GetSourceVid())
+			base.VolTaskLockerInst().Unlock(ctx, task.GetTargetVid())
+		})
+	return mgr
+}
+
+// Load load convert tasks from clustermgr
+func (mgr *CodeModeConvertMgr) Load() error {
+	span, ctx := trace.StartSpanFromContext(context.Background(), "CodeModeConvertMgr.Load")
+
+	tasks, err := mgr.clusterMgrCli.ListAllConvertTasks(ctx)
+	if err != nil {
+		span.Errorf("list all convert tasks failed: err[%+v]", err)
+		return err
+	}
+	for _, task := range tasks {
+		if task.Running() {
+			if err = mgr.lockVolumes(ctx, task); err != nil {
+				return err
+			}
+		}
+		mgr.setTask(task)
+	}
+	span.Infof("load convert tasks success: len[%d]", len(tasks))
+	return nil
+}
+
+// Run run codemode convert manager
+func (mgr *CodeModeConvertMgr) Run() {
+	go mgr.prepareLoop()
+}
+
+// Enabled returns true if codemode convert is enabled
+func (mgr *CodeModeConvertMgr) Enabled() bool {
+	return mgr.taskSwitch.Enabled()
+}
+
+// AddTask adds a task to convert the volume into codemode
+func (mgr *CodeModeConvertMgr) AddTask(ctx context.Context, vid proto.Vid, mode codemode.CodeMode) (string, error) {
+	span := trace.SpanFromContextSafe(ctx)
+
+	mgr.mu.Lock()
+	for _, info := range mgr.tasks {
+		if info.task.GetSourceVid() == vid {
+			mgr.mu.Unlock()
+			span.Warnf("volume has been added: vid[%d], task_id[%s]", vid, info.task.GetTaskID())
+			return "", errVolumeConverting
+		}
+	}
+	mgr.mu.Unlock()
+
+	volume, err := mgr.clusterMgrCli.GetVolumeInfo(ctx, vid)
+	if err != nil {
+		span.Errorf("get volume failed: vid[%d], err[%+v]", vid, err)
+		return "", err
+	}
+	if volume.CodeMode == mode {
+		return "", errcode.ErrIllegalArguments
+	}
+
+	task := &proto.CodeModeConvertTask{
+		TaskID:         client.GenConvertTaskID(vid),
+		State:          proto.MigrateStateInited,
+		SourceVid:      vid,
+		SourceCodeMode: volume.CodeMode,
+		Sources:        volume.VunitLocations,
+		TargetCodeMode: mode,
+	}
+	if err = mgr.clusterMgrCli.AddConvertTask(ctx, task); err != nil {
+		span.Errorf("add convert task failed: task_id[%s], err[%+v]", task.TaskID, err)
+		return "", err
+	}
+
+	mgr.setTask(task)
+
+	span.Infof("add convert task success: task[%+v]", task)
+	return task.TaskID, nil
+}
+
+func (mgr *CodeModeConvertMgr) prepareLoop() {
+	ticker := time.NewTicker(time.Duration(mgr.cfg.PrepareIntervalS) * time.Second)
+	defer ticker.Stop()
+	for {
+		mgr.taskSwitch.WaitEnable()
+		select {
+		case <-ticker.C:
+			mgr.prepareTasks()
+		case <-mgr.Done():
+			return
+		}
+	}
+}
+
+func (mgr *CodeModeConvertMgr) prepareTasks() {
+	for _, inited := range mgr.initedTasks() {
+		task := inited.(*proto.CodeModeConvertTask)
+		span, ctx := trace.StartSpanFromContext(context.Background(), "CodeModeConvertMgr.prepare")
+		if err := mgr.prepareTask(ctx, task); err != nil {
+			span.Warnf("prepare convert task failed: task_id[%s], err[%+v]", task.TaskID, err)
+		}
+	}
+}
+
+// prepareTask locks the source volume to be read-only and allocates the target volume.
+func (mgr *CodeModeConvertMgr) prepareTask(ctx context.Context, task *proto.CodeModeConvertTask) (err error) {
+	span := trace.SpanFromContextSafe(ctx)
+
+	if err = base.VolTaskLockerInst().TryLock(ctx, task.SourceVid); err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			base.VolTaskLockerInst().Unlock(ctx, task.SourceVid)
+		}
+	}()
+
+	if err = mgr.clusterMgrCli.LockVolume(ctx, task.SourceVid); err != nil {
+		return
+	}
+	source, err := mgr.clusterMgrCli.GetVolumeInfo(ctx, task.SourceVid)
+	if err != nil {
+		return
+	}
+
+	// keep the allocated target volume in memory, in case of updating convert task failed
+	if task.TargetVid == proto.InvalidVid {
+		target, errAlloc := mgr.clusterMgrCli.AllocVolume(ctx, task.TargetCodeMode)
+		if errAlloc != nil {
+			return errAlloc
+		}
+		task.TargetVid = target.Vid
+		task.Destinations = target.VunitLocations
+		mgr.setTask(task.Copy())
+	}
+	if err = base.VolTaskLockerInst().TryLock(ctx, task.TargetVid); err != nil {
+		return
+	}
+	// the blobs deleted when converting are deleted from the target volume as well
+	converting := &proto.ConvertedVolume{
+		SourceVid:      task.SourceVid,
+		TargetVid:      task.TargetVid,
+		TargetCodeMode: task.TargetCodeMode,
+		Converting:     true,
+	}
+	if err = mgr.clusterMgrCli.SetConvertedVolume(ctx, converting); err != nil {
+		base.VolTaskLockerInst().Unlock(ctx, task.TargetVid)
+		return
+	}
+
+	task.Sources = source.VunitLocations
+	task.State = proto.MigrateStatePrepared
+	if err = mgr.clusterMgrCli.UpdateConvertTask(ctx, task); err != nil {
+		base.VolTaskLockerInst().Unlock(ctx, task.TargetVid)
+		return
+	}
+
+	mgr.setTask(task)
+	span.Infof("prepare convert task success: task_id[%s], vid[%d] -> vid[%d]", task.TaskID, task.SourceVid, task.TargetVid)
+	return nil
+}
+
+// AcquireTask acquires a prepared task which is not running on any worker
+func (mgr *CodeModeConvertMgr) AcquireTask(ctx context.Context) (*proto.CodeModeConvertTask, error) {
+	task, err := mgr.acquireTask(ctx, mgr.clusterMgrCli, mgr.clusterMgrCli)
+	if err != nil {
+		return nil, err
+	}
+	return task.(*proto.CodeModeConvertTask), nil
+}
+
+// CompleteTask finishes the task if worker converted all blobs,
+// otherwise the task will be acquired again.
+func (mgr *CodeModeConvertMgr) CompleteTask(ctx context.Context, ret *proto.CodeModeConvertRet) error {
+	span := trace.SpanFromContextSafe(ctx)
+
+	running, err := mgr.runningTask(ret.TaskID)
+	if err != nil {
+		return err
+	}
+	task := running.(*proto.CodeModeConvertTask)
+
+	if err = ret.Err(); err != nil {
+		span.Warnf("worker convert failed and redo: task_id[%s], err[%+v]", ret.TaskID, err)
+		task.WorkerRedoCnt++
+		return mgr.redoTask(ctx, task)
+	}
+
+	// blobs may be written if the source volume was unlocked when converting
+	source, err := mgr.clusterMgrCli.GetVolumeInfo(ctx, task.SourceVid)
+	if err != nil {
+		return err
+	}
+	if source.Status != proto.VolumeStatusLock {
+		span.Warnf("source volume is not locked and redo: task_id[%s], status[%d]", task.TaskID, source.Status)
+		task.State = proto.MigrateStateInited
+		return mgr.redoTask(ctx, task)
+	}
+
+	sort.Slice(ret.SkippedBids, func(i, j int) bool { return ret.SkippedBids[i] < ret.SkippedBids[j] })
+	converted := &proto.ConvertedVolume{
+		SourceVid:      task.SourceVid,
+		TargetVid:      task.TargetVid,
+		TargetCodeMode: task.TargetCodeMode,
+		MaxBid:         ret.MaxBid,
+		SkippedBids:    ret.SkippedBids,
+	}
+	if err = mgr.clusterMgrCli.SetConvertedVolume(ctx, converted); err != nil {
+		span.Errorf("set converted volume failed: task_id[%s], err[%+v]", task.TaskID, err)
+		return err
+	}
+	// blobs written after unlocked are beyond the max bid, they are not rewritten to the target volume
+	if err = unlockVolume(ctx, mgr.clusterMgrCli, task.SourceVid); err != nil {
+		span.Errorf("unlock source volume failed: task_id[%s], err[%+v]", task.TaskID, err)
+		return err
+	}
+
+	task.State = proto.MigrateStateFinished
+	task.ConvertedCnt = ret.ConvertedCnt
+	task.SkippedBids = ret.SkippedBids
+	if err = mgr.clusterMgrCli.UpdateConvertTask(ctx, task); err != nil {
+		span.Errorf("update convert task failed: task_id[%s], err[%+v]", task.TaskID, err)
+		return err
+	}
+
+	mgr.setTask(task)
+	base.VolTaskLockerInst().Unlock(ctx, task.SourceVid)
+	base.VolTaskLockerInst().Unlock(ctx, task.TargetVid)
+
+	span.Infof("convert task finished: task_id[%s], converted[%d], skipped[%d]",
+		task.TaskID, task.ConvertedCnt, len(task.SkippedBids))
+	return nil
+}
+
+func (mgr *CodeModeConvertMgr) lockVolumes(ctx context.Context, task *proto.CodeModeConvertTask) error {
+	if err := base.VolTaskLockerInst().TryLock(ctx, task.SourceVid); err != nil {
+		return err
+	}
+	if err := base.VolTaskLockerInst().TryLock(ctx, task.TargetVid); err != nil {
+		base.VolTaskLockerInst().Unlock(ctx, task.SourceVid)
+		return err
+	}
+	return nil
+}
+
+// ListTasks returns all convert tasks ordered by source vid
+func (mgr *CodeModeConvertMgr) ListTasks(ctx context.Context) []*proto.CodeModeConvertTask {
+	var tasks []*proto.CodeModeConvertTask
+	for _, task := range mgr.listTasks() {
+		tasks = append(tasks, task.(*proto.CodeModeConvertTask))
+	}
+	return tasks
+}
+
+// Stats returns stats of convert tasks
+func (mgr *CodeModeConvertMgr) Stats() api.CodeModeConvertTasksStat {
+	stat := api.CodeModeConvertTasksStat{Enable: mgr.taskSwitch.Enabled()}
+
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+	for _, info := range mgr.tasks {
+		switch {
+		case info.task.GetState() == proto.MigrateStateInited:
+			stat.PreparingCnt++
+		case info.task.Running() && info.leased():
+			stat.WorkerDoingCnt++
+		case info.task.GetState() == proto.MigrateStateFinished:
+			stat.FinishedCnt++
+		}
+	}
+	return stat
+}
@@ -0,0 +1,210 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/scheduler/base"
+	"github.com/cubefs/cubefs/blobstore/testing/mocks"
+)
+
+func newConvertMgr(t *testing.T) *CodeModeConvertMgr {
+	ctr := gomock.NewController(t)
+	clusterMgr := NewMockClusterMgrAPI(ctr)
+	taskSwitch := mocks.NewMockSwitcher(ctr)
+	taskSwitch.EXPECT().Enabled().AnyTimes().Return(true)
+	conf := &CodeModeConvertMgrCfg{PrepareIntervalS: defaultConvertPrepareIntervalS}
+	return NewCodeModeConvertMgr(clusterMgr, taskSwitch, conf)
+}
+
+func TestConvertMgrLoad(t *testing.T) {
+	ctx := context.Background()
+	mgr := newConvertMgr(t)
+	cmCli := mgr.clusterMgrCli.(*MockClusterMgrAPI)
+
+	cmCli.EXPECT().ListAllConvertTasks(any).Return(nil, errMock)
+	require.ErrorIs(t, mgr.Load(), errMock)
+
+	tasks := []*proto.CodeModeConvertTask{
+		{TaskID: "codemode_convert-101-a", State: proto.MigrateStateInited, SourceVid: 101},
+		{TaskID: "codemode_convert-102-b", State: proto.MigrateStatePrepared, SourceVid: 102, TargetVid: 202},
+		{TaskID: "codemode_convert-103-c", State: proto.MigrateStateFinished, SourceVid: 103, TargetVid: 203},
+	}
+	cmCli.EXPECT().ListAllConvertTasks(any).Return(tasks, nil)
+	require.NoError(t, mgr.Load())
+	require.Equal(t, 3, len(mgr.ListTasks(ctx)))
+	require.Error(t, base.VolTaskLockerInst().TryLock(ctx, 102))
+	require.Error(t, base.VolTaskLockerInst().TryLock(ctx, 202))
+	require.NoError(t, base.VolTaskLockerInst().TryLock(ctx, 103))
+	base.VolTaskLockerInst().Unlock(ctx, 102)
+	base.VolTaskLockerInst().Unlock(ctx, 202)
+	base.VolTaskLockerInst().Unlock(ctx, 103)
+
+	stat := mgr.Stats()
+	require.Equal(t, 1, stat.PreparingCnt)
+	require.Equal(t, 0, stat.WorkerDoingCnt)
+	require.Equal(t, 1, stat.FinishedCnt)
+}
+
+func TestConvertMgrAddTask(t *testing.T) {
+	ctx := context.Background()
+	mgr := newConvertMgr(t)
+	cmCli := mgr.clusterMgrCli.(*MockClusterMgrAPI)
+
+	var vid proto.Vid = 111
+	volume := MockGenVolInfo(vid, codemode.EC6P6, proto.VolumeStatusIdle)
+
+	cmCli.EXPECT().GetVolumeInfo(any, any).Return(nil, errMock)
+	_, err := mgr.AddTask(ctx, vid, codemode.EC6P10L2)
+	require.ErrorIs(t, err, errMock)
+
+	cmCli.EXPECT().GetVolumeInfo(any, any).Return(volume, nil)
+	_, err = mgr.AddTask(ctx, vid, codemode.EC6P6)
+	require.Error(t, err)
+
+	cmCli.EXPECT().GetVolumeInfo(any, any).Return(volume, nil)
+	cmCli.EXPECT().AddConvertTask(any, any).Return(errMock)
+	_, err = mgr.AddTask(ctx, vid, codemode.EC6P10L2)
+	require.ErrorIs(t, err, errMock)
+
+	cmCli.EXPECT().GetVolumeInfo(any, any).Return(volume, nil)
+	cmCli.EXPECT().AddConvertTask(any, any).Return(nil)
+	taskID, err := mgr.AddTask(ctx, vid, codemode.EC6P10L2)
+	require.NoError(t, err)
+	require.NotEmpty(t, taskID)
+
+	_, err = mgr.AddTask(ctx, vid, codemode.EC6P10L2)
+	require.ErrorIs(t, err, errVolumeConverting)
+}
+
+func TestConvertMgrLifecycle(t *testing.T) {
+	ctx := context.Background()
+	mgr := newConvertMgr(t)
+	cmCli := mgr.clusterMgrCli.(*MockClusterMgrAPI)
+
+	var srcVid, dstVid proto.Vid = 121, 221
+	source := MockGenVolInfo(srcVid, codemode.EC6P6, proto.VolumeStatusIdle)
+	lockedSource := MockGenVolInfo(srcVid, codemode.EC6P6, proto.VolumeStatusLock)
+	target := MockGenVolInfo(dstVid, codemode.EC6P10L2, proto.VolumeStatusActive)
+
+	cmCli.EXPECT().GetVolumeInfo(any, any).Return(source, nil)
+	cmCli.EXPECT().AddConvertTask(any, any).Return(nil)
+	taskID, err := mgr.AddTask(ctx, srcVid, codemode.EC6P10L2)
+	require.NoError(t, err)
+
+	// nothing prepared
+	_, err = mgr.AcquireTask(ctx)
+	require.ErrorIs(t, err, proto.ErrTaskEmpty)
+
+	// alloc target failed
+	cmCli.EXPECT().LockVolume(any, any).Return(nil)
+	cmCli.EXPECT().GetVolumeInfo(any, any).Return(lockedSource, nil)
+	cmCli.EXPECT().AllocVolume(any, any).Return(nil, errMock)
+	mgr.prepareTasks()
+	require.Equal(t, 1, mgr.Stats().PreparingCnt)
+
+	// update task failed, the allocated target volume is kept
+	cmCli.EXPECT().LockVolume(any, any).Times(2).Return(nil)
+	cmCli.EXPECT().GetVolumeInfo(any, any).Times(2).Return(lockedSource, nil)
+	cmCli.EXPECT().AllocVolume(any, any).Return(target, nil)
+	cmCli.EXPECT().SetConvertedVolume(any, any).Times(2).DoAndReturn(
+		func(_ context.Context, converting *proto.ConvertedVolume) error {
+			require.True(t, converting.Converting)
+			require.True(t, converting.MayConverted(100))
+			require.False(t, converting.Converted(100))
+			return nil
+		})
+	cmCli.EXPECT().UpdateConvertTask(any, any).Return(errMock)
+	mgr.prepareTasks()
+	cmCli.EXPECT().UpdateConvertTask(any, any).Return(nil)
+	mgr.prepareTasks()
+	require.Equal(t, 0, mgr.Stats().PreparingCnt)
+	require.Error(t, base.VolTaskLockerInst().TryLock(ctx, srcVid))
+	require.Error(t, base.VolTaskLockerInst().TryLock(ctx, dstVid))
+
+	cmCli.EXPECT().GetVolumeInfo(any, srcVid).Return(lockedSource, nil)
+	cmCli.EXPECT().GetVolumeInfo(any, dstVid).Return(target, nil)
+	task, err := mgr.AcquireTask(ctx)
+	require.NoError(t, err)
+	require.Equal(t, taskID, task.TaskID)
+	require.Equal(t, dstVid, task.TargetVid)
+	require.True(t, task.IsValid())
+	require.Equal(t, 1, mgr.Stats().WorkerDoingCnt)
+
+	// worker failed
+	cmCli.EXPECT().UpdateConvertTask(any, any).Return(nil)
+	require.NoError(t, mgr.CompleteTask(ctx, &proto.CodeModeConvertRet{TaskID: taskID, ConvertErrStr: "mock"}))
+	require.Equal(t, uint8(1), mgr.ListTasks(ctx)[0].WorkerRedoCnt)
+
+	// finished
+	ret := &proto.CodeModeConvertRet{TaskID: taskID, ConvertedCnt: 10, SkippedBids: []proto.BlobID{9, 3, 5}, MaxBid: 12}
+	cmCli.EXPECT().GetVolumeInfo(any, any).Return(lockedSource, nil)
+	cmCli.EXPECT().SetConvertedVolume(any, any).DoAndReturn(
+		func(_ context.Context, converted *proto.ConvertedVolume) error {
+			require.Equal(t, dstVid, converted.TargetVid)
+			require.False(t, converted.Converting)
+			require.True(t, converted.Skipped(5))
+			require.True(t, converted.Converted(4))
+			require.False(t, converted.Converted(13))
+			return nil
+		})
+	// the source volume is unlocked after finished
+	cmCli.EXPECT().IsReservedVolume(any, srcVid).Return(false, nil)
+	cmCli.EXPECT().UnlockVolume(any, srcVid).Return(nil)
+	cmCli.EXPECT().UpdateConvertTask(any, any).Return(nil)
+	require.NoError(t, mgr.CompleteTask(ctx, ret))
+	require.Equal(t, 1, mgr.Stats().FinishedCnt)
+	require.ErrorIs(t, mgr.CompleteTask(ctx, ret), errNoSuchConvertTask)
+
+	require.NoError(t, base.VolTaskLockerInst().TryLock(ctx, srcVid))
+	require.NoError(t, base.VolTaskLockerInst().TryLock(ctx, dstVid))
+	base.VolTaskLockerInst().Unlock(ctx, srcVid)
+	base.VolTaskLockerInst().Unlock(ctx, dstVid)
+}
+
+func TestConvertMgrSourceUnlocked(t *testing.T) {
+	ctx := context.Background()
+	mgr := newConvertMgr(t)
+	cmCli := mgr.clusterMgrCli.(*MockClusterMgrAPI)
+
+	var srcVid, dstVid proto.Vid = 131, 231
+	task := &proto.CodeModeConvertTask{
+		TaskID:         "codemode_convert-131-a",
+		State:          proto.MigrateStatePrepared,
+		SourceVid:      srcVid,
+		SourceCodeMode: codemode.EC6P6,
+		TargetVid:      dstVid,
+		TargetCodeMode: codemode.EC6P10L2,
+	}
+	cmCli.EXPECT().ListAllConvertTasks(any).Return([]*proto.CodeModeConvertTask{task}, nil)
+	require.NoError(t, mgr.Load())
+
+	cmCli.EXPECT().GetVolumeInfo(any, any).Return(MockGenVolInfo(srcVid, codemode.EC6P6, proto.VolumeStatusIdle), nil)
+	cmCli.EXPECT().UpdateConvertTask(any, any).Return(nil)
+	require.NoError(t, mgr.CompleteTask(ctx, &proto.CodeModeConvertRet{TaskID: task.TaskID}))
+	require.Equal(t, 1, mgr.Stats().PreparingCnt)
+
+	require.NoError(t, base.VolTaskLockerInst().TryLock(ctx, srcVid))
+	require.NoError(t, base.VolTaskLockerInst().TryLock(ctx, dstVid))
+	base.VolTaskLockerInst().Unlock(ctx, srcVid)
+	base.VolTaskLockerInst().Unlock(ctx, dstVid)
+}
@@ -44,6 +44,21 @@ const (
 	defaultInspectBatch      = 1000
 	defaultInspectTimeoutMs  = 10000
 
+	defaultConvertPrepareIntervalS = 10
+
+	defaultClusterMigrateCollectIntervalS = 300
+	defaultClusterMigratePrepareIntervalS = 10
+	defaultClusterMigratePreparedLimit    = 10
+
+	defaultVolumeCompactCollectIntervalS = 600
+	defaultVolumeCompactPrepareIntervalS = 10
+	defaultVolumeCompactPreparedLimit    = 5
+	defaultVolumeCompactLiveRatio        = 0.3
+	defaultVolumeCompactFreeRatio        = 0.1
+
+	defaultBlobExpireIntervalS = 60
+	defaultListBlobStep        = 100
+
 	defaultTaskPoolSize           = 10
 	defaultDeleteHourRangeTo      = 24
 	defaultMessagePunishThreshold = 3
@@ -67,6 +82,10 @@ const (
 
 	defaultBlobDeleteNormalTopic = "blob_delete"
 	defaultBlobDeleteFailedTopic = "blob_delete_failed"
+
+	// mq of kafka or raft mq embedded in proxies, topics are the same
+	mqTypeKafka = "kafka"
+	mqTypeRaft  = "raft"
 )
 
 // Config service config
@@ -85,13 +104,19 @@ type Config struct {
 	Blobnode   blobnode.Config   `json:"blobnode"`
 	Scheduler  scheduler.Config  `json:"scheduler"`
 
-	Balance       BalanceMgrConfig    `json:"balance"`
-	DiskDrop      DropMgrConfig       `json:"disk_drop"`
-	DiskRepair    MigrateConfig       `json:"disk_repair"`
-	ManualMigrate MigrateConfig       `json:"manual_migrate"`
-	VolumeInspect VolumeInspectMgrCfg `json:"volume_inspect"`
-	TaskLog       recordlog.Config    `json:"task_log"`
-
+	Balance         BalanceMgrConfig      `json:"balance"`
+	DiskDrop        DropMgrConfig         `json:"disk_drop"`
+	DiskRepair      MigrateConfig         `json:"disk_repair"`
+	VolumeRisk      VolumeRiskConfig      `json:"volume_risk"`
+	ManualMigrate   MigrateConfig         `json:"manual_migrate"`
+	VolumeInspect   VolumeInspectMgrCfg   `json:"volume_inspect"`
+	CodeModeConvert CodeModeConvertMgrCfg `json:"codemode_convert"`
+	ClusterMigrate  ClusterMigrateMgrCfg  `json:"cluster_migrate"`
+	VolumeCompact   VolumeCompactMgrCfg   `json:"volume_compact"`
+	BlobExpire      BlobExpireMgrCfg      `json:"blob_expire"`
+	TaskLog         recordlog.Config      `json:"task_log"`
+
+	MQType      string            `json:"mq_type"`
 	Kafka       KafkaConfig       `json:"kafka"`
 	ShardRepair ShardRepairConfig `json:"shard_repair"`
 	BlobDelete  BlobDeleteConfig  `json:"blob_delete"`
@@ -187,14 +212,27 @@ func (c *Config) fixConfig() (err error) {
 	defaulter.LessOrEqual(&c.VolumeCacheUpdateIntervalS, defaultVolumeCacheUpdateIntervalS)
 	defaulter.LessOrEqual(&c.TaskLog.ChunkBits, defaultDeleteLogChunkSize)
 	c.fixClientConfig()
+	defaulter.Empty(&c.MQType, mqTypeKafka)
+	if c.MQType != mqTypeKafka && c.MQType != mqTypeRaft {
+		return errInvalidMQType
+	}
 	if err := c.fixKafkaConfig(); err != nil {
 		return errInvalidKafka
 	}
 	c.fixBalanceConfig()
 	c.fixDiskDropConfig()
+	c.VolumeRisk.CheckAndFix()
 	c.fixDiskRepairConfig()
 	c.fixManualMigrateConfig()
 	c.fixInspectConfig()
+	c.fixConvertConfig()
+	if err := c.fixClusterMigrateConfig(); err != nil {
+		return err
+	}
+	if err := c.fixVolumeCompactConfig(); err != nil {
+		return err
+	}
+	c.fixBlobExpireConfig()
 	c.fixShardRepairConfig()
 	if err := c.fixBlobDeleteConfig(); err != nil {
 		return err
@@ -247,6 +285,7 @@ func (c *Config) fixDiskDropConfig() {
 func (c *Config) fixDiskRepairConfig() {
 	c.DiskRepair.ClusterID = c.ClusterID
 	c.DiskRepair.CheckAndFix()
+	c.DiskRepair.VolumeRisk = c.VolumeRisk
 }
 
 func (c *Config) fixManualMigrateConfig() {
@@ -265,12 +304,47 @@ func (c *Config) fixInspectConfig() {
 	defaulter.LessOrEqual(&c.VolumeInspect.InspectIntervalS, defaultInspectIntervalS)
 }
 
+func (c *Config) fixConvertConfig() {
+	defaulter.LessOrEqual(&c.CodeModeConvert.PrepareIntervalS, defaultConvertPrepareIntervalS)
+}
+
+func (c *Config) fixClusterMigrateConfig() error {
+	if c.ClusterMigrate.TargetClusterID == c.ClusterID {
+		return errInvalidTargetCluster
+	}
+	c.ClusterMigrate.ClusterID = c.ClusterID
+	defaulter.LessOrEqual(&c.ClusterMigrate.CollectIntervalS, defaultClusterMigrateCollectIntervalS)
+	defaulter.LessOrEqual(&c.ClusterMigrate.PrepareIntervalS, defaultClusterMigratePrepareIntervalS)
+	defaulter.LessOrEqual(&c.ClusterMigrate.PreparedLimit, defaultClusterMigratePreparedLimit)
+	defaulter.LessOrEqual(&c.ClusterMigrate.ListVolStep, defaultListVolStep)
+	return nil
+}
+
+func (c *Config) fixVolumeCompactConfig() error {
+	if c.VolumeCompact.LiveRatio >= 1 || c.VolumeCompact.FreeRatio >= 1 {
+		return errInvalidCompactRatio
+	}
+	defaulter.LessOrEqual(&c.VolumeCompact.CollectIntervalS, defaultVolumeCompactCollectIntervalS)
+	defaulter.LessOrEqual(&c.VolumeCompact.PrepareIntervalS, defaultVolumeCompactPrepareIntervalS)
+	defaulter.LessOrEqual(&c.VolumeCompact.PreparedLimit, defaultVolumeCompactPreparedLimit)
+	defaulter.LessOrEqual(&c.VolumeCompact.ListVolStep, defaultListVolStep)
+	defaulter.LessOrEqual(&c.VolumeCompact.LiveRatio, defaultVolumeCompactLiveRatio)
+	defaulter.LessOrEqual(&c.VolumeCompact.FreeRatio, defaultVolumeCompactFreeRatio)
+	return nil
+}
+
+func (c *Config) fixBlobExpireConfig() {
+	defaulter.LessOrEqual(&c.BlobExpire.ExpireIntervalS, defaultBlobExpireIntervalS)
+	defaulter.LessOrEqual(&c.BlobExpire.ListBlobStep, defaultListBlobStep)
+}
+
 func (c *Config) fixShardRepairConfig() {
 	c.ShardRepair.ClusterID = c.ClusterID
 	defaulter.LessOrEqual(&c.ShardRepair.TaskPoolSize, defaultTaskPoolSize)
 	defaulter.LessOrEqual(&c.ShardRepair.OrphanShardLog.ChunkBits, defaultDeleteLogChunkSize)
 	defaulter.LessOrEqual(&c.ShardRepair.MessagePunishThreshold, defaultMessagePunishThreshold)
 	defaulter.LessOrEqual(&c.ShardRepair.MessagePunishTimeM, defaultMessagePunishTimeM)
+	c.ShardRepair.VolumeRisk = c.VolumeRisk
 	c.ShardRepair.Kafka.FailMsgSenderTimeoutMs = c.Kafka.FailMsgSenderTimeoutMs
 	c.ShardRepair.Kafka.BrokerList = c.Kafka.BrokerList
 	c.ShardRepair.Kafka.TopicNormals = c.Kafka.Topics.ShardRepair
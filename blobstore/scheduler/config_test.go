@@ -47,8 +47,14 @@ func TestConfigCheckAndFix(t *testing.T) {
 	require.Error(t, err, errInvalidKafka)
 
 	cfg.Kafka.Version = "0.10.0.0"
+	cfg.MQType = "x"
+	err = cfg.fixConfig()
+	require.ErrorIs(t, err, errInvalidMQType)
+
+	cfg.MQType = ""
 	err = cfg.fixConfig() // ok
 	require.NoError(t, err)
+	require.Equal(t, mqTypeKafka, cfg.MQType)
 
 	cfg.Kafka.Version = "2.1.0"
 	err = cfg.fixConfig() // ok
@@ -68,6 +74,20 @@ func TestConfigCheckAndFix(t *testing.T) {
 	require.Equal(t, defaultDeleteNoDelay, cfg.BlobDelete.SafeDelayTimeH)
 	require.Equal(t, defaultDeleteHourRangeTo, cfg.BlobDelete.DeleteHourRange.To)
 
+	cfg.ClusterMigrate.TargetClusterID = cfg.ClusterID
+	require.ErrorIs(t, cfg.fixConfig(), errInvalidTargetCluster)
+	cfg.ClusterMigrate.TargetClusterID = 2
+	require.NoError(t, cfg.fixConfig())
+	require.Equal(t, cfg.ClusterID, cfg.ClusterMigrate.ClusterID)
+	require.Equal(t, defaultClusterMigratePreparedLimit, cfg.ClusterMigrate.PreparedLimit)
+
+	cfg.VolumeCompact.LiveRatio = 1
+	require.ErrorIs(t, cfg.fixConfig(), errInvalidCompactRatio)
+	cfg.VolumeCompact.LiveRatio = 0
+	require.NoError(t, cfg.fixConfig())
+	require.Equal(t, defaultVolumeCompactLiveRatio, cfg.VolumeCompact.LiveRatio)
+	require.Equal(t, defaultVolumeCompactFreeRatio, cfg.VolumeCompact.FreeRatio)
+
 	testCases := []struct {
 		hourRange HourRange
 		err       error
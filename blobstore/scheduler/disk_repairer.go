@@ -44,13 +44,17 @@ type DiskRepairMgr struct {
 	repairedDisks  *migratedDisks
 	repairingDisks *migratingDisks
 
-	clusterMgrCli client.ClusterMgrAPI
+	clusterMgrCli   client.ClusterMgrAPI
+	clusterTopology IClusterTopology
 
 	taskSwitch taskswitch.ISwitcher
+	// risk of volumes in repairing
+	risks *volumeRiskTracker
 
 	// for stats
 	finishTaskCounter counter.Counter
 	taskStatsMgr      *base.TaskStatsMgr
+	repairPlanStats   *base.RepairPlanStats
 
 	hasRevised bool
 	taskLogger recordlog.Encoder
@@ -58,7 +62,8 @@ type DiskRepairMgr struct {
 }
 
 // NewDiskRepairMgr returns repair manager
-func NewDiskRepairMgr(clusterMgrCli client.ClusterMgrAPI, taskSwitch taskswitch.ISwitcher, taskLogger recordlog.Encoder, cfg *MigrateConfig) *DiskRepairMgr {
+func NewDiskRepairMgr(clusterMgrCli client.ClusterMgrAPI, clusterTopology IClusterTopology,
+	taskSwitch taskswitch.ISwitcher, taskLogger recordlog.Encoder, cfg *MigrateConfig) *DiskRepairMgr {
 	mgr := &DiskRepairMgr{
 		Closer:         closer.New(),
 		prepareQueue:   base.NewTaskQueue(time.Duration(cfg.PrepareQueueRetryDelayS) * time.Second),
@@ -68,14 +73,17 @@ func NewDiskRepairMgr(clusterMgrCli client.ClusterMgrAPI, taskSwitch taskswitch.
 		repairedDisks:  newMigratedDisks(),
 		repairingDisks: newMigratingDisks(),
 
-		clusterMgrCli: clusterMgrCli,
-		taskSwitch:    taskSwitch,
-		cfg:           cfg,
-		taskLogger:    taskLogger,
+		clusterMgrCli:   clusterMgrCli,
+		clusterTopology: clusterTopology,
+		taskSwitch:      taskSwitch,
+		risks:           newVolumeRiskTracker(cfg.VolumeRisk),
+		cfg:             cfg,
+		taskLogger:      taskLogger,
 
 		hasRevised: false,
 	}
 	mgr.taskStatsMgr = base.NewTaskStatsMgrAndRun(cfg.ClusterID, proto.TaskTypeDiskRepair, mgr)
+	mgr.repairPlanStats = base.NewRepairPlanStats(cfg.ClusterID, proto.TaskTypeDiskRepair.String())
 	return mgr
 }
 
@@ -117,9 +125,9 @@ func (mgr *DiskRepairMgr) Load() error {
 		span.Infof("load task success: task_id[%s], state[%d]", t.TaskID, t.State)
 		switch t.State {
 		case proto.MigrateStateInited:
-			mgr.prepareQueue.PushTask(t.TaskID, t)
+			mgr.prepareQueue.PushTaskWithPriority(t.TaskID, t, mgr.evalTaskPriority(ctx, t))
 		case proto.MigrateStatePrepared:
-			mgr.workQueue.AddPreparedTask(t.SourceIDC, t.TaskID, t)
+			mgr.workQueue.AddPreparedTaskWithPriority(t.SourceIDC, t.TaskID, t, mgr.evalTaskPriority(ctx, t))
 		case proto.MigrateStateWorkCompleted:
 			mgr.finishQueue.PushTask(t.TaskID, t)
 		case proto.MigrateStateFinished, proto.MigrateStateFinishedInAdvance:
@@ -355,10 +363,33 @@ func (mgr *DiskRepairMgr) initOneTask(ctx context.Context, badVuid proto.Vuid, b
 		return mgr.clusterMgrCli.AddMigrateTask(ctx, &t)
 	})
 
-	mgr.prepareQueue.PushTask(t.TaskID, &t)
+	mgr.prepareQueue.PushTaskWithPriority(t.TaskID, &t, mgr.evalTaskPriority(ctx, &t))
 	span.Infof("init repair task success %+v", t)
 }
 
+// evalTaskPriority returns priority of task by risk of volume in cache,
+// the task with unknown risk has lowest priority
+func (mgr *DiskRepairMgr) evalTaskPriority(ctx context.Context, t *proto.MigrateTask) int {
+	volInfo, err := mgr.clusterTopology.GetVolume(t.Vid())
+	if err != nil {
+		trace.SpanFromContextSafe(ctx).Warnf("get volume failed: vid[%d], err[%+v]", t.Vid(), err)
+		return lowestRiskPriority
+	}
+	mgr.evalTaskRisk(ctx, t, volInfo)
+	return mgr.risks.priority(t.TaskID)
+}
+
+func (mgr *DiskRepairMgr) evalTaskRisk(ctx context.Context, t *proto.MigrateTask, volInfo *client.VolumeInfoSimple) {
+	risk, ok := evalVolumeRisk(volInfo, nil, mgr.clusterTopology.IsBrokenDisk)
+	if !ok {
+		return
+	}
+	if mgr.risks.isAtRisk(risk) {
+		trace.SpanFromContextSafe(ctx).Warnf("volume at risk: task_id[%s], risk[%+v]", t.TaskID, risk)
+	}
+	mgr.risks.set(t.TaskID, risk)
+}
+
 func (mgr *DiskRepairMgr) prepareTaskLoop() {
 	for {
 		mgr.WaitEnable()
@@ -447,6 +478,14 @@ func (mgr *DiskRepairMgr) prepareTask(t *proto.MigrateTask) error {
 		return err
 	}
 
+	// repair task is acquired by worker in the idc of bad disk,
+	// the plan estimates shards downloaded across AZs by worker
+	plan := base.NewRepairPlan(volInfo.CodeMode, []uint8{badVuid.Index()})
+	mgr.repairPlanStats.Report(plan)
+	span.Debugf("repair task plan: task_id[%s], plan[%s], cross az shards[%d]", t.TaskID, plan.Type(), plan.CrossAZShards)
+
+	mgr.evalTaskRisk(ctx, t, volInfo)
+
 	t.CodeMode = volInfo.CodeMode
 	t.Sources = volInfo.VunitLocations
 	t.Destination = allocDstVunit.Location()
@@ -460,7 +499,7 @@ func (mgr *DiskRepairMgr) prepareTask(t *proto.MigrateTask) error {
 }
 
 func (mgr *DiskRepairMgr) sendToWorkQueue(t *proto.MigrateTask) {
-	mgr.workQueue.AddPreparedTask(t.SourceIDC, t.TaskID, t)
+	mgr.workQueue.AddPreparedTaskWithPriority(t.SourceIDC, t.TaskID, t, mgr.risks.priority(t.TaskID))
 	mgr.prepareQueue.RemoveTask(t.TaskID)
 }
 
@@ -477,6 +516,7 @@ func (mgr *DiskRepairMgr) finishTaskInAdvance(ctx context.Context, task *proto.M
 
 	mgr.finishTaskCounter.Add()
 	mgr.prepareQueue.RemoveTask(task.TaskID)
+	mgr.risks.delete(task.TaskID)
 	mgr.deletedTasks.add(task.SourceDiskID, task.TaskID)
 	base.VolTaskLockerInst().Unlock(ctx, task.Vid())
 }
@@ -552,6 +592,7 @@ func (mgr *DiskRepairMgr) finishTask(ctx context.Context, task *proto.MigrateTas
 	// 1.remove task in memory
 	// 2.release lock of volume task
 	mgr.finishQueue.RemoveTask(task.TaskID)
+	mgr.risks.delete(task.TaskID)
 
 	// add delete task and check it again
 	mgr.deletedTasks.add(task.SourceDiskID, task.TaskID)
@@ -587,7 +628,7 @@ func (mgr *DiskRepairMgr) handleUpdateVolMappingFail(ctx context.Context, task *
 		})
 
 		mgr.finishQueue.RemoveTask(task.TaskID)
-		mgr.workQueue.AddPreparedTask(task.SourceIDC, task.TaskID, task)
+		mgr.workQueue.AddPreparedTaskWithPriority(task.SourceIDC, task.TaskID, task, mgr.risks.priority(task.TaskID))
 		span.Infof("task redo again:  task_id[%v]", task.TaskID)
 		return nil
 	}
@@ -727,6 +768,17 @@ func (mgr *DiskRepairMgr) checkAndClearJunkTasks() {
 	}
 }
 
+// PreemptMigrate returns true if repair tasks of volumes at risk are pending,
+// balance, disk drop and manual migrate should give way to them
+func (mgr *DiskRepairMgr) PreemptMigrate() bool {
+	return !mgr.cfg.VolumeRisk.DisablePreempt && mgr.taskSwitch.Enabled() && mgr.risks.hasAtRisk()
+}
+
+// VolumesAtRisk returns volumes at risk in repairing
+func (mgr *DiskRepairMgr) VolumesAtRisk() api.VolumesAtRiskStat {
+	return mgr.risks.stat()
+}
+
 // AcquireTask acquire repair task
 func (mgr *DiskRepairMgr) AcquireTask(ctx context.Context, idc string) (task proto.MigrateTask, err error) {
 	if !mgr.taskSwitch.Enabled() {
@@ -825,6 +877,7 @@ func (mgr *DiskRepairMgr) RenewalTask(ctx context.Context, idc, taskID string) e
 // ReportWorkerTaskStats reports task stats
 func (mgr *DiskRepairMgr) ReportWorkerTaskStats(st *api.TaskReportArgs) {
 	mgr.taskStatsMgr.ReportWorkerTaskStats(st.TaskID, st.TaskStats, st.IncreaseDataSizeByte, st.IncreaseShardCnt)
+	mgr.taskStatsMgr.ReportCrossAZDataSize(st.TaskID, st.IncreaseCrossAZDataSizeByte)
 }
 
 // QueryTask return task statistics
@@ -50,14 +50,24 @@ func newDiskRepairer(t *testing.T) *DiskRepairMgr {
 	clusterMgr := NewMockClusterMgrAPI(ctr)
 	taskSwitch := mocks.NewMockSwitcher(ctr)
 	taskLogger := mocks.NewMockRecordLogEncoder(ctr)
+	volumes := newMockVolInfoMap()
+	clusterTopology := NewMockClusterTopology(ctr)
+	clusterTopology.EXPECT().GetVolume(any).AnyTimes().DoAndReturn(func(vid proto.Vid) (*client.VolumeInfoSimple, error) {
+		if vol, ok := volumes[vid]; ok {
+			return vol, nil
+		}
+		return nil, errMock
+	})
+	clusterTopology.EXPECT().IsBrokenDisk(any).AnyTimes().Return(false)
 	conf := &MigrateConfig{
 		TaskCommonConfig: base.TaskCommonConfig{
 			CollectTaskIntervalS: 1,
 			CheckTaskIntervalS:   1,
 			DiskConcurrency:      1,
 		},
+		VolumeRisk: VolumeRiskConfig{AtRiskFaultTolerance: 1},
 	}
-	return NewDiskRepairMgr(clusterMgr, taskSwitch, taskLogger, conf)
+	return NewDiskRepairMgr(clusterMgr, clusterTopology, taskSwitch, taskLogger, conf)
 }
 
 func TestDiskRepairerLoad(t *testing.T) {
@@ -47,6 +47,15 @@ type MMigrator interface {
 	IMigrator
 	IDisKMigrator
 	IManualMigrator
+	IDiskRepairer
+}
+
+// IDiskRepairer interface of disk repairer
+type IDiskRepairer interface {
+	IDisKMigrator
+	// PreemptMigrate returns true if repair tasks of volumes at risk are pending
+	PreemptMigrate() bool
+	VolumesAtRisk() api.VolumesAtRiskStat
 }
 
 // Migrator base interface of migrate, balancer, disk_droper, manual_migrater.
@@ -306,6 +315,7 @@ func (m *migratedDisks) list() (disks []*migratedDisk) {
 type MigrateConfig struct {
 	ClusterID proto.ClusterID `json:"-"` // fill in config.go
 	base.TaskCommonConfig
+	VolumeRisk VolumeRiskConfig `json:"-"` // fill in config.go, only for disk repair
 
 	lockFailHandleFunc lockFailFunc
 	// clear junk tasks
@@ -555,7 +565,7 @@ func (mgr *MigrateMgr) prepareTask() (err error) {
 		// 2. alloc chunk failed and VolTaskLockerInst().Unlock
 		// 3. this volume maybe execute other tasks, such as disk repair
 		// 4. then enter this branch and volume status is locked
-		err := mgr.clusterMgrCli.UnlockVolume(ctx, migTask.SourceVuid.Vid())
+		err := unlockVolume(ctx, mgr.clusterMgrCli, migTask.SourceVuid.Vid())
 		if err != nil {
 			span.Errorf("before finish in advance try unlock volume failed: vid[%d], err[%+v]",
 				migTask.SourceVuid.Vid(), err)
@@ -678,7 +688,7 @@ func (mgr *MigrateMgr) finishTask() (err error) {
 		err = nil
 	}
 
-	err = mgr.clusterMgrCli.UnlockVolume(ctx, migrateTask.SourceVuid.Vid())
+	err = unlockVolume(ctx, mgr.clusterMgrCli, migrateTask.SourceVuid.Vid())
 	if err != nil {
 		span.Errorf("unlock volume failed: err[%+v]", err)
 		return
@@ -709,6 +719,20 @@ func (mgr *MigrateMgr) finishTask() (err error) {
 	return
 }
 
+// unlockVolume unlocks the volume unless it is reserved by other task,
+// such as the target volume of cluster migrate which must keep locked.
+func unlockVolume(ctx context.Context, cli client.ClusterMgrAPI, vid proto.Vid) error {
+	reserved, err := cli.IsReservedVolume(ctx, vid)
+	if err != nil {
+		return err
+	}
+	if reserved {
+		trace.SpanFromContextSafe(ctx).Infof("volume is reserved and keep it locked: vid[%d]", vid)
+		return nil
+	}
+	return cli.UnlockVolume(ctx, vid)
+}
+
 func (mgr *MigrateMgr) updateVolumeCache(ctx context.Context, task *proto.MigrateTask) (err error) {
 	span := trace.SpanFromContextSafe(ctx)
 	span.Infof("update volume cache: vid[%d], task_id[%s]", task.SourceVuid.Vid(), task.TaskID)
@@ -1009,6 +1033,7 @@ func (mgr *MigrateMgr) QueryTask(ctx context.Context, taskID string) (*api.Migra
 // ReportWorkerTaskStats implement migrator
 func (mgr *MigrateMgr) ReportWorkerTaskStats(st *api.TaskReportArgs) {
 	mgr.taskStatsMgr.ReportWorkerTaskStats(st.TaskID, st.TaskStats, st.IncreaseDataSizeByte, st.IncreaseShardCnt)
+	mgr.taskStatsMgr.ReportCrossAZDataSize(st.TaskID, st.IncreaseCrossAZDataSizeByte)
 }
 
 // Enabled returns enable or not.
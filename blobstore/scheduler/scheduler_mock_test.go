@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/cubefs/cubefs/blobstore/scheduler (interfaces: ITaskRunner,IVolumeCache,MMigrator,IVolumeInspector,IClusterTopology)
+// Source: github.com/cubefs/cubefs/blobstore/scheduler (interfaces: ITaskRunner,IVolumeCache,MMigrator,IVolumeInspector,IClusterTopology,ICodeModeConverter,IClusterMigrator,IVolumeCompactor,IBlobExpirer)
 
 // Package scheduler is a generated GoMock package.
 package scheduler
@@ -9,6 +9,7 @@ import (
 	reflect "reflect"
 
 	scheduler "github.com/cubefs/cubefs/blobstore/api/scheduler"
+	codemode "github.com/cubefs/cubefs/blobstore/common/codemode"
 	proto "github.com/cubefs/cubefs/blobstore/common/proto"
 	client "github.com/cubefs/cubefs/blobstore/scheduler/client"
 	gomock "github.com/golang/mock/gomock"
@@ -458,6 +459,20 @@ func (mr *MockMigraterMockRecorder) Load() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Load", reflect.TypeOf((*MockMigrater)(nil).Load))
 }
 
+// PreemptMigrate mocks base method.
+func (m *MockMigrater) PreemptMigrate() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreemptMigrate")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// PreemptMigrate indicates an expected call of PreemptMigrate.
+func (mr *MockMigraterMockRecorder) PreemptMigrate() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreemptMigrate", reflect.TypeOf((*MockMigrater)(nil).PreemptMigrate))
+}
+
 // Progress mocks base method.
 func (m *MockMigrater) Progress(arg0 context.Context) ([]proto.DiskID, int, int) {
 	m.ctrl.T.Helper()
@@ -571,6 +586,20 @@ func (mr *MockMigraterMockRecorder) Stats() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockMigrater)(nil).Stats))
 }
 
+// VolumesAtRisk mocks base method.
+func (m *MockMigrater) VolumesAtRisk() scheduler.VolumesAtRiskStat {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VolumesAtRisk")
+	ret0, _ := ret[0].(scheduler.VolumesAtRiskStat)
+	return ret0
+}
+
+// VolumesAtRisk indicates an expected call of VolumesAtRisk.
+func (mr *MockMigraterMockRecorder) VolumesAtRisk() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VolumesAtRisk", reflect.TypeOf((*MockMigrater)(nil).VolumesAtRisk))
+}
+
 // WaitEnable mocks base method.
 func (m *MockMigrater) WaitEnable() {
 	m.ctrl.T.Helper()
@@ -749,6 +778,21 @@ func (mr *MockClusterTopologyMockRecorder) Done() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Done", reflect.TypeOf((*MockClusterTopology)(nil).Done))
 }
 
+// GetCompactedVolume mocks base method.
+func (m *MockClusterTopology) GetCompactedVolume(arg0 proto.Vid) (*proto.CompactedVolume, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCompactedVolume", arg0)
+	ret0, _ := ret[0].(*proto.CompactedVolume)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCompactedVolume indicates an expected call of GetCompactedVolume.
+func (mr *MockClusterTopologyMockRecorder) GetCompactedVolume(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompactedVolume", reflect.TypeOf((*MockClusterTopology)(nil).GetCompactedVolume), arg0)
+}
+
 // GetIDCDisks mocks base method.
 func (m *MockClusterTopology) GetIDCDisks(arg0 string) []*client.DiskInfoSimple {
 	m.ctrl.T.Helper()
@@ -848,3 +892,588 @@ func (mr *MockClusterTopologyMockRecorder) UpdateVolume(arg0 interface{}) *gomoc
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateVolume", reflect.TypeOf((*MockClusterTopology)(nil).UpdateVolume), arg0)
 }
+
+// MockCodeModeConverter is a mock of ICodeModeConverter interface.
+type MockCodeModeConverter struct {
+	ctrl     *gomock.Controller
+	recorder *MockCodeModeConverterMockRecorder
+}
+
+// MockCodeModeConverterMockRecorder is the mock recorder for MockCodeModeConverter.
+type MockCodeModeConverterMockRecorder struct {
+	mock *MockCodeModeConverter
+}
+
+// NewMockCodeModeConverter creates a new mock instance.
+func NewMockCodeModeConverter(ctrl *gomock.Controller) *MockCodeModeConverter {
+	mock := &MockCodeModeConverter{ctrl: ctrl}
+	mock.recorder = &MockCodeModeConverterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCodeModeConverter) EXPECT() *MockCodeModeConverterMockRecorder {
+	return m.recorder
+}
+
+// AcquireTask mocks base method.
+func (m *MockCodeModeConverter) AcquireTask(arg0 context.Context) (*proto.CodeModeConvertTask, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcquireTask", arg0)
+	ret0, _ := ret[0].(*proto.CodeModeConvertTask)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcquireTask indicates an expected call of AcquireTask.
+func (mr *MockCodeModeConverterMockRecorder) AcquireTask(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireTask", reflect.TypeOf((*MockCodeModeConverter)(nil).AcquireTask), arg0)
+}
+
+// AddTask mocks base method.
+func (m *MockCodeModeConverter) AddTask(arg0 context.Context, arg1 proto.Vid, arg2 codemode.CodeMode) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddTask", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddTask indicates an expected call of AddTask.
+func (mr *MockCodeModeConverterMockRecorder) AddTask(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTask", reflect.TypeOf((*MockCodeModeConverter)(nil).AddTask), arg0, arg1, arg2)
+}
+
+// Close mocks base method.
+func (m *MockCodeModeConverter) Close() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Close")
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockCodeModeConverterMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockCodeModeConverter)(nil).Close))
+}
+
+// CompleteTask mocks base method.
+func (m *MockCodeModeConverter) CompleteTask(arg0 context.Context, arg1 *proto.CodeModeConvertRet) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteTask", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteTask indicates an expected call of CompleteTask.
+func (mr *MockCodeModeConverterMockRecorder) CompleteTask(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteTask", reflect.TypeOf((*MockCodeModeConverter)(nil).CompleteTask), arg0, arg1)
+}
+
+// Done mocks base method.
+func (m *MockCodeModeConverter) Done() <-chan struct{} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Done")
+	ret0, _ := ret[0].(<-chan struct{})
+	return ret0
+}
+
+// Done indicates an expected call of Done.
+func (mr *MockCodeModeConverterMockRecorder) Done() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Done", reflect.TypeOf((*MockCodeModeConverter)(nil).Done))
+}
+
+// Enabled mocks base method.
+func (m *MockCodeModeConverter) Enabled() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enabled")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Enabled indicates an expected call of Enabled.
+func (mr *MockCodeModeConverterMockRecorder) Enabled() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enabled", reflect.TypeOf((*MockCodeModeConverter)(nil).Enabled))
+}
+
+// ListTasks mocks base method.
+func (m *MockCodeModeConverter) ListTasks(arg0 context.Context) []*proto.CodeModeConvertTask {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTasks", arg0)
+	ret0, _ := ret[0].([]*proto.CodeModeConvertTask)
+	return ret0
+}
+
+// ListTasks indicates an expected call of ListTasks.
+func (mr *MockCodeModeConverterMockRecorder) ListTasks(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTasks", reflect.TypeOf((*MockCodeModeConverter)(nil).ListTasks), arg0)
+}
+
+// Load mocks base method.
+func (m *MockCodeModeConverter) Load() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Load")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Load indicates an expected call of Load.
+func (mr *MockCodeModeConverterMockRecorder) Load() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Load", reflect.TypeOf((*MockCodeModeConverter)(nil).Load))
+}
+
+// RenewalTask mocks base method.
+func (m *MockCodeModeConverter) RenewalTask(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenewalTask", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenewalTask indicates an expected call of RenewalTask.
+func (mr *MockCodeModeConverterMockRecorder) RenewalTask(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenewalTask", reflect.TypeOf((*MockCodeModeConverter)(nil).RenewalTask), arg0, arg1, arg2)
+}
+
+// Run mocks base method.
+func (m *MockCodeModeConverter) Run() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Run")
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockCodeModeConverterMockRecorder) Run() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockCodeModeConverter)(nil).Run))
+}
+
+// Stats mocks base method.
+func (m *MockCodeModeConverter) Stats() scheduler.CodeModeConvertTasksStat {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(scheduler.CodeModeConvertTasksStat)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockCodeModeConverterMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockCodeModeConverter)(nil).Stats))
+}
+
+// MockClusterMigrator is a mock of IClusterMigrator interface.
+type MockClusterMigrator struct {
+	ctrl     *gomock.Controller
+	recorder *MockClusterMigratorMockRecorder
+}
+
+// MockClusterMigratorMockRecorder is the mock recorder for MockClusterMigrator.
+type MockClusterMigratorMockRecorder struct {
+	mock *MockClusterMigrator
+}
+
+// NewMockClusterMigrator creates a new mock instance.
+func NewMockClusterMigrator(ctrl *gomock.Controller) *MockClusterMigrator {
+	mock := &MockClusterMigrator{ctrl: ctrl}
+	mock.recorder = &MockClusterMigratorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClusterMigrator) EXPECT() *MockClusterMigratorMockRecorder {
+	return m.recorder
+}
+
+// AcquireTask mocks base method.
+func (m *MockClusterMigrator) AcquireTask(arg0 context.Context) (*proto.ClusterMigrateTask, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcquireTask", arg0)
+	ret0, _ := ret[0].(*proto.ClusterMigrateTask)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcquireTask indicates an expected call of AcquireTask.
+func (mr *MockClusterMigratorMockRecorder) AcquireTask(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireTask", reflect.TypeOf((*MockClusterMigrator)(nil).AcquireTask), arg0)
+}
+
+// Close mocks base method.
+func (m *MockClusterMigrator) Close() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Close")
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockClusterMigratorMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockClusterMigrator)(nil).Close))
+}
+
+// CompleteTask mocks base method.
+func (m *MockClusterMigrator) CompleteTask(arg0 context.Context, arg1 *proto.ClusterMigrateRet) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteTask", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteTask indicates an expected call of CompleteTask.
+func (mr *MockClusterMigratorMockRecorder) CompleteTask(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteTask", reflect.TypeOf((*MockClusterMigrator)(nil).CompleteTask), arg0, arg1)
+}
+
+// Done mocks base method.
+func (m *MockClusterMigrator) Done() <-chan struct{} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Done")
+	ret0, _ := ret[0].(<-chan struct{})
+	return ret0
+}
+
+// Done indicates an expected call of Done.
+func (mr *MockClusterMigratorMockRecorder) Done() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Done", reflect.TypeOf((*MockClusterMigrator)(nil).Done))
+}
+
+// Enabled mocks base method.
+func (m *MockClusterMigrator) Enabled() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enabled")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Enabled indicates an expected call of Enabled.
+func (mr *MockClusterMigratorMockRecorder) Enabled() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enabled", reflect.TypeOf((*MockClusterMigrator)(nil).Enabled))
+}
+
+// GetMigratedVolume mocks base method.
+func (m *MockClusterMigrator) GetMigratedVolume(arg0 context.Context, arg1 proto.Vid) (*proto.MigratedVolume, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMigratedVolume", arg0, arg1)
+	ret0, _ := ret[0].(*proto.MigratedVolume)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMigratedVolume indicates an expected call of GetMigratedVolume.
+func (mr *MockClusterMigratorMockRecorder) GetMigratedVolume(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMigratedVolume", reflect.TypeOf((*MockClusterMigrator)(nil).GetMigratedVolume), arg0, arg1)
+}
+
+// ListTasks mocks base method.
+func (m *MockClusterMigrator) ListTasks(arg0 context.Context) []*proto.ClusterMigrateTask {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTasks", arg0)
+	ret0, _ := ret[0].([]*proto.ClusterMigrateTask)
+	return ret0
+}
+
+// ListTasks indicates an expected call of ListTasks.
+func (mr *MockClusterMigratorMockRecorder) ListTasks(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTasks", reflect.TypeOf((*MockClusterMigrator)(nil).ListTasks), arg0)
+}
+
+// Load mocks base method.
+func (m *MockClusterMigrator) Load() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Load")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Load indicates an expected call of Load.
+func (mr *MockClusterMigratorMockRecorder) Load() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Load", reflect.TypeOf((*MockClusterMigrator)(nil).Load))
+}
+
+// RenewalTask mocks base method.
+func (m *MockClusterMigrator) RenewalTask(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenewalTask", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenewalTask indicates an expected call of RenewalTask.
+func (mr *MockClusterMigratorMockRecorder) RenewalTask(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenewalTask", reflect.TypeOf((*MockClusterMigrator)(nil).RenewalTask), arg0, arg1, arg2)
+}
+
+// Run mocks base method.
+func (m *MockClusterMigrator) Run() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Run")
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockClusterMigratorMockRecorder) Run() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockClusterMigrator)(nil).Run))
+}
+
+// Stats mocks base method.
+func (m *MockClusterMigrator) Stats() scheduler.ClusterMigrateTasksStat {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(scheduler.ClusterMigrateTasksStat)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockClusterMigratorMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockClusterMigrator)(nil).Stats))
+}
+
+// MockVolumeCompactor is a mock of IVolumeCompactor interface.
+type MockVolumeCompactor struct {
+	ctrl     *gomock.Controller
+	recorder *MockVolumeCompactorMockRecorder
+}
+
+// MockVolumeCompactorMockRecorder is the mock recorder for MockVolumeCompactor.
+type MockVolumeCompactorMockRecorder struct {
+	mock *MockVolumeCompactor
+}
+
+// NewMockVolumeCompactor creates a new mock instance.
+func NewMockVolumeCompactor(ctrl *gomock.Controller) *MockVolumeCompactor {
+	mock := &MockVolumeCompactor{ctrl: ctrl}
+	mock.recorder = &MockVolumeCompactorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVolumeCompactor) EXPECT() *MockVolumeCompactorMockRecorder {
+	return m.recorder
+}
+
+// AcquireTask mocks base method.
+func (m *MockVolumeCompactor) AcquireTask(arg0 context.Context) (*proto.VolumeCompactTask, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcquireTask", arg0)
+	ret0, _ := ret[0].(*proto.VolumeCompactTask)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcquireTask indicates an expected call of AcquireTask.
+func (mr *MockVolumeCompactorMockRecorder) AcquireTask(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireTask", reflect.TypeOf((*MockVolumeCompactor)(nil).AcquireTask), arg0)
+}
+
+// Close mocks base method.
+func (m *MockVolumeCompactor) Close() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Close")
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockVolumeCompactorMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockVolumeCompactor)(nil).Close))
+}
+
+// CompleteTask mocks base method.
+func (m *MockVolumeCompactor) CompleteTask(arg0 context.Context, arg1 *proto.VolumeCompactRet) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteTask", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteTask indicates an expected call of CompleteTask.
+func (mr *MockVolumeCompactorMockRecorder) CompleteTask(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteTask", reflect.TypeOf((*MockVolumeCompactor)(nil).CompleteTask), arg0, arg1)
+}
+
+// Done mocks base method.
+func (m *MockVolumeCompactor) Done() <-chan struct{} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Done")
+	ret0, _ := ret[0].(<-chan struct{})
+	return ret0
+}
+
+// Done indicates an expected call of Done.
+func (mr *MockVolumeCompactorMockRecorder) Done() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Done", reflect.TypeOf((*MockVolumeCompactor)(nil).Done))
+}
+
+// Enabled mocks base method.
+func (m *MockVolumeCompactor) Enabled() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enabled")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Enabled indicates an expected call of Enabled.
+func (mr *MockVolumeCompactorMockRecorder) Enabled() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enabled", reflect.TypeOf((*MockVolumeCompactor)(nil).Enabled))
+}
+
+// ListTasks mocks base method.
+func (m *MockVolumeCompactor) ListTasks(arg0 context.Context) []*proto.VolumeCompactTask {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTasks", arg0)
+	ret0, _ := ret[0].([]*proto.VolumeCompactTask)
+	return ret0
+}
+
+// ListTasks indicates an expected call of ListTasks.
+func (mr *MockVolumeCompactorMockRecorder) ListTasks(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTasks", reflect.TypeOf((*MockVolumeCompactor)(nil).ListTasks), arg0)
+}
+
+// Load mocks base method.
+func (m *MockVolumeCompactor) Load() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Load")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Load indicates an expected call of Load.
+func (mr *MockVolumeCompactorMockRecorder) Load() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Load", reflect.TypeOf((*MockVolumeCompactor)(nil).Load))
+}
+
+// RenewalTask mocks base method.
+func (m *MockVolumeCompactor) RenewalTask(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenewalTask", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenewalTask indicates an expected call of RenewalTask.
+func (mr *MockVolumeCompactorMockRecorder) RenewalTask(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenewalTask", reflect.TypeOf((*MockVolumeCompactor)(nil).RenewalTask), arg0, arg1, arg2)
+}
+
+// Run mocks base method.
+func (m *MockVolumeCompactor) Run() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Run")
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockVolumeCompactorMockRecorder) Run() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockVolumeCompactor)(nil).Run))
+}
+
+// Stats mocks base method.
+func (m *MockVolumeCompactor) Stats() scheduler.VolumeCompactTasksStat {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(scheduler.VolumeCompactTasksStat)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockVolumeCompactorMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockVolumeCompactor)(nil).Stats))
+}
+
+// MockBlobExpirer is a mock of IBlobExpirer interface.
+type MockBlobExpirer struct {
+	ctrl     *gomock.Controller
+	recorder *MockBlobExpirerMockRecorder
+}
+
+// MockBlobExpirerMockRecorder is the mock recorder for MockBlobExpirer.
+type MockBlobExpirerMockRecorder struct {
+	mock *MockBlobExpirer
+}
+
+// NewMockBlobExpirer creates a new mock instance.
+func NewMockBlobExpirer(ctrl *gomock.Controller) *MockBlobExpirer {
+	mock := &MockBlobExpirer{ctrl: ctrl}
+	mock.recorder = &MockBlobExpirerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBlobExpirer) EXPECT() *MockBlobExpirerMockRecorder {
+	return m.recorder
+}
+
+// Close mocks base method.
+func (m *MockBlobExpirer) Close() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Close")
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockBlobExpirerMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockBlobExpirer)(nil).Close))
+}
+
+// Done mocks base method.
+func (m *MockBlobExpirer) Done() <-chan struct{} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Done")
+	ret0, _ := ret[0].(<-chan struct{})
+	return ret0
+}
+
+// Done indicates an expected call of Done.
+func (mr *MockBlobExpirerMockRecorder) Done() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Done", reflect.TypeOf((*MockBlobExpirer)(nil).Done))
+}
+
+// Enabled mocks base method.
+func (m *MockBlobExpirer) Enabled() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enabled")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Enabled indicates an expected call of Enabled.
+func (mr *MockBlobExpirerMockRecorder) Enabled() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enabled", reflect.TypeOf((*MockBlobExpirer)(nil).Enabled))
+}
+
+// Run mocks base method.
+func (m *MockBlobExpirer) Run() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Run")
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockBlobExpirerMockRecorder) Run() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockBlobExpirer)(nil).Run))
+}
@@ -15,6 +15,7 @@
 package scheduler
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"net/http"
@@ -40,9 +41,14 @@ type Service struct {
 
 	balanceMgr    Migrator
 	diskDropMgr   IDisKMigrator
-	diskRepairMgr IDisKMigrator
+	diskRepairMgr IDiskRepairer
 	manualMigMgr  IManualMigrator
 	inspectMgr    IVolumeInspector
+	convertMgr    ICodeModeConverter
+
+	clusterMigrateMgr IClusterMigrator
+	volumeCompactMgr  IVolumeCompactor
+	blobExpireMgr     IBlobExpirer
 
 	shardRepairMgr  ITaskRunner
 	blobDeleteMgr   ITaskRunner
@@ -68,6 +74,23 @@ func (svr *Service) mgrByType(typ proto.TaskType) (Migrator, error) {
 	}
 }
 
+type taskRenewaler interface {
+	RenewalTask(ctx context.Context, idc, taskID string) error
+}
+
+func (svr *Service) renewalerByType(typ proto.TaskType) (taskRenewaler, error) {
+	switch typ {
+	case proto.TaskTypeCodeModeConvert:
+		return svr.convertMgr, nil
+	case proto.TaskTypeClusterMigrate:
+		return svr.clusterMigrateMgr, nil
+	case proto.TaskTypeVolumeCompact:
+		return svr.volumeCompactMgr, nil
+	default:
+		return svr.mgrByType(typ)
+	}
+}
+
 func (svr *Service) diskMgrByType(typ proto.TaskType) (IDisKMigrator, error) {
 	switch typ {
 	case proto.TaskTypeDiskDrop:
@@ -87,20 +110,31 @@ func (svr *Service) HTTPTaskAcquire(c *rpc.Context) {
 		return
 	}
 
-	// acquire task ordered: returns disk repair task first and other random
-	ctx := c.Request.Context()
+	migrateTask, err := svr.acquireTask(c.Request.Context(), args.IDC)
+	if err != nil {
+		c.RespondError(err)
+		return
+	}
+	c.RespondJSON(migrateTask)
+}
+
+// acquireTask acquire task ordered: returns disk repair task first and other random,
+// other tasks give way to repair if some volumes are at risk
+func (svr *Service) acquireTask(ctx context.Context, idc string) (proto.MigrateTask, error) {
 	migrators := []Migrator{svr.diskRepairMgr, svr.manualMigMgr, svr.diskDropMgr, svr.balanceMgr}
+	if svr.diskRepairMgr.PreemptMigrate() {
+		migrators = migrators[:1]
+	}
 	shuffledMigrators := migrators[1:]
 	rand.Shuffle(len(shuffledMigrators), func(i, j int) {
 		shuffledMigrators[i], shuffledMigrators[j] = shuffledMigrators[j], shuffledMigrators[i]
 	})
 	for _, acquire := range migrators {
-		if migrateTask, err := acquire.AcquireTask(ctx, args.IDC); err == nil {
-			c.RespondJSON(migrateTask)
-			return
+		if migrateTask, err := acquire.AcquireTask(ctx, idc); err == nil {
+			return migrateTask, nil
 		}
 	}
-	c.RespondError(errcode.ErrNothingTodo)
+	return proto.MigrateTask{}, errcode.ErrNothingTodo
 }
 
 // HTTPTaskReclaim reclaim task
@@ -210,7 +244,7 @@ func (svr *Service) HTTPTaskRenewal(c *rpc.Context) {
 	ctx := c.Request.Context()
 	typeErrors := make(map[proto.TaskType]map[string]string)
 	for typ, ids := range args.IDs {
-		renewaler, err := svr.mgrByType(typ)
+		renewaler, err := svr.renewalerByType(typ)
 		if err != nil {
 			c.RespondError(err)
 			return
@@ -341,6 +375,10 @@ func (svr *Service) HTTPStats(c *rpc.Context) {
 		MigrateTasksStat: svr.diskRepairMgr.Stats(),
 	}
 
+	// stats volumes at risk
+	volumesAtRisk := svr.diskRepairMgr.VolumesAtRisk()
+	taskStats.VolumesAtRisk = &volumesAtRisk
+
 	// stats drop tasks
 	dropDisks, totalTasksCnt, droppedTasksCnt := svr.diskDropMgr.Progress(ctx)
 	taskStats.DiskDrop = &api.DiskDropTasksStat{
@@ -370,6 +408,18 @@ func (svr *Service) HTTPStats(c *rpc.Context) {
 		TimeOutPerMin:  fmt.Sprint(timeout),
 	}
 
+	// stats codemode convert tasks
+	convertStat := svr.convertMgr.Stats()
+	taskStats.CodeModeConvert = &convertStat
+
+	// stats cluster migrate tasks
+	clusterMigrateStat := svr.clusterMigrateMgr.Stats()
+	taskStats.ClusterMigrate = &clusterMigrateStat
+
+	// stats volume compact tasks
+	volumeCompactStat := svr.volumeCompactMgr.Stats()
+	taskStats.VolumeCompact = &volumeCompactStat
+
 	c.RespondJSON(taskStats)
 }
 
@@ -392,6 +442,143 @@ func (svr *Service) HTTPManualMigrateTaskAdd(c *rpc.Context) {
 	c.RespondError(rpc.Error2HTTPError(err))
 }
 
+// HTTPConvertTaskAdd adds codemode convert task
+func (svr *Service) HTTPConvertTaskAdd(c *rpc.Context) {
+	args := new(api.AddConvertTaskArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	if !args.Valid() {
+		c.RespondError(errcode.ErrIllegalArguments)
+		return
+	}
+
+	taskID, err := svr.convertMgr.AddTask(c.Request.Context(), args.Vid, args.CodeMode)
+	if err != nil {
+		c.RespondError(rpc.Error2HTTPError(err))
+		return
+	}
+	c.RespondJSON(api.AddConvertTaskRet{TaskID: taskID})
+}
+
+// HTTPConvertTaskList returns all codemode convert tasks
+func (svr *Service) HTTPConvertTaskList(c *rpc.Context) {
+	c.RespondJSON(api.ListConvertTasksRet{Tasks: svr.convertMgr.ListTasks(c.Request.Context())})
+}
+
+// HTTPConvertAcquire acquire codemode convert task
+func (svr *Service) HTTPConvertAcquire(c *rpc.Context) {
+	task, _ := svr.convertMgr.AcquireTask(c.Request.Context())
+	if task != nil {
+		c.RespondJSON(task)
+		return
+	}
+	c.RespondError(errcode.ErrNothingTodo)
+}
+
+// HTTPConvertComplete complete codemode convert task
+func (svr *Service) HTTPConvertComplete(c *rpc.Context) {
+	args := new(proto.CodeModeConvertRet)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	if !client.ValidMigrateTask(proto.TaskTypeCodeModeConvert, args.TaskID) {
+		c.RespondError(errcode.ErrIllegalArguments)
+		return
+	}
+	c.RespondError(rpc.Error2HTTPError(svr.convertMgr.CompleteTask(c.Request.Context(), args)))
+}
+
+// HTTPConvertedVolume returns the converted record of volume
+func (svr *Service) HTTPConvertedVolume(c *rpc.Context) {
+	args := new(api.ConvertedVolumeArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	ret, err := svr.clusterMgrCli.GetConvertedVolume(c.Request.Context(), args.Vid)
+	if err != nil {
+		c.RespondError(rpc.Error2HTTPError(err))
+		return
+	}
+	c.RespondJSON(ret)
+}
+
+// HTTPClusterMigrateTaskList returns all cluster migrate tasks
+func (svr *Service) HTTPClusterMigrateTaskList(c *rpc.Context) {
+	c.RespondJSON(api.ListClusterMigrateTasksRet{Tasks: svr.clusterMigrateMgr.ListTasks(c.Request.Context())})
+}
+
+// HTTPClusterMigrateAcquire acquire cluster migrate task
+func (svr *Service) HTTPClusterMigrateAcquire(c *rpc.Context) {
+	task, _ := svr.clusterMigrateMgr.AcquireTask(c.Request.Context())
+	if task != nil {
+		c.RespondJSON(task)
+		return
+	}
+	c.RespondError(errcode.ErrNothingTodo)
+}
+
+// HTTPClusterMigrateComplete complete cluster migrate task
+func (svr *Service) HTTPClusterMigrateComplete(c *rpc.Context) {
+	args := new(proto.ClusterMigrateRet)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	if !client.ValidMigrateTask(proto.TaskTypeClusterMigrate, args.TaskID) {
+		c.RespondError(errcode.ErrIllegalArguments)
+		return
+	}
+	c.RespondError(rpc.Error2HTTPError(svr.clusterMigrateMgr.CompleteTask(c.Request.Context(), args)))
+}
+
+// HTTPMigratedVolume returns the migrated record of volume
+func (svr *Service) HTTPMigratedVolume(c *rpc.Context) {
+	args := new(api.MigratedVolumeArgs)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	ret, err := svr.clusterMigrateMgr.GetMigratedVolume(c.Request.Context(), args.Vid)
+	if err != nil {
+		c.RespondError(rpc.Error2HTTPError(err))
+		return
+	}
+	c.RespondJSON(ret)
+}
+
+// HTTPVolumeCompactTaskList returns all volume compact tasks
+func (svr *Service) HTTPVolumeCompactTaskList(c *rpc.Context) {
+	c.RespondJSON(api.ListVolumeCompactTasksRet{Tasks: svr.volumeCompactMgr.ListTasks(c.Request.Context())})
+}
+
+// HTTPVolumeCompactAcquire acquire volume compact task
+func (svr *Service) HTTPVolumeCompactAcquire(c *rpc.Context) {
+	task, _ := svr.volumeCompactMgr.AcquireTask(c.Request.Context())
+	if task != nil {
+		c.RespondJSON(task)
+		return
+	}
+	c.RespondError(errcode.ErrNothingTodo)
+}
+
+// HTTPVolumeCompactComplete complete volume compact task
+func (svr *Service) HTTPVolumeCompactComplete(c *rpc.Context) {
+	args := new(proto.VolumeCompactRet)
+	if err := c.ParseArgs(args); err != nil {
+		c.RespondError(err)
+		return
+	}
+	if !client.ValidMigrateTask(proto.TaskTypeVolumeCompact, args.TaskID) {
+		c.RespondError(errcode.ErrIllegalArguments)
+		return
+	}
+	c.RespondError(rpc.Error2HTTPError(svr.volumeCompactMgr.CompleteTask(c.Request.Context(), args)))
+}
+
 // HTTPUpdateVolume updates volume cache
 func (svr *Service) HTTPUpdateVolume(c *rpc.Context) {
 	args := new(api.UpdateVolumeArgs)
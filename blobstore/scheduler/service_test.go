@@ -25,7 +25,9 @@ import (
 
 	cmapi "github.com/cubefs/cubefs/blobstore/api/clustermgr"
 	api "github.com/cubefs/cubefs/blobstore/api/scheduler"
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
 	"github.com/cubefs/cubefs/blobstore/common/counter"
+	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
 	"github.com/cubefs/cubefs/blobstore/common/proto"
 	"github.com/cubefs/cubefs/blobstore/common/rpc"
 	"github.com/cubefs/cubefs/blobstore/scheduler/client"
@@ -55,9 +57,13 @@ func newMockService(t *testing.T) *Service {
 	manualMgr := NewMockMigrater(ctr)
 	balanceMgr := NewMockMigrater(ctr)
 	inspectorMgr := NewMockVolumeInspector(ctr)
+	convertMgr := NewMockCodeModeConverter(ctr)
+	clusterMigrateMgr := NewMockClusterMigrator(ctr)
+	volumeCompactMgr := NewMockVolumeCompactor(ctr)
 	clusterTopology := NewMockClusterTopology(ctr)
 
 	// return disk repair task
+	diskRepairMgr.EXPECT().PreemptMigrate().AnyTimes().Return(false)
 	diskRepairMgr.EXPECT().AcquireTask(any, any).Return(proto.MigrateTask{TaskType: proto.TaskTypeDiskRepair}, nil)
 
 	// reclaim repair task
@@ -118,6 +124,32 @@ func newMockService(t *testing.T) *Service {
 	// complete inspect task
 	inspectorMgr.EXPECT().CompleteInspect(any, any).Return()
 
+	// codemode convert task
+	convertMgr.EXPECT().AddTask(any, any, any).Return("codemode_convert-1-xxx", nil)
+	convertMgr.EXPECT().ListTasks(any).Return([]*proto.CodeModeConvertTask{{TaskID: "codemode_convert-1-xxx"}})
+	convertMgr.EXPECT().AcquireTask(any).Return(&proto.CodeModeConvertTask{TaskID: "codemode_convert-1-xxx"}, nil)
+	convertMgr.EXPECT().AcquireTask(any).Return(nil, proto.ErrTaskEmpty)
+	convertMgr.EXPECT().CompleteTask(any, any).Return(nil)
+	convertMgr.EXPECT().RenewalTask(any, any, any).Return(nil)
+	clusterMgrCli.EXPECT().GetConvertedVolume(any, any).Return(&proto.ConvertedVolume{SourceVid: 1, TargetVid: 2}, nil)
+	clusterMgrCli.EXPECT().GetConvertedVolume(any, any).Return(nil, errMock)
+
+	// cluster migrate task
+	clusterMigrateMgr.EXPECT().ListTasks(any).Return([]*proto.ClusterMigrateTask{{TaskID: "cluster_migrate-1"}})
+	clusterMigrateMgr.EXPECT().AcquireTask(any).Return(&proto.ClusterMigrateTask{TaskID: "cluster_migrate-1"}, nil)
+	clusterMigrateMgr.EXPECT().AcquireTask(any).Return(nil, proto.ErrTaskEmpty)
+	clusterMigrateMgr.EXPECT().CompleteTask(any, any).Return(nil)
+	clusterMigrateMgr.EXPECT().RenewalTask(any, any, any).Return(nil)
+	clusterMigrateMgr.EXPECT().GetMigratedVolume(any, any).Return(&proto.MigratedVolume{SourceVid: 1, TargetVid: 2}, nil)
+	clusterMigrateMgr.EXPECT().GetMigratedVolume(any, any).Return(nil, errMock)
+
+	// volume compact task
+	volumeCompactMgr.EXPECT().ListTasks(any).Return([]*proto.VolumeCompactTask{{TaskID: "volume_compact-1"}})
+	volumeCompactMgr.EXPECT().AcquireTask(any).Return(&proto.VolumeCompactTask{TaskID: "volume_compact-1"}, nil)
+	volumeCompactMgr.EXPECT().AcquireTask(any).Return(nil, proto.ErrTaskEmpty)
+	volumeCompactMgr.EXPECT().CompleteTask(any, any).Return(nil)
+	volumeCompactMgr.EXPECT().RenewalTask(any, any, any).Return(nil)
+
 	// volume update
 	clusterTopology.EXPECT().UpdateVolume(any).Return(&client.VolumeInfoSimple{}, nil)
 	clusterTopology.EXPECT().UpdateVolume(any).Return(nil, errMock)
@@ -132,6 +164,7 @@ func newMockService(t *testing.T) *Service {
 	diskRepairMgr.EXPECT().Stats().Return(api.MigrateTasksStat{})
 	diskRepairMgr.EXPECT().Progress(any).Return([]proto.DiskID{proto.DiskID(1)}, 0, 0)
 	diskRepairMgr.EXPECT().Enabled().Return(true)
+	diskRepairMgr.EXPECT().VolumesAtRisk().Return(api.VolumesAtRiskStat{})
 	diskDropMgr.EXPECT().Stats().Return(api.MigrateTasksStat{})
 	diskDropMgr.EXPECT().Progress(any).Return([]proto.DiskID{proto.DiskID(1)}, 0, 0)
 	diskDropMgr.EXPECT().Enabled().Return(true)
@@ -140,6 +173,9 @@ func newMockService(t *testing.T) *Service {
 	manualMgr.EXPECT().Stats().Return(api.MigrateTasksStat{})
 	inspectorMgr.EXPECT().GetTaskStats().Return([counter.SLOT]int{}, [counter.SLOT]int{})
 	inspectorMgr.EXPECT().Enabled().Return(true)
+	convertMgr.EXPECT().Stats().Return(api.CodeModeConvertTasksStat{})
+	clusterMigrateMgr.EXPECT().Stats().Return(api.ClusterMigrateTasksStat{})
+	volumeCompactMgr.EXPECT().Stats().Return(api.VolumeCompactTasksStat{})
 
 	// task detail
 	balanceMgr.EXPECT().QueryTask(any, any).Return(nil, nil)
@@ -166,6 +202,10 @@ func newMockService(t *testing.T) *Service {
 		manualMigMgr:  manualMgr,
 		diskRepairMgr: diskRepairMgr,
 		inspectMgr:    inspectorMgr,
+		convertMgr:    convertMgr,
+
+		clusterMigrateMgr: clusterMigrateMgr,
+		volumeCompactMgr:  volumeCompactMgr,
 
 		shardRepairMgr:  shardRepairMgr,
 		blobDeleteMgr:   blobDeleteMgr,
@@ -235,6 +275,15 @@ func TestServiceAPI(t *testing.T) {
 				client.GenMigrateTaskPrefix(proto.TaskTypeManualMigrate) + "2",
 				client.GenMigrateTaskPrefix(proto.TaskTypeManualMigrate) + "3",
 			},
+			proto.TaskTypeCodeModeConvert: {
+				client.GenConvertTaskID(volumeID),
+			},
+			proto.TaskTypeClusterMigrate: {
+				client.GenClusterMigrateTaskID(volumeID),
+			},
+			proto.TaskTypeVolumeCompact: {
+				client.GenVolumeCompactTaskID(volumeID),
+			},
 		},
 	})
 	require.NoError(t, err)
@@ -251,6 +300,75 @@ func TestServiceAPI(t *testing.T) {
 	// complete inspect task
 	require.NoError(t, cli.CompleteInspectTask(ctx, &proto.VolumeInspectRet{}))
 
+	// codemode convert task
+	{
+		_, err = cli.AddConvertTask(ctx, &api.AddConvertTaskArgs{Vid: volumeID})
+		require.Equal(t, 400, rpc.DetectStatusCode(err))
+		ret, err := cli.AddConvertTask(ctx, &api.AddConvertTaskArgs{Vid: volumeID, CodeMode: codemode.EC12P4})
+		require.NoError(t, err)
+		require.Equal(t, "codemode_convert-1-xxx", ret.TaskID)
+
+		tasks, err := cli.ListConvertTasks(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(tasks.Tasks))
+
+		task, err := cli.AcquireConvertTask(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "codemode_convert-1-xxx", task.TaskID)
+		_, err = cli.AcquireConvertTask(ctx)
+		require.Error(t, err)
+
+		err = cli.CompleteConvertTask(ctx, &proto.CodeModeConvertRet{TaskID: "balance-1-xxx"})
+		require.Equal(t, 400, rpc.DetectStatusCode(err))
+		require.NoError(t, cli.CompleteConvertTask(ctx, &proto.CodeModeConvertRet{TaskID: task.TaskID}))
+
+		converted, err := cli.GetConvertedVolume(ctx, volumeID)
+		require.NoError(t, err)
+		require.Equal(t, proto.Vid(2), converted.TargetVid)
+		_, err = cli.GetConvertedVolume(ctx, volumeID)
+		require.Error(t, err)
+	}
+
+	// cluster migrate task
+	{
+		tasks, err := cli.ListClusterMigrateTasks(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(tasks.Tasks))
+
+		task, err := cli.AcquireClusterMigrateTask(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "cluster_migrate-1", task.TaskID)
+		_, err = cli.AcquireClusterMigrateTask(ctx)
+		require.Error(t, err)
+
+		err = cli.CompleteClusterMigrateTask(ctx, &proto.ClusterMigrateRet{TaskID: "balance-1-xxx"})
+		require.Equal(t, 400, rpc.DetectStatusCode(err))
+		require.NoError(t, cli.CompleteClusterMigrateTask(ctx, &proto.ClusterMigrateRet{TaskID: task.TaskID}))
+
+		migrated, err := cli.GetMigratedVolume(ctx, volumeID)
+		require.NoError(t, err)
+		require.Equal(t, proto.Vid(2), migrated.TargetVid)
+		_, err = cli.GetMigratedVolume(ctx, volumeID)
+		require.Error(t, err)
+	}
+
+	// volume compact task
+	{
+		tasks, err := cli.ListVolumeCompactTasks(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(tasks.Tasks))
+
+		task, err := cli.AcquireVolumeCompactTask(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "volume_compact-1", task.TaskID)
+		_, err = cli.AcquireVolumeCompactTask(ctx)
+		require.Error(t, err)
+
+		err = cli.CompleteVolumeCompactTask(ctx, &proto.VolumeCompactRet{TaskID: "balance-1-xxx"})
+		require.Equal(t, 400, rpc.DetectStatusCode(err))
+		require.NoError(t, cli.CompleteVolumeCompactTask(ctx, &proto.VolumeCompactRet{TaskID: client.GenVolumeCompactTaskID(volumeID)}))
+	}
+
 	// volume update
 	require.NoError(t, cli.UpdateVolume(ctx, schedulerServer.URL, proto.Vid(1)))
 	require.Error(t, cli.UpdateVolume(ctx, schedulerServer.URL, proto.Vid(1)))
@@ -296,3 +414,32 @@ func TestServiceAPI(t *testing.T) {
 	})
 	require.Error(t, err)
 }
+
+func TestServiceAcquirePreempt(t *testing.T) {
+	ctr := gomock.NewController(t)
+	ctx := context.Background()
+	diskRepairMgr := NewMockMigrater(ctr)
+	balanceMgr := NewMockMigrater(ctr)
+	service := &Service{
+		ClusterID:     1,
+		leader:        true,
+		balanceMgr:    balanceMgr,
+		diskDropMgr:   balanceMgr,
+		manualMigMgr:  balanceMgr,
+		diskRepairMgr: diskRepairMgr,
+	}
+
+	// other tasks give way to repair of volumes at risk
+	diskRepairMgr.EXPECT().PreemptMigrate().Return(true)
+	diskRepairMgr.EXPECT().AcquireTask(any, any).Return(proto.MigrateTask{}, proto.ErrTaskEmpty)
+	_, err := service.acquireTask(ctx, "z0")
+	require.ErrorIs(t, err, errcode.ErrNothingTodo)
+
+	diskRepairMgr.EXPECT().PreemptMigrate().Return(false)
+	diskRepairMgr.EXPECT().AcquireTask(any, any).Return(proto.MigrateTask{}, proto.ErrTaskEmpty)
+	balanceMgr.EXPECT().AcquireTask(any, any).Return(proto.MigrateTask{}, proto.ErrTaskEmpty).Times(2)
+	balanceMgr.EXPECT().AcquireTask(any, any).Return(proto.MigrateTask{TaskType: proto.TaskTypeBalance}, nil)
+	task, err := service.acquireTask(ctx, "z0")
+	require.NoError(t, err)
+	require.Equal(t, proto.TaskTypeBalance, task.TaskType)
+}
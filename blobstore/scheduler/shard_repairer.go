@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/rand"
 	"time"
 
 	"github.com/Shopify/sarama"
@@ -71,6 +72,8 @@ type ShardRepairConfig struct {
 
 	TaskPoolSize   int              `json:"task_pool_size"`
 	OrphanShardLog recordlog.Config `json:"orphan_shard_log"`
+
+	VolumeRisk VolumeRiskConfig `json:"-"` // fill in config.go
 }
 
 func (cfg *ShardRepairConfig) topics() []string {
@@ -112,6 +115,7 @@ type ShardRepairMgr struct {
 	repairFailedCounter     prometheus.Counter
 	repairFailedCounterMin  *counter.Counter
 	errStatsDistribution    *base.ErrorStats
+	repairPlanStats         *base.RepairPlanStats
 
 	group             singleflight.Group
 	orphanShardLogger recordlog.Encoder
@@ -136,7 +140,7 @@ func NewShardRepairMgr(
 	workerSelector := selector.MakeSelector(60*1000, func() (hosts []string, err error) {
 		return clusterMgrCli.GetService(context.Background(), proto.ServiceNameBlobNode, cfg.ClusterID)
 	})
-	failMsgSender, err := base.NewMsgSender(cfg.failedProducerConfig())
+	failMsgSender, err := base.NewMsgSenderOf(kafkaClient, cfg.failedProducerConfig())
 	if err != nil {
 		return nil, err
 	}
@@ -162,6 +166,7 @@ func NewShardRepairMgr(
 		repairSuccessCounter:    base.NewCounter(cfg.ClusterID, ShardRepair, base.KindSuccess),
 		repairFailedCounter:     base.NewCounter(cfg.ClusterID, ShardRepair, base.KindFailed),
 		errStatsDistribution:    base.NewErrorStats(),
+		repairPlanStats:         base.NewRepairPlanStats(cfg.ClusterID, ShardRepair),
 		repairSuccessCounterMin: &counter.Counter{},
 		repairFailedCounterMin:  &counter.Counter{},
 
@@ -330,8 +335,9 @@ func (mgr *ShardRepairMgr) consume(ctx context.Context, repairMsg *proto.ShardRe
 	default:
 	}
 	span := trace.SpanFromContextSafe(ctx)
-	// if message retry times is greater than MessagePunishThreshold while sleep MessagePunishTimeM minutes
-	if repairMsg.Retry >= mgr.cfg.MessagePunishThreshold {
+	// if message retry times is greater than MessagePunishThreshold while sleep MessagePunishTimeM minutes,
+	// except that the blob is at risk
+	if repairMsg.Retry >= mgr.cfg.MessagePunishThreshold && !mgr.isAtRisk(ctx, repairMsg) {
 		span.Warnf("punish message for a while: until[%+v], sleep[%+v], retry[%d]",
 			time.Now().Add(mgr.punishTime), mgr.punishTime, repairMsg.Retry)
 		if ok := sleep(mgr.punishTime, consumerPause); !ok {
@@ -355,6 +361,20 @@ func (mgr *ShardRepairMgr) consume(ctx context.Context, repairMsg *proto.ShardRe
 	return shardRepairRet{status: ShardRepairStatusDone}
 }
 
+// isAtRisk returns true if the blob with bad shards is at risk
+func (mgr *ShardRepairMgr) isAtRisk(ctx context.Context, repairMsg *proto.ShardRepairMsg) bool {
+	volInfo, err := mgr.clusterTopology.GetVolume(repairMsg.Vid)
+	if err != nil {
+		return false
+	}
+	risk, ok := evalVolumeRisk(volInfo, repairMsg.BadIdx, mgr.clusterTopology.IsBrokenDisk)
+	if !ok || risk.FaultTolerance > mgr.cfg.VolumeRisk.AtRiskFaultTolerance {
+		return false
+	}
+	trace.SpanFromContextSafe(ctx).Warnf("blob at risk: bid[%d], risk[%+v]", repairMsg.Bid, risk)
+	return true
+}
+
 func (mgr *ShardRepairMgr) repairWithCheckVolConsistency(ctx context.Context, repairMsg *proto.ShardRepairMsg) error {
 	return DoubleCheckedRun(ctx, mgr.clusterTopology, repairMsg.Vid, func(info *client.VolumeInfoSimple) (*client.VolumeInfoSimple, error) {
 		return mgr.tryRepair(ctx, info, repairMsg)
@@ -389,11 +409,18 @@ func (mgr *ShardRepairMgr) repairShard(ctx context.Context, volInfo *client.Volu
 
 	span.Infof("repair shard: msg[%+v], vol info[%+v]", repairMsg, volInfo)
 
-	hosts := mgr.blobnodeSelector.GetRandomN(1)
-	if len(hosts) == 0 {
-		return volInfo, ErrBlobnodeServiceUnavailable
+	plan := base.NewRepairPlan(volInfo.CodeMode, repairMsg.BadIdx)
+	mgr.repairPlanStats.Report(plan)
+	workerHost, ok := mgr.selectWorkerInAZ(volInfo, repairMsg.BadIdx, plan.WorkerAZ)
+	if !ok {
+		hosts := mgr.blobnodeSelector.GetRandomN(1)
+		if len(hosts) == 0 {
+			return volInfo, ErrBlobnodeServiceUnavailable
+		}
+		workerHost = hosts[0]
 	}
-	workerHost := hosts[0]
+	span.Debugf("repair shard plan: plan[%s], cross az shards[%d], worker[%s]",
+		plan.Type(), plan.CrossAZShards, workerHost)
 
 	task := proto.ShardRepairTask{
 		Bid:      repairMsg.Bid,
@@ -415,6 +442,36 @@ func (mgr *ShardRepairMgr) repairShard(ctx context.Context, volInfo *client.Volu
 	return volInfo, err
 }
 
+// selectWorkerInAZ selects a random host of well volume units in az,
+// so that the worker downloads fewest shards across AZs
+func (mgr *ShardRepairMgr) selectWorkerInAZ(volInfo *client.VolumeInfoSimple, badIdxes []uint8, azIdx int) (string, bool) {
+	if azIdx < 0 {
+		return "", false
+	}
+
+	tactic := volInfo.CodeMode.Tactic()
+	hosts := make([]string, 0)
+	for idx, location := range volInfo.VunitLocations {
+		if tactic.AZIndex(idx) != azIdx || mgr.clusterTopology.IsBrokenDisk(location.DiskID) {
+			continue
+		}
+		isBad := false
+		for _, badIdx := range badIdxes {
+			if int(badIdx) == idx {
+				isBad = true
+				break
+			}
+		}
+		if !isBad {
+			hosts = append(hosts, location.Host)
+		}
+	}
+	if len(hosts) == 0 {
+		return "", false
+	}
+	return hosts[rand.Intn(len(hosts))], true
+}
+
 func (mgr *ShardRepairMgr) saveOrphanShard(ctx context.Context, repairMsg *proto.ShardRepairMsg) {
 	span := trace.SpanFromContextSafe(ctx)
 
@@ -17,6 +17,7 @@ package scheduler
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"testing"
 	"time"
@@ -44,6 +45,7 @@ func newShardRepairMgr(t *testing.T) *ShardRepairMgr {
 	clusterTopology := NewMockClusterTopology(ctr)
 	clusterTopology.EXPECT().GetVolume(any).AnyTimes().Return(&client.VolumeInfoSimple{}, nil)
 	clusterTopology.EXPECT().UpdateVolume(any).AnyTimes().Return(&client.VolumeInfoSimple{}, nil)
+	clusterTopology.EXPECT().IsBrokenDisk(any).AnyTimes().Return(false)
 
 	selector := mocks.NewMockSelector(ctr)
 	selector.EXPECT().GetRandomN(any).AnyTimes().Return([]string{"http://127.0.0.1:9600"})
@@ -79,6 +81,7 @@ func newShardRepairMgr(t *testing.T) *ShardRepairMgr {
 		repairSuccessCounter:    base.NewCounter(1, ShardRepair, base.KindSuccess),
 		repairFailedCounter:     base.NewCounter(1, ShardRepair, base.KindFailed),
 		errStatsDistribution:    base.NewErrorStats(),
+		repairPlanStats:         base.NewRepairPlanStats(1, ShardRepair),
 		repairSuccessCounterMin: &counter.Counter{},
 		repairFailedCounterMin:  &counter.Counter{},
 		cfg: &ShardRepairConfig{
@@ -275,6 +278,9 @@ func TestTryRepair(t *testing.T) {
 	{
 		// no host for shard repair
 		mgr := newShardRepairMgr(t)
+		clusterTopology := NewMockClusterTopology(ctr)
+		clusterTopology.EXPECT().IsBrokenDisk(any).AnyTimes().Return(true)
+		mgr.clusterTopology = clusterTopology
 		selector := mocks.NewMockSelector(ctr)
 		selector.EXPECT().GetRandomN(any).Return(nil)
 		mgr.blobnodeSelector = selector
@@ -297,6 +303,7 @@ func TestTryRepair(t *testing.T) {
 		mgr.blobnodeCli = blobnode
 
 		clusterTopology := NewMockClusterTopology(ctr)
+		clusterTopology.EXPECT().IsBrokenDisk(any).AnyTimes().Return(false)
 		clusterTopology.EXPECT().UpdateVolume(any).Return(volume, ErrFrequentlyUpdate)
 		mgr.clusterTopology = clusterTopology
 
@@ -312,6 +319,7 @@ func TestTryRepair(t *testing.T) {
 		mgr.blobnodeCli = blobnode
 
 		clusterTopology := NewMockClusterTopology(ctr)
+		clusterTopology.EXPECT().IsBrokenDisk(any).AnyTimes().Return(false)
 		clusterTopology.EXPECT().UpdateVolume(any).Return(volume, nil)
 		mgr.clusterTopology = clusterTopology
 
@@ -328,6 +336,7 @@ func TestTryRepair(t *testing.T) {
 		mgr.blobnodeCli = blobnode
 
 		clusterTopology := NewMockClusterTopology(ctr)
+		clusterTopology.EXPECT().IsBrokenDisk(any).AnyTimes().Return(false)
 		newVolume := MockGenVolInfo(proto.Vid(1), codemode.EC3P3, proto.VolumeStatusActive)
 		newVolume.VunitLocations[5].Vuid += 1
 		clusterTopology.EXPECT().UpdateVolume(any).Return(newVolume, nil)
@@ -339,3 +348,48 @@ func TestTryRepair(t *testing.T) {
 		require.True(t, doneVolume.EqualWith(newVolume))
 	}
 }
+
+func TestSelectWorkerInAZ(t *testing.T) {
+	ctr := gomock.NewController(t)
+	mgr := newShardRepairMgr(t)
+	clusterTopology := NewMockClusterTopology(ctr)
+	clusterTopology.EXPECT().IsBrokenDisk(any).AnyTimes().DoAndReturn(func(diskID proto.DiskID) bool {
+		// units in the local stripe of az 0 except index 16 are broken
+		return proto.Vuid(diskID).Index() != 16
+	})
+	mgr.clusterTopology = clusterTopology
+
+	volume := MockGenVolInfo(proto.Vid(1), codemode.EC6P10L2, proto.VolumeStatusActive)
+	for idx := range volume.VunitLocations {
+		volume.VunitLocations[idx].Host = fmt.Sprintf("http://127.0.0.1:%d", idx)
+	}
+
+	_, ok := mgr.selectWorkerInAZ(volume, []uint8{0}, -1)
+	require.False(t, ok)
+	host, ok := mgr.selectWorkerInAZ(volume, []uint8{0}, 0)
+	require.True(t, ok)
+	require.Equal(t, "http://127.0.0.1:16", host)
+	_, ok = mgr.selectWorkerInAZ(volume, []uint8{16}, 0)
+	require.False(t, ok)
+	_, ok = mgr.selectWorkerInAZ(volume, []uint8{0}, 1)
+	require.False(t, ok)
+}
+
+func TestShardRepairIsAtRisk(t *testing.T) {
+	ctx := context.Background()
+	ctr := gomock.NewController(t)
+	mgr := newShardRepairMgr(t)
+	mgr.cfg.VolumeRisk = VolumeRiskConfig{AtRiskFaultTolerance: 1}
+	volume := MockGenVolInfo(proto.Vid(1), codemode.EC6P6, proto.VolumeStatusActive)
+	clusterTopology := NewMockClusterTopology(ctr)
+	clusterTopology.EXPECT().GetVolume(proto.Vid(1)).AnyTimes().Return(volume, nil)
+	clusterTopology.EXPECT().GetVolume(proto.Vid(2)).Return(nil, errMock)
+	clusterTopology.EXPECT().IsBrokenDisk(any).AnyTimes().DoAndReturn(func(diskID proto.DiskID) bool {
+		return proto.Vuid(diskID).Index() < 3
+	})
+	mgr.clusterTopology = clusterTopology
+
+	require.False(t, mgr.isAtRisk(ctx, &proto.ShardRepairMsg{Vid: 2, BadIdx: []uint8{0}}))
+	require.False(t, mgr.isAtRisk(ctx, &proto.ShardRepairMsg{Vid: 1, BadIdx: []uint8{0, 3}}))
+	require.True(t, mgr.isAtRisk(ctx, &proto.ShardRepairMsg{Vid: 1, BadIdx: []uint8{3, 4}}))
+}
@@ -24,6 +24,7 @@ import (
 	"time"
 
 	cmapi "github.com/cubefs/cubefs/blobstore/api/clustermgr"
+	"github.com/cubefs/cubefs/blobstore/api/proxy"
 	api "github.com/cubefs/cubefs/blobstore/api/scheduler"
 	"github.com/cubefs/cubefs/blobstore/cmd"
 	"github.com/cubefs/cubefs/blobstore/common/config"
@@ -48,6 +49,10 @@ var (
 	errInvalidLeader    = errors.New("invalid leader")
 	errInvalidNodeID    = errors.New("invalid node_id")
 	errInvalidKafka     = errors.New("invalid kafka")
+	errInvalidMQType    = errors.New("invalid mq type")
+
+	errInvalidTargetCluster = errors.New("invalid target cluster of cluster migrate")
+	errInvalidCompactRatio  = errors.New("invalid ratio of volume compact")
 )
 
 var (
@@ -120,6 +125,9 @@ func NewService(conf *Config) (svr *Service, err error) {
 	topologyMgr := NewClusterTopologyMgr(clusterMgrCli, topoConf)
 
 	kafkaClient := base.NewKafkaConsumer(conf.Kafka.BrokerList)
+	if conf.MQType == mqTypeRaft {
+		kafkaClient = base.NewRaftMQConsumer(proxy.NewRaftMQLbClient(&conf.Proxy, cmapi.New(&conf.ClusterMgr), conf.ClusterID))
+	}
 	shardRepairMgr, err := NewShardRepairMgr(&conf.ShardRepair, topologyMgr, switchMgr, blobnodeCli, clusterMgrCli, kafkaClient)
 	if err != nil {
 		log.Errorf("new shard repair mgr: cfg[%+v], err[%w]", conf.ShardRepair, err)
@@ -150,10 +158,12 @@ func NewService(conf *Config) (svr *Service, err error) {
 		return
 	}
 
-	err = svr.NewKafkaMonitor(conf.ClusterID)
-	if err != nil {
-		log.Errorf("run kafka monitor failed: err[%w]", err)
-		return nil, err
+	if conf.MQType == mqTypeKafka {
+		err = svr.NewKafkaMonitor(conf.ClusterID)
+		if err != nil {
+			log.Errorf("run kafka monitor failed: err[%w]", err)
+			return nil, err
+		}
 	}
 
 	// all migrate manager
@@ -180,7 +190,7 @@ func NewService(conf *Config) (svr *Service, err error) {
 		return nil, err
 	}
 
-	diskRepairMgr := NewDiskRepairMgr(clusterMgrCli, diskRepairTaskSwitch, taskLogger, &conf.DiskRepair)
+	diskRepairMgr := NewDiskRepairMgr(clusterMgrCli, topologyMgr, diskRepairTaskSwitch, taskLogger, &conf.DiskRepair)
 
 	manualMigMgr := NewManualMigrateMgr(clusterMgrCli, volumeUpdater, taskLogger, &conf.ManualMigrate)
 
@@ -191,11 +201,43 @@ func NewService(conf *Config) (svr *Service, err error) {
 	}
 	inspectMgr := NewVolumeInspectMgr(clusterMgrCli, mqProxy, inspectorTaskSwitch, &conf.VolumeInspect)
 
+	convertTaskSwitch, err := switchMgr.AddSwitch(proto.TaskTypeCodeModeConvert.String())
+	if err != nil {
+		return nil, err
+	}
+	convertMgr := NewCodeModeConvertMgr(clusterMgrCli, convertTaskSwitch, &conf.CodeModeConvert)
+
+	clusterMigrateTaskSwitch, err := switchMgr.AddSwitch(proto.TaskTypeClusterMigrate.String())
+	if err != nil {
+		return nil, err
+	}
+	var targetClusterMgrCli client.ClusterMgrAPI
+	if conf.ClusterMigrate.Configured() {
+		targetClusterMgrCli = client.NewClusterMgrClient(&conf.ClusterMigrate.TargetClusterMgr)
+	}
+	clusterMigrateMgr := NewClusterMigrateMgr(clusterMgrCli, targetClusterMgrCli, clusterMigrateTaskSwitch, &conf.ClusterMigrate)
+
+	volumeCompactTaskSwitch, err := switchMgr.AddSwitch(proto.TaskTypeVolumeCompact.String())
+	if err != nil {
+		return nil, err
+	}
+	volumeCompactMgr := NewVolumeCompactMgr(clusterMgrCli, volumeCompactTaskSwitch, &conf.VolumeCompact)
+
+	blobExpireTaskSwitch, err := switchMgr.AddSwitch(proto.TaskTypeBlobExpire.String())
+	if err != nil {
+		return nil, err
+	}
+	blobExpireMgr := NewBlobExpireMgr(clusterMgrCli, mqProxy, blobExpireTaskSwitch, &conf.BlobExpire)
+
 	svr.balanceMgr = balanceMgr
 	svr.diskDropMgr = diskDropMgr
 	svr.manualMigMgr = manualMigMgr
 	svr.diskRepairMgr = diskRepairMgr
 	svr.inspectMgr = inspectMgr
+	svr.convertMgr = convertMgr
+	svr.clusterMigrateMgr = clusterMigrateMgr
+	svr.volumeCompactMgr = volumeCompactMgr
+	svr.blobExpireMgr = blobExpireMgr
 
 	err = svr.waitAndLoad()
 	if err != nil {
@@ -228,6 +270,15 @@ func (svr *Service) load() (err error) {
 	if err = svr.manualMigMgr.Load(); err != nil {
 		return
 	}
+	if err = svr.convertMgr.Load(); err != nil {
+		return
+	}
+	if err = svr.clusterMigrateMgr.Load(); err != nil {
+		return
+	}
+	if err = svr.volumeCompactMgr.Load(); err != nil {
+		return
+	}
 
 	return
 }
@@ -252,6 +303,10 @@ func (svr *Service) Run() {
 	svr.diskDropMgr.Run()
 	svr.manualMigMgr.Run()
 	svr.inspectMgr.Run()
+	svr.convertMgr.Run()
+	svr.clusterMigrateMgr.Run()
+	svr.volumeCompactMgr.Run()
+	svr.blobExpireMgr.Run()
 }
 
 // RunTask run shard repair and blob delete tasks
@@ -351,6 +406,10 @@ func (svr *Service) Close() {
 	svr.diskDropMgr.Close()
 	svr.manualMigMgr.Close()
 	svr.inspectMgr.Close()
+	svr.convertMgr.Close()
+	svr.clusterMigrateMgr.Close()
+	svr.volumeCompactMgr.Close()
+	svr.blobExpireMgr.Close()
 }
 
 // NewHandler returns app server handler
@@ -358,6 +417,8 @@ func NewHandler(service *Service) *rpc.Router {
 	rpc.RegisterArgsParser(&api.AcquireArgs{}, "json")
 	rpc.RegisterArgsParser(&api.DiskMigratingStatsArgs{}, "json")
 	rpc.RegisterArgsParser(&api.MigrateTaskDetailArgs{}, "json")
+	rpc.RegisterArgsParser(&api.ConvertedVolumeArgs{}, "json")
+	rpc.RegisterArgsParser(&api.MigratedVolumeArgs{}, "json")
 
 	// rpc http svr interface
 	rpc.GET(api.PathTaskAcquire, service.HTTPTaskAcquire, rpc.OptArgsQuery())
@@ -369,6 +430,21 @@ func NewHandler(service *Service) *rpc.Router {
 	rpc.GET(api.PathInspectAcquire, service.HTTPInspectAcquire)
 	rpc.POST(api.PathInspectComplete, service.HTTPInspectComplete, rpc.OptArgsBody())
 
+	rpc.POST(api.PathConvertTaskAdd, service.HTTPConvertTaskAdd, rpc.OptArgsBody())
+	rpc.GET(api.PathConvertTaskList, service.HTTPConvertTaskList)
+	rpc.GET(api.PathConvertAcquire, service.HTTPConvertAcquire)
+	rpc.POST(api.PathConvertComplete, service.HTTPConvertComplete, rpc.OptArgsBody())
+	rpc.GET(api.PathConvertedVolume, service.HTTPConvertedVolume, rpc.OptArgsQuery())
+
+	rpc.GET(api.PathClusterMigrateTaskList, service.HTTPClusterMigrateTaskList)
+	rpc.GET(api.PathClusterMigrateAcquire, service.HTTPClusterMigrateAcquire)
+	rpc.POST(api.PathClusterMigrateComplete, service.HTTPClusterMigrateComplete, rpc.OptArgsBody())
+	rpc.GET(api.PathMigratedVolume, service.HTTPMigratedVolume, rpc.OptArgsQuery())
+
+	rpc.GET(api.PathVolumeCompactTaskList, service.HTTPVolumeCompactTaskList)
+	rpc.GET(api.PathVolumeCompactAcquire, service.HTTPVolumeCompactAcquire)
+	rpc.POST(api.PathVolumeCompactComplete, service.HTTPVolumeCompactComplete, rpc.OptArgsBody())
+
 	rpc.POST(api.PathTaskReport, service.HTTPTaskReport, rpc.OptArgsBody())
 	rpc.POST(api.PathTaskRenewal, service.HTTPTaskRenewal, rpc.OptArgsBody())
 
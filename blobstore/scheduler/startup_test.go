@@ -101,6 +101,10 @@ func newMockServiceWithOpts(ctr *gomock.Controller, isLeader bool) *Service {
 	manualMgr := NewMockMigrater(ctr)
 	balanceMgr := NewMockMigrater(ctr)
 	inspecterMgr := NewMockVolumeInspector(ctr)
+	convertMgr := NewMockCodeModeConverter(ctr)
+	clusterMigrateMgr := NewMockClusterMigrator(ctr)
+	volumeCompactMgr := NewMockVolumeCompactor(ctr)
+	blobExpireMgr := NewMockBlobExpirer(ctr)
 	clusterTopology := NewMockClusterTopology(ctr)
 	volumeUpdater := NewMockVolumeUpdater(ctr)
 
@@ -109,12 +113,20 @@ func newMockServiceWithOpts(ctr *gomock.Controller, isLeader bool) *Service {
 	diskDropMgr.EXPECT().Close().AnyTimes().Return()
 	manualMgr.EXPECT().Close().AnyTimes().Return()
 	inspecterMgr.EXPECT().Close().AnyTimes().Return()
+	convertMgr.EXPECT().Close().AnyTimes().Return()
+	clusterMigrateMgr.EXPECT().Close().AnyTimes().Return()
+	volumeCompactMgr.EXPECT().Close().AnyTimes().Return()
+	blobExpireMgr.EXPECT().Close().AnyTimes().Return()
 
 	balanceMgr.EXPECT().Run().AnyTimes().Return()
 	diskDropMgr.EXPECT().Run().AnyTimes().Return()
 	diskRepairMgr.EXPECT().Run().AnyTimes().Return()
 	inspecterMgr.EXPECT().Run().AnyTimes().Return()
 	manualMgr.EXPECT().Run().AnyTimes().Return()
+	convertMgr.EXPECT().Run().AnyTimes().Return()
+	clusterMigrateMgr.EXPECT().Run().AnyTimes().Return()
+	volumeCompactMgr.EXPECT().Run().AnyTimes().Return()
+	blobExpireMgr.EXPECT().Run().AnyTimes().Return()
 
 	clusterTopology.EXPECT().LoadVolumes().AnyTimes().Return(nil)
 	shardRepairMgr.EXPECT().Run().AnyTimes().Return()
@@ -126,6 +138,9 @@ func newMockServiceWithOpts(ctr *gomock.Controller, isLeader bool) *Service {
 	diskRepairMgr.EXPECT().Load().AnyTimes().Return(nil)
 	diskDropMgr.EXPECT().Load().AnyTimes().Return(nil)
 	manualMgr.EXPECT().Load().AnyTimes().Return(nil)
+	convertMgr.EXPECT().Load().AnyTimes().Return(nil)
+	clusterMigrateMgr.EXPECT().Load().AnyTimes().Return(nil)
+	volumeCompactMgr.EXPECT().Load().AnyTimes().Return(nil)
 
 	blobDeleteMgr.EXPECT().GetErrorStats().AnyTimes().Return([]string{}, uint64(0))
 	blobDeleteMgr.EXPECT().GetTaskStats().AnyTimes().Return([counter.SLOT]int{}, [counter.SLOT]int{})
@@ -136,6 +151,7 @@ func newMockServiceWithOpts(ctr *gomock.Controller, isLeader bool) *Service {
 	diskRepairMgr.EXPECT().Stats().AnyTimes().Return(api.MigrateTasksStat{})
 	diskRepairMgr.EXPECT().Progress(any).AnyTimes().Return([]proto.DiskID{proto.DiskID(1)}, 0, 0)
 	diskRepairMgr.EXPECT().Enabled().AnyTimes().Return(true)
+	diskRepairMgr.EXPECT().VolumesAtRisk().AnyTimes().Return(api.VolumesAtRiskStat{})
 	diskDropMgr.EXPECT().Stats().AnyTimes().Return(api.MigrateTasksStat{})
 	diskDropMgr.EXPECT().Progress(any).AnyTimes().Return([]proto.DiskID{proto.DiskID(1)}, 0, 0)
 	diskDropMgr.EXPECT().Enabled().AnyTimes().Return(true)
@@ -144,12 +160,16 @@ func newMockServiceWithOpts(ctr *gomock.Controller, isLeader bool) *Service {
 	manualMgr.EXPECT().Stats().AnyTimes().Return(api.MigrateTasksStat{})
 	inspecterMgr.EXPECT().GetTaskStats().AnyTimes().Return([counter.SLOT]int{}, [counter.SLOT]int{})
 	inspecterMgr.EXPECT().Enabled().AnyTimes().Return(true)
+	convertMgr.EXPECT().Stats().AnyTimes().Return(api.CodeModeConvertTasksStat{})
+	clusterMigrateMgr.EXPECT().Stats().AnyTimes().Return(api.ClusterMigrateTasksStat{})
+	volumeCompactMgr.EXPECT().Stats().AnyTimes().Return(api.VolumeCompactTasksStat{})
 
 	volumeUpdater.EXPECT().UpdateFollowerVolumeCache(any, any, any).AnyTimes().Return(nil)
 	volumeUpdater.EXPECT().UpdateLeaderVolumeCache(any, any).AnyTimes().Return(nil)
 
 	manualMgr.EXPECT().AcquireTask(any, any).AnyTimes().Return(proto.MigrateTask{TaskType: proto.TaskTypeManualMigrate}, nil)
 	diskRepairMgr.EXPECT().AcquireTask(any, any).AnyTimes().Return(proto.MigrateTask{}, errMock)
+	diskRepairMgr.EXPECT().PreemptMigrate().AnyTimes().Return(false)
 	diskDropMgr.EXPECT().AcquireTask(any, any).AnyTimes().Return(proto.MigrateTask{}, errMock)
 	balanceMgr.EXPECT().AcquireTask(any, any).AnyTimes().Return(proto.MigrateTask{}, errMock)
 
@@ -164,11 +184,16 @@ func newMockServiceWithOpts(ctr *gomock.Controller, isLeader bool) *Service {
 		manualMigMgr:    manualMgr,
 		diskRepairMgr:   diskRepairMgr,
 		inspectMgr:      inspecterMgr,
+		convertMgr:      convertMgr,
 		shardRepairMgr:  shardRepairMgr,
 		blobDeleteMgr:   blobDeleteMgr,
 		clusterTopology: clusterTopology,
 		volumeUpdater:   volumeUpdater,
 		clusterMgrCli:   clusterMgrCli,
+
+		clusterMigrateMgr: clusterMigrateMgr,
+		volumeCompactMgr:  volumeCompactMgr,
+		blobExpireMgr:     blobExpireMgr,
 	}
 	return service
 }
@@ -0,0 +1,473 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	api "github.com/cubefs/cubefs/blobstore/api/scheduler"
+	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/rpc"
+	"github.com/cubefs/cubefs/blobstore/common/taskswitch"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/scheduler/base"
+	"github.com/cubefs/cubefs/blobstore/scheduler/client"
+	"github.com/cubefs/cubefs/blobstore/util/closer"
+)
+
+// IVolumeCompactor define the interface of volume compact manager
+type IVolumeCompactor interface {
+	AcquireTask(ctx context.Context) (*proto.VolumeCompactTask, error)
+	RenewalTask(ctx context.Context, idc, taskID string) error
+	CompleteTask(ctx context.Context, ret *proto.VolumeCompactRet) error
+	ListTasks(ctx context.Context) []*proto.VolumeCompactTask
+	Stats() api.VolumeCompactTasksStat
+	Enabled() bool
+	Load() error
+	Run()
+	closer.Closer
+}
+
+var (
+	errCompactLeaseExpired = errors.New("volume compact task lease expired")
+	errNoSuchCompactTask   = errors.New("no such volume compact task")
+)
+
+// VolumeCompactMgrCfg volume compact manager config
+type VolumeCompactMgrCfg struct {
+	CollectIntervalS int `json:"collect_interval_s"`
+	PrepareIntervalS int `json:"prepare_interval_s"`
+	PreparedLimit    int `json:"prepared_limit"`
+	ListVolStep      int `json:"list_vol_step"`
+	// volume is compacted if its live data is less than LiveRatio of total
+	// and its free space is less than FreeRatio of total
+	LiveRatio float64 `json:"live_ratio"`
+	FreeRatio float64 `json:"free_ratio"`
+}
+
+// compactReleasing returns true if the task has been finished but units of source volume are not released
+func compactReleasing(task *proto.VolumeCompactTask) bool {
+	return task.State == proto.MigrateStateFinished && !task.Released
+}
+
+// VolumeCompactMgr manager of volume compact, it reclaims the space of volumes
+// which are full of deleted blobs.
+// step1.collect idle volumes whose live data is below the threshold
+// step2.lock the source volume to be read-only and alloc a target volume
+// step3.worker copies live shards of source volume into target volume with the same bid
+// step4.record the compacted volume, delete and repair are redirected to the target volume
+// step5.release units of the source volume back to clustermgr
+//
+// The source volume is kept locked in clustermgr after released,
+// access redirects locations of locked volume by the compacted record.
+type VolumeCompactMgr struct {
+	closer.Closer
+	volumeTasks
+
+	taskSwitch    taskswitch.ISwitcher
+	clusterMgrCli client.ClusterMgrAPI
+
+	cfg *VolumeCompactMgrCfg
+}
+
+// NewVolumeCompactMgr returns volume compact manager
+func NewVolumeCompactMgr(clusterMgrCli client.ClusterMgrAPI, taskSwitch taskswitch.ISwitcher,
+	cfg *VolumeCompactMgrCfg,
+) *VolumeCompactMgr {
+	mgr := &VolumeCompactMgr{
+		Closer:        closer.New(),
+		taskSwitch:    taskSwitch,
+		clusterMgrCli: clusterMgrCli,
+		cfg:           cfg,
+	}
+	mgr.volumeTasks = newVolumeTasks(taskSwitch, errNoSuchCompactTask, errCompactLeaseExpired,
+		func(ctx context.Context, task proto.VolumeTask) error {
+			return clusterMgrCli.UpdateVolumeCompactTask(ctx, task.(*proto.VolumeCompactTask))
+		},
+		func(ctx context.Context, task proto.VolumeTask) {
+			base.VolTaskLockerInst().Unlock(ctx, task.GetSourceVid())
+			base.VolTaskLockerInst().Unlock(ctx, task.GetTargetVid())
+		})
+	return mgr
+}
+
+// Load load volume compact tasks from clustermgr
+func (mgr *VolumeCompactMgr) Load() error {
+	span, ctx := trace.StartSpanFromContext(context.Background(), "VolumeCompactMgr.Load")
+
+	tasks, err := mgr.clusterMgrCli.ListAllVolumeCompactTasks(ctx)
+	if err != nil {
+		span.Errorf("list all volume compact tasks failed: err[%+v]", err)
+		return err
+	}
+	for _, task := range tasks {
+		switch {
+		case task.Running():
+			if err = mgr.lockVolumes(ctx, task); err != nil {
+				return err
+			}
+		case compactReleasing(task):
+			if err = base.VolTaskLockerInst().TryLock(ctx, task.SourceVid); err != nil {
+				return err
+			}
+		}
+		mgr.setTask(task)
+	}
+	span.Infof("load volume compact tasks success: len[%d]", len(tasks))
+	return nil
+}
+
+// Run run volume compact manager
+func (mgr *VolumeCompactMgr) Run() {
+	go mgr.collectLoop()
+	go mgr.prepareLoop()
+}
+
+// Enabled returns true if volume compact is enabled
+func (mgr *VolumeCompactMgr) Enabled() bool {
+	return mgr.taskSwitch.Enabled()
+}
+
+func (mgr *VolumeCompactMgr) collectLoop() {
+	ticker := time.NewTicker(time.Duration(mgr.cfg.CollectIntervalS) * time.Second)
+	defer ticker.Stop()
+	for {
+		mgr.taskSwitch.WaitEnable()
+		select {
+		case <-ticker.C:
+			span, ctx := trace.StartSpanFromContext(context.Background(), "VolumeCompactMgr.collect")
+			if err := mgr.collectTasks(ctx); err != nil {
+				span.Warnf("collect volume compact tasks failed: err[%+v]", err)
+			}
+		case <-mgr.Done():
+			return
+		}
+	}
+}
+
+// needCompact returns true if the volume is filled and most of its blobs have been deleted,
+// volume being written or migrated is not compacted.
+func (mgr *VolumeCompactMgr) needCompact(vol *client.VolumeInfoSimple) bool {
+	if !vol.IsIdle() || vol.Total == 0 {
+		return false
+	}
+	return float64(vol.Used) <= float64(vol.Total)*mgr.cfg.LiveRatio &&
+		float64(vol.Free) <= float64(vol.Total)*mgr.cfg.FreeRatio
+}
+
+// collectTasks adds tasks of volumes need to be compacted, no more than
+// PreparedLimit tasks are pending at the same time.
+func (mgr *VolumeCompactMgr) collectTasks(ctx context.Context) error {
+	span := trace.SpanFromContextSafe(ctx)
+
+	pending := mgr.pendingCount()
+	marker := proto.InvalidVid
+	for pending < mgr.cfg.PreparedLimit {
+		vols, next, err := mgr.clusterMgrCli.ListVolume(ctx, marker, mgr.cfg.ListVolStep)
+		if err != nil {
+			return err
+		}
+		if len(vols) == 0 {
+			break
+		}
+		for _, vol := range vols {
+			if pending >= mgr.cfg.PreparedLimit {
+				break
+			}
+			if !mgr.needCompact(vol) || mgr.isCompacting(vol.Vid) {
+				continue
+			}
+
+			task := &proto.VolumeCompactTask{
+				TaskID:    client.GenVolumeCompactTaskID(vol.Vid),
+				State:     proto.MigrateStateInited,
+				CodeMode:  vol.CodeMode,
+				SourceVid: vol.Vid,
+				Sources:   vol.VunitLocations,
+			}
+			if err = mgr.clusterMgrCli.AddVolumeCompactTask(ctx, task); err != nil {
+				span.Errorf("add volume compact task failed: task_id[%s], err[%+v]", task.TaskID, err)
+				return err
+			}
+
+			mgr.setTask(task)
+			pending++
+			span.Infof("add volume compact task success: task_id[%s], used[%d], free[%d], total[%d]",
+				task.TaskID, vol.Used, vol.Free, vol.Total)
+		}
+		marker = next
+	}
+	return nil
+}
+
+func (mgr *VolumeCompactMgr) pendingCount() (count int) {
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+	for _, info := range mgr.tasks {
+		if info.task.GetState() == proto.MigrateStateInited || info.task.Running() {
+			count++
+		}
+	}
+	return
+}
+
+// isCompacting returns true if the volume is source or target of an unfinished task
+func (mgr *VolumeCompactMgr) isCompacting(vid proto.Vid) bool {
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+	for _, info := range mgr.tasks {
+		task := info.task.(*proto.VolumeCompactTask)
+		if task.State == proto.MigrateStateFinished && task.Released {
+			continue
+		}
+		if task.SourceVid == vid || task.TargetVid == vid {
+			return true
+		}
+	}
+	return false
+}
+
+func (mgr *VolumeCompactMgr) prepareLoop() {
+	ticker := time.NewTicker(time.Duration(mgr.cfg.PrepareIntervalS) * time.Second)
+	defer ticker.Stop()
+	for {
+		mgr.taskSwitch.WaitEnable()
+		select {
+		case <-ticker.C:
+			mgr.prepareTasks()
+			mgr.releaseTasks()
+		case <-mgr.Done():
+			return
+		}
+	}
+}
+
+func (mgr *VolumeCompactMgr) prepareTasks() {
+	for _, inited := range mgr.initedTasks() {
+		task := inited.(*proto.VolumeCompactTask)
+		span, ctx := trace.StartSpanFromContext(context.Background(), "VolumeCompactMgr.prepare")
+		if err := mgr.prepareTask(ctx, task); err != nil {
+			span.Warnf("prepare volume compact task failed: task_id[%s], err[%+v]", task.TaskID, err)
+		}
+	}
+}
+
+// prepareTask locks the source volume to be read-only and allocates the target volume.
+func (mgr *VolumeCompactMgr) prepareTask(ctx context.Context, task *proto.VolumeCompactTask) (err error) {
+	span := trace.SpanFromContextSafe(ctx)
+
+	if err = base.VolTaskLockerInst().TryLock(ctx, task.SourceVid); err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			base.VolTaskLockerInst().Unlock(ctx, task.SourceVid)
+		}
+	}()
+
+	if err = mgr.clusterMgrCli.LockVolume(ctx, task.SourceVid); err != nil {
+		return
+	}
+	source, err := mgr.clusterMgrCli.GetVolumeInfo(ctx, task.SourceVid)
+	if err != nil {
+		return
+	}
+
+	// keep the allocated target volume in memory, in case of updating compact task failed
+	if task.TargetVid == proto.InvalidVid {
+		target, errAlloc := mgr.clusterMgrCli.AllocVolume(ctx, task.CodeMode)
+		if errAlloc != nil {
+			return errAlloc
+		}
+		task.TargetVid = target.Vid
+		task.Destinations = target.VunitLocations
+		mgr.setTask(task.Copy())
+	}
+	if err = base.VolTaskLockerInst().TryLock(ctx, task.TargetVid); err != nil {
+		return
+	}
+
+	task.Sources = source.VunitLocations
+	task.State = proto.MigrateStatePrepared
+	if err = mgr.clusterMgrCli.UpdateVolumeCompactTask(ctx, task); err != nil {
+		base.VolTaskLockerInst().Unlock(ctx, task.TargetVid)
+		return
+	}
+
+	mgr.setTask(task)
+	span.Infof("prepare volume compact task success: task_id[%s], vid[%d] -> vid[%d]", task.TaskID, task.SourceVid, task.TargetVid)
+	return nil
+}
+
+// AcquireTask acquires a prepared task which is not running on any worker
+func (mgr *VolumeCompactMgr) AcquireTask(ctx context.Context) (*proto.VolumeCompactTask, error) {
+	task, err := mgr.acquireTask(ctx, mgr.clusterMgrCli, mgr.clusterMgrCli)
+	if err != nil {
+		return nil, err
+	}
+	return task.(*proto.VolumeCompactTask), nil
+}
+
+// CompleteTask finishes the task if worker copied all live blobs,
+// otherwise the task will be acquired again.
+func (mgr *VolumeCompactMgr) CompleteTask(ctx context.Context, ret *proto.VolumeCompactRet) error {
+	span := trace.SpanFromContextSafe(ctx)
+
+	running, err := mgr.runningTask(ret.TaskID)
+	if err != nil {
+		return err
+	}
+	task := running.(*proto.VolumeCompactTask)
+
+	if err = ret.Err(); err != nil {
+		span.Warnf("worker compact failed and redo: task_id[%s], err[%+v]", ret.TaskID, err)
+		task.WorkerRedoCnt++
+		return mgr.redoTask(ctx, task)
+	}
+
+	// blobs may be written if the source volume was unlocked when compacting
+	source, err := mgr.clusterMgrCli.GetVolumeInfo(ctx, task.SourceVid)
+	if err != nil {
+		return err
+	}
+	if source.Status != proto.VolumeStatusLock {
+		span.Warnf("source volume is not locked and redo: task_id[%s], status[%d]", task.TaskID, source.Status)
+		task.State = proto.MigrateStateInited
+		return mgr.redoTask(ctx, task)
+	}
+
+	compacted := &proto.CompactedVolume{
+		SourceVid: task.SourceVid,
+		TargetVid: task.TargetVid,
+	}
+	if err = mgr.clusterMgrCli.SetCompactedVolume(ctx, compacted); err != nil {
+		span.Errorf("set compacted volume failed: task_id[%s], err[%+v]", task.TaskID, err)
+		return err
+	}
+	if err = moveExpiringBlobs(ctx, mgr.clusterMgrCli, task.SourceVid, mgr.clusterMgrCli, task.TargetVid); err != nil {
+		span.Errorf("move expiring blobs failed: task_id[%s], err[%+v]", task.TaskID, err)
+		return err
+	}
+
+	task.State = proto.MigrateStateFinished
+	task.CompactedCnt = ret.CompactedCnt
+	if err = mgr.clusterMgrCli.UpdateVolumeCompactTask(ctx, task); err != nil {
+		span.Errorf("update volume compact task failed: task_id[%s], err[%+v]", task.TaskID, err)
+		return err
+	}
+
+	mgr.setTask(task)
+	// the source volume is unlocked after its units released
+	base.VolTaskLockerInst().Unlock(ctx, task.TargetVid)
+
+	span.Infof("volume compact task finished: task_id[%s], compacted[%d]", task.TaskID, task.CompactedCnt)
+	return nil
+}
+
+func (mgr *VolumeCompactMgr) releaseTasks() {
+	mgr.mu.Lock()
+	var finished []*proto.VolumeCompactTask
+	for _, info := range mgr.tasks {
+		if task := info.task.(*proto.VolumeCompactTask); compactReleasing(task) {
+			finished = append(finished, task.Copy())
+		}
+	}
+	mgr.mu.Unlock()
+
+	for _, task := range finished {
+		span, ctx := trace.StartSpanFromContext(context.Background(), "VolumeCompactMgr.release")
+		if err := mgr.releaseTask(ctx, task); err != nil {
+			span.Warnf("release volume compact task failed: task_id[%s], err[%+v]", task.TaskID, err)
+		}
+	}
+}
+
+// releaseTask releases units of the compacted source volume back to clustermgr.
+func (mgr *VolumeCompactMgr) releaseTask(ctx context.Context, task *proto.VolumeCompactTask) error {
+	span := trace.SpanFromContextSafe(ctx)
+
+	source, err := mgr.clusterMgrCli.GetVolumeInfo(ctx, task.SourceVid)
+	if err != nil {
+		return err
+	}
+	for _, unit := range source.VunitLocations {
+		err = mgr.clusterMgrCli.ReleaseVolumeUnit(ctx, unit.Vuid, unit.DiskID)
+		if err != nil {
+			// CodeVuidNotFound means the volume unit has been released
+			httpCode := rpc.DetectStatusCode(err)
+			if httpCode != errcode.CodeVuidNotFound && httpCode != errcode.CodeDiskBroken {
+				span.Errorf("release volume unit failed: vuid[%d], err[%+v]", unit.Vuid, err)
+				return err
+			}
+		}
+	}
+
+	task.Released = true
+	if err = mgr.clusterMgrCli.UpdateVolumeCompactTask(ctx, task); err != nil {
+		return err
+	}
+
+	mgr.setTask(task)
+	base.VolTaskLockerInst().Unlock(ctx, task.SourceVid)
+
+	span.Infof("release compacted volume success: task_id[%s], vid[%d]", task.TaskID, task.SourceVid)
+	return nil
+}
+
+func (mgr *VolumeCompactMgr) lockVolumes(ctx context.Context, task *proto.VolumeCompactTask) error {
+	if err := base.VolTaskLockerInst().TryLock(ctx, task.SourceVid); err != nil {
+		return err
+	}
+	if err := base.VolTaskLockerInst().TryLock(ctx, task.TargetVid); err != nil {
+		base.VolTaskLockerInst().Unlock(ctx, task.SourceVid)
+		return err
+	}
+	return nil
+}
+
+// ListTasks returns all volume compact tasks ordered by source vid
+func (mgr *VolumeCompactMgr) ListTasks(ctx context.Context) []*proto.VolumeCompactTask {
+	var tasks []*proto.VolumeCompactTask
+	for _, task := range mgr.listTasks() {
+		tasks = append(tasks, task.(*proto.VolumeCompactTask))
+	}
+	return tasks
+}
+
+// Stats returns stats of volume compact tasks
+func (mgr *VolumeCompactMgr) Stats() api.VolumeCompactTasksStat {
+	stat := api.VolumeCompactTasksStat{Enable: mgr.taskSwitch.Enabled()}
+
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+	for _, info := range mgr.tasks {
+		task := info.task.(*proto.VolumeCompactTask)
+		switch {
+		case task.State == proto.MigrateStateInited:
+			stat.PreparingCnt++
+		case task.Running() && info.leased():
+			stat.WorkerDoingCnt++
+		case compactReleasing(task):
+			stat.ReleasingCnt++
+		case task.State == proto.MigrateStateFinished:
+			stat.CompactedVolumeCnt++
+			stat.CompactedBlobCnt += task.CompactedCnt
+		}
+	}
+	return stat
+}
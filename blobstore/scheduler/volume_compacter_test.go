@@ -0,0 +1,254 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+	errcode "github.com/cubefs/cubefs/blobstore/common/errors"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/scheduler/base"
+	"github.com/cubefs/cubefs/blobstore/scheduler/client"
+	"github.com/cubefs/cubefs/blobstore/testing/mocks"
+)
+
+func newVolumeCompactMgr(t *testing.T) *VolumeCompactMgr {
+	ctr := gomock.NewController(t)
+	clusterMgr := NewMockClusterMgrAPI(ctr)
+	taskSwitch := mocks.NewMockSwitcher(ctr)
+	taskSwitch.EXPECT().Enabled().AnyTimes().Return(true)
+	conf := &VolumeCompactMgrCfg{
+		PreparedLimit: defaultVolumeCompactPreparedLimit,
+		ListVolStep:   defaultListVolStep,
+		LiveRatio:     defaultVolumeCompactLiveRatio,
+		FreeRatio:     defaultVolumeCompactFreeRatio,
+	}
+	return NewVolumeCompactMgr(clusterMgr, taskSwitch, conf)
+}
+
+func mockCompactVolInfo(vid proto.Vid, status proto.VolumeStatus, used, free uint64) *client.VolumeInfoSimple {
+	vol := MockGenVolInfo(vid, codemode.EC6P6, status)
+	vol.Total = 100
+	vol.Used = used
+	vol.Free = free
+	return vol
+}
+
+func TestVolumeCompactMgrLoad(t *testing.T) {
+	ctx := context.Background()
+	mgr := newVolumeCompactMgr(t)
+	cmCli := mgr.clusterMgrCli.(*MockClusterMgrAPI)
+
+	cmCli.EXPECT().ListAllVolumeCompactTasks(any).Return(nil, errMock)
+	require.ErrorIs(t, mgr.Load(), errMock)
+
+	tasks := []*proto.VolumeCompactTask{
+		{TaskID: "volume_compact-501-a", State: proto.MigrateStateInited, SourceVid: 501},
+		{TaskID: "volume_compact-502-b", State: proto.MigrateStatePrepared, SourceVid: 502, TargetVid: 602},
+		{TaskID: "volume_compact-503-c", State: proto.MigrateStateFinished, SourceVid: 503, TargetVid: 603},
+		{TaskID: "volume_compact-504-d", State: proto.MigrateStateFinished, SourceVid: 504, TargetVid: 604, Released: true, CompactedCnt: 10},
+	}
+	cmCli.EXPECT().ListAllVolumeCompactTasks(any).Return(tasks, nil)
+	require.NoError(t, mgr.Load())
+	require.Equal(t, 4, len(mgr.ListTasks(ctx)))
+	for _, vid := range []proto.Vid{502, 602, 503} {
+		require.Error(t, base.VolTaskLockerInst().TryLock(ctx, vid))
+		base.VolTaskLockerInst().Unlock(ctx, vid)
+	}
+	require.NoError(t, base.VolTaskLockerInst().TryLock(ctx, 504))
+	base.VolTaskLockerInst().Unlock(ctx, 504)
+
+	stat := mgr.Stats()
+	require.True(t, stat.Enable)
+	require.Equal(t, 1, stat.PreparingCnt)
+	require.Equal(t, 1, stat.ReleasingCnt)
+	require.Equal(t, 1, stat.CompactedVolumeCnt)
+	require.Equal(t, 10, stat.CompactedBlobCnt)
+}
+
+func TestVolumeCompactMgrCollect(t *testing.T) {
+	ctx := context.Background()
+	mgr := newVolumeCompactMgr(t)
+	cmCli := mgr.clusterMgrCli.(*MockClusterMgrAPI)
+
+	vols := []*client.VolumeInfoSimple{
+		mockCompactVolInfo(511, proto.VolumeStatusIdle, 10, 5),
+		// being written
+		mockCompactVolInfo(512, proto.VolumeStatusActive, 10, 5),
+		// too much live data
+		mockCompactVolInfo(513, proto.VolumeStatusIdle, 50, 5),
+		// not filled yet
+		mockCompactVolInfo(514, proto.VolumeStatusIdle, 10, 50),
+		mockCompactVolInfo(515, proto.VolumeStatusIdle, 0, 0),
+	}
+
+	cmCli.EXPECT().ListVolume(any, any, any).Return(nil, proto.InvalidVid, errMock)
+	require.ErrorIs(t, mgr.collectTasks(ctx), errMock)
+
+	cmCli.EXPECT().ListVolume(any, any, any).Return(vols, proto.Vid(515), nil)
+	cmCli.EXPECT().AddVolumeCompactTask(any, any).Return(nil)
+	cmCli.EXPECT().AddVolumeCompactTask(any, any).Return(errMock)
+	require.ErrorIs(t, mgr.collectTasks(ctx), errMock)
+	require.Equal(t, 1, len(mgr.ListTasks(ctx)))
+
+	// collected volume is skipped
+	cmCli.EXPECT().ListVolume(any, any, any).Return(vols, proto.Vid(515), nil)
+	cmCli.EXPECT().ListVolume(any, any, any).Return(nil, proto.InvalidVid, nil)
+	cmCli.EXPECT().AddVolumeCompactTask(any, any).Return(nil)
+	require.NoError(t, mgr.collectTasks(ctx))
+	tasks := mgr.ListTasks(ctx)
+	require.Equal(t, 2, len(tasks))
+	require.Equal(t, proto.Vid(511), tasks[0].SourceVid)
+	require.Equal(t, proto.Vid(515), tasks[1].SourceVid)
+	require.True(t, client.ValidMigrateTask(proto.TaskTypeVolumeCompact, tasks[0].TaskID))
+	require.Equal(t, 2, mgr.Stats().PreparingCnt)
+
+	// no more than prepared limit
+	mgr.cfg.PreparedLimit = 2
+	require.NoError(t, mgr.collectTasks(ctx))
+}
+
+func TestVolumeCompactMgrLifecycle(t *testing.T) {
+	ctx := context.Background()
+	mgr := newVolumeCompactMgr(t)
+	cmCli := mgr.clusterMgrCli.(*MockClusterMgrAPI)
+
+	var srcVid, dstVid proto.Vid = 521, 621
+	source := mockCompactVolInfo(srcVid, proto.VolumeStatusIdle, 10, 5)
+	lockedSource := mockCompactVolInfo(srcVid, proto.VolumeStatusLock, 10, 5)
+	target := MockGenVolInfo(dstVid, codemode.EC6P6, proto.VolumeStatusActive)
+
+	cmCli.EXPECT().ListVolume(any, any, any).Return([]*client.VolumeInfoSimple{source}, srcVid, nil)
+	cmCli.EXPECT().ListVolume(any, any, any).Return(nil, proto.InvalidVid, nil)
+	cmCli.EXPECT().AddVolumeCompactTask(any, any).Return(nil)
+	require.NoError(t, mgr.collectTasks(ctx))
+	taskID := mgr.ListTasks(ctx)[0].TaskID
+
+	// nothing prepared
+	_, err := mgr.AcquireTask(ctx)
+	require.ErrorIs(t, err, proto.ErrTaskEmpty)
+
+	// alloc target volume failed
+	cmCli.EXPECT().LockVolume(any, any).Return(nil)
+	cmCli.EXPECT().GetVolumeInfo(any, any).Return(lockedSource, nil)
+	cmCli.EXPECT().AllocVolume(any, any).Return(nil, errMock)
+	mgr.prepareTasks()
+	require.Equal(t, 1, mgr.Stats().PreparingCnt)
+	require.NoError(t, base.VolTaskLockerInst().TryLock(ctx, srcVid))
+	base.VolTaskLockerInst().Unlock(ctx, srcVid)
+
+	// update task failed, the allocated target volume is kept
+	cmCli.EXPECT().LockVolume(any, any).Times(2).Return(nil)
+	cmCli.EXPECT().GetVolumeInfo(any, any).Times(2).Return(lockedSource, nil)
+	cmCli.EXPECT().AllocVolume(any, any).Return(target, nil)
+	cmCli.EXPECT().UpdateVolumeCompactTask(any, any).Return(errMock)
+	mgr.prepareTasks()
+	require.Equal(t, dstVid, mgr.ListTasks(ctx)[0].TargetVid)
+	cmCli.EXPECT().UpdateVolumeCompactTask(any, any).Return(nil)
+	mgr.prepareTasks()
+	require.Equal(t, 0, mgr.Stats().PreparingCnt)
+	require.Error(t, base.VolTaskLockerInst().TryLock(ctx, srcVid))
+	require.Error(t, base.VolTaskLockerInst().TryLock(ctx, dstVid))
+
+	// target volume is not collected
+	require.True(t, mgr.isCompacting(dstVid))
+
+	cmCli.EXPECT().GetVolumeInfo(any, srcVid).Return(lockedSource, nil)
+	cmCli.EXPECT().GetVolumeInfo(any, dstVid).Return(target, nil)
+	task, err := mgr.AcquireTask(ctx)
+	require.NoError(t, err)
+	require.Equal(t, taskID, task.TaskID)
+	require.True(t, task.IsValid())
+	require.Equal(t, 1, mgr.Stats().WorkerDoingCnt)
+
+	// worker failed and redo
+	cmCli.EXPECT().UpdateVolumeCompactTask(any, any).Return(nil)
+	require.NoError(t, mgr.CompleteTask(ctx, &proto.VolumeCompactRet{TaskID: taskID, CompactErrStr: "mock"}))
+	require.Equal(t, uint8(1), mgr.ListTasks(ctx)[0].WorkerRedoCnt)
+
+	// source volume unlocked and prepare again
+	cmCli.EXPECT().GetVolumeInfo(any, any).Return(source, nil)
+	cmCli.EXPECT().UpdateVolumeCompactTask(any, any).Return(nil)
+	require.NoError(t, mgr.CompleteTask(ctx, &proto.VolumeCompactRet{TaskID: taskID}))
+	require.Equal(t, 1, mgr.Stats().PreparingCnt)
+	require.NoError(t, base.VolTaskLockerInst().TryLock(ctx, srcVid))
+	base.VolTaskLockerInst().Unlock(ctx, srcVid)
+
+	// target volume is kept when prepared again
+	cmCli.EXPECT().LockVolume(any, any).Return(nil)
+	cmCli.EXPECT().GetVolumeInfo(any, any).Return(lockedSource, nil)
+	cmCli.EXPECT().UpdateVolumeCompactTask(any, any).Return(nil)
+	mgr.prepareTasks()
+	require.Equal(t, dstVid, mgr.ListTasks(ctx)[0].TargetVid)
+
+	// finished
+	cmCli.EXPECT().GetVolumeInfo(any, srcVid).Return(lockedSource, nil)
+	cmCli.EXPECT().GetVolumeInfo(any, dstVid).Return(target, nil)
+	_, err = mgr.AcquireTask(ctx)
+	require.NoError(t, err)
+	cmCli.EXPECT().GetVolumeInfo(any, any).Return(lockedSource, nil)
+	cmCli.EXPECT().SetCompactedVolume(any, any).Return(errMock)
+	require.ErrorIs(t, mgr.CompleteTask(ctx, &proto.VolumeCompactRet{TaskID: taskID, CompactedCnt: 5}), errMock)
+	cmCli.EXPECT().GetVolumeInfo(any, any).Return(lockedSource, nil)
+	cmCli.EXPECT().SetCompactedVolume(any, any).DoAndReturn(
+		func(_ context.Context, compacted *proto.CompactedVolume) error {
+			require.Equal(t, srcVid, compacted.SourceVid)
+			require.Equal(t, dstVid, compacted.TargetVid)
+			return nil
+		})
+	// expiry records of the source volume are moved to the target volume
+	expiring := &proto.ExpiringBlob{Vid: srcVid, MinBid: 10, Count: 2, Expire: 100}
+	cmCli.EXPECT().ListExpiringBlobs(any, "", any).Return(
+		[]*proto.ExpiringBlob{expiring, {Vid: dstVid + 1, MinBid: 1, Count: 1, Expire: 100}}, "", nil)
+	cmCli.EXPECT().SetExpiringBlob(any, &proto.ExpiringBlob{Vid: dstVid, MinBid: 10, Count: 2, Expire: 100}).Return(nil)
+	cmCli.EXPECT().DeleteExpiringBlob(any, expiring).Return(nil)
+	cmCli.EXPECT().UpdateVolumeCompactTask(any, any).Return(nil)
+	require.NoError(t, mgr.CompleteTask(ctx, &proto.VolumeCompactRet{TaskID: taskID, CompactedCnt: 5}))
+	require.ErrorIs(t, mgr.CompleteTask(ctx, &proto.VolumeCompactRet{TaskID: taskID}), errNoSuchCompactTask)
+	require.NoError(t, base.VolTaskLockerInst().TryLock(ctx, dstVid))
+	base.VolTaskLockerInst().Unlock(ctx, dstVid)
+	require.Error(t, base.VolTaskLockerInst().TryLock(ctx, srcVid))
+	require.Equal(t, 1, mgr.Stats().ReleasingCnt)
+
+	// release volume units failed
+	cmCli.EXPECT().GetVolumeInfo(any, any).Return(lockedSource, nil)
+	cmCli.EXPECT().ReleaseVolumeUnit(any, any, any).Return(errMock)
+	mgr.releaseTasks()
+	require.Equal(t, 1, mgr.Stats().ReleasingCnt)
+
+	// released units are skipped
+	cmCli.EXPECT().GetVolumeInfo(any, any).Return(lockedSource, nil)
+	cmCli.EXPECT().ReleaseVolumeUnit(any, any, any).Return(errcode.ErrNoSuchVuid)
+	cmCli.EXPECT().ReleaseVolumeUnit(any, any, any).Times(len(lockedSource.VunitLocations) - 1).Return(nil)
+	cmCli.EXPECT().UpdateVolumeCompactTask(any, any).DoAndReturn(
+		func(_ context.Context, task *proto.VolumeCompactTask) error {
+			require.True(t, task.Released)
+			return nil
+		})
+	mgr.releaseTasks()
+	require.NoError(t, base.VolTaskLockerInst().TryLock(ctx, srcVid))
+	base.VolTaskLockerInst().Unlock(ctx, srcVid)
+	require.False(t, mgr.isCompacting(srcVid))
+
+	stat := mgr.Stats()
+	require.Equal(t, 0, stat.ReleasingCnt)
+	require.Equal(t, 1, stat.CompactedVolumeCnt)
+	require.Equal(t, 5, stat.CompactedBlobCnt)
+}
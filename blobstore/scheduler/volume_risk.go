@@ -0,0 +1,176 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package scheduler
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	api "github.com/cubefs/cubefs/blobstore/api/scheduler"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/scheduler/client"
+	"github.com/cubefs/cubefs/blobstore/util/defaulter"
+)
+
+const (
+	defaultAtRiskFaultTolerance = 1
+	defaultAtRiskReportLimit    = 100
+
+	// priority of task whose volume risk is unknown
+	lowestRiskPriority = math.MinInt32
+)
+
+// VolumeRiskConfig volume risk config
+type VolumeRiskConfig struct {
+	// volumes whose fault tolerance is no more than it are at risk
+	AtRiskFaultTolerance int `json:"at_risk_fault_tolerance"`
+	// max volumes at risk in stats
+	ReportLimit int `json:"report_limit"`
+	// repair of volumes at risk preempts balance, disk drop and manual migrate
+	DisablePreempt bool `json:"disable_preempt"`
+}
+
+// CheckAndFix check and fix volume risk config
+func (c *VolumeRiskConfig) CheckAndFix() {
+	defaulter.LessOrEqual(&c.AtRiskFaultTolerance, defaultAtRiskFaultTolerance)
+	defaulter.LessOrEqual(&c.ReportLimit, defaultAtRiskReportLimit)
+}
+
+// evalVolumeRisk returns durability risk of volume, the units on broken
+// or repairing disks and units of badIdxes are bad. Local parity units
+// of LRC only help local repair and are not counted in fault tolerance.
+func evalVolumeRisk(vol *client.VolumeInfoSimple, badIdxes []uint8, isBroken func(diskID proto.DiskID) bool) (api.VolumeRisk, bool) {
+	risk := api.VolumeRisk{Vid: vol.Vid, CodeMode: vol.CodeMode}
+	if !vol.CodeMode.IsValid() {
+		return risk, false
+	}
+
+	tactic := vol.CodeMode.Tactic()
+	tolerance := tactic.M
+	if tactic.IsReplicateMode() {
+		tolerance = tactic.N - 1
+	}
+	for idx, unit := range vol.VunitLocations {
+		if idx >= tactic.N+tactic.M {
+			break
+		}
+		if isBroken(unit.DiskID) || containIdx(badIdxes, uint8(idx)) {
+			risk.BadCnt++
+		}
+	}
+	risk.FaultTolerance = tolerance - risk.BadCnt
+	return risk, true
+}
+
+func containIdx(idxes []uint8, idx uint8) bool {
+	for _, i := range idxes {
+		if i == idx {
+			return true
+		}
+	}
+	return false
+}
+
+// riskPriority volume with less fault tolerance has higher priority
+func riskPriority(risk api.VolumeRisk) int {
+	return -risk.FaultTolerance
+}
+
+// volumeRiskTracker tracks volume risk of tasks in queues
+type volumeRiskTracker struct {
+	mu    sync.Mutex
+	tasks map[string]api.VolumeRisk
+
+	cfg VolumeRiskConfig
+}
+
+func newVolumeRiskTracker(cfg VolumeRiskConfig) *volumeRiskTracker {
+	return &volumeRiskTracker{
+		tasks: make(map[string]api.VolumeRisk),
+		cfg:   cfg,
+	}
+}
+
+func (t *volumeRiskTracker) set(taskID string, risk api.VolumeRisk) {
+	t.mu.Lock()
+	t.tasks[taskID] = risk
+	t.mu.Unlock()
+}
+
+func (t *volumeRiskTracker) delete(taskID string) {
+	t.mu.Lock()
+	delete(t.tasks, taskID)
+	t.mu.Unlock()
+}
+
+// priority returns priority of task in queue
+func (t *volumeRiskTracker) priority(taskID string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if risk, ok := t.tasks[taskID]; ok {
+		return riskPriority(risk)
+	}
+	return lowestRiskPriority
+}
+
+func (t *volumeRiskTracker) isAtRisk(risk api.VolumeRisk) bool {
+	return risk.FaultTolerance <= t.cfg.AtRiskFaultTolerance
+}
+
+// hasAtRisk returns true if any task of volume at risk is pending
+func (t *volumeRiskTracker) hasAtRisk() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, risk := range t.tasks {
+		if t.isAtRisk(risk) {
+			return true
+		}
+	}
+	return false
+}
+
+// stat returns volumes at risk, one volume may has several tasks of different disks
+func (t *volumeRiskTracker) stat() api.VolumesAtRiskStat {
+	t.mu.Lock()
+	volumes := make(map[proto.Vid]api.VolumeRisk)
+	for _, risk := range t.tasks {
+		if !t.isAtRisk(risk) {
+			continue
+		}
+		if r, ok := volumes[risk.Vid]; !ok || risk.FaultTolerance < r.FaultTolerance {
+			volumes[risk.Vid] = risk
+		}
+	}
+	t.mu.Unlock()
+
+	ret := api.VolumesAtRiskStat{
+		AtRiskCnt: len(volumes),
+		Volumes:   make([]api.VolumeRisk, 0, len(volumes)),
+	}
+	for _, risk := range volumes {
+		ret.Volumes = append(ret.Volumes, risk)
+	}
+	sort.Slice(ret.Volumes, func(i, j int) bool {
+		if ret.Volumes[i].FaultTolerance == ret.Volumes[j].FaultTolerance {
+			return ret.Volumes[i].Vid < ret.Volumes[j].Vid
+		}
+		return ret.Volumes[i].FaultTolerance < ret.Volumes[j].FaultTolerance
+	})
+	if t.cfg.ReportLimit > 0 && len(ret.Volumes) > t.cfg.ReportLimit {
+		ret.Volumes = ret.Volumes[:t.cfg.ReportLimit]
+	}
+	return ret
+}
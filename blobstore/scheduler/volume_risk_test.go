@@ -0,0 +1,143 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	api "github.com/cubefs/cubefs/blobstore/api/scheduler"
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/scheduler/client"
+	"github.com/cubefs/cubefs/blobstore/testing/mocks"
+)
+
+func brokenIdxes(idxes ...uint8) func(diskID proto.DiskID) bool {
+	return func(diskID proto.DiskID) bool {
+		return containIdx(idxes, proto.Vuid(diskID).Index())
+	}
+}
+
+func TestEvalVolumeRisk(t *testing.T) {
+	cases := []struct {
+		mode      codemode.CodeMode
+		broken    []uint8
+		bads      []uint8
+		badCnt    int
+		tolerance int
+	}{
+		{codemode.EC6P6, nil, nil, 0, 6},
+		{codemode.EC6P6, []uint8{0, 1}, []uint8{2}, 3, 3},
+		{codemode.EC6P6, []uint8{0}, []uint8{0}, 1, 5},
+		{codemode.EC6P10L2, []uint8{16, 17}, nil, 0, 10},
+		{codemode.EC6P10L2, []uint8{0, 1, 2, 3, 4, 5, 6, 7, 8}, []uint8{9, 16}, 10, 0},
+		{codemode.Replica3, []uint8{0}, nil, 1, 1},
+		{codemode.Replica3, []uint8{0}, []uint8{1}, 2, 0},
+	}
+	for _, cs := range cases {
+		vol := MockGenVolInfo(1, cs.mode, proto.VolumeStatusIdle)
+		risk, ok := evalVolumeRisk(vol, cs.bads, brokenIdxes(cs.broken...))
+		require.True(t, ok)
+		require.Equal(t, proto.Vid(1), risk.Vid)
+		require.Equal(t, cs.mode, risk.CodeMode)
+		require.Equal(t, cs.badCnt, risk.BadCnt, "%s %v %v", cs.mode, cs.broken, cs.bads)
+		require.Equal(t, cs.tolerance, risk.FaultTolerance, "%s %v %v", cs.mode, cs.broken, cs.bads)
+	}
+
+	vol := MockGenVolInfo(1, codemode.EC6P6, proto.VolumeStatusIdle)
+	vol.CodeMode = codemode.CodeMode(0)
+	_, ok := evalVolumeRisk(vol, nil, brokenIdxes())
+	require.False(t, ok)
+}
+
+func TestVolumeRiskTracker(t *testing.T) {
+	cfg := VolumeRiskConfig{}
+	cfg.CheckAndFix()
+	require.Equal(t, defaultAtRiskFaultTolerance, cfg.AtRiskFaultTolerance)
+	require.Equal(t, defaultAtRiskReportLimit, cfg.ReportLimit)
+
+	tracker := newVolumeRiskTracker(VolumeRiskConfig{AtRiskFaultTolerance: 1, ReportLimit: 2})
+	require.False(t, tracker.hasAtRisk())
+	require.Equal(t, lowestRiskPriority, tracker.priority("task"))
+
+	tracker.set("task1", api.VolumeRisk{Vid: 1, FaultTolerance: 3})
+	require.False(t, tracker.hasAtRisk())
+	require.Equal(t, -3, tracker.priority("task1"))
+	require.Equal(t, 0, tracker.stat().AtRiskCnt)
+
+	tracker.set("task2", api.VolumeRisk{Vid: 2, FaultTolerance: 1})
+	tracker.set("task3", api.VolumeRisk{Vid: 2, FaultTolerance: 0})
+	tracker.set("task4", api.VolumeRisk{Vid: 3, FaultTolerance: 1})
+	tracker.set("task5", api.VolumeRisk{Vid: 4, FaultTolerance: 1})
+	require.True(t, tracker.hasAtRisk())
+	require.True(t, tracker.priority("task3") > tracker.priority("task2"))
+
+	stat := tracker.stat()
+	require.Equal(t, 3, stat.AtRiskCnt)
+	require.Equal(t, []api.VolumeRisk{{Vid: 2, FaultTolerance: 0}, {Vid: 3, FaultTolerance: 1}}, stat.Volumes)
+
+	tracker.cfg.ReportLimit = 0
+	require.Len(t, tracker.stat().Volumes, 3)
+
+	for _, taskID := range []string{"task2", "task3", "task4", "task5"} {
+		tracker.delete(taskID)
+	}
+	require.False(t, tracker.hasAtRisk())
+	require.Equal(t, lowestRiskPriority, tracker.priority("task3"))
+}
+
+func TestDiskRepairerVolumesAtRisk(t *testing.T) {
+	ctx := context.Background()
+	mgr := newDiskRepairer(t)
+	ctr := gomock.NewController(t)
+	volumes := newMockVolInfoMap()
+	// ten units of volume 2 are on broken disks
+	brokenDisks := make(map[proto.DiskID]bool)
+	for idx := range volumes[2].VunitLocations {
+		volumes[2].VunitLocations[idx].DiskID = proto.DiskID(1000 + idx)
+		brokenDisks[volumes[2].VunitLocations[idx].DiskID] = idx < 10
+	}
+	clusterTopology := NewMockClusterTopology(ctr)
+	clusterTopology.EXPECT().GetVolume(any).AnyTimes().DoAndReturn(func(vid proto.Vid) (*client.VolumeInfoSimple, error) {
+		return volumes[vid], nil
+	})
+	clusterTopology.EXPECT().IsBrokenDisk(any).AnyTimes().DoAndReturn(func(diskID proto.DiskID) bool {
+		return brokenDisks[diskID]
+	})
+	mgr.clusterTopology = clusterTopology
+	mgr.clusterMgrCli.(*MockClusterMgrAPI).EXPECT().AddMigrateTask(any, any).AnyTimes().Return(nil)
+
+	mgr.initOneTask(ctx, volumes[1].VunitLocations[0].Vuid, 1, "z0")
+	mgr.initOneTask(ctx, volumes[2].VunitLocations[0].Vuid, 2, "z0")
+
+	mgr.taskSwitch.(*mocks.MockSwitcher).EXPECT().Enabled().Return(true)
+	require.True(t, mgr.PreemptMigrate())
+	stat := mgr.VolumesAtRisk()
+	require.Equal(t, 1, stat.AtRiskCnt)
+	require.Equal(t, proto.Vid(2), stat.Volumes[0].Vid)
+	require.Equal(t, 0, stat.Volumes[0].FaultTolerance)
+
+	// task of volume at risk is prepared first
+	_, task, exist := mgr.prepareQueue.PopTask()
+	require.True(t, exist)
+	require.Equal(t, proto.Vid(2), task.(*proto.MigrateTask).Vid())
+
+	mgr.cfg.VolumeRisk.DisablePreempt = true
+	require.False(t, mgr.PreemptMigrate())
+}
@@ -0,0 +1,194 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/common/taskswitch"
+	"github.com/cubefs/cubefs/blobstore/common/trace"
+	"github.com/cubefs/cubefs/blobstore/scheduler/client"
+)
+
+type volumeTaskInfo struct {
+	task        proto.VolumeTask
+	leaseExpire time.Time
+}
+
+func (t *volumeTaskInfo) leased() bool {
+	return time.Now().Before(t.leaseExpire)
+}
+
+func (t *volumeTaskInfo) renewal() {
+	t.leaseExpire = time.Now().Add(proto.TaskLeaseExpiredS * time.Second)
+}
+
+// volumeTasks keeps the volume tasks of a manager in memory and leases the running
+// tasks to workers, it is shared by codemode convert, cluster migrate and volume compact.
+// A running task is leased to one worker at a time, the worker renews the lease
+// periodically, the task can be acquired by another worker after the lease expired.
+type volumeTasks struct {
+	mu    sync.Mutex
+	tasks map[string]*volumeTaskInfo
+
+	taskSwitch      taskswitch.ISwitcher
+	errNoSuchTask   error
+	errLeaseExpired error
+
+	// updateTask persists the task into clustermgr
+	updateTask func(ctx context.Context, task proto.VolumeTask) error
+	// unlockVolumes unlocks the volumes of task which will be prepared again
+	unlockVolumes func(ctx context.Context, task proto.VolumeTask)
+}
+
+func newVolumeTasks(taskSwitch taskswitch.ISwitcher, errNoSuchTask, errLeaseExpired error,
+	updateTask func(ctx context.Context, task proto.VolumeTask) error,
+	unlockVolumes func(ctx context.Context, task proto.VolumeTask),
+) volumeTasks {
+	return volumeTasks{
+		tasks:           make(map[string]*volumeTaskInfo),
+		taskSwitch:      taskSwitch,
+		errNoSuchTask:   errNoSuchTask,
+		errLeaseExpired: errLeaseExpired,
+		updateTask:      updateTask,
+		unlockVolumes:   unlockVolumes,
+	}
+}
+
+// setTask sets the task in memory without lease
+func (v *volumeTasks) setTask(task proto.VolumeTask) {
+	v.mu.Lock()
+	v.tasks[task.GetTaskID()] = &volumeTaskInfo{task: task}
+	v.mu.Unlock()
+}
+
+// acquireTask leases a running task which is not leased by any worker, the locations
+// are refreshed by source and target clustermgr, volume units may have been migrated
+// since prepared.
+func (v *volumeTasks) acquireTask(ctx context.Context, sourceCli, targetCli client.ClusterMgrAPI) (proto.VolumeTask, error) {
+	if !v.taskSwitch.Enabled() {
+		return nil, proto.ErrTaskPaused
+	}
+
+	v.mu.Lock()
+	var task proto.VolumeTask
+	for _, info := range v.tasks {
+		if info.task.Running() && !info.leased() {
+			info.renewal()
+			task = info.task.CopyTask()
+			break
+		}
+	}
+	v.mu.Unlock()
+	if task == nil {
+		return nil, proto.ErrTaskEmpty
+	}
+
+	source, err := sourceCli.GetVolumeInfo(ctx, task.GetSourceVid())
+	if err != nil {
+		v.releaseLease(task.GetTaskID())
+		return nil, err
+	}
+	target, err := targetCli.GetVolumeInfo(ctx, task.GetTargetVid())
+	if err != nil {
+		v.releaseLease(task.GetTaskID())
+		return nil, err
+	}
+	task.SetVunitLocations(source.VunitLocations, target.VunitLocations)
+
+	trace.SpanFromContextSafe(ctx).Infof("acquire volume task: task_id[%s]", task.GetTaskID())
+	return task, nil
+}
+
+// RenewalTask renewal the lease of running task
+func (v *volumeTasks) RenewalTask(ctx context.Context, idc, taskID string) error {
+	if !v.taskSwitch.Enabled() {
+		return proto.ErrTaskPaused
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	info, ok := v.tasks[taskID]
+	if !ok || !info.task.Running() {
+		return v.errNoSuchTask
+	}
+	if !info.leased() {
+		return v.errLeaseExpired
+	}
+	info.renewal()
+	return nil
+}
+
+// runningTask returns a copy of the running task to be completed
+func (v *volumeTasks) runningTask(taskID string) (proto.VolumeTask, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	info, ok := v.tasks[taskID]
+	if !ok || !info.task.Running() {
+		return nil, v.errNoSuchTask
+	}
+	return info.task.CopyTask(), nil
+}
+
+// redoTask gives the lease of task back, the task is acquired by worker again if it
+// is still running, or its volumes are unlocked if it will be prepared again.
+func (v *volumeTasks) redoTask(ctx context.Context, task proto.VolumeTask) error {
+	if err := v.updateTask(ctx, task); err != nil {
+		return err
+	}
+	if task.GetState() == proto.MigrateStateInited {
+		v.unlockVolumes(ctx, task)
+	}
+	v.setTask(task)
+	return nil
+}
+
+func (v *volumeTasks) releaseLease(taskID string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if info, ok := v.tasks[taskID]; ok {
+		info.leaseExpire = time.Time{}
+	}
+}
+
+// listTasks returns copies of tasks ordered by source vid
+func (v *volumeTasks) listTasks() []proto.VolumeTask {
+	v.mu.Lock()
+	tasks := make([]proto.VolumeTask, 0, len(v.tasks))
+	for _, info := range v.tasks {
+		tasks = append(tasks, info.task.CopyTask())
+	}
+	v.mu.Unlock()
+
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].GetSourceVid() < tasks[j].GetSourceVid() })
+	return tasks
+}
+
+// initedTasks returns copies of tasks to be prepared
+func (v *volumeTasks) initedTasks() []proto.VolumeTask {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	var tasks []proto.VolumeTask
+	for _, info := range v.tasks {
+		if info.task.GetState() == proto.MigrateStateInited {
+			tasks = append(tasks, info.task.CopyTask())
+		}
+	}
+	return tasks
+}
@@ -0,0 +1,163 @@
+// Copyright 2022 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/blobstore/common/codemode"
+	"github.com/cubefs/cubefs/blobstore/common/proto"
+	"github.com/cubefs/cubefs/blobstore/scheduler/client"
+	"github.com/cubefs/cubefs/blobstore/testing/mocks"
+)
+
+var (
+	errMockNoSuchTask   = errors.New("mock no such task")
+	errMockLeaseExpired = errors.New("mock lease expired")
+)
+
+type mockVolumeTasks struct {
+	volumeTasks
+	updated  []proto.VolumeTask
+	unlocked []proto.VolumeTask
+}
+
+func newMockVolumeTasks(t *testing.T, enabled bool) *mockVolumeTasks {
+	taskSwitch := mocks.NewMockSwitcher(gomock.NewController(t))
+	taskSwitch.EXPECT().Enabled().AnyTimes().Return(enabled)
+	v := &mockVolumeTasks{}
+	v.volumeTasks = newVolumeTasks(taskSwitch, errMockNoSuchTask, errMockLeaseExpired,
+		func(_ context.Context, task proto.VolumeTask) error {
+			if task.GetTaskID() == "" {
+				return errMock
+			}
+			v.updated = append(v.updated, task)
+			return nil
+		},
+		func(_ context.Context, task proto.VolumeTask) {
+			v.unlocked = append(v.unlocked, task)
+		})
+	return v
+}
+
+func TestVolumeTasksAcquire(t *testing.T) {
+	ctx := context.Background()
+	cmCli := NewMockClusterMgrAPI(gomock.NewController(t))
+	targetCli := NewMockClusterMgrAPI(gomock.NewController(t))
+
+	paused := newMockVolumeTasks(t, false)
+	_, err := paused.acquireTask(ctx, cmCli, targetCli)
+	require.ErrorIs(t, err, proto.ErrTaskPaused)
+	require.ErrorIs(t, paused.RenewalTask(ctx, "", "volume_compact-1-a"), proto.ErrTaskPaused)
+
+	v := newMockVolumeTasks(t, true)
+	v.setTask(&proto.VolumeCompactTask{TaskID: "volume_compact-1-a", State: proto.MigrateStateInited, SourceVid: 1})
+	_, err = v.acquireTask(ctx, cmCli, targetCli)
+	require.ErrorIs(t, err, proto.ErrTaskEmpty)
+
+	v.setTask(&proto.VolumeCompactTask{TaskID: "volume_compact-1-a", State: proto.MigrateStatePrepared, SourceVid: 1, TargetVid: 2})
+	// the lease is given back if locations failed to refresh
+	cmCli.EXPECT().GetVolumeInfo(any, proto.Vid(1)).Return(nil, errMock)
+	_, err = v.acquireTask(ctx, cmCli, targetCli)
+	require.ErrorIs(t, err, errMock)
+	cmCli.EXPECT().GetVolumeInfo(any, proto.Vid(1)).Return(MockGenVolInfo(1, codemode.EC6P6, proto.VolumeStatusLock), nil)
+	targetCli.EXPECT().GetVolumeInfo(any, proto.Vid(2)).Return(nil, errMock)
+	_, err = v.acquireTask(ctx, cmCli, targetCli)
+	require.ErrorIs(t, err, errMock)
+
+	// the source is refreshed by source cluster and the target by target cluster
+	source := MockGenVolInfo(1, codemode.EC6P6, proto.VolumeStatusLock)
+	target := MockGenVolInfo(2, codemode.EC6P6, proto.VolumeStatusLock)
+	cmCli.EXPECT().GetVolumeInfo(any, proto.Vid(1)).Return(source, nil)
+	targetCli.EXPECT().GetVolumeInfo(any, proto.Vid(2)).Return(target, nil)
+	acquired, err := v.acquireTask(ctx, cmCli, targetCli)
+	require.NoError(t, err)
+	task := acquired.(*proto.VolumeCompactTask)
+	require.Equal(t, source.VunitLocations, task.Sources)
+	require.Equal(t, target.VunitLocations, task.Destinations)
+	// the acquired task is a copy
+	require.Nil(t, v.listTasks()[0].(*proto.VolumeCompactTask).Sources)
+
+	// leased to one worker at a time
+	_, err = v.acquireTask(ctx, cmCli, targetCli)
+	require.ErrorIs(t, err, proto.ErrTaskEmpty)
+	require.NoError(t, v.RenewalTask(ctx, "", task.TaskID))
+	require.ErrorIs(t, v.RenewalTask(ctx, "", "volume_compact-0-x"), errMockNoSuchTask)
+
+	// acquired by another worker after the lease expired
+	v.releaseLease(task.TaskID)
+	require.ErrorIs(t, v.RenewalTask(ctx, "", task.TaskID), errMockLeaseExpired)
+	cmCli.EXPECT().GetVolumeInfo(any, proto.Vid(1)).Return(source, nil)
+	targetCli.EXPECT().GetVolumeInfo(any, proto.Vid(2)).Return(target, nil)
+	_, err = v.acquireTask(ctx, cmCli, targetCli)
+	require.NoError(t, err)
+}
+
+func TestVolumeTasksRedo(t *testing.T) {
+	ctx := context.Background()
+	v := newMockVolumeTasks(t, true)
+
+	v.setTask(&proto.VolumeCompactTask{TaskID: "volume_compact-1-a", State: proto.MigrateStateInited, SourceVid: 1})
+	_, err := v.runningTask("volume_compact-1-a")
+	require.ErrorIs(t, err, errMockNoSuchTask)
+	_, err = v.runningTask("volume_compact-0-x")
+	require.ErrorIs(t, err, errMockNoSuchTask)
+
+	v.setTask(&proto.VolumeCompactTask{TaskID: "volume_compact-1-a", State: proto.MigrateStatePrepared, SourceVid: 1, TargetVid: 2})
+	v.tasks["volume_compact-1-a"].renewal()
+	running, err := v.runningTask("volume_compact-1-a")
+	require.NoError(t, err)
+	task := running.(*proto.VolumeCompactTask)
+	task.WorkerRedoCnt++
+	require.Equal(t, uint8(0), v.listTasks()[0].(*proto.VolumeCompactTask).WorkerRedoCnt)
+
+	// nothing changed if the task failed to persist
+	require.ErrorIs(t, v.redoTask(ctx, &proto.VolumeCompactTask{State: proto.MigrateStateInited}), errMock)
+	require.Empty(t, v.unlocked)
+
+	// redo the running task, the volumes are kept locked and the lease is given back
+	require.NoError(t, v.redoTask(ctx, task))
+	require.Equal(t, 1, len(v.updated))
+	require.Empty(t, v.unlocked)
+	require.Equal(t, uint8(1), v.listTasks()[0].(*proto.VolumeCompactTask).WorkerRedoCnt)
+	require.ErrorIs(t, v.RenewalTask(ctx, "", task.TaskID), errMockLeaseExpired)
+	require.Empty(t, v.initedTasks())
+
+	// prepare again, the volumes are unlocked
+	task = task.Copy()
+	task.State = proto.MigrateStateInited
+	require.NoError(t, v.redoTask(ctx, task))
+	require.Equal(t, 1, len(v.unlocked))
+	require.Equal(t, proto.Vid(2), v.unlocked[0].GetTargetVid())
+	require.Equal(t, 1, len(v.initedTasks()))
+	require.ErrorIs(t, v.RenewalTask(ctx, "", task.TaskID), errMockNoSuchTask)
+}
+
+func TestVolumeTasksList(t *testing.T) {
+	v := newMockVolumeTasks(t, true)
+	for _, vid := range []proto.Vid{3, 1, 2} {
+		v.setTask(&proto.VolumeCompactTask{TaskID: client.GenVolumeCompactTaskID(vid), SourceVid: vid})
+	}
+	tasks := v.listTasks()
+	require.Equal(t, 3, len(tasks))
+	for idx, task := range tasks {
+		require.Equal(t, proto.Vid(idx+1), task.GetSourceVid())
+	}
+}
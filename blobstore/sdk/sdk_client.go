@@ -225,6 +225,32 @@ func (s *sdkHandler) Alloc(ctx context.Context, args *acapi.AllocArgs) (acapi.Al
 	return s.doAlloc(ctx, args)
 }
 
+func (s *sdkHandler) Convert(ctx context.Context, args *acapi.ConvertArgs) (acapi.Location, error) {
+	if !args.IsValid() {
+		return acapi.Location{}, errcode.ErrIllegalArguments
+	}
+
+	ctx = acapi.ClientWithReqidContext(ctx)
+	span := trace.SpanFromContextSafe(ctx)
+	span.Debugf("accept sdk convert request args: %+v", args)
+	if !stream.LocationCrcVerify(&args.Location) {
+		span.Infof("invalid crc %+v", args.Location)
+		return acapi.Location{}, errcode.ErrIllegalArguments
+	}
+
+	loc, err := s.handler.Convert(ctx, &args.Location, args.CodeMode)
+	if err != nil {
+		span.Error("stream convert failed", errors.Detail(err))
+		return acapi.Location{}, httpError(err)
+	}
+	if err = stream.LocationCrcFill(loc); err != nil {
+		span.Error("stream convert fill location crc", err)
+		return acapi.Location{}, httpError(err)
+	}
+	span.Infof("done sdk convert request location:%+v", loc)
+	return *loc, nil
+}
+
 // sign generate crc with locations
 func (s *sdkHandler) sign(ctx context.Context, args *acapi.SignArgs) (acapi.SignResp, error) {
 	if !args.IsValid() {
@@ -436,7 +462,7 @@ func (s *sdkHandler) doPutObject(ctx context.Context, args *acapi.PutArgs) (acap
 	}
 
 	rc := s.limiter.Reader(ctx, args.Body)
-	loc, err := s.handler.Put(ctx, rc, args.Size, hasherMap)
+	loc, err := s.handler.Put(ctx, rc, args.Size, hasherMap, args.Expire)
 	if err != nil {
 		span.Error("stream put failed", errors.Detail(err))
 		err = httpError(err)
@@ -482,7 +508,7 @@ func (s *sdkHandler) doPutAt(ctx context.Context, args *acapi.PutAtArgs) (hashSu
 	}
 
 	rc := s.limiter.Reader(ctx, args.Body)
-	err = s.handler.PutAt(ctx, rc, args.ClusterID, args.Vid, args.BlobID, args.Size, hasherMap)
+	err = s.handler.PutAt(ctx, rc, args.ClusterID, args.Vid, args.BlobID, args.Size, hasherMap, args.Expire)
 	if err != nil {
 		span.Error("stream putat failed ", errors.Detail(err))
 		return nil, err
@@ -501,7 +527,7 @@ func (s *sdkHandler) doAlloc(ctx context.Context, args *acapi.AllocArgs) (resp a
 	span := trace.SpanFromContextSafe(ctx)
 	span.Debugf("accept sdk alloc request args:%+v", args)
 
-	location, err := s.handler.Alloc(ctx, args.Size, args.BlobSize, args.AssignClusterID, args.CodeMode)
+	location, err := s.handler.Alloc(ctx, args.Size, args.BlobSize, args.AssignClusterID, args.CodeMode, args.Expire)
 	if err != nil {
 		span.Error("stream alloc failed ", errors.Detail(err))
 		return resp, err
@@ -522,12 +548,13 @@ func (s *sdkHandler) doAlloc(ctx context.Context, args *acapi.AllocArgs) (resp a
 }
 
 type blobPart struct {
-	cid   proto.ClusterID
-	vid   proto.Vid
-	bid   proto.BlobID
-	size  int
-	token string
-	buf   []byte
+	cid    proto.ClusterID
+	vid    proto.Vid
+	bid    proto.BlobID
+	size   int
+	token  string
+	expire int64
+	buf    []byte
 }
 
 func (s *sdkHandler) putPartsBatch(ctx context.Context, parts []blobPart) error {
@@ -542,6 +569,7 @@ func (s *sdkHandler) putPartsBatch(ctx context.Context, parts []blobPart) error
 				Size:      int64(part.size),
 				Hashes:    0,
 				Token:     part.token,
+				Expire:    part.expire,
 				Body:      bytes.NewReader(part.buf),
 			})
 			return err
@@ -645,7 +673,7 @@ func (s *sdkHandler) putParts(ctx context.Context, args *acapi.PutArgs) (acapi.L
 	}()
 
 	// alloc
-	allocResp, err := s.Alloc(ctx, &acapi.AllocArgs{Size: uint64(args.Size)})
+	allocResp, err := s.Alloc(ctx, &acapi.AllocArgs{Size: uint64(args.Size), Expire: args.Expire})
 	if err != nil {
 		return acapi.Location{}, nil, err
 	}
@@ -727,6 +755,7 @@ func (s *sdkHandler) putParts(ctx context.Context, args *acapi.PutArgs) (acapi.L
 				parts[i].cid = loc.ClusterID
 				parts[i].vid = loc.Blobs[currIdx].Vid
 				parts[i].bid = loc.Blobs[currIdx].MinBid + proto.BlobID(currCount)
+				parts[i].expire = loc.Expire
 
 				currCount++
 				if loc.Blobs[currIdx].Count == currCount {
@@ -768,6 +797,7 @@ func (s *sdkHandler) putParts(ctx context.Context, args *acapi.PutArgs) (acapi.L
 					BlobSize:        loc.BlobSize,
 					AssignClusterID: loc.ClusterID,
 					CodeMode:        loc.CodeMode,
+					Expire:          loc.Expire,
 				})
 				if err1 != nil {
 					return true, err1
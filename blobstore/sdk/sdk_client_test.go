@@ -236,7 +236,7 @@ func TestSdkHandler_Put(t *testing.T) {
 	args := &acapi.PutArgs{Size: 2}
 
 	// stream put error
-	hd.handler.(*mocks.MockStreamHandler).EXPECT().Put(any, any, any, any).Return(nil, errMock)
+	hd.handler.(*mocks.MockStreamHandler).EXPECT().Put(any, any, any, any, any).Return(nil, errMock)
 	loc, hash, err = hd.Put(ctx, args)
 	require.NotNil(t, err)
 	require.Equal(t, uint64(0), loc.Size)
@@ -245,7 +245,7 @@ func TestSdkHandler_Put(t *testing.T) {
 	// ok
 	args.Hashes = 1
 	mockLoc := acapi.Location{Size: 2}
-	hd.handler.(*mocks.MockStreamHandler).EXPECT().Put(any, any, any, any).Return(&mockLoc, nil)
+	hd.handler.(*mocks.MockStreamHandler).EXPECT().Put(any, any, any, any, any).Return(&mockLoc, nil)
 	loc, hash, err = hd.Put(ctx, args)
 	require.NoError(t, err)
 	require.Equal(t, mockLoc.Size, loc.Size)
@@ -255,7 +255,7 @@ func TestSdkHandler_Put(t *testing.T) {
 	args.GetBody = func() (io.ReadCloser, error) {
 		return nil, nil
 	}
-	hd.handler.(*mocks.MockStreamHandler).EXPECT().Put(any, any, any, any).Return(nil, errMock).Times(3)
+	hd.handler.(*mocks.MockStreamHandler).EXPECT().Put(any, any, any, any, any).Return(nil, errMock).Times(3)
 	loc, hash, err = hd.Put(ctx, args)
 	require.NotNil(t, err)
 	require.Equal(t, uint64(0), loc.Size)
@@ -267,8 +267,8 @@ func TestSdkHandler_Put(t *testing.T) {
 		buff := bytes.NewBuffer(data)
 		return io.NopCloser(buff), nil
 	}
-	hd.handler.(*mocks.MockStreamHandler).EXPECT().Put(any, any, any, any).Return(nil, errMock)
-	hd.handler.(*mocks.MockStreamHandler).EXPECT().Put(any, any, any, any).Return(&mockLoc, nil)
+	hd.handler.(*mocks.MockStreamHandler).EXPECT().Put(any, any, any, any, any).Return(nil, errMock)
+	hd.handler.(*mocks.MockStreamHandler).EXPECT().Put(any, any, any, any, any).Return(&mockLoc, nil)
 	loc, hash, err = hd.Put(ctx, args)
 	require.NoError(t, err)
 	require.Equal(t, mockLoc.Size, loc.Size)
@@ -293,7 +293,7 @@ func TestSdkHandler_Alloc(t *testing.T) {
 		AssignClusterID: 1,
 		CodeMode:        codemode.EC3P3,
 	}
-	hd.handler.(*mocks.MockStreamHandler).EXPECT().Alloc(any, any, any, any, any).Return(nil, errMock)
+	hd.handler.(*mocks.MockStreamHandler).EXPECT().Alloc(any, any, any, any, any, any).Return(nil, errMock)
 	ret, err := hd.Alloc(ctx, args)
 	require.NotNil(t, err)
 	require.Equal(t, acapi.Location{}, ret.Location)
@@ -305,7 +305,7 @@ func TestSdkHandler_Alloc(t *testing.T) {
 	}
 	crc, _ := stream.LocationCrcCalculate(loca)
 	loca.Crc = crc
-	hd.handler.(*mocks.MockStreamHandler).EXPECT().Alloc(any, any, any, any, any).Return(loca, nil)
+	hd.handler.(*mocks.MockStreamHandler).EXPECT().Alloc(any, any, any, any, any, any).Return(loca, nil)
 	ret, err = hd.Alloc(ctx, args)
 	require.NoError(t, err)
 	require.Equal(t, *loca, ret.Location)
@@ -322,7 +322,7 @@ func TestSdkHandler_putParts(t *testing.T) {
 
 	// alloc fail
 	args.Hashes = 1
-	hd.handler.(*mocks.MockStreamHandler).EXPECT().Alloc(any, any, any, any, any).Return(nil, errMock)
+	hd.handler.(*mocks.MockStreamHandler).EXPECT().Alloc(any, any, any, any, any, any).Return(nil, errMock)
 	loc, hash, err := hd.Put(ctx, args)
 	require.NotNil(t, err)
 	require.ErrorIs(t, err, errMock)
@@ -345,15 +345,15 @@ func TestSdkHandler_putParts(t *testing.T) {
 	}
 	crc, _ := stream.LocationCrcCalculate(loca)
 	loca.Crc = crc
-	hd.handler.(*mocks.MockStreamHandler).EXPECT().Alloc(any, any, any, any, any).Return(loca, nil)
-	hd.handler.(*mocks.MockStreamHandler).EXPECT().PutAt(any, any, any, any, any, any, any).Return(nil).Times(14/4 + 1)
+	hd.handler.(*mocks.MockStreamHandler).EXPECT().Alloc(any, any, any, any, any, any).Return(loca, nil)
+	hd.handler.(*mocks.MockStreamHandler).EXPECT().PutAt(any, any, any, any, any, any, any, any).Return(nil).Times(14/4 + 1)
 	loc, hash, err = hd.Put(ctx, args)
 	require.NoError(t, err)
 	require.Equal(t, 1, len(hash))
 	require.Equal(t, *loca, loc)
 
 	// waiting at least one blob, errcode.ErrAccessReadRequestBody
-	hd.handler.(*mocks.MockStreamHandler).EXPECT().Alloc(any, any, any, any, any).Return(loca, nil)
+	hd.handler.(*mocks.MockStreamHandler).EXPECT().Alloc(any, any, any, any, any, any).Return(loca, nil)
 	hd.handler.(*mocks.MockStreamHandler).EXPECT().Delete(any, any).Return(nil)
 	_, _, err = hd.Put(ctx, args)
 	require.NotNil(t, err)
@@ -362,8 +362,8 @@ func TestSdkHandler_putParts(t *testing.T) {
 	// alloc the rest parts failed
 	loca.CodeMode = codemode.EC3P3
 	args.Body = bytes.NewBuffer([]byte(data))
-	hd.handler.(*mocks.MockStreamHandler).EXPECT().Alloc(any, any, any, any, any).Return(loca, nil).Times(4) // init, retry3
-	hd.handler.(*mocks.MockStreamHandler).EXPECT().PutAt(any, any, any, any, any, any, any).Return(errMock).Times(4)
+	hd.handler.(*mocks.MockStreamHandler).EXPECT().Alloc(any, any, any, any, any, any).Return(loca, nil).Times(4) // init, retry3
+	hd.handler.(*mocks.MockStreamHandler).EXPECT().PutAt(any, any, any, any, any, any, any, any).Return(errMock).Times(4)
 	hd.handler.(*mocks.MockStreamHandler).EXPECT().Delete(any, any).Return(nil).Times(4 + 1) // 4 blobs, fail del
 	_, _, err = hd.Put(ctx, args)
 	require.NotNil(t, err)
@@ -373,7 +373,7 @@ func TestSdkHandler_putParts(t *testing.T) {
 	{
 		loca.CodeMode = codemode.EC3P3
 		args.Body = bytes.NewBuffer([]byte(data))
-		hd.handler.(*mocks.MockStreamHandler).EXPECT().Alloc(any, any, any, any, any).Return(loca, nil)
+		hd.handler.(*mocks.MockStreamHandler).EXPECT().Alloc(any, any, any, any, any, any).Return(loca, nil)
 		locb := &acapi.Location{
 			ClusterID: 1,
 			Size:      uint64(args.Size),
@@ -385,12 +385,42 @@ func TestSdkHandler_putParts(t *testing.T) {
 			}},
 		}
 		stream.LocationCrcFill(locb)
-		hd.handler.(*mocks.MockStreamHandler).EXPECT().Alloc(any, any, any, any, any).Return(locb, nil)
-		hd.handler.(*mocks.MockStreamHandler).EXPECT().PutAt(any, any, any, any, any, any, any).Return(errMock).Times(1)
-		hd.handler.(*mocks.MockStreamHandler).EXPECT().PutAt(any, any, any, any, any, any, any).Return(nil).AnyTimes()
+		hd.handler.(*mocks.MockStreamHandler).EXPECT().Alloc(any, any, any, any, any, any).Return(locb, nil)
+		hd.handler.(*mocks.MockStreamHandler).EXPECT().PutAt(any, any, any, any, any, any, any, any).Return(errMock).Times(1)
+		hd.handler.(*mocks.MockStreamHandler).EXPECT().PutAt(any, any, any, any, any, any, any, any).Return(nil).AnyTimes()
 		hd.handler.(*mocks.MockStreamHandler).EXPECT().Delete(any, any).Return(nil).Times(4 + 1 + 1) // 4 blobs, fail del
 		_, _, err = hd.Put(ctx, args)
 		require.NotNil(t, err)
 		require.ErrorIs(t, err, errcode.ErrUnexpected)
 	}
 }
+
+func TestSdkHandler_Convert(t *testing.T) {
+	any := gomock.Any()
+	errMock := errors.New("fake error")
+	ctx := context.Background()
+	hd := newSdkHandler()
+
+	_, err := hd.Convert(ctx, nil)
+	require.ErrorIs(t, err, errcode.ErrIllegalArguments)
+
+	args := &acapi.ConvertArgs{
+		Location: acapi.Location{Size: 1, BlobSize: 1, CodeMode: codemode.EC6P6, Blobs: []acapi.SliceInfo{{MinBid: 1, Vid: 1, Count: 1}}},
+		CodeMode: codemode.EC6P10L2,
+	}
+	_, err = hd.Convert(ctx, args)
+	require.ErrorIs(t, err, errcode.ErrIllegalArguments)
+
+	require.NoError(t, stream.LocationCrcFill(&args.Location))
+	hd.handler.(*mocks.MockStreamHandler).EXPECT().Convert(any, any, any).Return(nil, errMock)
+	_, err = hd.Convert(ctx, args)
+	require.Error(t, err)
+
+	newLoc := args.Location.Copy()
+	newLoc.CodeMode = codemode.EC6P10L2
+	hd.handler.(*mocks.MockStreamHandler).EXPECT().Convert(any, any, any).Return(&newLoc, nil)
+	loc, err := hd.Convert(ctx, args)
+	require.NoError(t, err)
+	require.Equal(t, codemode.EC6P10L2, loc.CodeMode)
+	require.True(t, stream.LocationCrcVerify(&loc))
+}
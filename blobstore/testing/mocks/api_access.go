@@ -36,6 +36,21 @@ func (m *MockAccessAPI) EXPECT() *MockAccessAPIMockRecorder {
 	return m.recorder
 }
 
+// Convert mocks base method.
+func (m *MockAccessAPI) Convert(arg0 context.Context, arg1 *access.ConvertArgs) (access.Location, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Convert", arg0, arg1)
+	ret0, _ := ret[0].(access.Location)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Convert indicates an expected call of Convert.
+func (mr *MockAccessAPIMockRecorder) Convert(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Convert", reflect.TypeOf((*MockAccessAPI)(nil).Convert), arg0, arg1)
+}
+
 // Delete mocks base method.
 func (m *MockAccessAPI) Delete(arg0 context.Context, arg1 *access.DeleteArgs) ([]access.Location, error) {
 	m.ctrl.T.Helper()
@@ -36,6 +36,20 @@ func (m *MockProxyClient) EXPECT() *MockProxyClientMockRecorder {
 	return m.recorder
 }
 
+// CommitMsg mocks base method.
+func (m *MockProxyClient) CommitMsg(arg0 context.Context, arg1 string, arg2 *proxy.CommitMsgArgs) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CommitMsg", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CommitMsg indicates an expected call of CommitMsg.
+func (mr *MockProxyClientMockRecorder) CommitMsg(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitMsg", reflect.TypeOf((*MockProxyClient)(nil).CommitMsg), arg0, arg1, arg2)
+}
+
 // Erase mocks base method.
 func (m *MockProxyClient) Erase(arg0 context.Context, arg1, arg2 string) error {
 	m.ctrl.T.Helper()
@@ -50,6 +64,21 @@ func (mr *MockProxyClientMockRecorder) Erase(arg0, arg1, arg2 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Erase", reflect.TypeOf((*MockProxyClient)(nil).Erase), arg0, arg1, arg2)
 }
 
+// FetchMsg mocks base method.
+func (m *MockProxyClient) FetchMsg(arg0 context.Context, arg1 string, arg2 *proxy.FetchMsgArgs) (proxy.FetchMsgRet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchMsg", arg0, arg1, arg2)
+	ret0, _ := ret[0].(proxy.FetchMsgRet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchMsg indicates an expected call of FetchMsg.
+func (mr *MockProxyClientMockRecorder) FetchMsg(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchMsg", reflect.TypeOf((*MockProxyClient)(nil).FetchMsg), arg0, arg1, arg2)
+}
+
 // GetCacheDisk mocks base method.
 func (m *MockProxyClient) GetCacheDisk(arg0 context.Context, arg1 string, arg2 *proxy.CacheDiskArgs) (*blobnode.DiskInfo, error) {
 	m.ctrl.T.Helper()
@@ -95,6 +124,20 @@ func (mr *MockProxyClientMockRecorder) ListVolumes(arg0, arg1, arg2 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVolumes", reflect.TypeOf((*MockProxyClient)(nil).ListVolumes), arg0, arg1, arg2)
 }
 
+// ProduceMsg mocks base method.
+func (m *MockProxyClient) ProduceMsg(arg0 context.Context, arg1 string, arg2 *proxy.ProduceMsgArgs) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProduceMsg", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ProduceMsg indicates an expected call of ProduceMsg.
+func (mr *MockProxyClientMockRecorder) ProduceMsg(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProduceMsg", reflect.TypeOf((*MockProxyClient)(nil).ProduceMsg), arg0, arg1, arg2)
+}
+
 // SendDeleteMsg mocks base method.
 func (m *MockProxyClient) SendDeleteMsg(arg0 context.Context, arg1 string, arg2 *proxy.DeleteArgs) error {
 	m.ctrl.T.Helper()
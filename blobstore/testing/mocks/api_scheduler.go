@@ -36,6 +36,36 @@ func (m *MockIScheduler) EXPECT() *MockISchedulerMockRecorder {
 	return m.recorder
 }
 
+// AcquireClusterMigrateTask mocks base method.
+func (m *MockIScheduler) AcquireClusterMigrateTask(arg0 context.Context) (*proto.ClusterMigrateTask, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcquireClusterMigrateTask", arg0)
+	ret0, _ := ret[0].(*proto.ClusterMigrateTask)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcquireClusterMigrateTask indicates an expected call of AcquireClusterMigrateTask.
+func (mr *MockISchedulerMockRecorder) AcquireClusterMigrateTask(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireClusterMigrateTask", reflect.TypeOf((*MockIScheduler)(nil).AcquireClusterMigrateTask), arg0)
+}
+
+// AcquireConvertTask mocks base method.
+func (m *MockIScheduler) AcquireConvertTask(arg0 context.Context) (*proto.CodeModeConvertTask, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcquireConvertTask", arg0)
+	ret0, _ := ret[0].(*proto.CodeModeConvertTask)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcquireConvertTask indicates an expected call of AcquireConvertTask.
+func (mr *MockISchedulerMockRecorder) AcquireConvertTask(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireConvertTask", reflect.TypeOf((*MockIScheduler)(nil).AcquireConvertTask), arg0)
+}
+
 // AcquireInspectTask mocks base method.
 func (m *MockIScheduler) AcquireInspectTask(arg0 context.Context) (*proto.VolumeInspectTask, error) {
 	m.ctrl.T.Helper()
@@ -66,6 +96,36 @@ func (mr *MockISchedulerMockRecorder) AcquireTask(arg0, arg1 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireTask", reflect.TypeOf((*MockIScheduler)(nil).AcquireTask), arg0, arg1)
 }
 
+// AcquireVolumeCompactTask mocks base method.
+func (m *MockIScheduler) AcquireVolumeCompactTask(arg0 context.Context) (*proto.VolumeCompactTask, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcquireVolumeCompactTask", arg0)
+	ret0, _ := ret[0].(*proto.VolumeCompactTask)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcquireVolumeCompactTask indicates an expected call of AcquireVolumeCompactTask.
+func (mr *MockISchedulerMockRecorder) AcquireVolumeCompactTask(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireVolumeCompactTask", reflect.TypeOf((*MockIScheduler)(nil).AcquireVolumeCompactTask), arg0)
+}
+
+// AddConvertTask mocks base method.
+func (m *MockIScheduler) AddConvertTask(arg0 context.Context, arg1 *scheduler.AddConvertTaskArgs) (*scheduler.AddConvertTaskRet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddConvertTask", arg0, arg1)
+	ret0, _ := ret[0].(*scheduler.AddConvertTaskRet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddConvertTask indicates an expected call of AddConvertTask.
+func (mr *MockISchedulerMockRecorder) AddConvertTask(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddConvertTask", reflect.TypeOf((*MockIScheduler)(nil).AddConvertTask), arg0, arg1)
+}
+
 // AddManualMigrateTask mocks base method.
 func (m *MockIScheduler) AddManualMigrateTask(arg0 context.Context, arg1 *scheduler.AddManualMigrateArgs) error {
 	m.ctrl.T.Helper()
@@ -94,6 +154,34 @@ func (mr *MockISchedulerMockRecorder) CancelTask(arg0, arg1 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelTask", reflect.TypeOf((*MockIScheduler)(nil).CancelTask), arg0, arg1)
 }
 
+// CompleteClusterMigrateTask mocks base method.
+func (m *MockIScheduler) CompleteClusterMigrateTask(arg0 context.Context, arg1 *proto.ClusterMigrateRet) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteClusterMigrateTask", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteClusterMigrateTask indicates an expected call of CompleteClusterMigrateTask.
+func (mr *MockISchedulerMockRecorder) CompleteClusterMigrateTask(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteClusterMigrateTask", reflect.TypeOf((*MockIScheduler)(nil).CompleteClusterMigrateTask), arg0, arg1)
+}
+
+// CompleteConvertTask mocks base method.
+func (m *MockIScheduler) CompleteConvertTask(arg0 context.Context, arg1 *proto.CodeModeConvertRet) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteConvertTask", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteConvertTask indicates an expected call of CompleteConvertTask.
+func (mr *MockISchedulerMockRecorder) CompleteConvertTask(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteConvertTask", reflect.TypeOf((*MockIScheduler)(nil).CompleteConvertTask), arg0, arg1)
+}
+
 // CompleteInspectTask mocks base method.
 func (m *MockIScheduler) CompleteInspectTask(arg0 context.Context, arg1 *proto.VolumeInspectRet) error {
 	m.ctrl.T.Helper()
@@ -122,6 +210,20 @@ func (mr *MockISchedulerMockRecorder) CompleteTask(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteTask", reflect.TypeOf((*MockIScheduler)(nil).CompleteTask), arg0, arg1)
 }
 
+// CompleteVolumeCompactTask mocks base method.
+func (m *MockIScheduler) CompleteVolumeCompactTask(arg0 context.Context, arg1 *proto.VolumeCompactRet) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteVolumeCompactTask", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteVolumeCompactTask indicates an expected call of CompleteVolumeCompactTask.
+func (mr *MockISchedulerMockRecorder) CompleteVolumeCompactTask(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteVolumeCompactTask", reflect.TypeOf((*MockIScheduler)(nil).CompleteVolumeCompactTask), arg0, arg1)
+}
+
 // DetailMigrateTask mocks base method.
 func (m *MockIScheduler) DetailMigrateTask(arg0 context.Context, arg1 *scheduler.MigrateTaskDetailArgs) (scheduler.MigrateTaskDetail, error) {
 	m.ctrl.T.Helper()
@@ -152,6 +254,36 @@ func (mr *MockISchedulerMockRecorder) DiskMigratingStats(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiskMigratingStats", reflect.TypeOf((*MockIScheduler)(nil).DiskMigratingStats), arg0, arg1)
 }
 
+// GetConvertedVolume mocks base method.
+func (m *MockIScheduler) GetConvertedVolume(arg0 context.Context, arg1 proto.Vid) (*proto.ConvertedVolume, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConvertedVolume", arg0, arg1)
+	ret0, _ := ret[0].(*proto.ConvertedVolume)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConvertedVolume indicates an expected call of GetConvertedVolume.
+func (mr *MockISchedulerMockRecorder) GetConvertedVolume(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConvertedVolume", reflect.TypeOf((*MockIScheduler)(nil).GetConvertedVolume), arg0, arg1)
+}
+
+// GetMigratedVolume mocks base method.
+func (m *MockIScheduler) GetMigratedVolume(arg0 context.Context, arg1 proto.Vid) (*proto.MigratedVolume, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMigratedVolume", arg0, arg1)
+	ret0, _ := ret[0].(*proto.MigratedVolume)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMigratedVolume indicates an expected call of GetMigratedVolume.
+func (mr *MockISchedulerMockRecorder) GetMigratedVolume(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMigratedVolume", reflect.TypeOf((*MockIScheduler)(nil).GetMigratedVolume), arg0, arg1)
+}
+
 // LeaderStats mocks base method.
 func (m *MockIScheduler) LeaderStats(arg0 context.Context) (scheduler.TasksStat, error) {
 	m.ctrl.T.Helper()
@@ -167,6 +299,51 @@ func (mr *MockISchedulerMockRecorder) LeaderStats(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LeaderStats", reflect.TypeOf((*MockIScheduler)(nil).LeaderStats), arg0)
 }
 
+// ListClusterMigrateTasks mocks base method.
+func (m *MockIScheduler) ListClusterMigrateTasks(arg0 context.Context) (*scheduler.ListClusterMigrateTasksRet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListClusterMigrateTasks", arg0)
+	ret0, _ := ret[0].(*scheduler.ListClusterMigrateTasksRet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListClusterMigrateTasks indicates an expected call of ListClusterMigrateTasks.
+func (mr *MockISchedulerMockRecorder) ListClusterMigrateTasks(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListClusterMigrateTasks", reflect.TypeOf((*MockIScheduler)(nil).ListClusterMigrateTasks), arg0)
+}
+
+// ListConvertTasks mocks base method.
+func (m *MockIScheduler) ListConvertTasks(arg0 context.Context) (*scheduler.ListConvertTasksRet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListConvertTasks", arg0)
+	ret0, _ := ret[0].(*scheduler.ListConvertTasksRet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListConvertTasks indicates an expected call of ListConvertTasks.
+func (mr *MockISchedulerMockRecorder) ListConvertTasks(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListConvertTasks", reflect.TypeOf((*MockIScheduler)(nil).ListConvertTasks), arg0)
+}
+
+// ListVolumeCompactTasks mocks base method.
+func (m *MockIScheduler) ListVolumeCompactTasks(arg0 context.Context) (*scheduler.ListVolumeCompactTasksRet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListVolumeCompactTasks", arg0)
+	ret0, _ := ret[0].(*scheduler.ListVolumeCompactTasksRet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListVolumeCompactTasks indicates an expected call of ListVolumeCompactTasks.
+func (mr *MockISchedulerMockRecorder) ListVolumeCompactTasks(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVolumeCompactTasks", reflect.TypeOf((*MockIScheduler)(nil).ListVolumeCompactTasks), arg0)
+}
+
 // ReclaimTask mocks base method.
 func (m *MockIScheduler) ReclaimTask(arg0 context.Context, arg1 *scheduler.OperateTaskArgs) error {
 	m.ctrl.T.Helper()
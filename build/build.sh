@@ -368,6 +368,20 @@ build_snapshot() {
     popd >/dev/null
 }
 
+build_raftlog() {
+    pushd $SrcPath >/dev/null
+    echo -n "build cfs-raftlog      "
+    CGO_ENABLED=0 go build ${MODFLAGS} -gcflags=all=-trimpath=${SrcPath} -asmflags=all=-trimpath=${SrcPath} -ldflags="${LDFlags}" -o ${BuildBinPath}/cfs-raftlog ${SrcPath}/raftlog/*.go  && echo "success" || echo "failed"
+    popd >/dev/null
+}
+
+build_mirror() {
+    pushd $SrcPath >/dev/null
+    echo -n "build cfs-mirror       "
+    CGO_ENABLED=0 go build ${MODFLAGS} -gcflags=all=-trimpath=${SrcPath} -asmflags=all=-trimpath=${SrcPath} -ldflags="${LDFlags}" -o ${BuildBinPath}/cfs-mirror ${SrcPath}/mirror/*.go  && echo "success" || echo "failed"
+    popd >/dev/null
+}
+
 build_libsdkpre() {
     case `uname` in
         Linux)
@@ -515,6 +529,12 @@ case "$cmd" in
     "snapshot")
         build_snapshot
         ;;
+    "raftlog")
+        build_raftlog
+        ;;
+    "mirror")
+        build_mirror
+        ;;
     "libsdkpre")
         build_libsdkpre
         ;;
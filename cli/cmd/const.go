@@ -129,6 +129,10 @@ const (
 	CliFlagMaxConcurrencyInode     = "maxConcurrencyInode"
 	CliFlagForceInode              = "forceInode"
 	CliFlagEnableQuota             = "enableQuota"
+	CliFlagPermissionMode          = "permission-mode"
+	CliFlagEnableDedup             = "enable-dedup"
+	CliFlagEnableDefrag            = "enable-defrag"
+	CliFlagEnableMirror            = "enable-mirror"
 	CliFlagDeleteLockTime          = "delete-lock-time"
 	CliFlagClientIDKey             = "clientIDKey"
 	CliFlagMarkDiskBrokenThreshold = "markBrokenDiskThreshold"
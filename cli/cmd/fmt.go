@@ -178,6 +178,10 @@ func formatSimpleVolView(svv *proto.SimpleVolView) string {
 	sb.WriteString(fmt.Sprintf("  DpRepairBlockSize               : %v\n", strutil.FormatSize(svv.DpRepairBlockSize)))
 	sb.WriteString(fmt.Sprintf("  EnableAutoDpMetaRepair          : %v\n", svv.EnableAutoDpMetaRepair))
 	sb.WriteString(fmt.Sprintf("  Quota                           : %v\n", formatEnabledDisabled(svv.EnableQuota)))
+	sb.WriteString(fmt.Sprintf("  PermissionMode                  : %v\n", formatPermissionMode(svv.PermissionMode)))
+	sb.WriteString(fmt.Sprintf("  Dedup                           : %v\n", formatEnabledDisabled(svv.EnableDedup)))
+	sb.WriteString(fmt.Sprintf("  Defrag                          : %v\n", formatEnabledDisabled(svv.EnableDefrag)))
+	sb.WriteString(fmt.Sprintf("  Mirror                          : %v\n", formatEnabledDisabled(svv.EnableMirror)))
 	if svv.Forbidden && svv.Status == 1 {
 		sb.WriteString(fmt.Sprintf("  DeleteDelayTime                 : %v\n", time.Until(svv.DeleteExecTime)))
 	}
@@ -242,6 +246,26 @@ func formatUidInfoTableRow(uidInfo *proto.UidSpaceInfo) string {
 		uidInfo.Uid, time.Unix(uidInfo.CTime, 0).Format(time.RFC1123), uidInfo.Enabled, uidInfo.Limited, uidInfo.LimitSize, uidInfo.UsedSize)
 }
 
+var (
+	volumeUidUserPattern     = "%-32v    %-10v    %-10v"
+	volumeUidUserTableHeader = fmt.Sprintf(volumeUidUserPattern, "USER", "UID", "GID")
+)
+
+func formatUidUserTableRow(userInfo *proto.UidUserInfo) string {
+	return fmt.Sprintf(volumeUidUserPattern, userInfo.UserID, userInfo.Uid, userInfo.Gid)
+}
+
+func formatPermissionMode(mode uint8) string {
+	switch mode {
+	case proto.PermissionModeIndependent:
+		return "independent"
+	case proto.PermissionModeUnified:
+		return "unified"
+	default:
+		return "unknown"
+	}
+}
+
 func formatVerInfoTableRow(verInfo *proto.VolVersionInfo) string {
 	return fmt.Sprintf(volumeVersionPattern,
 		verInfo.Ver, time.UnixMicro(int64(verInfo.Ver)).Local().Format(time.RFC1123), verInfo.Status, "")
@@ -15,12 +15,20 @@ const (
 	cmdUidListShort  = "list volume uid info list"
 	cmdUidCheckShort = "check volume uid"
 
+	cmdUidUserAddShort  = "map the S3 user to the uid and gid of volume"
+	cmdUidUserDelShort  = "remove the uid and gid mapping of the S3 user"
+	cmdUidUserListShort = "list the uid and gid mappings of the S3 users"
+
 	// uid op
 	CliUidAdd       = "add"
 	cliUidListShort = "list"
 	CliUidDel       = "del"
 	CliUidCheck     = "check"
 
+	CliUidUserAdd  = "user-add"
+	CliUidUserDel  = "user-del"
+	CliUidUserList = "user-list"
+
 	// param
 	uidAll = "all"
 )
@@ -37,6 +45,9 @@ func newUidCmd(client *master.MasterClient) *cobra.Command {
 		newUidDelCmd(client),
 		newUidListCmd(client),
 		newUidCheckCmd(client),
+		newUidUserAddCmd(client),
+		newUidUserDelCmd(client),
+		newUidUserListCmd(client),
 	)
 	return cmd
 }
@@ -165,3 +176,66 @@ func newUidCheckCmd(client *master.MasterClient) *cobra.Command {
 	cmd.Flags().StringVar(&optKeyword, "keyword", "", "Specify keyword of volume name to filter")
 	return cmd
 }
+
+func newUidUserAddCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   CliUidUserAdd + " [VOLUME] [USER ID] [UID] [GID]",
+		Short: cmdUidUserAddShort,
+		Args:  cobra.MinimumNArgs(4),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			defer func() {
+				errout(err)
+			}()
+			var uidInfo *proto.UidSpaceRsp
+			if uidInfo, err = client.UserAPI().UidUserOperation(args[0], args[1], util.UidUserAdd, args[2], args[3]); err != nil || !uidInfo.OK {
+				return
+			}
+			stdout("success!\n")
+		},
+	}
+	return cmd
+}
+
+func newUidUserDelCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   CliUidUserDel + " [VOLUME] [USER ID]",
+		Short: cmdUidUserDelShort,
+		Args:  cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			defer func() {
+				errout(err)
+			}()
+			var uidInfo *proto.UidSpaceRsp
+			if uidInfo, err = client.UserAPI().UidUserOperation(args[0], args[1], util.UidUserDel, "", ""); err != nil || !uidInfo.OK {
+				return
+			}
+			stdout("success!\n")
+		},
+	}
+	return cmd
+}
+
+func newUidUserListCmd(client *master.MasterClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   CliUidUserList + " [VOLUME]",
+		Short: cmdUidUserListShort,
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			defer func() {
+				errout(err)
+			}()
+			var uidInfo *proto.UidSpaceRsp
+			if uidInfo, err = client.UserAPI().UidUserOperation(args[0], "", util.UidUserList, "", ""); err != nil || !uidInfo.OK {
+				return
+			}
+			stdout("%v\n", volumeUidUserTableHeader)
+			for _, info := range uidInfo.UidUserArr {
+				stdout("%v\n", formatUidUserTableRow(info))
+			}
+		},
+	}
+	return cmd
+}
@@ -292,6 +292,10 @@ func newVolUpdateCmd(client *master.MasterClient) *cobra.Command {
 	var optReplicaNum string
 	var optDeleteLockTime int64
 	var optEnableQuota string
+	var optPermissionMode string
+	var optEnableDedup string
+	var optEnableDefrag string
+	var optEnableMirror string
 	var optEnableDpAutoMetaRepair string
 	confirmString := strings.Builder{}
 	var vv *proto.SimpleVolView
@@ -409,6 +413,80 @@ func newVolUpdateCmd(client *master.MasterClient) *cobra.Command {
 			}
 			confirmString.WriteString(fmt.Sprintf("  EnableQuota : %v\n", formatEnabledDisabled(vv.EnableQuota)))
 
+			if optPermissionMode != "" {
+				var mode uint8
+				switch optPermissionMode {
+				case formatPermissionMode(proto.PermissionModeIndependent):
+					mode = proto.PermissionModeIndependent
+				case formatPermissionMode(proto.PermissionModeUnified):
+					mode = proto.PermissionModeUnified
+				default:
+					err = fmt.Errorf("permission mode must be independent or unified")
+					return
+				}
+				if mode != vv.PermissionMode {
+					isChange = true
+					confirmString.WriteString(fmt.Sprintf("  PermissionMode : %v -> %v\n",
+						formatPermissionMode(vv.PermissionMode), formatPermissionMode(mode)))
+					vv.PermissionMode = mode
+				} else {
+					confirmString.WriteString(fmt.Sprintf("  PermissionMode : %v\n", formatPermissionMode(vv.PermissionMode)))
+				}
+			} else {
+				confirmString.WriteString(fmt.Sprintf("  PermissionMode : %v\n", formatPermissionMode(vv.PermissionMode)))
+			}
+
+			if optEnableDedup != "" {
+				var enable bool
+				if enable, err = strconv.ParseBool(optEnableDedup); err != nil {
+					return
+				}
+				if enable != vv.EnableDedup {
+					isChange = true
+					confirmString.WriteString(fmt.Sprintf("  Dedup : %v -> %v\n",
+						formatEnabledDisabled(vv.EnableDedup), formatEnabledDisabled(enable)))
+					vv.EnableDedup = enable
+				} else {
+					confirmString.WriteString(fmt.Sprintf("  Dedup : %v\n", formatEnabledDisabled(vv.EnableDedup)))
+				}
+			} else {
+				confirmString.WriteString(fmt.Sprintf("  Dedup : %v\n", formatEnabledDisabled(vv.EnableDedup)))
+			}
+
+			if optEnableDefrag != "" {
+				var enable bool
+				if enable, err = strconv.ParseBool(optEnableDefrag); err != nil {
+					return
+				}
+				if enable != vv.EnableDefrag {
+					isChange = true
+					confirmString.WriteString(fmt.Sprintf("  Defrag : %v -> %v\n",
+						formatEnabledDisabled(vv.EnableDefrag), formatEnabledDisabled(enable)))
+					vv.EnableDefrag = enable
+				} else {
+					confirmString.WriteString(fmt.Sprintf("  Defrag : %v\n", formatEnabledDisabled(vv.EnableDefrag)))
+				}
+			} else {
+				confirmString.WriteString(fmt.Sprintf("  Defrag : %v\n", formatEnabledDisabled(vv.EnableDefrag)))
+			}
+
+			if optEnableMirror != "" {
+				var enable bool
+				if enable, err = strconv.ParseBool(optEnableMirror); err != nil {
+					return
+				}
+				if enable != vv.EnableMirror {
+					isChange = true
+					confirmString.WriteString(fmt.Sprintf("  Mirror : %v -> %v\n",
+						formatEnabledDisabled(vv.EnableMirror), formatEnabledDisabled(enable)))
+					vv.EnableMirror = enable
+				} else {
+					confirmString.WriteString(fmt.Sprintf("  Mirror : %v\n", formatEnabledDisabled(vv.EnableMirror)))
+				}
+			} else {
+				confirmString.WriteString(fmt.Sprintf("  Mirror : %v\n", formatEnabledDisabled(vv.EnableMirror)))
+			}
+
 			if optDeleteLockTime >= 0 {
 				if optDeleteLockTime != vv.DeleteLockTime {
 					isChange = true
@@ -658,6 +736,10 @@ func newVolUpdateCmd(client *master.MasterClient) *cobra.Command {
 	cmd.Flags().IntVar(&optTxOpLimitVal, CliTxOpLimit, 0, "Specify limitation[Unit: second] for transaction(default 0 unlimited)")
 	cmd.Flags().StringVar(&optReplicaNum, CliFlagReplicaNum, "", "Specify data partition replicas number(default 3 for normal volume,1 for low volume)")
 	cmd.Flags().StringVar(&optEnableQuota, CliFlagEnableQuota, "", "Enable quota")
+	cmd.Flags().StringVar(&optPermissionMode, CliFlagPermissionMode, "", "Specify permission mode of S3 and POSIX access [independent|unified]")
+	cmd.Flags().StringVar(&optEnableDedup, CliFlagEnableDedup, "", "Allow extent deduplication by fsck dedup [true|false]")
+	cmd.Flags().StringVar(&optEnableDefrag, CliFlagEnableDefrag, "", "Allow online extent defragmentation by datanodes [true|false]")
+	cmd.Flags().StringVar(&optEnableMirror, CliFlagEnableMirror, "", "Allow the volume to be the source of cfs-mirror [true|false]")
 	cmd.Flags().Int64Var(&optDeleteLockTime, CliFlagDeleteLockTime, -1, "Specify delete lock time[Unit: hour] for volume")
 	cmd.Flags().StringVar(&clientIDKey, CliFlagClientIDKey, client.ClientIDKey(), CliUsageClientIDKey)
 	cmd.Flags().StringVar(&optEnableDpAutoMetaRepair, CliFlagAutoDpMetaRepair, "", "Enable or disable dp auto meta repair")
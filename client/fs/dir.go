@@ -899,6 +899,10 @@ func (d *Dir) Setxattr(ctx context.Context, req *fuse.SetxattrRequest) error {
 		log.LogErrorf("Set 'DirStat' is not supported.")
 		return fuse.ENOSYS
 	}
+	if proto.IsObjectLockXAttr(name) && d.super.mw.ObjectLockEnabled() {
+		log.LogErrorf("Setxattr: object lock attribute is not allowed to modify: ino(%v) name(%v)", ino, name)
+		return fuse.EPERM
+	}
 	// TODO： implement flag to improve compatible (Mofei Zhang)
 	if err = d.super.mw.XAttrSet_ll(ino, []byte(name), []byte(value)); err != nil {
 		log.LogErrorf("Setxattr: ino(%v) name(%v) err(%v)", ino, name, err)
@@ -926,6 +930,10 @@ func (d *Dir) Removexattr(ctx context.Context, req *fuse.RemovexattrRequest) err
 		log.LogErrorf("Remove 'DirStat' is not supported.")
 		return fuse.ENOSYS
 	}
+	if proto.IsObjectLockXAttr(name) && d.super.mw.ObjectLockEnabled() {
+		log.LogErrorf("Removexattr: object lock attribute is not allowed to modify: ino(%v) name(%v)", ino, name)
+		return fuse.EPERM
+	}
 	if err = d.super.mw.XAttrDel_ll(ino, name); err != nil {
 		log.LogErrorf("Removexattr: ino(%v) name(%v) err(%v)", ino, name, err)
 		return ParseError(err)
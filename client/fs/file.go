@@ -214,6 +214,13 @@ func (f *File) Open(ctx context.Context, req *fuse.OpenRequest, resp *fuse.OpenR
 	log.LogDebugf("TRACE open ino(%v) info(%v)", ino, f.info)
 	start := time.Now()
 
+	if req != nil && !req.Flags.IsReadOnly() {
+		if err = f.super.mw.CheckObjectLock(ino); err != nil {
+			log.LogErrorf("Open: object is locked, ino(%v) flags(%v) err(%v)", ino, req.Flags, err)
+			return nil, ParseError(err)
+		}
+	}
+
 	if f.super.bcacheDir != "" && !f.filterFilesSuffix(f.super.bcacheFilterFiles) {
 		parentPath := f.getParentPath()
 		if parentPath != "" && !strings.HasSuffix(parentPath, "/") {
@@ -566,6 +573,12 @@ func (f *File) Setattr(ctx context.Context, req *fuse.SetattrRequest, resp *fuse
 
 	ino := f.info.Inode
 	start := time.Now()
+	if req.Valid.Size() {
+		if err = f.super.mw.CheckObjectLock(ino); err != nil {
+			log.LogErrorf("Setattr: object is locked, ino(%v) size(%v) err(%v)", ino, req.Size, err)
+			return ParseError(err)
+		}
+	}
 	if req.Valid.Size() && proto.IsHot(f.super.volType) {
 		// when use trunc param in open request through nfs client and mount on cfs mountPoint, cfs client may not recv open message but only setAttr,
 		// the streamer may not open and cause io error finally,so do a open no matter the stream be opened or not
@@ -707,6 +720,10 @@ func (f *File) Setxattr(ctx context.Context, req *fuse.SetxattrRequest) error {
 	ino := f.info.Inode
 	name := req.Name
 	value := req.Xattr
+	if proto.IsObjectLockXAttr(name) && f.super.mw.ObjectLockEnabled() {
+		log.LogErrorf("Setxattr: object lock attribute is not allowed to modify: ino(%v) name(%v)", ino, name)
+		return fuse.EPERM
+	}
 	// TODO： implement flag to improve compatible (Mofei Zhang)
 	if err = f.super.mw.XAttrSet_ll(ino, []byte(name), []byte(value)); err != nil {
 		log.LogErrorf("Setxattr: ino(%v) name(%v) err(%v)", ino, name, err)
@@ -729,6 +746,10 @@ func (f *File) Removexattr(ctx context.Context, req *fuse.RemovexattrRequest) er
 	}
 	ino := f.info.Inode
 	name := req.Name
+	if proto.IsObjectLockXAttr(name) && f.super.mw.ObjectLockEnabled() {
+		log.LogErrorf("Removexattr: object lock attribute is not allowed to modify: ino(%v) name(%v)", ino, name)
+		return fuse.EPERM
+	}
 	if err = f.super.mw.XAttrDel_ll(ino, name); err != nil {
 		log.LogErrorf("Removexattr: ino(%v) name(%v) err(%v)", ino, name, err)
 		return ParseError(err)
@@ -231,6 +231,7 @@ func NewSuper(opt *proto.MountOptions) (s *Super, err error) {
 		OnSplitExtentKey:  s.mw.SplitExtentKey,
 		OnGetExtents:      s.mw.GetExtents,
 		OnTruncate:        s.mw.Truncate,
+		OnModifyInode:     s.mw.InodeModify,
 		OnEvictIcache:     s.ic.Delete,
 		OnLoadBcache:      s.bc.Get,
 		OnCacheBcache:     s.bc.Put,
@@ -265,6 +266,10 @@ func NewSuper(opt *proto.MountOptions) (s *Super, err error) {
 	if !opt.EnablePosixACL {
 		opt.EnablePosixACL = s.ec.GetEnablePosixAcl()
 	}
+	// the mode bits and posix acl are shared with the S3 access in the unified permission mode
+	if !opt.EnablePosixACL && s.ec.GetPermissionMode() == proto.PermissionModeUnified {
+		opt.EnablePosixACL = true
+	}
 
 	if s.rootIno, err = s.mw.GetRootIno(opt.SubDir); err != nil {
 		return nil, err
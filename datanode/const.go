@@ -57,6 +57,7 @@ const (
 	ActionRepair                        = "ActionRepair:"
 	ActionDecommissionPartition         = "ActionDecommissionPartition"
 	ActionAddDataPartitionRaftMember    = "ActionAddDataPartitionRaftMember"
+	ActionPromoteDataPartitionLearner   = "ActionPromoteDataPartitionLearner"
 	ActionRemoveDataPartitionRaftMember = "ActionRemoveDataPartitionRaftMember"
 	ActionDataPartitionTryToLeader      = "ActionDataPartitionTryToLeader"
 
@@ -68,6 +69,7 @@ const (
 	ActionStreamReadTinyExtentRepair = "ActionStreamReadTinyExtentRepair"
 	ActionBatchMarkDelete            = "ActionBatchMarkDelete"
 	ActionBatchLockNormalExtent      = "ActionBatchLockNormalExtent"
+	ActionDefragFenceExtents         = "ActionDefragFenceExtents"
 	ActionUpdateVersion              = "ActionUpdateVersion"
 	ActionStopDataPartitionRepair    = "ActionStopDataPartitionRepair"
 	ActionRecoverDataReplicaMeta     = "ActionRecoverDataReplicaMeta"
@@ -42,6 +42,7 @@ type DataPartitionRepairTask struct {
 	extents                        map[uint64]*storage.ExtentInfo
 	ExtentsToBeCreated             []*storage.ExtentInfo
 	ExtentsToBeRepaired            []*storage.ExtentInfo
+	ExtentRefsToBeMerged           []*storage.ExtentInfo
 	LeaderTinyDeleteRecordFileSize int64
 	LeaderAddr                     string
 }
@@ -51,6 +52,7 @@ func NewDataPartitionRepairTask(extentFiles []*storage.ExtentInfo, tinyDeleteRec
 		extents:                        make(map[uint64]*storage.ExtentInfo),
 		ExtentsToBeCreated:             make([]*storage.ExtentInfo, 0),
 		ExtentsToBeRepaired:            make([]*storage.ExtentInfo, 0),
+		ExtentRefsToBeMerged:           make([]*storage.ExtentInfo, 0),
 		LeaderTinyDeleteRecordFileSize: tinyDeleteRecordFileSize,
 		LeaderAddr:                     leaderAddr,
 		TaskType:                       extentType,
@@ -231,6 +233,7 @@ func (dp *DataPartition) getRemoteExtentInfo(extentType uint8, tinyExtents []uin
 // DoRepair asks the leader to perform the repair tasks.
 func (dp *DataPartition) DoRepair(repairTasks []*DataPartitionRepairTask) {
 	store := dp.extentStore
+	dp.mergeExtentRefs(repairTasks[0])
 	for _, extentInfo := range repairTasks[0].ExtentsToBeCreated {
 		if !AutoRepairStatus {
 			log.LogWarnf("AutoRepairStatus is False,so cannot Create extent(%v),pid=%d", extentInfo.String(), dp.partitionID)
@@ -341,11 +344,56 @@ func (dp *DataPartition) prepareRepairTasks(repairTasks []*DataPartitionRepairTa
 			extentInfoMap[extentID] = extentInfo
 		}
 	}
+	dp.buildExtentRefTasks(repairTasks, extentInfoMap)
 	dp.buildExtentCreationTasks(repairTasks, extentInfoMap)
 	availableTinyExtents, brokenTinyExtents = dp.buildExtentRepairTasks(repairTasks, extentInfoMap)
 	return
 }
 
+// Merge the references of the shared extents if the replicas do not have the same ones,
+// the replica missing the extent gets the references before the extent is created.
+func (dp *DataPartition) buildExtentRefTasks(repairTasks []*DataPartitionRepairTask, extentInfoMap map[uint64]*storage.ExtentInfo) {
+	for extentID, extentInfo := range extentInfoMap {
+		if storage.IsTinyExtent(extentID) || extentInfo.IsDeleted {
+			continue
+		}
+		var refs []*storage.ExtentRef
+		for _, repairTask := range repairTasks {
+			if repairTask == nil {
+				continue
+			}
+			if ei, ok := repairTask.extents[extentID]; ok && ei.Ref != nil {
+				refs = append(refs, ei.Ref)
+			}
+		}
+		merged := storage.MergeExtentRefs(refs...)
+		if merged == nil {
+			continue
+		}
+		for index, repairTask := range repairTasks {
+			if repairTask == nil {
+				continue
+			}
+			if ei, ok := repairTask.extents[extentID]; ok && merged.Equal(ei.Ref) {
+				continue
+			}
+			repairTask.ExtentRefsToBeMerged = append(repairTask.ExtentRefsToBeMerged,
+				&storage.ExtentInfo{FileID: extentID, Ref: merged})
+			log.LogInfof("action[buildExtentRefTasks] mergeRef(%v_%v) refs(%v) on Index(%v).",
+				dp.partitionID, extentID, merged.Refs, index)
+		}
+	}
+}
+
+func (dp *DataPartition) mergeExtentRefs(repairTask *DataPartitionRepairTask) {
+	for _, extentInfo := range repairTask.ExtentRefsToBeMerged {
+		if err := dp.extentStore.MergeExtentRef(extentInfo.FileID, extentInfo.Ref); err != nil {
+			log.LogWarnf("action[mergeExtentRefs] dp %v extent %v failed, err:%v",
+				dp.partitionID, extentInfo.FileID, err)
+		}
+	}
+}
+
 // Create a new extent if one of the replica is missing.
 func (dp *DataPartition) buildExtentCreationTasks(repairTasks []*DataPartitionRepairTask, extentInfoMap map[uint64]*storage.ExtentInfo) {
 	for extentID, extentInfo := range extentInfoMap {
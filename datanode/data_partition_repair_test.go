@@ -582,3 +582,46 @@ func TestExtentRepair_512KB(t *testing.T) {
 	data, crc = genDataAndGetCrc("snapshot", util.BlockSize)
 	testDoSnapshotRepair(t, normalId, data, crc, false)
 }
+
+func TestBuildExtentRefTasks(t *testing.T) {
+	dp := &DataPartition{partitionID: 1}
+	// the leader misses the addition of dup2, and the follower misses the release of ino_1
+	leader := []*storage.ExtentInfo{
+		{FileID: 1025, Size: 4096, Ref: &storage.ExtentRef{Refs: 1, Tokens: map[string]uint32{"dup3": 1}, Released: map[string]string{"ino_1": "dup1"}}},
+		{FileID: 1026, Size: 4096},
+	}
+	follower := []*storage.ExtentInfo{
+		{FileID: 1025, Size: 4096, Ref: &storage.ExtentRef{Refs: 3, Tokens: map[string]uint32{"dup1": 1, "dup2": 1, "dup3": 1}}},
+		{FileID: 1026, Size: 4096},
+	}
+	// the new replica has no extents yet
+	tasks := []*DataPartitionRepairTask{
+		NewDataPartitionRepairTask(leader, 0, "leader", "leader", proto.NormalExtentType),
+		NewDataPartitionRepairTask(follower, 0, "follower", "leader", proto.NormalExtentType),
+		NewDataPartitionRepairTask(nil, 0, "learner", "leader", proto.NormalExtentType),
+	}
+	extentInfoMap := map[uint64]*storage.ExtentInfo{1025: leader[0], 1026: leader[1]}
+	dp.buildExtentRefTasks(tasks, extentInfoMap)
+
+	for _, task := range tasks {
+		require.Equal(t, 1, len(task.ExtentRefsToBeMerged))
+		merged := task.ExtentRefsToBeMerged[0]
+		require.Equal(t, uint64(1025), merged.FileID)
+		require.Equal(t, &storage.ExtentRef{
+			Refs:     2,
+			Tokens:   map[string]uint32{"dup2": 1, "dup3": 1},
+			Released: map[string]string{"ino_1": "dup1"},
+		}, merged.Ref)
+	}
+
+	// the replicas with the merged references are not repaired again
+	leader[0].Ref = tasks[0].ExtentRefsToBeMerged[0].Ref
+	follower[0].Ref = tasks[1].ExtentRefsToBeMerged[0].Ref
+	for _, task := range tasks {
+		task.ExtentRefsToBeMerged = nil
+	}
+	dp.buildExtentRefTasks(tasks, extentInfoMap)
+	require.Equal(t, 0, len(tasks[0].ExtentRefsToBeMerged))
+	require.Equal(t, 0, len(tasks[1].ExtentRefsToBeMerged))
+	require.Equal(t, 1, len(tasks[2].ExtentRefsToBeMerged))
+}
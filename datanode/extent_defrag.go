@@ -0,0 +1,490 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package datanode
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/repl"
+	"github.com/cubefs/cubefs/sdk/data/stream"
+	"github.com/cubefs/cubefs/sdk/meta"
+	"github.com/cubefs/cubefs/util"
+	"github.com/cubefs/cubefs/util/config"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	DefaultExtentDefragInterval       = 3600 // second
+	DefaultExtentDefragMinExtents     = 1024
+	DefaultExtentDefragMinTinyExtents = 64
+	DefaultExtentDefragMaxFileSize    = 4 * util.GB
+	DefaultExtentDefragColdTime       = 24 * 3600 // second
+
+	extentDefragPageSize      = 100
+	extentDefragFilesPerRound = 1000
+	extentDefragWriteSize     = 16 * util.MB
+
+	// a tiny extent is hole-ridden if it is larger than the min size and less than
+	// the ratio of it is really allocated
+	tinyExtentReclaimMinSize  = 128 * util.MB
+	tinyExtentReclaimRatio    = 0.5
+	tinyExtentReclaimInterval = 24 * time.Hour
+)
+
+type defragClient struct {
+	mw  *meta.MetaWrapper
+	ec  *stream.ExtentClient
+	dps map[uint64]*proto.DataPartitionResponse // refreshed by each round of the volume
+}
+
+// extentDefragger rewrites the fragmented files of the volumes served by this datanode into
+// contiguous extents, and swaps the extent keys on metanode with OpMetaExtentsReplace.
+// The old extents, tiny ones included, are released through the normal delete path of metanode.
+//
+// Each meta partition of a volume is scanned by a single datanode, the raft leader of the data
+// partition at the index (meta partition id mod count) of the data partitions sorted by id.
+// A file scanned twice by a stale view is still safe, as the swap is checked by metanode.
+//
+// The live data of the hole-ridden tiny extents led by this datanode is moved out by rewriting
+// the files referencing them, so the space between the punched holes is freed as well.
+//
+// Only the volumes with enableDefrag are defragmented. The extents copied are fenced on the raft
+// leaders of their data partitions for the length of the copy, an in-place overwrite applied during
+// the copy fails the seal of the fence, and the ones applied after the seal are rejected until the
+// swap is done. The overwrites reported by the clients bump the generation of the inode as well.
+type extentDefragger struct {
+	s               *DataNode
+	interval        time.Duration
+	coldTime        time.Duration
+	minExtents      uint32
+	minTinyExtents  uint32
+	maxFileSize     uint64
+	clients         map[string]*defragClient
+	lastTinyReclaim map[string]time.Time
+}
+
+func (s *DataNode) parseExtentDefragConfig(cfg *config.Config) {
+	if !cfg.GetBool(ConfigKeyEnableExtentDefrag) {
+		return
+	}
+	d := &extentDefragger{
+		s:              s,
+		interval:       time.Duration(cfg.GetInt64(ConfigKeyExtentDefragInterval)) * time.Second,
+		coldTime:       time.Duration(cfg.GetInt64(ConfigKeyExtentDefragColdTime)) * time.Second,
+		minExtents:     uint32(cfg.GetInt64(ConfigKeyExtentDefragMinExtents)),
+		minTinyExtents: uint32(cfg.GetInt64(ConfigKeyExtentDefragMinTinyExtents)),
+		maxFileSize:    uint64(cfg.GetInt64(ConfigKeyExtentDefragMaxFileSize)),
+		clients:        make(map[string]*defragClient),
+
+		lastTinyReclaim: make(map[string]time.Time),
+	}
+	if d.interval <= 0 {
+		d.interval = DefaultExtentDefragInterval * time.Second
+	}
+	if d.coldTime <= 0 {
+		d.coldTime = DefaultExtentDefragColdTime * time.Second
+	}
+	if d.minExtents == 0 {
+		d.minExtents = DefaultExtentDefragMinExtents
+	}
+	if d.minTinyExtents == 0 {
+		d.minTinyExtents = DefaultExtentDefragMinTinyExtents
+	}
+	if d.maxFileSize == 0 {
+		d.maxFileSize = DefaultExtentDefragMaxFileSize
+	}
+	s.defragger = d
+	log.LogInfof("action[parseExtentDefragConfig] interval(%v) coldTime(%v) minExtents(%v) minTinyExtents(%v) maxFileSize(%v)",
+		d.interval, d.coldTime, d.minExtents, d.minTinyExtents, d.maxFileSize)
+}
+
+func (d *extentDefragger) run() {
+	ticker := time.NewTicker(d.interval)
+	defer func() {
+		ticker.Stop()
+		d.closeClients()
+	}()
+	for {
+		select {
+		case <-d.s.stopC:
+			return
+		case <-ticker.C:
+			d.defragVolumes()
+		}
+	}
+}
+
+func (d *extentDefragger) closeClients() {
+	for volName, c := range d.clients {
+		c.ec.Close()
+		c.mw.Close()
+		delete(d.clients, volName)
+	}
+}
+
+func (d *extentDefragger) getClient(volName string) (c *defragClient, err error) {
+	if c = d.clients[volName]; c != nil {
+		return
+	}
+	c = &defragClient{}
+	if c.mw, err = meta.NewMetaWrapper(&meta.MetaConfig{
+		Volume:        volName,
+		Masters:       MasterClient.Nodes(),
+		ValidateOwner: false,
+	}); err != nil {
+		return nil, err
+	}
+	if c.ec, err = stream.NewExtentClient(&stream.ExtentConfig{
+		Volume:            volName,
+		Masters:           MasterClient.Nodes(),
+		OnAppendExtentKey: c.mw.AppendExtentKey,
+		OnSplitExtentKey:  c.mw.SplitExtentKey,
+		OnGetExtents:      c.mw.GetExtents,
+		OnTruncate:        c.mw.Truncate,
+	}); err != nil {
+		c.mw.Close()
+		return nil, err
+	}
+	d.clients[volName] = c
+	return
+}
+
+func (d *extentDefragger) defragVolumes() {
+	volumes := make(map[string]struct{})
+	d.s.space.RangePartitions(func(dp *DataPartition) bool {
+		if _, ok := dp.IsRaftLeader(); ok {
+			volumes[dp.volumeID] = struct{}{}
+		}
+		return true
+	})
+	for volName := range volumes {
+		select {
+		case <-d.s.stopC:
+			return
+		default:
+		}
+		view, err := MasterClient.AdminAPI().GetVolumeSimpleInfo(volName)
+		if err != nil {
+			log.LogWarnf("action[defragVolumes] vol(%v) get volume err(%v)", volName, err)
+			continue
+		}
+		if !view.EnableDefrag {
+			continue
+		}
+		c, err := d.getClient(volName)
+		if err != nil {
+			log.LogWarnf("action[defragVolumes] vol(%v) new client err(%v)", volName, err)
+			continue
+		}
+		d.defragVolume(volName, c)
+	}
+}
+
+// ownedPartitions returns the meta partitions of the volume scanned by this datanode.
+func (d *extentDefragger) ownedPartitions(volName string, c *defragClient) (pids []uint64, err error) {
+	view, err := MasterClient.ClientAPI().GetDataPartitions(volName)
+	if err != nil {
+		return
+	}
+	dps := view.DataPartitions
+	c.dps = make(map[uint64]*proto.DataPartitionResponse, len(dps))
+	for _, dp := range dps {
+		c.dps[dp.PartitionID] = dp
+	}
+	if len(dps) == 0 {
+		return
+	}
+	sort.Slice(dps, func(i, j int) bool { return dps[i].PartitionID < dps[j].PartitionID })
+	for _, pid := range c.mw.GetPartitionIDs() {
+		dp := d.s.space.Partition(dps[pid%uint64(len(dps))].PartitionID)
+		if dp == nil {
+			continue
+		}
+		if _, ok := dp.IsRaftLeader(); ok {
+			pids = append(pids, pid)
+		}
+	}
+	return
+}
+
+// holeRiddenTinyExtents returns the hole-ridden tiny extents of the data partitions of the volume
+// led by this datanode.
+func (d *extentDefragger) holeRiddenTinyExtents(volName string) (extents map[uint64][]uint64) {
+	extents = make(map[uint64][]uint64)
+	d.s.space.RangePartitions(func(dp *DataPartition) bool {
+		if dp.volumeID != volName {
+			return true
+		}
+		if _, ok := dp.IsRaftLeader(); !ok {
+			return true
+		}
+		infos, err := dp.ExtentStore().GetTinyExtentHoleInfo()
+		if err != nil {
+			log.LogWarnf("action[holeRiddenTinyExtents] dp(%v) err(%v)", dp.partitionID, err)
+			return true
+		}
+		for _, info := range infos {
+			if info.Size >= tinyExtentReclaimMinSize && float64(info.Allocated) < float64(info.Size)*tinyExtentReclaimRatio {
+				extents[dp.partitionID] = append(extents[dp.partitionID], info.ExtentID)
+			}
+		}
+		return true
+	})
+	return
+}
+
+func (d *extentDefragger) defragVolume(volName string, c *defragClient) {
+	pids, err := d.ownedPartitions(volName, c)
+	if err != nil {
+		log.LogWarnf("action[defragVolume] vol(%v) get data partitions err(%v)", volName, err)
+		return
+	}
+	handled := d.defragPartitions(volName, c, pids, d.minExtents, d.minTinyExtents, nil, 0)
+
+	if time.Since(d.lastTinyReclaim[volName]) < tinyExtentReclaimInterval {
+		return
+	}
+	tinyExtents := d.holeRiddenTinyExtents(volName)
+	if len(tinyExtents) == 0 {
+		return
+	}
+	d.lastTinyReclaim[volName] = time.Now()
+	log.LogInfof("action[defragVolume] vol(%v) reclaim tiny extents(%v)", volName, tinyExtents)
+	// the files referencing the tiny extents may be in any meta partition
+	d.defragPartitions(volName, c, c.mw.GetPartitionIDs(), 0, 0, tinyExtents, handled)
+}
+
+func (d *extentDefragger) defragPartitions(volName string, c *defragClient, pids []uint64, minExtents, minTinyExtents uint32,
+	tinyExtents map[uint64][]uint64, handled int,
+) int {
+	for _, pid := range pids {
+		var marker uint64
+		for {
+			select {
+			case <-d.s.stopC:
+				return handled
+			default:
+			}
+			resp, err := c.mw.GetFragmentedInodes(pid, marker, minExtents, minTinyExtents, extentDefragPageSize, tinyExtents)
+			if err != nil {
+				log.LogWarnf("action[defragPartitions] vol(%v) mp(%v) marker(%v) err(%v)", volName, pid, marker, err)
+				break
+			}
+			for _, fi := range resp.Inodes {
+				if fi.Size > d.maxFileSize {
+					continue
+				}
+				done, err := d.defragInode(c, fi.Inode)
+				if err != nil {
+					log.LogWarnf("action[defragPartitions] vol(%v) ino(%v) err(%v)", volName, fi.Inode, err)
+					continue
+				}
+				if !done {
+					continue
+				}
+				log.LogInfof("action[defragPartitions] vol(%v) ino(%v) size(%v) extents(%v) tinyExtents(%v) compacted",
+					volName, fi.Inode, fi.Size, fi.ExtentCount, fi.TinyExtentCount)
+				if handled++; handled >= extentDefragFilesPerRound {
+					return handled
+				}
+			}
+			if resp.NextMarker == 0 {
+				break
+			}
+			marker = resp.NextMarker
+		}
+	}
+	return handled
+}
+
+// defragInode copies the data of the inode into a temporary inode of the same meta partition,
+// then moves the extents of the temporary inode to the inode if the inode is not modified.
+// The temporary inode is unlinked as soon as it is created, so it is released by the delete
+// worker of metanode even if this datanode crashes before evicting it.
+func (d *extentDefragger) defragInode(c *defragClient, ino uint64) (done bool, err error) {
+	info, err := c.mw.InodeGet_ll(ino)
+	if err != nil {
+		return
+	}
+	if time.Since(info.ModifyTime) < d.coldTime {
+		return
+	}
+	gen, size, eks, err := c.mw.GetExtents(ino)
+	if err != nil || len(eks) == 0 {
+		return
+	}
+	// holes of sparse files would be filled with zero
+	var total uint64
+	for _, ek := range eks {
+		total += uint64(ek.Size)
+	}
+	if total != size {
+		return
+	}
+
+	tmp, err := c.mw.InodeCreateNear_ll(ino, proto.Mode(os.FileMode(0o644)), info.Uid, info.Gid)
+	if err != nil {
+		return
+	}
+	if _, err = c.mw.InodeUnlink_ll(tmp.Inode, ""); err != nil {
+		log.LogWarnf("action[defragInode] unlink tmp ino(%v) err(%v)", tmp.Inode, err)
+		return
+	}
+	defer func() {
+		c.ec.EvictStream(tmp.Inode)
+		if e := c.mw.Evict(tmp.Inode, ""); e != nil {
+			log.LogWarnf("action[defragInode] evict tmp ino(%v) err(%v)", tmp.Inode, e)
+		}
+	}()
+
+	fence := &extentFence{
+		id:  fmt.Sprintf("defrag_%v_%v", ino, tmp.Inode),
+		eks: make(map[uint64][]*proto.ExtentKey),
+	}
+	for i := range eks {
+		fence.eks[eks[i].PartitionId] = append(fence.eks[eks[i].PartitionId], &eks[i])
+	}
+	defer d.fenceExtents(c, fence, proto.DefragFenceRelease)
+	if err = d.fenceExtents(c, fence, proto.DefragFenceBegin); err != nil {
+		return
+	}
+
+	if err = c.ec.OpenStream(ino); err != nil {
+		return
+	}
+	defer c.ec.CloseStream(ino)
+	if err = c.ec.OpenStream(tmp.Inode); err != nil {
+		return
+	}
+
+	buf := make([]byte, 0, extentDefragWriteSize)
+	var offset uint64
+	write := func() error {
+		if len(buf) == 0 {
+			return nil
+		}
+		if _, e := c.ec.Write(tmp.Inode, int(offset), buf, 0, nil); e != nil {
+			return e
+		}
+		offset += uint64(len(buf))
+		buf = buf[:0]
+		return nil
+	}
+	for i := range eks {
+		ek := eks[i]
+		data := make([]byte, ek.Size)
+		var n int
+		if n, err, _ = c.ec.ReadExtent(ino, &ek, data, 0, int(ek.Size)); err != nil {
+			return
+		}
+		if n != int(ek.Size) {
+			err = fmt.Errorf("read ek(%v) size(%v) expect(%v)", ek, n, ek.Size)
+			return
+		}
+		buf = append(buf, data...)
+		if len(buf) >= extentDefragWriteSize {
+			if err = write(); err != nil {
+				return
+			}
+		}
+	}
+	if err = write(); err != nil {
+		return
+	}
+	if err = c.ec.Flush(tmp.Inode); err != nil {
+		return
+	}
+
+	// the seal fails if the extents are overwritten during the copy
+	if err = d.fenceExtents(c, fence, proto.DefragFenceSeal); err != nil {
+		return
+	}
+	// metanode rejects the swap if the inode is modified since its extents were read
+	if _, err = c.mw.ReplaceExtentKeys(ino, tmp.Inode, eks, gen, info.ModifyTime.Unix()); err != nil {
+		return
+	}
+	done = true
+	return
+}
+
+// extentFence is the fence of the extent keys of a file on the data partitions of them.
+type extentFence struct {
+	id  string
+	eks map[uint64][]*proto.ExtentKey // by partition id
+}
+
+// fenceExtents sends the action of the fence to the raft leader of each data partition of the extents.
+func (d *extentDefragger) fenceExtents(c *defragClient, fence *extentFence, action proto.DefragFenceAction) (err error) {
+	for pid, eks := range fence.eks {
+		req := &proto.DefragFenceRequest{FenceID: fence.id, Action: action}
+		if action == proto.DefragFenceBegin {
+			req.Eks = eks
+		}
+		if e := d.sendDefragFence(c.dps[pid], pid, req); e != nil {
+			log.LogWarnf("action[fenceExtents] dp(%v) fence(%v) action(%v) err(%v)", pid, fence.id, action, e)
+			if err == nil {
+				err = e
+			}
+			if action != proto.DefragFenceRelease {
+				return
+			}
+		}
+	}
+	return
+}
+
+func (d *extentDefragger) sendDefragFence(dp *proto.DataPartitionResponse, pid uint64, req *proto.DefragFenceRequest) (err error) {
+	if dp == nil || (dp.LeaderAddr == "" && len(dp.Hosts) == 0) {
+		return fmt.Errorf("no leader of dp(%v)", pid)
+	}
+	target := dp.LeaderAddr
+	if target == "" {
+		target = dp.Hosts[0]
+	}
+
+	p := new(repl.Packet)
+	p.Magic = proto.ProtoMagic
+	p.Opcode = proto.OpDefragFenceExtents
+	p.ExtentType = proto.NormalExtentType
+	p.PartitionID = pid
+	p.ReqID = proto.GenerateRequestID()
+	if p.Data, err = json.Marshal(req); err != nil {
+		return
+	}
+	p.Size = uint32(len(p.Data))
+
+	conn, err := gConnPool.GetConnect(target)
+	if err != nil {
+		return
+	}
+	defer func() {
+		gConnPool.PutConnect(conn, err != nil)
+	}()
+	if err = p.WriteToConn(conn); err != nil {
+		return
+	}
+	if err = p.ReadFromConnWithVer(conn, proto.ReadDeadlineTime); err != nil {
+		return
+	}
+	if p.ResultCode != proto.OpOk {
+		err = fmt.Errorf("dp(%v) host(%v) result(%v) msg(%v)", pid, target, p.GetResultMsg(), string(p.Data[:p.Size]))
+	}
+	return
+}
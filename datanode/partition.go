@@ -144,6 +144,9 @@ type DataPartition struct {
 	recoverErrCnt              uint64 // donot reset, if reach max err cnt, delete this dp
 
 	diskErrCnt uint64 // number of disk io errors while reading or writing
+
+	defragFences    map[string]*defragFence // fences of the extents being defragmented, by fence id
+	defragFenceLock sync.RWMutex
 }
 
 func (dp *DataPartition) IsForbidden() bool {
@@ -366,6 +369,7 @@ func newDataPartition(dpCfg *dataPartitionCfg, disk *Disk, isCreate bool) (dp *D
 		verSeq:                  dpCfg.VerSeq,
 		DataPartitionCreateType: dpCfg.CreateType,
 		volVersionInfoList:      &proto.VolVersionInfoList{},
+		defragFences:            make(map[string]*defragFence),
 	}
 	atomic.StoreUint64(&partition.recoverErrCnt, 0)
 	log.LogInfof("action[newDataPartition] dp %v replica num %v", partitionID, dpCfg.ReplicaNum)
@@ -1002,6 +1006,7 @@ func (dp *DataPartition) DoExtentStoreRepair(repairTask *DataPartitionRepairTask
 		return
 	}
 	store := dp.extentStore
+	dp.mergeExtentRefs(repairTask)
 	log.LogDebugf("DoExtentStoreRepair dp %v len extents to created %v type %v",
 		dp.partitionID, len(repairTask.ExtentsToBeCreated), repairTask.TaskType)
 	for _, extentInfo := range repairTask.ExtentsToBeCreated {
@@ -0,0 +1,164 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package datanode
+
+import (
+	"encoding/json"
+	"sync/atomic"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/storage"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// the fence of a crashed defragmentation is released by the raft leader after it expires
+const defragFenceTTL = 10 * time.Minute
+
+// defragFence fences the ranges of the extents copied by the defragmentation of a file.
+//
+// The fence is applied through raft, so the random writes are checked against it in the same
+// order on every replica. An overwrite applied after the begin makes the fence dirty, which
+// fails the seal, so the copy is never swapped in if it misses an overwrite. An overwrite
+// applied after the seal is rejected, and the client retries it with the reloaded extent keys.
+type defragFence struct {
+	ranges    map[uint64][]*proto.ExtentKey
+	dirty     bool
+	sealed    bool
+	expireAt  int64
+	releasing int32
+}
+
+func newDefragFence(req *proto.DefragFenceRequest) (f *defragFence) {
+	f = &defragFence{
+		ranges:   make(map[uint64][]*proto.ExtentKey),
+		expireAt: req.ExpireAt,
+	}
+	for _, ek := range req.Eks {
+		f.ranges[ek.ExtentId] = append(f.ranges[ek.ExtentId], ek)
+	}
+	return
+}
+
+func (f *defragFence) overlaps(extentID uint64, offset, size int64) bool {
+	for _, ek := range f.ranges[extentID] {
+		if offset < int64(ek.ExtentOffset)+int64(ek.Size) && int64(ek.ExtentOffset) < offset+size {
+			return true
+		}
+	}
+	return false
+}
+
+// HandleDefragFence submits the fence action to raft, it must be called on the raft leader.
+func (dp *DataPartition) HandleDefragFence(req *proto.DefragFenceRequest) (err error) {
+	if req.Action != proto.DefragFenceRelease {
+		req.ExpireAt = time.Now().Add(defragFenceTTL).Unix()
+	}
+	val, err := json.Marshal(req)
+	if err != nil {
+		return
+	}
+	data, err := MarshalRaftCmd(&RaftCmdItem{
+		Op: uint32(proto.OpDefragFenceExtents),
+		K:  []byte(req.FenceID),
+		V:  val,
+	})
+	if err != nil {
+		return
+	}
+	code, err := dp.Submit(data)
+	if err != nil {
+		return
+	}
+	if code != proto.OpOk {
+		err = storage.ExtentDefragFenceBrokenError
+	}
+	return
+}
+
+func (dp *DataPartition) fsmDefragFence(opItem *RaftCmdItem) (resp uint8) {
+	req := new(proto.DefragFenceRequest)
+	if err := json.Unmarshal(opItem.V, req); err != nil {
+		log.LogErrorf("action[fsmDefragFence] dp(%v) op item %v err %v", dp.partitionID, opItem, err)
+		return proto.OpErr
+	}
+	resp = proto.OpOk
+
+	dp.defragFenceLock.Lock()
+	defer dp.defragFenceLock.Unlock()
+	switch req.Action {
+	case proto.DefragFenceBegin:
+		dp.defragFences[req.FenceID] = newDefragFence(req)
+	case proto.DefragFenceSeal:
+		f := dp.defragFences[req.FenceID]
+		if f == nil || f.dirty {
+			// the fence is overwritten, or lost by the restart of this replica
+			delete(dp.defragFences, req.FenceID)
+			resp = proto.OpConflictExtentsErr
+			break
+		}
+		f.sealed = true
+		f.expireAt = req.ExpireAt
+	case proto.DefragFenceRelease:
+		delete(dp.defragFences, req.FenceID)
+	}
+	log.LogInfof("action[fsmDefragFence] dp(%v) fence(%v) action(%v) eks(%v) resp(%v)",
+		dp.partitionID, req.FenceID, req.Action, len(req.Eks), resp)
+	return
+}
+
+// checkDefragFences is called by the apply of the overwrite of the range, it marks the begun
+// fences of the range dirty, and rejects the overwrite if the range is sealed.
+func (dp *DataPartition) checkDefragFences(extentID uint64, offset, size int64) (err error) {
+	dp.defragFenceLock.Lock()
+	defer dp.defragFenceLock.Unlock()
+	for id, f := range dp.defragFences {
+		if !f.overlaps(extentID, offset, size) {
+			continue
+		}
+		if f.sealed {
+			log.LogWarnf("action[checkDefragFences] dp(%v) extent(%v) offset(%v) size(%v) is sealed by fence(%v)",
+				dp.partitionID, extentID, offset, size, id)
+			return storage.ExtentDefragFencedError
+		}
+		f.dirty = true
+	}
+	return
+}
+
+// releaseExpiredDefragFences releases the fences of the crashed defragmentations on the raft leader.
+func (dp *DataPartition) releaseExpiredDefragFences() {
+	now := time.Now().Unix()
+	var expired []string
+	dp.defragFenceLock.RLock()
+	for id, f := range dp.defragFences {
+		if f.expireAt < now && atomic.CompareAndSwapInt32(&f.releasing, 0, 1) {
+			expired = append(expired, id)
+		}
+	}
+	dp.defragFenceLock.RUnlock()
+
+	for _, id := range expired {
+		err := dp.HandleDefragFence(&proto.DefragFenceRequest{FenceID: id, Action: proto.DefragFenceRelease})
+		log.LogWarnf("action[releaseExpiredDefragFences] dp(%v) release fence(%v) err(%v)", dp.partitionID, id, err)
+		if err != nil {
+			dp.defragFenceLock.RLock()
+			if f := dp.defragFences[id]; f != nil {
+				atomic.StoreInt32(&f.releasing, 0)
+			}
+			dp.defragFenceLock.RUnlock()
+		}
+	}
+}
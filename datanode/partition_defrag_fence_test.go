@@ -0,0 +1,76 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package datanode
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func applyDefragFence(t *testing.T, dp *DataPartition, req *proto.DefragFenceRequest) uint8 {
+	val, err := json.Marshal(req)
+	require.NoError(t, err)
+	data, err := MarshalRaftCmd(&RaftCmdItem{Op: uint32(proto.OpDefragFenceExtents), K: []byte(req.FenceID), V: val})
+	require.NoError(t, err)
+	resp, err := dp.Apply(data, 1)
+	require.NoError(t, err)
+	return resp.(uint8)
+}
+
+func TestDefragFence(t *testing.T) {
+	dp := &DataPartition{partitionID: 1, defragFences: make(map[string]*defragFence)}
+	eks := []*proto.ExtentKey{
+		{PartitionId: 1, ExtentId: 1025, ExtentOffset: 0, Size: 4096},
+		{PartitionId: 1, ExtentId: 1, ExtentOffset: 8192, Size: 4096},
+	}
+	begin := func(id string) {
+		require.Equal(t, proto.OpOk, applyDefragFence(t, dp, &proto.DefragFenceRequest{
+			FenceID: id, Action: proto.DefragFenceBegin, Eks: eks,
+		}))
+	}
+	seal := func(id string) uint8 {
+		return applyDefragFence(t, dp, &proto.DefragFenceRequest{FenceID: id, Action: proto.DefragFenceSeal})
+	}
+	release := func(id string) {
+		require.Equal(t, proto.OpOk, applyDefragFence(t, dp, &proto.DefragFenceRequest{FenceID: id, Action: proto.DefragFenceRelease}))
+	}
+
+	// the overwrites out of the fenced ranges, including the other files in the tiny extent
+	begin("f1")
+	require.NoError(t, dp.checkDefragFences(1025, 4096, 4096))
+	require.NoError(t, dp.checkDefragFences(1, 0, 8192))
+	require.NoError(t, dp.checkDefragFences(2, 0, 4096))
+	require.Equal(t, proto.OpOk, seal("f1"))
+
+	// the overwrites of the sealed ranges are rejected until the release
+	require.Equal(t, storage.ExtentDefragFencedError, dp.checkDefragFences(1025, 4095, 2))
+	require.Equal(t, storage.ExtentDefragFencedError, dp.checkDefragFences(1, 12287, 100))
+	release("f1")
+	require.NoError(t, dp.checkDefragFences(1025, 0, 4096))
+
+	// an overwrite during the copy fails the seal
+	begin("f2")
+	require.NoError(t, dp.checkDefragFences(1025, 100, 1))
+	require.Equal(t, proto.OpConflictExtentsErr, seal("f2"))
+	require.NoError(t, dp.checkDefragFences(1025, 100, 1))
+
+	// the fence lost by the restart of the replica fails the seal
+	require.Equal(t, proto.OpConflictExtentsErr, seal("f3"))
+	require.Empty(t, dp.defragFences)
+}
@@ -259,6 +259,14 @@ func (dp *DataPartition) ApplyRandomWrite(command []byte, raftApplyID uint64) (r
 	log.LogDebugf("[ApplyRandomWrite] ApplyID(%v) Partition(%v)_Extent(%v)_ExtentOffset(%v)_Size(%v)",
 		raftApplyID, dp.partitionID, opItem.extentID, opItem.offset, opItem.size)
 
+	if opItem.opcode == proto.OpRandomWrite || opItem.opcode == proto.OpSyncRandomWrite ||
+		opItem.opcode == proto.OpRandomWriteVer || opItem.opcode == proto.OpSyncRandomWriteVer {
+		if dp.checkDefragFences(opItem.extentID, opItem.offset, opItem.size) != nil {
+			respStatus = proto.ErrCodeVersionOpError
+			return
+		}
+	}
+
 	for i := 0; i < 20; i++ {
 		dp.disk.allocCheckLimit(proto.FlowWriteType, uint32(opItem.size))
 		dp.disk.allocCheckLimit(proto.IopsWriteType, 1)
@@ -319,6 +327,7 @@ func (dp *DataPartition) ApplyRandomWrite(command []byte, raftApplyID uint64) (r
 
 // RandomWriteSubmit submits the proposal to raft.
 func (dp *DataPartition) RandomWriteSubmit(pkg *repl.Packet) (err error) {
+	dp.releaseExpiredDefragFences()
 	val, err := MarshalRandWriteRaftLog(pkg.Opcode, pkg.ExtentID, pkg.ExtentOffset, int64(pkg.Size), pkg.Data, pkg.CRC)
 	if err != nil {
 		log.LogErrorf("action[RandomWriteSubmit] [%v] marshal error %v", dp.partitionID, err)
@@ -54,7 +54,11 @@ type dataPartitionCfg struct {
 }
 
 func (dp *DataPartition) raftPort() (heartbeat, replica int, err error) {
-	raftConfig := dp.config.RaftStore.RaftConfig()
+	return raftPortOf(dp.config.RaftStore)
+}
+
+func raftPortOf(raftStore raftstore.RaftStore) (heartbeat, replica int, err error) {
+	raftConfig := raftStore.RaftConfig()
 	heartbeatAddrSplits := strings.Split(raftConfig.HeartbeatAddr, ":")
 	replicaAddrSplits := strings.Split(raftConfig.ReplicateAddr, ":")
 	if len(heartbeatAddrSplits) != 2 {
@@ -113,7 +117,8 @@ func (dp *DataPartition) StartRaft(isLoad bool) (err error) {
 		addr := strings.Split(peer.Addr, ":")[0]
 		rp := raftstore.PeerAddress{
 			Peer: raftproto.Peer{
-				ID: peer.ID,
+				ID:   peer.ID,
+				Type: raftproto.PeerType(peer.Type),
 			},
 			Address:       addr,
 			HeartbeatPort: heartbeatPort,
@@ -400,7 +405,10 @@ func (dp *DataPartition) addRaftNode(req *proto.AddDataPartitionRaftMemberReques
 	log.LogInfof("addRaftNode: partitionID(%v) nodeID(%v) index(%v) data(%v) ",
 		req.PartitionId, dp.config.NodeID, index, string(data))
 	dp.config.Peers = append(dp.config.Peers, req.AddPeer)
-	dp.config.Hosts = append(dp.config.Hosts, req.AddPeer.Addr)
+	// a witness stores no data, so it's not a host of the data partition
+	if !req.AddPeer.IsWitness() {
+		dp.config.Hosts = append(dp.config.Hosts, req.AddPeer.Addr)
+	}
 	dp.replicasLock.Lock()
 	dp.replicas = make([]string, len(dp.config.Hosts))
 	copy(dp.replicas, dp.config.Hosts)
@@ -410,6 +418,28 @@ func (dp *DataPartition) addRaftNode(req *proto.AddDataPartitionRaftMemberReques
 	return
 }
 
+// promoteRaftNode promotes a learner to a voter.
+func (dp *DataPartition) promoteRaftNode(req *proto.PromoteDataPartitionLearnerRequest) (isUpdated bool) {
+	for i, peer := range dp.config.Peers {
+		if peer.ID == req.PromotePeer.ID && peer.IsLearner() {
+			dp.config.Peers[i].Type = proto.PeerNormal
+			isUpdated = true
+			break
+		}
+	}
+	log.LogInfof("action[promoteRaftNode] dp(%v) promote peer(%v) updated(%v)", dp.partitionID, req.PromotePeer, isUpdated)
+	return
+}
+
+func (dp *DataPartition) getPeer(nodeID uint64) (peer proto.Peer, ok bool) {
+	for _, peer = range dp.config.Peers {
+		if peer.ID == nodeID {
+			return peer, true
+		}
+	}
+	return proto.Peer{}, false
+}
+
 // Delete a raft node.
 func (dp *DataPartition) removeRaftNode(req *proto.RemoveDataPartitionRaftMemberRequest, index uint64) (isUpdated bool, err error) {
 	// cache or preload partition not support raft and repair.
@@ -51,6 +51,10 @@ func (dp *DataPartition) Apply(command []byte, index uint64) (resp interface{},
 			dp.fsmVersionOp(opItem)
 			return
 		}
+		if opItem.Op == uint32(proto.OpDefragFenceExtents) {
+			resp = dp.fsmDefragFence(opItem)
+			return
+		}
 		return
 	}
 	if index > dp.metaAppliedID {
@@ -122,7 +126,12 @@ func (dp *DataPartition) ApplyMemberChange(confChange *raftproto.ConfChange, ind
 		log.LogInfof("action[ApplyMemberChange] ConfRemoveNode [%v], partitionId [%v] index(%v)", req.RemovePeer, req.PartitionId, index)
 		isUpdated, err = dp.removeRaftNode(req, index)
 	case raftproto.ConfUpdateNode:
-		log.LogDebugf("[updateRaftNode]: not support.")
+		req := &proto.PromoteDataPartitionLearnerRequest{}
+		if err = json.Unmarshal(confChange.Context, req); err != nil {
+			return
+		}
+		log.LogInfof("action[ApplyMemberChange] ConfUpdateNode [%v], partitionId [%v] index(%v)", req.PromotePeer, req.PartitionId, index)
+		isUpdated = dp.promoteRaftNode(req)
 	default:
 		// do nothing
 	}
@@ -0,0 +1,337 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package datanode
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cubefs/cubefs/depends/tiglabs/raft"
+	raftproto "github.com/cubefs/cubefs/depends/tiglabs/raft/proto"
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/raftstore"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	WitnessPartitionPrefix  = "witnesspartition"
+	witnessTruncateInterval = time.Minute
+)
+
+// RegexpWitnessPartitionDir validates the directory name of a witness partition.
+var RegexpWitnessPartitionDir, _ = regexp.Compile(`^witnesspartition_(\d)+$`)
+
+// WitnessPartition is the metadata-only replica of a data partition. It votes in the raft group
+// and keeps the raft log, but has no extent store and serves no client, so it breaks the tie of
+// the two replicas data partition without the cost of a third copy.
+// The witness partitions are kept in the raft directory of the datanode.
+type WitnessPartition struct {
+	partitionID uint64
+	volumeID    string
+	path        string
+	nodeID      uint64
+	appliedID   uint64
+
+	peersLock sync.RWMutex
+	peers     []proto.Peer
+
+	persistMutex  sync.Mutex
+	raftStore     raftstore.RaftStore
+	raftPartition raftstore.Partition
+	stopOnce      sync.Once
+	stopC         chan struct{}
+}
+
+// WitnessPartitionMetadata is the persisted metadata of a witness partition.
+type WitnessPartitionMetadata struct {
+	VolumeID    string
+	PartitionID uint64
+	Peers       []proto.Peer
+	ApplyID     uint64
+}
+
+// isWitnessRequest returns true if the node is added as a witness of the data partition.
+func isWitnessRequest(request *proto.CreateDataPartitionRequest, nodeID uint64) bool {
+	for _, peer := range request.Members {
+		if peer.ID == nodeID {
+			return peer.IsWitness()
+		}
+	}
+	return false
+}
+
+// CreateWitnessPartition creates the witness partition in dir and starts its raft.
+func CreateWitnessPartition(dir string, raftStore raftstore.RaftStore, nodeID uint64,
+	request *proto.CreateDataPartitionRequest,
+) (wp *WitnessPartition, err error) {
+	wp = newWitnessPartition(path.Join(dir, fmt.Sprintf("%v_%v", WitnessPartitionPrefix, request.PartitionId)),
+		raftStore, nodeID, &WitnessPartitionMetadata{
+			VolumeID:    request.VolumeId,
+			PartitionID: request.PartitionId,
+			Peers:       request.Members,
+		})
+	if err = os.MkdirAll(wp.path, 0o755); err != nil {
+		return
+	}
+	if err = wp.PersistMetadata(); err != nil {
+		return
+	}
+	if err = wp.StartRaft(); err != nil {
+		return
+	}
+	go wp.scheduleTruncate()
+	return
+}
+
+// LoadWitnessPartition loads the witness partition from the metadata in partitionDir and starts its raft.
+func LoadWitnessPartition(partitionDir string, raftStore raftstore.RaftStore, nodeID uint64) (wp *WitnessPartition, err error) {
+	var data []byte
+	if data, err = ioutil.ReadFile(path.Join(partitionDir, DataPartitionMetadataFileName)); err != nil {
+		return
+	}
+	meta := &WitnessPartitionMetadata{}
+	if err = json.Unmarshal(data, meta); err != nil {
+		return
+	}
+	wp = newWitnessPartition(partitionDir, raftStore, nodeID, meta)
+	if err = wp.StartRaft(); err != nil {
+		return
+	}
+	go wp.scheduleTruncate()
+	return
+}
+
+func newWitnessPartition(partitionDir string, raftStore raftstore.RaftStore, nodeID uint64,
+	meta *WitnessPartitionMetadata,
+) *WitnessPartition {
+	return &WitnessPartition{
+		partitionID: meta.PartitionID,
+		volumeID:    meta.VolumeID,
+		path:        partitionDir,
+		nodeID:      nodeID,
+		appliedID:   meta.ApplyID,
+		peers:       meta.Peers,
+		raftStore:   raftStore,
+		stopC:       make(chan struct{}),
+	}
+}
+
+// StartRaft starts the raft instance of the witness partition.
+func (wp *WitnessPartition) StartRaft() (err error) {
+	heartbeatPort, replicaPort, err := raftPortOf(wp.raftStore)
+	if err != nil {
+		return
+	}
+	var peers []raftstore.PeerAddress
+	for _, peer := range wp.getPeers() {
+		peers = append(peers, raftstore.PeerAddress{
+			Peer: raftproto.Peer{
+				ID:   peer.ID,
+				Type: raftproto.PeerType(peer.Type),
+			},
+			Address:       strings.Split(peer.Addr, ":")[0],
+			HeartbeatPort: heartbeatPort,
+			ReplicaPort:   replicaPort,
+		})
+	}
+	log.LogInfof("action[StartRaft] witness dp(%v) raft peers(%v) path(%v)", wp.partitionID, peers, wp.path)
+	wp.raftPartition, err = wp.raftStore.CreatePartition(&raftstore.PartitionConfig{
+		ID:      wp.partitionID,
+		Applied: atomic.LoadUint64(&wp.appliedID),
+		Peers:   peers,
+		SM:      wp,
+		WalPath: wp.path,
+	})
+	return
+}
+
+// Stop stops the raft instance of the witness partition and persists the applied index.
+func (wp *WitnessPartition) Stop() {
+	wp.stopOnce.Do(func() {
+		close(wp.stopC)
+		if wp.raftPartition != nil {
+			wp.raftPartition.Stop()
+		}
+		if err := wp.PersistMetadata(); err != nil {
+			log.LogErrorf("action[Stop] witness dp(%v) persist metadata err(%v)", wp.partitionID, err)
+		}
+	})
+}
+
+// RemoveAll stops the witness partition and deletes its raft log and metadata.
+func (wp *WitnessPartition) RemoveAll() (err error) {
+	wp.Stop()
+	wp.persistMutex.Lock()
+	defer wp.persistMutex.Unlock()
+	return os.RemoveAll(wp.path)
+}
+
+// PersistMetadata persists the peers and the applied index of the witness partition.
+func (wp *WitnessPartition) PersistMetadata() (err error) {
+	wp.persistMutex.Lock()
+	defer wp.persistMutex.Unlock()
+	if _, err = os.Stat(wp.path); err != nil {
+		return
+	}
+	md := &WitnessPartitionMetadata{
+		VolumeID:    wp.volumeID,
+		PartitionID: wp.partitionID,
+		Peers:       wp.getPeers(),
+		ApplyID:     atomic.LoadUint64(&wp.appliedID),
+	}
+	var data []byte
+	if data, err = json.Marshal(md); err != nil {
+		return
+	}
+	fileName := path.Join(wp.path, TempMetadataFileName)
+	if err = ioutil.WriteFile(fileName, data, 0o666); err != nil {
+		return
+	}
+	return os.Rename(fileName, path.Join(wp.path, DataPartitionMetadataFileName))
+}
+
+// scheduleTruncate truncates the raft log periodically, the log entries have nothing to apply to a witness.
+func (wp *WitnessPartition) scheduleTruncate() {
+	ticker := time.NewTicker(witnessTruncateInterval)
+	defer ticker.Stop()
+	var lastTruncateID uint64
+	for {
+		select {
+		case <-ticker.C:
+			appliedID := atomic.LoadUint64(&wp.appliedID)
+			if appliedID <= lastTruncateID {
+				continue
+			}
+			if err := wp.PersistMetadata(); err != nil {
+				log.LogErrorf("action[scheduleTruncate] witness dp(%v) persist metadata err(%v)", wp.partitionID, err)
+				continue
+			}
+			wp.raftPartition.Truncate(appliedID)
+			lastTruncateID = appliedID
+		case <-wp.stopC:
+			return
+		}
+	}
+}
+
+func (wp *WitnessPartition) getPeers() []proto.Peer {
+	wp.peersLock.RLock()
+	defer wp.peersLock.RUnlock()
+	peers := make([]proto.Peer, len(wp.peers))
+	copy(peers, wp.peers)
+	return peers
+}
+
+/* The functions below implement the interfaces defined in the raft library. */
+
+// Apply records the index only, a witness stores no data.
+func (wp *WitnessPartition) Apply(command []byte, index uint64) (resp interface{}, err error) {
+	atomic.StoreUint64(&wp.appliedID, index)
+	return proto.OpOk, nil
+}
+
+// ApplyMemberChange keeps the peers of the witness partition the same as the data partition.
+func (wp *WitnessPartition) ApplyMemberChange(confChange *raftproto.ConfChange, index uint64) (resp interface{}, err error) {
+	atomic.StoreUint64(&wp.appliedID, index)
+	wp.peersLock.Lock()
+	switch confChange.Type {
+	case raftproto.ConfAddNode:
+		req := &proto.AddDataPartitionRaftMemberRequest{}
+		if err = json.Unmarshal(confChange.Context, req); err != nil {
+			break
+		}
+		exist := false
+		for _, peer := range wp.peers {
+			if peer.ID == req.AddPeer.ID {
+				exist = true
+				break
+			}
+		}
+		if !exist {
+			wp.peers = append(wp.peers, req.AddPeer)
+		}
+	case raftproto.ConfRemoveNode:
+		req := &proto.RemoveDataPartitionRaftMemberRequest{}
+		if err = json.Unmarshal(confChange.Context, req); err != nil {
+			break
+		}
+		peers := make([]proto.Peer, 0, len(wp.peers))
+		for _, peer := range wp.peers {
+			if peer.ID != req.RemovePeer.ID {
+				peers = append(peers, peer)
+			}
+		}
+		wp.peers = peers
+	case raftproto.ConfUpdateNode:
+		req := &proto.PromoteDataPartitionLearnerRequest{}
+		if err = json.Unmarshal(confChange.Context, req); err != nil {
+			break
+		}
+		for i, peer := range wp.peers {
+			if peer.ID == req.PromotePeer.ID && peer.IsLearner() {
+				wp.peers[i].Type = proto.PeerNormal
+			}
+		}
+	}
+	wp.peersLock.Unlock()
+	if err != nil {
+		log.LogErrorf("action[ApplyMemberChange] witness dp(%v) type(%v) index(%v) err(%v)",
+			wp.partitionID, confChange.Type, index, err)
+		return
+	}
+	log.LogInfof("action[ApplyMemberChange] witness dp(%v) type(%v) index(%v) peers(%v)",
+		wp.partitionID, confChange.Type, index, wp.getPeers())
+	err = wp.PersistMetadata()
+	return
+}
+
+// Snapshot returns an empty snapshot, a witness never becomes the leader.
+func (wp *WitnessPartition) Snapshot() (raftproto.Snapshot, error) {
+	return NewItemIterator(wp.raftPartition.AppliedIndex()), nil
+}
+
+// ApplySnapshot drains the snapshot, the snapshot of a data partition carries no data either.
+func (wp *WitnessPartition) ApplySnapshot(peers []raftproto.Peer, iterator raftproto.SnapIterator) (err error) {
+	for {
+		if _, err = iterator.Next(); err != nil {
+			break
+		}
+	}
+	if err == io.EOF {
+		err = nil
+	}
+	log.LogInfof("action[ApplySnapshot] witness dp(%v) apply snapshot err(%v)", wp.partitionID, err)
+	return
+}
+
+// HandleFatalEvent stops the raft of the witness partition.
+func (wp *WitnessPartition) HandleFatalEvent(err *raft.FatalError) {
+	log.LogCriticalf("action[HandleFatalEvent] witness dp(%v) err(%v)", wp.partitionID, err)
+	wp.Stop()
+}
+
+// HandleLeaderChange does nothing, a witness never becomes the leader.
+func (wp *WitnessPartition) HandleLeaderChange(leader uint64) {
+	log.LogDebugf("action[HandleLeaderChange] witness dp(%v) leader(%v)", wp.partitionID, leader)
+}
@@ -0,0 +1,77 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package datanode
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path"
+	"testing"
+
+	raftproto "github.com/cubefs/cubefs/depends/tiglabs/raft/proto"
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWitnessPartitionMemberChange(t *testing.T) {
+	request := &proto.CreateDataPartitionRequest{
+		PartitionId: 10,
+		VolumeId:    "vol",
+		Members: []proto.Peer{
+			{ID: 1, Addr: "127.0.0.1:17310"},
+			{ID: 2, Addr: "127.0.0.2:17310"},
+			{ID: 3, Addr: "127.0.0.3:17310", Type: proto.PeerWitness},
+		},
+	}
+	require.False(t, isWitnessRequest(request, 1))
+	require.True(t, isWitnessRequest(request, 3))
+	require.False(t, isWitnessRequest(request, 4))
+
+	dir := t.TempDir()
+	wp := newWitnessPartition(dir, nil, 3, &WitnessPartitionMetadata{
+		VolumeID:    request.VolumeId,
+		PartitionID: request.PartitionId,
+		Peers:       request.Members,
+	})
+
+	_, err := wp.Apply([]byte("data"), 5)
+	require.NoError(t, err)
+
+	// a learner joins, is promoted, then an old replica leaves
+	learner := proto.Peer{ID: 4, Addr: "127.0.0.4:17310", Type: proto.PeerLearner}
+	ctx, err := json.Marshal(&proto.AddDataPartitionRaftMemberRequest{PartitionId: 10, AddPeer: learner})
+	require.NoError(t, err)
+	_, err = wp.ApplyMemberChange(&raftproto.ConfChange{Type: raftproto.ConfAddNode, Context: ctx}, 6)
+	require.NoError(t, err)
+	ctx, err = json.Marshal(&proto.PromoteDataPartitionLearnerRequest{PartitionId: 10, PromotePeer: learner})
+	require.NoError(t, err)
+	_, err = wp.ApplyMemberChange(&raftproto.ConfChange{Type: raftproto.ConfUpdateNode, Context: ctx}, 7)
+	require.NoError(t, err)
+	ctx, err = json.Marshal(&proto.RemoveDataPartitionRaftMemberRequest{PartitionId: 10, RemovePeer: request.Members[0]})
+	require.NoError(t, err)
+	_, err = wp.ApplyMemberChange(&raftproto.ConfChange{Type: raftproto.ConfRemoveNode, Context: ctx}, 8)
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadFile(path.Join(dir, DataPartitionMetadataFileName))
+	require.NoError(t, err)
+	meta := &WitnessPartitionMetadata{}
+	require.NoError(t, json.Unmarshal(data, meta))
+	require.Equal(t, uint64(8), meta.ApplyID)
+	require.Equal(t, []proto.Peer{
+		request.Members[1],
+		request.Members[2],
+		{ID: 4, Addr: "127.0.0.4:17310", Type: proto.PeerNormal},
+	}, meta.Peers)
+}
@@ -129,6 +129,14 @@ const (
 
 	// disk status becomes unavailable if disk error partition count reaches this value
 	ConfigKeyDiskUnavailablePartitionErrorCount = "diskUnavailablePartitionErrorCount"
+
+	// online extent defragmentation
+	ConfigKeyEnableExtentDefrag         = "enableExtentDefrag"         // bool
+	ConfigKeyExtentDefragInterval       = "extentDefragInterval"       // int, second
+	ConfigKeyExtentDefragMinExtents     = "extentDefragMinExtents"     // int
+	ConfigKeyExtentDefragMinTinyExtents = "extentDefragMinTinyExtents" // int
+	ConfigKeyExtentDefragMaxFileSize    = "extentDefragMaxFileSize"    // int, byte
+	ConfigKeyExtentDefragColdTime       = "extentDefragColdTime"       // int, second
 )
 
 const cpuSampleDuration = 1 * time.Second
@@ -189,6 +197,8 @@ type DataNode struct {
 
 	diskUnavailablePartitionErrorCount uint64 // disk status becomes unavailable when disk error partition count reaches this value
 	started                            int32
+
+	defragger *extentDefragger
 }
 
 type verOp2Phase struct {
@@ -231,6 +241,7 @@ func doStart(server common.Server, cfg *config.Config) (err error) {
 	if err = s.parseConfig(cfg); err != nil {
 		return
 	}
+	s.parseExtentDefragConfig(cfg)
 
 	s.registerMetrics()
 	s.register(cfg)
@@ -494,6 +505,9 @@ func (s *DataNode) startSpaceManager(cfg *config.Config) (err error) {
 	}
 
 	wg.Wait()
+	if err = s.space.LoadWitnessPartitions(); err != nil {
+		return fmt.Errorf("load witness partitions failed: %v", err)
+	}
 	// start async sample
 	s.space.StartDiskSample()
 	s.updateQosLimit() // load from config
@@ -678,6 +692,7 @@ func (s *DataNode) registerHandler() {
 	http.HandleFunc("/setAutoRepairStatus", s.setAutoRepairStatus)
 	http.HandleFunc("/getTinyDeleted", s.getTinyDeleted)
 	http.HandleFunc("/getNormalDeleted", s.getNormalDeleted)
+	http.HandleFunc("/getTinyExtentHoleInfo", s.getTinyExtentHoleInfo)
 	http.HandleFunc("/getSmuxPoolStat", s.getSmuxPoolStat())
 	http.HandleFunc("/setMetricsDegrade", s.setMetricsDegrade)
 	http.HandleFunc("/getMetricsDegrade", s.getMetricsDegrade)
@@ -939,6 +954,9 @@ func (s *DataNode) shallDegrade() bool {
 func (s *DataNode) scheduleTask() {
 	go s.startUpdateNodeInfo()
 	s.scheduleToCheckLackPartitions()
+	if s.defragger != nil {
+		go s.defragger.run()
+	}
 }
 
 func (s *DataNode) startCpuSample() {
@@ -267,6 +267,28 @@ func (s *DataNode) getTinyDeleted(w http.ResponseWriter, r *http.Request) {
 	s.buildSuccessResp(w, extentInfo)
 }
 
+func (s *DataNode) getTinyExtentHoleInfo(w http.ResponseWriter, r *http.Request) {
+	var (
+		pid   common.Uint
+		err   error
+		infos []*storage.TinyExtentHoleInfo
+	)
+	if err = parseArgs(r, pid.ID()); err != nil {
+		s.buildFailureResp(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	partition := s.space.Partition(pid.V)
+	if partition == nil {
+		s.buildFailureResp(w, http.StatusNotFound, "partition not exist")
+		return
+	}
+	if infos, err = partition.ExtentStore().GetTinyExtentHoleInfo(); err != nil {
+		s.buildFailureResp(w, 500, err.Error())
+		return
+	}
+	s.buildSuccessResp(w, infos)
+}
+
 func (s *DataNode) getNormalDeleted(w http.ResponseWriter, r *http.Request) {
 	var (
 		pid        common.Uint
@@ -43,6 +43,7 @@ type SpaceManager struct {
 	clusterID          string
 	disks              map[string]*Disk
 	partitions         map[uint64]*DataPartition
+	witnesses          map[uint64]*WitnessPartition
 	raftStore          raftstore.RaftStore
 	nodeID             uint64
 	diskMutex          sync.RWMutex
@@ -69,6 +70,7 @@ func NewSpaceManager(dataNode *DataNode) *SpaceManager {
 	space.disks = make(map[string]*Disk)
 	space.diskList = make([]string, 0)
 	space.partitions = make(map[uint64]*DataPartition)
+	space.witnesses = make(map[uint64]*WitnessPartition)
 	space.stats = NewStats(dataNode.zoneName)
 	space.stopC = make(chan bool)
 	space.dataNode = dataNode
@@ -111,6 +113,9 @@ func (manager *SpaceManager) Stop() {
 	for _, partition := range manager.partitions {
 		partition.stopRaft()
 	}
+	for _, wp := range manager.witnesses {
+		wp.Stop()
+	}
 
 	var wg sync.WaitGroup
 	for _, d := range manager.disks {
@@ -547,10 +552,56 @@ func (manager *SpaceManager) CreatePartition(request *proto.CreateDataPartitionR
 	return
 }
 
+// CreateWitnessPartition creates the witness of a data partition in the raft directory.
+func (manager *SpaceManager) CreateWitnessPartition(request *proto.CreateDataPartitionRequest) (wp *WitnessPartition, err error) {
+	manager.partitionMutex.Lock()
+	defer manager.partitionMutex.Unlock()
+	if wp = manager.witnesses[request.PartitionId]; wp != nil {
+		return
+	}
+	if manager.partitions[request.PartitionId] != nil {
+		return nil, fmt.Errorf("data partition(%v) already exists", request.PartitionId)
+	}
+	if wp, err = CreateWitnessPartition(manager.dataNode.raftDir, manager.raftStore, manager.nodeID, request); err != nil {
+		return
+	}
+	manager.witnesses[wp.partitionID] = wp
+	return
+}
+
+// LoadWitnessPartitions loads the witness partitions in the raft directory.
+func (manager *SpaceManager) LoadWitnessPartitions() (err error) {
+	var entries []os.DirEntry
+	if entries, err = os.ReadDir(manager.dataNode.raftDir); err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || !RegexpWitnessPartitionDir.MatchString(entry.Name()) {
+			continue
+		}
+		var wp *WitnessPartition
+		if wp, err = LoadWitnessPartition(path.Join(manager.dataNode.raftDir, entry.Name()), manager.raftStore, manager.nodeID); err != nil {
+			log.LogErrorf("action[LoadWitnessPartitions] load witness(%v) err(%v)", entry.Name(), err)
+			return
+		}
+		manager.partitionMutex.Lock()
+		manager.witnesses[wp.partitionID] = wp
+		manager.partitionMutex.Unlock()
+		log.LogInfof("action[LoadWitnessPartitions] load witness dp(%v) peers(%v)", wp.partitionID, wp.getPeers())
+	}
+	return
+}
+
 // DeletePartition deletes a partition based on the partition id.
 func (manager *SpaceManager) DeletePartition(dpID uint64, decommissionType uint32, force bool) (err error) {
 	manager.partitionMutex.Lock()
 
+	if wp := manager.witnesses[dpID]; wp != nil {
+		delete(manager.witnesses, dpID)
+		manager.partitionMutex.Unlock()
+		return wp.RemoveAll()
+	}
+
 	dp := manager.partitions[dpID]
 	if dp == nil {
 		manager.partitionMutex.Unlock()
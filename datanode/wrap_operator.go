@@ -31,6 +31,7 @@ import (
 	"github.com/cubefs/cubefs/depends/tiglabs/raft"
 	raftProto "github.com/cubefs/cubefs/depends/tiglabs/raft/proto"
 	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/raftstore"
 	"github.com/cubefs/cubefs/repl"
 	"github.com/cubefs/cubefs/storage"
 	"github.com/cubefs/cubefs/util"
@@ -173,6 +174,8 @@ func (s *DataNode) OperatePacket(p *repl.Packet, c net.Conn) (err error) {
 		s.handlePacketToAddDataPartitionRaftMember(p)
 	case proto.OpRemoveDataPartitionRaftMember:
 		s.handlePacketToRemoveDataPartitionRaftMember(p)
+	case proto.OpPromoteDataPartitionLearner:
+		s.handlePacketToPromoteDataPartitionLearner(p)
 	case proto.OpDataPartitionTryToLeader:
 		s.handlePacketToDataPartitionTryToLeader(p)
 	case proto.OpGetPartitionSize:
@@ -189,6 +192,8 @@ func (s *DataNode) OperatePacket(p *repl.Packet, c net.Conn) (err error) {
 		s.handleBatchUnlockNormalExtent(p, c)
 	case proto.OpVersionOperation:
 		s.handleUpdateVerPacket(p)
+	case proto.OpDefragFenceExtents:
+		s.handlePacketToDefragFenceExtents(p)
 	case proto.OpStopDataPartitionRepair:
 		s.handlePacketToStopDataPartitionRepair(p)
 	case proto.OpRecoverDataReplicaMeta:
@@ -262,6 +267,15 @@ func (s *DataNode) handlePacketToCreateDataPartition(p *repl.Packet) {
 		return
 	}
 	p.PartitionID = request.PartitionId
+	// a witness keeps the raft log only, it has no disk path to reply
+	if isWitnessRequest(request, s.space.GetNodeID()) {
+		if _, err = s.space.CreateWitnessPartition(request); err != nil {
+			err = fmt.Errorf("from master Task(%v) cannot create witness err(%v)", task.ToString(), err)
+			return
+		}
+		p.PacketOkReply()
+		return
+	}
 	if dp, err = s.space.CreatePartition(request); err != nil {
 		err = fmt.Errorf("from master Task(%v) cannot create Partition err(%v)", task.ToString(), err)
 		return
@@ -761,7 +775,7 @@ func (s *DataNode) handleBatchMarkDeletePacket(p *repl.Packet, c net.Conn) {
 				partition.disk.limitWrite.Run(0, func() {
 					log.LogInfof("[handleBatchMarkDeletePacket] vol(%v) dp(%v) mark delete extent(%v)", partition.config.VolName, partition.partitionID, ext.ExtentId)
 					if proto.IsTinyExtentType(p.ExtentType) || ext.IsSnapshotDeletion {
-						err = store.MarkDelete(ext.ExtentId, int64(ext.ExtentOffset), int64(ext.Size))
+						err = store.MarkDeleteWithToken(ext.ExtentId, int64(ext.ExtentOffset), int64(ext.Size), ext.ReleaseToken)
 					} else {
 						// NOTE: it must use 0 to remove normal extent
 						// Consider the following scenario:
@@ -771,7 +785,7 @@ func (s *DataNode) handleBatchMarkDeletePacket(p *repl.Packet, c net.Conn) {
 						// meta partition: size 100kb
 						// when we remove the file, the request size is 100kb
 						// replica 1 will lost 100kb if we use ext.Size to remove extent
-						err = partition.ExtentStore().MarkDelete(ext.ExtentId, 0, 0)
+						err = partition.ExtentStore().MarkDeleteWithToken(ext.ExtentId, 0, 0, ext.ReleaseToken)
 					}
 					if err != nil {
 						log.LogErrorf("action[handleBatchMarkDeletePacket]: failed to mark delete extent(%v), %v", ext.ExtentId, err)
@@ -781,8 +795,10 @@ func (s *DataNode) handleBatchMarkDeletePacket(p *repl.Packet, c net.Conn) {
 					return
 				}
 			} else {
+				// stop here, the whole batch is retried and the applied releases are ignored by their tokens
 				log.LogInfof("delete limiter reach(%v), remote (%v) try again.", deleteLimiteRater.Limit(), c.RemoteAddr().String())
 				err = storage.LimitedIoError
+				return
 			}
 		}
 	}
@@ -998,6 +1014,11 @@ func (s *DataNode) handleRandomWritePacket(p *repl.Packet) {
 		return
 	}
 
+	if err == nil && p.ResultCode == proto.ErrCodeVersionOpError {
+		err = storage.ExtentDefragFencedError
+		return
+	}
+
 	if err == nil && p.ResultCode != proto.OpOk && p.ResultCode != proto.OpTryOtherExtent {
 		log.LogErrorf("action[handleRandomWritePacket] opcod %v seq %v dpid %v dpseq %v extid %v ResultCode %v",
 			p.Opcode, p.VerSeq, p.PartitionID, partition.verSeq, p.ExtentID, p.ResultCode)
@@ -1325,7 +1346,8 @@ func (s *DataNode) handlePacketToDecommissionDataPartition(p *repl.Packet) {
 		return
 	}
 	if req.AddPeer.ID != 0 {
-		_, err = dp.ChangeRaftMember(raftProto.ConfAddNode, raftProto.Peer{ID: req.AddPeer.ID}, reqData)
+		_, err = dp.ChangeRaftMember(raftProto.ConfAddNode,
+			raftProto.Peer{ID: req.AddPeer.ID, Type: raftProto.PeerType(req.AddPeer.Type)}, reqData)
 		if err != nil {
 			return
 		}
@@ -1410,6 +1432,64 @@ func (s *DataNode) handlePacketToAddDataPartitionRaftMember(p *repl.Packet) {
 	log.LogInfof("action[handlePacketToAddDataPartitionRaftMember] after ChangeRaftMember %v, partition id %v", req.AddPeer, &req.PartitionId)
 }
 
+func (s *DataNode) handlePacketToPromoteDataPartitionLearner(p *repl.Packet) {
+	var (
+		err          error
+		reqData      []byte
+		isRaftLeader bool
+		req          = &proto.PromoteDataPartitionLearnerRequest{}
+	)
+
+	defer func() {
+		if err != nil {
+			p.PackErrorBody(ActionPromoteDataPartitionLearner, err.Error())
+		} else {
+			p.PacketOkReply()
+		}
+	}()
+
+	adminTask := &proto.AdminTask{}
+	decode := json.NewDecoder(bytes.NewBuffer(p.Data))
+	decode.UseNumber()
+	if err = decode.Decode(adminTask); err != nil {
+		return
+	}
+	if reqData, err = json.Marshal(adminTask.Request); err != nil {
+		return
+	}
+	if err = json.Unmarshal(reqData, req); err != nil {
+		return
+	}
+	log.LogInfof("action[handlePacketToPromoteDataPartitionLearner] req(%v) promotePeer %v, partition id %v",
+		p.GetReqID(), req.PromotePeer, req.PartitionId)
+
+	p.AddMesgLog(string(reqData))
+	dp := s.space.Partition(req.PartitionId)
+	if dp == nil {
+		err = proto.ErrDataPartitionNotExists
+		return
+	}
+	p.PartitionID = req.PartitionId
+	peer, ok := dp.getPeer(req.PromotePeer.ID)
+	if !ok {
+		err = fmt.Errorf("peer(%v) not found in dp(%v)", req.PromotePeer, req.PartitionId)
+		return
+	}
+	if !peer.IsLearner() {
+		log.LogInfof("action[handlePacketToPromoteDataPartitionLearner] dp %v peer %v is not learner", dp.partitionID, peer)
+		return
+	}
+
+	isRaftLeader, err = s.forwardToRaftLeader(dp, p, false)
+	if !isRaftLeader {
+		return
+	}
+	if err = raftstore.CheckLearnerCaughtUp(dp.raftPartition.Status(), peer.ID, req.MaxLagOfLogs); err != nil {
+		return
+	}
+	_, err = dp.ChangeRaftMember(raftProto.ConfUpdateNode, raftProto.Peer{ID: peer.ID, Type: raftProto.PeerNormal}, reqData)
+}
+
 func (s *DataNode) handlePacketToRemoveDataPartitionRaftMember(p *repl.Packet) {
 	var (
 		err          error
@@ -1775,6 +1855,31 @@ func (s *DataNode) handleBatchUnlockNormalExtent(p *repl.Packet, connect net.Con
 	log.LogInfof("action[handleBatchUnlockNormalExtent] success len: %v", len(exts))
 }
 
+// Handle OpDefragFenceExtents packet.
+func (s *DataNode) handlePacketToDefragFenceExtents(p *repl.Packet) {
+	var err error
+	defer func() {
+		if err != nil {
+			p.PackErrorBody(ActionDefragFenceExtents, err.Error())
+		} else {
+			p.PacketOkReply()
+		}
+	}()
+
+	partition := p.Object.(*DataPartition)
+	req := &proto.DefragFenceRequest{}
+	if err = json.Unmarshal(p.Data[:p.Size], req); err != nil {
+		return
+	}
+	if _, isLeader := partition.IsRaftLeader(); !isLeader {
+		err = raft.ErrNotLeader
+		return
+	}
+	err = partition.HandleDefragFence(req)
+	log.LogInfof("action[handlePacketToDefragFenceExtents] dp(%v) fence(%v) action(%v) eks(%v) err(%v)",
+		partition.partitionID, req.FenceID, req.Action, len(req.Eks), err)
+}
+
 func (s *DataNode) handlePacketToRecoverBackupDataReplica(p *repl.Packet) {
 	task := &proto.AdminTask{}
 	err := json.Unmarshal(p.Data, task)
@@ -52,8 +52,12 @@ const (
 	EntryNormal     EntryType = 0
 	EntryConfChange EntryType = 1
 
-	PeerNormal  PeerType = 0
+	PeerNormal PeerType = 0
+	// PeerArbiter is a witness which votes and keeps the raft log, but never becomes the leader.
 	PeerArbiter PeerType = 1
+	// PeerLearner receives the raft log but is not counted in the quorum until it is promoted
+	// to a normal peer by ConfUpdateNode.
+	PeerLearner PeerType = 2
 )
 
 // The Snapshot interface is supplied by the application to access the snapshot data of application.
@@ -195,10 +199,22 @@ func (t PeerType) String() string {
 		return "PeerNormal"
 	case 1:
 		return "PeerArbiter"
+	case 2:
+		return "PeerLearner"
 	}
 	return "unknown"
 }
 
+// IsVoter returns true if the peer is counted in the quorum.
+func (p Peer) IsVoter() bool {
+	return p.Type != PeerLearner
+}
+
+// IsPromotable returns true if the peer can campaign to be the leader.
+func (p Peer) IsPromotable() bool {
+	return p.Type == PeerNormal
+}
+
 func (p Peer) String() string {
 	return fmt.Sprintf(`"nodeID":"%v","peerID":"%v","priority":"%v","type":"%v"`,
 		p.ID, p.PeerID, p.Priority, p.Type.String())
@@ -776,6 +776,7 @@ func (s *raft) getStatus() *Status {
 		st.Replicas = make(map[uint64]*ReplicaStatus)
 		for id, p := range s.raftFsm.replicas {
 			st.Replicas[id] = &ReplicaStatus{
+				Type:        p.peer.Type.String(),
 				Match:       p.match,
 				Commit:      p.committed,
 				Next:        p.next,
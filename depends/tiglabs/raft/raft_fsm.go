@@ -488,7 +488,18 @@ func (r *raftFsm) updatePeer(peer proto.Peer) {
 }
 
 func (r *raftFsm) quorum() int {
-	return len(r.replicas)/2 + 1
+	var voters int
+	for _, pr := range r.replicas {
+		if pr.peer.IsVoter() {
+			voters++
+		}
+	}
+	return voters/2 + 1
+}
+
+func (r *raftFsm) isVoter(id uint64) bool {
+	pr, ok := r.replicas[id]
+	return ok && pr.peer.IsVoter()
 }
 
 func (r *raftFsm) send(m *proto.Message) {
@@ -115,8 +115,8 @@ func (r *raftFsm) campaign(force bool, t CampaignType) {
 		return
 	}
 
-	for id := range r.replicas {
-		if id == r.config.NodeID {
+	for id, pr := range r.replicas {
+		if id == r.config.NodeID || !pr.peer.IsVoter() {
 			continue
 		}
 		li, lt := r.raftLog.lastIndexAndTerm()
@@ -144,9 +144,16 @@ func (r *raftFsm) poll(id uint64, v bool) (granted int) {
 			logger.Debug("raft[%v,%v] received vote rejection from %v at term %d.", r.id, r.config.ReplicateAddr, id, r.term)
 		}
 	}
+	if !r.isVoter(id) {
+		return r.granted()
+	}
 	if _, ok := r.votes[id]; !ok {
 		r.votes[id] = v
 	}
+	return r.granted()
+}
+
+func (r *raftFsm) granted() (granted int) {
 	for _, vv := range r.votes {
 		if vv {
 			granted++
@@ -317,6 +317,43 @@ func TestCommit(t *testing.T) {
 	}
 }
 
+// TestLearnerNotInQuorum ensures the match index of a learner is not counted in the quorum
+// until it is promoted, and a learner never campaigns.
+func TestLearnerNotInQuorum(t *testing.T) {
+	s := stor.DefaultMemoryStorage()
+	s.StoreEntries([]*proto.Entry{{Index: 1, Term: 1}, {Index: 2, Term: 1}})
+	s.StoreHardState(proto.HardState{Term: 1})
+	cfg := newTestRaftConfig(1, withStorage(s), withPeers(1, 2))
+	sm := newTestRaftFsm(10, 1, cfg)
+	learner := proto.Peer{PeerID: 3, ID: 3, Type: proto.PeerLearner}
+	sm.applyConfChange(&proto.ConfChange{Type: proto.ConfAddNode, Peer: learner})
+	if q := sm.quorum(); q != 2 {
+		t.Fatalf("quorum = %d, want 2", q)
+	}
+
+	sm.replicas[1].match, sm.replicas[1].next = 2, 3
+	sm.replicas[2].match, sm.replicas[2].next = 0, 1
+	sm.replicas[3].match, sm.replicas[3].next = 2, 3
+	sm.maybeCommit()
+	if g := sm.raftLog.committed; g != 0 {
+		t.Errorf("committed = %d, want 0", g)
+	}
+
+	learner.Type = proto.PeerNormal
+	sm.applyConfChange(&proto.ConfChange{Type: proto.ConfUpdateNode, Peer: learner})
+	sm.maybeCommit()
+	if g := sm.raftLog.committed; g != 2 {
+		t.Errorf("committed = %d, want 2", g)
+	}
+
+	lcfg := newTestRaftConfig(3, withPeers(1, 2))
+	lsm := newTestRaftFsm(10, 1, lcfg)
+	lsm.applyConfChange(&proto.ConfChange{Type: proto.ConfAddNode, Peer: proto.Peer{PeerID: 3, ID: 3, Type: proto.PeerLearner}})
+	if lsm.promotable() {
+		t.Errorf("learner should not be promotable")
+	}
+}
+
 // TestHandleMsgApp ensures:
 // 1. Reply false if log doesn’t contain an entry at prevLogIndex whose term matches prevLogTerm.
 // 2. If an existing entry conflicts with a new one (same index but different terms),
@@ -27,6 +27,7 @@ type DownReplica struct {
 
 // ReplicaStatus  replica status
 type ReplicaStatus struct {
+	Type        string // PeerNormal、PeerArbiter、PeerLearner
 	Match       uint64 // 复制进度
 	Commit      uint64 // commmit位置
 	Next        uint64
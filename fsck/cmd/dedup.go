@@ -0,0 +1,491 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cubefs/cubefs/metanode"
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/sdk/data/stream"
+	"github.com/cubefs/cubefs/sdk/master"
+	"github.com/cubefs/cubefs/sdk/meta"
+	"github.com/cubefs/cubefs/storage"
+	"github.com/cubefs/cubefs/util"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	defaultDedupMinSize  = 1 * util.MB
+	defaultDedupColdTime = 24 * time.Hour
+	dedupAddRefsRetry    = 3
+)
+
+// dedupRef is an extent key of a file which references a whole normal extent.
+type dedupRef struct {
+	Inode uint64
+	Ek    proto.ExtentKey
+	State *dedupInode
+}
+
+// dedupInode is the generation and the modify time of the inode when it was collected, the
+// remap is rejected if the inode has been modified since then. The remaps of the inode bump
+// the generation, so the state is shared by all the references of the inode.
+type dedupInode struct {
+	Gen        uint64
+	ModifyTime int64
+}
+
+// dedupExtent is a physical normal extent and the files referencing it.
+type dedupExtent struct {
+	PartitionId uint64
+	ExtentId    uint64
+	Size        uint32
+	Refs        []*dedupRef
+	Fingerprint string
+	recorded    map[uint64]bool // the inodes which have recorded the fingerprint
+	invalid     bool
+}
+
+type dedupStat struct {
+	Groups      uint64
+	Duplicates  uint64
+	Reclaimable uint64
+	Reclaimed   uint64
+	LeakedRefs  uint64
+}
+
+func newDedupCmd() *cobra.Command {
+	var (
+		minSize  uint32
+		coldTime time.Duration
+	)
+	c := &cobra.Command{
+		Use:   "dedup",
+		Short: "report and reclaim the space of duplicate normal extents",
+		Long: `Find the normal extents of the volume with the same content. With --clean true,
+the duplicates are mapped to a single extent whose reference count is kept by the
+datanodes, and the duplicate extents are released through the normal delete path.
+Cleaning requires dedup to be enabled on the volume.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			setCleanStatus()
+			if err := Dedup(minSize, coldTime); err != nil {
+				fmt.Println(err)
+			}
+		},
+	}
+	c.Flags().Uint32Var(&minSize, "min-size", defaultDedupMinSize, "skip extents smaller than this size")
+	c.Flags().DurationVar(&coldTime, "cold-time", defaultDedupColdTime, "skip files modified within this duration")
+	return c
+}
+
+func Dedup(minSize uint32, coldTime time.Duration) (err error) {
+	defer log.LogFlush()
+
+	if MasterAddr == "" || VolName == "" || MetaPort == "" {
+		return fmt.Errorf("Lack of parameters: master(%v) vol(%v) mport(%v)", MasterAddr, VolName, MetaPort)
+	}
+	if _, err = log.InitLog("fscklog", "fsck", log.InfoLevel, nil, log.DefaultLogLeftSpaceLimitRatio); err != nil {
+		return fmt.Errorf("Init log failed: %v", err)
+	}
+
+	masters := strings.Split(MasterAddr, meta.HostsSeparator)
+	if CleanS {
+		var view *proto.SimpleVolView
+		if view, err = master.NewMasterClient(masters, false).AdminAPI().GetVolumeSimpleInfo(VolName); err != nil {
+			return fmt.Errorf("Get volume %v failed: %v", VolName, err)
+		}
+		if !view.EnableDedup {
+			return fmt.Errorf("dedup is not enabled on vol %v", VolName)
+		}
+	}
+	mw, err := meta.NewMetaWrapper(&meta.MetaConfig{Volume: VolName, Masters: masters})
+	if err != nil {
+		return fmt.Errorf("NewMetaWrapper failed: %v", err)
+	}
+	defer mw.Close()
+	ec, err := stream.NewExtentClient(&stream.ExtentConfig{
+		Volume:            VolName,
+		Masters:           masters,
+		OnAppendExtentKey: mw.AppendExtentKey,
+		OnSplitExtentKey:  mw.SplitExtentKey,
+		OnGetExtents:      mw.GetExtents,
+		OnTruncate:        mw.Truncate,
+	})
+	if err != nil {
+		return fmt.Errorf("NewExtentClient failed: %v", err)
+	}
+	defer ec.Close()
+
+	extents, err := collectDedupExtents(minSize, coldTime)
+	if err != nil {
+		return
+	}
+	groups := fingerprintDedupExtents(mw, ec, extents)
+
+	stat := &dedupStat{}
+	for _, group := range groups {
+		stat.Groups++
+		stat.Duplicates += uint64(len(group) - 1)
+		stat.Reclaimable += uint64(len(group)-1) * uint64(group[0].Size)
+		fmt.Printf("fingerprint %s size %d copies %d\n", group[0].Fingerprint, group[0].Size, len(group))
+		for _, e := range group {
+			fmt.Printf("    dp %d extent %d files %d\n", e.PartitionId, e.ExtentId, len(e.Refs))
+		}
+		if CleanS {
+			reclaimDedupGroup(mw, ec, group, stat)
+		}
+	}
+	fmt.Printf("vol %s: %d duplicate groups, %d duplicate extents, %d bytes reclaimable, %d bytes reclaimed, %d refs leaked\n",
+		VolName, stat.Groups, stat.Duplicates, stat.Reclaimable, stat.Reclaimed, stat.LeakedRefs)
+	return
+}
+
+// collectDedupExtents loads the inodes of the volume from the metanode snapshots, and returns the
+// normal extents referenced as a whole by cold regular files, grouped by the extent size.
+func collectDedupExtents(minSize uint32, coldTime time.Duration) (bySize map[uint32][]*dedupExtent, err error) {
+	mps, err := getMetaPartitions(MasterAddr, VolName)
+	if err != nil {
+		return
+	}
+	extents := make(map[string]*dedupExtent)
+	deadline := time.Now().Add(-coldTime).Unix()
+	for _, mp := range mps {
+		err = rangeMpInodes(mp.PartitionID, func(ino *metanode.Inode) {
+			if !proto.IsRegular(ino.Type) || ino.ModifyTime > deadline {
+				return
+			}
+			state := &dedupInode{Gen: ino.Generation, ModifyTime: ino.ModifyTime}
+			ino.Extents.Range(func(_ int, ek proto.ExtentKey) bool {
+				if storage.IsTinyExtent(ek.ExtentId) || ek.ExtentOffset != 0 || ek.Size < minSize ||
+					ek.IsSplit() || ek.GetSeq() != 0 {
+					return true
+				}
+				key := fmt.Sprintf("%d_%d", ek.PartitionId, ek.ExtentId)
+				e, ok := extents[key]
+				if !ok {
+					e = &dedupExtent{PartitionId: ek.PartitionId, ExtentId: ek.ExtentId, Size: ek.Size}
+					extents[key] = e
+				}
+				// an extent referenced partially can not be shared
+				if e.Size != ek.Size {
+					e.invalid = true
+				}
+				e.Refs = append(e.Refs, &dedupRef{Inode: ino.Inode, Ek: ek, State: state})
+				return true
+			})
+		})
+		if err != nil {
+			return
+		}
+	}
+	bySize = make(map[uint32][]*dedupExtent)
+	for _, e := range extents {
+		if !e.invalid {
+			bySize[e.Size] = append(bySize[e.Size], e)
+		}
+	}
+	return
+}
+
+func rangeMpInodes(mpId uint64, f func(ino *metanode.Inode)) (err error) {
+	mpInfo, err := getMpInfoById(strconv.FormatUint(mpId, 10))
+	if err != nil {
+		return
+	}
+	var leaderAddr string
+	for _, mr := range mpInfo.Replicas {
+		if mr.IsLeader {
+			leaderAddr = mr.Addr
+			break
+		}
+	}
+	if leaderAddr == "" {
+		return fmt.Errorf("Get leader address failed mpId %v", mpId)
+	}
+
+	resp, err := http.Get(fmt.Sprintf("http://%s:%s/getInodeSnapshot?pid=%d", strings.Split(leaderAddr, ":")[0], MetaPort, mpId))
+	if err != nil {
+		return fmt.Errorf("Get inode snapshot failed, mp %d, addr %s, err: %v", mpId, leaderAddr, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Invalid status code: %v, mp %d, addr %s", resp.StatusCode, mpId, leaderAddr)
+	}
+
+	reader := bufio.NewReaderSize(resp.Body, 16*1024*1024)
+	inoBuf := make([]byte, 4)
+	for {
+		inoBuf = inoBuf[:4]
+		if _, err = io.ReadFull(reader, inoBuf); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return
+		}
+		length := binary.BigEndian.Uint32(inoBuf)
+		if uint32(cap(inoBuf)) >= length {
+			inoBuf = inoBuf[:length]
+		} else {
+			inoBuf = make([]byte, length)
+		}
+		if _, err = io.ReadFull(reader, inoBuf); err != nil {
+			return fmt.Errorf("read inode failed, mp %d, err %v", mpId, err)
+		}
+		ino := &metanode.Inode{
+			Generation: 1,
+			NLink:      1,
+			Extents:    metanode.NewSortedExtents(),
+			ObjExtents: metanode.NewSortedObjExtents(),
+		}
+		if err = ino.Unmarshal(inoBuf); err != nil {
+			return fmt.Errorf("unmarshal inode failed, mp %d, err %v", mpId, err)
+		}
+		f(ino)
+	}
+}
+
+func readDedupRef(ec *stream.ExtentClient, ref *dedupRef) (data []byte, err error) {
+	if err = ec.OpenStream(ref.Inode); err != nil {
+		return
+	}
+	defer ec.CloseStream(ref.Inode)
+	data = make([]byte, ref.Ek.Size)
+	n, err, _ := ec.ReadExtent(ref.Inode, &ref.Ek, data, 0, int(ref.Ek.Size))
+	if err == nil && n != int(ref.Ek.Size) {
+		err = fmt.Errorf("read %v bytes, expect %v", n, ref.Ek.Size)
+	}
+	return
+}
+
+func fingerprintOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// loadDedupFingerprint returns the fingerprint of the extent recorded by the files referencing it.
+func loadDedupFingerprint(mw *meta.MetaWrapper, e *dedupExtent, cache map[uint64]map[string]string) string {
+	key := proto.DedupFingerprintKey(e.PartitionId, e.ExtentId)
+	e.recorded = make(map[uint64]bool, len(e.Refs))
+	var fingerprint string
+	for _, ref := range e.Refs {
+		fingerprints, ok := cache[ref.Inode]
+		if !ok {
+			fingerprints = make(map[string]string)
+			info, err := mw.XAttrGet_ll(ref.Inode, proto.DedupFingerprintXAttr)
+			if err == nil && len(info.Get(proto.DedupFingerprintXAttr)) > 0 {
+				if err = json.Unmarshal(info.Get(proto.DedupFingerprintXAttr), &fingerprints); err != nil {
+					log.LogWarnf("loadDedupFingerprint: ino %d invalid fingerprints, err: %v", ref.Inode, err)
+				}
+			}
+			cache[ref.Inode] = fingerprints
+		}
+		if fp, ok := fingerprints[key]; ok {
+			e.recorded[ref.Inode] = true
+			fingerprint = fp
+		}
+	}
+	return fingerprint
+}
+
+// fingerprintDedupExtents groups the extents sharing the same size by the content. The fingerprints
+// recorded on metanode are used to avoid reading the extents again, the content is always verified
+// before reclaim. Each group is ordered and the first one is kept on reclaim.
+func fingerprintDedupExtents(mw *meta.MetaWrapper, ec *stream.ExtentClient, bySize map[uint32][]*dedupExtent) (groups [][]*dedupExtent) {
+	cache := make(map[uint64]map[string]string)
+	for _, extents := range bySize {
+		if len(extents) < 2 {
+			continue
+		}
+		byFingerprint := make(map[string][]*dedupExtent)
+		for _, e := range extents {
+			if e.Fingerprint = loadDedupFingerprint(mw, e, cache); e.Fingerprint == "" {
+				data, err := readDedupRef(ec, e.Refs[0])
+				if err != nil {
+					log.LogWarnf("fingerprintDedupExtents: read dp %d extent %d failed, err: %v", e.PartitionId, e.ExtentId, err)
+					continue
+				}
+				e.Fingerprint = fingerprintOf(data)
+			}
+			byFingerprint[e.Fingerprint] = append(byFingerprint[e.Fingerprint], e)
+		}
+		for _, group := range byFingerprint {
+			if len(group) < 2 {
+				continue
+			}
+			sort.Slice(group, func(i, j int) bool {
+				if group[i].PartitionId != group[j].PartitionId {
+					return group[i].PartitionId < group[j].PartitionId
+				}
+				return group[i].ExtentId < group[j].ExtentId
+			})
+			groups = append(groups, group)
+		}
+	}
+	return
+}
+
+// verifyDedupExtent checks that the content of the extent read by all its files matches the fingerprint.
+func verifyDedupExtent(ec *stream.ExtentClient, e *dedupExtent, fingerprint string) error {
+	for _, ref := range e.Refs {
+		data, err := readDedupRef(ec, ref)
+		if err != nil {
+			return err
+		}
+		if fingerprintOf(data) != fingerprint {
+			return fmt.Errorf("ino %d ek %v content changed", ref.Inode, ref.Ek)
+		}
+	}
+	return nil
+}
+
+// reclaimDedupGroup maps the files of the duplicate extents to the first extent of the group.
+// The references are added on the datanodes before the extent keys are changed on metanode, so a
+// failed remap only leaks a reference and never frees the data still in use.
+func reclaimDedupGroup(mw *meta.MetaWrapper, ec *stream.ExtentClient, group []*dedupExtent, stat *dedupStat) {
+	keep := group[0]
+	// the fingerprint may be recorded before the extent is overwritten
+	if err := verifyDedupExtent(ec, keep, keep.Fingerprint); err != nil {
+		log.LogWarnf("reclaimDedupGroup: dp %d extent %d changed, err: %v", keep.PartitionId, keep.ExtentId, err)
+		return
+	}
+	for _, ref := range keep.Refs {
+		if keep.recorded[ref.Inode] {
+			continue
+		}
+		remap := proto.ExtentRemap{Old: ref.Ek, New: ref.Ek, Fingerprint: keep.Fingerprint}
+		if err := remapDedupRef(mw, ref, remap); err != nil {
+			log.LogWarnf("reclaimDedupGroup: record fingerprint of ino %d ek %v failed, err: %v", ref.Inode, ref.Ek, err)
+		}
+	}
+
+	for _, dup := range group[1:] {
+		var remapped int
+		if err := verifyDedupExtent(ec, dup, keep.Fingerprint); err != nil {
+			log.LogWarnf("reclaimDedupGroup: dp %d extent %d changed, err: %v", dup.PartitionId, dup.ExtentId, err)
+			continue
+		}
+		if err := addDedupExtentRefs(keep, dup, len(dup.Refs)); err != nil {
+			log.LogWarnf("reclaimDedupGroup: add refs of dp %d extent %d failed, err: %v", keep.PartitionId, keep.ExtentId, err)
+			continue
+		}
+		for _, ref := range dup.Refs {
+			newEk := ref.Ek
+			newEk.PartitionId = keep.PartitionId
+			newEk.ExtentId = keep.ExtentId
+			remap := proto.ExtentRemap{Old: ref.Ek, New: newEk, Fingerprint: keep.Fingerprint}
+			if err := remapDedupRef(mw, ref, remap); err != nil {
+				log.LogWarnf("reclaimDedupGroup: remap ino %d ek %v failed, err: %v", ref.Inode, ref.Ek, err)
+				stat.LeakedRefs++
+				continue
+			}
+			remapped++
+		}
+		if remapped == len(dup.Refs) {
+			stat.Reclaimed += uint64(dup.Size)
+		}
+		log.LogInfof("reclaimDedupGroup: dp %d extent %d, remapped %d/%d files to dp %d extent %d",
+			dup.PartitionId, dup.ExtentId, remapped, len(dup.Refs), keep.PartitionId, keep.ExtentId)
+	}
+}
+
+// remapDedupRef remaps the extent key of the reference unless the inode has been written since
+// collected, the writes after verifying the content must not be overwritten by the kept extent.
+func remapDedupRef(mw *meta.MetaWrapper, ref *dedupRef, remap proto.ExtentRemap) (err error) {
+	if _, err = mw.RemapExtentKeys(ref.Inode, []proto.ExtentRemap{remap}, ref.State.Gen, ref.State.ModifyTime); err != nil {
+		return
+	}
+	ref.State.Gen++
+	return
+}
+
+// addDedupExtentRefs adds cnt references to the kept extent on all the replicas for the files of the
+// duplicate extent. The duplicate extent is the token of the references, so retrying is safe.
+func addDedupExtentRefs(keep, dup *dedupExtent, cnt int) (err error) {
+	dpInfo, err := getDpInfoById(strconv.FormatUint(keep.PartitionId, 10))
+	if err != nil {
+		return
+	}
+	eks := make([]*proto.ExtentKey, 0, cnt)
+	for i := 0; i < cnt; i++ {
+		eks = append(eks, &proto.ExtentKey{
+			PartitionId: keep.PartitionId,
+			ExtentId:    keep.ExtentId,
+			Size:        keep.Size,
+		})
+	}
+	lockEks := &proto.GcLockExtents{
+		Eks:        eks,
+		Flag:       proto.GcDedupRefFlag,
+		DedupToken: fmt.Sprintf("dedup_%d_%d", dup.PartitionId, dup.ExtentId),
+	}
+	for i := 0; i < dedupAddRefsRetry; i++ {
+		if err = sendDedupExtentRefs(dpInfo, lockEks); err == nil {
+			return
+		}
+		log.LogWarnf("addDedupExtentRefs: dp %d extent %d token %s failed, retry %d, err: %v",
+			keep.PartitionId, keep.ExtentId, lockEks.DedupToken, i, err)
+	}
+	return
+}
+
+func sendDedupExtentRefs(dpInfo *proto.DataPartitionInfo, lockEks *proto.GcLockExtents) (err error) {
+	addr := dpInfo.Hosts[0]
+	conn, err := streamConnPool.GetConnect(addr)
+	defer func() {
+		streamConnPool.PutConnect(conn, err != nil)
+	}()
+	if err != nil {
+		return
+	}
+
+	p := new(proto.Packet)
+	p.Magic = proto.ProtoMagic
+	p.Opcode = proto.OpBatchLockNormalExtent
+	p.ExtentType = proto.NormalExtentType
+	p.PartitionID = dpInfo.PartitionID
+	if p.Data, err = json.Marshal(lockEks); err != nil {
+		return
+	}
+	p.Size = uint32(len(p.Data))
+	p.ReqID = proto.GenerateRequestID()
+	p.RemainingFollowers = uint8(len(dpInfo.Hosts) - 1)
+	p.Arg = []byte(strings.Join(dpInfo.Hosts[1:], proto.AddrSplit) + proto.AddrSplit)
+	p.ArgLen = uint32(len(p.Arg))
+
+	if err = p.WriteToConn(conn); err != nil {
+		return
+	}
+	if err = p.ReadFromConn(conn, proto.BatchDeleteExtentReadDeadLineTime); err != nil {
+		return
+	}
+	if p.ResultCode != proto.OpOk {
+		err = fmt.Errorf("addDedupExtentRefs dp %d failed, ResultCode: %v", dpInfo.PartitionID, p.String())
+	}
+	return
+}
@@ -45,6 +45,7 @@ func NewRootCmd() *cobra.Command {
 		newCleanCmd(),
 		newInfoCmd(),
 		newGCCommand(),
+		newDedupCmd(),
 	)
 
 	c.PersistentFlags().StringVarP(&MasterAddr, "master", "m", "", "master addresses")
@@ -253,6 +253,7 @@ func (c *Client) Start() (err error) {
 		OnAppendExtentKey: mw.AppendExtentKey,
 		OnGetExtents:      mw.GetExtents,
 		OnTruncate:        mw.Truncate,
+		OnModifyInode:     mw.InodeModify,
 		BcacheEnable:      c.cfg.EnableBcache,
 		OnLoadBcache:      c.bc.Get,
 		OnCacheBcache:     c.bc.Put,
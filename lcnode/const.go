@@ -31,6 +31,7 @@ const (
 
 	configSnapshotRoutineNumPerTaskStr = "snapshotRoutineNumPerTask"
 	configLcNodeTaskCountLimit         = "lcNodeTaskCountLimit"
+	configInventoryRecordsPerFileStr   = "inventoryRecordsPerFile"
 )
 
 // Default of configuration value
@@ -51,6 +52,15 @@ const (
 	defaultUnboundedChanInitCapacity = 10000
 	defaultLcNodeTaskCountLimit      = 1
 	maxLcNodeTaskCountLimit          = 20
+
+	defaultInventoryRecordsPerFile = 1000000
+	maxInventoryRecordsPerFile     = 10000000
+)
+
+// the extended attributes of the objects stored by ObjectNode
+const (
+	xattrKeyOSSETag    = "oss:etag"
+	xattrKeyOSSTagging = "oss:tagging"
 )
 
 var (
@@ -64,4 +74,5 @@ var (
 
 	snapshotRoutineNumPerTask int
 	lcNodeTaskCountLimit      int
+	inventoryRecordsPerFile   int
 )
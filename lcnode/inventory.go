@@ -0,0 +1,360 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package lcnode
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+	"github.com/google/uuid"
+)
+
+const (
+	inventoryManifestVersion = "2016-11-30"
+	inventoryParquetSchema   = "s3.inventory"
+	inventoryStorageClass    = "STANDARD"
+	inventoryTimeFormat      = "2006-01-02T15:04:05.000Z"
+	inventoryDirTimeFormat   = "2006-01-02T15-04Z"
+)
+
+// the optional fields are always reported in this order
+var inventoryOptionalFields = []string{
+	proto.InventoryFieldSize,
+	proto.InventoryFieldLastModifiedDate,
+	proto.InventoryFieldETag,
+	proto.InventoryFieldStorageClass,
+	proto.InventoryFieldTags,
+}
+
+var inventoryParquetColumns = map[string]*parquetColumn{
+	proto.InventoryFieldSize:             {name: "size", physicalType: parquetTypeInt64, convertedType: parquetConvertedNone},
+	proto.InventoryFieldLastModifiedDate: {name: "last_modified_date", physicalType: parquetTypeInt64, convertedType: parquetConvertedTimestampMillis},
+	proto.InventoryFieldETag:             {name: "e_tag", physicalType: parquetTypeByteArray, convertedType: parquetConvertedUTF8, optional: true},
+	proto.InventoryFieldStorageClass:     {name: "storage_class", physicalType: parquetTypeByteArray, convertedType: parquetConvertedUTF8},
+	proto.InventoryFieldTags:             {name: "tags", physicalType: parquetTypeByteArray, convertedType: parquetConvertedUTF8, optional: true},
+}
+
+type inventoryRecord struct {
+	Key          string
+	Size         uint64
+	LastModified time.Time
+	ETag         string
+	Tags         string
+}
+
+// newInventoryRecord makes the record of the object from the inode and the extended attributes.
+// The ETag is not reported if it is not stored or stale, e.g. the object is written by the file
+// system interface, as the ETag is only updated when the object is read by ObjectNode.
+func newInventoryRecord(key string, inode *proto.InodeInfo, xattr *proto.XAttrInfo) *inventoryRecord {
+	record := &inventoryRecord{
+		Key:          key,
+		Size:         inode.Size,
+		LastModified: inode.ModifyTime,
+	}
+	if xattr == nil {
+		return record
+	}
+	if raw := string(xattr.Get(xattrKeyOSSETag)); raw != "" {
+		etag, ts := raw, int64(0)
+		if i := strings.LastIndex(raw, ":"); i >= 0 {
+			etag = raw[:i]
+			ts, _ = strconv.ParseInt(raw[i+1:], 10, 64)
+		}
+		if ts >= inode.ModifyTime.Unix() {
+			record.ETag = etag
+		}
+	}
+	record.Tags = string(xattr.Get(xattrKeyOSSTagging))
+	return record
+}
+
+type inventoryEncoder interface {
+	append(bucket string, record *inventoryRecord)
+	rows() int
+	encode() ([]byte, error)
+}
+
+type csvInventoryEncoder struct {
+	fields []string
+	buf    bytes.Buffer
+	gw     *gzip.Writer
+	cw     *csv.Writer
+	count  int
+}
+
+func newCSVInventoryEncoder(fields []string) *csvInventoryEncoder {
+	e := &csvInventoryEncoder{fields: fields}
+	e.gw = gzip.NewWriter(&e.buf)
+	e.cw = csv.NewWriter(e.gw)
+	return e
+}
+
+func (e *csvInventoryEncoder) append(bucket string, record *inventoryRecord) {
+	values := make([]string, 0, len(e.fields)+2)
+	values = append(values, bucket, url.QueryEscape(record.Key))
+	for _, field := range e.fields {
+		switch field {
+		case proto.InventoryFieldSize:
+			values = append(values, strconv.FormatUint(record.Size, 10))
+		case proto.InventoryFieldLastModifiedDate:
+			values = append(values, record.LastModified.UTC().Format(inventoryTimeFormat))
+		case proto.InventoryFieldETag:
+			values = append(values, record.ETag)
+		case proto.InventoryFieldStorageClass:
+			values = append(values, inventoryStorageClass)
+		case proto.InventoryFieldTags:
+			values = append(values, record.Tags)
+		}
+	}
+	// the writer only fails if the underlying buffer fails
+	_ = e.cw.Write(values)
+	e.count++
+}
+
+func (e *csvInventoryEncoder) rows() int {
+	return e.count
+}
+
+func (e *csvInventoryEncoder) encode() ([]byte, error) {
+	e.cw.Flush()
+	if err := e.cw.Error(); err != nil {
+		return nil, err
+	}
+	if err := e.gw.Close(); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
+type parquetInventoryEncoder struct {
+	fields []string
+	writer *parquetWriter
+}
+
+func newParquetInventoryEncoder(fields []string) *parquetInventoryEncoder {
+	w := &parquetWriter{
+		name: inventoryParquetSchema,
+		columns: []*parquetColumn{
+			{name: "bucket", physicalType: parquetTypeByteArray, convertedType: parquetConvertedUTF8},
+			{name: "key", physicalType: parquetTypeByteArray, convertedType: parquetConvertedUTF8},
+		},
+	}
+	for _, field := range fields {
+		c := *inventoryParquetColumns[field]
+		w.columns = append(w.columns, &c)
+	}
+	return &parquetInventoryEncoder{fields: fields, writer: w}
+}
+
+func (e *parquetInventoryEncoder) append(bucket string, record *inventoryRecord) {
+	columns := e.writer.columns
+	columns[0].appendString(bucket)
+	columns[1].appendString(record.Key)
+	for i, field := range e.fields {
+		c := columns[i+2]
+		switch field {
+		case proto.InventoryFieldSize:
+			c.appendInt64(int64(record.Size))
+		case proto.InventoryFieldLastModifiedDate:
+			c.appendInt64(record.LastModified.UnixNano() / int64(time.Millisecond))
+		case proto.InventoryFieldETag:
+			c.appendOptionalString(record.ETag)
+		case proto.InventoryFieldStorageClass:
+			c.appendString(inventoryStorageClass)
+		case proto.InventoryFieldTags:
+			c.appendOptionalString(record.Tags)
+		}
+	}
+	e.writer.rows++
+}
+
+func (e *parquetInventoryEncoder) rows() int {
+	return int(e.writer.rows)
+}
+
+func (e *parquetInventoryEncoder) encode() ([]byte, error) {
+	return e.writer.encode(), nil
+}
+
+type InventoryManifest struct {
+	SourceBucket      string                   `json:"sourceBucket"`
+	DestinationBucket string                   `json:"destinationBucket"`
+	Version           string                   `json:"version"`
+	CreationTimestamp string                   `json:"creationTimestamp"`
+	FileFormat        string                   `json:"fileFormat"`
+	FileSchema        string                   `json:"fileSchema"`
+	Files             []*InventoryManifestFile `json:"files"`
+}
+
+type InventoryManifestFile struct {
+	Key         string `json:"key"`
+	Size        int64  `json:"size"`
+	MD5Checksum string `json:"MD5checksum"`
+}
+
+// InventoryReport generates the inventory report of the bucket into the destination bucket:
+//
+//	<prefix>/<bucket>/<id>/data/<uuid>.csv.gz or <uuid>.parquet
+//	<prefix>/<bucket>/<id>/<YYYY-MM-DDTHH-MMZ>/manifest.json
+//	<prefix>/<bucket>/<id>/<YYYY-MM-DDTHH-MMZ>/manifest.checksum
+//
+// The records are written into a new data file every inventoryRecordsPerFile records, and the manifest
+// listing all the data files is written at last, so the report is available only if it is completed.
+type InventoryReport struct {
+	sync.Mutex
+	bucket     string
+	config     *proto.InventoryConfiguration
+	store      inventoryStore
+	fields     []string
+	encoder    inventoryEncoder
+	files      []*InventoryManifestFile
+	createTime time.Time
+	err        error
+}
+
+func NewInventoryReport(bucket string, config *proto.InventoryConfiguration, store inventoryStore, now time.Time) *InventoryReport {
+	r := &InventoryReport{
+		bucket:     bucket,
+		config:     config,
+		store:      store,
+		files:      make([]*InventoryManifestFile, 0),
+		createTime: now,
+	}
+	enabled := make(map[string]bool)
+	for _, field := range config.OptionalFields {
+		enabled[field] = true
+	}
+	for _, field := range inventoryOptionalFields {
+		if enabled[field] {
+			r.fields = append(r.fields, field)
+		}
+	}
+	r.encoder = r.newEncoder()
+	return r
+}
+
+func (r *InventoryReport) newEncoder() inventoryEncoder {
+	if r.config.Format == proto.InventoryFormatParquet {
+		return newParquetInventoryEncoder(r.fields)
+	}
+	return newCSVInventoryEncoder(r.fields)
+}
+
+func (r *InventoryReport) key(items ...string) string {
+	parts := make([]string, 0, len(items)+3)
+	if prefix := strings.Trim(r.config.DestinationPrefix, "/"); prefix != "" {
+		parts = append(parts, prefix)
+	}
+	parts = append(parts, r.bucket, r.config.ID)
+	return strings.Join(append(parts, items...), "/")
+}
+
+func (r *InventoryReport) schema() string {
+	if r.config.Format == proto.InventoryFormatParquet {
+		return r.encoder.(*parquetInventoryEncoder).writer.schema()
+	}
+	return strings.Join(append([]string{"Bucket", "Key"}, r.fields...), ", ")
+}
+
+// Append appends the records into the report, the records are dropped if the report has failed.
+func (r *InventoryReport) Append(records []*inventoryRecord) {
+	r.Lock()
+	defer r.Unlock()
+	if r.err != nil {
+		return
+	}
+	for _, record := range records {
+		r.encoder.append(r.bucket, record)
+		if r.encoder.rows() >= inventoryRecordsPerFile {
+			if r.err = r.flush(); r.err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (r *InventoryReport) flush() error {
+	if r.encoder.rows() == 0 {
+		return nil
+	}
+	data, err := r.encoder.encode()
+	if err != nil {
+		return err
+	}
+	ext := ".csv.gz"
+	if r.config.Format == proto.InventoryFormatParquet {
+		ext = ".parquet"
+	}
+	key := r.key("data", uuid.New().String()+ext)
+	if err = r.store.put(key, data); err != nil {
+		log.LogErrorf("InventoryReport: put data file fail: bucket(%v) id(%v) key(%v) err(%v)",
+			r.bucket, r.config.ID, key, err)
+		return err
+	}
+	sum := md5.Sum(data)
+	r.files = append(r.files, &InventoryManifestFile{Key: key, Size: int64(len(data)), MD5Checksum: hex.EncodeToString(sum[:])})
+	r.encoder = r.newEncoder()
+	return nil
+}
+
+// Finish writes the remaining records and the manifest of the report.
+func (r *InventoryReport) Finish() (err error) {
+	r.Lock()
+	defer r.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	if err = r.flush(); err != nil {
+		return
+	}
+	manifest := &InventoryManifest{
+		SourceBucket:      r.bucket,
+		DestinationBucket: "arn:aws:s3:::" + r.config.DestinationBucket,
+		Version:           inventoryManifestVersion,
+		CreationTimestamp: strconv.FormatInt(r.createTime.UnixNano()/int64(time.Millisecond), 10),
+		FileFormat:        r.config.Format,
+		FileSchema:        r.schema(),
+		Files:             r.files,
+	}
+	var data []byte
+	if data, err = json.Marshal(manifest); err != nil {
+		return
+	}
+	dir := r.createTime.UTC().Format(inventoryDirTimeFormat)
+	if err = r.store.put(r.key(dir, "manifest.json"), data); err != nil {
+		return
+	}
+	sum := md5.Sum(data)
+	if err = r.store.put(r.key(dir, "manifest.checksum"), []byte(hex.EncodeToString(sum[:]))); err != nil {
+		return
+	}
+	log.LogInfof("InventoryReport: report finished: bucket(%v) id(%v) files(%v)", r.bucket, r.config.ID, len(r.files))
+	return
+}
+
+func (r *InventoryReport) Close() error {
+	return r.store.close()
+}
@@ -0,0 +1,340 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package lcnode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// The parquet file is written with a subset of the format: the whole file is a single row group, each
+// column chunk is split into PLAIN encoded and uncompressed data pages of bounded size, and the definition
+// levels of the optional columns are encoded by the RLE/bit-packing hybrid encoding. The page headers and
+// the file metadata are encoded by the thrift compact protocol.
+// https://github.com/apache/parquet-format
+
+const parquetMagic = "PAR1"
+
+// parquetPageSize is the size of values in a data page, a new page is started once it is exceeded.
+var parquetPageSize = 1 << 20
+
+// parquet physical types and converted types
+const (
+	parquetTypeInt64     int32 = 2
+	parquetTypeByteArray int32 = 6
+
+	parquetConvertedNone            int32 = -1
+	parquetConvertedUTF8            int32 = 0
+	parquetConvertedTimestampMillis int32 = 9
+
+	parquetRepetitionRequired int32 = 0
+	parquetRepetitionOptional int32 = 1
+	parquetEncodingPlain      int32 = 0
+	parquetEncodingRLE        int32 = 3
+	parquetCodecUncompressed  int32 = 0
+	parquetPageTypeData       int32 = 0
+)
+
+// thrift compact protocol types
+const (
+	thriftTypeI32    byte = 5
+	thriftTypeI64    byte = 6
+	thriftTypeBinary byte = 8
+	thriftTypeList   byte = 9
+	thriftTypeStruct byte = 12
+)
+
+type thriftWriter struct {
+	buf     bytes.Buffer
+	lastIDs []int16
+}
+
+func newThriftWriter() *thriftWriter {
+	return &thriftWriter{lastIDs: []int16{0}}
+}
+
+func (w *thriftWriter) uvarint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	w.buf.Write(b[:binary.PutUvarint(b[:], v)])
+}
+
+func (w *thriftWriter) varint(v int64) {
+	w.uvarint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (w *thriftWriter) fieldHeader(id int16, typ byte) {
+	last := &w.lastIDs[len(w.lastIDs)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		w.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		w.buf.WriteByte(typ)
+		w.varint(int64(id))
+	}
+	*last = id
+}
+
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.fieldHeader(id, thriftTypeI32)
+	w.varint(int64(v))
+}
+
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.fieldHeader(id, thriftTypeI64)
+	w.varint(v)
+}
+
+func (w *thriftWriter) binary(id int16, v string) {
+	w.fieldHeader(id, thriftTypeBinary)
+	w.uvarint(uint64(len(v)))
+	w.buf.WriteString(v)
+}
+
+func (w *thriftWriter) list(id int16, elemType byte, size int) {
+	w.fieldHeader(id, thriftTypeList)
+	if size < 15 {
+		w.buf.WriteByte(byte(size)<<4 | elemType)
+	} else {
+		w.buf.WriteByte(0xf0 | elemType)
+		w.uvarint(uint64(size))
+	}
+}
+
+// beginStruct begins the struct field, or the struct element of the list if id is 0.
+func (w *thriftWriter) beginStruct(id int16) {
+	if id > 0 {
+		w.fieldHeader(id, thriftTypeStruct)
+	}
+	w.lastIDs = append(w.lastIDs, 0)
+}
+
+func (w *thriftWriter) endStruct() {
+	w.buf.WriteByte(0)
+	w.lastIDs = w.lastIDs[:len(w.lastIDs)-1]
+}
+
+// bytes ends the top level struct and returns the encoded data.
+func (w *thriftWriter) bytes() []byte {
+	w.buf.WriteByte(0)
+	return w.buf.Bytes()
+}
+
+type parquetPage struct {
+	values bytes.Buffer
+	levels []byte // definition levels of the optional column, 0 for null and 1 for value
+	rows   int32
+}
+
+// encodeLevels encodes the definition levels by the RLE runs of the RLE/bit-packing hybrid encoding with
+// bit width 1, prefixed by the 4 bytes length.
+func (p *parquetPage) encodeLevels() []byte {
+	buf := bytes.NewBuffer(make([]byte, 4))
+	var b [binary.MaxVarintLen64]byte
+	for start := 0; start < len(p.levels); {
+		end := start + 1
+		for end < len(p.levels) && p.levels[end] == p.levels[start] {
+			end++
+		}
+		buf.Write(b[:binary.PutUvarint(b[:], uint64(end-start)<<1)])
+		buf.WriteByte(p.levels[start])
+		start = end
+	}
+	data := buf.Bytes()
+	binary.LittleEndian.PutUint32(data, uint32(len(data)-4))
+	return data
+}
+
+type parquetColumn struct {
+	name          string
+	physicalType  int32
+	convertedType int32
+	optional      bool
+	pages         []*parquetPage
+}
+
+func (c *parquetColumn) page() *parquetPage {
+	if n := len(c.pages); n > 0 && c.pages[n-1].values.Len() < parquetPageSize {
+		return c.pages[n-1]
+	}
+	p := &parquetPage{}
+	c.pages = append(c.pages, p)
+	return p
+}
+
+func (c *parquetColumn) appendLevel(p *parquetPage, level byte) {
+	if c.optional {
+		p.levels = append(p.levels, level)
+	}
+	p.rows++
+}
+
+func (c *parquetColumn) appendInt64(v int64) {
+	p := c.page()
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], uint64(v))
+	p.values.Write(b[:])
+	c.appendLevel(p, 1)
+}
+
+func (c *parquetColumn) appendString(v string) {
+	p := c.page()
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], uint32(len(v)))
+	p.values.Write(b[:])
+	p.values.WriteString(v)
+	c.appendLevel(p, 1)
+}
+
+// appendNull appends the null value of the optional column.
+func (c *parquetColumn) appendNull() {
+	c.appendLevel(c.page(), 0)
+}
+
+// appendOptionalString appends the string value of the optional column, or null if it is empty.
+func (c *parquetColumn) appendOptionalString(v string) {
+	if v == "" {
+		c.appendNull()
+		return
+	}
+	c.appendString(v)
+}
+
+func (c *parquetColumn) schema() string {
+	repetition := "required"
+	if c.optional {
+		repetition = "optional"
+	}
+	typ := "binary"
+	if c.physicalType == parquetTypeInt64 {
+		typ = "int64"
+	}
+	switch c.convertedType {
+	case parquetConvertedUTF8:
+		return fmt.Sprintf("%v %v %v (UTF8);", repetition, typ, c.name)
+	case parquetConvertedTimestampMillis:
+		return fmt.Sprintf("%v %v %v (TIMESTAMP_MILLIS);", repetition, typ, c.name)
+	}
+	return fmt.Sprintf("%v %v %v;", repetition, typ, c.name)
+}
+
+func (c *parquetColumn) repetition() int32 {
+	if c.optional {
+		return parquetRepetitionOptional
+	}
+	return parquetRepetitionRequired
+}
+
+type parquetWriter struct {
+	name    string
+	columns []*parquetColumn
+	rows    int64
+}
+
+func (w *parquetWriter) schema() string {
+	items := make([]string, 0, len(w.columns))
+	for _, c := range w.columns {
+		items = append(items, c.schema())
+	}
+	return fmt.Sprintf("message %v { %v }", w.name, strings.Join(items, " "))
+}
+
+func (w *parquetWriter) encode() []byte {
+	type chunk struct {
+		offset int64
+		size   int64
+	}
+	buf := bytes.NewBufferString(parquetMagic)
+	chunks := make([]chunk, 0, len(w.columns))
+	for _, c := range w.columns {
+		offset := int64(buf.Len())
+		for _, p := range c.pages {
+			var levels []byte
+			if c.optional {
+				levels = p.encodeLevels()
+			}
+			size := int32(len(levels) + p.values.Len())
+			header := newThriftWriter()
+			header.i32(1, parquetPageTypeData)
+			header.i32(2, size)
+			header.i32(3, size)
+			header.beginStruct(5)
+			header.i32(1, p.rows)
+			header.i32(2, parquetEncodingPlain)
+			header.i32(3, parquetEncodingRLE)
+			header.i32(4, parquetEncodingRLE)
+			header.endStruct()
+			buf.Write(header.bytes())
+			buf.Write(levels)
+			buf.Write(p.values.Bytes())
+		}
+		chunks = append(chunks, chunk{offset: offset, size: int64(buf.Len()) - offset})
+	}
+
+	meta := newThriftWriter()
+	meta.i32(1, 1)
+	meta.list(2, thriftTypeStruct, len(w.columns)+1)
+	meta.beginStruct(0)
+	meta.binary(4, w.name)
+	meta.i32(5, int32(len(w.columns)))
+	meta.endStruct()
+	for _, c := range w.columns {
+		meta.beginStruct(0)
+		meta.i32(1, c.physicalType)
+		meta.i32(3, c.repetition())
+		meta.binary(4, c.name)
+		if c.convertedType != parquetConvertedNone {
+			meta.i32(6, c.convertedType)
+		}
+		meta.endStruct()
+	}
+	meta.i64(3, w.rows)
+	meta.list(4, thriftTypeStruct, 1)
+	meta.beginStruct(0)
+	meta.list(1, thriftTypeStruct, len(w.columns))
+	var totalSize int64
+	for i, c := range w.columns {
+		meta.beginStruct(0)
+		meta.i64(2, chunks[i].offset)
+		meta.beginStruct(3)
+		meta.i32(1, c.physicalType)
+		meta.list(2, thriftTypeI32, 2)
+		meta.varint(int64(parquetEncodingPlain))
+		meta.varint(int64(parquetEncodingRLE))
+		meta.list(3, thriftTypeBinary, 1)
+		meta.uvarint(uint64(len(c.name)))
+		meta.buf.WriteString(c.name)
+		meta.i32(4, parquetCodecUncompressed)
+		meta.i64(5, w.rows)
+		meta.i64(6, chunks[i].size)
+		meta.i64(7, chunks[i].size)
+		meta.i64(9, chunks[i].offset)
+		meta.endStruct()
+		meta.endStruct()
+		totalSize += chunks[i].size
+	}
+	meta.i64(2, totalSize)
+	meta.i64(3, w.rows)
+	meta.endStruct()
+	meta.binary(6, "cubefs lcnode")
+	footer := meta.bytes()
+
+	buf.Write(footer)
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(len(footer)))
+	buf.Write(size[:])
+	buf.WriteString(parquetMagic)
+	return buf.Bytes()
+}
@@ -0,0 +1,245 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package lcnode
+
+import (
+	"encoding/binary"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+// thriftReader decodes the thrift compact protocol into the maps of field id to value.
+type thriftReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *thriftReader) byte() byte {
+	b := r.data[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.data[r.pos:])
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) varint() int64 {
+	u := r.uvarint()
+	return int64(u>>1) ^ -int64(u&1)
+}
+
+func (r *thriftReader) value(typ byte) interface{} {
+	switch typ {
+	case thriftTypeI32, thriftTypeI64:
+		return r.varint()
+	case thriftTypeBinary:
+		n := int(r.uvarint())
+		r.pos += n
+		return string(r.data[r.pos-n : r.pos])
+	case thriftTypeList:
+		h := r.byte()
+		size := int(h >> 4)
+		if size == 15 {
+			size = int(r.uvarint())
+		}
+		list := make([]interface{}, 0, size)
+		for i := 0; i < size; i++ {
+			list = append(list, r.value(h&0x0f))
+		}
+		return list
+	case thriftTypeStruct:
+		return r.structure()
+	}
+	panic(fmt.Sprintf("unexpected thrift type %v", typ))
+}
+
+func (r *thriftReader) structure() map[int16]interface{} {
+	fields := make(map[int16]interface{})
+	var last int16
+	for {
+		h := r.byte()
+		if h == 0 {
+			return fields
+		}
+		id := last + int16(h>>4)
+		if h>>4 == 0 {
+			id = int16(r.varint())
+		}
+		fields[id] = r.value(h & 0x0f)
+		last = id
+	}
+}
+
+type parquetTestColumn struct {
+	name     string
+	optional bool
+	pages    int
+	values   []interface{} // nil for null
+}
+
+// decodeLevels decodes the definition levels of bit width 1 by the RLE/bit-packing hybrid encoding.
+func decodeLevels(t *testing.T, data []byte, n int) []byte {
+	r := &thriftReader{data: data}
+	levels := make([]byte, 0, n)
+	for r.pos < len(data) {
+		h := r.uvarint()
+		if h&1 == 0 {
+			v := r.byte()
+			for i := uint64(0); i < h>>1; i++ {
+				levels = append(levels, v)
+			}
+			continue
+		}
+		for i := uint64(0); i < h>>1; i++ {
+			b := r.byte()
+			for bit := 0; bit < 8; bit++ {
+				levels = append(levels, b>>bit&1)
+			}
+		}
+	}
+	require.GreaterOrEqual(t, len(levels), n)
+	return levels[:n]
+}
+
+func readParquet(t *testing.T, data []byte) (rows int64, columns []*parquetTestColumn) {
+	require.Equal(t, parquetMagic, string(data[:4]))
+	require.Equal(t, parquetMagic, string(data[len(data)-4:]))
+	footerSize := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	meta := (&thriftReader{data: data[len(data)-8-footerSize : len(data)-8]}).structure()
+	rows = meta[3].(int64)
+
+	schema := meta[2].([]interface{})
+	require.Equal(t, int64(len(schema)-1), schema[0].(map[int16]interface{})[5])
+	for _, element := range schema[1:] {
+		fields := element.(map[int16]interface{})
+		columns = append(columns, &parquetTestColumn{
+			name:     fields[4].(string),
+			optional: fields[3].(int64) == int64(parquetRepetitionOptional),
+		})
+	}
+
+	rowGroups := meta[4].([]interface{})
+	require.Len(t, rowGroups, 1)
+	chunks := rowGroups[0].(map[int16]interface{})[1].([]interface{})
+	require.Len(t, chunks, len(columns))
+	for i, chunk := range chunks {
+		c := columns[i]
+		cc := chunk.(map[int16]interface{})
+		cm := cc[3].(map[int16]interface{})
+		require.Equal(t, []interface{}{c.name}, cm[3])
+		require.Equal(t, rows, cm[5])
+		r := &thriftReader{data: data, pos: int(cm[9].(int64))}
+		for int64(len(c.values)) < rows {
+			header := r.structure()
+			require.Equal(t, int64(parquetPageTypeData), header[1])
+			page := data[r.pos : r.pos+int(header[3].(int64))]
+			r.pos += len(page)
+			n := int(header[5].(map[int16]interface{})[1].(int64))
+			c.pages++
+
+			levels := make([]byte, n)
+			if c.optional {
+				size := int(binary.LittleEndian.Uint32(page))
+				levels = decodeLevels(t, page[4:4+size], n)
+				page = page[4+size:]
+			} else {
+				for j := range levels {
+					levels[j] = 1
+				}
+			}
+			for _, level := range levels {
+				if level == 0 {
+					c.values = append(c.values, nil)
+					continue
+				}
+				if cm[1].(int64) == int64(parquetTypeInt64) {
+					c.values = append(c.values, int64(binary.LittleEndian.Uint64(page)))
+					page = page[8:]
+					continue
+				}
+				size := int(binary.LittleEndian.Uint32(page))
+				c.values = append(c.values, string(page[4:4+size]))
+				page = page[4+size:]
+			}
+			require.Empty(t, page)
+		}
+		require.Equal(t, cc[2], cm[9])
+		require.Equal(t, cm[7].(int64), int64(r.pos)-cm[9].(int64))
+	}
+	return
+}
+
+func TestParquetRoundTrip(t *testing.T) {
+	defer func(size int) { parquetPageSize = size }(parquetPageSize)
+	parquetPageSize = 64
+
+	e := newParquetInventoryEncoder([]string{proto.InventoryFieldSize, proto.InventoryFieldETag, proto.InventoryFieldTags})
+	now := time.Now()
+	var keys, etags, tags []interface{}
+	for i := 0; i < 100; i++ {
+		record := &inventoryRecord{Key: fmt.Sprintf("dir/key-%03d", i), Size: uint64(i), LastModified: now}
+		keys = append(keys, record.Key)
+		etags = append(etags, nil)
+		tags = append(tags, nil)
+		if i%3 != 0 {
+			record.ETag = fmt.Sprintf("etag-%d", i)
+			etags[i] = record.ETag
+		}
+		if i >= 50 {
+			record.Tags = "k=v"
+			tags[i] = record.Tags
+		}
+		e.append("bucket", record)
+	}
+	data, err := e.encode()
+	require.NoError(t, err)
+
+	rows, columns := readParquet(t, data)
+	require.Equal(t, int64(100), rows)
+	require.Len(t, columns, 5)
+	sizes := make([]interface{}, 0, rows)
+	buckets := make([]interface{}, 0, rows)
+	for i := int64(0); i < rows; i++ {
+		sizes = append(sizes, i)
+		buckets = append(buckets, "bucket")
+	}
+	for i, expected := range []parquetTestColumn{
+		{name: "bucket", values: buckets},
+		{name: "key", values: keys},
+		{name: "size", values: sizes},
+		{name: "e_tag", optional: true, values: etags},
+		{name: "tags", optional: true, values: tags},
+	} {
+		require.Equal(t, expected.name, columns[i].name)
+		require.Equal(t, expected.optional, columns[i].optional)
+		require.Equal(t, expected.values, columns[i].values)
+		// the pages are bounded in size
+		require.Greater(t, columns[i].pages, 1)
+	}
+
+	// the empty file
+	data, err = newParquetInventoryEncoder(nil).encode()
+	require.NoError(t, err)
+	rows, columns = readParquet(t, data)
+	require.Equal(t, int64(0), rows)
+	require.Len(t, columns, 2)
+}
@@ -0,0 +1,172 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package lcnode
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+type mockInventoryStore struct {
+	objects map[string][]byte
+	err     error
+}
+
+func (s *mockInventoryStore) put(key string, data []byte) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.objects[key] = append([]byte(nil), data...)
+	return nil
+}
+
+func (s *mockInventoryStore) close() error {
+	return nil
+}
+
+func (s *mockInventoryStore) find(suffix string) (keys []string) {
+	for key := range s.objects {
+		if strings.HasSuffix(key, suffix) {
+			keys = append(keys, key)
+		}
+	}
+	return
+}
+
+func TestNewInventoryRecord(t *testing.T) {
+	mtime := time.Unix(1690000000, 0)
+	inode := &proto.InodeInfo{Inode: 1, Size: 10, ModifyTime: mtime}
+
+	record := newInventoryRecord("a/b", inode, &proto.XAttrInfo{Inode: 1, XAttrs: map[string]string{
+		xattrKeyOSSETag:    "5d41402abc4b2a76b9719d911017c592:1690000000",
+		xattrKeyOSSTagging: "k=v",
+	}})
+	require.Equal(t, "5d41402abc4b2a76b9719d911017c592", record.ETag)
+	require.Equal(t, "k=v", record.Tags)
+	require.Equal(t, uint64(10), record.Size)
+
+	// the stale etag is not reported
+	record = newInventoryRecord("a/b", inode, &proto.XAttrInfo{Inode: 1, XAttrs: map[string]string{
+		xattrKeyOSSETag: "5d41402abc4b2a76b9719d911017c592-2:1680000000",
+	}})
+	require.Equal(t, "", record.ETag)
+
+	record = newInventoryRecord("a/b", inode, nil)
+	require.Equal(t, "", record.ETag)
+}
+
+func TestInventoryReportCSV(t *testing.T) {
+	inventoryRecordsPerFile = 2
+	now := time.Date(2023, 8, 6, 1, 0, 0, 0, time.UTC)
+	store := &mockInventoryStore{objects: make(map[string][]byte)}
+	report := NewInventoryReport("src", &proto.InventoryConfiguration{
+		ID:                "daily",
+		Format:            proto.InventoryFormatCSV,
+		DestinationBucket: "dst",
+		DestinationPrefix: "reports/",
+		OptionalFields:    []string{proto.InventoryFieldETag, proto.InventoryFieldSize},
+	}, store, now)
+
+	mtime := time.Date(2023, 8, 1, 10, 20, 30, 0, time.UTC)
+	report.Append([]*inventoryRecord{
+		{Key: "a b/1", Size: 1, LastModified: mtime, ETag: "e1"},
+		{Key: "a b/2", Size: 2, LastModified: mtime, ETag: "e2"},
+		{Key: "c", Size: 3, LastModified: mtime},
+	})
+	require.NoError(t, report.Finish())
+	require.Len(t, store.objects, 4)
+
+	manifestKeys := store.find("/manifest.json")
+	require.Equal(t, []string{"reports/src/daily/2023-08-06T01-00Z/manifest.json"}, manifestKeys)
+	manifestData := store.objects[manifestKeys[0]]
+	sum := md5.Sum(manifestData)
+	require.Equal(t, hex.EncodeToString(sum[:]), string(store.objects["reports/src/daily/2023-08-06T01-00Z/manifest.checksum"]))
+
+	manifest := &InventoryManifest{}
+	require.NoError(t, json.Unmarshal(manifestData, manifest))
+	require.Equal(t, "src", manifest.SourceBucket)
+	require.Equal(t, "arn:aws:s3:::dst", manifest.DestinationBucket)
+	require.Equal(t, "Bucket, Key, Size, ETag", manifest.FileSchema)
+	require.Len(t, manifest.Files, 2)
+
+	var rows [][]string
+	for _, file := range manifest.Files {
+		require.True(t, strings.HasPrefix(file.Key, "reports/src/daily/data/"))
+		require.True(t, strings.HasSuffix(file.Key, ".csv.gz"))
+		data := store.objects[file.Key]
+		require.Equal(t, int64(len(data)), file.Size)
+		gr, err := gzip.NewReader(bytes.NewReader(data))
+		require.NoError(t, err)
+		records, err := csv.NewReader(gr).ReadAll()
+		require.NoError(t, err)
+		rows = append(rows, records...)
+	}
+	require.Equal(t, [][]string{
+		{"src", "a+b%2F1", "1", "e1"},
+		{"src", "a+b%2F2", "2", "e2"},
+		{"src", "c", "3", ""},
+	}, rows)
+}
+
+func TestInventoryReportParquet(t *testing.T) {
+	inventoryRecordsPerFile = defaultInventoryRecordsPerFile
+	store := &mockInventoryStore{objects: make(map[string][]byte)}
+	report := NewInventoryReport("src", &proto.InventoryConfiguration{
+		ID:                "weekly",
+		Format:            proto.InventoryFormatParquet,
+		DestinationBucket: "dst",
+		OptionalFields:    []string{proto.InventoryFieldLastModifiedDate, proto.InventoryFieldSize},
+	}, store, time.Now())
+	report.Append([]*inventoryRecord{{Key: "key", Size: 5, LastModified: time.Now()}})
+	require.NoError(t, report.Finish())
+
+	manifestKeys := store.find("/manifest.json")
+	require.Len(t, manifestKeys, 1)
+	require.True(t, strings.HasPrefix(manifestKeys[0], "src/weekly/"))
+	manifest := &InventoryManifest{}
+	require.NoError(t, json.Unmarshal(store.objects[manifestKeys[0]], manifest))
+	require.Equal(t, "message s3.inventory { required binary bucket (UTF8); required binary key (UTF8); "+
+		"required int64 size; required int64 last_modified_date (TIMESTAMP_MILLIS); }", manifest.FileSchema)
+	require.Len(t, manifest.Files, 1)
+
+	data := store.objects[manifest.Files[0].Key]
+	require.Equal(t, parquetMagic, string(data[:4]))
+	require.Equal(t, parquetMagic, string(data[len(data)-4:]))
+	footerSize := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	require.Less(t, footerSize, len(data)-12)
+	// the first page of the first column follows the magic number
+	require.Equal(t, byte(0x15), data[4])
+}
+
+func TestInventoryReportFailed(t *testing.T) {
+	inventoryRecordsPerFile = 1
+	store := &mockInventoryStore{objects: make(map[string][]byte), err: errors.New("put failed")}
+	report := NewInventoryReport("src", &proto.InventoryConfiguration{ID: "id", Format: proto.InventoryFormatCSV}, store, time.Now())
+	report.Append([]*inventoryRecord{{Key: "a"}, {Key: "b"}})
+	require.Error(t, report.Finish())
+	require.Len(t, store.objects, 0)
+}
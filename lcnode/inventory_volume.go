@@ -0,0 +1,172 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package lcnode
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/sdk/data/stream"
+	"github.com/cubefs/cubefs/sdk/master"
+	"github.com/cubefs/cubefs/sdk/meta"
+	"github.com/cubefs/cubefs/util"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	inventoryFileMode = 0o644
+	inventoryDirMode  = inventoryFileMode | os.ModeDir
+)
+
+type inventoryStore interface {
+	put(key string, data []byte) error
+	close() error
+}
+
+// inventoryVolume writes the inventory reports as objects into the destination volume, the ETag of the
+// objects is stored as ObjectNode does. Only the volumes storing the data in data nodes are supported.
+type inventoryVolume struct {
+	name string
+	mw   *meta.MetaWrapper
+	ec   *stream.ExtentClient
+}
+
+func newInventoryVolume(volume string, masters []string, mc *master.MasterClient) (v *inventoryVolume, err error) {
+	var info *proto.SimpleVolView
+	if info, err = mc.AdminAPI().GetVolumeSimpleInfo(volume); err != nil {
+		return
+	}
+	if proto.IsCold(info.VolType) {
+		return nil, fmt.Errorf("inventory destination volume(%v) is cold volume", volume)
+	}
+
+	var mw *meta.MetaWrapper
+	if mw, err = meta.NewMetaWrapper(&meta.MetaConfig{
+		Volume:        volume,
+		Masters:       masters,
+		Authenticate:  false,
+		ValidateOwner: false,
+	}); err != nil {
+		return
+	}
+	var ec *stream.ExtentClient
+	if ec, err = stream.NewExtentClient(&stream.ExtentConfig{
+		Volume:            volume,
+		Masters:           masters,
+		FollowerRead:      true,
+		OnAppendExtentKey: mw.AppendExtentKey,
+		OnSplitExtentKey:  mw.SplitExtentKey,
+		OnGetExtents:      mw.GetExtents,
+		OnTruncate:        mw.Truncate,
+	}); err != nil {
+		_ = mw.Close()
+		return
+	}
+	return &inventoryVolume{name: volume, mw: mw, ec: ec}, nil
+}
+
+func (v *inventoryVolume) makeDirectories(dirs []string) (parentID uint64, err error) {
+	parentID = proto.RootIno
+	for i, dir := range dirs {
+		var (
+			ino  uint64
+			mode uint32
+		)
+		ino, mode, err = v.mw.Lookup_ll(parentID, dir)
+		if err == syscall.ENOENT {
+			var info *proto.InodeInfo
+			info, err = v.mw.Create_ll(parentID, dir, uint32(inventoryDirMode), 0, 0, nil, strings.Join(dirs[:i+1], pathSep), false)
+			if err == syscall.EEXIST {
+				ino, mode, err = v.mw.Lookup_ll(parentID, dir)
+			} else if err == nil {
+				ino, mode = info.Inode, info.Mode
+			}
+		}
+		if err != nil {
+			return
+		}
+		if !os.FileMode(mode).IsDir() {
+			return 0, syscall.ENOTDIR
+		}
+		parentID = ino
+	}
+	return
+}
+
+func (v *inventoryVolume) put(key string, data []byte) (err error) {
+	items := strings.Split(key, pathSep)
+	name := items[len(items)-1]
+	var parentID uint64
+	if parentID, err = v.makeDirectories(items[:len(items)-1]); err != nil {
+		return
+	}
+
+	var info *proto.InodeInfo
+	if info, err = v.mw.InodeCreate_ll(parentID, inventoryFileMode, 0, 0, nil, make([]uint64, 0), key); err != nil {
+		return
+	}
+	ino := info.Inode
+	defer func() {
+		if err != nil {
+			log.LogWarnf("inventoryVolume: unlink temp inode: volume(%v) key(%v) inode(%v) err(%v)", v.name, key, ino, err)
+			_, _ = v.mw.InodeUnlink_ll(ino, key)
+			_ = v.mw.Evict(ino, key)
+		}
+	}()
+
+	if err = v.ec.OpenStream(ino); err != nil {
+		return
+	}
+	for offset := 0; offset < len(data); offset += util.BlockSize {
+		end := offset + util.BlockSize
+		if end > len(data) {
+			end = len(data)
+		}
+		if _, err = v.ec.Write(ino, offset, data[offset:end], 0, nil); err != nil {
+			_ = v.ec.CloseStream(ino)
+			return
+		}
+	}
+	err = v.ec.Flush(ino)
+	if closeErr := v.ec.CloseStream(ino); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return
+	}
+
+	if info, err = v.mw.InodeGet_ll(ino); err != nil {
+		return
+	}
+	sum := md5.Sum(data)
+	etag := hex.EncodeToString(sum[:]) + ":" + strconv.FormatInt(info.ModifyTime.Unix(), 10)
+	if err = v.mw.BatchSetXAttr_ll(ino, map[string]string{xattrKeyOSSETag: etag}); err != nil {
+		return
+	}
+	return v.mw.DentryCreate_ll(parentID, name, ino, inventoryFileMode, key)
+}
+
+func (v *inventoryVolume) close() error {
+	if err := v.ec.Close(); err != nil {
+		log.LogWarnf("inventoryVolume: close extent client fail: volume(%v) err(%v)", v.name, err)
+	}
+	return v.mw.Close()
+}
@@ -41,6 +41,7 @@ type LcScanner struct {
 	lcnode        *LcNode
 	adminTask     *proto.AdminTask
 	rule          *proto.Rule
+	inventory     *InventoryReport
 	dirChan       *unboundedchan.UnboundedChan
 	fileChan      *unboundedchan.UnboundedChan
 	dirRPoll      *routinepool.RoutinePool
@@ -87,6 +88,15 @@ func NewS3Scanner(adminTask *proto.AdminTask, l *LcNode) (*LcScanner, error) {
 		stopC:         make(chan bool),
 	}
 
+	if inv := scanTask.Inventory; inv != nil {
+		var store *inventoryVolume
+		if store, err = newInventoryVolume(inv.DestinationBucket, l.masters, l.mc); err != nil {
+			_ = metaWrapper.Close()
+			return nil, err
+		}
+		scanner.inventory = NewInventoryReport(scanTask.VolName, inv, store, scanner.now)
+	}
+
 	return scanner, nil
 }
 
@@ -320,6 +330,10 @@ func (s *LcScanner) batchHandleFile() {
 
 	var expiredDentries []*proto.ScanDentry
 	inodesInfo := s.mw.BatchInodeGet(inodes)
+	if s.inventory != nil {
+		s.batchHandleInventory(dentries, inodes, inodesInfo)
+		return
+	}
 	for _, info := range inodesInfo {
 		if s.inodeExpired(info, s.rule.Expire) {
 			d := dentries[info.Inode]
@@ -352,6 +366,29 @@ func (s *LcScanner) batchHandleFile() {
 	atomic.AddInt64(&s.currentStat.ExpiredNum, int64(len(expiredDentries)))
 }
 
+func (s *LcScanner) batchHandleInventory(dentries map[uint64]*proto.ScanDentry, inodes []uint64, inodesInfo []*proto.InodeInfo) {
+	xattrs := make(map[uint64]*proto.XAttrInfo, len(inodes))
+	infos, err := s.mw.BatchGetXAttr(inodes, []string{xattrKeyOSSETag, xattrKeyOSSTagging})
+	if err != nil {
+		// the objects are reported without the ETag and tags rather than missing in the report
+		log.LogWarnf("batchHandleInventory BatchGetXAttr err: %v, volume: %v, inodes num: %v", err, s.Volume, len(inodes))
+	}
+	for _, info := range infos {
+		xattrs[info.Inode] = info
+	}
+
+	records := make([]*inventoryRecord, 0, len(inodesInfo))
+	for _, info := range inodesInfo {
+		d := dentries[info.Inode]
+		if d == nil {
+			continue
+		}
+		records = append(records, newInventoryRecord(d.Path, info, xattrs[info.Inode]))
+	}
+	s.inventory.Append(records)
+	log.LogDebugf("batchHandleInventory num: %v, reported num: %v", len(inodesInfo), len(records))
+}
+
 func (s *LcScanner) inodeExpired(inode *proto.InodeInfo, cond *proto.ExpirationConfig) bool {
 	if inode == nil || cond == nil {
 		return false
@@ -515,10 +552,17 @@ func (s *LcScanner) checkScanning() {
 				} else {
 					log.LogInfof("checkScanning completed for task(%v)", s.adminTask)
 					taskCheckTimer.Stop()
-					t := time.Now()
 					response := s.adminTask.Response.(*proto.LcNodeRuleTaskResponse)
-					response.EndTime = &t
 					response.Status = proto.TaskSucceeds
+					if s.inventory != nil {
+						if err := s.inventory.Finish(); err != nil {
+							log.LogErrorf("checkScanning finish inventory report err(%v), task(%v)", err, s.adminTask)
+							response.Status = proto.TaskFailed
+							response.Result = err.Error()
+						}
+					}
+					t := time.Now()
+					response.EndTime = &t
 					response.Done = true
 					response.ID = s.ID
 					response.LcNode = s.lcnode.localServerAddr
@@ -557,5 +601,8 @@ func (s *LcScanner) Stop() {
 	close(s.dirChan.In)
 	close(s.fileChan.In)
 	s.mw.Close()
+	if s.inventory != nil {
+		s.inventory.Close()
+	}
 	log.LogInfof("scanner(%v) stopped", s.ID)
 }
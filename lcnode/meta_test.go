@@ -38,6 +38,10 @@ func (*MockMetaWrapper) BatchInodeGet(inodes []uint64) []*proto.InodeInfo {
 	return nil
 }
 
+func (*MockMetaWrapper) BatchGetXAttr(inodes []uint64, keys []string) ([]*proto.XAttrInfo, error) {
+	return nil, nil
+}
+
 func (*MockMetaWrapper) DeleteWithCond_ll(parentID, cond uint64, name string, isDir bool, fullPath string) (*proto.InodeInfo, error) {
 	return nil, nil
 }
@@ -209,6 +209,20 @@ func (l *LcNode) parseConfig(cfg *config.Config) (err error) {
 	}
 	log.LogInfof("loadConfig: setup config: %v(%v)", configLcNodeTaskCountLimit, lcNodeTaskCountLimit)
 
+	// parse inventoryRecordsPerFile
+	var recordsNum int64
+	recordsStr := cfg.GetString(configInventoryRecordsPerFileStr)
+	if recordsStr != "" {
+		if recordsNum, err = strconv.ParseInt(recordsStr, 10, 64); err != nil {
+			return fmt.Errorf("%v,err:%v", proto.ErrInvalidCfg, err.Error())
+		}
+	}
+	inventoryRecordsPerFile = int(recordsNum)
+	if inventoryRecordsPerFile <= 0 || inventoryRecordsPerFile > maxInventoryRecordsPerFile {
+		inventoryRecordsPerFile = defaultInventoryRecordsPerFile
+	}
+	log.LogInfof("loadConfig: setup config: %v(%v)", configInventoryRecordsPerFileStr, inventoryRecordsPerFile)
+
 	return
 }
 
@@ -1435,6 +1435,7 @@ func (c *client) start() (err error) {
 		OnSplitExtentKey:  mw.SplitExtentKey,
 		OnGetExtents:      mw.GetExtents,
 		OnTruncate:        mw.Truncate,
+		OnModifyInode:     mw.InodeModify,
 		BcacheEnable:      c.enableBcache,
 		OnLoadBcache:      c.bc.Get,
 		OnCacheBcache:     c.bc.Put,
@@ -383,6 +383,10 @@ type updateVolReq struct {
 	followerRead            bool
 	authenticate            bool
 	enablePosixAcl          bool
+	permissionMode          uint8
+	enableDedup             bool
+	enableDefrag            bool
+	enableMirror            bool
 	enableTransaction       proto.TxOpMask
 	txTimeout               int64
 	txConflictRetryNum      int64
@@ -489,6 +493,28 @@ func parseVolUpdateReq(r *http.Request, vol *Vol, req *updateVolReq) (err error)
 		return
 	}
 
+	var permissionMode int
+	if permissionMode, err = extractUintWithDefault(r, permissionModeKey, int(vol.permissionMode)); err != nil {
+		return
+	}
+	req.permissionMode = uint8(permissionMode)
+	if permissionMode > math.MaxUint8 || !proto.IsValidPermissionMode(req.permissionMode) {
+		err = fmt.Errorf("parse [%s] is not valid permission mode [%d]", permissionModeKey, permissionMode)
+		return
+	}
+
+	if req.enableDedup, err = extractBoolWithDefault(r, enableDedupKey, vol.enableDedup); err != nil {
+		return
+	}
+
+	if req.enableDefrag, err = extractBoolWithDefault(r, enableDefragKey, vol.enableDefrag); err != nil {
+		return
+	}
+
+	if req.enableMirror, err = extractBoolWithDefault(r, enableMirrorKey, vol.enableMirror); err != nil {
+		return
+	}
+
 	var txMask proto.TxOpMask
 	if txMask, err = parseTxMask(r, vol.enableTransaction); err != nil {
 		return
@@ -632,6 +632,10 @@ func (m *Server) UidOperate(w http.ResponseWriter, r *http.Request) {
 		uidList []*proto.UidSpaceInfo
 		uidInfo *proto.UidSpaceInfo
 		ok      bool
+
+		gid      uint32
+		userID   string
+		userList []*proto.UidUserInfo
 	)
 	if volName, err = extractName(r); err != nil {
 		sendErrReply(w, r, newErrHTTPReply(err))
@@ -651,7 +655,7 @@ func (m *Server) UidOperate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if op != util.UidLimitList {
+	if op != util.UidLimitList && op != util.UidUserList && op != util.UidUserDel {
 		if uid, err = extractUint32(r, UIDKey); err != nil {
 			err = keyNotFound(UIDKey)
 			sendErrReply(w, r, newErrHTTPReply(err))
@@ -659,6 +663,22 @@ func (m *Server) UidOperate(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// the S3 user is mapped to the uid and gid
+	if op == util.UidUserAdd {
+		if gid, err = extractUint32(r, GIDKey); err != nil {
+			err = keyNotFound(GIDKey)
+			sendErrReply(w, r, newErrHTTPReply(err))
+			return
+		}
+	}
+	if op == util.UidUserAdd || op == util.UidUserDel {
+		if userID = r.FormValue(userKey); userID == "" {
+			err = keyNotFound(userKey)
+			sendErrReply(w, r, newErrHTTPReply(err))
+			return
+		}
+	}
+
 	if op == util.UidAddLimit {
 		if capSize, err = extractPositiveUint64(r, CapacityKey); err != nil {
 			err = keyNotFound(CapacityKey)
@@ -687,6 +707,16 @@ func (m *Server) UidOperate(w http.ResponseWriter, r *http.Request) {
 		ok = vol.uidSpaceManager.pushUidCmd(cmd)
 	case util.UidLimitList:
 		uidList = vol.uidSpaceManager.listAll()
+	case util.UidUserAdd, util.UidUserDel:
+		cmd := &UidCmd{
+			op:     op,
+			uid:    uid,
+			gid:    gid,
+			userID: userID,
+		}
+		ok = vol.uidSpaceManager.pushUidCmd(cmd)
+	case util.UidUserList:
+		userList = vol.uidSpaceManager.listUsers()
 	default:
 		// do nothing
 	}
@@ -694,6 +724,7 @@ func (m *Server) UidOperate(w http.ResponseWriter, r *http.Request) {
 	rsp := &proto.UidSpaceRsp{
 		OK:          ok,
 		UidSpaceArr: uidList,
+		UidUserArr:  userList,
 	}
 
 	_ = sendOkReply(w, r, newSuccessHTTPReply(rsp))
@@ -1611,6 +1642,7 @@ func (m *Server) addDataReplica(w http.ResponseWriter, r *http.Request) {
 		addr        string
 		dp          *DataPartition
 		partitionID uint64
+		peerType    uint8
 		err         error
 	)
 	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminAddDataReplica))
@@ -1623,6 +1655,11 @@ func (m *Server) addDataReplica(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if peerType, err = parsePeerType(r, true); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
 	if dp, err = m.cluster.getDataPartitionByID(partitionID); err != nil {
 		sendErrReply(w, r, newErrHTTPReply(proto.ErrDataPartitionNotExists))
 		return
@@ -1644,10 +1681,16 @@ func (m *Server) addDataReplica(w http.ResponseWriter, r *http.Request) {
 		break
 	}
 
-	if err = m.cluster.addDataReplica(dp, addr, false); err != nil {
+	if err = m.cluster.addDataReplica(dp, addr, false, peerType); err != nil {
 		sendErrReply(w, r, newErrHTTPReply(err))
 		return
 	}
+	// witness holds no extent, nothing to repair
+	if peerType == proto.PeerWitness {
+		msg = fmt.Sprintf("data partitionID :%v  add witness [%v] successfully", partitionID, addr)
+		sendOkReply(w, r, newSuccessHTTPReply(msg))
+		return
+	}
 	// for disk manager to check status for new replica
 	dp.DecommissionDstAddr = addr
 	dp.DecommissionType = ManualAddReplica
@@ -1662,6 +1705,37 @@ func (m *Server) addDataReplica(w http.ResponseWriter, r *http.Request) {
 	sendOkReply(w, r, newSuccessHTTPReply(msg))
 }
 
+func (m *Server) promoteDataReplica(w http.ResponseWriter, r *http.Request) {
+	var (
+		msg         string
+		addr        string
+		dp          *DataPartition
+		partitionID uint64
+		err         error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminPromoteDataReplica))
+	defer func() {
+		doStatAndMetric(proto.AdminPromoteDataReplica, metric, err, nil)
+	}()
+
+	if partitionID, addr, err = parseRequestToAddDataReplica(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	if dp, err = m.cluster.getDataPartitionByID(partitionID); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(proto.ErrDataPartitionNotExists))
+		return
+	}
+
+	if err = m.cluster.promoteDataReplica(dp, addr); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	msg = fmt.Sprintf("data partitionID :%v  promote replica [%v] successfully", partitionID, addr)
+	sendOkReply(w, r, newSuccessHTTPReply(msg))
+}
+
 func (m *Server) deleteDataReplica(w http.ResponseWriter, r *http.Request) {
 	var (
 		msg         string
@@ -1724,6 +1798,7 @@ func (m *Server) addMetaReplica(w http.ResponseWriter, r *http.Request) {
 		addr        string
 		mp          *MetaPartition
 		partitionID uint64
+		peerType    uint8
 		err         error
 	)
 	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminAddMetaReplica))
@@ -1736,12 +1811,17 @@ func (m *Server) addMetaReplica(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if peerType, err = parsePeerType(r, false); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
 	if mp, err = m.cluster.getMetaPartitionByID(partitionID); err != nil {
 		sendErrReply(w, r, newErrHTTPReply(proto.ErrMetaPartitionNotExists))
 		return
 	}
 
-	if err = m.cluster.addMetaReplica(mp, addr); err != nil {
+	if err = m.cluster.addMetaReplica(mp, addr, peerType); err != nil {
 		sendErrReply(w, r, newErrHTTPReply(err))
 		return
 	}
@@ -1751,6 +1831,37 @@ func (m *Server) addMetaReplica(w http.ResponseWriter, r *http.Request) {
 	sendOkReply(w, r, newSuccessHTTPReply(msg))
 }
 
+func (m *Server) promoteMetaReplica(w http.ResponseWriter, r *http.Request) {
+	var (
+		msg         string
+		addr        string
+		mp          *MetaPartition
+		partitionID uint64
+		err         error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminPromoteMetaReplica))
+	defer func() {
+		doStatAndMetric(proto.AdminPromoteMetaReplica, metric, err, nil)
+	}()
+
+	if partitionID, addr, err = parseRequestToAddMetaReplica(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+
+	if mp, err = m.cluster.getMetaPartitionByID(partitionID); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(proto.ErrMetaPartitionNotExists))
+		return
+	}
+
+	if err = m.cluster.promoteMetaReplica(mp, addr); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	msg = fmt.Sprintf("meta partitionID :%v  promote replica [%v] successfully", partitionID, addr)
+	sendOkReply(w, r, newSuccessHTTPReply(msg))
+}
+
 func (m *Server) deleteMetaReplica(w http.ResponseWriter, r *http.Request) {
 	var (
 		msg         string
@@ -2320,6 +2431,10 @@ func (m *Server) updateVol(w http.ResponseWriter, r *http.Request) {
 	newArgs.dpSelectorName = req.dpSelectorName
 	newArgs.dpSelectorParm = req.dpSelectorParm
 	newArgs.enablePosixAcl = req.enablePosixAcl
+	newArgs.permissionMode = req.permissionMode
+	newArgs.enableDedup = req.enableDedup
+	newArgs.enableDefrag = req.enableDefrag
+	newArgs.enableMirror = req.enableMirror
 	newArgs.enableTransaction = req.enableTransaction
 	newArgs.txTimeout = req.txTimeout
 	newArgs.txConflictRetryNum = req.txConflictRetryNum
@@ -2680,6 +2795,10 @@ func newSimpleView(vol *Vol) (view *proto.SimpleVolView) {
 		Capacity:                vol.Capacity,
 		FollowerRead:            vol.FollowerRead,
 		EnablePosixAcl:          vol.enablePosixAcl,
+		PermissionMode:          vol.permissionMode,
+		EnableDedup:             vol.enableDedup,
+		EnableDefrag:            vol.enableDefrag,
+		EnableMirror:            vol.enableMirror,
 		EnableQuota:             vol.enableQuota,
 		EnableTransactionV1:     proto.GetMaskString(vol.enableTransaction),
 		EnableTransaction:       "off",
@@ -2729,6 +2848,9 @@ func newSimpleView(vol *Vol) (view *proto.SimpleVolView) {
 			Limited: uid.Limited,
 		})
 	}
+	for _, user := range vol.uidSpaceManager.userInfo {
+		view.UidUsers = append(view.UidUsers, user)
+	}
 	return
 }
 
@@ -4857,6 +4979,30 @@ func pareseBoolWithDefault(r *http.Request, key string, old bool) (bool, error)
 	return newVal, nil
 }
 
+// parsePeerType parses the type of the replica to add, a learner does not vote until promoted.
+func parsePeerType(r *http.Request, witnessAllowed bool) (peerType uint8, err error) {
+	var learner, witness bool
+	if learner, err = pareseBoolWithDefault(r, learnerKey, false); err != nil {
+		return
+	}
+	if witness, err = pareseBoolWithDefault(r, witnessKey, false); err != nil {
+		return
+	}
+	switch {
+	case learner && witness:
+		err = fmt.Errorf("replica can not be both learner and witness")
+	case witness && !witnessAllowed:
+		err = fmt.Errorf("witness is not supported")
+	case learner:
+		peerType = proto.PeerLearner
+	case witness:
+		peerType = proto.PeerWitness
+	default:
+		peerType = proto.PeerNormal
+	}
+	return
+}
+
 func parseRaftForce(r *http.Request) (bool, error) {
 	return pareseBoolWithDefault(r, raftForceDelKey, false)
 }
@@ -6602,6 +6748,74 @@ func (m *Server) DelBucketLifecycle(w http.ResponseWriter, r *http.Request) {
 	sendOkReply(w, r, newSuccessHTTPReply(msg))
 }
 
+func (m *Server) SetBucketInventory(w http.ResponseWriter, r *http.Request) {
+	var (
+		bytes []byte
+		err   error
+	)
+	if bytes, err = io.ReadAll(r.Body); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	req := proto.InventoryConfigurations{}
+	if err = json.Unmarshal(bytes, &req); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if _, err = m.cluster.getVol(req.VolName); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeVolNotExists, Msg: err.Error()})
+		return
+	}
+	if err = m.cluster.SetBucketInventory(&req); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply("PutBucketInventoryConfiguration successful"))
+}
+
+// GetBucketInventory replies the empty configurations if the volume has no inventory configured.
+func (m *Server) GetBucketInventory(w http.ResponseWriter, r *http.Request) {
+	var (
+		err     error
+		name    string
+		invConf *proto.InventoryConfigurations
+	)
+	if name, err = parseAndExtractName(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if _, err = m.cluster.getVol(name); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(proto.ErrVolNotExists))
+		return
+	}
+	if invConf = m.cluster.GetBucketInventory(name); invConf == nil {
+		invConf = &proto.InventoryConfigurations{VolName: name}
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(invConf))
+}
+
+func (m *Server) DelBucketInventory(w http.ResponseWriter, r *http.Request) {
+	var (
+		err  error
+		name string
+	)
+	if name, err = parseAndExtractName(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if _, err = m.cluster.getVol(name); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(proto.ErrVolNotExists))
+		return
+	}
+	if err = m.cluster.DelBucketInventory(name); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	msg := fmt.Sprintf("delete vol[%v] inventory successfully", name)
+	log.LogWarn(msg)
+	sendOkReply(w, r, newSuccessHTTPReply(msg))
+}
+
 func (m *Server) lcnodeInfo(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
 		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
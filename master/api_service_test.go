@@ -1318,6 +1318,57 @@ func TestListUsersOfVol(t *testing.T) {
 	process(reqURL, t)
 }
 
+func TestRole(t *testing.T) {
+	roleName := "test_role"
+	trustPolicy := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":"cfs"},"Action":"sts:AssumeRole"}]}`
+	param := &proto.RoleCreateParam{
+		RoleName: roleName, UserID: "cfs", TrustPolicy: trustPolicy,
+		PermissionPolicy: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:*","Resource":"*"}]}`,
+	}
+	data, err := json.Marshal(param)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	post(fmt.Sprintf("%v%v", hostAddr, proto.RoleCreate), data, t)
+	roleInfo, err := server.user.getRoleInfo(roleName)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if roleInfo.MaxSessionDuration != defaultRoleMaxSessionDuration {
+		t.Errorf("expect max session duration[%v], real[%v]", defaultRoleMaxSessionDuration, roleInfo.MaxSessionDuration)
+		return
+	}
+	if _, err = server.user.createRole(param); err != proto.ErrDuplicateRole {
+		t.Errorf("expect err ErrDuplicateRole, but err is %v", err)
+		return
+	}
+
+	update := &proto.RoleUpdateParam{RoleName: roleName, MaxSessionDuration: maxRoleMaxSessionDuration}
+	if data, err = json.Marshal(update); err != nil {
+		t.Error(err)
+		return
+	}
+	post(fmt.Sprintf("%v%v", hostAddr, proto.RoleUpdate), data, t)
+	if roleInfo, err = server.user.getRoleInfo(roleName); err != nil {
+		t.Error(err)
+		return
+	}
+	if roleInfo.MaxSessionDuration != maxRoleMaxSessionDuration || roleInfo.TrustPolicy != trustPolicy {
+		t.Errorf("unexpected role after update: %v", roleInfo)
+		return
+	}
+
+	process(fmt.Sprintf("%v%v?role=%v", hostAddr, proto.RoleGetInfo, roleName), t)
+	process(fmt.Sprintf("%v%v?keywords=%v", hostAddr, proto.RoleList, "test"), t)
+	process(fmt.Sprintf("%v%v?role=%v", hostAddr, proto.RoleDelete, roleName), t)
+	if _, err = server.user.getRoleInfo(roleName); err != proto.ErrRoleNotExists {
+		t.Errorf("expect err ErrRoleNotExists, but err is %v", err)
+		return
+	}
+}
+
 func TestListNodeSets(t *testing.T) {
 	reqURL := fmt.Sprintf("%v%v", hostAddr, proto.GetAllNodeSets)
 	process(reqURL, t)
@@ -317,6 +317,135 @@ func (m *Server) getUsersOfVol(w http.ResponseWriter, r *http.Request) {
 	sendOkReply(w, r, newSuccessHTTPReply(users))
 }
 
+func (m *Server) createRole(w http.ResponseWriter, r *http.Request) {
+	var (
+		roleInfo *proto.RoleInfo
+		err      error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.RoleCreate))
+	defer func() {
+		doStatAndMetric(proto.RoleCreate, metric, err, nil)
+	}()
+
+	var bytes []byte
+	if bytes, err = io.ReadAll(r.Body); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	param := proto.RoleCreateParam{}
+	if err = json.Unmarshal(bytes, &param); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if roleInfo, err = m.user.createRole(&param); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	_ = sendOkReply(w, r, newSuccessHTTPReply(roleInfo))
+}
+
+func (m *Server) deleteRole(w http.ResponseWriter, r *http.Request) {
+	var (
+		roleName string
+		err      error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.RoleDelete))
+	defer func() {
+		doStatAndMetric(proto.RoleDelete, metric, err, nil)
+	}()
+
+	if roleName, err = parseRole(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if err = m.user.deleteRole(roleName); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	msg := fmt.Sprintf("delete role[%v] successfully", roleName)
+	log.LogWarn(msg)
+	sendOkReply(w, r, newSuccessHTTPReply(msg))
+}
+
+func (m *Server) updateRole(w http.ResponseWriter, r *http.Request) {
+	var (
+		roleInfo *proto.RoleInfo
+		err      error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.RoleUpdate))
+	defer func() {
+		doStatAndMetric(proto.RoleUpdate, metric, err, nil)
+	}()
+
+	var bytes []byte
+	if bytes, err = io.ReadAll(r.Body); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	param := proto.RoleUpdateParam{}
+	if err = json.Unmarshal(bytes, &param); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if roleInfo, err = m.user.updateRole(&param); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	_ = sendOkReply(w, r, newSuccessHTTPReply(roleInfo))
+}
+
+func (m *Server) getRoleInfo(w http.ResponseWriter, r *http.Request) {
+	var (
+		roleName string
+		roleInfo *proto.RoleInfo
+		err      error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.RoleGetInfo))
+	defer func() {
+		doStatAndMetric(proto.RoleGetInfo, metric, err, nil)
+	}()
+
+	if roleName, err = parseRole(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if roleInfo, err = m.user.getRoleInfo(roleName); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	sendOkReply(w, r, newSuccessHTTPReply(roleInfo))
+}
+
+func (m *Server) getAllRoles(w http.ResponseWriter, r *http.Request) {
+	var (
+		keywords string
+		roles    []*proto.RoleInfo
+		err      error
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.RoleList))
+	defer func() {
+		doStatAndMetric(proto.RoleList, metric, err, nil)
+	}()
+
+	if keywords, err = parseKeywords(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	roles = m.user.getAllRoleInfo(keywords)
+	sendOkReply(w, r, newSuccessHTTPReply(roles))
+}
+
+func parseRole(r *http.Request) (roleName string, err error) {
+	if err = r.ParseForm(); err != nil {
+		return
+	}
+	if roleName = r.FormValue(roleKey); roleName == "" {
+		err = keyNotFound(roleKey)
+		return
+	}
+	return
+}
+
 func parseUser(r *http.Request) (userID string, err error) {
 	if err = r.ParseForm(); err != nil {
 		return
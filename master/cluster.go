@@ -2063,7 +2063,9 @@ func (c *Cluster) decommissionSingleDp(dp *DataPartition, newAddr, offlineAddr s
 	}()
 	// 1. add new replica first
 	if dp.GetSpecialReplicaDecommissionStep() == SpecialDecommissionEnter {
-		if err = c.addDataReplica(dp, newAddr, false); err != nil {
+		// the new replica joins as a learner and is promoted after the repair, so it never counts in
+		// the quorum of the single or two replicas partition before it catches up
+		if err = c.addDataReplica(dp, newAddr, false, proto.PeerLearner); err != nil {
 			err = fmt.Errorf("action[decommissionSingleDp] dp %v addDataReplica %v fail err %v", dp.PartitionID, newAddr, err)
 			goto ERR
 		}
@@ -2170,6 +2172,10 @@ func (c *Cluster) decommissionSingleDp(dp *DataPartition, newAddr, offlineAddr s
 			err = fmt.Errorf("action[decommissionSingleDp] dp %v wait addDataReplica result addr %v master leader changed", dp.PartitionID, newAddr)
 			goto ERR
 		}
+		if err = c.promoteDataReplica(dp, newAddr); err != nil {
+			err = fmt.Errorf("action[decommissionSingleDp] dp %v promote learner %v err %v", dp.PartitionID, newAddr, err)
+			goto ERR
+		}
 		if dataNode, err = c.dataNode(newAddr); err != nil {
 			err = fmt.Errorf("action[decommissionSingleDp] dp %v get offlineAddr %v err %v", dp.PartitionID, newAddr, err)
 			goto ERR
@@ -2348,10 +2354,18 @@ func (c *Cluster) migrateDataPartition(srcAddr, targetAddr string, dp *DataParti
 			goto errHandler
 		}
 	} else {
-		if err = c.removeDataReplica(dp, srcAddr, false, raftForce); err != nil {
+		// the new replica catches up as a learner before the source leaves, so the quorum is never weakened
+		if err = c.addDataReplica(dp, newAddr, false, proto.PeerLearner); err != nil {
+			goto errHandler
+		}
+		if err = c.waitPromoteDataReplica(dp, newAddr); err != nil {
+			if rollbackErr := c.removeDataReplica(dp, newAddr, false, false); rollbackErr != nil {
+				log.LogErrorf("action[migrateDataPartition] vol[%v],dp[%v] remove learner[%v] failed,err[%v]",
+					dp.VolName, dp.PartitionID, newAddr, rollbackErr)
+			}
 			goto errHandler
 		}
-		if err = c.addDataReplica(dp, newAddr, false); err != nil {
+		if err = c.removeDataReplica(dp, srcAddr, false, raftForce); err != nil {
 			goto errHandler
 		}
 
@@ -2438,7 +2452,7 @@ func (c *Cluster) validateDecommissionDataPartition(dp *DataPartition, offlineAd
 	return
 }
 
-func (c *Cluster) addDataReplica(dp *DataPartition, addr string, ignoreDecommissionDisk bool) (err error) {
+func (c *Cluster) addDataReplica(dp *DataPartition, addr string, ignoreDecommissionDisk bool, peerType uint8) (err error) {
 	defer func() {
 		if err != nil {
 			log.LogErrorf("action[addDataReplica],vol[%v],dp %v ,err[%v]", dp.VolName, dp.PartitionID, err)
@@ -2457,11 +2471,16 @@ func (c *Cluster) addDataReplica(dp *DataPartition, addr string, ignoreDecommiss
 		return
 	}
 
-	addPeer := proto.Peer{ID: dataNode.ID, Addr: addr}
+	addPeer := proto.Peer{ID: dataNode.ID, Addr: addr, Type: peerType}
 
 	if !proto.IsNormalDp(dp.PartitionType) {
 		return fmt.Errorf("action[addDataReplica] [%d] is not normal dp, not support add or delete replica", dp.PartitionID)
 	}
+	if addPeer.IsWitness() {
+		if err = dp.canAddWitness(); err != nil {
+			return
+		}
+	}
 
 	log.LogInfof("action[addDataReplica] dp %v dst addr %v try add raft member, node id %v", dp.PartitionID, addr, dataNode.ID)
 	if err = c.addDataPartitionRaftMember(dp, addPeer); err != nil {
@@ -2479,6 +2498,58 @@ func (c *Cluster) addDataReplica(dp *DataPartition, addr string, ignoreDecommiss
 	return
 }
 
+// promoteDataReplica turns the learner on addr into a voter, the leader rejects it until the learner catches up.
+func (c *Cluster) promoteDataReplica(dp *DataPartition, addr string) (err error) {
+	defer func() {
+		if err != nil {
+			log.LogErrorf("action[promoteDataReplica] vol[%v],dp %v addr %v,err[%v]", dp.VolName, dp.PartitionID, addr, err)
+		}
+	}()
+	dp.RLock()
+	peer, ok := dp.getPeer(addr)
+	leaderAddr := dp.getLeaderAddr()
+	dp.RUnlock()
+	if !ok {
+		return fmt.Errorf("data partition[%v] has no replica[%v]", dp.PartitionID, addr)
+	}
+	if !peer.IsLearner() {
+		return
+	}
+	if leaderAddr == "" {
+		return fmt.Errorf("data partition[%v] has no leader", dp.PartitionID)
+	}
+	leaderDataNode, err := c.dataNode(leaderAddr)
+	if err != nil {
+		return
+	}
+	task := dp.createTaskToPromoteLearner(peer, leaderAddr)
+	if _, err = leaderDataNode.TaskManager.syncSendAdminTask(task); err != nil {
+		return
+	}
+
+	dp.Lock()
+	defer dp.Unlock()
+	newPeers := make([]proto.Peer, 0, len(dp.Peers))
+	for _, p := range dp.Peers {
+		if p.ID == peer.ID && p.Addr == peer.Addr {
+			p.Type = proto.PeerNormal
+		}
+		newPeers = append(newPeers, p)
+	}
+	return dp.update("promoteDataReplica", dp.VolName, newPeers, dp.Hosts, c)
+}
+
+// waitPromoteDataReplica retries the promotion until the learner catches up the leader.
+func (c *Cluster) waitPromoteDataReplica(dp *DataPartition, addr string) (err error) {
+	for i := 0; i < defaultLearnerPromoteRetryTimes; i++ {
+		if err = c.promoteDataReplica(dp, addr); err == nil {
+			return
+		}
+		time.Sleep(retrySendSyncTaskInternal)
+	}
+	return
+}
+
 // update datanode size with to replica size
 func (c *Cluster) updateDataNodeSize(addr string, dp *DataPartition) error {
 	if len(dp.Replicas) == 0 {
@@ -2574,7 +2645,10 @@ func (c *Cluster) addDataPartitionRaftMember(dp *DataPartition, addPeer proto.Pe
 	oldPeers := make([]proto.Peer, len(dp.Peers))
 	copy(oldPeers, dp.Peers)
 
-	dp.Hosts = append(dp.Hosts, addPeer.Addr)
+	// a witness only keeps the raft log, clients and repair must not see it
+	if !addPeer.IsWitness() {
+		dp.Hosts = append(dp.Hosts, addPeer.Addr)
+	}
 	dp.Peers = append(dp.Peers, addPeer)
 
 	dp.Unlock()
@@ -2636,11 +2710,19 @@ func (c *Cluster) createDataReplica(dp *DataPartition, addPeer proto.Peer, ignor
 	copy(peers, dp.Peers)
 	dp.RUnlock()
 
-	diskPath, err := c.syncCreateDataPartitionToDataNode(addPeer.Addr, vol.dataPartitionSize,
+	// a witness keeps the raft log only, the datanode creates no extent store for it
+	size := vol.dataPartitionSize
+	if addPeer.IsWitness() {
+		size = 0
+	}
+	diskPath, err := c.syncCreateDataPartitionToDataNode(addPeer.Addr, size,
 		dp, peers, hosts, proto.DecommissionedCreateDataPartition, dp.PartitionType, true, ignoreDecommissionDisk)
 	if err != nil {
 		return
 	}
+	if addPeer.IsWitness() {
+		return
+	}
 
 	dp.Lock()
 	defer dp.Unlock()
@@ -2669,8 +2751,8 @@ func (c *Cluster) removeDataReplica(dp *DataPartition, addr string, validate boo
 		return
 	}
 	log.LogInfof("action[removeDataReplica]  dp %v try remove replica  addr [%v]", dp.PartitionID, addr)
-	// validate be set true only in api call
-	if validate && !raftForceDel {
+	// validate be set true only in api call, a witness holds no data and can always be removed
+	if validate && !raftForceDel && !dp.isWitness(addr) {
 		if err = c.validateDecommissionDataPartition(dp, addr); err != nil {
 			return
 		}
@@ -4672,6 +4754,7 @@ type LcNodeStatInfo struct {
 type LcNodeInfoResponse struct {
 	RegisterInfos      []*LcNodeStatInfo
 	LcConfigurations   map[string]*proto.LcConfiguration
+	InventoryConfs     map[string]*proto.InventoryConfigurations
 	LcRuleTaskStatus   lcRuleTaskStatus
 	LcNodeStatus       lcNodeStatus
 	SnapshotVerStatus  lcSnapshotVerStatus
@@ -4698,6 +4781,16 @@ func (c *Cluster) getAllLcNodeInfo() (rsp *LcNodeInfoResponse, err error) {
 		return
 	}
 
+	c.lcMgr.RLock()
+	if b, err = json.Marshal(c.lcMgr.inventoryConfs); err != nil {
+		c.lcMgr.RUnlock()
+		return
+	}
+	c.lcMgr.RUnlock()
+	if err = json.Unmarshal(b, &rsp.InventoryConfs); err != nil {
+		return
+	}
+
 	c.lcMgr.lcRuleTaskStatus.RLock()
 	if b, err = json.Marshal(c.lcMgr.lcRuleTaskStatus); err != nil {
 		c.lcMgr.lcRuleTaskStatus.RUnlock()
@@ -4882,6 +4975,48 @@ func (c *Cluster) DelBucketLifecycle(VolName string) {
 	log.LogInfof("action[DelS3BucketLifecycle],clusterID[%v] vol:%v", c.Name, VolName)
 }
 
+func (c *Cluster) SetBucketInventory(req *proto.InventoryConfigurations) (err error) {
+	invConf := &proto.InventoryConfigurations{
+		VolName:        req.VolName,
+		Configurations: req.Configurations,
+	}
+	if c.lcMgr.GetS3BucketInventory(req.VolName) != nil {
+		err = c.syncUpdateInventoryConf(invConf)
+	} else {
+		err = c.syncAddInventoryConf(invConf)
+	}
+	if err != nil {
+		err = fmt.Errorf("action[SetBucketInventory],clusterID[%v] vol:%v err:%v ", c.Name, invConf.VolName, err.Error())
+		log.LogError(errors.Stack(err))
+		Warn(c.Name, err.Error())
+		return proto.ErrPersistenceByRaft
+	}
+	c.lcMgr.SetS3BucketInventory(invConf)
+	log.LogInfof("action[SetBucketInventory],clusterID[%v] vol:%v", c.Name, invConf.VolName)
+	return
+}
+
+func (c *Cluster) GetBucketInventory(VolName string) (invConf *proto.InventoryConfigurations) {
+	invConf = c.lcMgr.GetS3BucketInventory(VolName)
+	log.LogInfof("action[GetBucketInventory],clusterID[%v] vol:%v", c.Name, VolName)
+	return
+}
+
+func (c *Cluster) DelBucketInventory(VolName string) (err error) {
+	invConf := &proto.InventoryConfigurations{
+		VolName: VolName,
+	}
+	if err = c.syncDeleteInventoryConf(invConf); err != nil {
+		err = fmt.Errorf("action[DelBucketInventory],clusterID[%v] vol:%v err:%v ", c.Name, VolName, err.Error())
+		log.LogError(errors.Stack(err))
+		Warn(c.Name, err.Error())
+		return proto.ErrPersistenceByRaft
+	}
+	c.lcMgr.DelS3BucketInventory(VolName)
+	log.LogInfof("action[DelBucketInventory],clusterID[%v] vol:%v", c.Name, VolName)
+	return
+}
+
 func (c *Cluster) addDecommissionDiskToNodeset(dd *DecommissionDisk) (err error) {
 	var (
 		node *DataNode
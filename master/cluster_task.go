@@ -167,11 +167,20 @@ func (c *Cluster) migrateMetaPartition(srcAddr, targetAddr string, mp *MetaParti
 		}
 	}
 
-	if err = c.deleteMetaReplica(mp, srcAddr, false, false); err != nil {
+	// the new replica catches up as a learner before the source leaves, so the quorum is never weakened
+	if err = c.addMetaReplica(mp, newPeers[0].Addr, proto.PeerLearner); err != nil {
+		goto errHandler
+	}
+
+	if err = c.waitPromoteMetaReplica(mp, newPeers[0].Addr); err != nil {
+		if rollbackErr := c.deleteMetaReplica(mp, newPeers[0].Addr, false, false); rollbackErr != nil {
+			log.LogErrorf("action[migrateMetaPartition] vol[%v],mp[%v] remove learner[%v] failed,err[%v]",
+				mp.volName, mp.PartitionID, newPeers[0].Addr, rollbackErr)
+		}
 		goto errHandler
 	}
 
-	if err = c.addMetaReplica(mp, newPeers[0].Addr); err != nil {
+	if err = c.deleteMetaReplica(mp, srcAddr, false, false); err != nil {
 		goto errHandler
 	}
 
@@ -482,7 +491,7 @@ func (c *Cluster) updateMetaPartitionOfflinePeerIDWithLock(mp *MetaPartition, pe
 	return
 }
 
-func (c *Cluster) addMetaReplica(partition *MetaPartition, addr string) (err error) {
+func (c *Cluster) addMetaReplica(partition *MetaPartition, addr string, peerType uint8) (err error) {
 	defer func() {
 		if err != nil {
 			log.LogErrorf("action[addMetaReplica],vol[%v],data partition[%v],err[%v]", partition.volName, partition.PartitionID, err)
@@ -498,7 +507,11 @@ func (c *Cluster) addMetaReplica(partition *MetaPartition, addr string) (err err
 	if err != nil {
 		return
 	}
-	addPeer := proto.Peer{ID: metaNode.ID, Addr: addr}
+	if peerType == proto.PeerWitness {
+		err = fmt.Errorf("vol[%v],mp[%v] witness is not supported by meta partition", partition.volName, partition.PartitionID)
+		return
+	}
+	addPeer := proto.Peer{ID: metaNode.ID, Addr: addr, Type: peerType}
 	if err = c.addMetaPartitionRaftMember(partition, addPeer); err != nil {
 		return
 	}
@@ -516,6 +529,58 @@ func (c *Cluster) addMetaReplica(partition *MetaPartition, addr string) (err err
 	return
 }
 
+// promoteMetaReplica turns the learner on addr into a voter, the leader rejects it until the learner catches up.
+func (c *Cluster) promoteMetaReplica(partition *MetaPartition, addr string) (err error) {
+	defer func() {
+		if err != nil {
+			log.LogErrorf("action[promoteMetaReplica],vol[%v],mp[%v],addr[%v],err[%v]", partition.volName, partition.PartitionID, addr, err)
+		}
+	}()
+	partition.RLock()
+	peer, ok := partition.getPeer(addr)
+	leaderMr, err := partition.getMetaReplicaLeader()
+	partition.RUnlock()
+	if !ok {
+		return fmt.Errorf("vol[%v],mp[%v] has no replica[%v]", partition.volName, partition.PartitionID, addr)
+	}
+	if !peer.IsLearner() {
+		return nil
+	}
+	if err != nil {
+		return
+	}
+	leaderMetaNode, err := c.metaNode(leaderMr.Addr)
+	if err != nil {
+		return
+	}
+	t := partition.createTaskToPromoteLearner(peer, leaderMr.Addr)
+	if _, err = leaderMetaNode.Sender.syncSendAdminTask(t); err != nil {
+		return
+	}
+
+	partition.Lock()
+	defer partition.Unlock()
+	newPeers := make([]proto.Peer, 0, len(partition.Peers))
+	for _, p := range partition.Peers {
+		if p.ID == peer.ID && p.Addr == peer.Addr {
+			p.Type = proto.PeerNormal
+		}
+		newPeers = append(newPeers, p)
+	}
+	return partition.persistToRocksDB("promoteMetaReplica", partition.volName, partition.Hosts, newPeers, c)
+}
+
+// waitPromoteMetaReplica retries the promotion until the learner catches up the leader.
+func (c *Cluster) waitPromoteMetaReplica(partition *MetaPartition, addr string) (err error) {
+	for i := 0; i < defaultLearnerPromoteRetryTimes; i++ {
+		if err = c.promoteMetaReplica(partition, addr); err == nil {
+			return
+		}
+		time.Sleep(retrySendSyncTaskInternal)
+	}
+	return
+}
+
 func (c *Cluster) createMetaReplica(partition *MetaPartition, addPeer proto.Peer) (err error) {
 	task, err := partition.createTaskToCreateReplica(addPeer.Addr)
 	if err != nil {
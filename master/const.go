@@ -73,6 +73,7 @@ const (
 	crossZoneKey               = "crossZone"
 	normalZonesFirstKey        = "normalZonesFirst"
 	userKey                    = "user"
+	roleKey                    = "role"
 	nodeHostsKey               = "hosts"
 	nodeDeleteBatchCountKey    = "batchCount"
 	nodeMarkDeleteRateKey      = "markDeleteRate"
@@ -95,6 +96,10 @@ const (
 	forceKey                   = "force"
 	raftForceDelKey            = "raftForceDel"
 	enablePosixAclKey          = "enablePosixAcl"
+	permissionModeKey          = "permissionMode"
+	enableDedupKey             = "enableDedup"
+	enableDefragKey            = "enableDefrag"
+	enableMirrorKey            = "enableMirror"
 	enableTxMaskKey            = "enableTxMask"
 	txTimeoutKey               = "txTimeout"
 	txConflictRetryNumKey      = "txConflictRetryNum"
@@ -120,6 +125,7 @@ const (
 	IPKey                      = "ip"
 	OperateKey                 = "op"
 	UIDKey                     = "uid"
+	GIDKey                     = "gid"
 	CapacityKey                = "capacity"
 	configKey                  = "config"
 	MaxFilesKey                = "maxFiles"
@@ -141,6 +147,8 @@ const (
 	decommissionTypeKey        = "decommissionType"
 	autoDpMetaRepairKey        = "autoDpMetaRepair"
 	dpTimeoutKey               = "dpTimeout"
+	learnerKey                 = "learner"
+	witnessKey                 = "witness"
 )
 
 const (
@@ -197,6 +205,8 @@ const (
 	EmptyCrcValue                         uint32 = 4045511210
 	DefaultZoneName                              = proto.DefaultZoneName
 	retrySendSyncTaskInternal                    = 3 * time.Second
+	defaultLearnerPromoteMaxLag           uint64 = 1000
+	defaultLearnerPromoteRetryTimes              = 100
 	defaultRangeOfCountDifferencesAllowed        = 50
 	defaultMinusOfMaxInodeID                     = 1000
 	defaultNodeSetGrpBatchCnt                    = 3
@@ -298,6 +308,14 @@ const (
 
 	opSyncS3QosSet    uint32 = 0x60
 	opSyncS3QosDelete uint32 = 0x61
+
+	opSyncAddRoleInfo    uint32 = 0x62
+	opSyncDeleteRoleInfo uint32 = 0x63
+	opSyncUpdateRoleInfo uint32 = 0x64
+
+	opSyncAddInventoryConf    uint32 = 0x65
+	opSyncDeleteInventoryConf uint32 = 0x66
+	opSyncUpdateInventoryConf uint32 = 0x67
 )
 
 const (
@@ -316,6 +334,7 @@ const (
 	domainAcronym          = "zoneDomain"
 	apiLimiterAcronym      = "al"
 	lcConfigurationAcronym = "lc"
+	inventoryConfAcronym   = "inventory"
 	S3QoS                  = "s3qos"
 	maxDataPartitionIDKey  = keySeparator + "max_dp_id"
 	maxMetaPartitionIDKey  = keySeparator + "max_mp_id"
@@ -340,16 +359,19 @@ const (
 
 	akAcronym        = "ak"
 	userAcronym      = "user"
+	roleAcronym      = "role"
 	volUserAcronym   = "voluser"
 	volNameAcronym   = "volname"
 	akPrefix         = keySeparator + akAcronym + keySeparator
 	userPrefix       = keySeparator + userAcronym + keySeparator
+	rolePrefix       = keySeparator + roleAcronym + keySeparator
 	volUserPrefix    = keySeparator + volUserAcronym + keySeparator
 	volWarnUsedRatio = 0.9
 	volCachePrefix   = keySeparator + volNameAcronym + keySeparator
 	quotaPrefix      = keySeparator + "quota" + keySeparator
 	lcNodePrefix     = keySeparator + lcNodeAcronym + keySeparator
 	lcConfPrefix     = keySeparator + lcConfigurationAcronym + keySeparator
+	inventoryPrefix  = keySeparator + inventoryConfAcronym + keySeparator
 	S3QoSPrefix      = keySeparator + S3QoS + keySeparator
 )
 
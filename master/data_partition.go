@@ -199,7 +199,7 @@ func (partition *DataPartition) tryToChangeLeader(c *Cluster, dataNode *DataNode
 func (partition *DataPartition) prepareAddRaftMember(addPeer proto.Peer) (leaderAddr string, candidateAddrs []string, err error) {
 	partition.RLock()
 	defer partition.RUnlock()
-	if contains(partition.Hosts, addPeer.Addr) {
+	if _, ok := partition.getPeer(addPeer.Addr); ok || contains(partition.Hosts, addPeer.Addr) {
 		err = fmt.Errorf("vol[%v],data partition[%v] has contains host[%v]", partition.VolName, partition.PartitionID, addPeer.Addr)
 		return
 	}
@@ -231,6 +231,49 @@ func (partition *DataPartition) createTaskToAddRaftMember(addPeer proto.Peer, le
 	return
 }
 
+func (partition *DataPartition) createTaskToPromoteLearner(peer proto.Peer, leaderAddr string) (task *proto.AdminTask) {
+	req := &proto.PromoteDataPartitionLearnerRequest{
+		PartitionId:  partition.PartitionID,
+		PromotePeer:  peer,
+		MaxLagOfLogs: defaultLearnerPromoteMaxLag,
+	}
+	task = proto.NewAdminTask(proto.OpPromoteDataPartitionLearner, leaderAddr, req)
+	partition.resetTaskID(task)
+	return
+}
+
+func (partition *DataPartition) getPeer(addr string) (peer proto.Peer, ok bool) {
+	for _, peer = range partition.Peers {
+		if peer.Addr == addr {
+			return peer, true
+		}
+	}
+	return proto.Peer{}, false
+}
+
+func (partition *DataPartition) isWitness(addr string) bool {
+	partition.RLock()
+	defer partition.RUnlock()
+	peer, ok := partition.getPeer(addr)
+	return ok && peer.IsWitness()
+}
+
+// canAddWitness checks the partition is a two replicas one without witness, the witness breaks the
+// tie of the two data replicas.
+func (partition *DataPartition) canAddWitness() (err error) {
+	partition.RLock()
+	defer partition.RUnlock()
+	if partition.ReplicaNum != 2 {
+		return fmt.Errorf("data partition[%v] replicaNum[%v], witness is only for two replicas", partition.PartitionID, partition.ReplicaNum)
+	}
+	for _, peer := range partition.Peers {
+		if peer.IsWitness() {
+			return fmt.Errorf("data partition[%v] already has witness[%v]", partition.PartitionID, peer.Addr)
+		}
+	}
+	return
+}
+
 func (partition *DataPartition) createTaskToRemoveRaftMember(c *Cluster, removePeer proto.Peer, force bool, autoRemove bool) (err error) {
 	doWork := func(leaderAddr string, flag bool) error {
 		log.LogInfof("action[createTaskToRemoveRaftMember] vol[%v],data partition[%v] removePeer %v leaderAddr %v autoRemove %v",
@@ -1351,10 +1394,15 @@ func (partition *DataPartition) Decommission(c *Cluster) bool {
 			goto errHandler
 		}
 	} else {
-		if err = c.removeDataReplica(partition, srcAddr, false, partition.DecommissionRaftForce); err != nil {
-			goto errHandler
+		// a forced decommission removes the source first, as the raft group may have lost the quorum
+		if partition.DecommissionRaftForce {
+			if err = c.removeDataReplica(partition, srcAddr, false, true); err != nil {
+				goto errHandler
+			}
 		}
-		if err = c.addDataReplica(partition, targetAddr, false); err != nil {
+		// the new replica is a learner until it's repaired, checkDiskRecoveryProgress promotes it
+		// and removes the source afterwards, so the quorum is never weakened during the repair
+		if err = c.addDataReplica(partition, targetAddr, false, proto.PeerLearner); err != nil {
 			goto errHandler
 		}
 		newReplica, _ := partition.getReplica(targetAddr)
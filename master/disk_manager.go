@@ -188,6 +188,28 @@ func (c *Cluster) checkDiskRecoveryProgress() {
 					partition.DecommissionErrorMessage = fmt.Sprintf("New replica %v is unavailable", partition.DecommissionDstAddr)
 					Warn(c.Name, fmt.Sprintf("action[checkDiskRecoveryProgress]clusterID[%v],partitionID[%v] replica %v has recovered failed",
 						c.Name, partitionID, partition.DecommissionDstAddr))
+				} else if err = c.promoteDataReplica(partition, newReplica.Addr); err != nil {
+					// the learner has not caught up the raft log yet, check it again later
+					if time.Since(partition.RecoverStartTime) > c.GetDecommissionDataPartitionRecoverTimeOut() {
+						partition.DecommissionNeedRollback = true
+						partition.SetDecommissionStatus(DecommissionFail)
+						partition.DecommissionErrorMessage = fmt.Sprintf("Decommission target node %v promote failed: %v",
+							partition.DecommissionDstAddr, err)
+					} else {
+						newBadDpIds = append(newBadDpIds, partitionID)
+						continue
+					}
+				} else if err = c.removeDecommissionSrcReplica(partition); err != nil {
+					// the source leaves only after the new replica is promoted, try it again later
+					if time.Since(partition.RecoverStartTime) > c.GetDecommissionDataPartitionRecoverTimeOut() {
+						partition.DecommissionNeedRollback = true
+						partition.SetDecommissionStatus(DecommissionFail)
+						partition.DecommissionErrorMessage = fmt.Sprintf("Decommission source node %v remove failed: %v",
+							partition.DecommissionSrcAddr, err)
+					} else {
+						newBadDpIds = append(newBadDpIds, partitionID)
+						continue
+					}
 				} else {
 					partition.DecommissionErrorMessage = ""
 					partition.SetDecommissionStatus(DecommissionSuccess) // can be readonly or readwrite
@@ -216,6 +238,14 @@ func (c *Cluster) checkDiskRecoveryProgress() {
 	})
 }
 
+// removeDecommissionSrcReplica removes the source replica of the decommission once the new replica is promoted.
+func (c *Cluster) removeDecommissionSrcReplica(partition *DataPartition) (err error) {
+	if replica, _ := partition.getReplica(partition.DecommissionSrcAddr); replica == nil {
+		return
+	}
+	return c.removeDataReplica(partition, partition.DecommissionSrcAddr, false, false)
+}
+
 func (c *Cluster) addAndSyncDecommissionedDisk(dataNode *DataNode, diskPath string) (err error) {
 	if exist := dataNode.addDecommissionedDisk(diskPath); exist {
 		return
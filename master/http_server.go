@@ -174,6 +174,7 @@ var AuthenticationUri2MsgTypeMap = map[string]proto.MsgType{
 	proto.QosUpdateZoneLimit:             proto.MsgMasterQosUpdateZoneLimitReq,
 	proto.QosUpdateMasterLimit:           proto.MsgMasterQosUpdateMasterLimitReq,
 	proto.QosUpdateClientParam:           proto.MsgMasterQosUpdateClientParamReq,
+	proto.AdminPromoteMetaReplica:        proto.MsgMasterPromoteMetaReplicaReq,
 
 	// Master API data partition management
 	proto.AdminCreateDataPartition:       proto.MsgMasterCreateDataPartitionReq,
@@ -183,6 +184,7 @@ var AuthenticationUri2MsgTypeMap = map[string]proto.MsgType{
 	proto.AdminAddDataReplica:            proto.MsgMasterAddDataReplicaReq,
 	proto.AdminDeleteDataReplica:         proto.MsgMasterDeleteDataReplicaReq,
 	proto.AdminSetDpRdOnly:               proto.MsgMasterSetDpRdOnlyReq,
+	proto.AdminPromoteDataReplica:        proto.MsgMasterPromoteDataReplicaReq,
 
 	// Master API meta node management
 	proto.AddMetaNode:               proto.MsgMasterAddMetaNodeReq,
@@ -211,6 +213,9 @@ var AuthenticationUri2MsgTypeMap = map[string]proto.MsgType{
 	proto.UserRemovePolicy:    proto.MsgMasterUserRemovePolicyReq,
 	proto.UserDeleteVolPolicy: proto.MsgMasterUserDeleteVolPolicyReq,
 	proto.UserTransferVol:     proto.MsgMasterUserTransferVolReq,
+	proto.RoleCreate:          proto.MsgMasterRoleCreateReq,
+	proto.RoleDelete:          proto.MsgMasterRoleDeleteReq,
+	proto.RoleUpdate:          proto.MsgMasterRoleUpdateReq,
 
 	// Master API zone management
 	proto.UpdateZone: proto.MsgMasterUpdateZoneReq,
@@ -448,6 +453,17 @@ func (m *Server) registerAPIRoutes(router *mux.Router) {
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.DeleteBucketLifecycle).
 		HandlerFunc(m.DelBucketLifecycle)
+
+	// S3 inventory configuration APIS
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.SetBucketInventory).
+		HandlerFunc(m.SetBucketInventory)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.GetBucketInventory).
+		HandlerFunc(m.GetBucketInventory)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.DeleteBucketInventory).
+		HandlerFunc(m.DelBucketInventory)
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.AddLcNode).
 		HandlerFunc(m.addLcNode)
@@ -518,6 +534,9 @@ func (m *Server) registerAPIRoutes(router *mux.Router) {
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.AdminAddMetaReplica).
 		HandlerFunc(m.addMetaReplica)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminPromoteMetaReplica).
+		HandlerFunc(m.promoteMetaReplica)
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.AdminDeleteMetaReplica).
 		HandlerFunc(m.deleteMetaReplica)
@@ -591,6 +610,9 @@ func (m *Server) registerAPIRoutes(router *mux.Router) {
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.AdminAddDataReplica).
 		HandlerFunc(m.addDataReplica)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminPromoteDataReplica).
+		HandlerFunc(m.promoteDataReplica)
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.AdminDeleteDataReplica).
 		HandlerFunc(m.deleteDataReplica)
@@ -755,6 +777,23 @@ func (m *Server) registerAPIRoutes(router *mux.Router) {
 		Path(proto.UsersOfVol).
 		HandlerFunc(m.getUsersOfVol)
 
+	// role management APIs
+	router.NewRoute().Methods(http.MethodPost).
+		Path(proto.RoleCreate).
+		HandlerFunc(m.createRole)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.RoleDelete).
+		HandlerFunc(m.deleteRole)
+	router.NewRoute().Methods(http.MethodPost).
+		Path(proto.RoleUpdate).
+		HandlerFunc(m.updateRole)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.RoleGetInfo).
+		HandlerFunc(m.getRoleInfo)
+	router.NewRoute().Methods(http.MethodGet).
+		Path(proto.RoleList).
+		HandlerFunc(m.getAllRoles)
+
 	// zone management APIs
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.UpdateZone).
@@ -27,6 +27,7 @@ type lifecycleManager struct {
 	sync.RWMutex
 	cluster          *Cluster
 	lcConfigurations map[string]*proto.LcConfiguration
+	inventoryConfs   map[string]*proto.InventoryConfigurations
 	lcNodeStatus     *lcNodeStatus
 	lcRuleTaskStatus *lcRuleTaskStatus
 	idleLcNodeCh     chan struct{}
@@ -37,6 +38,7 @@ func newLifecycleManager() *lifecycleManager {
 	log.LogInfof("action[newLifecycleManager] construct")
 	lcMgr := &lifecycleManager{
 		lcConfigurations: make(map[string]*proto.LcConfiguration),
+		inventoryConfs:   make(map[string]*proto.InventoryConfigurations),
 		lcNodeStatus:     newLcNodeStatus(),
 		lcRuleTaskStatus: newLcRuleTaskStatus(),
 		idleLcNodeCh:     make(chan struct{}),
@@ -80,6 +82,11 @@ func (lcMgr *lifecycleManager) genEnabledRuleTasks() []*proto.RuleTask {
 			tasks = append(tasks, ts...)
 		}
 	}
+	// the inventory reports are generated by scanning the volumes as the lifecycle rules
+	now := time.Now()
+	for _, v := range lcMgr.inventoryConfs {
+		tasks = append(tasks, v.GenInventoryTasks(now)...)
+	}
 	return tasks
 }
 
@@ -189,6 +196,27 @@ func (lcMgr *lifecycleManager) DelS3BucketLifecycle(VolName string) {
 	delete(lcMgr.lcConfigurations, VolName)
 }
 
+func (lcMgr *lifecycleManager) SetS3BucketInventory(invConf *proto.InventoryConfigurations) {
+	lcMgr.Lock()
+	defer lcMgr.Unlock()
+
+	lcMgr.inventoryConfs[invConf.VolName] = invConf
+}
+
+func (lcMgr *lifecycleManager) GetS3BucketInventory(VolName string) (invConf *proto.InventoryConfigurations) {
+	lcMgr.RLock()
+	defer lcMgr.RUnlock()
+
+	return lcMgr.inventoryConfs[VolName]
+}
+
+func (lcMgr *lifecycleManager) DelS3BucketInventory(VolName string) {
+	lcMgr.Lock()
+	defer lcMgr.Unlock()
+
+	delete(lcMgr.inventoryConfs, VolName)
+}
+
 //-----------------------------------------------
 
 type OpLcNode interface {
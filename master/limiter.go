@@ -15,6 +15,7 @@ type UidSpaceManager struct {
 	volName        string
 	mpSpaceMetrics map[uint64][]*proto.UidReportSpaceInfo
 	uidInfo        map[uint32]*proto.UidSpaceInfo
+	userInfo       map[string]*proto.UidUserInfo // uid and gid of the S3 users
 	c              *Cluster
 	vol            *Vol
 	msgChan        chan *proto.MetaPartitionReport
@@ -24,13 +25,16 @@ type UidSpaceManager struct {
 
 type UidSpaceFsm struct {
 	UidSpaceArr []*proto.UidSpaceInfo
+	UidUserArr  []*proto.UidUserInfo
 }
 
 type UidCmd struct {
-	op   uint64
-	uid  uint32
-	size uint64
-	wg   sync.WaitGroup
+	op     uint64
+	uid    uint32
+	gid    uint32
+	userID string
+	size   uint64
+	wg     sync.WaitGroup
 }
 
 func (vol *Vol) initUidSpaceManager(c *Cluster) {
@@ -40,6 +44,7 @@ func (vol *Vol) initUidSpaceManager(c *Cluster) {
 		volName:        vol.Name,
 		mpSpaceMetrics: make(map[uint64][]*proto.UidReportSpaceInfo),
 		uidInfo:        make(map[uint32]*proto.UidSpaceInfo),
+		userInfo:       make(map[string]*proto.UidUserInfo),
 		msgChan:        make(chan *proto.MetaPartitionReport, 10000),
 		cmdChan:        make(chan *UidCmd, 1000),
 	}
@@ -90,6 +95,38 @@ func (uMgr *UidSpaceManager) removeUid(cmd *UidCmd) bool {
 	return true
 }
 
+func (uMgr *UidSpaceManager) addUser(cmd *UidCmd) {
+	defer cmd.wg.Done()
+	uMgr.userInfo[cmd.userID] = &proto.UidUserInfo{
+		VolName: uMgr.volName,
+		UserID:  cmd.userID,
+		Uid:     cmd.uid,
+		Gid:     cmd.gid,
+	}
+	uMgr.persist()
+	log.LogWarnf("UidSpaceManager.vol %v addUser %v uid %v gid %v success", uMgr.volName, cmd.userID, cmd.uid, cmd.gid)
+}
+
+func (uMgr *UidSpaceManager) removeUser(cmd *UidCmd) {
+	defer cmd.wg.Done()
+	if _, ok := uMgr.userInfo[cmd.userID]; !ok {
+		log.LogWarnf("UidSpaceManager.vol %v del user %v not exist", uMgr.volName, cmd.userID)
+		return
+	}
+	delete(uMgr.userInfo, cmd.userID)
+	uMgr.persist()
+	log.LogWarnf("UidSpaceManager.vol %v del user %v success", uMgr.volName, cmd.userID)
+}
+
+func (uMgr *UidSpaceManager) listUsers() (rsp []*proto.UidUserInfo) {
+	uMgr.rwMutex.RLock()
+	defer uMgr.rwMutex.RUnlock()
+	for _, info := range uMgr.userInfo {
+		rsp = append(rsp, info)
+	}
+	return
+}
+
 func (uMgr *UidSpaceManager) checkUid(uid uint32) (ok bool, uidInfo *proto.UidSpaceInfo) {
 	uMgr.rwMutex.RLock()
 	defer uMgr.rwMutex.RUnlock()
@@ -115,6 +152,9 @@ func (uMgr *UidSpaceManager) persist() (err error) {
 	for _, t := range uMgr.uidInfo {
 		uidFsm.UidSpaceArr = append(uidFsm.UidSpaceArr, t)
 	}
+	for _, t := range uMgr.userInfo {
+		uidFsm.UidUserArr = append(uidFsm.UidUserArr, t)
+	}
 
 	var val []byte
 	if val, err = json.Marshal(uidFsm); err != nil {
@@ -140,6 +180,9 @@ func (uMgr *UidSpaceManager) load(c *Cluster, val []byte) (err error) {
 		uMgr.uidInfo[info.Uid] = info
 		log.LogDebugf("vol %v uid %v load usedSize %v limit %v enabled %v", uMgr.volName, info.Uid, info.UsedSize, info.LimitSize, info.Limited)
 	}
+	for _, info := range uidFsm.UidUserArr {
+		uMgr.userInfo[info.UserID] = info
+	}
 	return
 }
 
@@ -166,6 +209,10 @@ func (uMgr *UidSpaceManager) scheduleUidUpdate() {
 				uMgr.addUid(cmd)
 			} else if cmd.op == util.UidDelLimit {
 				uMgr.removeUid(cmd)
+			} else if cmd.op == util.UidUserAdd {
+				uMgr.addUser(cmd)
+			} else if cmd.op == util.UidUserDel {
+				uMgr.removeUser(cmd)
 			}
 			uMgr.rwMutex.Unlock()
 			log.LogDebugf("vol %v scheduleUidUpdate.cmd(%v) left", uMgr.volName, cmd)
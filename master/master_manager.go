@@ -200,6 +200,9 @@ func (m *Server) loadMetadata() {
 	if err = m.user.loadVolUsers(); err != nil {
 		panic(err)
 	}
+	if err = m.user.loadRoleStore(); err != nil {
+		panic(err)
+	}
 	log.LogInfo("action[loadUserInfo] end")
 
 	log.LogInfo("action[refreshUser] begin")
@@ -226,6 +229,12 @@ func (m *Server) loadMetadata() {
 	}
 	log.LogInfo("action[loadLcConfs] end")
 
+	log.LogInfo("action[loadInventoryConfs] begin")
+	if err = m.cluster.loadInventoryConfs(); err != nil {
+		panic(err)
+	}
+	log.LogInfo("action[loadInventoryConfs] end")
+
 	log.LogInfo("action[loadLcNodes] begin")
 	if err = m.cluster.loadLcNodes(); err != nil {
 		panic(err)
@@ -252,6 +261,7 @@ func (m *Server) clearMetadata() {
 		m.user.clearUserStore()
 		m.user.clearAKStore()
 		m.user.clearVolUsers()
+		m.user.clearRoleStore()
 	}
 
 	m.cluster.t = newTopology()
@@ -681,6 +681,26 @@ func (mp *MetaPartition) createTaskToAddRaftMember(addPeer proto.Peer, leaderAdd
 	return
 }
 
+func (mp *MetaPartition) createTaskToPromoteLearner(peer proto.Peer, leaderAddr string) (t *proto.AdminTask) {
+	req := &proto.PromoteMetaPartitionLearnerRequest{
+		PartitionId:  mp.PartitionID,
+		PromotePeer:  peer,
+		MaxLagOfLogs: defaultLearnerPromoteMaxLag,
+	}
+	t = proto.NewAdminTask(proto.OpPromoteMetaPartitionLearner, leaderAddr, req)
+	resetMetaPartitionTaskID(t, mp.PartitionID)
+	return
+}
+
+func (mp *MetaPartition) getPeer(addr string) (peer proto.Peer, ok bool) {
+	for _, peer = range mp.Peers {
+		if peer.Addr == addr {
+			return peer, true
+		}
+	}
+	return proto.Peer{}, false
+}
+
 func (mp *MetaPartition) createTaskToRemoveRaftMember(removePeer proto.Peer) (t *proto.AdminTask, err error) {
 	mr, err := mp.getMetaReplicaLeader()
 	if err != nil {
@@ -301,6 +301,10 @@ type volValue struct {
 	CacheRule        string
 
 	EnablePosixAcl bool
+	PermissionMode uint8
+	EnableDedup    bool
+	EnableDefrag   bool
+	EnableMirror   bool
 	EnableQuota    bool
 
 	EnableTransaction       bsProto.TxOpMask
@@ -351,6 +355,10 @@ func newVolValue(vol *Vol) (vv *volValue) {
 		DpSelectorParm:          vol.dpSelectorParm,
 		DefaultPriority:         vol.defaultPriority,
 		EnablePosixAcl:          vol.enablePosixAcl,
+		PermissionMode:          vol.permissionMode,
+		EnableDedup:             vol.enableDedup,
+		EnableDefrag:            vol.enableDefrag,
+		EnableMirror:            vol.enableMirror,
 		EnableQuota:             vol.enableQuota,
 		EnableTransaction:       vol.enableTransaction,
 		TxTimeout:               vol.txTimeout,
@@ -1871,3 +1879,45 @@ func (c *Cluster) loadLcConfs() (err error) {
 	}
 	return
 }
+
+func (c *Cluster) syncAddInventoryConf(invConf *bsProto.InventoryConfigurations) (err error) {
+	return c.syncPutInventoryConfInfo(opSyncAddInventoryConf, invConf)
+}
+
+func (c *Cluster) syncDeleteInventoryConf(invConf *bsProto.InventoryConfigurations) (err error) {
+	return c.syncPutInventoryConfInfo(opSyncDeleteInventoryConf, invConf)
+}
+
+func (c *Cluster) syncUpdateInventoryConf(invConf *bsProto.InventoryConfigurations) (err error) {
+	return c.syncPutInventoryConfInfo(opSyncUpdateInventoryConf, invConf)
+}
+
+func (c *Cluster) syncPutInventoryConfInfo(opType uint32, invConf *bsProto.InventoryConfigurations) (err error) {
+	metadata := new(RaftCmd)
+	metadata.Op = opType
+	metadata.K = inventoryPrefix + invConf.VolName
+	metadata.V, err = json.Marshal(invConf)
+	if err != nil {
+		return errors.New(err.Error())
+	}
+	return c.submit(metadata)
+}
+
+func (c *Cluster) loadInventoryConfs() (err error) {
+	result, err := c.fsm.store.SeekForPrefix([]byte(inventoryPrefix))
+	if err != nil {
+		err = fmt.Errorf("action[loadInventoryConfs],err:%v", err.Error())
+		return err
+	}
+
+	for _, value := range result {
+		invConf := &bsProto.InventoryConfigurations{}
+		if err = json.Unmarshal(value, invConf); err != nil {
+			err = fmt.Errorf("action[loadInventoryConfs],value:%v,unmarshal err:%v", string(value), err)
+			return
+		}
+		c.lcMgr.SetS3BucketInventory(invConf)
+		log.LogInfof("action[loadInventoryConfs],vol[%v]", invConf.VolName)
+	}
+	return
+}
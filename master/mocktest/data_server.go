@@ -142,6 +142,9 @@ func (mds *MockDataServer) serveConn(rc net.Conn) {
 	case proto.OpRemoveDataPartitionRaftMember:
 		err = mds.handleRemoveDataPartitionRaftMember(conn, req, adminTask)
 		Printf("data node [%v] remove data partition raft member,id[%v],err:%v\n", mds.TcpAddr, adminTask.ID, err)
+	case proto.OpPromoteDataPartitionLearner:
+		err = mds.handlePromoteDataPartitionLearner(conn, req, adminTask)
+		Printf("data node [%v] promote data partition learner,id[%v],err:%v\n", mds.TcpAddr, adminTask.ID, err)
 	case proto.OpDataPartitionTryToLeader:
 		err = mds.handleTryToLeader(conn, req, adminTask)
 		Printf("data node [%v] try to leader,id[%v],err:%v\n", mds.TcpAddr, adminTask.ID, err)
@@ -155,6 +158,11 @@ func (mds *MockDataServer) handleAddDataPartitionRaftMember(conn net.Conn, p *pr
 	return
 }
 
+func (mds *MockDataServer) handlePromoteDataPartitionLearner(conn net.Conn, p *proto.Packet, adminTask *proto.AdminTask) (err error) {
+	responseAckOKToMaster(conn, p, nil)
+	return
+}
+
 func (mds *MockDataServer) handleRemoveDataPartitionRaftMember(conn net.Conn, p *proto.Packet, adminTask *proto.AdminTask) (err error) {
 	responseAckOKToMaster(conn, p, nil)
 	return
@@ -132,6 +132,9 @@ func (mms *MockMetaServer) serveConn(rc net.Conn) {
 	case proto.OpRemoveMetaPartitionRaftMember:
 		err = mms.handleRemoveMetaPartitionRaftMember(conn, req, adminTask)
 		Printf("meta node [%v] remove data partition raft member,id[%v],err:%v\n", mms.TcpAddr, adminTask.ID, err)
+	case proto.OpPromoteMetaPartitionLearner:
+		err = mms.handlePromoteMetaPartitionLearner(conn, req, adminTask)
+		Printf("meta node [%v] promote meta partition learner,id[%v],err:%v\n", mms.TcpAddr, adminTask.ID, err)
 	case proto.OpMetaPartitionTryToLeader:
 		err = mms.handleTryToLeader(conn, req, adminTask)
 		Printf("meta node [%v] try to leader,id[%v],err:%v\n", mms.TcpAddr, adminTask.ID, err)
@@ -145,6 +148,11 @@ func (mms *MockMetaServer) handleAddMetaPartitionRaftMember(conn net.Conn, p *pr
 	return
 }
 
+func (mms *MockMetaServer) handlePromoteMetaPartitionLearner(conn net.Conn, p *proto.Packet, adminTask *proto.AdminTask) (err error) {
+	responseAckOKToMaster(conn, p, nil)
+	return
+}
+
 func (mms *MockMetaServer) handleRemoveMetaPartitionRaftMember(conn net.Conn, p *proto.Packet, adminTask *proto.AdminTask) (err error) {
 	responseAckOKToMaster(conn, p, nil)
 	return
@@ -0,0 +1,161 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	defaultRoleMaxSessionDuration = 3600  // seconds
+	maxRoleMaxSessionDuration     = 43200 // seconds
+)
+
+var roleNameRegexp = regexp.MustCompile(`^[\w+=,.@-]{1,64}$`)
+
+// The policy documents are evaluated by ObjectNode, only the format is checked here.
+func isValidRolePolicy(policy string) bool {
+	return policy != "" && json.Valid([]byte(policy))
+}
+
+func isValidRoleMaxSessionDuration(duration int64) bool {
+	return duration >= defaultRoleMaxSessionDuration && duration <= maxRoleMaxSessionDuration
+}
+
+func (u *User) createRole(param *proto.RoleCreateParam) (roleInfo *proto.RoleInfo, err error) {
+	if !roleNameRegexp.MatchString(param.RoleName) {
+		err = proto.ErrInvalidRole
+		return
+	}
+	if !isValidRolePolicy(param.TrustPolicy) || !isValidRolePolicy(param.PermissionPolicy) {
+		err = proto.ErrInvalidRole
+		return
+	}
+	maxSessionDuration := param.MaxSessionDuration
+	if maxSessionDuration == 0 {
+		maxSessionDuration = defaultRoleMaxSessionDuration
+	}
+	if !isValidRoleMaxSessionDuration(maxSessionDuration) {
+		err = proto.ErrInvalidRole
+		return
+	}
+	if _, err = u.getUserInfo(param.UserID); err != nil {
+		return
+	}
+	u.roleStoreMutex.Lock()
+	defer u.roleStoreMutex.Unlock()
+	if _, exist := u.roleStore.Load(param.RoleName); exist {
+		err = proto.ErrDuplicateRole
+		return
+	}
+	roleInfo = &proto.RoleInfo{
+		RoleName: param.RoleName, UserID: param.UserID, TrustPolicy: param.TrustPolicy,
+		PermissionPolicy: param.PermissionPolicy, MaxSessionDuration: maxSessionDuration,
+		CreateTime: time.Unix(time.Now().Unix(), 0).Format(proto.TimeFormat), Description: param.Description,
+	}
+	if err = u.syncAddRoleInfo(roleInfo); err != nil {
+		return
+	}
+	u.roleStore.Store(roleInfo.RoleName, roleInfo)
+	log.LogInfof("action[createRole], roleName: %v, userID: %v", roleInfo.RoleName, roleInfo.UserID)
+	return
+}
+
+func (u *User) deleteRole(roleName string) (err error) {
+	u.roleStoreMutex.Lock()
+	defer u.roleStoreMutex.Unlock()
+	var roleInfo *proto.RoleInfo
+	if roleInfo, err = u.getRoleInfo(roleName); err != nil {
+		return
+	}
+	if err = u.syncDeleteRoleInfo(roleInfo); err != nil {
+		return
+	}
+	u.roleStore.Delete(roleName)
+	log.LogInfof("action[deleteRole], roleName: %v", roleName)
+	return
+}
+
+func (u *User) updateRole(param *proto.RoleUpdateParam) (roleInfo *proto.RoleInfo, err error) {
+	u.roleStoreMutex.Lock()
+	defer u.roleStoreMutex.Unlock()
+	var old *proto.RoleInfo
+	if old, err = u.getRoleInfo(param.RoleName); err != nil {
+		return
+	}
+	// the stored role is replaced rather than modified, readers may hold the old one
+	newRole := *old
+	if param.TrustPolicy != "" {
+		newRole.TrustPolicy = param.TrustPolicy
+	}
+	if param.PermissionPolicy != "" {
+		newRole.PermissionPolicy = param.PermissionPolicy
+	}
+	if param.MaxSessionDuration != 0 {
+		newRole.MaxSessionDuration = param.MaxSessionDuration
+	}
+	if param.Description != "" {
+		newRole.Description = param.Description
+	}
+	if !isValidRolePolicy(newRole.TrustPolicy) || !isValidRolePolicy(newRole.PermissionPolicy) ||
+		!isValidRoleMaxSessionDuration(newRole.MaxSessionDuration) {
+		err = proto.ErrInvalidRole
+		return
+	}
+	if err = u.syncUpdateRoleInfo(&newRole); err != nil {
+		err = proto.ErrPersistenceByRaft
+		return
+	}
+	roleInfo = &newRole
+	u.roleStore.Store(roleInfo.RoleName, roleInfo)
+	log.LogInfof("action[updateRole], roleName: %v", roleInfo.RoleName)
+	return
+}
+
+func (u *User) getRoleInfo(roleName string) (roleInfo *proto.RoleInfo, err error) {
+	value, exist := u.roleStore.Load(roleName)
+	if !exist {
+		err = proto.ErrRoleNotExists
+		return
+	}
+	roleInfo = value.(*proto.RoleInfo)
+	return
+}
+
+func (u *User) getAllRoleInfo(keywords string) (roles []*proto.RoleInfo) {
+	roles = make([]*proto.RoleInfo, 0)
+	u.roleStore.Range(func(key, value interface{}) bool {
+		roleInfo := value.(*proto.RoleInfo)
+		if strings.Contains(roleInfo.RoleName, keywords) {
+			roles = append(roles, roleInfo)
+		}
+		return true
+	})
+	log.LogInfof("action[getAllRoleInfo], keywords: %v, total numbers: %v", keywords, len(roles))
+	return
+}
+
+func (u *User) clearRoleStore() {
+	u.roleStore.Range(func(key, value interface{}) bool {
+		u.roleStore.Delete(key)
+		return true
+	})
+}
@@ -28,9 +28,11 @@ type User struct {
 	userStore      sync.Map // K: userID, V: UserInfo
 	AKStore        sync.Map // K: ak, V: userID
 	volUser        sync.Map // K: vol, V: userIDs
+	roleStore      sync.Map // K: roleName, V: RoleInfo
 	userStoreMutex sync.RWMutex
 	AKStoreMutex   sync.RWMutex
 	volUserMutex   sync.RWMutex
+	roleStoreMutex sync.RWMutex
 }
 
 func newUser(fsm *MetadataFsm, partition raftstore.Partition) (u *User) {
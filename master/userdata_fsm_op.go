@@ -103,6 +103,30 @@ func (u *User) syncPutVolUser(opType uint32, volUser *proto.VolUser) (err error)
 	return u.submit(userInfo)
 }
 
+// key = #role#roleName, value = roleInfo
+func (u *User) syncAddRoleInfo(roleInfo *proto.RoleInfo) (err error) {
+	return u.syncPutRoleInfo(opSyncAddRoleInfo, roleInfo)
+}
+
+func (u *User) syncDeleteRoleInfo(roleInfo *proto.RoleInfo) (err error) {
+	return u.syncPutRoleInfo(opSyncDeleteRoleInfo, roleInfo)
+}
+
+func (u *User) syncUpdateRoleInfo(roleInfo *proto.RoleInfo) (err error) {
+	return u.syncPutRoleInfo(opSyncUpdateRoleInfo, roleInfo)
+}
+
+func (u *User) syncPutRoleInfo(opType uint32, roleInfo *proto.RoleInfo) (err error) {
+	raftCmd := new(RaftCmd)
+	raftCmd.Op = opType
+	raftCmd.K = rolePrefix + roleInfo.RoleName
+	raftCmd.V, err = json.Marshal(roleInfo)
+	if err != nil {
+		return errors.New(err.Error())
+	}
+	return u.submit(raftCmd)
+}
+
 func (u *User) loadUserStore() (err error) {
 	result, err := u.fsm.store.SeekForPrefix([]byte(userPrefix))
 	if err != nil {
@@ -156,3 +180,21 @@ func (u *User) loadVolUsers() (err error) {
 	}
 	return
 }
+
+func (u *User) loadRoleStore() (err error) {
+	result, err := u.fsm.store.SeekForPrefix([]byte(rolePrefix))
+	if err != nil {
+		err = fmt.Errorf("action[loadRoleStore], err: %v", err.Error())
+		return err
+	}
+	for _, value := range result {
+		roleInfo := &proto.RoleInfo{}
+		if err = json.Unmarshal(value, roleInfo); err != nil {
+			err = fmt.Errorf("action[loadRoleStore], unmarshal err: %v", err.Error())
+			return err
+		}
+		u.roleStore.Store(roleInfo.RoleName, roleInfo)
+		log.LogInfof("action[loadRoleStore], roleName[%v], userID[%v]", roleInfo.RoleName, roleInfo.UserID)
+	}
+	return
+}
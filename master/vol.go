@@ -43,6 +43,10 @@ type VolVarargs struct {
 	coldArgs                *coldVolArgs
 	dpReplicaNum            uint8
 	enablePosixAcl          bool
+	permissionMode          uint8
+	enableDedup             bool
+	enableDefrag            bool
+	enableMirror            bool
 	dpReadOnlyWhenVolFull   bool
 	enableQuota             bool
 	enableTransaction       proto.TxOpMask
@@ -89,6 +93,10 @@ type Vol struct {
 	domainOn                bool
 	defaultPriority         bool // old default zone first
 	enablePosixAcl          bool
+	permissionMode          uint8
+	enableDedup             bool
+	enableDefrag            bool
+	enableMirror            bool
 	enableTransaction       proto.TxOpMask
 	txTimeout               int64
 	txConflictRetryNum      int64
@@ -154,6 +162,10 @@ func newVol(vv volValue) (vol *Vol) {
 	vol.defaultPriority = vv.DefaultPriority
 	vol.domainId = vv.DomainId
 	vol.enablePosixAcl = vv.EnablePosixAcl
+	vol.permissionMode = vv.PermissionMode
+	vol.enableDedup = vv.EnableDedup
+	vol.enableDefrag = vv.EnableDefrag
+	vol.enableMirror = vv.EnableMirror
 	vol.enableQuota = vv.EnableQuota
 	vol.enableTransaction = vv.EnableTransaction
 	vol.txTimeout = vv.TxTimeout
@@ -1554,6 +1566,10 @@ func setVolFromArgs(args *VolVarargs, vol *Vol) {
 	vol.FollowerRead = args.followerRead
 	vol.authenticate = args.authenticate
 	vol.enablePosixAcl = args.enablePosixAcl
+	vol.permissionMode = args.permissionMode
+	vol.enableDedup = args.enableDedup
+	vol.enableDefrag = args.enableDefrag
+	vol.enableMirror = args.enableMirror
 	vol.DpReadOnlyWhenVolFull = args.dpReadOnlyWhenVolFull
 	vol.enableQuota = args.enableQuota
 	vol.enableTransaction = args.enableTransaction
@@ -1609,6 +1625,10 @@ func getVolVarargs(vol *Vol) *VolVarargs {
 		dpSelectorName:          vol.dpSelectorName,
 		dpSelectorParm:          vol.dpSelectorParm,
 		enablePosixAcl:          vol.enablePosixAcl,
+		permissionMode:          vol.permissionMode,
+		enableDedup:             vol.enableDedup,
+		enableDefrag:            vol.enableDefrag,
+		enableMirror:            vol.enableMirror,
 		enableQuota:             vol.enableQuota,
 		dpReplicaNum:            vol.dpReplicaNum,
 		enableTransaction:       vol.enableTransaction,
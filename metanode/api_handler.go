@@ -77,6 +77,8 @@ func (m *MetaNode) registerAPIHandler() (err error) {
 	http.HandleFunc("/getDentrySnapshot", m.getDentrySnapshotHandler)
 	// get tx information
 	http.HandleFunc("/getTx", m.getTxHandler)
+	// get changes applied after an index, used by volume mirror
+	http.HandleFunc("/getChangeLog", m.getChangeLogHandler)
 	return
 }
 
@@ -527,6 +529,40 @@ func (m *MetaNode) getTxHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (m *MetaNode) getChangeLogHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusBadRequest, "")
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[getChangeLogHandler] response %s", err)
+		}
+	}()
+	var pid, from, limit common.Uint
+	limit.V = defaultChangeLogReadLimit
+	if err := parseArgs(r, pid.PID(), from.Key("from").OmitEmpty(), limit.Key("limit").OmitEmpty()); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	if limit.V == 0 || limit.V > defaultChangeLogReadLimit {
+		limit.V = defaultChangeLogReadLimit
+	}
+
+	mp, err := m.metadataManager.GetPartition(pid.V)
+	if err != nil {
+		resp.Code = http.StatusNotFound
+		resp.Msg = err.Error()
+		return
+	}
+	changes, err := mp.ReadChangeLog(from.V, int(limit.V))
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	resp.Code = http.StatusOK
+	resp.Msg = http.StatusText(http.StatusOK)
+	resp.Data = changes
+}
+
 func (m *MetaNode) getRealVerSeq(w http.ResponseWriter, r *http.Request) (verSeq uint64, err error) {
 	var seq common.Uint
 	err = parseArgs(r, seq.Key("verSeq").OmitEmpty().OnValue(func() error {
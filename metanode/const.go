@@ -189,11 +189,20 @@ const (
 	opFSMStoreTickV1  = 72
 
 	opFSMVerListSnapShot = 73
+
+	opFSMExtentsReplace = 75
+	opFSMExtentsRemap   = 76
+
+	opFSMUpdateDentryWithCond = 77
+
+	opFSMInodeModify = 78
 )
 
 var (
 	ErrNoLeader   = errors.New("no leader")
 	ErrNotALeader = errors.New("not a leader")
+
+	ErrChangeLogDisabled = errors.New("change log is disabled")
 )
 
 // Default configuration
@@ -222,6 +231,7 @@ const (
 	cfgRetainLogs                = "retainLogs"                // string, raft RetainLogs
 	cfgRaftSyncSnapFormatVersion = "raftSyncSnapFormatVersion" // int, format version of snapshot that raft leader sent to follower
 	cfgServiceIDKey              = "serviceIDKey"
+	cfgChangeLogCapacity         = "changeLogCapacity" // int, change records kept by each partition for volume mirror
 
 	metaNodeDeleteBatchCountKey = "batchCount"
 	configNameResolveInterval   = "nameResolveInterval" // int
@@ -234,6 +244,7 @@ const (
 
 	defaultDelExtentsCnt         = 100000
 	defaultMaxQuotaGoroutine     = 5
+	defaultMaxFragmentedInodes   = 1000
 	defaultQuotaSwitch           = true
 	DefaultNameResolveInterval   = 1 // minutes
 	DefaultRaftNumOfLogsToRetain = 20000 * 2
@@ -51,6 +51,8 @@ type Vol struct {
 	sync.RWMutex
 	dataPartitionView map[uint64]*DataPartition
 	volDeleteLockTime int64
+	enableDedup       bool
+	enableDefrag      bool
 }
 
 // NewVol returns a new volume instance.
@@ -243,6 +243,14 @@ func (m *metadataManager) HandleMetadataOperation(conn net.Conn, p *Packet, remo
 		err = m.opMetaExtentsAdd(conn, p, remoteAddr)
 	case proto.OpMetaExtentAddWithCheck:
 		err = m.opMetaExtentAddWithCheck(conn, p, remoteAddr)
+	case proto.OpMetaExtentsReplace:
+		err = m.opMetaExtentsReplace(conn, p, remoteAddr)
+	case proto.OpMetaGetFragmentedInodes:
+		err = m.opMetaGetFragmentedInodes(conn, p, remoteAddr)
+	case proto.OpMetaExtentsRemap:
+		err = m.opMetaExtentsRemap(conn, p, remoteAddr)
+	case proto.OpMetaInodeModify:
+		err = m.opMetaInodeModify(conn, p, remoteAddr)
 	case proto.OpMetaExtentsList:
 		err = m.opMetaExtentsList(conn, p, remoteAddr)
 	case proto.OpMetaObjExtentsList:
@@ -265,6 +273,8 @@ func (m *metadataManager) HandleMetadataOperation(conn net.Conn, p *Packet, remo
 		err = m.opAddMetaPartitionRaftMember(conn, p, remoteAddr)
 	case proto.OpRemoveMetaPartitionRaftMember:
 		err = m.opRemoveMetaPartitionRaftMember(conn, p, remoteAddr)
+	case proto.OpPromoteMetaPartitionLearner:
+		err = m.opPromoteMetaPartitionLearner(conn, p, remoteAddr)
 	case proto.OpMetaPartitionTryToLeader:
 		err = m.opMetaPartitionTryToLeader(conn, p, remoteAddr)
 	case proto.OpMetaBatchInodeGet:
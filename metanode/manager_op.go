@@ -1561,7 +1561,7 @@ func (m *metadataManager) opDecommissionMetaPartition(conn net.Conn,
 		return
 	}
 	_, err = mp.ChangeMember(raftProto.ConfAddNode,
-		raftProto.Peer{ID: req.AddPeer.ID}, reqData)
+		raftProto.Peer{ID: req.AddPeer.ID, Type: raftProto.PeerType(req.AddPeer.Type)}, reqData)
 	if err != nil {
 		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
 		m.respondToClientWithVer(conn, p)
@@ -1650,6 +1650,76 @@ func (m *metadataManager) opAddMetaPartitionRaftMember(conn net.Conn,
 	return
 }
 
+func (m *metadataManager) opPromoteMetaPartitionLearner(conn net.Conn,
+	p *Packet, remoteAddr string,
+) (err error) {
+	var reqData []byte
+	req := &proto.PromoteMetaPartitionLearnerRequest{}
+	adminTask := &proto.AdminTask{
+		Request: req,
+	}
+
+	defer func() {
+		if err != nil {
+			log.LogInfof("[%s], remote %s promote learner failed, req %v, err %s", p.String(), remoteAddr, adminTask, err.Error())
+			return
+		}
+
+		log.LogInfof("[%s], remote %s promote learner success, req %v", p.String(), remoteAddr, adminTask)
+	}()
+
+	decode := json.NewDecoder(bytes.NewBuffer(p.Data))
+	decode.UseNumber()
+	if err = decode.Decode(adminTask); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClientWithVer(conn, p)
+		return err
+	}
+	mp, err := m.getPartition(req.PartitionId)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpTryOtherAddr, ([]byte)(proto.ErrMetaPartitionNotExists.Error()))
+		m.respondToClientWithVer(conn, p)
+		return err
+	}
+
+	peer, ok := mp.GetPeer(req.PromotePeer.ID)
+	if !ok {
+		err = fmt.Errorf("peer %v not found in mp %v", req.PromotePeer, req.PartitionId)
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClientWithVer(conn, p)
+		return
+	}
+	if !peer.IsLearner() {
+		p.PacketOkReply()
+		m.respondToClientWithVer(conn, p)
+		return
+	}
+
+	if !m.serveProxy(conn, mp, p) {
+		return nil
+	}
+	if reqData, err = json.Marshal(req); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClientWithVer(conn, p)
+		return
+	}
+	if err = mp.CheckLearnerCaughtUp(peer.ID, req.MaxLagOfLogs); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClientWithVer(conn, p)
+		return
+	}
+	_, err = mp.ChangeMember(raftProto.ConfUpdateNode,
+		raftProto.Peer{ID: peer.ID, Type: raftProto.PeerNormal}, reqData)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClientWithVer(conn, p)
+		return err
+	}
+	p.PacketOkReply()
+	m.respondToClientWithVer(conn, p)
+	return
+}
+
 func (m *metadataManager) opRemoveMetaPartitionRaftMember(conn net.Conn,
 	p *Packet, remoteAddr string,
 ) (err error) {
@@ -2113,6 +2183,124 @@ func (m *metadataManager) opMetaBatchExtentsAdd(conn net.Conn, p *Packet, remote
 	return
 }
 
+func (m *metadataManager) opMetaExtentsReplace(conn net.Conn, p *Packet, remoteAddr string) (err error) {
+	req := &proto.ReplaceExtentKeysRequest{}
+	if err = json.Unmarshal(p.Data, req); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClientWithVer(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	mp, err := m.getPartition(req.PartitionId)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClientWithVer(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	if !m.serveProxy(conn, mp, p) {
+		return
+	}
+	if err = m.checkMultiVersionStatus(mp, p); err != nil {
+		err = errors.NewErrorf("[%v],req[%v],err[%v]", p.GetOpMsgWithReqAndResult(), req, string(p.Data))
+		m.respondToClientWithVer(conn, p)
+		return
+	}
+	err = mp.ExtentsReplace(req, p)
+	m.updatePackRspSeq(mp, p)
+	_ = m.respondToClientWithVer(conn, p)
+	log.LogDebugf("%s [opMetaExtentsReplace] req: %d - %v, resp: %v, body: %s",
+		remoteAddr, p.GetReqID(), req, p.GetResultMsg(), p.Data)
+	return
+}
+
+func (m *metadataManager) opMetaExtentsRemap(conn net.Conn, p *Packet, remoteAddr string) (err error) {
+	req := &proto.RemapExtentKeysRequest{}
+	if err = json.Unmarshal(p.Data, req); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClientWithVer(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	mp, err := m.getPartition(req.PartitionId)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClientWithVer(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	if !m.serveProxy(conn, mp, p) {
+		return
+	}
+	if err = m.checkMultiVersionStatus(mp, p); err != nil {
+		err = errors.NewErrorf("[%v],req[%v],err[%v]", p.GetOpMsgWithReqAndResult(), req, string(p.Data))
+		m.respondToClientWithVer(conn, p)
+		return
+	}
+	err = mp.ExtentsRemap(req, p)
+	m.updatePackRspSeq(mp, p)
+	_ = m.respondToClientWithVer(conn, p)
+	log.LogDebugf("%s [opMetaExtentsRemap] req: %d - %v, resp: %v, body: %s",
+		remoteAddr, p.GetReqID(), req, p.GetResultMsg(), p.Data)
+	return
+}
+
+func (m *metadataManager) opMetaInodeModify(conn net.Conn, p *Packet, remoteAddr string) (err error) {
+	req := &proto.InodeModifyRequest{}
+	if err = json.Unmarshal(p.Data, req); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClientWithVer(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	mp, err := m.getPartition(req.PartitionId)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClientWithVer(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	if !m.serveProxy(conn, mp, p) {
+		return
+	}
+	if err = m.checkMultiVersionStatus(mp, p); err != nil {
+		err = errors.NewErrorf("[%v],req[%v],err[%v]", p.GetOpMsgWithReqAndResult(), req, string(p.Data))
+		m.respondToClientWithVer(conn, p)
+		return
+	}
+	err = mp.InodeModify(req, p)
+	m.updatePackRspSeq(mp, p)
+	_ = m.respondToClientWithVer(conn, p)
+	log.LogDebugf("%s [opMetaInodeModify] req: %d - %v, resp: %v, body: %s",
+		remoteAddr, p.GetReqID(), req, p.GetResultMsg(), p.Data)
+	return
+}
+
+func (m *metadataManager) opMetaGetFragmentedInodes(conn net.Conn, p *Packet, remoteAddr string) (err error) {
+	req := &proto.GetFragmentedInodesRequest{}
+	if err = json.Unmarshal(p.Data, req); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	mp, err := m.getPartition(req.PartitionId)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	if !m.serveProxy(conn, mp, p) {
+		return
+	}
+	err = mp.GetFragmentedInodes(req, p)
+	m.respondToClient(conn, p)
+	log.LogDebugf("%s [opMetaGetFragmentedInodes] req: %d - %v, resp: %v",
+		remoteAddr, p.GetReqID(), req, p.GetResultMsg())
+	return
+}
+
 func (m *metadataManager) opMetaBatchObjExtentsAdd(conn net.Conn, p *Packet, remoteAddr string) (err error) {
 	req := &proto.AppendObjExtentKeysRequest{}
 	if err = json.Unmarshal(p.Data, req); err != nil {
@@ -56,6 +56,9 @@ func (m *metadataManager) IsForbiddenOp(mp MetaPartition, reqOp uint8) bool {
 		proto.OpMetaBatchObjExtentsAdd,
 		proto.OpMetaBatchExtentsAdd,
 		proto.OpMetaExtentsDel,
+		proto.OpMetaExtentsReplace,
+		proto.OpMetaExtentsRemap,
+		proto.OpMetaInodeModify,
 		// inode
 		proto.OpMetaCreateInode,
 		proto.OpQuotaCreateInode,
@@ -215,6 +215,10 @@ func (m *MetaNode) parseConfig(cfg *config.Config) (err error) {
 
 	m.serviceIDKey = cfg.GetString(cfgServiceIDKey)
 
+	if changeLogCap := cfg.GetInt64(cfgChangeLogCapacity); changeLogCap > 0 {
+		updateChangeLogCapacity(uint64(changeLogCap))
+	}
+
 	total, _, err := util.GetMemInfo()
 	if err != nil {
 		log.LogErrorf("get total mem failed, err %s", err.Error())
@@ -198,6 +198,10 @@ type OpExtent interface {
 	ObjExtentsList(req *proto.GetExtentsRequest, p *Packet) (err error)
 	ExtentsTruncate(req *ExtentsTruncateReq, p *Packet, remoteAddr string) (err error)
 	BatchExtentAppend(req *proto.AppendExtentKeysRequest, p *Packet) (err error)
+	ExtentsReplace(req *proto.ReplaceExtentKeysRequest, p *Packet) (err error)
+	GetFragmentedInodes(req *proto.GetFragmentedInodesRequest, p *Packet) (err error)
+	ExtentsRemap(req *proto.RemapExtentKeysRequest, p *Packet) (err error)
+	InodeModify(req *proto.InodeModifyRequest, p *Packet) (err error)
 	// ExtentsDelete(req *proto.DelExtentKeyRequest, p *Packet) (err error)
 }
 
@@ -258,10 +262,13 @@ type OpPartition interface {
 	UpdatePartition(req *UpdatePartitionReq, resp *UpdatePartitionResp) (err error)
 	DeleteRaft() error
 	IsExsitPeer(peer proto.Peer) bool
+	GetPeer(nodeID uint64) (peer proto.Peer, ok bool)
+	CheckLearnerCaughtUp(peerID, maxLag uint64) error
 	TryToLeader(groupID uint64) error
 	CanRemoveRaftMember(peer proto.Peer) error
 	IsEquareCreateMetaPartitionRequst(request *proto.CreateMetaPartitionRequest) (err error)
 	GetUniqID(p *Packet, num uint32) (err error)
+	ReadChangeLog(from uint64, limit int) (resp *proto.ChangeLogResponse, err error)
 }
 
 // MetaPartition defines the interface for the meta partition operations.
@@ -514,6 +521,7 @@ type metaPartition struct {
 	verUpdateChan           chan []byte
 	enableAuditLog          bool
 	recycleInodeDelFileFlag atomicutil.Flag
+	changeLog               *changeLog
 }
 
 func (mp *metaPartition) IsForbidden() bool {
@@ -701,6 +709,7 @@ func (mp *metaPartition) onStart(isCreate bool) (err error) {
 			mp.config.PartitionId, err.Error())
 		return
 	}
+	mp.changeLog = newChangeLog(ChangeLogCapacity(), mp.applyID)
 	mp.startScheduleTask()
 	if err = mp.startFreeList(); err != nil {
 		err = errors.NewErrorf("[onStart] start free list id=%d: %s",
@@ -721,6 +730,8 @@ func (mp *metaPartition) onStart(isCreate bool) (err error) {
 	}
 
 	mp.vol.volDeleteLockTime = volumeInfo.DeleteLockTime
+	mp.vol.enableDedup = volumeInfo.EnableDedup
+	mp.vol.enableDefrag = volumeInfo.EnableDefrag
 
 	go mp.runVersionOp()
 
@@ -804,7 +815,8 @@ func (mp *metaPartition) startRaft() (err error) {
 		addr := strings.Split(peer.Addr, ":")[0]
 		rp := raftstore.PeerAddress{
 			Peer: raftproto.Peer{
-				ID: peer.ID,
+				ID:   peer.ID,
+				Type: raftproto.PeerType(peer.Type),
 			},
 			Address:       addr,
 			HeartbeatPort: heartbeatPort,
@@ -1291,6 +1303,19 @@ func (mp *metaPartition) IsExsitPeer(peer proto.Peer) bool {
 	return false
 }
 
+func (mp *metaPartition) GetPeer(nodeID uint64) (peer proto.Peer, ok bool) {
+	for _, peer = range mp.config.Peers {
+		if peer.ID == nodeID {
+			return peer, true
+		}
+	}
+	return proto.Peer{}, false
+}
+
+func (mp *metaPartition) CheckLearnerCaughtUp(peerID, maxLag uint64) error {
+	return raftstore.CheckLearnerCaughtUp(mp.raftPartition.Status(), peerID, maxLag)
+}
+
 func (mp *metaPartition) TryToLeader(groupID uint64) error {
 	return mp.raftPartition.TryToLeader(groupID)
 }
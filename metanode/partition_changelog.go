@@ -0,0 +1,269 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const defaultChangeLogReadLimit = 10000
+
+// changeLogCapacity is the number of change records each meta partition keeps in memory, 0 disables the change log.
+var changeLogCapacity uint64
+
+func ChangeLogCapacity() uint64 {
+	return atomic.LoadUint64(&changeLogCapacity)
+}
+
+func updateChangeLogCapacity(val uint64) {
+	atomic.StoreUint64(&changeLogCapacity, val)
+}
+
+// changeLog is a ring of the namespace and data changes applied by the partition, it is consumed
+// by the volume mirror to replay the changes to another cluster. The log is not persisted, after
+// restart or snapshot installing only the changes after the current apply id are available.
+type changeLog struct {
+	sync.RWMutex
+	records []*proto.ChangeRecord
+	head    int
+	count   int
+	since   uint64
+}
+
+func newChangeLog(capacity uint64, since uint64) *changeLog {
+	if capacity == 0 {
+		return nil
+	}
+	return &changeLog{
+		records: make([]*proto.ChangeRecord, capacity),
+		since:   since,
+	}
+}
+
+func (cl *changeLog) append(r *proto.ChangeRecord) {
+	if cl == nil {
+		return
+	}
+	cl.Lock()
+	defer cl.Unlock()
+	if r.Index <= cl.since {
+		return
+	}
+	pos := (cl.head + cl.count) % len(cl.records)
+	if cl.count == len(cl.records) {
+		cl.since = cl.records[cl.head].Index
+		cl.head = (cl.head + 1) % len(cl.records)
+	} else {
+		cl.count++
+	}
+	cl.records[pos] = r
+}
+
+func (cl *changeLog) reset(since uint64) {
+	if cl == nil {
+		return
+	}
+	cl.Lock()
+	cl.head, cl.count, cl.since = 0, 0, since
+	for i := range cl.records {
+		cl.records[i] = nil
+	}
+	cl.Unlock()
+}
+
+// read returns at most limit records whose index is larger than from.
+func (cl *changeLog) read(from uint64, limit int) (since uint64, records []*proto.ChangeRecord) {
+	cl.RLock()
+	defer cl.RUnlock()
+	start := sort.Search(cl.count, func(i int) bool {
+		return cl.records[(cl.head+i)%len(cl.records)].Index > from
+	})
+	records = make([]*proto.ChangeRecord, 0)
+	for i := start; i < cl.count; i++ {
+		r := cl.records[(cl.head+i)%len(cl.records)]
+		// records of one raft entry are returned together, the consumer checkpoints by index
+		if len(records) >= limit && r.Index != records[len(records)-1].Index {
+			break
+		}
+		records = append(records, r)
+	}
+	return cl.since, records
+}
+
+func dentryChange(typ uint8, index uint64, d *Dentry) *proto.ChangeRecord {
+	return &proto.ChangeRecord{
+		Index:    index,
+		Type:     typ,
+		Inode:    d.Inode,
+		ParentID: d.ParentId,
+		Name:     d.Name,
+		Mode:     d.Type,
+	}
+}
+
+func writeChange(index, inode, offset, size uint64) *proto.ChangeRecord {
+	return &proto.ChangeRecord{
+		Index:  index,
+		Type:   proto.ChangeWrite,
+		Inode:  inode,
+		Offset: offset,
+		Size:   size,
+	}
+}
+
+// applyStatus returns the status of the response of an applied raft command.
+func applyStatus(resp interface{}) uint8 {
+	switch r := resp.(type) {
+	case uint8:
+		return r
+	case *InodeResponse:
+		return r.Status
+	case *DentryResponse:
+		return r.Status
+	}
+	return proto.OpOk
+}
+
+// recordChange decodes the applied raft command again and records what it changed,
+// the commands rejected by the partition change nothing and are not recorded.
+func (mp *metaPartition) recordChange(msg *MetaItem, index uint64, resp interface{}) {
+	if mp.changeLog == nil || applyStatus(resp) != proto.OpOk {
+		return
+	}
+	var (
+		records []*proto.ChangeRecord
+		err     error
+	)
+	switch msg.Op {
+	case opFSMCreateDentry, opFSMDeleteDentry, opFSMUpdateDentry:
+		den := &Dentry{}
+		if err = den.Unmarshal(msg.V); err != nil {
+			break
+		}
+		typ := proto.ChangeCreateDentry
+		if msg.Op == opFSMDeleteDentry {
+			typ = proto.ChangeDeleteDentry
+		} else if msg.Op == opFSMUpdateDentry {
+			typ = proto.ChangeUpdateDentry
+		}
+		records = append(records, dentryChange(typ, index, den))
+	case opFSMUpdateDentryWithCond:
+		cond := &DentryUpdateWithCond{}
+		if err = json.Unmarshal(msg.V, cond); err != nil {
+			break
+		}
+		den := &Dentry{}
+		if err = den.Unmarshal(cond.Dentry); err != nil {
+			break
+		}
+		records = append(records, dentryChange(proto.ChangeUpdateDentry, index, den))
+	case opFSMDeleteDentryBatch:
+		var db DentryBatch
+		if db, err = DentryBatchUnmarshal(msg.V); err != nil {
+			break
+		}
+		resps, _ := resp.([]*DentryResponse)
+		for i, den := range db {
+			if i < len(resps) && resps[i].Status != proto.OpOk {
+				continue
+			}
+			records = append(records, dentryChange(proto.ChangeDeleteDentry, index, den))
+		}
+	case opFSMTxCreateDentry, opFSMTxDeleteDentry:
+		txDen := NewTxDentry(0, "", 0, 0, nil, nil)
+		if err = txDen.Unmarshal(msg.V); err != nil {
+			break
+		}
+		typ := proto.ChangeCreateDentry
+		if msg.Op == opFSMTxDeleteDentry {
+			typ = proto.ChangeDeleteDentry
+		}
+		records = append(records, dentryChange(typ, index, txDen.Dentry))
+	case opFSMTxUpdateDentry:
+		txUpdateDen := NewTxUpdateDentry(nil, nil, nil)
+		if err = txUpdateDen.Unmarshal(msg.V); err != nil {
+			break
+		}
+		records = append(records, dentryChange(proto.ChangeUpdateDentry, index, txUpdateDen.NewDentry))
+	case opFSMSetAttr:
+		req := &SetattrRequest{}
+		if err = json.Unmarshal(msg.V, req); err != nil {
+			break
+		}
+		records = append(records, &proto.ChangeRecord{Index: index, Type: proto.ChangeSetAttr, Inode: req.Inode})
+	case opFSMExtentsAdd, opFSMExtentsAddWithCheck, opFSMExtentSplit:
+		ino := NewInode(0, 0)
+		if err = ino.Unmarshal(msg.V); err != nil {
+			break
+		}
+		ino.Extents.Range(func(_ int, ek proto.ExtentKey) bool {
+			records = append(records, writeChange(index, ino.Inode, ek.FileOffset, uint64(ek.Size)))
+			return true
+		})
+	case opFSMObjExtentsAdd:
+		ino := NewInode(0, 0)
+		if err = ino.Unmarshal(msg.V); err != nil {
+			break
+		}
+		ino.ObjExtents.Range(func(ek proto.ObjExtentKey) bool {
+			records = append(records, writeChange(index, ino.Inode, ek.FileOffset, ek.Size))
+			return true
+		})
+	case opFSMInodeModify:
+		req := &proto.InodeModifyRequest{}
+		if err = json.Unmarshal(msg.V, req); err != nil {
+			break
+		}
+		records = append(records, writeChange(index, req.Inode, req.Offset, req.Size))
+	case opFSMSetXAttr, opFSMRemoveXAttr, opFSMUpdateXAttr:
+		var extend *Extend
+		if extend, err = NewExtendFromBytes(msg.V); err != nil {
+			break
+		}
+		records = append(records, &proto.ChangeRecord{Index: index, Type: proto.ChangeXAttr, Inode: extend.GetInode()})
+	case opFSMExtentTruncate:
+		ino := NewInode(0, 0)
+		if err = ino.Unmarshal(msg.V); err != nil {
+			break
+		}
+		records = append(records, &proto.ChangeRecord{Index: index, Type: proto.ChangeTruncate, Inode: ino.Inode, Size: ino.Size})
+	}
+	if err != nil {
+		log.LogWarnf("[recordChange] mp(%v) op(%v) index(%v) decode failed: %v", mp.config.PartitionId, msg.Op, index, err)
+		return
+	}
+	for _, r := range records {
+		mp.changeLog.append(r)
+	}
+}
+
+// ReadChangeLog returns the changes applied after raft index from.
+func (mp *metaPartition) ReadChangeLog(from uint64, limit int) (resp *proto.ChangeLogResponse, err error) {
+	if mp.changeLog == nil {
+		return nil, ErrChangeLogDisabled
+	}
+	resp = &proto.ChangeLogResponse{
+		PartitionID: mp.config.PartitionId,
+		ApplyID:     mp.getApplyID(),
+	}
+	resp.Since, resp.Records = mp.changeLog.read(from, limit)
+	return
+}
@@ -0,0 +1,117 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangeLogRing(t *testing.T) {
+	require.Nil(t, newChangeLog(0, 10))
+
+	cl := newChangeLog(4, 10)
+	cl.append(&proto.ChangeRecord{Index: 9})
+	for _, idx := range []uint64{11, 12, 12, 13} {
+		cl.append(&proto.ChangeRecord{Index: idx})
+	}
+	since, records := cl.read(0, 100)
+	require.Equal(t, uint64(10), since)
+	require.Len(t, records, 4)
+
+	// the records of index 12 are not split by the limit
+	_, records = cl.read(10, 2)
+	require.Len(t, records, 3)
+	require.Equal(t, uint64(12), records[2].Index)
+
+	// evicting the oldest record moves since forward
+	cl.append(&proto.ChangeRecord{Index: 14})
+	since, records = cl.read(11, 100)
+	require.Equal(t, uint64(11), since)
+	require.Len(t, records, 4)
+	require.Equal(t, uint64(14), records[3].Index)
+
+	cl.reset(20)
+	since, records = cl.read(0, 100)
+	require.Equal(t, uint64(20), since)
+	require.Len(t, records, 0)
+}
+
+func TestRecordChange(t *testing.T) {
+	mp := &metaPartition{config: &MetaPartitionConfig{PartitionId: 1}, changeLog: newChangeLog(16, 0)}
+
+	den := &Dentry{ParentId: 1, Name: "a", Inode: 100, Type: 0o755}
+	val, err := den.Marshal()
+	require.NoError(t, err)
+	mp.recordChange(&MetaItem{Op: opFSMCreateDentry, V: val}, 1, proto.OpOk)
+	mp.recordChange(&MetaItem{Op: opFSMDeleteDentry, V: val}, 2, &DentryResponse{Status: proto.OpOk})
+	// operations not changing namespace or data are not recorded
+	mp.recordChange(&MetaItem{Op: opFSMCreateInode, V: val}, 3, &InodeResponse{Status: proto.OpOk})
+	// nor are the rejected ones
+	mp.recordChange(&MetaItem{Op: opFSMCreateDentry, V: val}, 4, proto.OpExistErr)
+	mp.recordChange(&MetaItem{Op: opFSMDeleteDentry, V: val}, 5, &DentryResponse{Status: proto.OpNotExistErr})
+
+	_, records := mp.changeLog.read(0, 100)
+	require.Len(t, records, 2)
+	require.Equal(t, proto.ChangeCreateDentry, records[0].Type)
+	require.Equal(t, "a", records[0].Name)
+	require.Equal(t, uint64(100), records[0].Inode)
+	require.Equal(t, proto.ChangeDeleteDentry, records[1].Type)
+}
+
+func TestRecordOverwriteAndXAttrChange(t *testing.T) {
+	mp := &metaPartition{config: &MetaPartitionConfig{PartitionId: 1}, changeLog: newChangeLog(16, 0)}
+
+	val, err := json.Marshal(&proto.InodeModifyRequest{Inode: 100, Offset: 4096, Size: 512})
+	require.NoError(t, err)
+	mp.recordChange(&MetaItem{Op: opFSMInodeModify, V: val}, 1, proto.OpOk)
+
+	extend := NewExtend(100)
+	extend.Put([]byte("user.k"), []byte("v"), 0)
+	val, err = extend.Bytes()
+	require.NoError(t, err)
+	mp.recordChange(&MetaItem{Op: opFSMSetXAttr, V: val}, 2, nil)
+	mp.recordChange(&MetaItem{Op: opFSMRemoveXAttr, V: val}, 3, nil)
+
+	_, records := mp.changeLog.read(0, 100)
+	require.Len(t, records, 3)
+	require.Equal(t, proto.ChangeWrite, records[0].Type)
+	require.Equal(t, uint64(4096), records[0].Offset)
+	require.Equal(t, uint64(512), records[0].Size)
+	for _, r := range records[1:] {
+		require.Equal(t, proto.ChangeXAttr, r.Type)
+		require.Equal(t, uint64(100), r.Inode)
+	}
+}
+
+func TestRecordBatchDeleteChange(t *testing.T) {
+	mp := &metaPartition{config: &MetaPartitionConfig{PartitionId: 1}, changeLog: newChangeLog(16, 0)}
+
+	db := DentryBatch{
+		{ParentId: 1, Name: "a", Inode: 100},
+		{ParentId: 1, Name: "b", Inode: 101},
+	}
+	val, err := db.Marshal()
+	require.NoError(t, err)
+	mp.recordChange(&MetaItem{Op: opFSMDeleteDentryBatch, V: val}, 1,
+		[]*DentryResponse{{Status: proto.OpNotExistErr}, {Status: proto.OpOk}})
+
+	_, records := mp.changeLog.read(0, 100)
+	require.Len(t, records, 1)
+	require.Equal(t, "b", records[0].Name)
+}
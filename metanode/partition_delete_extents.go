@@ -330,6 +330,7 @@ func (mp *metaPartition) deleteExtentsFromList(fileList *synclist.SyncList) {
 					log.LogDebugf("[deleteExtentsFromList] partitionId=%d, delete old file status: %s", mp.config.PartitionId, status.State)
 				}
 			}
+			readOffset := cursor
 			cursor += uint64(rLen)
 			buff := bytes.NewBuffer(buf[:rLen])
 			for buff.Len() != 0 {
@@ -373,6 +374,7 @@ func (mp *metaPartition) deleteExtentsFromList(fileList *synclist.SyncList) {
 				eks = append(eks, &proto.DelExtentParam{
 					ExtentKey:          &ek,
 					IsSnapshotDeletion: ek.IsSplit(),
+					ReleaseToken:       proto.DelFileReleaseToken(mp.config.PartitionId, fileName, readOffset+uint64(rLen-lastUnread)),
 				})
 				deleteCnt++
 				needDeleteExtents[dpId] = eks
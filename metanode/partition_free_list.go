@@ -87,6 +87,8 @@ func (mp *metaPartition) UpdateVolumeView(dataView *proto.DataPartitionsView, vo
 	}
 	mp.vol.UpdatePartitions(convert(dataView))
 	mp.vol.volDeleteLockTime = volumeView.DeleteLockTime
+	mp.vol.enableDedup = volumeView.EnableDedup
+	mp.vol.enableDefrag = volumeView.EnableDefrag
 }
 
 func (mp *metaPartition) updateVolView(convert func(view *proto.DataPartitionsView) *DataPartitionsView) (err error) {
@@ -107,6 +109,8 @@ func (mp *metaPartition) updateVolView(convert func(view *proto.DataPartitionsVi
 		return
 	}
 	mp.vol.volDeleteLockTime = volView.DeleteLockTime
+	mp.vol.enableDedup = volView.EnableDedup
+	mp.vol.enableDefrag = volView.EnableDefrag
 	return nil
 }
 
@@ -328,6 +332,7 @@ func (mp *metaPartition) deleteMarkedInodes(inoSlice []uint64) {
 				exts = append(exts, &proto.DelExtentParam{
 					ExtentKey:          ext,
 					IsSnapshotDeletion: ext.IsSplit(),
+					ReleaseToken:       proto.InodeReleaseToken(inode.Inode, ext),
 				})
 			}
 			log.LogWritef("[deleteMarkedInodes] mp[%v] ino(%v) deleteExtent(%v)", mp.config.PartitionId, inode.Inode, len(inodeExts))
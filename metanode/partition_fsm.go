@@ -48,6 +48,11 @@ func (mp *metaPartition) Apply(command []byte, index uint64) (resp interface{},
 
 	mp.nonIdempotent.Lock()
 	defer mp.nonIdempotent.Unlock()
+	defer func() {
+		if err == nil {
+			mp.recordChange(msg, index, resp)
+		}
+	}()
 
 	switch msg.Op {
 	case opFSMCreateInode:
@@ -196,6 +201,23 @@ func (mp *metaPartition) Apply(command []byte, index uint64) (resp interface{},
 		}
 
 		resp = mp.fsmUpdateDentry(den)
+	case opFSMUpdateDentryWithCond:
+		cond := &DentryUpdateWithCond{}
+		if err = json.Unmarshal(msg.V, cond); err != nil {
+			return
+		}
+		den := &Dentry{}
+		if err = den.Unmarshal(cond.Dentry); err != nil {
+			return
+		}
+
+		status := mp.dentryInTx(den.ParentId, den.Name)
+		if status != proto.OpOk {
+			resp = &DentryResponse{Status: status}
+			return
+		}
+
+		resp = mp.fsmUpdateDentryWithCond(den, cond.OldIno)
 	case opFSMUpdatePartition:
 		req := &UpdatePartitionReq{}
 		if err = json.Unmarshal(msg.V, req); err != nil {
@@ -226,6 +248,24 @@ func (mp *metaPartition) Apply(command []byte, index uint64) (resp interface{},
 			return
 		}
 		resp = mp.fsmAppendObjExtents(ino)
+	case opFSMExtentsReplace:
+		req := &proto.ReplaceExtentKeysRequest{}
+		if err = json.Unmarshal(msg.V, req); err != nil {
+			return
+		}
+		resp = mp.fsmReplaceExtents(req)
+	case opFSMExtentsRemap:
+		req := &proto.RemapExtentKeysRequest{}
+		if err = json.Unmarshal(msg.V, req); err != nil {
+			return
+		}
+		resp = mp.fsmRemapExtents(req)
+	case opFSMInodeModify:
+		req := &proto.InodeModifyRequest{}
+		if err = json.Unmarshal(msg.V, req); err != nil {
+			return
+		}
+		resp = mp.fsmInodeModify(req)
 	case opFSMExtentsEmpty:
 		ino := NewInode(0, 0)
 		if err = ino.Unmarshal(msg.V); err != nil {
@@ -590,7 +630,11 @@ func (mp *metaPartition) ApplyMemberChange(confChange *raftproto.ConfChange, ind
 		}
 		updated, err = mp.confRemoveNode(req, index)
 	case raftproto.ConfUpdateNode:
-		// updated, err = mp.confUpdateNode(req, index)
+		req := &proto.PromoteMetaPartitionLearnerRequest{}
+		if err = json.Unmarshal(confChange.Context, req); err != nil {
+			return
+		}
+		updated = mp.confPromoteLearner(req, index)
 	default:
 		// do nothing
 	}
@@ -673,6 +717,7 @@ func (mp *metaPartition) ApplySnapshot(peers []raftproto.Peer, iter raftproto.Sn
 	defer func() {
 		if err == io.EOF {
 			mp.applyID = appIndexID
+			mp.changeLog.reset(appIndexID)
 			mp.config.UniqId = uniqID
 			mp.txProcessor.txManager.txIdAlloc.setTransactionID(txID)
 			mp.inodeTree = inodeTree
@@ -119,6 +119,20 @@ func (mp *metaPartition) confAddNode(req *proto.AddMetaPartitionRaftMemberReques
 	return
 }
 
+// confPromoteLearner promotes a learner to a voter.
+func (mp *metaPartition) confPromoteLearner(req *proto.PromoteMetaPartitionLearnerRequest, index uint64) (updated bool) {
+	for i, peer := range mp.config.Peers {
+		if peer.ID == req.PromotePeer.ID && peer.IsLearner() {
+			mp.config.Peers[i].Type = proto.PeerNormal
+			updated = true
+			break
+		}
+	}
+	log.LogInfof("action[confPromoteLearner] mp(%v) promote peer(%v) index(%v) updated(%v)",
+		mp.config.PartitionId, req.PromotePeer, index, updated)
+	return
+}
+
 func (mp *metaPartition) confRemoveNode(req *proto.RemoveMetaPartitionRaftMemberRequest, index uint64) (updated bool, err error) {
 	var canRemoveSelf bool
 	if canRemoveSelf, err = mp.canRemoveSelf(); err != nil {
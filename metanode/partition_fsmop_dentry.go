@@ -359,8 +359,22 @@ func (mp *metaPartition) fsmTxUpdateDentry(txUpDateDentry *TxUpdateDentry) (resp
 	return
 }
 
+// DentryUpdateWithCond is the raft command to update the dentry only if it still refers to the OldIno.
+type DentryUpdateWithCond struct {
+	Dentry []byte `json:"den"`
+	OldIno uint64 `json:"oldIno"`
+}
+
 func (mp *metaPartition) fsmUpdateDentry(dentry *Dentry) (
 	resp *DentryResponse,
+) {
+	return mp.fsmUpdateDentryWithCond(dentry, 0)
+}
+
+// fsmUpdateDentryWithCond updates the dentry to the new inode, if the oldIno is not zero,
+// the dentry must still refer to it, otherwise OpArgMismatchErr is returned.
+func (mp *metaPartition) fsmUpdateDentryWithCond(dentry *Dentry, oldIno uint64) (
+	resp *DentryResponse,
 ) {
 	resp = NewDentryResponse()
 	resp.Status = proto.OpOk
@@ -370,6 +384,10 @@ func (mp *metaPartition) fsmUpdateDentry(dentry *Dentry) (
 			return
 		}
 		d := item.(*Dentry)
+		if oldIno != 0 && d.Inode != oldIno {
+			resp.Status = proto.OpArgMismatchErr
+			return
+		}
 		if dentry.Inode == d.Inode {
 			return
 		}
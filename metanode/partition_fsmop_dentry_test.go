@@ -0,0 +1,47 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateDentryWithCond(t *testing.T) {
+	mp := NewMetaPartitionForTest()
+	require.Equal(t, uint8(proto.OpOk), mp.fsmCreateDentry(&Dentry{ParentId: 1, Name: "obj", Inode: 100, Type: 0o644}, true))
+
+	// the dentry has been replaced by others
+	resp := mp.fsmUpdateDentryWithCond(&Dentry{ParentId: 1, Name: "obj", Inode: 101}, 99)
+	require.Equal(t, uint8(proto.OpArgMismatchErr), resp.Status)
+
+	resp = mp.fsmUpdateDentryWithCond(&Dentry{ParentId: 1, Name: "obj", Inode: 101}, 100)
+	require.Equal(t, uint8(proto.OpOk), resp.Status)
+	require.Equal(t, uint64(100), resp.Msg.Inode)
+
+	// the concurrent writer holding the same condition fails
+	resp = mp.fsmUpdateDentryWithCond(&Dentry{ParentId: 1, Name: "obj", Inode: 102}, 100)
+	require.Equal(t, uint8(proto.OpArgMismatchErr), resp.Status)
+
+	resp = mp.fsmUpdateDentryWithCond(&Dentry{ParentId: 1, Name: "none", Inode: 102}, 100)
+	require.Equal(t, uint8(proto.OpNotExistErr), resp.Status)
+
+	// the update without condition is unconditional
+	resp = mp.fsmUpdateDentry(&Dentry{ParentId: 1, Name: "obj", Inode: 103})
+	require.Equal(t, uint8(proto.OpOk), resp.Status)
+	require.Equal(t, uint64(101), resp.Msg.Inode)
+}
@@ -775,6 +775,245 @@ func (mp *metaPartition) fsmClearInodeCache(ino *Inode) (status uint8) {
 	return
 }
 
+// fsmReplaceExtents moves the extents of the source inode to the inode and releases
+// the old extents of the inode. Inodes with snapshot versions are not supported.
+func (mp *metaPartition) fsmReplaceExtents(req *proto.ReplaceExtentKeysRequest) (status uint8) {
+	status = proto.OpOk
+	item := mp.inodeTree.CopyGet(NewInode(req.Inode, 0))
+	srcItem := mp.inodeTree.CopyGet(NewInode(req.SrcInode, 0))
+	if item == nil || srcItem == nil {
+		status = proto.OpNotExistErr
+		return
+	}
+	ino := item.(*Inode)
+	src := srcItem.(*Inode)
+	if ino.ShouldDelete() || src.ShouldDelete() {
+		status = proto.OpNotExistErr
+		return
+	}
+	if !proto.IsRegular(ino.Type) || !proto.IsRegular(src.Type) {
+		status = proto.OpArgMismatchErr
+		return
+	}
+
+	ino.Lock()
+	defer ino.Unlock()
+	src.Lock()
+	defer src.Unlock()
+
+	if !ino.isEmptyVerList() || !src.isEmptyVerList() {
+		log.LogWarnf("action[fsmReplaceExtents] mp[%v] ino[%v] srcIno[%v] has snapshot versions",
+			mp.config.PartitionId, ino.Inode, src.Inode)
+		status = proto.OpArgMismatchErr
+		return
+	}
+	if ino.Size != src.Size {
+		log.LogWarnf("action[fsmReplaceExtents] mp[%v] ino[%v] size(%v) srcIno[%v] size(%v) mismatch",
+			mp.config.PartitionId, ino.Inode, ino.Size, src.Inode, src.Size)
+		status = proto.OpArgMismatchErr
+		return
+	}
+	// the overwrites keep the extent keys, so the generation and the modify time are checked as well
+	if ino.Generation != req.Generation || ino.ModifyTime != req.ModifyTime {
+		log.LogWarnf("action[fsmReplaceExtents] mp[%v] ino[%v] modified, gen(%v) mtime(%v) req gen(%v) mtime(%v)",
+			mp.config.PartitionId, ino.Inode, ino.Generation, ino.ModifyTime, req.Generation, req.ModifyTime)
+		status = proto.OpConflictExtentsErr
+		return
+	}
+	oldEks := ino.Extents.CopyExtents()
+	if !isSameExtents(oldEks, req.OldExtents) {
+		log.LogWarnf("action[fsmReplaceExtents] mp[%v] ino[%v] extents changed, current(%v) req(%v)",
+			mp.config.PartitionId, ino.Inode, len(oldEks), len(req.OldExtents))
+		status = proto.OpConflictExtentsErr
+		return
+	}
+	newEks := src.Extents.CopyExtents()
+	for _, ek := range append(oldEks, newEks...) {
+		if ek.IsSplit() || ek.GetSeq() != 0 {
+			status = proto.OpArgMismatchErr
+			return
+		}
+	}
+
+	ino.Extents = NewSortedExtentsFromEks(newEks)
+	ino.Generation++
+	src.Extents = NewSortedExtents()
+	mp.updateUsedInfo(-int64(src.Size), 0, src.Inode)
+	src.Size = 0
+	src.Generation++
+	mp.uidManager.minusUidSpace(ino.Uid, ino.Inode, oldEks)
+	if src.Uid != ino.Uid {
+		mp.uidManager.minusUidSpace(src.Uid, src.Inode, newEks)
+		mp.uidManager.addUidSpace(ino.Uid, ino.Inode, newEks)
+	}
+
+	log.LogInfof("action[fsmReplaceExtents] mp[%v] ino[%v] replace %v extents with %v extents of srcIno[%v]",
+		mp.config.PartitionId, ino.Inode, len(oldEks), len(newEks), src.Inode)
+	if len(oldEks) > 0 {
+		mp.extDelCh <- oldEks
+	}
+	return
+}
+
+// fsmRemapExtents maps extent keys of the inode to the deduplicated extents. Nothing is changed
+// unless the inode is not modified since verified and all the old extent keys are found. An old extent no longer referenced is released.
+func (mp *metaPartition) fsmRemapExtents(req *proto.RemapExtentKeysRequest) (status uint8) {
+	status = proto.OpOk
+	item := mp.inodeTree.CopyGet(NewInode(req.Inode, 0))
+	if item == nil {
+		status = proto.OpNotExistErr
+		return
+	}
+	ino := item.(*Inode)
+	if ino.ShouldDelete() {
+		status = proto.OpNotExistErr
+		return
+	}
+	if !proto.IsRegular(ino.Type) {
+		status = proto.OpArgMismatchErr
+		return
+	}
+
+	ino.Lock()
+	defer ino.Unlock()
+
+	if !ino.isEmptyVerList() {
+		log.LogWarnf("action[fsmRemapExtents] mp[%v] ino[%v] has snapshot versions", mp.config.PartitionId, ino.Inode)
+		status = proto.OpArgMismatchErr
+		return
+	}
+	// the overwrites keep the extent keys, so the generation and the modify time are checked as well
+	if ino.Generation != req.Generation || ino.ModifyTime != req.ModifyTime {
+		log.LogWarnf("action[fsmRemapExtents] mp[%v] ino[%v] modified, gen(%v) mtime(%v) req gen(%v) mtime(%v)",
+			mp.config.PartitionId, ino.Inode, ino.Generation, ino.ModifyTime, req.Generation, req.ModifyTime)
+		status = proto.OpConflictExtentsErr
+		return
+	}
+	eks := ino.Extents.CopyExtents()
+	delEks := make([]proto.ExtentKey, 0, len(req.Remaps))
+	for _, remap := range req.Remaps {
+		idx := -1
+		for i := range eks {
+			if isSameExtent(&eks[i], &remap.Old) {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			log.LogWarnf("action[fsmRemapExtents] mp[%v] ino[%v] extent %v not found",
+				mp.config.PartitionId, ino.Inode, remap.Old)
+			status = proto.OpConflictExtentsErr
+			return
+		}
+		if eks[idx].IsSplit() || eks[idx].GetSeq() != 0 {
+			status = proto.OpArgMismatchErr
+			return
+		}
+		if eks[idx].PartitionId != remap.New.PartitionId || eks[idx].ExtentId != remap.New.ExtentId {
+			delEks = append(delEks, eks[idx])
+		}
+		eks[idx] = remap.New
+	}
+
+	ino.Extents = NewSortedExtentsFromEks(eks)
+	ino.Generation++
+	mp.updateDedupFingerprints(ino.Inode, eks, req.Remaps)
+	log.LogInfof("action[fsmRemapExtents] mp[%v] ino[%v] remap %v extents, release %v extents",
+		mp.config.PartitionId, ino.Inode, len(req.Remaps), len(delEks))
+	if len(delEks) > 0 {
+		mp.extDelCh <- delEks
+	}
+	return
+}
+
+// fsmInodeModify updates the modify time and the generation of the inode overwritten in place.
+func (mp *metaPartition) fsmInodeModify(req *proto.InodeModifyRequest) (status uint8) {
+	status = proto.OpOk
+	item := mp.inodeTree.CopyGet(NewInode(req.Inode, 0))
+	if item == nil {
+		status = proto.OpNotExistErr
+		return
+	}
+	ino := item.(*Inode)
+	if ino.ShouldDelete() {
+		status = proto.OpNotExistErr
+		return
+	}
+	if !proto.IsRegular(ino.Type) {
+		status = proto.OpArgMismatchErr
+		return
+	}
+
+	ino.Lock()
+	defer ino.Unlock()
+
+	if req.ModifyTime > ino.ModifyTime {
+		ino.ModifyTime = req.ModifyTime
+	}
+	ino.Generation++
+	return
+}
+
+// updateDedupFingerprints records the fingerprints of the remapped extents in the xattr of the inode,
+// the fingerprints of the extents no longer referenced by the inode are dropped.
+func (mp *metaPartition) updateDedupFingerprints(inode uint64, eks []proto.ExtentKey, remaps []proto.ExtentRemap) {
+	fingerprints := make(map[string]string)
+	if item := mp.extendTree.Get(NewExtend(inode)); item != nil {
+		if value, ok := item.(*Extend).Get([]byte(proto.DedupFingerprintXAttr)); ok {
+			if err := json.Unmarshal(value, &fingerprints); err != nil {
+				log.LogWarnf("action[updateDedupFingerprints] mp[%v] ino[%v] invalid fingerprints, err(%v)",
+					mp.config.PartitionId, inode, err)
+			}
+		}
+	}
+	changed := false
+	for _, remap := range remaps {
+		if remap.Fingerprint != "" {
+			fingerprints[proto.DedupFingerprintKey(remap.New.PartitionId, remap.New.ExtentId)] = remap.Fingerprint
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+	referenced := make(map[string]struct{}, len(eks))
+	for _, ek := range eks {
+		referenced[proto.DedupFingerprintKey(ek.PartitionId, ek.ExtentId)] = struct{}{}
+	}
+	for key := range fingerprints {
+		if _, ok := referenced[key]; !ok {
+			delete(fingerprints, key)
+		}
+	}
+	value, _ := json.Marshal(fingerprints)
+	extend := NewExtend(inode)
+	extend.Put([]byte(proto.DedupFingerprintXAttr), value, 0)
+	if err := mp.fsmSetXAttr(extend); err != nil {
+		log.LogWarnf("action[updateDedupFingerprints] mp[%v] ino[%v] set fingerprints, err(%v)",
+			mp.config.PartitionId, inode, err)
+	}
+}
+
+func isSameExtent(ek, other *proto.ExtentKey) bool {
+	return ek.PartitionId == other.PartitionId &&
+		ek.ExtentId == other.ExtentId &&
+		ek.ExtentOffset == other.ExtentOffset &&
+		ek.FileOffset == other.FileOffset &&
+		ek.Size == other.Size
+}
+
+func isSameExtents(eks, other []proto.ExtentKey) bool {
+	if len(eks) != len(other) {
+		return false
+	}
+	for idx := range eks {
+		if !isSameExtent(&eks[idx], &other[idx]) {
+			return false
+		}
+	}
+	return true
+}
+
 // attion: unmarshal error will disard extent
 func (mp *metaPartition) fsmSendToChan(val []byte, v3 bool) (status uint8) {
 	sortExtents := NewSortedExtents()
@@ -0,0 +1,75 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"fmt"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// getObjectLockXAttr returns the object lock attributes of the regular file, or nil if it has none.
+func (mp *metaPartition) getObjectLockXAttr(ino uint64) *proto.XAttrInfo {
+	item := mp.extendTree.Get(NewExtend(ino))
+	if item == nil {
+		return nil
+	}
+	extend := item.(*Extend)
+	xattr := &proto.XAttrInfo{Inode: ino, XAttrs: make(map[string]string)}
+	for _, key := range []string{proto.ObjectLockXAttrKey, proto.ObjectLockModeXAttrKey, proto.ObjectLegalHoldXAttrKey} {
+		if value, exist := extend.Get([]byte(key)); exist {
+			xattr.XAttrs[key] = string(value)
+		}
+	}
+	if len(xattr.XAttrs) == 0 {
+		return nil
+	}
+	// the object lock configuration of the bucket is stored on the root directory
+	if inoItem := mp.inodeTree.Get(NewInode(ino, 0)); inoItem == nil || !proto.IsRegular(inoItem.(*Inode).Type) {
+		return nil
+	}
+	return xattr
+}
+
+// checkObjectLock returns an error if the inode is protected by object lock, so it cannot be
+// removed or truncated. The clients check the lock as well, while the check here enforces it
+// on the clients which skip it.
+func (mp *metaPartition) checkObjectLock(ino uint64, bypassGovernance bool) (err error) {
+	xattr := mp.getObjectLockXAttr(ino)
+	if err = proto.CheckObjectLockXAttr(xattr, bypassGovernance); err != nil {
+		log.LogWarnf("action[checkObjectLock] mp[%v] ino[%v] retainUntilDate(%v) mode(%v) legalHold(%v) err(%v)",
+			mp.config.PartitionId, ino, string(xattr.Get(proto.ObjectLockXAttrKey)),
+			string(xattr.Get(proto.ObjectLockModeXAttrKey)), string(xattr.Get(proto.ObjectLegalHoldXAttrKey)), err)
+		return fmt.Errorf("inode[%v] is protected by object lock: %v", ino, err)
+	}
+	return
+}
+
+// checkObjectLockXAttrUpdate returns an error if the update of the extended attribute of the inode
+// shortens or removes its retention, nil value means the removal of the attribute.
+func (mp *metaPartition) checkObjectLockXAttrUpdate(ino uint64, key string, value []byte, bypassGovernance bool) (err error) {
+	if !proto.IsObjectLockXAttr(key) {
+		return
+	}
+	xattr := mp.getObjectLockXAttr(ino)
+	if err = proto.CheckObjectLockXAttrUpdate(xattr, key, value, bypassGovernance); err != nil {
+		log.LogWarnf("action[checkObjectLockXAttrUpdate] mp[%v] ino[%v] retainUntilDate(%v) mode(%v) key(%v) value(%v) err(%v)",
+			mp.config.PartitionId, ino, string(xattr.Get(proto.ObjectLockXAttrKey)),
+			string(xattr.Get(proto.ObjectLockModeXAttrKey)), key, string(value), err)
+		return fmt.Errorf("the retention of inode[%v] cannot be shortened: %v", ino, err)
+	}
+	return
+}
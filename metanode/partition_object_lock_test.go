@@ -0,0 +1,78 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func newObjectLockTestInode(mp *metaPartition, id uint64, mode uint32, attrs map[string]string) {
+	mp.inodeTree.ReplaceOrInsert(NewInode(id, mode), true)
+	extend := NewExtend(id)
+	for key, value := range attrs {
+		extend.Put([]byte(key), []byte(value), 0)
+	}
+	mp.extendTree.ReplaceOrInsert(extend, true)
+}
+
+func TestCheckObjectLock(t *testing.T) {
+	mp := newPartition(&MetaPartitionConfig{PartitionId: 10020, VolName: VolNameForTest}, manager)
+	future := strconv.FormatInt(time.Now().Add(time.Hour).UnixNano(), 10)
+	later := strconv.FormatInt(time.Now().Add(2*time.Hour).UnixNano(), 10)
+	past := strconv.FormatInt(time.Now().Add(-time.Hour).UnixNano(), 10)
+
+	newObjectLockTestInode(mp, 10, 0o644, map[string]string{
+		proto.ObjectLockXAttrKey: future, proto.ObjectLockModeXAttrKey: proto.ObjectLockGovernanceMode,
+	})
+	newObjectLockTestInode(mp, 11, 0o644, map[string]string{
+		proto.ObjectLockXAttrKey: future, proto.ObjectLockModeXAttrKey: proto.ObjectLockComplianceMode,
+	})
+	newObjectLockTestInode(mp, 12, 0o644, map[string]string{proto.ObjectLegalHoldXAttrKey: proto.ObjectLegalHoldOn})
+	newObjectLockTestInode(mp, 13, 0o644, map[string]string{proto.ObjectLockXAttrKey: past})
+	newObjectLockTestInode(mp, 14, 0o644, map[string]string{"user.key": "value"})
+	// the object lock configuration of the bucket on the directory
+	newObjectLockTestInode(mp, 15, proto.Mode(os.ModeDir|0o755), map[string]string{proto.ObjectLockXAttrKey: `{"ObjectLockEnabled":"Enabled"}`})
+
+	require.Error(t, mp.checkObjectLock(10, false))
+	require.NoError(t, mp.checkObjectLock(10, true))
+	require.Error(t, mp.checkObjectLock(11, true))
+	require.Error(t, mp.checkObjectLock(12, true))
+	require.NoError(t, mp.checkObjectLock(13, false))
+	require.NoError(t, mp.checkObjectLock(14, false))
+	require.NoError(t, mp.checkObjectLock(15, false))
+	require.NoError(t, mp.checkObjectLock(16, false))
+
+	// the retention can only be extended, or changed from GOVERNANCE to COMPLIANCE mode
+	require.NoError(t, mp.checkObjectLockXAttrUpdate(10, proto.ObjectLockXAttrKey, []byte(later), false))
+	require.Error(t, mp.checkObjectLockXAttrUpdate(10, proto.ObjectLockXAttrKey, []byte(past), false))
+	require.Error(t, mp.checkObjectLockXAttrUpdate(10, proto.ObjectLockXAttrKey, nil, false))
+	require.NoError(t, mp.checkObjectLockXAttrUpdate(10, proto.ObjectLockXAttrKey, nil, true))
+	require.NoError(t, mp.checkObjectLockXAttrUpdate(10, proto.ObjectLockModeXAttrKey, []byte(proto.ObjectLockComplianceMode), false))
+	require.Error(t, mp.checkObjectLockXAttrUpdate(11, proto.ObjectLockModeXAttrKey, []byte(proto.ObjectLockGovernanceMode), true))
+	require.Error(t, mp.checkObjectLockXAttrUpdate(11, proto.ObjectLockModeXAttrKey, nil, true))
+	require.Error(t, mp.checkObjectLockXAttrUpdate(11, proto.ObjectLockXAttrKey, []byte(past), true))
+
+	// the legal hold and the other attributes are not checked
+	require.NoError(t, mp.checkObjectLockXAttrUpdate(11, proto.ObjectLegalHoldXAttrKey, nil, false))
+	require.NoError(t, mp.checkObjectLockXAttrUpdate(11, "user.key", nil, false))
+	require.NoError(t, mp.checkObjectLockXAttrUpdate(13, proto.ObjectLockXAttrKey, nil, false))
+	require.NoError(t, mp.checkObjectLockXAttrUpdate(15, proto.ObjectLockXAttrKey, []byte("{}"), false))
+}
@@ -487,7 +487,16 @@ func (mp *metaPartition) UpdateDentry(req *UpdateDentryReq, p *Packet, remoteAdd
 		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
 		return
 	}
-	resp, err := mp.submit(opFSMUpdateDentry, val)
+	op := uint32(opFSMUpdateDentry)
+	if req.OldIno != 0 {
+		// the update is conditional, check the old inode in the same raft command
+		op = opFSMUpdateDentryWithCond
+		if val, err = json.Marshal(&DentryUpdateWithCond{Dentry: val, OldIno: req.OldIno}); err != nil {
+			p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+			return
+		}
+	}
+	resp, err := mp.submit(op, val)
 	if err != nil {
 		p.PacketErrorWithBody(proto.OpAgain, []byte(err.Error()))
 		return
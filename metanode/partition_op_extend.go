@@ -98,6 +98,14 @@ func (mp *metaPartition) UpdateXAttr(req *proto.UpdateXAttrRequest, p *Packet) (
 }
 
 func (mp *metaPartition) SetXAttr(req *proto.SetXAttrRequest, p *Packet) (err error) {
+	if proto.IsObjectLockXAttr(req.Key) {
+		mp.xattrLock.Lock()
+		defer mp.xattrLock.Unlock()
+		if err = mp.checkObjectLockXAttrUpdate(req.Inode, req.Key, []byte(req.Value), req.BypassGovernance); err != nil {
+			p.PacketErrorWithBody(proto.OpNotPerm, []byte(err.Error()))
+			return
+		}
+	}
 	extend := NewExtend(req.Inode)
 	extend.Put([]byte(req.Key), []byte(req.Value), mp.verSeq)
 	if _, err = mp.putExtend(opFSMSetXAttr, extend); err != nil {
@@ -109,8 +117,19 @@ func (mp *metaPartition) SetXAttr(req *proto.SetXAttrRequest, p *Packet) (err er
 }
 
 func (mp *metaPartition) BatchSetXAttr(req *proto.BatchSetXAttrRequest, p *Packet) (err error) {
+	for key := range req.Attrs {
+		if proto.IsObjectLockXAttr(key) {
+			mp.xattrLock.Lock()
+			defer mp.xattrLock.Unlock()
+			break
+		}
+	}
 	extend := NewExtend(req.Inode)
 	for key, val := range req.Attrs {
+		if err = mp.checkObjectLockXAttrUpdate(req.Inode, key, []byte(val), false); err != nil {
+			p.PacketErrorWithBody(proto.OpNotPerm, []byte(err.Error()))
+			return
+		}
 		extend.Put([]byte(key), []byte(val), mp.verSeq)
 	}
 
@@ -207,6 +226,14 @@ func (mp *metaPartition) BatchGetXAttr(req *proto.BatchGetXAttrRequest, p *Packe
 }
 
 func (mp *metaPartition) RemoveXAttr(req *proto.RemoveXAttrRequest, p *Packet) (err error) {
+	if proto.IsObjectLockXAttr(req.Key) {
+		mp.xattrLock.Lock()
+		defer mp.xattrLock.Unlock()
+		if err = mp.checkObjectLockXAttrUpdate(req.Inode, req.Key, nil, req.BypassGovernance); err != nil {
+			p.PacketErrorWithBody(proto.OpNotPerm, []byte(err.Error()))
+			return
+		}
+	}
 	extend := NewExtend(req.Inode)
 	extend.Put([]byte(req.Key), nil, req.VerSeq)
 	if _, err = mp.putExtend(opFSMRemoveXAttr, extend); err != nil {
@@ -22,10 +22,12 @@ import (
 	"time"
 
 	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/storage"
 	"github.com/cubefs/cubefs/util/auditlog"
 	"github.com/cubefs/cubefs/util/errors"
 	"github.com/cubefs/cubefs/util/exporter"
 	"github.com/cubefs/cubefs/util/log"
+	"github.com/cubefs/cubefs/util/timeutil"
 )
 
 func (mp *metaPartition) CheckQuota(inodeId uint64, p *Packet) (iParm *Inode, inode *Inode, err error) {
@@ -166,6 +168,158 @@ func (mp *metaPartition) ExtentAppendWithCheck(req *proto.AppendExtentKeyWithChe
 	return
 }
 
+// ExtentsReplace replaces the extents of an inode with the extents of the source inode,
+// which is left empty. It fails with OpConflictExtentsErr if the extents, the generation or
+// the modify time of the inode have been changed since the old extents were read.
+func (mp *metaPartition) ExtentsReplace(req *proto.ReplaceExtentKeysRequest, p *Packet) (err error) {
+	if !proto.IsHot(mp.volType) {
+		err = fmt.Errorf("only support hot vol")
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	if !mp.vol.enableDefrag {
+		err = fmt.Errorf("defrag is not enabled on vol %v", mp.config.VolName)
+		p.PacketErrorWithBody(proto.OpNotPerm, []byte(err.Error()))
+		return
+	}
+	if req.Inode == req.SrcInode {
+		err = fmt.Errorf("inode[%v] can not replace extents with itself", req.Inode)
+		p.PacketErrorWithBody(proto.OpArgMismatchErr, []byte(err.Error()))
+		return
+	}
+	val, err := json.Marshal(req)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	resp, err := mp.submit(opFSMExtentsReplace, val)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpAgain, []byte(err.Error()))
+		return
+	}
+	log.LogDebugf("ExtentsReplace: mp[%v] ino(%v) srcIno(%v) oldEks(%v) rspcode(%v)",
+		mp.config.PartitionId, req.Inode, req.SrcInode, len(req.OldExtents), resp.(uint8))
+	p.PacketErrorWithBody(resp.(uint8), nil)
+	return
+}
+
+// ExtentsRemap maps extent keys of an inode to other extents with the same content, which
+// is how duplicated extents are shared. The new extents must cover exactly the same ranges.
+func (mp *metaPartition) ExtentsRemap(req *proto.RemapExtentKeysRequest, p *Packet) (err error) {
+	if !proto.IsHot(mp.volType) {
+		err = fmt.Errorf("only support hot vol")
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	if !mp.vol.enableDedup {
+		err = fmt.Errorf("dedup is not enabled on vol %v", mp.config.VolName)
+		p.PacketErrorWithBody(proto.OpNotPerm, []byte(err.Error()))
+		return
+	}
+	for _, remap := range req.Remaps {
+		if remap.Old.FileOffset != remap.New.FileOffset || remap.Old.Size != remap.New.Size || remap.Old.CRC != remap.New.CRC ||
+			remap.New.ExtentOffset != 0 || storage.IsTinyExtent(remap.New.ExtentId) {
+			err = fmt.Errorf("inode[%v] invalid remap from %v to %v", req.Inode, remap.Old, remap.New)
+			p.PacketErrorWithBody(proto.OpArgMismatchErr, []byte(err.Error()))
+			return
+		}
+	}
+	val, err := json.Marshal(req)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	resp, err := mp.submit(opFSMExtentsRemap, val)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpAgain, []byte(err.Error()))
+		return
+	}
+	log.LogDebugf("ExtentsRemap: mp[%v] ino(%v) remaps(%v) rspcode(%v)",
+		mp.config.PartitionId, req.Inode, len(req.Remaps), resp.(uint8))
+	p.PacketErrorWithBody(resp.(uint8), nil)
+	return
+}
+
+// InodeModify records the in-place overwrite of an inode. The overwrites go to the data nodes
+// only, the modify time and the generation are updated here so that the defragmentation and
+// the change log consumers notice them.
+func (mp *metaPartition) InodeModify(req *proto.InodeModifyRequest, p *Packet) (err error) {
+	req.ModifyTime = timeutil.GetCurrentTimeUnix()
+	val, err := json.Marshal(req)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	resp, err := mp.submit(opFSMInodeModify, val)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpAgain, []byte(err.Error()))
+		return
+	}
+	log.LogDebugf("InodeModify: mp[%v] ino(%v) offset(%v) size(%v) rspcode(%v)",
+		mp.config.PartitionId, req.Inode, req.Offset, req.Size, resp.(uint8))
+	p.PacketErrorWithBody(resp.(uint8), nil)
+	return
+}
+
+// GetFragmentedInodes returns the files whose extent count or tiny extent count
+// reaches the given thresholds, or which reference the tiny extents to reclaim,
+// starting from the marker inode.
+func (mp *metaPartition) GetFragmentedInodes(req *proto.GetFragmentedInodesRequest, p *Packet) (err error) {
+	limit := int(req.Limit)
+	if limit <= 0 || limit > defaultMaxFragmentedInodes {
+		limit = defaultMaxFragmentedInodes
+	}
+	reclaim := make(map[uint64]map[uint64]struct{}, len(req.TinyExtents))
+	for dpID, extentIDs := range req.TinyExtents {
+		reclaim[dpID] = make(map[uint64]struct{}, len(extentIDs))
+		for _, extentID := range extentIDs {
+			reclaim[dpID][extentID] = struct{}{}
+		}
+	}
+	resp := &proto.GetFragmentedInodesResponse{}
+	mp.inodeTree.AscendGreaterOrEqual(NewInode(req.Marker, 0), func(item BtreeItem) bool {
+		ino := item.(*Inode)
+		if len(resp.Inodes) >= limit {
+			resp.NextMarker = ino.Inode
+			return false
+		}
+		if !proto.IsRegular(ino.Type) || ino.ShouldDelete() {
+			return true
+		}
+		var ekCnt, tinyCnt uint32
+		var referenced bool
+		ino.DoReadFunc(func() {
+			ino.Extents.Range(func(_ int, ek proto.ExtentKey) bool {
+				ekCnt++
+				if storage.IsTinyExtent(ek.ExtentId) {
+					tinyCnt++
+					if _, ok := reclaim[ek.PartitionId][ek.ExtentId]; ok {
+						referenced = true
+					}
+				}
+				return true
+			})
+		})
+		if referenced || (req.MinExtents > 0 && ekCnt >= req.MinExtents) ||
+			(req.MinTinyExtents > 0 && tinyCnt >= req.MinTinyExtents) {
+			resp.Inodes = append(resp.Inodes, &proto.FragmentedInode{
+				Inode:           ino.Inode,
+				Size:            ino.Size,
+				ExtentCount:     ekCnt,
+				TinyExtentCount: tinyCnt,
+			})
+		}
+		return true
+	})
+	reply, err := json.Marshal(resp)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	p.PacketOkWithBody(reply)
+	return
+}
+
 func (mp *metaPartition) SetTxInfo(info []*proto.TxInfo) {
 	for _, txInfo := range info {
 		if txInfo.Volume != mp.config.VolName {
@@ -488,6 +642,10 @@ func (mp *metaPartition) ExtentsTruncate(req *ExtentsTruncateReq, p *Packet, rem
 		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
 		return
 	}
+	if err = mp.checkObjectLock(req.Inode, false); err != nil {
+		p.PacketErrorWithBody(proto.OpNotPerm, []byte(err.Error()))
+		return
+	}
 	i := item.(*Inode)
 	status := mp.isOverQuota(req.Inode, req.Size > i.Size, false)
 	if status != 0 {
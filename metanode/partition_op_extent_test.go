@@ -0,0 +1,222 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func newReplaceTestInode(mp *metaPartition, id uint64, eks []proto.ExtentKey) *Inode {
+	ino := NewInode(id, 0o644)
+	for _, ek := range eks {
+		ino.Extents.eks = append(ino.Extents.eks, ek)
+		ino.Size += uint64(ek.Size)
+	}
+	mp.inodeTree.ReplaceOrInsert(ino, true)
+	return ino
+}
+
+func newReplaceTestExtent(fileOffset, extentID, extentOffset uint64, size uint32) proto.ExtentKey {
+	return proto.ExtentKey{
+		FileOffset:   fileOffset,
+		PartitionId:  partitionId,
+		ExtentId:     extentID,
+		ExtentOffset: extentOffset,
+		Size:         size,
+	}
+}
+
+func TestFsmReplaceExtents(t *testing.T) {
+	mp := newPartition(&MetaPartitionConfig{PartitionId: 10010, VolName: VolNameForTest}, manager)
+
+	fragmented := []proto.ExtentKey{
+		newReplaceTestExtent(0, 1025, 0, 4096),
+		newReplaceTestExtent(4096, 1026, 0, 4096),
+		newReplaceTestExtent(8192, 1027, 0, 4096),
+	}
+	ino := newReplaceTestInode(mp, 10, fragmented)
+	src := newReplaceTestInode(mp, 11, []proto.ExtentKey{newReplaceTestExtent(0, 1028, 0, 12288)})
+
+	// a stale extent list must be rejected
+	req := &proto.ReplaceExtentKeysRequest{
+		Inode: ino.Inode, SrcInode: src.Inode, OldExtents: fragmented[:2],
+		Generation: ino.Generation, ModifyTime: ino.ModifyTime,
+	}
+	require.Equal(t, proto.OpConflictExtentsErr, mp.fsmReplaceExtents(req))
+	require.Equal(t, 3, ino.Extents.Len())
+	require.Equal(t, 0, len(mp.extDelCh))
+
+	// an overwrite keeps the extent keys but changes the generation or the modify time
+	req.OldExtents = fragmented
+	req.Generation = ino.Generation - 1
+	require.Equal(t, proto.OpConflictExtentsErr, mp.fsmReplaceExtents(req))
+	req.Generation = ino.Generation
+	req.ModifyTime = ino.ModifyTime - 1
+	require.Equal(t, proto.OpConflictExtentsErr, mp.fsmReplaceExtents(req))
+	require.Equal(t, 3, ino.Extents.Len())
+	require.Equal(t, 0, len(mp.extDelCh))
+
+	req.ModifyTime = ino.ModifyTime
+	require.Equal(t, proto.OpOk, mp.fsmReplaceExtents(req))
+	require.Equal(t, 1, ino.Extents.Len())
+	require.Equal(t, uint64(1028), ino.Extents.eks[0].ExtentId)
+	require.Equal(t, uint64(12288), ino.Size)
+	require.Equal(t, 0, src.Extents.Len())
+	require.Equal(t, uint64(0), src.Size)
+
+	delEks := <-mp.extDelCh
+	require.True(t, isSameExtents(fragmented, delEks))
+}
+
+func TestFsmInodeModifyConflictsReplace(t *testing.T) {
+	mp := newPartition(&MetaPartitionConfig{PartitionId: 10012, VolName: VolNameForTest}, manager)
+
+	eks := []proto.ExtentKey{newReplaceTestExtent(0, 1025, 0, 4096), newReplaceTestExtent(4096, 1026, 0, 4096)}
+	ino := newReplaceTestInode(mp, 10, eks)
+	src := newReplaceTestInode(mp, 11, []proto.ExtentKey{newReplaceTestExtent(0, 1028, 0, 8192)})
+	req := &proto.ReplaceExtentKeysRequest{
+		Inode: ino.Inode, SrcInode: src.Inode, OldExtents: eks,
+		Generation: ino.Generation, ModifyTime: ino.ModifyTime,
+	}
+
+	// the in-place overwrite during the copy makes the replacement stale
+	gen := ino.Generation
+	require.Equal(t, proto.OpOk, mp.fsmInodeModify(&proto.InodeModifyRequest{Inode: ino.Inode, Size: 512, ModifyTime: ino.ModifyTime}))
+	require.Equal(t, gen+1, ino.Generation)
+	require.Equal(t, proto.OpConflictExtentsErr, mp.fsmReplaceExtents(req))
+	require.Equal(t, 2, ino.Extents.Len())
+
+	require.Equal(t, proto.OpNotExistErr, mp.fsmInodeModify(&proto.InodeModifyRequest{Inode: 12}))
+}
+
+func TestFsmReplaceExtentsSizeMismatch(t *testing.T) {
+	mp := newPartition(&MetaPartitionConfig{PartitionId: 10011, VolName: VolNameForTest}, manager)
+
+	eks := []proto.ExtentKey{newReplaceTestExtent(0, 1025, 0, 4096)}
+	ino := newReplaceTestInode(mp, 10, eks)
+	src := newReplaceTestInode(mp, 11, []proto.ExtentKey{newReplaceTestExtent(0, 1028, 0, 1024)})
+
+	req := &proto.ReplaceExtentKeysRequest{Inode: ino.Inode, SrcInode: src.Inode, OldExtents: eks}
+	require.Equal(t, proto.OpArgMismatchErr, mp.fsmReplaceExtents(req))
+	require.Equal(t, uint64(1025), ino.Extents.eks[0].ExtentId)
+	require.Equal(t, 1, src.Extents.Len())
+}
+
+func TestGetFragmentedInodes(t *testing.T) {
+	mp := newPartition(&MetaPartitionConfig{PartitionId: 10012, VolName: VolNameForTest}, manager)
+
+	var normal, tiny []proto.ExtentKey
+	for i := uint64(0); i < 8; i++ {
+		normal = append(normal, newReplaceTestExtent(i*4096, 1025+i, 0, 4096))
+		tiny = append(tiny, newReplaceTestExtent(i*4096, storage.TinyExtentStartID, i*4096, 4096))
+	}
+	newReplaceTestInode(mp, 10, normal[:2])
+	newReplaceTestInode(mp, 11, normal)
+	newReplaceTestInode(mp, 12, tiny)
+
+	p := &Packet{}
+	req := &proto.GetFragmentedInodesRequest{MinExtents: 8, Limit: 1}
+	require.NoError(t, mp.GetFragmentedInodes(req, p))
+	resp := &proto.GetFragmentedInodesResponse{}
+	require.NoError(t, json.Unmarshal(p.Data, resp))
+	require.Equal(t, 1, len(resp.Inodes))
+	require.Equal(t, uint64(11), resp.Inodes[0].Inode)
+	require.Equal(t, uint64(12), resp.NextMarker)
+
+	req = &proto.GetFragmentedInodesRequest{Marker: resp.NextMarker, MinTinyExtents: 4}
+	require.NoError(t, mp.GetFragmentedInodes(req, p))
+	resp = &proto.GetFragmentedInodesResponse{}
+	require.NoError(t, json.Unmarshal(p.Data, resp))
+	require.Equal(t, 1, len(resp.Inodes))
+	require.Equal(t, uint32(8), resp.Inodes[0].TinyExtentCount)
+	require.Equal(t, uint64(0), resp.NextMarker)
+
+	// the files referencing the tiny extents to reclaim are listed regardless of the thresholds
+	newReplaceTestInode(mp, 13, tiny[:1])
+	req = &proto.GetFragmentedInodesRequest{
+		MinExtents:  100,
+		TinyExtents: map[uint64][]uint64{partitionId: {storage.TinyExtentStartID}},
+	}
+	require.NoError(t, mp.GetFragmentedInodes(req, p))
+	resp = &proto.GetFragmentedInodesResponse{}
+	require.NoError(t, json.Unmarshal(p.Data, resp))
+	require.Equal(t, 2, len(resp.Inodes))
+	require.Equal(t, uint64(12), resp.Inodes[0].Inode)
+	require.Equal(t, uint64(13), resp.Inodes[1].Inode)
+
+	req.TinyExtents = map[uint64][]uint64{partitionId: {storage.TinyExtentStartID + 1}}
+	require.NoError(t, mp.GetFragmentedInodes(req, p))
+	resp = &proto.GetFragmentedInodesResponse{}
+	require.NoError(t, json.Unmarshal(p.Data, resp))
+	require.Equal(t, 0, len(resp.Inodes))
+}
+
+func TestFsmRemapExtents(t *testing.T) {
+	mp := newPartition(&MetaPartitionConfig{PartitionId: 10013, VolName: VolNameForTest}, manager)
+
+	eks := []proto.ExtentKey{
+		newReplaceTestExtent(0, 1025, 0, 4096),
+		newReplaceTestExtent(4096, 1026, 0, 4096),
+	}
+	ino := newReplaceTestInode(mp, 10, eks)
+
+	shared := newReplaceTestExtent(4096, 2048, 0, 4096)
+
+	// nothing is changed if an old extent is missing
+	req := &proto.RemapExtentKeysRequest{Inode: ino.Inode, Remaps: []proto.ExtentRemap{
+		{Old: eks[0], New: eks[0], Fingerprint: "kept"},
+		{Old: newReplaceTestExtent(4096, 1027, 0, 4096), New: shared, Fingerprint: "shared"},
+	}, Generation: ino.Generation, ModifyTime: ino.ModifyTime}
+	require.Equal(t, proto.OpConflictExtentsErr, mp.fsmRemapExtents(req))
+	require.True(t, isSameExtents(eks, ino.Extents.CopyExtents()))
+	require.Nil(t, mp.extendTree.Get(NewExtend(ino.Inode)))
+
+	// nothing is changed if the inode is overwritten in place after verified
+	req.Remaps[1].Old = eks[1]
+	req.ModifyTime = ino.ModifyTime - 1
+	require.Equal(t, proto.OpConflictExtentsErr, mp.fsmRemapExtents(req))
+	req.ModifyTime = ino.ModifyTime
+	req.Generation = ino.Generation + 1
+	require.Equal(t, proto.OpConflictExtentsErr, mp.fsmRemapExtents(req))
+	require.True(t, isSameExtents(eks, ino.Extents.CopyExtents()))
+
+	req.Generation = ino.Generation
+	require.Equal(t, proto.OpOk, mp.fsmRemapExtents(req))
+	newEks := ino.Extents.CopyExtents()
+	require.Equal(t, 2, len(newEks))
+	require.Equal(t, uint64(1025), newEks[0].ExtentId)
+	require.Equal(t, uint64(2048), newEks[1].ExtentId)
+	require.Equal(t, uint64(8192), ino.Size)
+
+	// the fingerprints are kept in the xattr instead of the extent keys
+	value, ok := mp.extendTree.Get(NewExtend(ino.Inode)).(*Extend).Get([]byte(proto.DedupFingerprintXAttr))
+	require.True(t, ok)
+	fingerprints := make(map[string]string)
+	require.NoError(t, json.Unmarshal(value, &fingerprints))
+	require.Equal(t, map[string]string{
+		proto.DedupFingerprintKey(partitionId, 1025): "kept",
+		proto.DedupFingerprintKey(partitionId, 2048): "shared",
+	}, fingerprints)
+
+	// only the extent mapped away is released
+	delEks := <-mp.extDelCh
+	require.Equal(t, 1, len(delEks))
+	require.Equal(t, uint64(1026), delEks[0].ExtentId)
+}
@@ -285,6 +285,12 @@ func (mp *metaPartition) TxUnlinkInode(req *proto.TxUnlinkInodeRequest, p *Packe
 		p.PacketErrorWithBody(proto.OpNotPerm, []byte(err.Error()))
 		return
 	}
+	if respIno.GetNLink() <= 1 {
+		if err = mp.checkObjectLock(req.Inode, false); err != nil {
+			p.PacketErrorWithBody(proto.OpNotPerm, []byte(err.Error()))
+			return
+		}
+	}
 
 	ti := &TxInode{
 		Inode:  inoResp.Msg,
@@ -341,12 +347,20 @@ func (mp *metaPartition) UnlinkInode(req *UnlinkInoReq, p *Packet, remoteAddr st
 		p.PacketErrorWithBody(status, reply)
 	}
 	ino := NewInode(req.Inode, 0)
-	if item := mp.inodeTree.Get(ino); item == nil {
+	item := mp.inodeTree.Get(ino)
+	if item == nil {
 		err = fmt.Errorf("mp[%v] inode[%v] reqeust cann't found", mp.config.PartitionId, ino)
 		log.LogErrorf("action[UnlinkInode] %v", err)
 		p.PacketErrorWithBody(proto.OpNotExistErr, []byte(err.Error()))
 		return
 	}
+	// the locked inode cannot lose its last link
+	if item.(*Inode).GetNLink() <= 1 {
+		if err = mp.checkObjectLock(req.Inode, req.BypassGovernance); err != nil {
+			p.PacketErrorWithBody(proto.OpNotPerm, []byte(err.Error()))
+			return
+		}
+	}
 
 	if req.UniqID > 0 {
 		val = InodeOnceUnlinkMarshal(req)
@@ -381,10 +395,25 @@ func (mp *metaPartition) UnlinkInodeBatch(req *BatchUnlinkInoReq, p *Packet, rem
 	}
 
 	var inodes InodeBatch
+	result := &BatchUnlinkInoResp{}
+	status := proto.OpOk
 	start := time.Now()
 	for i, id := range req.Inodes {
-		inodes = append(inodes, NewInode(id, 0))
 		ino := id
+		// the locked inode cannot lose its last link
+		if item := mp.inodeTree.Get(NewInode(id, 0)); item != nil && item.(*Inode).GetNLink() <= 1 &&
+			mp.checkObjectLock(id, false) != nil {
+			status = proto.OpNotPerm
+			result.Items = append(result.Items, &struct {
+				Info   *proto.InodeInfo `json:"info"`
+				Status uint8            `json:"status"`
+			}{
+				Info:   &proto.InodeInfo{Inode: id},
+				Status: proto.OpNotPerm,
+			})
+		} else {
+			inodes = append(inodes, NewInode(id, 0))
+		}
 		fullPath := ""
 		if len(req.FullPaths) > i {
 			fullPath = req.FullPaths[i]
@@ -396,20 +425,24 @@ func (mp *metaPartition) UnlinkInodeBatch(req *BatchUnlinkInoReq, p *Packet, rem
 		}
 	}
 
-	val, err := inodes.Marshal()
-	if err != nil {
-		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
-		return
-	}
-	r, err := mp.submit(opFSMUnlinkInodeBatch, val)
-	if err != nil {
-		p.PacketErrorWithBody(proto.OpAgain, []byte(err.Error()))
-		return
+	var resps []*InodeResponse
+	if len(inodes) > 0 {
+		var (
+			val []byte
+			r   interface{}
+		)
+		if val, err = inodes.Marshal(); err != nil {
+			p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+			return
+		}
+		if r, err = mp.submit(opFSMUnlinkInodeBatch, val); err != nil {
+			p.PacketErrorWithBody(proto.OpAgain, []byte(err.Error()))
+			return
+		}
+		resps = r.([]*InodeResponse)
 	}
 
-	result := &BatchUnlinkInoResp{}
-	status := proto.OpOk
-	for _, ir := range r.([]*InodeResponse) {
+	for _, ir := range resps {
 		if ir.Status != proto.OpOk {
 			status = ir.Status
 		}
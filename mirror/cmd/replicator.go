@@ -0,0 +1,663 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/sdk/data/stream"
+	"github.com/cubefs/cubefs/sdk/master"
+	"github.com/cubefs/cubefs/sdk/meta"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	changeLogReadLimit = 10000
+	copyBufSize        = 1 << 20
+	// a change waiting for its parent or inode to be replayed by other partitions is skipped
+	// after so many rounds, the source inode is an orphan then.
+	maxDeferRounds = 10
+)
+
+var (
+	// errDeferred means the change depends on changes of other partitions not replayed yet.
+	errDeferred = errors.New("depends on changes not replayed")
+	// errChangesLost means no replica keeps the changes after the checkpoint, a full sync is required.
+	errChangesLost = errors.New("changes after checkpoint are lost")
+)
+
+type volume struct {
+	name string
+	mc   *master.MasterClient
+	mw   *meta.MetaWrapper
+	ec   *stream.ExtentClient
+}
+
+func openVolume(masters, name string) (v *volume, err error) {
+	addrs := strings.Split(masters, ",")
+	v = &volume{name: name, mc: master.NewMasterClient(addrs, false)}
+	if v.mw, err = meta.NewMetaWrapper(&meta.MetaConfig{
+		Volume:        name,
+		Masters:       addrs,
+		Authenticate:  false,
+		ValidateOwner: false,
+	}); err != nil {
+		return nil, fmt.Errorf("open meta of volume %v: %v", name, err)
+	}
+	if v.ec, err = stream.NewExtentClient(&stream.ExtentConfig{
+		Volume:            name,
+		Masters:           addrs,
+		FollowerRead:      false,
+		OnAppendExtentKey: v.mw.AppendExtentKey,
+		OnSplitExtentKey:  v.mw.SplitExtentKey,
+		OnGetExtents:      v.mw.GetExtents,
+		OnTruncate:        v.mw.Truncate,
+		OnModifyInode:     v.mw.InodeModify,
+	}); err != nil {
+		v.mw.Close()
+		return nil, fmt.Errorf("open data of volume %v: %v", name, err)
+	}
+	return
+}
+
+func (v *volume) close() {
+	v.ec.Close()
+	v.mw.Close()
+}
+
+type replicator struct {
+	state    *mirrorState
+	src      *volume
+	dst      *volume
+	metaPort string
+	deferred map[uint64]int    // partition id -> rounds the head change is deferred
+	reverse  map[uint64]uint64 // target inode -> source inode
+}
+
+func newReplicator(state *mirrorState, src, dst *volume, metaPort string) *replicator {
+	r := &replicator{
+		state:    state,
+		src:      src,
+		dst:      dst,
+		metaPort: metaPort,
+		deferred: make(map[uint64]int),
+		reverse:  make(map[uint64]uint64, len(state.Inodes)),
+	}
+	for srcIno, dstIno := range state.Inodes {
+		r.reverse[dstIno] = srcIno
+	}
+	return r
+}
+
+func isNotExist(err error) bool {
+	return err == syscall.ENOENT
+}
+
+type apiResponse struct {
+	Code int             `json:"code"`
+	Msg  string          `json:"msg"`
+	Data json.RawMessage `json:"data"`
+}
+
+func (r *replicator) fetchChangeLog(addr string, pid, from uint64) (resp *proto.ChangeLogResponse, err error) {
+	url := fmt.Sprintf("http://%s:%s/getChangeLog?pid=%d&from=%d&limit=%d",
+		strings.Split(addr, ":")[0], r.metaPort, pid, from, changeLogReadLimit)
+	httpResp, err := http.Get(url)
+	if err != nil {
+		return
+	}
+	defer httpResp.Body.Close()
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return
+	}
+	reply := &apiResponse{}
+	if err = json.Unmarshal(body, reply); err != nil {
+		return nil, fmt.Errorf("decode reply of %v: %v", url, err)
+	}
+	if reply.Code != http.StatusOK {
+		return nil, fmt.Errorf("get change log from %v: %v", addr, reply.Msg)
+	}
+	resp = &proto.ChangeLogResponse{}
+	err = json.Unmarshal(reply.Data, resp)
+	return
+}
+
+// readChanges reads the changes after from, the leader is tried first as it has the latest changes,
+// other replicas are tried if the changes are no longer kept by the leader.
+func (r *replicator) readChanges(mp *proto.MetaPartitionView, from uint64) (resp *proto.ChangeLogResponse, err error) {
+	addrs := []string{mp.LeaderAddr}
+	for _, addr := range mp.Members {
+		if addr != mp.LeaderAddr {
+			addrs = append(addrs, addr)
+		}
+	}
+	err = errChangesLost
+	for _, addr := range addrs {
+		if addr == "" {
+			continue
+		}
+		var changes *proto.ChangeLogResponse
+		if changes, err = r.fetchChangeLog(addr, mp.PartitionID, from); err != nil {
+			log.LogWarnf("mirror: read changes of mp(%v) from %v failed: %v", mp.PartitionID, addr, err)
+			continue
+		}
+		if from < changes.Since {
+			err = errChangesLost
+			continue
+		}
+		return changes, nil
+	}
+	return
+}
+
+// round replays the changes of all partitions once.
+func (r *replicator) round() (err error) {
+	mps, err := r.src.mc.ClientAPI().GetMetaPartitions(r.src.name)
+	if err != nil {
+		return
+	}
+	for _, mp := range mps {
+		if err = r.syncPartition(mp); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (r *replicator) syncPartition(mp *proto.MetaPartitionView) (err error) {
+	pid := mp.PartitionID
+	for {
+		cp := r.state.Checkpoints[pid]
+		var changes *proto.ChangeLogResponse
+		if changes, err = r.readChanges(mp, cp); err != nil {
+			return fmt.Errorf("mp(%v) checkpoint(%v): %w", pid, cp, err)
+		}
+		applied, deferred, replayErr := r.replay(pid, changes.Records)
+		if replayErr != nil {
+			return fmt.Errorf("mp(%v) replay: %v", pid, replayErr)
+		}
+		if deferred {
+			r.deferred[pid]++
+			if applied > cp {
+				r.state.setCheckpoint(pid, applied)
+			}
+			return nil
+		}
+		delete(r.deferred, pid)
+		if len(changes.Records) < changeLogReadLimit {
+			// the apply id is read before the records, nothing before it is missed
+			if applied < changes.ApplyID {
+				applied = changes.ApplyID
+			}
+			if applied > cp {
+				r.state.setCheckpoint(pid, applied)
+			}
+			return nil
+		}
+		r.state.setCheckpoint(pid, applied)
+	}
+}
+
+// replay applies the records in order, it returns the index whose changes are all replayed, and
+// stops at the first change depending on other partitions.
+func (r *replicator) replay(pid uint64, records []*proto.ChangeRecord) (applied uint64, deferred bool, err error) {
+	for i, rec := range records {
+		if i > 0 && rec.Index != records[i-1].Index {
+			applied = records[i-1].Index
+		}
+		if err = r.apply(pid, rec); err == errDeferred {
+			log.LogInfof("mirror: change %+v deferred", rec)
+			return applied, true, nil
+		}
+		if err != nil {
+			return
+		}
+	}
+	if len(records) > 0 {
+		applied = records[len(records)-1].Index
+	}
+	return
+}
+
+func (r *replicator) apply(pid uint64, rec *proto.ChangeRecord) (err error) {
+	switch rec.Type {
+	case proto.ChangeCreateDentry, proto.ChangeUpdateDentry:
+		err = r.linkDentry(rec)
+	case proto.ChangeDeleteDentry:
+		err = r.unlinkDentry(rec)
+	case proto.ChangeSetAttr:
+		err = r.syncAttr(rec.Inode)
+	case proto.ChangeWrite:
+		err = r.syncRange(rec.Inode, rec.Offset, rec.Size)
+	case proto.ChangeTruncate:
+		err = r.syncSize(rec.Inode)
+	case proto.ChangeXAttr:
+		err = r.syncXAttr(rec.Inode)
+	}
+	if err == errDeferred && r.deferred[pid] >= maxDeferRounds {
+		log.LogWarnf("mirror: change %+v of mp(%v) is deferred too many rounds, skip it", rec, pid)
+		return nil
+	}
+	return
+}
+
+// mapped returns the target inode, errDeferred if the source inode is alive but not replayed yet.
+func (r *replicator) mapped(srcIno uint64) (dstIno uint64, err error) {
+	if dstIno, ok := r.state.Inodes[srcIno]; ok {
+		return dstIno, nil
+	}
+	info, err := r.src.mw.InodeGet_ll(srcIno)
+	if isNotExist(err) || (err == nil && info.Nlink == 0) {
+		return 0, syscall.ENOENT
+	}
+	if err != nil {
+		return
+	}
+	return 0, errDeferred
+}
+
+// linkDentry makes parent/name of target volume point to the replica of the inode.
+func (r *replicator) linkDentry(rec *proto.ChangeRecord) (err error) {
+	dstParent, err := r.mapped(rec.ParentID)
+	if isNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return
+	}
+	// the source may have changed since, replay only what is still there
+	srcIno, _, err := r.src.mw.Lookup_ll(rec.ParentID, rec.Name)
+	if isNotExist(err) || (err == nil && srcIno != rec.Inode) {
+		return nil
+	}
+	if err != nil {
+		return
+	}
+
+	dstIno, ok := r.state.Inodes[rec.Inode]
+	existIno, existMode, lookupErr := r.dst.mw.Lookup_ll(dstParent, rec.Name)
+	if lookupErr != nil && !isNotExist(lookupErr) {
+		return lookupErr
+	}
+	if lookupErr == nil {
+		if ok && existIno == dstIno {
+			return nil
+		}
+		if err = r.removeDstEntry(dstParent, rec.Name, existIno, proto.IsDir(existMode)); err != nil {
+			return
+		}
+	}
+
+	if ok {
+		if proto.IsDir(rec.Mode) {
+			return r.moveDir(rec, dstParent)
+		}
+		_, err = r.dst.mw.Link(dstParent, rec.Name, dstIno, "")
+		return
+	}
+
+	info, err := r.src.mw.InodeGet_ll(rec.Inode)
+	if isNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return
+	}
+	newInfo, err := r.dst.mw.Create_ll(dstParent, rec.Name, info.Mode, info.Uid, info.Gid, info.Target, "", false)
+	if err != nil {
+		return
+	}
+	r.state.mapInode(rec.Inode, newInfo.Inode)
+	r.reverse[newInfo.Inode] = rec.Inode
+	if proto.IsDir(info.Mode) {
+		r.state.setDir(rec.Inode, location{Parent: rec.ParentID, Name: rec.Name})
+	} else if proto.IsRegular(info.Mode) && info.Size > 0 {
+		if err = r.syncRange(rec.Inode, 0, info.Size); err != nil {
+			return
+		}
+	}
+	if err = r.syncXAttr(rec.Inode); err != nil {
+		return
+	}
+	return r.syncAttr(rec.Inode)
+}
+
+// moveDir replays the rename of a directory, directories can not be hard linked.
+func (r *replicator) moveDir(rec *proto.ChangeRecord, dstParent uint64) (err error) {
+	loc, ok := r.state.Dirs[rec.Inode]
+	if !ok {
+		return fmt.Errorf("location of dir %v is unknown", rec.Inode)
+	}
+	oldParent, ok := r.state.Inodes[loc.Parent]
+	if !ok {
+		return fmt.Errorf("parent %v of dir %v is not mirrored", loc.Parent, rec.Inode)
+	}
+	if err = r.dst.mw.Rename_ll(oldParent, loc.Name, dstParent, rec.Name, "", "", false); err != nil && !isNotExist(err) {
+		return
+	}
+	r.state.setDir(rec.Inode, location{Parent: rec.ParentID, Name: rec.Name})
+	return nil
+}
+
+// unlinkDentry removes parent/name of target volume if the source has removed it.
+func (r *replicator) unlinkDentry(rec *proto.ChangeRecord) (err error) {
+	dstParent, ok := r.state.Inodes[rec.ParentID]
+	if !ok {
+		return nil
+	}
+	dstIno, ok := r.state.Inodes[rec.Inode]
+	if !ok {
+		return nil
+	}
+	srcIno, _, err := r.src.mw.Lookup_ll(rec.ParentID, rec.Name)
+	if err == nil && srcIno == rec.Inode {
+		return nil
+	}
+	if err != nil && !isNotExist(err) {
+		return
+	}
+	existIno, existMode, err := r.dst.mw.Lookup_ll(dstParent, rec.Name)
+	if isNotExist(err) || (err == nil && existIno != dstIno) {
+		return nil
+	}
+	if err != nil {
+		return
+	}
+	if proto.IsDir(existMode) {
+		// a renamed directory is moved by the creation of its new entry
+		if _, err = r.src.mw.InodeGet_ll(rec.Inode); err == nil {
+			return nil
+		}
+		if !isNotExist(err) {
+			return
+		}
+	}
+	return r.removeDstEntry(dstParent, rec.Name, existIno, proto.IsDir(existMode))
+}
+
+func (r *replicator) removeDstEntry(dstParent uint64, name string, dstIno uint64, isDir bool) (err error) {
+	info, err := r.dst.mw.Delete_ll(dstParent, name, isDir, "")
+	if err == syscall.ENOTEMPTY {
+		// the children are removed by changes of other partitions
+		return errDeferred
+	}
+	if err != nil {
+		if isNotExist(err) {
+			return nil
+		}
+		return
+	}
+	if isDir || (info != nil && info.Nlink == 0) {
+		if !isDir {
+			r.dst.mw.Evict(dstIno, "")
+		}
+		r.forget(dstIno)
+	}
+	return
+}
+
+func (r *replicator) forget(dstIno uint64) {
+	if srcIno, ok := r.reverse[dstIno]; ok {
+		r.state.unmapInode(srcIno)
+		r.state.deleteDir(srcIno)
+		delete(r.reverse, dstIno)
+	}
+}
+
+func (r *replicator) syncAttr(srcIno uint64) (err error) {
+	dstIno, err := r.mapped(srcIno)
+	if isNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return
+	}
+	info, err := r.src.mw.InodeGet_ll(srcIno)
+	if isNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return
+	}
+	valid := proto.AttrMode | proto.AttrUid | proto.AttrGid | proto.AttrModifyTime | proto.AttrAccessTime
+	return r.dst.mw.Setattr(dstIno, valid, info.Mode, info.Uid, info.Gid, info.AccessTime.Unix(), info.ModifyTime.Unix())
+}
+
+// syncXAttr makes the extended attributes of the target the same as the source. The dedup
+// fingerprints refer to the extents of the source cluster, they are never copied.
+func (r *replicator) syncXAttr(srcIno uint64) (err error) {
+	dstIno, err := r.mapped(srcIno)
+	if isNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return
+	}
+	srcInfo, err := r.src.mw.XAttrGetAll_ll(srcIno)
+	if isNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return
+	}
+	dstInfo, err := r.dst.mw.XAttrGetAll_ll(dstIno)
+	if err != nil {
+		return
+	}
+	delete(srcInfo.XAttrs, proto.DedupFingerprintXAttr)
+	attrs := make(map[string]string)
+	for key, value := range srcInfo.XAttrs {
+		if dstValue, ok := dstInfo.XAttrs[key]; !ok || dstValue != value {
+			attrs[key] = value
+		}
+	}
+	if len(attrs) > 0 {
+		if err = r.dst.mw.BatchSetXAttr_ll(dstIno, attrs); err != nil {
+			return
+		}
+	}
+	for key := range dstInfo.XAttrs {
+		if _, ok := srcInfo.XAttrs[key]; ok || key == proto.DedupFingerprintXAttr {
+			continue
+		}
+		if err = r.dst.mw.XAttrDel_ll(dstIno, key); err != nil && !isNotExist(err) {
+			return
+		}
+	}
+	return nil
+}
+
+func (r *replicator) syncSize(srcIno uint64) (err error) {
+	dstIno, err := r.mapped(srcIno)
+	if isNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return
+	}
+	info, err := r.src.mw.InodeGet_ll(srcIno)
+	if isNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return
+	}
+	if err = r.dst.ec.OpenStream(dstIno); err != nil {
+		return
+	}
+	defer r.dst.ec.CloseStream(dstIno)
+	return r.dst.ec.Truncate(r.dst.mw, 0, dstIno, int(info.Size), "")
+}
+
+// syncRange copies [offset, offset+size) of the source file, the part beyond the current file size is ignored.
+func (r *replicator) syncRange(srcIno, offset, size uint64) (err error) {
+	dstIno, err := r.mapped(srcIno)
+	if isNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return
+	}
+	if err = r.src.ec.OpenStream(srcIno); err != nil {
+		return
+	}
+	defer r.src.ec.CloseStream(srcIno)
+	if err = r.dst.ec.OpenStream(dstIno); err != nil {
+		return
+	}
+	defer r.dst.ec.CloseStream(dstIno)
+
+	buf := make([]byte, copyBufSize)
+	end := offset + size
+	for offset < end {
+		n := copyBufSize
+		if end-offset < uint64(n) {
+			n = int(end - offset)
+		}
+		read, readErr := r.src.ec.Read(srcIno, buf, int(offset), n)
+		if readErr != nil && readErr != io.EOF {
+			return readErr
+		}
+		if read > 0 {
+			if _, err = r.dst.ec.Write(dstIno, int(offset), buf[:read], 0, nil); err != nil {
+				return
+			}
+			offset += uint64(read)
+		}
+		if read == 0 || readErr == io.EOF {
+			break
+		}
+	}
+	return r.dst.ec.Flush(dstIno)
+}
+
+// fullSync walks the source volume and makes the target the same, the changes during the walk are
+// replayed by the following rounds from the apply ids taken before the walk.
+func (r *replicator) fullSync() (err error) {
+	mps, err := r.src.mc.ClientAPI().GetMetaPartitions(r.src.name)
+	if err != nil {
+		return
+	}
+	checkpoints := make(map[uint64]uint64, len(mps))
+	for _, mp := range mps {
+		var changes *proto.ChangeLogResponse
+		if changes, err = r.readChanges(mp, math.MaxUint64); err != nil {
+			return fmt.Errorf("mp(%v) read apply id: %v", mp.PartitionID, err)
+		}
+		checkpoints[mp.PartitionID] = changes.ApplyID
+	}
+	log.LogWarnf("mirror: full sync from %v to %v start", r.src.name, r.dst.name)
+	start := time.Now()
+	if err = r.syncDir(proto.RootIno); err != nil {
+		return
+	}
+	for pid, cp := range checkpoints {
+		r.state.setCheckpoint(pid, cp)
+	}
+	r.state.setFullSynced(true)
+	log.LogWarnf("mirror: full sync from %v to %v finished, cost %v", r.src.name, r.dst.name, time.Since(start))
+	return
+}
+
+func (r *replicator) syncDir(srcDir uint64) (err error) {
+	dstDir := r.state.Inodes[srcDir]
+	srcDentries, err := r.src.mw.ReadDir_ll(srcDir)
+	if err != nil {
+		return
+	}
+	dstDentries, err := r.dst.mw.ReadDir_ll(dstDir)
+	if err != nil {
+		return
+	}
+	srcNames := make(map[string]struct{}, len(srcDentries))
+	for _, d := range srcDentries {
+		srcNames[d.Name] = struct{}{}
+	}
+	for _, d := range dstDentries {
+		if _, ok := srcNames[d.Name]; !ok {
+			if err = r.removeDstTree(dstDir, d); err != nil {
+				return
+			}
+		}
+	}
+	for _, d := range srcDentries {
+		rec := &proto.ChangeRecord{Type: proto.ChangeCreateDentry, Inode: d.Inode, ParentID: srcDir, Name: d.Name, Mode: d.Type}
+		if err = r.linkDentry(rec); err != nil {
+			return fmt.Errorf("sync %v/%v: %v", srcDir, d.Name, err)
+		}
+		if err = r.syncXAttr(d.Inode); err != nil {
+			return fmt.Errorf("sync xattr of %v/%v: %v", srcDir, d.Name, err)
+		}
+		if proto.IsDir(d.Type) {
+			if err = r.syncDir(d.Inode); err != nil {
+				return
+			}
+		} else if err = r.syncFile(d.Inode); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// syncFile copies the whole file if the target differs from the source in size or modify time.
+func (r *replicator) syncFile(srcIno uint64) (err error) {
+	dstIno, ok := r.state.Inodes[srcIno]
+	if !ok {
+		return nil
+	}
+	srcInfo, err := r.src.mw.InodeGet_ll(srcIno)
+	if err != nil {
+		return
+	}
+	dstInfo, err := r.dst.mw.InodeGet_ll(dstIno)
+	if err != nil {
+		return
+	}
+	if !proto.IsRegular(srcInfo.Mode) || (srcInfo.Size == dstInfo.Size && srcInfo.ModifyTime.Equal(dstInfo.ModifyTime)) {
+		return nil
+	}
+	if err = r.syncRange(srcIno, 0, srcInfo.Size); err != nil {
+		return
+	}
+	if err = r.syncSize(srcIno); err != nil {
+		return
+	}
+	return r.syncAttr(srcIno)
+}
+
+func (r *replicator) removeDstTree(dstParent uint64, d proto.Dentry) (err error) {
+	if proto.IsDir(d.Type) {
+		var children []proto.Dentry
+		if children, err = r.dst.mw.ReadDir_ll(d.Inode); err != nil {
+			return
+		}
+		for _, c := range children {
+			if err = r.removeDstTree(d.Inode, c); err != nil {
+				return
+			}
+		}
+	}
+	return r.removeDstEntry(dstParent, d.Name, d.Inode, proto.IsDir(d.Type))
+}
@@ -0,0 +1,307 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"path"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/sdk/master"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+var (
+	SrcMaster string
+	SrcVol    string
+	DstMaster string
+	DstVol    string
+	StateDir  string
+	MetaPort  string
+	LogDir    string
+	LogLevel  string
+	Interval  time.Duration
+)
+
+func NewRootCmd() *cobra.Command {
+	var optShowVersion bool
+	c := &cobra.Command{
+		Use:   path.Base(os.Args[0]),
+		Short: "CubeFS volume mirror, replays the changes of a volume to a volume of another cluster",
+		Args:  cobra.MinimumNArgs(0),
+		Run: func(cmd *cobra.Command, args []string) {
+			if optShowVersion {
+				fmt.Fprintln(os.Stdout, proto.DumpVersion("MIRROR"))
+				return
+			}
+			cmd.Help()
+		},
+	}
+
+	proto.InitBufferPool(0)
+
+	c.AddCommand(
+		newRunCmd(),
+		newStatusCmd(),
+		newFailoverCmd(),
+	)
+
+	c.PersistentFlags().StringVarP(&StateDir, "state", "s", "", "directory keeping checkpoints and inode mapping of the mirror")
+	c.PersistentFlags().StringVarP(&SrcMaster, "src-master", "", "", "master addresses of the source cluster")
+	c.PersistentFlags().StringVarP(&SrcVol, "src-vol", "", "", "source volume name")
+	c.PersistentFlags().StringVarP(&MetaPort, "mport", "", "", "prof port of metanode of the source cluster")
+	c.Flags().BoolVarP(&optShowVersion, "version", "v", false, "Show version information")
+	return c
+}
+
+func newRunCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "run",
+		Short: "replay the changes of the source volume to the target volume until stopped",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := runMirror(); err != nil {
+				fmt.Fprintf(os.Stderr, "mirror failed: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	c.Flags().StringVarP(&DstMaster, "dst-master", "", "", "master addresses of the target cluster")
+	c.Flags().StringVarP(&DstVol, "dst-vol", "", "", "target volume name, it should be mounted read-only")
+	c.Flags().StringVarP(&LogDir, "log-dir", "", "", "log directory, default the state directory")
+	c.Flags().StringVarP(&LogLevel, "log-level", "", "warn", "log level")
+	c.Flags().DurationVarP(&Interval, "interval", "i", 10*time.Second, "interval between two rounds")
+	return c
+}
+
+func newStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "show the checkpoint and lag of each meta partition",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := showStatus(); err != nil {
+				fmt.Fprintf(os.Stderr, "show status failed: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+}
+
+func newFailoverCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "failover",
+		Short: "stop mirroring for good, the target volume can be mounted writable afterwards",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := failover(); err != nil {
+				fmt.Fprintf(os.Stderr, "failover failed: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+}
+
+func checkFlags(flags map[string]string) error {
+	for name, val := range flags {
+		if val == "" {
+			return fmt.Errorf("--%v is not specified", name)
+		}
+	}
+	return nil
+}
+
+// lockState makes sure only one mirror works on a state directory.
+func lockState() (fp *os.File, err error) {
+	if fp, err = os.OpenFile(path.Join(StateDir, ".lock"), os.O_CREATE|os.O_RDWR, 0o644); err != nil {
+		return
+	}
+	if err = syscall.Flock(int(fp.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		fp.Close()
+		return nil, fmt.Errorf("state directory %v is used by another mirror: %v", StateDir, err)
+	}
+	return
+}
+
+func parseLogLevel(level string) log.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return log.DebugLevel
+	case "info":
+		return log.InfoLevel
+	case "warn":
+		return log.WarnLevel
+	default:
+		return log.ErrorLevel
+	}
+}
+
+func runMirror() (err error) {
+	if err = checkFlags(map[string]string{
+		"state": StateDir, "src-master": SrcMaster, "src-vol": SrcVol,
+		"dst-master": DstMaster, "dst-vol": DstVol, "mport": MetaPort,
+	}); err != nil {
+		return
+	}
+	if err = os.MkdirAll(StateDir, 0o755); err != nil {
+		return
+	}
+	lock, err := lockState()
+	if err != nil {
+		return
+	}
+	defer lock.Close()
+
+	state, err := loadState(StateDir)
+	if os.IsNotExist(err) {
+		state, err = newMirrorState(SrcVol, DstVol), nil
+	}
+	if err != nil {
+		return
+	}
+	if state.SrcVol != SrcVol || state.DstVol != DstVol {
+		return fmt.Errorf("state directory is for %v -> %v", state.SrcVol, state.DstVol)
+	}
+	if state.FailedOver {
+		return fmt.Errorf("mirror %v -> %v has failed over", state.SrcVol, state.DstVol)
+	}
+
+	if LogDir == "" {
+		LogDir = StateDir
+	}
+	if _, err = log.InitLog(LogDir, "mirror", parseLogLevel(LogLevel), nil, log.DefaultLogLeftSpaceLimitRatio); err != nil {
+		return
+	}
+	defer log.LogFlush()
+
+	src, err := openVolume(SrcMaster, SrcVol)
+	if err != nil {
+		return
+	}
+	defer src.close()
+	// the in-place overwrites are in the change log only if the clients of the volume report them
+	view, err := src.mc.AdminAPI().GetVolumeSimpleInfo(SrcVol)
+	if err != nil {
+		return
+	}
+	if !view.EnableMirror {
+		return fmt.Errorf("volume %v is not enabled for mirror, update it with enableMirror first", SrcVol)
+	}
+	dst, err := openVolume(DstMaster, DstVol)
+	if err != nil {
+		return
+	}
+	defer dst.close()
+
+	r := newReplicator(state, src, dst, MetaPort)
+	sigC := make(chan os.Signal, 1)
+	signal.Notify(sigC, syscall.SIGINT, syscall.SIGTERM)
+	ticker := time.NewTicker(Interval)
+	defer ticker.Stop()
+	for {
+		if !state.FullSynced {
+			err = r.fullSync()
+		} else if err = r.round(); errors.Is(err, errChangesLost) {
+			log.LogWarnf("mirror: %v, full sync again", err)
+			state.setFullSynced(false)
+			err = r.fullSync()
+		}
+		if err != nil {
+			log.LogErrorf("mirror: %v -> %v round failed: %v", SrcVol, DstVol, err)
+		}
+		if err = state.save(StateDir); err != nil {
+			return
+		}
+		select {
+		case <-sigC:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func showStatus() (err error) {
+	if err = checkFlags(map[string]string{"state": StateDir, "src-master": SrcMaster, "mport": MetaPort}); err != nil {
+		return
+	}
+	state, err := loadState(StateDir)
+	if err != nil {
+		return
+	}
+	fmt.Printf("Mirror      : %v -> %v\n", state.SrcVol, state.DstVol)
+	fmt.Printf("Failed over : %v\n", state.FailedOver)
+	fmt.Printf("Full synced : %v\n", state.FullSynced)
+	fmt.Printf("Update time : %v\n", state.UpdateTime.Format(time.RFC3339))
+	fmt.Printf("Inodes      : %v\n", len(state.Inodes))
+
+	mc := master.NewMasterClient([]string{SrcMaster}, false)
+	mps, err := mc.ClientAPI().GetMetaPartitions(state.SrcVol)
+	if err != nil {
+		return
+	}
+	sort.Slice(mps, func(i, j int) bool { return mps[i].PartitionID < mps[j].PartitionID })
+	r := &replicator{metaPort: MetaPort}
+	fmt.Printf("%-12v %-16v %-16v %-12v\n", "PARTITION", "CHECKPOINT", "APPLY ID", "LAG")
+	for _, mp := range mps {
+		cp := state.Checkpoints[mp.PartitionID]
+		changes, readErr := r.readChanges(mp, math.MaxUint64)
+		if readErr != nil {
+			fmt.Printf("%-12v %-16v %-16v %-12v\n", mp.PartitionID, cp, "N/A", readErr)
+			continue
+		}
+		var lag uint64
+		if changes.ApplyID > cp {
+			lag = changes.ApplyID - cp
+		}
+		fmt.Printf("%-12v %-16v %-16v %-12v\n", mp.PartitionID, cp, changes.ApplyID, lag)
+	}
+	return
+}
+
+func failover() (err error) {
+	if err = checkFlags(map[string]string{"state": StateDir}); err != nil {
+		return
+	}
+	lock, err := lockState()
+	if err != nil {
+		return
+	}
+	defer lock.Close()
+	state, err := loadState(StateDir)
+	if err != nil {
+		return
+	}
+	state.setFailedOver(true)
+	if err = state.save(StateDir); err != nil {
+		return
+	}
+	fmt.Printf("mirror %v -> %v failed over, changes up to the checkpoints below are in %v:\n", state.SrcVol, state.DstVol, state.DstVol)
+	ids := make([]uint64, 0, len(state.Checkpoints))
+	for id := range state.Checkpoints {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		fmt.Printf("  mp %v: %v\n", id, state.Checkpoints[id])
+	}
+	return
+}
@@ -0,0 +1,268 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+)
+
+const (
+	stateFileName   = "mirror.state"
+	journalFileName = "mirror.journal"
+	// the journal is compacted into the state file once it has more entries than both this
+	// and the inode mapping, so the state file is rewritten far less than the journal grows.
+	minCompactEntries = 100000
+)
+
+// Operations recorded in the journal, each one changes a single item of the state.
+const (
+	opSetCheckpoint uint8 = iota + 1
+	opMapInode
+	opUnmapInode
+	opSetDir
+	opDeleteDir
+	opSetFullSynced
+	opSetFailedOver
+)
+
+// location is where a directory is linked in the source namespace.
+type location struct {
+	Parent uint64 `json:"parent"`
+	Name   string `json:"name"`
+}
+
+// journalEntry is one line of the journal.
+type journalEntry struct {
+	Op  uint8     `json:"op"`
+	Key uint64    `json:"key,omitempty"`
+	Val uint64    `json:"val,omitempty"`
+	Loc *location `json:"loc,omitempty"`
+}
+
+// mirrorState is everything the mirror needs to resume. The changes are appended to the journal
+// after each round, and the journal is compacted into the state file from time to time.
+// The fields must be changed by the methods below, otherwise the changes are not journaled.
+type mirrorState struct {
+	SrcVol string `json:"srcVol"`
+	DstVol string `json:"dstVol"`
+	// FailedOver is set by failover, the mirror never writes the target volume after that.
+	FailedOver bool `json:"failedOver"`
+	// Checkpoints is the raft index of each source meta partition whose changes are all replayed.
+	Checkpoints map[uint64]uint64 `json:"checkpoints"`
+	// Inodes maps the inode of source volume to the one of target volume.
+	Inodes map[uint64]uint64 `json:"inodes"`
+	// Dirs tracks the location of directories to replay rename, a directory has only one entry.
+	Dirs       map[uint64]location `json:"dirs"`
+	FullSynced bool                `json:"fullSynced"`
+	UpdateTime time.Time           `json:"updateTime"`
+
+	pending     []journalEntry // changes not saved yet
+	journal     int            // entries in the journal file
+	journalSize int64          // bytes of the complete entries in the journal file
+	snapshot    bool           // the state file exists
+}
+
+func newMirrorState(srcVol, dstVol string) *mirrorState {
+	return &mirrorState{
+		SrcVol:      srcVol,
+		DstVol:      dstVol,
+		Checkpoints: make(map[uint64]uint64),
+		Inodes:      map[uint64]uint64{proto.RootIno: proto.RootIno},
+		Dirs:        make(map[uint64]location),
+	}
+}
+
+// loadState reads the state file and replays the journal on it.
+func loadState(dir string) (s *mirrorState, err error) {
+	data, err := os.ReadFile(path.Join(dir, stateFileName))
+	if err != nil {
+		return
+	}
+	s = &mirrorState{}
+	if err = json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("decode state file: %v", err)
+	}
+	s.snapshot = true
+	fp, err := os.Open(path.Join(dir, journalFileName))
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+	if info, statErr := fp.Stat(); statErr == nil && info.ModTime().After(s.UpdateTime) {
+		s.UpdateTime = info.ModTime()
+	}
+	reader := bufio.NewReader(fp)
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr == io.EOF {
+			// a line without the ending is partially written by a crash, it is overwritten by
+			// the next append, the changes after the checkpoints are replayed again
+			break
+		}
+		if readErr != nil {
+			return nil, readErr
+		}
+		entry := journalEntry{}
+		if err = json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("decode journal entry %v: %v", s.journal, err)
+		}
+		s.apply(entry)
+		s.journal++
+		s.journalSize += int64(len(line))
+	}
+	return s, nil
+}
+
+// apply changes the state without journaling, the entries are idempotent so replaying the
+// journal on a state file which already has them makes no difference.
+func (s *mirrorState) apply(e journalEntry) {
+	switch e.Op {
+	case opSetCheckpoint:
+		s.Checkpoints[e.Key] = e.Val
+	case opMapInode:
+		s.Inodes[e.Key] = e.Val
+	case opUnmapInode:
+		delete(s.Inodes, e.Key)
+	case opSetDir:
+		if e.Loc != nil {
+			s.Dirs[e.Key] = *e.Loc
+		}
+	case opDeleteDir:
+		delete(s.Dirs, e.Key)
+	case opSetFullSynced:
+		s.FullSynced = e.Val != 0
+	case opSetFailedOver:
+		s.FailedOver = e.Val != 0
+	}
+}
+
+func (s *mirrorState) record(e journalEntry) {
+	s.apply(e)
+	s.pending = append(s.pending, e)
+}
+
+func boolValue(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (s *mirrorState) setCheckpoint(pid, index uint64) {
+	s.record(journalEntry{Op: opSetCheckpoint, Key: pid, Val: index})
+}
+
+func (s *mirrorState) mapInode(srcIno, dstIno uint64) {
+	s.record(journalEntry{Op: opMapInode, Key: srcIno, Val: dstIno})
+}
+
+func (s *mirrorState) unmapInode(srcIno uint64) {
+	s.record(journalEntry{Op: opUnmapInode, Key: srcIno})
+}
+
+func (s *mirrorState) setDir(srcIno uint64, loc location) {
+	s.record(journalEntry{Op: opSetDir, Key: srcIno, Loc: &loc})
+}
+
+func (s *mirrorState) deleteDir(srcIno uint64) {
+	s.record(journalEntry{Op: opDeleteDir, Key: srcIno})
+}
+
+func (s *mirrorState) setFullSynced(synced bool) {
+	s.record(journalEntry{Op: opSetFullSynced, Val: boolValue(synced)})
+}
+
+func (s *mirrorState) setFailedOver(failedOver bool) {
+	s.record(journalEntry{Op: opSetFailedOver, Val: boolValue(failedOver)})
+}
+
+// save appends the pending changes to the journal, and compacts the journal into the state file
+// if it grows larger than the state.
+func (s *mirrorState) save(dir string) (err error) {
+	s.UpdateTime = time.Now()
+	if !s.snapshot {
+		return s.compact(dir)
+	}
+	if len(s.pending) > 0 {
+		if err = s.appendJournal(dir); err != nil {
+			return
+		}
+	}
+	if s.journal > minCompactEntries && s.journal > len(s.Inodes) {
+		return s.compact(dir)
+	}
+	return
+}
+
+func (s *mirrorState) appendJournal(dir string) (err error) {
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	for i := range s.pending {
+		if err = encoder.Encode(&s.pending[i]); err != nil {
+			return
+		}
+	}
+	fp, err := os.OpenFile(path.Join(dir, journalFileName), os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return
+	}
+	// drop what a failed append has partially written
+	if err = fp.Truncate(s.journalSize); err == nil {
+		if _, err = fp.WriteAt(buf.Bytes(), s.journalSize); err == nil {
+			err = fp.Sync()
+		}
+	}
+	if closeErr := fp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return
+	}
+	s.journal += len(s.pending)
+	s.journalSize += int64(buf.Len())
+	s.pending = s.pending[:0]
+	return
+}
+
+// compact writes the whole state to a temporary file and renames it, then removes the journal.
+// A crash never leaves a partial state, the journal left by a crash is replayed harmlessly.
+func (s *mirrorState) compact(dir string) (err error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return
+	}
+	tmp := path.Join(dir, stateFileName+".tmp")
+	fp, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return
+	}
+	if _, err = fp.Write(data); err == nil {
+		err = fp.Sync()
+	}
+	if closeErr := fp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return
+	}
+	if err = os.Rename(tmp, path.Join(dir, stateFileName)); err != nil {
+		return
+	}
+	s.snapshot = true
+	s.pending = s.pending[:0]
+	if err = os.Remove(path.Join(dir, journalFileName)); err != nil && !os.IsNotExist(err) {
+		return
+	}
+	s.journal, s.journalSize = 0, 0
+	return nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cmd
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMirrorState(t *testing.T) {
+	dir := t.TempDir()
+	_, err := loadState(dir)
+	require.True(t, os.IsNotExist(err))
+
+	s := newMirrorState("src", "dst")
+	s.setCheckpoint(1, 100)
+	s.mapInode(10, 20)
+	s.setDir(10, location{Parent: proto.RootIno, Name: "a"})
+	require.NoError(t, s.save(dir))
+
+	loaded, err := loadState(dir)
+	require.NoError(t, err)
+	require.Equal(t, "src", loaded.SrcVol)
+	require.Equal(t, uint64(100), loaded.Checkpoints[1])
+	require.Equal(t, uint64(20), loaded.Inodes[10])
+	require.Equal(t, uint64(proto.RootIno), loaded.Inodes[proto.RootIno])
+	require.Equal(t, "a", loaded.Dirs[10].Name)
+}
+
+func TestMirrorStateJournal(t *testing.T) {
+	dir := t.TempDir()
+	s := newMirrorState("src", "dst")
+	require.NoError(t, s.save(dir))
+	snapshot, err := os.ReadFile(path.Join(dir, stateFileName))
+	require.NoError(t, err)
+
+	// the changes after the first save are appended to the journal only
+	s.mapInode(10, 20)
+	s.mapInode(11, 21)
+	s.setDir(10, location{Parent: proto.RootIno, Name: "a"})
+	s.setCheckpoint(1, 100)
+	require.NoError(t, s.save(dir))
+	s.unmapInode(11)
+	s.deleteDir(10)
+	s.setFullSynced(true)
+	require.NoError(t, s.save(dir))
+	data, err := os.ReadFile(path.Join(dir, stateFileName))
+	require.NoError(t, err)
+	require.Equal(t, snapshot, data)
+
+	// a partially written entry is ignored and overwritten by the next append
+	fp, err := os.OpenFile(path.Join(dir, journalFileName), os.O_APPEND|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = fp.WriteString(`{"op":2,"key":12`)
+	require.NoError(t, err)
+	require.NoError(t, fp.Close())
+
+	loaded, err := loadState(dir)
+	require.NoError(t, err)
+	require.Equal(t, 7, loaded.journal)
+	require.Equal(t, uint64(20), loaded.Inodes[10])
+	require.NotContains(t, loaded.Inodes, uint64(11))
+	require.NotContains(t, loaded.Inodes, uint64(12))
+	require.NotContains(t, loaded.Dirs, uint64(10))
+	require.Equal(t, uint64(100), loaded.Checkpoints[1])
+	require.True(t, loaded.FullSynced)
+
+	loaded.setFailedOver(true)
+	require.NoError(t, loaded.save(dir))
+	loaded, err = loadState(dir)
+	require.NoError(t, err)
+	require.True(t, loaded.FailedOver)
+	require.NotContains(t, loaded.Inodes, uint64(12))
+
+	// the compaction writes everything to the state file and removes the journal
+	require.NoError(t, loaded.compact(dir))
+	_, err = os.Stat(path.Join(dir, journalFileName))
+	require.True(t, os.IsNotExist(err))
+	loaded, err = loadState(dir)
+	require.NoError(t, err)
+	require.True(t, loaded.FailedOver)
+	require.Equal(t, uint64(20), loaded.Inodes[10])
+}
@@ -131,6 +131,10 @@ func (g *Grant) isAllowed(reqId string, apiName proto.Action) bool {
 	}
 }
 
+func (g *Grant) isPublic() bool {
+	return g.Grantee.Type == TypeGroup && (g.Grantee.URI == GroupAllUser || g.Grantee.URI == GroupAuthenticated)
+}
+
 func (acp *AccessControlPolicy) IsValid() error {
 	if len(acp.Acl.Grants) == 0 {
 		return ErrMissingGrants
@@ -165,6 +169,26 @@ func (acp *AccessControlPolicy) IsEmpty() bool {
 	return len(acp.Acl.Grants) == 0
 }
 
+// IsPublic reports whether the acl grants any permission to all users or all authenticated users.
+func (acp *AccessControlPolicy) IsPublic() bool {
+	for _, g := range acp.Acl.Grants {
+		if g.isPublic() {
+			return true
+		}
+	}
+	return false
+}
+
+func (acp *AccessControlPolicy) withoutPublicGrants() *AccessControlPolicy {
+	filtered := &AccessControlPolicy{Xmlns: acp.Xmlns, Owner: acp.Owner}
+	for _, g := range acp.Acl.Grants {
+		if !g.isPublic() {
+			filtered.Acl.Grants = append(filtered.Acl.Grants, g)
+		}
+	}
+	return filtered
+}
+
 func (acp *AccessControlPolicy) SetOwner(owner string) {
 	acp.Owner.Id = owner
 }
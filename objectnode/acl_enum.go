@@ -73,14 +73,15 @@ var (
 		proto.OSSPutBucketAclAction: PermissionWriteAcp,
 		proto.OSSGetBucketAclAction: PermissionReadAcp,
 		// object read
-		proto.OSSGetObjectAction:  PermissionRead,
-		proto.OSSHeadObjectAction: PermissionRead,
+		proto.OSSGetObjectAction:           PermissionRead,
+		proto.OSSHeadObjectAction:          PermissionRead,
+		proto.OSSGetObjectAttributesAction: PermissionRead,
 		// object acp
 		proto.OSSPutObjectAclAction: PermissionWriteAcp,
 		proto.OSSGetObjectAclAction: PermissionReadAcp,
 	}
 	aclApiList             = []proto.Action{proto.OSSPutBucketAclAction, proto.OSSGetBucketAclAction, proto.OSSPutObjectAclAction, proto.OSSGetObjectAclAction}
-	objectACLSupportedApis = []proto.Action{proto.OSSGetObjectAction, proto.OSSHeadObjectAction, proto.OSSPutObjectAclAction, proto.OSSGetObjectAclAction}
+	objectACLSupportedApis = []proto.Action{proto.OSSGetObjectAction, proto.OSSHeadObjectAction, proto.OSSGetObjectAttributesAction, proto.OSSPutObjectAclAction, proto.OSSGetObjectAclAction}
 )
 
 var (
@@ -97,6 +97,11 @@ func (o *ObjectNode) putBucketACLHandler(w http.ResponseWriter, r *http.Request)
 			GetRequestID(r), param.bucket, err)
 		return
 	}
+	if err = o.checkPublicACL(vol, acl); err != nil {
+		log.LogErrorf("putBucketACLHandler: public acl denied: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.bucket, err)
+		return
+	}
 	if err = putBucketACL(vol, acl); err != nil {
 		log.LogErrorf("putBucketACLHandler: put acl fail: requestID(%v) volume(%v) acl(%+v) err(%v)",
 			GetRequestID(r), param.bucket, acl, err)
@@ -208,6 +213,11 @@ func (o *ObjectNode) putObjectACLHandler(w http.ResponseWriter, r *http.Request)
 			GetRequestID(r), param.bucket, param.object, err)
 		return
 	}
+	if err = o.checkPublicACL(vol, acl); err != nil {
+		log.LogErrorf("putObjectACLHandler: public acl denied: requestID(%v) volume(%v) path(%v) err(%v)",
+			GetRequestID(r), param.bucket, param.object, err)
+		return
+	}
 	if oldAcl != nil {
 		originalOwner := oldAcl.GetOwner()
 		if oldAcl.IsEmpty() {
@@ -232,4 +242,18 @@ func (o *ObjectNode) putObjectACLHandler(w http.ResponseWriter, r *http.Request)
 		}
 		return
 	}
+	if vol.unifiedPermission() {
+		var inode uint64
+		if _, inode, _, _, err = vol.recursiveLookupTarget(param.object, true); err == nil {
+			err = vol.setPosixPermission(inode, "", acl)
+		}
+		if err != nil {
+			log.LogErrorf("putObjectACLHandler: set posix permission fail: requestID(%v) volume(%v) path(%v) err(%v)",
+				GetRequestID(r), param.bucket, param.object, err)
+			if err == syscall.ENOENT {
+				erc = NoSuchKey
+			}
+			return
+		}
+	}
 }
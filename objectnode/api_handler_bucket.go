@@ -119,6 +119,10 @@ func (o *ObjectNode) createBucketHandler(w http.ResponseWriter, r *http.Request)
 		log.LogErrorf("createBucketHandler: parse acl fail: requestID(%v) err(%v)", GetRequestID(r), err)
 		return
 	}
+	if err = o.accountPublicAccessBlock(userInfo.UserID).checkACL(acl); err != nil {
+		log.LogErrorf("createBucketHandler: public acl denied: requestID(%v) volume(%v) err(%v)", GetRequestID(r), bucket, err)
+		return
+	}
 
 	if err = o.mc.AdminAPI().CreateDefaultVolume(bucket, userInfo.UserID); err != nil {
 		log.LogErrorf("createBucketHandler: create bucket fail: requestID(%v) volume(%v) accessKey(%v) err(%v)",
@@ -116,6 +116,18 @@ func (o *ObjectNode) createMultipleUploadHandler(w http.ResponseWriter, r *http.
 			GetRequestID(r), acl, err)
 		return
 	}
+	if err = o.checkPublicACL(vol, acl); err != nil {
+		log.LogErrorf("createMultipleUploadHandler: public acl denied: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), vol.Name(), err)
+		return
+	}
+	// Check 'x-amz-checksum-algorithm' header
+	var checksum *Checksum
+	if algorithm := r.Header.Get(XAmzChecksumAlgorithm); algorithm != "" {
+		if checksum, errorCode = NewChecksum(strings.ToUpper(algorithm)); errorCode != nil {
+			return
+		}
+	}
 	opt := &PutFileOption{
 		MIMEType:     contentType,
 		Disposition:  contentDisposition,
@@ -124,6 +136,7 @@ func (o *ObjectNode) createMultipleUploadHandler(w http.ResponseWriter, r *http.
 		CacheControl: cacheControl,
 		Expires:      expires,
 		ACL:          acl,
+		Checksum:     checksum,
 	}
 
 	var uploadID string
@@ -138,6 +151,9 @@ func (o *ObjectNode) createMultipleUploadHandler(w http.ResponseWriter, r *http.
 		Key:      param.Object(),
 		UploadId: uploadID,
 	}
+	if checksum != nil {
+		w.Header().Set(XAmzChecksumAlgorithm, checksum.Algorithm)
+	}
 	response, err := MarshalXMLEntity(initResult)
 	if err != nil {
 		log.LogErrorf("createMultipleUploadHandler: xml marshal result fail: requestID(%v) result(%v) err(%v)",
@@ -205,6 +221,12 @@ func (o *ObjectNode) uploadPartHandler(w http.ResponseWriter, r *http.Request) {
 		requestMD5 = hex.EncodeToString(decoded)
 	}
 
+	// Get request additional checksum, verify it if specified.
+	var checksum *Checksum
+	if checksum, errorCode = ParseChecksum(r); errorCode != nil {
+		return
+	}
+
 	// Verify ContentLength
 	length := GetContentLength(r)
 	if length > SinglePutLimit {
@@ -252,7 +274,7 @@ func (o *ObjectNode) uploadPartHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Write Part
 	start := time.Now()
-	fsFileInfo, err := vol.WritePart(param.Object(), uploadId, partNumberInt, reader)
+	fsFileInfo, err := vol.WritePart(param.Object(), uploadId, partNumberInt, reader, checksum)
 	span.AppendTrackLog("part.w", start, err)
 	if err != nil {
 		log.LogErrorf("uploadPartHandler: write part fail: requestID(%v) volume(%v) path(%v) uploadId(%v) part(%v) err(%v)",
@@ -271,6 +293,9 @@ func (o *ObjectNode) uploadPartHandler(w http.ResponseWriter, r *http.Request) {
 
 	// write header to response
 	w.Header()[ETag] = []string{"\"" + fsFileInfo.ETag + "\""}
+	if fsFileInfo.Checksum != "" {
+		w.Header().Set(ChecksumHeader(fsFileInfo.ChecksumAlgorithm), fsFileInfo.Checksum)
+	}
 }
 
 // Upload part copy
@@ -398,7 +423,7 @@ func (o *ObjectNode) uploadPartCopyHandler(w http.ResponseWriter, r *http.Reques
 		rd = reader
 	}
 	start = time.Now()
-	fsFileInfo, err := vol.WritePart(param.Object(), uploadId, partNumberInt, rd)
+	fsFileInfo, err := vol.WritePart(param.Object(), uploadId, partNumberInt, rd, nil)
 	span.AppendTrackLog("part.w", start, err)
 	if err != nil {
 		log.LogErrorf("uploadPartCopyHandler: write part fail: requestID(%v) volume(%v) path(%v) uploadId(%v) part(%v) err(%v)",
@@ -419,7 +444,9 @@ func (o *ObjectNode) uploadPartCopyHandler(w http.ResponseWriter, r *http.Reques
 
 	Etag := "\"" + fsFileInfo.ETag + "\""
 	w.Header()[ETag] = []string{Etag}
-	response := NewS3CopyPartResult(Etag, fsFileInfo.CreateTime.UTC().Format(time.RFC3339)).String()
+	result := NewS3CopyPartResult(Etag, fsFileInfo.CreateTime.UTC().Format(time.RFC3339))
+	result.ChecksumResult = NewChecksumResult(fsFileInfo.ChecksumAlgorithm, fsFileInfo.Checksum)
+	response := result.String()
 
 	writeSuccessResponseXML(w, []byte(response))
 }
@@ -687,6 +714,12 @@ func (o *ObjectNode) completeMultipartUploadHandler(w http.ResponseWriter, r *ht
 	}
 	defer rateLimit.ReleaseLimitResource(vol.owner, param.apiName)
 
+	// get precondition of the completed object
+	var condition *WriteCondition
+	if condition, errorCode = ParseWriteCondition(r); errorCode != nil {
+		return
+	}
+
 	// get uploaded part info in request
 	_, errorCode = VerifyContentLength(r, BodyLimit)
 	if errorCode != nil {
@@ -764,7 +797,7 @@ func (o *ObjectNode) completeMultipartUploadHandler(w http.ResponseWriter, r *ht
 
 	// complete multipart
 	start = time.Now()
-	fsFileInfo, err := vol.CompleteMultipart(param.Object(), uploadId, committedPartInfo, discardedInods)
+	fsFileInfo, err := vol.CompleteMultipart(param.Object(), uploadId, committedPartInfo, discardedInods, condition)
 	span.AppendTrackLog("part.c", start, err)
 	if err != nil {
 		log.LogErrorf("completeMultipartUploadHandler: complete multipart fail: requestID(%v) volume(%v) uploadID(%v) err(%v)",
@@ -774,11 +807,29 @@ func (o *ObjectNode) completeMultipartUploadHandler(w http.ResponseWriter, r *ht
 		}
 		return
 	}
+	if vol.unifiedPermission() {
+		// the object is owned by the initiator of the upload, or the owner of the volume if no acl
+		var acl *AccessControlPolicy
+		if acl, err = getObjectACL(vol, param.Object(), false); err == nil {
+			owner := vol.GetOwner()
+			if acl != nil {
+				owner = acl.GetOwner()
+			}
+			err = vol.setPosixPermission(fsFileInfo.Inode, owner, acl)
+		}
+		if err != nil {
+			log.LogErrorf("completeMultipartUploadHandler: set posix permission fail: requestID(%v) volume(%v) path(%v) err(%v)",
+				GetRequestID(r), param.Bucket(), param.Object(), err)
+			return
+		}
+	}
 
 	completeResult := CompleteMultipartResult{
 		Bucket: param.Bucket(),
 		Key:    param.Object(),
 		ETag:   wrapUnescapedQuot(fsFileInfo.ETag),
+
+		ChecksumResult: NewChecksumResult(fsFileInfo.ChecksumAlgorithm, fsFileInfo.Checksum),
 	}
 	response, ierr := MarshalXMLEntity(completeResult)
 	if ierr != nil {
@@ -988,6 +1039,7 @@ type S3CopyPartResult struct {
 	XMLName      xml.Name
 	ETag         string `xml:"ETag"`
 	LastModified string `xml:"LastModified"`
+	ChecksumResult
 }
 
 func NewS3CopyPartResult(etag, lastModified string) *S3CopyPartResult {
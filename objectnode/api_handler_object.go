@@ -219,9 +219,13 @@ func (o *ObjectNode) getObjectHandler(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set(Expires, fileInfo.Expires)
 	}
 	if len(fileInfo.RetainUntilDate) > 0 {
-		w.Header().Set(XAmzObjectLockMode, ComplianceMode)
+		w.Header().Set(XAmzObjectLockMode, fileInfo.RetentionMode)
 		w.Header().Set(XAmzObjectLockRetainUntilDate, fileInfo.RetainUntilDate)
 	}
+	if len(fileInfo.LegalHold) > 0 {
+		w.Header().Set(XAmzObjectLockLegalHold, fileInfo.LegalHold)
+	}
+	setChecksumHeader(w, r, fileInfo)
 
 	// check request is whether contain param : partNumber
 	partNumber := r.URL.Query().Get(ParamPartNumber)
@@ -487,9 +491,13 @@ func (o *ObjectNode) headObjectHandler(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set(Expires, fileInfo.Expires)
 	}
 	if len(fileInfo.RetainUntilDate) > 0 {
-		w.Header().Set(XAmzObjectLockMode, ComplianceMode)
+		w.Header().Set(XAmzObjectLockMode, fileInfo.RetentionMode)
 		w.Header().Set(XAmzObjectLockRetainUntilDate, fileInfo.RetainUntilDate)
 	}
+	if len(fileInfo.LegalHold) > 0 {
+		w.Header().Set(XAmzObjectLockLegalHold, fileInfo.LegalHold)
+	}
+	setChecksumHeader(w, r, fileInfo)
 
 	// check request is whether contain param : partNumber
 	partNumber := r.URL.Query().Get(ParamPartNumber)
@@ -624,6 +632,7 @@ func (o *ObjectNode) deleteObjectsHandler(w http.ResponseWriter, r *http.Request
 
 	deletedObjects := make([]Deleted, 0, len(deleteReq.Objects))
 	deletedErrors := make([]Error, 0)
+	bypassGovernance := isBypassGovernanceRequest(r)
 	start := time.Now()
 	for _, object := range deleteReq.Objects {
 		result := POLICY_UNKNOW
@@ -644,6 +653,18 @@ func (o *ObjectNode) deleteObjectsHandler(w http.ResponseWriter, r *http.Request
 			})
 			continue
 		}
+		if bypassGovernance {
+			paramCopy := *param
+			paramCopy.object = object.Key
+			if !o.allowedBypassGovernance(&paramCopy, vol) {
+				deletedErrors = append(deletedErrors, Error{
+					Key:     object.Key,
+					Code:    "AccessDenied",
+					Message: "Not Allowed To Bypass Governance Retention",
+				})
+				continue
+			}
+		}
 		log.LogWarnf("deleteObjectsHandler: delete path: requestID(%v) remote(%v) volume(%v) path(%v)",
 			GetRequestID(r), getRequestIP(r), vol.Name(), object.Key)
 		// QPS and Concurrency Limit
@@ -651,13 +672,23 @@ func (o *ObjectNode) deleteObjectsHandler(w http.ResponseWriter, r *http.Request
 		if err = rateLimit.AcquireLimitResource(vol.owner, DELETE_OBJECT); err != nil {
 			return
 		}
-		if err1 := vol.DeletePath(object.Key); err1 != nil {
+		var err1 error
+		if bypassGovernance {
+			err1 = vol.DeletePathBypassGovernance(object.Key)
+		} else {
+			err1 = vol.DeletePath(object.Key)
+		}
+		if err1 != nil {
 			log.LogErrorf("deleteObjectsHandler: delete object failed: requestID(%v) volume(%v) path(%v) err(%v)",
 				GetRequestID(r), vol.Name(), object.Key, err1)
-			if !strings.Contains(err1.Error(), AccessDenied.ErrorMessage) {
-				deletedErrors = append(deletedErrors, Error{Key: object.Key, Code: "InternalError", Message: err1.Error()})
-			} else {
+			switch {
+			case err1 == syscall.EPERM:
+				// the object is protected by object lock, which the meta node enforces as well
+				deletedErrors = append(deletedErrors, Error{Key: object.Key, Code: "AccessDenied", Message: AccessDenied.ErrorMessage})
+			case strings.Contains(err1.Error(), AccessDenied.ErrorMessage):
 				deletedErrors = append(deletedErrors, Error{Key: object.Key, Code: "AccessDenied", Message: err1.Error()})
+			default:
+				deletedErrors = append(deletedErrors, Error{Key: object.Key, Code: "InternalError", Message: err1.Error()})
 			}
 		} else {
 			deletedObjects = append(deletedObjects, Deleted{Key: object.Key})
@@ -791,6 +822,11 @@ func (o *ObjectNode) copyObjectHandler(w http.ResponseWriter, r *http.Request) {
 			GetRequestID(r), param.Bucket(), acl, err)
 		return
 	}
+	if err = o.checkPublicACL(vol, acl); err != nil {
+		log.LogErrorf("copyObjectHandler: public acl denied: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		return
+	}
 
 	// get src object meta
 	var sourceVol *Volume
@@ -867,6 +903,12 @@ func (o *ObjectNode) copyObjectHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Get request precondition of the target object.
+	var condition *WriteCondition
+	if condition, errorCode = ParseWriteCondition(r); errorCode != nil {
+		return
+	}
+
 	// ObjectLock  Config
 	objetLock, err := vol.metaLoader.loadObjectLock()
 	if err != nil {
@@ -887,6 +929,7 @@ func (o *ObjectNode) copyObjectHandler(w http.ResponseWriter, r *http.Request) {
 		Expires:      expires,
 		ACL:          acl,
 		ObjectLock:   objetLock,
+		Condition:    condition,
 	}
 	start = time.Now()
 	fsFileInfo, err := vol.CopyFile(sourceVol, sourceObject, param.Object(), metadataDirective, opt)
@@ -908,6 +951,11 @@ func (o *ObjectNode) copyObjectHandler(w http.ResponseWriter, r *http.Request) {
 		errorCode = CopySourceSizeTooLarge
 		return
 	}
+	if err = vol.setPosixPermission(fsFileInfo.Inode, userInfo.UserID, acl); err != nil {
+		log.LogErrorf("copyObjectHandler: set posix permission fail: requestID(%v) volume(%v) path(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), param.Object(), err)
+		return
+	}
 
 	copyResult := CopyResult{
 		ETag:         "\"" + fsFileInfo.ETag + "\"",
@@ -1273,6 +1321,18 @@ func (o *ObjectNode) putObjectHandler(w http.ResponseWriter, r *http.Request) {
 		requestMD5 = hex.EncodeToString(decoded)
 	}
 
+	// Get request additional checksum, if it is specified, compute and verify it.
+	var checksum *Checksum
+	if checksum, errorCode = ParseChecksum(r); errorCode != nil {
+		return
+	}
+
+	// Get request precondition, the object is written only if it holds.
+	var condition *WriteCondition
+	if condition, errorCode = ParseWriteCondition(r); errorCode != nil {
+		return
+	}
+
 	// ObjectLock  Config
 	objetLock, err := vol.metaLoader.loadObjectLock()
 	if err != nil {
@@ -1321,6 +1381,11 @@ func (o *ObjectNode) putObjectHandler(w http.ResponseWriter, r *http.Request) {
 			GetRequestID(r), vol.Name(), param.Object(), acl, err)
 		return
 	}
+	if err = o.checkPublicACL(vol, acl); err != nil {
+		log.LogErrorf("putObjectHandler: public acl denied: requestID(%v) volume(%v) path(%v) err(%v)",
+			GetRequestID(r), vol.Name(), param.Object(), err)
+		return
+	}
 
 	// Verify ContentLength
 	length := GetContentLength(r)
@@ -1375,6 +1440,8 @@ func (o *ObjectNode) putObjectHandler(w http.ResponseWriter, r *http.Request) {
 		Expires:      expires,
 		ACL:          acl,
 		ObjectLock:   objetLock,
+		Checksum:     checksum,
+		Condition:    condition,
 	}
 	start := time.Now()
 	fsFileInfo, err := vol.PutObject(param.Object(), reader, opt)
@@ -1393,9 +1460,17 @@ func (o *ObjectNode) putObjectHandler(w http.ResponseWriter, r *http.Request) {
 		errorCode = BadDigest
 		return
 	}
+	if err = vol.setPosixPermission(fsFileInfo.Inode, userInfo.UserID, acl); err != nil {
+		log.LogErrorf("putObjectHandler: set posix permission fail: requestID(%v) volume(%v) path(%v) err(%v)",
+			GetRequestID(r), vol.Name(), param.Object(), err)
+		return
+	}
 
 	// set response header
 	w.Header()[ETag] = []string{wrapUnescapedQuot(fsFileInfo.ETag)}
+	if fsFileInfo.Checksum != "" {
+		w.Header().Set(ChecksumHeader(fsFileInfo.ChecksumAlgorithm), fsFileInfo.Checksum)
+	}
 }
 
 // Post object
@@ -1460,6 +1535,13 @@ func (o *ObjectNode) postObjectHandler(w http.ResponseWriter, r *http.Request) {
 		requestMD5 = hex.EncodeToString(decoded)
 	}
 
+	// additional checksum check if specified in the request
+	checksum, ec := ParseFormChecksum(formReq)
+	if ec != nil {
+		errorCode = ec
+		return
+	}
+
 	// object lock check
 	objetLock, err := vol.metaLoader.loadObjectLock()
 	if err != nil {
@@ -1496,6 +1578,11 @@ func (o *ObjectNode) postObjectHandler(w http.ResponseWriter, r *http.Request) {
 			errorCode.ErrorMessage = fmt.Sprintf("%s (%v)", errorCode.ErrorMessage, err)
 			return
 		}
+		if err = o.checkPublicACL(vol, aclInfo); err != nil {
+			log.LogErrorf("postObjectHandler: public acl denied: requestID(%v) volume(%v) acl(%v) err(%v)",
+				GetRequestID(r), param.Bucket(), acl, err)
+			return
+		}
 	}
 
 	var tagging *Tagging
@@ -1603,6 +1690,7 @@ func (o *ObjectNode) postObjectHandler(w http.ResponseWriter, r *http.Request) {
 		Expires:      expires,
 		ACL:          aclInfo,
 		ObjectLock:   objetLock,
+		Checksum:     checksum,
 	}
 	start := time.Now()
 	fsFileInfo, err := vol.PutObject(key, reader, putOpt)
@@ -1621,10 +1709,18 @@ func (o *ObjectNode) postObjectHandler(w http.ResponseWriter, r *http.Request) {
 		errorCode = BadDigest
 		return
 	}
+	if err = vol.setPosixPermission(fsFileInfo.Inode, userInfo.UserID, aclInfo); err != nil {
+		log.LogErrorf("postObjectHandler: set posix permission fail: requestID(%v) volume(%v) path(%v) err(%v)",
+			GetRequestID(r), vol.Name(), key, err)
+		return
+	}
 
 	// set response header
 	etag := wrapUnescapedQuot(fsFileInfo.ETag)
 	w.Header()[ETag] = []string{etag}
+	if fsFileInfo.Checksum != "" {
+		w.Header().Set(ChecksumHeader(fsFileInfo.ChecksumAlgorithm), fsFileInfo.Checksum)
+	}
 
 	// return response depending on success_action_xxx parameter
 	if successRedirectURL != nil {
@@ -1708,7 +1804,15 @@ func (o *ObjectNode) deleteObjectHandler(w http.ResponseWriter, r *http.Request)
 
 	// Delete file
 	start := time.Now()
-	err = vol.DeletePath(param.Object())
+	if isBypassGovernanceRequest(r) {
+		if !o.allowedBypassGovernance(param, vol) {
+			errorCode = AccessDenied
+			return
+		}
+		err = vol.DeletePathBypassGovernance(param.Object())
+	} else {
+		err = vol.DeletePath(param.Object())
+	}
 	span.AppendTrackLog("file.d", start, err)
 	if err != nil {
 		log.LogErrorf("deleteObjectHandler: Volume delete file fail: "+
@@ -1970,6 +2074,11 @@ func (o *ObjectNode) putObjectXAttrHandler(w http.ResponseWriter, r *http.Reques
 	if len(key) == 0 {
 		return
 	}
+	// the object lock attributes can only be changed by the object lock apis
+	if isObjectLockXAttr(key) {
+		errorCode = AccessDenied
+		return
+	}
 
 	start := time.Now()
 	err = vol.SetXAttr(param.object, key, []byte(value), true)
@@ -2093,6 +2202,10 @@ func (o *ObjectNode) deleteObjectXAttrHandler(w http.ResponseWriter, r *http.Req
 		errorCode = InvalidArgument
 		return
 	}
+	if isObjectLockXAttr(xattrKey) {
+		errorCode = AccessDenied
+		return
+	}
 
 	start := time.Now()
 	err = vol.DeleteXAttr(param.object, xattrKey)
@@ -2221,7 +2334,7 @@ func (o *ObjectNode) getObjectRetentionHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 	var objectRetention ObjectRetention
-	objectRetention.Mode = ComplianceMode
+	objectRetention.Mode = objectRetentionMode(xattrs)
 	objectRetention.RetainUntilDate = RetentionDate{Time: time.Unix(0, retainUntilDateInt64).UTC()}
 	b, err := xml.Marshal(objectRetention)
 	if err != nil {
@@ -2233,6 +2346,214 @@ func (o *ObjectNode) getObjectRetentionHandler(w http.ResponseWriter, r *http.Re
 	writeSuccessResponseXML(w, b)
 }
 
+// PutObjectRetention
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutObjectRetention.html
+func (o *ObjectNode) putObjectRetentionHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+
+	span := trace.SpanFromContextSafe(r.Context())
+	defer func() {
+		o.errorResponse(w, r, err, errorCode)
+	}()
+
+	// check args
+	param := ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	if param.Object() == "" {
+		errorCode = InvalidKey
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("putObjectRetentionHandler: load volume fail: requestID(%v) err(%v)",
+			GetRequestID(r), err)
+		return
+	}
+	if errorCode = checkObjectLockEnabled(vol); errorCode != nil {
+		return
+	}
+
+	var body []byte
+	if body, err = io.ReadAll(io.LimitReader(r.Body, MaxObjectLockSize+1)); err != nil {
+		log.LogErrorf("putObjectRetentionHandler: read request body fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), vol.Name(), err)
+		return
+	}
+	if len(body) > MaxObjectLockSize {
+		errorCode = EntityTooLarge
+		return
+	}
+	var retention *ObjectRetention
+	if retention, err = ParseObjectRetentionFromXML(body); err != nil {
+		log.LogErrorf("putObjectRetentionHandler: parse retention fail: requestID(%v) volume(%v) retention(%v) err(%v)",
+			GetRequestID(r), vol.Name(), string(body), err)
+		return
+	}
+
+	// get object meta
+	start := time.Now()
+	_, xattrs, err := vol.ObjectMeta(param.Object())
+	span.AppendTrackLog("meta.r", start, err)
+	if err != nil {
+		log.LogErrorf("putObjectRetentionHandler: get file meta fail: requestId(%v) volume(%v) path(%v) err(%v)",
+			GetRequestID(r), vol.Name(), param.Object(), err)
+		if err == syscall.ENOENT {
+			errorCode = NoSuchKey
+		}
+		return
+	}
+	bypassGovernance := isBypassGovernanceRequest(r) && o.allowedBypassGovernance(param, vol)
+	if errorCode = checkRetentionUpdate(xattrs, retention, bypassGovernance); errorCode != nil {
+		log.LogWarnf("putObjectRetentionHandler: retention update not allowed: requestId(%v) volume(%v) path(%v) "+
+			"retainUntilDate(%v) mode(%v) retention(%+v) bypassGovernance(%v)", GetRequestID(r), vol.Name(), param.Object(),
+			string(xattrs.Get(XAttrKeyOSSLock)), string(xattrs.Get(XAttrKeyOSSLockMode)), retention, bypassGovernance)
+		return
+	}
+
+	start = time.Now()
+	err = storeObjectRetention(vol, param.Object(), retention, bypassGovernance)
+	span.AppendTrackLog("xattr.w", start, err)
+	if err != nil {
+		log.LogErrorf("putObjectRetentionHandler: store retention fail: requestId(%v) volume(%v) path(%v) retention(%+v) err(%v)",
+			GetRequestID(r), vol.Name(), param.Object(), retention, err)
+		switch err {
+		case syscall.ENOENT:
+			errorCode = NoSuchKey
+		case syscall.EPERM:
+			// the retention is changed since checked, and refused by the meta node
+			errorCode = AccessDenied
+		}
+		return
+	}
+}
+
+// GetObjectLegalHold
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetObjectLegalHold.html
+func (o *ObjectNode) getObjectLegalHoldHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+
+	span := trace.SpanFromContextSafe(r.Context())
+	defer func() {
+		o.errorResponse(w, r, err, errorCode)
+	}()
+
+	// check args
+	param := ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	if param.Object() == "" {
+		errorCode = InvalidKey
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("getObjectLegalHoldHandler: load volume fail: requestID(%v) err(%v)",
+			GetRequestID(r), err)
+		return
+	}
+
+	// get object meta
+	start := time.Now()
+	_, xattrs, err := vol.ObjectMeta(param.Object())
+	span.AppendTrackLog("meta.r", start, err)
+	if err != nil {
+		log.LogErrorf("getObjectLegalHoldHandler: get file meta fail: requestId(%v) volume(%v) path(%v) err(%v)",
+			GetRequestID(r), vol.Name(), param.Object(), err)
+		if err == syscall.ENOENT {
+			errorCode = NoSuchKey
+		}
+		return
+	}
+	status := string(xattrs.Get(XAttrKeyOSSLegalHold))
+	if status == "" {
+		errorCode = NoSuchObjectLockConfiguration
+		return
+	}
+	legalHold := ObjectLegalHold{Status: status}
+	b, err := xml.Marshal(legalHold)
+	if err != nil {
+		log.LogErrorf("getObjectLegalHoldHandler: xml marshal fail: requestId(%v) volume(%v) result(%v) err(%v)",
+			GetRequestID(r), vol.Name(), legalHold, err)
+		return
+	}
+
+	writeSuccessResponseXML(w, b)
+}
+
+// PutObjectLegalHold
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutObjectLegalHold.html
+func (o *ObjectNode) putObjectLegalHoldHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+
+	span := trace.SpanFromContextSafe(r.Context())
+	defer func() {
+		o.errorResponse(w, r, err, errorCode)
+	}()
+
+	// check args
+	param := ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	if param.Object() == "" {
+		errorCode = InvalidKey
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("putObjectLegalHoldHandler: load volume fail: requestID(%v) err(%v)",
+			GetRequestID(r), err)
+		return
+	}
+	if errorCode = checkObjectLockEnabled(vol); errorCode != nil {
+		return
+	}
+
+	var body []byte
+	if body, err = io.ReadAll(io.LimitReader(r.Body, MaxObjectLockSize+1)); err != nil {
+		log.LogErrorf("putObjectLegalHoldHandler: read request body fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), vol.Name(), err)
+		return
+	}
+	if len(body) > MaxObjectLockSize {
+		errorCode = EntityTooLarge
+		return
+	}
+	var legalHold *ObjectLegalHold
+	if legalHold, err = ParseObjectLegalHoldFromXML(body); err != nil {
+		log.LogErrorf("putObjectLegalHoldHandler: parse legal hold fail: requestID(%v) volume(%v) legalHold(%v) err(%v)",
+			GetRequestID(r), vol.Name(), string(body), err)
+		return
+	}
+
+	start := time.Now()
+	err = vol.SetXAttr(param.Object(), XAttrKeyOSSLegalHold, []byte(legalHold.Status), false)
+	span.AppendTrackLog("xattr.w", start, err)
+	if err != nil {
+		log.LogErrorf("putObjectLegalHoldHandler: set legal hold xattr fail: requestID(%v) volume(%v) path(%v) err(%v)",
+			GetRequestID(r), vol.Name(), param.Object(), err)
+		if err == syscall.ENOENT {
+			errorCode = NoSuchKey
+		}
+		return
+	}
+}
+
 func parsePartInfo(partNumber uint64, fileSize uint64) (uint64, uint64, uint64, uint64) {
 	var partSize uint64
 	var partCount uint64
@@ -2288,3 +2609,101 @@ func VerifyContentLength(r *http.Request, bodyLimit int64) (int64, *ErrorCode) {
 	}
 	return length, nil
 }
+
+// GetObjectAttributes
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetObjectAttributes.html
+func (o *ObjectNode) getObjectAttributesHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+
+	span := trace.SpanFromContextSafe(r.Context())
+	defer func() {
+		o.errorResponse(w, r, err, errorCode)
+	}()
+
+	// check args
+	param := ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	if param.Object() == "" {
+		errorCode = InvalidKey
+		return
+	}
+	attributes, errorCode := parseObjectAttributes(r.Header.Values(XAmzObjectAttributes))
+	if errorCode != nil {
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("getObjectAttributesHandler: load volume fail: requestID(%v) err(%v)",
+			GetRequestID(r), err)
+		return
+	}
+
+	// get object meta
+	start := time.Now()
+	fileInfo, _, err := vol.ObjectMeta(param.Object())
+	span.AppendTrackLog("meta.r", start, err)
+	if err != nil {
+		log.LogErrorf("getObjectAttributesHandler: get file meta fail: requestId(%v) volume(%v) path(%v) err(%v)",
+			GetRequestID(r), vol.Name(), param.Object(), err)
+		if err == syscall.ENOENT {
+			errorCode = NoSuchKey
+		}
+		return
+	}
+
+	result := GetObjectAttributesResult{}
+	if attributes[ObjectAttributeETag] {
+		result.ETag = fileInfo.ETag
+	}
+	if attributes[ObjectAttributeChecksum] && fileInfo.Checksum != "" {
+		checksum := NewChecksumResult(fileInfo.ChecksumAlgorithm, fileInfo.Checksum)
+		result.Checksum = &checksum
+	}
+	if attributes[ObjectAttributeObjectParts] {
+		if partsCount := ParseETagValue(fileInfo.ETag).PartNum; partsCount > 0 {
+			result.ObjectParts = &ObjectPartsResult{PartsCount: partsCount}
+		}
+	}
+	if attributes[ObjectAttributeStorageClass] {
+		result.StorageClass = StorageClassStandard
+	}
+	if attributes[ObjectAttributeObjectSize] {
+		result.ObjectSize = &fileInfo.Size
+	}
+	response, err := MarshalXMLEntity(result)
+	if err != nil {
+		log.LogErrorf("getObjectAttributesHandler: xml marshal fail: requestId(%v) volume(%v) result(%v) err(%v)",
+			GetRequestID(r), vol.Name(), result, err)
+		return
+	}
+
+	w.Header().Set(LastModified, formatTimeRFC1123(fileInfo.ModifyTime))
+	writeSuccessResponseXML(w, response)
+}
+
+func parseObjectAttributes(values []string) (map[string]bool, *ErrorCode) {
+	attributes := make(map[string]bool)
+	for _, value := range values {
+		for _, attr := range strings.Split(value, ",") {
+			attr = strings.TrimSpace(attr)
+			switch attr {
+			case ObjectAttributeETag, ObjectAttributeChecksum, ObjectAttributeObjectParts,
+				ObjectAttributeStorageClass, ObjectAttributeObjectSize:
+				attributes[attr] = true
+			case "":
+			default:
+				return nil, InvalidObjectAttributes
+			}
+		}
+	}
+	if len(attributes) == 0 {
+		return nil, InvalidObjectAttributes
+	}
+	return attributes, nil
+}
@@ -97,6 +97,12 @@ func (o *ObjectNode) traceMiddleware(next http.Handler) http.Handler {
 			if o.externalAudit != nil {
 				o.externalAudit.Logger(w, r)
 			}
+			if o.accessLogger != nil {
+				o.accessLogger.Log(w, r)
+			}
+			if o.bucketMetrics != nil {
+				o.bucketMetrics.Record(w, r)
+			}
 		}()
 
 		requestID, err := generateRequestID()
@@ -161,6 +167,11 @@ func (o *ObjectNode) traceMiddleware(next http.Handler) http.Handler {
 func (o *ObjectNode) authMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(
 		func(w http.ResponseWriter, r *http.Request) {
+			// website requests are always anonymous
+			if o.isWebsiteRequest(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
 			// parse authentication
 			auth, err := NewAuth(r)
 			if err != nil && err == MissingSecurityElement {
@@ -195,9 +206,13 @@ func (o *ObjectNode) policyCheckMiddleware(next http.Handler) http.Handler {
 // ContentMiddleware returns a middleware handler to process reader for content.
 // If the request contains the "X-amz-Decoded-Content-Length" header, it means that the data
 // in the request body is chunked. Use ChunkedReader to parse the data.
+// The unsigned chunks with trailing headers are parsed by TrailerChunkedReader.
 func (o *ObjectNode) contentMiddleware(next http.Handler) http.Handler {
 	var handlerFunc http.HandlerFunc = func(w http.ResponseWriter, r *http.Request) {
-		if r.Header.Get(XAmzDecodedContentLength) != "" && r.Header.Get(ContentEncoding) != streamingContentEncoding {
+		if r.Header.Get(XAmzContentSha256) == StreamingUnsignedPayloadTrailer {
+			r.Body = NewTrailerChunkedReader(r.Body)
+			log.LogDebugf("contentMiddleware: trailer chunk reader inited: requestID(%v)", GetRequestID(r))
+		} else if r.Header.Get(XAmzDecodedContentLength) != "" && r.Header.Get(ContentEncoding) != streamingContentEncoding {
 			r.Body = NewClosableChunkedReader(r.Body)
 			log.LogDebugf("contentMiddleware: chunk reader inited: requestID(%v)", GetRequestID(r))
 		}
@@ -118,6 +118,9 @@ const (
 
 	UnsignedPayload   = "UNSIGNED-PAYLOAD"
 	EmptyStringSHA256 = `e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855`
+
+	StreamingPayloadTrailer         = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD-TRAILER"
+	StreamingUnsignedPayloadTrailer = "STREAMING-UNSIGNED-PAYLOAD-TRAILER"
 )
 
 type Auther interface {
@@ -238,10 +241,18 @@ func (o *ObjectNode) validateAuthInfo(r *http.Request, auth Auther) (err error)
 				GetRequestID(r), param.bucket, userPolicy.OwnVols)
 			return AccessDenied
 		}
+		var restricted bool
+		if !IsAccountLevelApi(param.apiName) && param.bucket != "" {
+			if restricted, err = o.restrictedAccount(param.bucket, uid); err != nil {
+				log.LogErrorf("validateAuthInfo: load public access block fail: requestID(%v) bucket(%v) err(%v)",
+					GetRequestID(r), param.bucket, err)
+				return err
+			}
+		}
 		action := "s3:" + param.apiName
-		if !stsInfo.Policy.IsAllow(action, param.bucket, param.object) {
-			log.LogErrorf("validateAuthInfo: sts policy not allow: requestID(%v) policy(%v) api(%v) resource(%v)",
-				GetRequestID(r), *stsInfo.Policy, param.apiName, param.resource)
+		if !stsInfo.Policy.IsAllowOnBucket(action, param.bucket, param.object, restricted) {
+			log.LogErrorf("validateAuthInfo: sts policy not allow: requestID(%v) policy(%v) api(%v) resource(%v) restricted(%v)",
+				GetRequestID(r), *stsInfo.Policy, param.apiName, param.resource, restricted)
 			return AccessDenied
 		}
 	}
@@ -181,7 +181,9 @@ func (auth *HeaderAuth) SignatureMatch(secretKey string, wildcards Wildcards) bo
 		return auth.signature == auth.buildSignatureV2(secretKey, wildcards)
 	case signatureV4:
 		var signature string
-		if auth.request.Header.Get(XAmzDecodedContentLength) != "" &&
+		// the chunks of unsigned payload with trailer are not signed, which are parsed by content middleware
+		if auth.request.Header.Get(XAmzContentSha256) != StreamingUnsignedPayloadTrailer &&
+			auth.request.Header.Get(XAmzDecodedContentLength) != "" &&
 			auth.request.Header.Get(ContentEncoding) == streamingContentEncoding {
 			signature = auth.buildSignatureChunk(secretKey)
 		} else {
@@ -233,7 +235,11 @@ func (auth *HeaderAuth) buildSignatureChunk(secretKey string) string {
 
 	signature := calculateSignature(signingKey, auth.stringToSign)
 
-	auth.request.Body = NewSignChunkedReader(auth.request.Body, signingKey, scope, cred.Date, signature)
+	reader := NewSignChunkedReader(auth.request.Body, signingKey, scope, cred.Date, signature)
+	if req.Header.Get(XAmzContentSha256) == StreamingPayloadTrailer {
+		reader.trailer = make(http.Header)
+	}
+	auth.request.Body = reader
 
 	return signature
 }
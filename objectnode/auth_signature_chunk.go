@@ -20,6 +20,8 @@ import (
 	"encoding/hex"
 	"errors"
 	"io"
+	"net/http"
+	"sort"
 	"strings"
 )
 
@@ -48,6 +50,8 @@ type SignChunkedReader struct {
 	scope    string // <yyyymmdd>/<region>/<service>/aws4_request
 	datetime string // 20130524T000000Z
 	prevSig  string // previous signature
+
+	trailer http.Header // trailing headers of STREAMING-AWS4-HMAC-SHA256-PAYLOAD-TRAILER
 }
 
 func (cr *SignChunkedReader) Read(p []byte) (n int, err error) {
@@ -96,6 +100,12 @@ func (cr *SignChunkedReader) fillCheck() error {
 		if signature != cr.getSignature(cr.buf.Bytes()) {
 			return errors.New("signature of chunk does not match")
 		}
+		if cr.trailer != nil {
+			cr.prevSig = signature
+			if err = cr.readTrailer(); err != nil {
+				return err
+			}
+		}
 		return io.EOF
 	}
 
@@ -137,6 +147,43 @@ func (cr *SignChunkedReader) getSignature(data []byte) string {
 	return hex.EncodeToString(MakeHmacSha256(cr.key, []byte(stringToSign)))
 }
 
+// readTrailer reads the trailing headers following the last chunk and verifies them
+// with the "x-amz-trailer-signature" trailer.
+func (cr *SignChunkedReader) readTrailer() error {
+	trailer := make(http.Header)
+	if err := readChunkTrailer(cr.reader, trailer); err != nil {
+		return err
+	}
+	signature := trailer.Get(XAmzTrailerSignature)
+	trailer.Del(XAmzTrailerSignature)
+
+	names := make([]string, 0, len(trailer))
+	for name := range trailer {
+		names = append(names, strings.ToLower(name))
+	}
+	sort.Strings(names)
+	canonical := bytes.NewBuffer(nil)
+	for _, name := range names {
+		canonical.WriteString(name + ":" + trailer.Get(name) + "\n")
+	}
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256-TRAILER",
+		cr.datetime,
+		cr.scope,
+		cr.prevSig,
+		hex.EncodeToString(MakeSha256(canonical.Bytes())),
+	}, "\n")
+	if signature != hex.EncodeToString(MakeHmacSha256(cr.key, []byte(stringToSign))) {
+		return errors.New("signature of trailer does not match")
+	}
+	cr.trailer = trailer
+	return nil
+}
+
+func (cr *SignChunkedReader) Trailer() http.Header {
+	return cr.trailer
+}
+
 func (cr *SignChunkedReader) Close() error {
 	return nil
 }
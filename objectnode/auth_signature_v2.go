@@ -29,6 +29,7 @@ var signV2SubresourceQueries = map[string]bool{
 	"cors":           true,
 	"delete":         true,
 	"encryption":     true,
+	"legal-hold":     true,
 	"lifecycle":      true,
 	"location":       true,
 	"logging":        true,
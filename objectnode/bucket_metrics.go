@@ -0,0 +1,731 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/exporter"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	MaxBucketMetricsSize = 1 << 14 // 16KB
+	// the exported series of a bucket are bounded by the number of the metrics configurations
+	MaxBucketMetricsConfigurations = 10
+
+	bucketMetricsPeriod    = time.Minute
+	bucketMetricsRetention = time.Hour
+
+	bucketMetricsFilterLabel = "filter"
+)
+
+var (
+	MetricsErrIDMismatch       = &ErrorCode{ErrorCode: "InvalidArgument", ErrorMessage: "The metrics configuration ID in the request does not match the ID in the body.", StatusCode: http.StatusBadRequest}
+	MetricsErrInvalidID        = &ErrorCode{ErrorCode: "InvalidArgument", ErrorMessage: "The metrics configuration ID is invalid.", StatusCode: http.StatusBadRequest}
+	MetricsErrInvalidFilter    = &ErrorCode{ErrorCode: "InvalidArgument", ErrorMessage: "The metrics configuration filter is invalid or not supported.", StatusCode: http.StatusBadRequest}
+	MetricsErrTooManyConfigs   = &ErrorCode{ErrorCode: "TooManyConfigurations", ErrorMessage: "You are attempting to create a new configuration but have already reached the 10-configuration limit.", StatusCode: http.StatusBadRequest}
+	NoSuchMetricsConfiguration = &ErrorCode{ErrorCode: "NoSuchConfiguration", ErrorMessage: "The specified configuration does not exist.", StatusCode: http.StatusNotFound}
+)
+
+// MetricsConfiguration is the request metrics configuration of the bucket, the requests to the objects
+// matching the filter are counted, and all the requests to the bucket are counted if there is no filter.
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_MetricsConfiguration.html
+type MetricsConfiguration struct {
+	XMLName xml.Name       `xml:"MetricsConfiguration"`
+	Xmlns   string         `xml:"xmlns,attr,omitempty"`
+	ID      string         `xml:"Id"`
+	Filter  *MetricsFilter `xml:"Filter,omitempty"`
+}
+
+type MetricsFilter struct {
+	AccessPointArn string              `xml:"AccessPointArn,omitempty"`
+	Prefix         string              `xml:"Prefix,omitempty"`
+	Tag            *Tag                `xml:"Tag,omitempty"`
+	And            *MetricsAndOperator `xml:"And,omitempty"`
+}
+
+type MetricsAndOperator struct {
+	AccessPointArn string `xml:"AccessPointArn,omitempty"`
+	Prefix         string `xml:"Prefix,omitempty"`
+	Tags           []Tag  `xml:"Tag,omitempty"`
+}
+
+// BucketMetricsConfigurations is all the metrics configurations of the bucket stored in the xattr.
+type BucketMetricsConfigurations struct {
+	XMLName        xml.Name                `xml:"MetricsConfigurations"`
+	Configurations []*MetricsConfiguration `xml:"MetricsConfiguration"`
+}
+
+// ListMetricsConfigurationsResult is the result of ListBucketMetricsConfigurations, the configurations
+// are never truncated as there are at most MaxBucketMetricsConfigurations of them.
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_ListBucketMetricsConfigurations.html
+type ListMetricsConfigurationsResult struct {
+	XMLName               xml.Name                `xml:"ListMetricsConfigurationsResult"`
+	Xmlns                 string                  `xml:"xmlns,attr,omitempty"`
+	IsTruncated           bool                    `xml:"IsTruncated"`
+	MetricsConfigurations []*MetricsConfiguration `xml:"MetricsConfiguration"`
+}
+
+func parseMetricsConfiguration(body []byte, id string) (*MetricsConfiguration, *ErrorCode) {
+	conf := &MetricsConfiguration{}
+	if err := xml.Unmarshal(body, conf); err != nil {
+		return nil, MalformedXML
+	}
+	if errorCode := conf.validate(id); errorCode != nil {
+		return nil, errorCode
+	}
+	return conf, nil
+}
+
+func (c *MetricsConfiguration) validate(id string) *ErrorCode {
+	if c.ID == "" || len(c.ID) > MaxIdLength || strings.ContainsAny(c.ID, "/:") {
+		return MetricsErrInvalidID
+	}
+	if c.ID != id {
+		return MetricsErrIDMismatch
+	}
+	if c.Filter == nil {
+		return nil
+	}
+	// the access points are not supported, and only one of the prefix, tag and and-operator is allowed
+	f := c.Filter
+	if f.AccessPointArn != "" || (f.And != nil && f.And.AccessPointArn != "") {
+		return MetricsErrInvalidFilter
+	}
+	conditions := 0
+	if f.Prefix != "" {
+		conditions++
+	}
+	if f.Tag != nil {
+		conditions++
+	}
+	if f.And != nil {
+		conditions++
+	}
+	if conditions != 1 {
+		return MetricsErrInvalidFilter
+	}
+	prefix, tags := c.filterRule()
+	if len(prefix) > MaxKeyLength {
+		return KeyTooLong
+	}
+	if f.And != nil && len(tags) == 0 && prefix == "" {
+		return MetricsErrInvalidFilter
+	}
+	if len(tags) > TaggingCounts {
+		return TooManyTags
+	}
+	keys := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		if !tag.isValid() {
+			return InvalidTag
+		}
+		if _, ok := keys[tag.Key]; ok {
+			return DuplicateTagKey
+		}
+		keys[tag.Key] = struct{}{}
+	}
+	return nil
+}
+
+// filterRule returns the prefix and tags the objects must match.
+func (c *MetricsConfiguration) filterRule() (prefix string, tags []Tag) {
+	switch {
+	case c.Filter == nil:
+	case c.Filter.And != nil:
+		prefix, tags = c.Filter.And.Prefix, c.Filter.And.Tags
+	case c.Filter.Tag != nil:
+		tags = []Tag{*c.Filter.Tag}
+	default:
+		prefix = c.Filter.Prefix
+	}
+	return
+}
+
+// match reports whether the request is counted, the tags of the object are loaded only if they are filtered.
+func (c *MetricsConfiguration) match(object string, loadTags func() map[string]string) bool {
+	if c.Filter == nil {
+		return true
+	}
+	// the filtered metrics only count the requests to the objects
+	prefix, tags := c.filterRule()
+	if object == "" || !strings.HasPrefix(object, prefix) {
+		return false
+	}
+	if len(tags) == 0 {
+		return true
+	}
+	objectTags := loadTags()
+	for _, tag := range tags {
+		if v, ok := objectTags[tag.Key]; !ok || v != tag.Value {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *BucketMetricsConfigurations) get(id string) *MetricsConfiguration {
+	if c == nil {
+		return nil
+	}
+	for _, conf := range c.Configurations {
+		if conf.ID == id {
+			return conf
+		}
+	}
+	return nil
+}
+
+func listMetricsConfigurations(configs *BucketMetricsConfigurations) *ListMetricsConfigurationsResult {
+	result := &ListMetricsConfigurationsResult{
+		Xmlns:                 XMLNS,
+		MetricsConfigurations: make([]*MetricsConfiguration, 0),
+	}
+	if configs != nil {
+		result.MetricsConfigurations = append(result.MetricsConfigurations, configs.Configurations...)
+	}
+	sort.Slice(result.MetricsConfigurations, func(i, j int) bool {
+		return result.MetricsConfigurations[i].ID < result.MetricsConfigurations[j].ID
+	})
+	return result
+}
+
+func storeBucketMetrics(bytes []byte, vol *Volume) (err error) {
+	return vol.store.Put(vol.name, bucketRootPath, XAttrKeyOSSMetrics, bytes)
+}
+
+func deleteBucketMetrics(vol *Volume) (err error) {
+	return vol.store.Delete(vol.name, bucketRootPath, XAttrKeyOSSMetrics)
+}
+
+// The request metrics in the same names and units as the CloudWatch request metrics of S3.
+// Reference: https://docs.aws.amazon.com/AmazonS3/latest/userguide/metrics-dimensions.html
+const (
+	metricAllRequests = iota
+	metricGetRequests
+	metricPutRequests
+	metricDeleteRequests
+	metricHeadRequests
+	metricPostRequests
+	metricListRequests
+	metricBytesDownloaded
+	metricBytesUploaded
+	metric4xxErrors
+	metric5xxErrors
+	metricTotalRequestLatency
+	metricCount
+)
+
+var bucketMetricNames = [metricCount]string{
+	"AllRequests", "GetRequests", "PutRequests", "DeleteRequests", "HeadRequests", "PostRequests", "ListRequests",
+	"BytesDownloaded", "BytesUploaded", "4xxErrors", "5xxErrors", "TotalRequestLatency",
+}
+
+var bucketMetricUnits = [metricCount]string{
+	"Count", "Count", "Count", "Count", "Count", "Count", "Count",
+	"Bytes", "Bytes", "Count", "Count", "Milliseconds",
+}
+
+func parseBucketMetricName(name string) (int, bool) {
+	for i, n := range bucketMetricNames {
+		if n == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// metricStatistic is the statistic set of the datapoints of a metric.
+type metricStatistic struct {
+	sum         float64
+	sampleCount float64
+	minimum     float64
+	maximum     float64
+}
+
+func (s *metricStatistic) add(v float64) {
+	s.merge(metricStatistic{sum: v, sampleCount: 1, minimum: v, maximum: v})
+}
+
+func (s *metricStatistic) merge(o metricStatistic) {
+	if o.sampleCount == 0 {
+		return
+	}
+	if s.sampleCount == 0 {
+		*s = o
+		return
+	}
+	s.sum += o.sum
+	s.sampleCount += o.sampleCount
+	s.minimum = math.Min(s.minimum, o.minimum)
+	s.maximum = math.Max(s.maximum, o.maximum)
+}
+
+type bucketMetricsPoint [metricCount]metricStatistic
+
+type bucketMetricsKey struct {
+	bucket string
+	filter string
+}
+
+// bucketMetricsSample is the measurement of a request to the bucket.
+type bucketMetricsSample struct {
+	api         string
+	requestType int
+	statusCode  int
+	downloaded  int64
+	uploaded    int64
+	startTime   time.Time
+	latency     time.Duration
+}
+
+func newBucketMetricsSample(w http.ResponseWriter, r *http.Request) *bucketMetricsSample {
+	param := ParseRequestParam(r)
+	s := &bucketMetricsSample{
+		api:         param.API(),
+		requestType: bucketMetricsRequestType(r, param),
+		statusCode:  http.StatusOK,
+		startTime:   time.Now(),
+	}
+	if rs, ok := w.(*ResponseStater); ok {
+		s.statusCode = rs.StatusCode
+		s.downloaded = rs.Written
+		s.startTime = rs.StartTime
+	}
+	s.latency = time.Since(s.startTime)
+	if size, err := strconv.ParseInt(r.Header.Get(XAmzDecodedContentLength), 10, 64); err == nil {
+		s.uploaded = size
+	} else if r.ContentLength > 0 {
+		s.uploaded = r.ContentLength
+	}
+	return s
+}
+
+// bucketMetricsRequestType returns the type metric of the request, -1 if it is only counted by AllRequests.
+func bucketMetricsRequestType(r *http.Request, param *RequestParam) int {
+	switch param.Action() {
+	case proto.OSSListObjectsAction, proto.OSSListObjectVersionsAction:
+		return metricListRequests
+	case proto.OSSDeleteObjectsAction:
+		// the multiple objects deletion is counted as the delete requests
+		return metricDeleteRequests
+	case proto.OSSPostObjectAction:
+		return metricPostRequests
+	}
+	if param.Object() == "" {
+		return -1
+	}
+	switch r.Method {
+	case http.MethodGet:
+		return metricGetRequests
+	case http.MethodPut:
+		return metricPutRequests
+	case http.MethodDelete:
+		return metricDeleteRequests
+	case http.MethodHead:
+		return metricHeadRequests
+	case http.MethodPost:
+		return metricPostRequests
+	}
+	return -1
+}
+
+func (s *bucketMetricsSample) point() *bucketMetricsPoint {
+	p := &bucketMetricsPoint{}
+	p[metricAllRequests].add(1)
+	if s.requestType >= 0 {
+		p[s.requestType].add(1)
+	}
+	if s.downloaded > 0 {
+		p[metricBytesDownloaded].add(float64(s.downloaded))
+	}
+	if s.uploaded > 0 {
+		p[metricBytesUploaded].add(float64(s.uploaded))
+	}
+	var clientError, serverError float64
+	if s.statusCode >= http.StatusInternalServerError {
+		serverError = 1
+	} else if s.statusCode >= http.StatusBadRequest {
+		clientError = 1
+	}
+	p[metric4xxErrors].add(clientError)
+	p[metric5xxErrors].add(serverError)
+	p[metricTotalRequestLatency].add(float64(s.latency.Milliseconds()))
+	return p
+}
+
+// export exports the sample to prometheus, the labels are limited to the bucket, the configuration
+// and the api names to keep the cardinality of the series bounded.
+func (s *bucketMetricsSample) export(bucket, filter string) {
+	labels := map[string]string{exporter.Vol: bucket, bucketMetricsFilterLabel: filter}
+	exporter.NewCounter("bucket_requests").AddWithLabels(1,
+		map[string]string{exporter.Vol: bucket, bucketMetricsFilterLabel: filter, exporter.Op: s.api})
+	if s.downloaded > 0 {
+		exporter.NewCounter("bucket_bytes_downloaded").AddWithLabels(s.downloaded, labels)
+	}
+	if s.uploaded > 0 {
+		exporter.NewCounter("bucket_bytes_uploaded").AddWithLabels(s.uploaded, labels)
+	}
+	if s.statusCode >= http.StatusBadRequest {
+		errorType := "4xx"
+		if s.statusCode >= http.StatusInternalServerError {
+			errorType = "5xx"
+		}
+		exporter.NewCounter("bucket_errors").AddWithLabels(1,
+			map[string]string{exporter.Vol: bucket, bucketMetricsFilterLabel: filter, exporter.Type: errorType})
+	}
+	exporter.NewTPFrom("bucket_request_latency", s.startTime).SetWithLabels(labels)
+}
+
+// metricDatapoint is the statistic set of a metric in a period.
+type metricDatapoint struct {
+	timestamp time.Time
+	metricStatistic
+}
+
+// BucketMetrics collects the request metrics of the buckets with metrics configurations. The metrics
+// are exported to prometheus, and the datapoints of each minute within the retention are kept in
+// memory for GetMetricStatistics, which only cover the requests served by this node.
+type BucketMetrics struct {
+	getVol func(bucket string) (*Volume, error)
+
+	mu     sync.RWMutex
+	series map[bucketMetricsKey]map[int64]*bucketMetricsPoint
+
+	stopC chan struct{}
+	wg    sync.WaitGroup
+}
+
+func NewBucketMetrics(getVol func(bucket string) (*Volume, error)) *BucketMetrics {
+	return &BucketMetrics{
+		getVol: getVol,
+		series: make(map[bucketMetricsKey]map[int64]*bucketMetricsPoint),
+		stopC:  make(chan struct{}),
+	}
+}
+
+func (m *BucketMetrics) Start() {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		ticker := time.NewTicker(bucketMetricsPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.expire(time.Now())
+			case <-m.stopC:
+				return
+			}
+		}
+	}()
+}
+
+func (m *BucketMetrics) Close() {
+	close(m.stopC)
+	m.wg.Wait()
+}
+
+// Record counts the request by the metrics configurations of its bucket.
+func (m *BucketMetrics) Record(w http.ResponseWriter, r *http.Request) {
+	param := ParseRequestParam(r)
+	if param.Bucket() == "" {
+		return
+	}
+	vol, err := m.getVol(param.Bucket())
+	if err != nil {
+		return
+	}
+	configs, err := vol.metaLoader.loadMetrics()
+	if err != nil {
+		log.LogWarnf("BucketMetrics: load metrics fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), vol.Name(), err)
+		return
+	}
+	if configs == nil || len(configs.Configurations) == 0 {
+		return
+	}
+
+	var objectTags map[string]string
+	loadTags := func() map[string]string {
+		if objectTags == nil {
+			objectTags = loadBucketMetricsObjectTags(vol, r, param.Object())
+		}
+		return objectTags
+	}
+	sample := newBucketMetricsSample(w, r)
+	for _, conf := range configs.Configurations {
+		if !conf.match(param.Object(), loadTags) {
+			continue
+		}
+		sample.export(vol.Name(), conf.ID)
+		m.add(bucketMetricsKey{bucket: vol.Name(), filter: conf.ID}, sample.startTime, sample.point())
+	}
+}
+
+// loadBucketMetricsObjectTags returns the tags in the request to put the object, or the tags of the existing object.
+func loadBucketMetricsObjectTags(vol *Volume, r *http.Request, object string) map[string]string {
+	tags := make(map[string]string)
+	raw := r.Header.Get(XAmzTagging)
+	if raw == "" {
+		xattr, err := vol.GetXAttr(object, XAttrKeyOSSTagging)
+		if err != nil || xattr == nil {
+			return tags
+		}
+		raw = string(xattr.Get(XAttrKeyOSSTagging))
+	}
+	if tagging, err := ParseTagging(raw); err == nil {
+		for _, tag := range tagging.TagSet {
+			tags[tag.Key] = tag.Value
+		}
+	}
+	return tags
+}
+
+func (m *BucketMetrics) add(key bucketMetricsKey, t time.Time, p *bucketMetricsPoint) {
+	minute := t.Truncate(bucketMetricsPeriod).Unix()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	points, ok := m.series[key]
+	if !ok {
+		points = make(map[int64]*bucketMetricsPoint)
+		m.series[key] = points
+	}
+	point, ok := points[minute]
+	if !ok {
+		points[minute] = p
+		return
+	}
+	for i := range point {
+		point[i].merge(p[i])
+	}
+}
+
+// expire drops the datapoints out of the retention.
+func (m *BucketMetrics) expire(now time.Time) {
+	deadline := now.Add(-bucketMetricsRetention).Unix()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, points := range m.series {
+		for minute := range points {
+			if minute < deadline {
+				delete(points, minute)
+			}
+		}
+		if len(points) == 0 {
+			delete(m.series, key)
+		}
+	}
+}
+
+// statistics returns the statistics of the metric aggregated by the period in [start, end),
+// the periods without any datapoint are left out.
+func (m *BucketMetrics) statistics(key bucketMetricsKey, metric int, start, end time.Time, period time.Duration) []*metricDatapoint {
+	stats := make(map[int64]*metricDatapoint)
+	m.mu.RLock()
+	for minute, point := range m.series[key] {
+		t := time.Unix(minute, 0)
+		if t.Before(start) || !t.Before(end) || point[metric].sampleCount == 0 {
+			continue
+		}
+		periodStart := start.Add(t.Sub(start) / period * period)
+		dp, ok := stats[periodStart.Unix()]
+		if !ok {
+			dp = &metricDatapoint{timestamp: periodStart}
+			stats[periodStart.Unix()] = dp
+		}
+		dp.merge(point[metric])
+	}
+	m.mu.RUnlock()
+
+	datapoints := make([]*metricDatapoint, 0, len(stats))
+	for _, dp := range stats {
+		datapoints = append(datapoints, dp)
+	}
+	sort.Slice(datapoints, func(i, j int) bool { return datapoints[i].timestamp.Before(datapoints[j].timestamp) })
+	return datapoints
+}
+
+const (
+	cloudWatchXMLNS          = "http://monitoring.amazonaws.com/doc/2010-08-01/"
+	cloudWatchNamespaceS3    = "AWS/S3"
+	cloudWatchDimensionLimit = 30
+	cloudWatchDatapointLimit = 1440
+
+	cloudWatchGetMetricStatisticsValue = "GetMetricStatistics"
+	cloudWatchNamespaceKey             = "Namespace"
+	cloudWatchMetricNameKey            = "MetricName"
+	cloudWatchStartTimeKey             = "StartTime"
+	cloudWatchEndTimeKey               = "EndTime"
+	cloudWatchPeriodKey                = "Period"
+	cloudWatchDimensionNameKey         = "Dimensions.member.%d.Name"
+	cloudWatchDimensionValueKey        = "Dimensions.member.%d.Value"
+	cloudWatchStatisticKey             = "Statistics.member.%d"
+	cloudWatchExtendedStatisticKey     = "ExtendedStatistics.member.1"
+
+	cloudWatchDimensionBucketName = "BucketName"
+	cloudWatchDimensionFilterID   = "FilterId"
+
+	cloudWatchStatisticSampleCount = "SampleCount"
+	cloudWatchStatisticAverage     = "Average"
+	cloudWatchStatisticSum         = "Sum"
+	cloudWatchStatisticMinimum     = "Minimum"
+	cloudWatchStatisticMaximum     = "Maximum"
+)
+
+var (
+	CloudWatchErrMissingParameter = &ErrorCode{ErrorCode: "MissingParameter", ErrorMessage: "An input parameter that is mandatory for processing the request is not supplied.", StatusCode: http.StatusBadRequest}
+	CloudWatchErrInvalidParameter = &ErrorCode{ErrorCode: "InvalidParameterValue", ErrorMessage: "The value of an input parameter is bad or out-of-range.", StatusCode: http.StatusBadRequest}
+	CloudWatchErrTooManyPoints    = &ErrorCode{ErrorCode: "InvalidParameterCombination", ErrorMessage: "You have requested up to 1,440 datapoints, reduce the time range or increase the period.", StatusCode: http.StatusBadRequest}
+)
+
+// GetMetricStatisticsResponse is the response of the CloudWatch GetMetricStatistics in the query protocol.
+// API reference: https://docs.aws.amazon.com/AmazonCloudWatch/latest/APIReference/API_GetMetricStatistics.html
+type GetMetricStatisticsResponse struct {
+	XMLName                   xml.Name                   `xml:"GetMetricStatisticsResponse"`
+	Xmlns                     string                     `xml:"xmlns,attr"`
+	GetMetricStatisticsResult *GetMetricStatisticsResult `xml:"GetMetricStatisticsResult"`
+	ResponseMetadata          struct {
+		RequestID string `xml:"RequestId,omitempty"`
+	} `xml:"ResponseMetadata,omitempty"`
+}
+
+type GetMetricStatisticsResult struct {
+	Label      string             `xml:"Label"`
+	Datapoints []*MetricDatapoint `xml:"Datapoints>member"`
+}
+
+type MetricDatapoint struct {
+	Timestamp   string   `xml:"Timestamp"`
+	SampleCount *float64 `xml:"SampleCount,omitempty"`
+	Average     *float64 `xml:"Average,omitempty"`
+	Sum         *float64 `xml:"Sum,omitempty"`
+	Minimum     *float64 `xml:"Minimum,omitempty"`
+	Maximum     *float64 `xml:"Maximum,omitempty"`
+	Unit        string   `xml:"Unit"`
+}
+
+type getMetricStatisticsRequest struct {
+	key        bucketMetricsKey
+	metric     int
+	startTime  time.Time
+	endTime    time.Time
+	period     time.Duration
+	statistics []string
+}
+
+// parseGetMetricStatisticsRequest parses the request in the query protocol, only the S3 request metrics
+// with the BucketName and FilterId dimensions are supported.
+func parseGetMetricStatisticsRequest(r *http.Request) (*getMetricStatisticsRequest, *ErrorCode) {
+	req := &getMetricStatisticsRequest{}
+	namespace, metricName := r.FormValue(cloudWatchNamespaceKey), r.FormValue(cloudWatchMetricNameKey)
+	startTime, endTime, period := r.FormValue(cloudWatchStartTimeKey), r.FormValue(cloudWatchEndTimeKey), r.FormValue(cloudWatchPeriodKey)
+	if namespace == "" || metricName == "" || startTime == "" || endTime == "" || period == "" {
+		return nil, CloudWatchErrMissingParameter
+	}
+	var ok bool
+	if req.metric, ok = parseBucketMetricName(metricName); namespace != cloudWatchNamespaceS3 || !ok {
+		return nil, CloudWatchErrInvalidParameter
+	}
+
+	for i := 1; i <= cloudWatchDimensionLimit; i++ {
+		name, value := r.FormValue(fmt.Sprintf(cloudWatchDimensionNameKey, i)), r.FormValue(fmt.Sprintf(cloudWatchDimensionValueKey, i))
+		if name == "" {
+			break
+		}
+		switch name {
+		case cloudWatchDimensionBucketName:
+			req.key.bucket = value
+		case cloudWatchDimensionFilterID:
+			req.key.filter = value
+		default:
+			return nil, CloudWatchErrInvalidParameter
+		}
+	}
+	if req.key.bucket == "" || req.key.filter == "" {
+		return nil, CloudWatchErrMissingParameter
+	}
+
+	var err error
+	if req.startTime, err = time.Parse(time.RFC3339, startTime); err != nil {
+		return nil, CloudWatchErrInvalidParameter
+	}
+	if req.endTime, err = time.Parse(time.RFC3339, endTime); err != nil || !req.startTime.Before(req.endTime) {
+		return nil, CloudWatchErrInvalidParameter
+	}
+	seconds, err := strconv.ParseInt(period, 10, 64)
+	if err != nil || seconds <= 0 || seconds%int64(bucketMetricsPeriod/time.Second) != 0 {
+		return nil, CloudWatchErrInvalidParameter
+	}
+	req.period = time.Duration(seconds) * time.Second
+	if req.endTime.Sub(req.startTime)/req.period > cloudWatchDatapointLimit {
+		return nil, CloudWatchErrTooManyPoints
+	}
+
+	// the percentile statistics are not supported
+	if r.FormValue(cloudWatchExtendedStatisticKey) != "" {
+		return nil, CloudWatchErrInvalidParameter
+	}
+	for i := 1; ; i++ {
+		statistic := r.FormValue(fmt.Sprintf(cloudWatchStatisticKey, i))
+		if statistic == "" {
+			break
+		}
+		switch statistic {
+		case cloudWatchStatisticSampleCount, cloudWatchStatisticAverage, cloudWatchStatisticSum,
+			cloudWatchStatisticMinimum, cloudWatchStatisticMaximum:
+			req.statistics = append(req.statistics, statistic)
+		default:
+			return nil, CloudWatchErrInvalidParameter
+		}
+		if i > 5 {
+			return nil, CloudWatchErrInvalidParameter
+		}
+	}
+	if len(req.statistics) == 0 {
+		return nil, CloudWatchErrMissingParameter
+	}
+	return req, nil
+}
+
+func newMetricDatapoint(dp *metricDatapoint, statistics []string, unit string) *MetricDatapoint {
+	value := func(v float64) *float64 { return &v }
+	result := &MetricDatapoint{Timestamp: dp.timestamp.UTC().Format(time.RFC3339), Unit: unit}
+	for _, statistic := range statistics {
+		switch statistic {
+		case cloudWatchStatisticSampleCount:
+			result.SampleCount = value(dp.sampleCount)
+		case cloudWatchStatisticAverage:
+			result.Average = value(dp.sum / dp.sampleCount)
+		case cloudWatchStatisticSum:
+			result.Sum = value(dp.sum)
+		case cloudWatchStatisticMinimum:
+			result.Minimum = value(dp.minimum)
+		case cloudWatchStatisticMaximum:
+			result.Maximum = value(dp.maximum)
+		}
+	}
+	return result
+}
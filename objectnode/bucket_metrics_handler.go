@@ -0,0 +1,306 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketMetricsConfiguration.html
+func (o *ObjectNode) getBucketMetricsConfigurationHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		o.errorResponse(w, r, err, errorCode)
+	}()
+
+	param := ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("getBucketMetricsConfigurationHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		return
+	}
+
+	var configs *BucketMetricsConfigurations
+	if configs, err = vol.metaLoader.loadMetrics(); err != nil {
+		log.LogErrorf("getBucketMetricsConfigurationHandler: load metrics fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), vol.Name(), err)
+		return
+	}
+	conf := configs.get(param.GetVar("id"))
+	if conf == nil {
+		errorCode = NoSuchMetricsConfiguration
+		return
+	}
+	result := *conf
+	result.Xmlns = XMLNS
+	var data []byte
+	if data, err = MarshalXMLEntity(&result); err != nil {
+		log.LogErrorf("getBucketMetricsConfigurationHandler: xml marshal fail: requestID(%v) volume(%v) metrics(%+v) err(%v)",
+			GetRequestID(r), vol.Name(), result, err)
+		return
+	}
+
+	writeSuccessResponseXML(w, data)
+}
+
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketMetricsConfiguration.html
+func (o *ObjectNode) putBucketMetricsConfigurationHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		o.errorResponse(w, r, err, errorCode)
+	}()
+
+	param := ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("putBucketMetricsConfigurationHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		return
+	}
+	var body []byte
+	if body, err = io.ReadAll(io.LimitReader(r.Body, MaxBucketMetricsSize+1)); err != nil {
+		log.LogErrorf("putBucketMetricsConfigurationHandler: read request body fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), vol.Name(), err)
+		return
+	}
+	if len(body) > MaxBucketMetricsSize {
+		errorCode = EntityTooLarge
+		return
+	}
+	if requestMD5 := r.Header.Get(ContentMD5); requestMD5 != "" && requestMD5 != GetMD5(body) {
+		errorCode = InvalidDigest
+		return
+	}
+	var conf *MetricsConfiguration
+	if conf, errorCode = parseMetricsConfiguration(body, param.GetVar("id")); errorCode != nil {
+		log.LogErrorf("putBucketMetricsConfigurationHandler: parse metrics config fail: requestID(%v) volume(%v) config(%v) err(%v)",
+			GetRequestID(r), vol.Name(), string(body), errorCode)
+		return
+	}
+	conf.Xmlns = ""
+
+	var configs *BucketMetricsConfigurations
+	if configs, err = vol.metaLoader.loadMetrics(); err != nil {
+		log.LogErrorf("putBucketMetricsConfigurationHandler: load metrics fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), vol.Name(), err)
+		return
+	}
+	updated := &BucketMetricsConfigurations{}
+	if configs != nil {
+		for _, c := range configs.Configurations {
+			if c.ID != conf.ID {
+				updated.Configurations = append(updated.Configurations, c)
+			}
+		}
+	}
+	if len(updated.Configurations) >= MaxBucketMetricsConfigurations {
+		errorCode = MetricsErrTooManyConfigs
+		return
+	}
+	updated.Configurations = append(updated.Configurations, conf)
+
+	if body, err = xml.Marshal(updated); err != nil {
+		log.LogErrorf("putBucketMetricsConfigurationHandler: xml marshal fail: requestID(%v) volume(%v) metrics(%+v) err(%v)",
+			GetRequestID(r), vol.Name(), updated, err)
+		return
+	}
+	if err = storeBucketMetrics(body, vol); err != nil {
+		log.LogErrorf("putBucketMetricsConfigurationHandler: store metrics config fail: requestID(%v) volume(%v) config(%v) err(%v)",
+			GetRequestID(r), vol.Name(), string(body), err)
+		return
+	}
+	vol.metaLoader.storeMetrics(updated)
+
+	log.LogInfof("putBucketMetricsConfigurationHandler: put metrics success: requestID(%v) volume(%v) metrics(%v)",
+		GetRequestID(r), vol.Name(), conf.ID)
+}
+
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_DeleteBucketMetricsConfiguration.html
+func (o *ObjectNode) deleteBucketMetricsConfigurationHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		o.errorResponse(w, r, err, errorCode)
+	}()
+
+	param := ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("deleteBucketMetricsConfigurationHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		return
+	}
+
+	var configs *BucketMetricsConfigurations
+	if configs, err = vol.metaLoader.loadMetrics(); err != nil {
+		log.LogErrorf("deleteBucketMetricsConfigurationHandler: load metrics fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), vol.Name(), err)
+		return
+	}
+	id := param.GetVar("id")
+	if configs.get(id) == nil {
+		errorCode = NoSuchMetricsConfiguration
+		return
+	}
+	updated := &BucketMetricsConfigurations{}
+	for _, c := range configs.Configurations {
+		if c.ID != id {
+			updated.Configurations = append(updated.Configurations, c)
+		}
+	}
+
+	if len(updated.Configurations) == 0 {
+		err = deleteBucketMetrics(vol)
+		updated = nil
+	} else {
+		var body []byte
+		if body, err = xml.Marshal(updated); err == nil {
+			err = storeBucketMetrics(body, vol)
+		}
+	}
+	if err != nil {
+		log.LogErrorf("deleteBucketMetricsConfigurationHandler: delete metrics fail: requestID(%v) volume(%v) metrics(%v) err(%v)",
+			GetRequestID(r), vol.Name(), id, err)
+		return
+	}
+	vol.metaLoader.storeMetrics(updated)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_ListBucketMetricsConfigurations.html
+func (o *ObjectNode) listBucketMetricsConfigurationsHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		o.errorResponse(w, r, err, errorCode)
+	}()
+
+	param := ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("listBucketMetricsConfigurationsHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		return
+	}
+
+	var configs *BucketMetricsConfigurations
+	if configs, err = vol.metaLoader.loadMetrics(); err != nil {
+		log.LogErrorf("listBucketMetricsConfigurationsHandler: load metrics fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), vol.Name(), err)
+		return
+	}
+	var data []byte
+	if data, err = MarshalXMLEntity(listMetricsConfigurations(configs)); err != nil {
+		log.LogErrorf("listBucketMetricsConfigurationsHandler: xml marshal fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), vol.Name(), err)
+		return
+	}
+
+	writeSuccessResponseXML(w, data)
+}
+
+// The datapoints are collected by this node only.
+// https://docs.aws.amazon.com/AmazonCloudWatch/latest/APIReference/API_GetMetricStatistics.html
+func (o *ObjectNode) getMetricStatisticsHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		o.errorResponse(w, r, err, errorCode)
+	}()
+
+	var req *getMetricStatisticsRequest
+	if req, errorCode = parseGetMetricStatisticsRequest(r); errorCode != nil {
+		log.LogErrorf("getMetricStatisticsHandler: parse request fail: requestID(%v) form(%v) err(%v)",
+			GetRequestID(r), r.Form, errorCode)
+		return
+	}
+
+	// the statistics are accessible to the owner of the bucket and the users authorized to the action
+	param := ParseRequestParam(r)
+	if isAnonymous(param.AccessKey()) {
+		errorCode = AccessDenied
+		return
+	}
+	var userInfo *proto.UserInfo
+	if userInfo, err = o.getUserInfoByAccessKey(param.AccessKey()); err != nil {
+		log.LogErrorf("getMetricStatisticsHandler: get user info fail: requestID(%v) accessKey(%v) err(%v)",
+			GetRequestID(r), param.AccessKey(), err)
+		return
+	}
+	if _, err = o.getVol(req.key.bucket); err != nil {
+		log.LogErrorf("getMetricStatisticsHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), req.key.bucket, err)
+		return
+	}
+	if userInfo.UserType != proto.UserTypeRoot && userInfo.UserType != proto.UserTypeAdmin &&
+		!userInfo.Policy.IsOwn(req.key.bucket) && !userInfo.Policy.IsAuthorizedS3(req.key.bucket, param.API()) {
+		log.LogErrorf("getMetricStatisticsHandler: access denied: requestID(%v) volume(%v) userID(%v)",
+			GetRequestID(r), req.key.bucket, userInfo.UserID)
+		errorCode = AccessDenied
+		return
+	}
+
+	result := &GetMetricStatisticsResult{
+		Label:      bucketMetricNames[req.metric],
+		Datapoints: make([]*MetricDatapoint, 0),
+	}
+	for _, dp := range o.bucketMetrics.statistics(req.key, req.metric, req.startTime, req.endTime, req.period) {
+		result.Datapoints = append(result.Datapoints, newMetricDatapoint(dp, req.statistics, bucketMetricUnits[req.metric]))
+	}
+	response := &GetMetricStatisticsResponse{Xmlns: cloudWatchXMLNS, GetMetricStatisticsResult: result}
+	response.ResponseMetadata.RequestID = GetRequestID(r)
+	var data []byte
+	if data, err = MarshalXMLEntity(response); err != nil {
+		log.LogErrorf("getMetricStatisticsHandler: xml marshal fail: requestID(%v) err(%v)", GetRequestID(r), err)
+		return
+	}
+
+	writeSuccessResponseXML(w, data)
+}
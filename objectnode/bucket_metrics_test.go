@@ -0,0 +1,202 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMetricsConfiguration(t *testing.T) {
+	conf, errorCode := parseMetricsConfiguration([]byte(`<MetricsConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/">`+
+		`<Id>docs</Id><Filter><Prefix>docs/</Prefix></Filter></MetricsConfiguration>`), "docs")
+	require.Nil(t, errorCode)
+	prefix, tags := conf.filterRule()
+	require.Equal(t, "docs/", prefix)
+	require.Empty(t, tags)
+
+	conf, errorCode = parseMetricsConfiguration([]byte(`<MetricsConfiguration><Id>all</Id></MetricsConfiguration>`), "all")
+	require.Nil(t, errorCode)
+	require.Nil(t, conf.Filter)
+
+	conf, errorCode = parseMetricsConfiguration([]byte(`<MetricsConfiguration><Id>and</Id><Filter><And><Prefix>a/</Prefix>`+
+		`<Tag><Key>k1</Key><Value>v1</Value></Tag><Tag><Key>k2</Key><Value>v2</Value></Tag></And></Filter></MetricsConfiguration>`), "and")
+	require.Nil(t, errorCode)
+	prefix, tags = conf.filterRule()
+	require.Equal(t, "a/", prefix)
+	require.Len(t, tags, 2)
+
+	_, errorCode = parseMetricsConfiguration([]byte(`<MetricsConfiguration><Id>a</Id></MetricsConfiguration>`), "b")
+	require.Equal(t, MetricsErrIDMismatch, errorCode)
+	_, errorCode = parseMetricsConfiguration([]byte(`<MetricsConfiguration><Id>a</Id><Filter><Prefix>p</Prefix>`+
+		`<Tag><Key>k</Key><Value>v</Value></Tag></Filter></MetricsConfiguration>`), "a")
+	require.Equal(t, MetricsErrInvalidFilter, errorCode)
+	_, errorCode = parseMetricsConfiguration([]byte(`<MetricsConfiguration><Id>a</Id><Filter><AccessPointArn>arn</AccessPointArn>`+
+		`</Filter></MetricsConfiguration>`), "a")
+	require.Equal(t, MetricsErrInvalidFilter, errorCode)
+	_, errorCode = parseMetricsConfiguration([]byte(`<MetricsConfiguration><Id>a</Id><Filter><And>`+
+		`<Tag><Key>k</Key><Value>v1</Value></Tag><Tag><Key>k</Key><Value>v2</Value></Tag></And></Filter></MetricsConfiguration>`), "a")
+	require.Equal(t, DuplicateTagKey, errorCode)
+	_, errorCode = parseMetricsConfiguration([]byte(`<MetricsConfiguration><Id>a</Id>`), "a")
+	require.Equal(t, MalformedXML, errorCode)
+}
+
+func TestMetricsConfigurationMatch(t *testing.T) {
+	loaded := 0
+	loadTags := func() map[string]string {
+		loaded++
+		return map[string]string{"team": "a", "env": "prod"}
+	}
+
+	conf := &MetricsConfiguration{ID: "all"}
+	require.True(t, conf.match("", loadTags))
+	require.True(t, conf.match("docs/a", loadTags))
+
+	conf = &MetricsConfiguration{ID: "prefix", Filter: &MetricsFilter{Prefix: "docs/"}}
+	require.False(t, conf.match("", loadTags))
+	require.True(t, conf.match("docs/a", loadTags))
+	require.False(t, conf.match("logs/a", loadTags))
+	require.Equal(t, 0, loaded)
+
+	conf = &MetricsConfiguration{ID: "tag", Filter: &MetricsFilter{Tag: &Tag{Key: "team", Value: "a"}}}
+	require.True(t, conf.match("logs/a", loadTags))
+	require.Equal(t, 1, loaded)
+
+	conf = &MetricsConfiguration{ID: "and", Filter: &MetricsFilter{And: &MetricsAndOperator{
+		Prefix: "docs/", Tags: []Tag{{Key: "team", Value: "a"}, {Key: "env", Value: "test"}},
+	}}}
+	require.False(t, conf.match("docs/a", loadTags))
+	require.False(t, conf.match("logs/a", loadTags))
+
+	var configs *BucketMetricsConfigurations
+	require.Nil(t, configs.get("all"))
+	configs = &BucketMetricsConfigurations{Configurations: []*MetricsConfiguration{{ID: "b"}, {ID: "a"}}}
+	require.Equal(t, "a", configs.get("a").ID)
+	result := listMetricsConfigurations(configs)
+	require.Equal(t, "a", result.MetricsConfigurations[0].ID)
+	require.Equal(t, "b", result.MetricsConfigurations[1].ID)
+}
+
+func TestBucketMetricsSample(t *testing.T) {
+	sample := &bucketMetricsSample{requestType: metricGetRequests, statusCode: http.StatusNotFound, downloaded: 128, latency: 3 * time.Millisecond}
+	p := sample.point()
+	require.Equal(t, float64(1), p[metricAllRequests].sum)
+	require.Equal(t, float64(1), p[metricGetRequests].sum)
+	require.Equal(t, float64(0), p[metricPutRequests].sampleCount)
+	require.Equal(t, float64(128), p[metricBytesDownloaded].sum)
+	require.Equal(t, float64(0), p[metricBytesUploaded].sampleCount)
+	require.Equal(t, float64(1), p[metric4xxErrors].sum)
+	require.Equal(t, float64(0), p[metric5xxErrors].sum)
+	require.Equal(t, float64(1), p[metric5xxErrors].sampleCount)
+	require.Equal(t, float64(3), p[metricTotalRequestLatency].sum)
+
+	metric, ok := parseBucketMetricName("BytesDownloaded")
+	require.True(t, ok)
+	require.Equal(t, metricBytesDownloaded, metric)
+	_, ok = parseBucketMetricName("FirstByteLatency")
+	require.False(t, ok)
+}
+
+func TestBucketMetricsStatistics(t *testing.T) {
+	m := NewBucketMetrics(nil)
+	key := bucketMetricsKey{bucket: "bucket", filter: "all"}
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, latency := range []time.Duration{10, 30, 20, 40} {
+		sample := &bucketMetricsSample{requestType: -1, statusCode: http.StatusOK, latency: latency * time.Millisecond}
+		m.add(key, start.Add(time.Duration(i)*time.Minute+time.Second), sample.point())
+	}
+	m.add(bucketMetricsKey{bucket: "bucket", filter: "other"}, start, (&bucketMetricsSample{requestType: -1}).point())
+
+	datapoints := m.statistics(key, metricTotalRequestLatency, start, start.Add(4*time.Minute), 2*time.Minute)
+	require.Len(t, datapoints, 2)
+	require.Equal(t, start, datapoints[0].timestamp)
+	require.Equal(t, float64(2), datapoints[0].sampleCount)
+	require.Equal(t, float64(40), datapoints[0].sum)
+	require.Equal(t, float64(10), datapoints[0].minimum)
+	require.Equal(t, float64(30), datapoints[0].maximum)
+	require.Equal(t, start.Add(2*time.Minute), datapoints[1].timestamp)
+	require.Equal(t, float64(60), datapoints[1].sum)
+
+	result := newMetricDatapoint(datapoints[0], []string{cloudWatchStatisticAverage, cloudWatchStatisticSum}, "Milliseconds")
+	require.Equal(t, "2023-01-01T00:00:00Z", result.Timestamp)
+	require.Equal(t, float64(20), *result.Average)
+	require.Equal(t, float64(40), *result.Sum)
+	require.Nil(t, result.SampleCount)
+
+	require.Len(t, m.statistics(key, metricAllRequests, start.Add(3*time.Minute), start.Add(time.Hour), time.Minute), 1)
+	m.expire(start.Add(bucketMetricsRetention + 2*time.Minute))
+	require.Len(t, m.statistics(key, metricAllRequests, start, start.Add(time.Hour), time.Minute), 2)
+	m.expire(start.Add(2 * bucketMetricsRetention))
+	require.Empty(t, m.series)
+}
+
+func TestParseGetMetricStatisticsRequest(t *testing.T) {
+	newRequest := func(form url.Values) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+		r.Header.Set(ContentType, ValueFormURLEncoded)
+		return r
+	}
+	form := url.Values{
+		"Action":                    {"GetMetricStatistics"},
+		"Namespace":                 {"AWS/S3"},
+		"MetricName":                {"AllRequests"},
+		"StartTime":                 {"2023-01-01T00:00:00Z"},
+		"EndTime":                   {"2023-01-01T01:00:00Z"},
+		"Period":                    {"300"},
+		"Dimensions.member.1.Name":  {"BucketName"},
+		"Dimensions.member.1.Value": {"bucket"},
+		"Dimensions.member.2.Name":  {"FilterId"},
+		"Dimensions.member.2.Value": {"all"},
+		"Statistics.member.1":       {"Sum"},
+		"Statistics.member.2":       {"SampleCount"},
+	}
+	req, errorCode := parseGetMetricStatisticsRequest(newRequest(form))
+	require.Nil(t, errorCode)
+	require.Equal(t, bucketMetricsKey{bucket: "bucket", filter: "all"}, req.key)
+	require.Equal(t, metricAllRequests, req.metric)
+	require.Equal(t, 5*time.Minute, req.period)
+	require.Equal(t, []string{"Sum", "SampleCount"}, req.statistics)
+
+	cases := []struct {
+		key, value string
+		errorCode  *ErrorCode
+	}{
+		{"Namespace", "AWS/EC2", CloudWatchErrInvalidParameter},
+		{"MetricName", "", CloudWatchErrMissingParameter},
+		{"Period", "90", CloudWatchErrInvalidParameter},
+		{"Period", "1", CloudWatchErrInvalidParameter},
+		{"EndTime", "2023-01-01T00:00:00Z", CloudWatchErrInvalidParameter},
+		{"EndTime", "2023-01-10T00:00:00Z", CloudWatchErrTooManyPoints},
+		{"Dimensions.member.2.Value", "", CloudWatchErrMissingParameter},
+		{"Dimensions.member.2.Name", "StorageType", CloudWatchErrInvalidParameter},
+		{"Statistics.member.1", "p99", CloudWatchErrInvalidParameter},
+		{"ExtendedStatistics.member.1", "p99", CloudWatchErrInvalidParameter},
+	}
+	for _, c := range cases {
+		invalid := url.Values{}
+		for k, v := range form {
+			invalid[k] = v
+		}
+		invalid.Set(c.key, c.value)
+		_, errorCode = parseGetMetricStatisticsRequest(newRequest(invalid))
+		require.Equal(t, c.errorCode, errorCode, "%v=%v", c.key, c.value)
+	}
+}
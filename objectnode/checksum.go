@@ -0,0 +1,249 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"hash"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Additional checksum algorithms of the object.
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/userguide/checking-object-integrity.html
+const (
+	ChecksumCRC32  = "CRC32"
+	ChecksumCRC32C = "CRC32C"
+	ChecksumSHA1   = "SHA1"
+	ChecksumSHA256 = "SHA256"
+
+	ChecksumModeEnabled = "ENABLED"
+)
+
+var checksumHashes = map[string]func() hash.Hash{
+	ChecksumCRC32:  func() hash.Hash { return crc32.NewIEEE() },
+	ChecksumCRC32C: func() hash.Hash { return crc32.New(crc32.MakeTable(crc32.Castagnoli)) },
+	ChecksumSHA1:   sha1.New,
+	ChecksumSHA256: sha256.New,
+}
+
+// ChecksumHeader returns the request and response header name of the checksum algorithm,
+// such as "x-amz-checksum-crc32c".
+func ChecksumHeader(algorithm string) string {
+	return XAmzChecksumPrefix + strings.ToLower(algorithm)
+}
+
+func IsValidChecksumAlgorithm(algorithm string) bool {
+	_, ok := checksumHashes[algorithm]
+	return ok
+}
+
+// Checksum computes the additional checksum of the payload while it is written,
+// and verifies it with the value specified in the header or the trailer of the request.
+type Checksum struct {
+	Algorithm string
+	Value     string // base64 encoded checksum computed from the payload
+
+	hash     hash.Hash
+	expected string
+	trailer  TrailerReader
+}
+
+// NewChecksum returns a checksum which only computes the payload without verification.
+func NewChecksum(algorithm string) (*Checksum, *ErrorCode) {
+	newHash, ok := checksumHashes[algorithm]
+	if !ok {
+		return nil, InvalidChecksumAlgorithm
+	}
+	return &Checksum{Algorithm: algorithm, hash: newHash()}, nil
+}
+
+// ParseChecksum parses the additional checksum of the payload from the request headers,
+// or from the "x-amz-trailer" whose value is verified after the payload is read completely.
+// It returns nil if no additional checksum is specified.
+func ParseChecksum(r *http.Request) (*Checksum, *ErrorCode) {
+	algorithm, expected, errorCode := findChecksumValue(r.Header.Get)
+	if errorCode != nil {
+		return nil, errorCode
+	}
+
+	var trailer TrailerReader
+	if name := strings.ToLower(r.Header.Get(XAmzTrailer)); strings.HasPrefix(name, XAmzChecksumPrefix) {
+		if algorithm != "" {
+			return nil, MultipleChecksumTypes
+		}
+		algorithm = strings.ToUpper(strings.TrimPrefix(name, XAmzChecksumPrefix))
+		var ok bool
+		if trailer, ok = r.Body.(TrailerReader); !ok || trailer.Trailer() == nil {
+			return nil, InvalidChecksum
+		}
+	}
+
+	if algorithm == "" {
+		if r.Header.Get(XAmzSdkChecksumAlgorithm) != "" {
+			return nil, MissingChecksum
+		}
+		return nil, nil
+	}
+
+	checksum, errorCode := NewChecksum(algorithm)
+	if errorCode != nil {
+		return nil, errorCode
+	}
+	if sdkAlgorithm := r.Header.Get(XAmzSdkChecksumAlgorithm); sdkAlgorithm != "" &&
+		strings.ToUpper(sdkAlgorithm) != algorithm {
+		return nil, MultipleChecksumTypes
+	}
+	if trailer == nil && !checksum.validValue(expected) {
+		return nil, InvalidChecksum
+	}
+	checksum.expected = expected
+	checksum.trailer = trailer
+	return checksum, nil
+}
+
+// ParseFormChecksum parses the additional checksum of the file from the form fields of
+// the POST request, it returns nil if no additional checksum is specified.
+func ParseFormChecksum(formReq *FormRequest) (*Checksum, *ErrorCode) {
+	algorithm, expected, errorCode := findChecksumValue(formReq.MultipartFormValue)
+	if errorCode != nil || algorithm == "" {
+		return nil, errorCode
+	}
+	checksum, errorCode := NewChecksum(algorithm)
+	if errorCode != nil {
+		return nil, errorCode
+	}
+	if !checksum.validValue(expected) {
+		return nil, InvalidChecksum
+	}
+	checksum.expected = expected
+	return checksum, nil
+}
+
+func findChecksumValue(get func(key string) string) (algorithm, value string, errorCode *ErrorCode) {
+	for alg := range checksumHashes {
+		if v := get(ChecksumHeader(alg)); v != "" {
+			if algorithm != "" {
+				return "", "", MultipleChecksumTypes
+			}
+			algorithm, value = alg, v
+		}
+	}
+	return
+}
+
+func (c *Checksum) validValue(value string) bool {
+	raw, err := base64.StdEncoding.DecodeString(value)
+	return err == nil && len(raw) == c.hash.Size()
+}
+
+// Reader returns a reader that computes the checksum of the data read from r.
+func (c *Checksum) Reader(r io.Reader) io.Reader {
+	return io.TeeReader(r, c.hash)
+}
+
+// Verify must be called after the payload is read completely, it computes the checksum
+// and compares it with the expected one if specified.
+func (c *Checksum) Verify() error {
+	c.Value = base64.StdEncoding.EncodeToString(c.hash.Sum(nil))
+	expected := c.expected
+	if c.trailer != nil {
+		if expected = c.trailer.Trailer().Get(ChecksumHeader(c.Algorithm)); !c.validValue(expected) {
+			return InvalidChecksum
+		}
+	}
+	if expected != "" && expected != c.Value {
+		return ChecksumMismatch
+	}
+	return nil
+}
+
+// Encode returns the value of checksum stored in the extend attributes.
+func (c *Checksum) Encode() string {
+	return c.Algorithm + ":" + c.Value
+}
+
+// ParseChecksumXAttr parses the algorithm and base64 encoded value of the checksum
+// stored in the extend attributes.
+func ParseChecksumXAttr(raw string) (algorithm, value string) {
+	items := strings.SplitN(raw, ":", 2)
+	if len(items) != 2 || !IsValidChecksumAlgorithm(items[0]) {
+		return "", ""
+	}
+	return items[0], items[1]
+}
+
+// ComposeChecksum computes the checksum of the multipart object, which is the checksum of
+// the concatenated raw checksums of all the parts suffixed with the number of parts.
+func ComposeChecksum(algorithm string, partValues []string) (string, error) {
+	newHash, ok := checksumHashes[algorithm]
+	if !ok {
+		return "", InvalidChecksumAlgorithm
+	}
+	h := newHash()
+	for _, value := range partValues {
+		raw, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return "", err
+		}
+		h.Write(raw)
+	}
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)) + "-" + strconv.Itoa(len(partValues)), nil
+}
+
+// setChecksumHeader returns the checksum of the whole object in the response header of
+// GetObject and HeadObject if "x-amz-checksum-mode" is enabled.
+func setChecksumHeader(w http.ResponseWriter, r *http.Request, fileInfo *FSFileInfo) {
+	if !strings.EqualFold(r.Header.Get(XAmzChecksumMode), ChecksumModeEnabled) || fileInfo.Checksum == "" {
+		return
+	}
+	// the checksum is not available for the range or part of object
+	if r.Header.Get(Range) != "" || r.URL.Query().Get(ParamPartNumber) != "" {
+		return
+	}
+	w.Header().Set(ChecksumHeader(fileInfo.ChecksumAlgorithm), fileInfo.Checksum)
+}
+
+// ChecksumResult is the checksum elements of the XML results.
+type ChecksumResult struct {
+	ChecksumCRC32  string `xml:"ChecksumCRC32,omitempty"`
+	ChecksumCRC32C string `xml:"ChecksumCRC32C,omitempty"`
+	ChecksumSHA1   string `xml:"ChecksumSHA1,omitempty"`
+	ChecksumSHA256 string `xml:"ChecksumSHA256,omitempty"`
+}
+
+func NewChecksumResult(algorithm, value string) ChecksumResult {
+	var result ChecksumResult
+	switch algorithm {
+	case ChecksumCRC32:
+		result.ChecksumCRC32 = value
+	case ChecksumCRC32C:
+		result.ChecksumCRC32C = value
+	case ChecksumSHA1:
+		result.ChecksumSHA1 = value
+	case ChecksumSHA256:
+		result.ChecksumSHA256 = value
+	}
+	return result
+}
+
+func (r ChecksumResult) IsEmpty() bool {
+	return r == ChecksumResult{}
+}
@@ -0,0 +1,198 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseChecksum(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPut, "/bucket/key", nil)
+	checksum, errorCode := ParseChecksum(req)
+	require.Nil(t, errorCode)
+	require.Nil(t, checksum)
+
+	req.Header.Set(XAmzSdkChecksumAlgorithm, "CRC32C")
+	_, errorCode = ParseChecksum(req)
+	require.Equal(t, MissingChecksum, errorCode)
+
+	req.Header.Set(ChecksumHeader(ChecksumCRC32C), "yZRlqg==")
+	checksum, errorCode = ParseChecksum(req)
+	require.Nil(t, errorCode)
+	require.Equal(t, ChecksumCRC32C, checksum.Algorithm)
+	require.Equal(t, "x-amz-checksum-crc32c", ChecksumHeader(checksum.Algorithm))
+
+	req.Header.Set(ChecksumHeader(ChecksumCRC32), "DUoRhQ==")
+	_, errorCode = ParseChecksum(req)
+	require.Equal(t, MultipleChecksumTypes, errorCode)
+
+	req = httptest.NewRequest(http.MethodPut, "/bucket/key", nil)
+	req.Header.Set(ChecksumHeader(ChecksumSHA256), "yZRlqg==")
+	_, errorCode = ParseChecksum(req)
+	require.Equal(t, InvalidChecksum, errorCode)
+
+	// the trailer requires the body to be parsed by trailer chunked reader
+	req = httptest.NewRequest(http.MethodPut, "/bucket/key", nil)
+	req.Header.Set(XAmzTrailer, "x-amz-checksum-crc32")
+	_, errorCode = ParseChecksum(req)
+	require.Equal(t, InvalidChecksum, errorCode)
+	req.Body = NewTrailerChunkedReader(ioutil.NopCloser(bytes.NewReader(nil)))
+	checksum, errorCode = ParseChecksum(req)
+	require.Nil(t, errorCode)
+	require.Equal(t, ChecksumCRC32, checksum.Algorithm)
+
+	req.Header.Set(XAmzTrailer, "x-amz-checksum-md5")
+	_, errorCode = ParseChecksum(req)
+	require.Equal(t, InvalidChecksumAlgorithm, errorCode)
+}
+
+func TestChecksumVerify(t *testing.T) {
+	data := "hello world"
+	sha256Sum := sha256.Sum256([]byte(data))
+	cases := []struct {
+		algorithm string
+		value     string
+	}{
+		{ChecksumCRC32, "DUoRhQ=="},
+		{ChecksumCRC32C, "yZRlqg=="},
+		{ChecksumSHA1, "Kq5sNclPz7QV2+lfQIuc6R7oRu0="},
+		{ChecksumSHA256, base64.StdEncoding.EncodeToString(sha256Sum[:])},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodPut, "/bucket/key", nil)
+		req.Header.Set(ChecksumHeader(c.algorithm), c.value)
+		checksum, errorCode := ParseChecksum(req)
+		require.Nil(t, errorCode)
+		_, err := io.Copy(ioutil.Discard, checksum.Reader(strings.NewReader(data)))
+		require.NoError(t, err)
+		require.NoError(t, checksum.Verify())
+		require.Equal(t, c.value, checksum.Value)
+
+		algorithm, value := ParseChecksumXAttr(checksum.Encode())
+		require.Equal(t, c.algorithm, algorithm)
+		require.Equal(t, c.value, value)
+
+		checksum, errorCode = ParseChecksum(req)
+		require.Nil(t, errorCode)
+		_, err = io.Copy(ioutil.Discard, checksum.Reader(strings.NewReader("hello")))
+		require.NoError(t, err)
+		require.Equal(t, ChecksumMismatch, checksum.Verify())
+	}
+
+	algorithm, value := ParseChecksumXAttr("MD5:yZRlqg==")
+	require.Empty(t, algorithm)
+	require.Empty(t, value)
+}
+
+func TestComposeChecksum(t *testing.T) {
+	parts := []string{"DUoRhQ==", "DUoRhQ=="}
+	raw, _ := base64.StdEncoding.DecodeString(parts[0])
+	h := checksumHashes[ChecksumCRC32]()
+	h.Write(raw)
+	h.Write(raw)
+	expected := base64.StdEncoding.EncodeToString(h.Sum(nil)) + "-2"
+
+	value, err := ComposeChecksum(ChecksumCRC32, parts)
+	require.NoError(t, err)
+	require.Equal(t, expected, value)
+
+	_, err = ComposeChecksum("MD5", parts)
+	require.Error(t, err)
+
+	result := NewChecksumResult(ChecksumCRC32, value)
+	require.False(t, result.IsEmpty())
+	b, err := xml.Marshal(result)
+	require.NoError(t, err)
+	require.Equal(t, "<ChecksumResult><ChecksumCRC32>"+value+"</ChecksumCRC32></ChecksumResult>", string(b))
+	require.True(t, NewChecksumResult("", "").IsEmpty())
+}
+
+func TestTrailerChunkedReader(t *testing.T) {
+	body := "5\r\nhello\r\n6\r\n world\r\n0\r\nx-amz-checksum-crc32c:yZRlqg==\r\n\r\n"
+	req := httptest.NewRequest(http.MethodPut, "/bucket/key", nil)
+	req.Header.Set(XAmzTrailer, "x-amz-checksum-crc32c")
+	req.Body = NewTrailerChunkedReader(ioutil.NopCloser(strings.NewReader(body)))
+	checksum, errorCode := ParseChecksum(req)
+	require.Nil(t, errorCode)
+
+	data, err := ioutil.ReadAll(checksum.Reader(req.Body))
+	require.NoError(t, err)
+	require.Equal(t, "hello world", string(data))
+	require.Equal(t, "yZRlqg==", req.Body.(TrailerReader).Trailer().Get("x-amz-checksum-crc32c"))
+	require.NoError(t, checksum.Verify())
+
+	// malformed chunk
+	reader := NewTrailerChunkedReader(ioutil.NopCloser(strings.NewReader("5\r\nhelloXX0\r\n\r\n")))
+	_, err = ioutil.ReadAll(reader)
+	require.Error(t, err)
+
+	// missing trailer
+	req.Body = NewTrailerChunkedReader(ioutil.NopCloser(strings.NewReader("5\r\nhello\r\n0\r\n\r\n")))
+	checksum, errorCode = ParseChecksum(req)
+	require.Nil(t, errorCode)
+	_, err = ioutil.ReadAll(checksum.Reader(req.Body))
+	require.NoError(t, err)
+	require.Equal(t, InvalidChecksum, checksum.Verify())
+}
+
+func TestSignChunkedReaderWithTrailer(t *testing.T) {
+	sk := "wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY"
+	key := buildSigningKey("AWS4", sk, "20130524", "us-east-1", "s3", "aws4_request")
+	scope := "20130524/us-east-1/s3/aws4_request"
+	datetime := "20130524T000000Z"
+	seed := "4f232c4386841ef735655705268965c44a0e4690baa4adea153f7db9fa80a0a9"
+
+	signer := &SignChunkedReader{key: key, scope: scope, datetime: datetime, prevSig: seed}
+	sig1 := signer.getSignature([]byte("hello world"))
+	signer.prevSig = sig1
+	sig2 := signer.getSignature(nil)
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256-TRAILER",
+		datetime,
+		scope,
+		sig2,
+		hex.EncodeToString(MakeSha256([]byte("x-amz-checksum-crc32c:yZRlqg==\n"))),
+	}, "\n")
+	trailerSig := hex.EncodeToString(MakeHmacSha256(key, []byte(stringToSign)))
+
+	body := "b;chunk-signature=" + sig1 + "\r\nhello world\r\n" +
+		"0;chunk-signature=" + sig2 + "\r\n" +
+		"x-amz-checksum-crc32c:yZRlqg==\r\n" +
+		"x-amz-trailer-signature:" + trailerSig + "\r\n\r\n"
+	reader := NewSignChunkedReader(strings.NewReader(body), key, scope, datetime, seed)
+	reader.trailer = make(http.Header)
+	data, err := ioutil.ReadAll(reader)
+	require.NoError(t, err)
+	require.Equal(t, "hello world", string(data))
+	require.Equal(t, "yZRlqg==", reader.Trailer().Get("x-amz-checksum-crc32c"))
+
+	body = strings.Replace(body, "yZRlqg==", "DUoRhQ==", 1)
+	reader = NewSignChunkedReader(strings.NewReader(body), key, scope, datetime, seed)
+	reader.trailer = make(http.Header)
+	_, err = ioutil.ReadAll(reader)
+	require.Error(t, err)
+}
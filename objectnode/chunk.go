@@ -15,8 +15,12 @@
 package objectnode
 
 import (
+	"bufio"
+	"errors"
 	"io"
+	"net/http"
 	"net/http/httputil"
+	"strings"
 )
 
 // ClosableChunkReader wraps the chunked reader from the "httputil" package provided by Go
@@ -42,3 +46,142 @@ func NewClosableChunkedReader(source io.ReadCloser) io.ReadCloser {
 		Reader: httputil.NewChunkedReader(source),
 	}
 }
+
+// TrailerReader is implemented by the request body readers which parse the trailing
+// headers of the "aws-chunked" payload, the trailer is only available after EOF is read.
+type TrailerReader interface {
+	Trailer() http.Header
+}
+
+// trailerChunkedReader parses the "aws-chunked" payload of STREAMING-UNSIGNED-PAYLOAD-TRAILER,
+// the chunks are not signed and the trailing headers follow the last zero-length chunk.
+//
+//	<hex-size>\r\n<data>\r\n
+//	0\r\n
+//	x-amz-checksum-crc32c:sOO8/Q==\r\n
+//	\r\n
+type trailerChunkedReader struct {
+	reader  *bufio.Reader
+	src     io.ReadCloser
+	remain  int64
+	trailer http.Header
+	err     error
+}
+
+// NewTrailerChunkedReader returns an instance of the io.ReadCloser interface
+// used to parse the unsigned chunk data with trailing headers.
+func NewTrailerChunkedReader(source io.ReadCloser) io.ReadCloser {
+	return &trailerChunkedReader{
+		reader:  bufio.NewReader(source),
+		src:     source,
+		trailer: make(http.Header),
+	}
+}
+
+func (cr *trailerChunkedReader) Read(p []byte) (n int, err error) {
+	if cr.err != nil {
+		return 0, cr.err
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if cr.remain == 0 {
+		if cr.err = cr.nextChunk(); cr.err != nil {
+			return 0, cr.err
+		}
+	}
+	if int64(len(p)) > cr.remain {
+		p = p[:cr.remain]
+	}
+	n, err = cr.reader.Read(p)
+	cr.remain -= int64(n)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err == nil && cr.remain == 0 {
+		err = readChunkCRLF(cr.reader)
+	}
+	cr.err = err
+	return
+}
+
+func (cr *trailerChunkedReader) nextChunk() error {
+	line, err := readChunkLine(cr.reader)
+	if err != nil {
+		return err
+	}
+	// ignore the chunk extensions
+	if i := strings.IndexByte(line, ';'); i >= 0 {
+		line = line[:i]
+	}
+	size, err := parseHexUint([]byte(strings.TrimSpace(line)))
+	if err != nil {
+		return err
+	}
+	if size == 0 {
+		if err = readChunkTrailer(cr.reader, cr.trailer); err != nil {
+			return err
+		}
+		return io.EOF
+	}
+	cr.remain = int64(size)
+	return nil
+}
+
+func (cr *trailerChunkedReader) Trailer() http.Header {
+	return cr.trailer
+}
+
+func (cr *trailerChunkedReader) Close() error {
+	return cr.src.Close()
+}
+
+// readChunkTrailer reads the trailing headers until the blank line.
+func readChunkTrailer(reader *bufio.Reader, trailer http.Header) error {
+	for {
+		line, err := readChunkLine(reader)
+		if err == io.EOF && len(line) == 0 {
+			// some clients close the body without the final blank line
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if line == "" {
+			return nil
+		}
+		items := strings.SplitN(line, ":", 2)
+		if len(items) != 2 {
+			return errors.New("malformed chunk trailer")
+		}
+		trailer.Add(strings.TrimSpace(items[0]), strings.TrimSpace(items[1]))
+	}
+}
+
+func readChunkLine(reader *bufio.Reader) (string, error) {
+	line, truncated, err := reader.ReadLine()
+	if truncated {
+		return "", errors.New("header line of chunk is too long")
+	}
+	if err != nil {
+		if err == io.EOF && len(line) > 0 {
+			err = io.ErrUnexpectedEOF
+		}
+		return "", err
+	}
+	return string(line), nil
+}
+
+func readChunkCRLF(reader *bufio.Reader) error {
+	crlf := make([]byte, 2)
+	if _, err := io.ReadFull(reader, crlf); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	if string(crlf) != "\r\n" {
+		return errors.New("malformed chunked encoding")
+	}
+	return nil
+}
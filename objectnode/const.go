@@ -85,6 +85,15 @@ const (
 	XAmzSecurityToken               = "X-Amz-Security-Token" // #nosec G101
 	XAmzObjectLockMode              = "X-Amz-Object-Lock-Mode"
 	XAmzObjectLockRetainUntilDate   = "X-Amz-Object-Lock-Retain-Until-Date"
+	XAmzObjectLockLegalHold         = "X-Amz-Object-Lock-Legal-Hold"
+	XAmzBypassGovernanceRetention   = "X-Amz-Bypass-Governance-Retention"
+	XAmzTrailer                     = "X-Amz-Trailer"
+	XAmzTrailerSignature            = "X-Amz-Trailer-Signature"
+	XAmzChecksumPrefix              = "x-amz-checksum-"
+	XAmzChecksumAlgorithm           = "X-Amz-Checksum-Algorithm"
+	XAmzSdkChecksumAlgorithm        = "X-Amz-Sdk-Checksum-Algorithm"
+	XAmzChecksumMode                = "X-Amz-Checksum-Mode"
+	XAmzObjectAttributes            = "X-Amz-Object-Attributes"
 
 	HeaderNameXAmzDecodedContentLength = "x-amz-decoded-content-length"
 )
@@ -96,7 +105,10 @@ const (
 	ValueContentTypeXML       = "application/xml"
 	ValueContentTypeJSON      = "application/json"
 	ValueContentTypeDirectory = "application/directory"
+	ValueContentTypeHTML      = "text/html; charset=utf-8"
+	ValueContentTypeText      = "text/plain"
 	ValueMultipartFormData    = "multipart/form-data"
+	ValueFormURLEncoded       = "application/x-www-form-urlencoded"
 )
 
 const (
@@ -141,6 +153,15 @@ const (
 	StorageClassStandard = "STANDARD"
 )
 
+// Object attributes of GetObjectAttributes
+const (
+	ObjectAttributeETag         = "ETag"
+	ObjectAttributeChecksum     = "Checksum"
+	ObjectAttributeObjectParts  = "ObjectParts"
+	ObjectAttributeStorageClass = "StorageClass"
+	ObjectAttributeObjectSize   = "ObjectSize"
+)
+
 // XAttr keys for ObjectNode compatible feature
 const (
 	XAttrKeyOSSPrefix       = "oss:"
@@ -152,8 +173,17 @@ const (
 	XAttrKeyOSSDISPOSITION  = "oss:disposition"
 	XAttrKeyOSSCORS         = "oss:cors"
 	XAttrKeyOSSLock         = "oss:lock"
+	XAttrKeyOSSLockMode     = "oss:lock-mode"
+	XAttrKeyOSSLegalHold    = "oss:legal-hold"
+	XAttrKeyOSSWebsite      = "oss:website"
+	XAttrKeyOSSLogging      = "oss:logging"
 	XAttrKeyOSSCacheControl = "oss:cache"
 	XAttrKeyOSSExpires      = "oss:expires"
+	XAttrKeyOSSChecksum     = "oss:checksum"
+
+	XAttrKeyOSSChecksumAlgorithm = "oss:checksum-algorithm"
+	XAttrKeyOSSPublicAccessBlock = "oss:public-access-block"
+	XAttrKeyOSSMetrics           = "oss:metrics"
 
 	// Deprecated
 	XAttrKeyOSSETagDeprecated = "oss:tag"
@@ -34,6 +34,11 @@ type FSFileInfo struct {
 	Expires         string
 	Metadata        map[string]string `graphql:"-"` // User-defined metadata
 	RetainUntilDate string
+	RetentionMode   string
+	LegalHold       string
+	// Additional checksum of the object, the value of multipart object is suffixed with "-<parts>".
+	ChecksumAlgorithm string
+	Checksum          string
 }
 
 type Prefixes []string
@@ -92,6 +92,8 @@ type PutFileOption struct {
 	CacheControl string
 	Expires      string
 	ObjectLock   *ObjectLockConfig
+	Checksum     *Checksum
+	Condition    *WriteCondition
 }
 
 type ListFilesV1Option struct {
@@ -144,6 +146,11 @@ type Volume struct {
 	cacheAction    int
 	cacheThreshold int
 
+	// the permission mode and the uid and gid of the S3 users, synced from master
+	permLock       sync.RWMutex
+	permissionMode uint8
+	uidUsers       map[string]*proto.UidUserInfo
+
 	closeOnce sync.Once
 	closeCh   chan struct{}
 
@@ -198,6 +205,30 @@ func (v *Volume) loadOSSMeta() {
 		return
 	}
 	v.metaLoader.storeObjectLock(objectlock)
+
+	var website *WebsiteConfiguration
+	if website, err = v.loadBucketWebsite(); err != nil {
+		return
+	}
+	v.metaLoader.storeWebsite(website)
+
+	var logging *BucketLoggingStatus
+	if logging, err = v.loadBucketLogging(); err != nil {
+		return
+	}
+	v.metaLoader.storeLogging(logging)
+
+	var publicAccessBlock *PublicAccessBlockConfiguration
+	if publicAccessBlock, err = v.loadBucketPublicAccessBlock(); err != nil {
+		return
+	}
+	v.metaLoader.storePublicAccessBlock(publicAccessBlock)
+
+	var metrics *BucketMetricsConfigurations
+	if metrics, err = v.loadBucketMetrics(); err != nil {
+		return
+	}
+	v.metaLoader.storeMetrics(metrics)
 	v.metaLoader.setSynced()
 }
 
@@ -277,6 +308,66 @@ func (v *Volume) loadObjectLock() (configuration *ObjectLockConfig, err error) {
 	return configuration, nil
 }
 
+func (v *Volume) loadBucketWebsite() (configuration *WebsiteConfiguration, err error) {
+	var raw []byte
+	if raw, err = v.store.Get(v.name, bucketRootPath, XAttrKeyOSSWebsite); err != nil {
+		return
+	}
+	if len(raw) == 0 {
+		return
+	}
+	configuration = &WebsiteConfiguration{}
+	if err = xml.Unmarshal(raw, configuration); err != nil {
+		return
+	}
+	return configuration, nil
+}
+
+func (v *Volume) loadBucketLogging() (configuration *BucketLoggingStatus, err error) {
+	var raw []byte
+	if raw, err = v.store.Get(v.name, bucketRootPath, XAttrKeyOSSLogging); err != nil {
+		return
+	}
+	if len(raw) == 0 {
+		return
+	}
+	configuration = &BucketLoggingStatus{}
+	if err = xml.Unmarshal(raw, configuration); err != nil {
+		return
+	}
+	return configuration, nil
+}
+
+func (v *Volume) loadBucketPublicAccessBlock() (configuration *PublicAccessBlockConfiguration, err error) {
+	var raw []byte
+	if raw, err = v.store.Get(v.name, bucketRootPath, XAttrKeyOSSPublicAccessBlock); err != nil {
+		return
+	}
+	if len(raw) == 0 {
+		return
+	}
+	configuration = &PublicAccessBlockConfiguration{}
+	if err = xml.Unmarshal(raw, configuration); err != nil {
+		return
+	}
+	return configuration, nil
+}
+
+func (v *Volume) loadBucketMetrics() (configurations *BucketMetricsConfigurations, err error) {
+	var raw []byte
+	if raw, err = v.store.Get(v.name, bucketRootPath, XAttrKeyOSSMetrics); err != nil {
+		return
+	}
+	if len(raw) == 0 {
+		return
+	}
+	configurations = &BucketMetricsConfigurations{}
+	if err = xml.Unmarshal(raw, configurations); err != nil {
+		return
+	}
+	return configurations, nil
+}
+
 func (v *Volume) getInodeFromPath(path string) (inode uint64, err error) {
 	if path == "/" {
 		return volumeRootInode, nil
@@ -671,9 +762,16 @@ func (v *Volume) PutObject(path string, reader io.Reader, opt *PutFileOption) (f
 		return
 	}
 
+	// check the precondition before writing data, it is checked again when applying the dentry
+	if opt != nil && opt.Condition != nil {
+		if err = v.checkWriteCondition(oldInode, opt.Condition); err != nil {
+			return
+		}
+	}
+
 	// check whether existing object is protected by object lock
 	if oldInode != 0 && opt != nil && opt.ObjectLock != nil {
-		err = isObjectLocked(v, oldInode, lastPathItem.Name, path)
+		err = isObjectLocked(v, oldInode, lastPathItem.Name, path, false)
 		if err != nil {
 			return
 		}
@@ -712,6 +810,9 @@ func (v *Volume) PutObject(path string, reader io.Reader, opt *PutFileOption) (f
 		}
 	}()
 
+	if opt != nil && opt.Checksum != nil {
+		reader = opt.Checksum.Reader(reader)
+	}
 	if proto.IsCold(v.volType) {
 		if _, err = v.ebsWrite(invisibleTempDataInode.Inode, reader, md5Hash); err != nil {
 			log.LogErrorf("PutObject: ebs write fail: volume(%v) path(%v) inode(%v) err(%v)",
@@ -731,6 +832,13 @@ func (v *Volume) PutObject(path string, reader io.Reader, opt *PutFileOption) (f
 			return nil, err
 		}
 	}
+	if opt != nil && opt.Checksum != nil {
+		if err = opt.Checksum.Verify(); err != nil {
+			log.LogErrorf("PutObject: verify checksum fail: volume(%v) path(%v) inode(%v) algorithm(%v) err(%v)",
+				v.name, path, invisibleTempDataInode.Inode, opt.Checksum.Algorithm, err)
+			return
+		}
+	}
 
 	var finalInode *proto.InodeInfo
 	if finalInode, err = v.mw.InodeGet_ll(invisibleTempDataInode.Inode); err != nil {
@@ -773,6 +881,10 @@ func (v *Volume) PutObject(path string, reader io.Reader, opt *PutFileOption) (f
 	}
 	if opt != nil && opt.ObjectLock != nil && opt.ObjectLock.ToRetention() != nil {
 		attr.XAttrs[XAttrKeyOSSLock] = formatRetentionDateStr(finalInode.ModifyTime, opt.ObjectLock.ToRetention())
+		attr.XAttrs[XAttrKeyOSSLockMode] = opt.ObjectLock.ToRetention().Mode
+	}
+	if opt != nil && opt.Checksum != nil {
+		attr.XAttrs[XAttrKeyOSSChecksum] = opt.Checksum.Encode()
 	}
 
 	// If user-defined metadata have been specified, use extend attributes for storage.
@@ -802,9 +914,17 @@ func (v *Volume) PutObject(path string, reader io.Reader, opt *PutFileOption) (f
 		ETag:       etagValue.ETag(),
 		Inode:      finalInode.Inode,
 	}
+	if opt != nil && opt.Checksum != nil {
+		fsInfo.ChecksumAlgorithm = opt.Checksum.Algorithm
+		fsInfo.Checksum = opt.Checksum.Value
+	}
 
 	// apply new inode to dentry
-	err = v.applyInodeToDEntry(parentId, lastPathItem.Name, invisibleTempDataInode.Inode, false, fixedPath)
+	var cond *WriteCondition
+	if opt != nil {
+		cond = opt.Condition
+	}
+	err = v.applyInodeToDEntry(parentId, lastPathItem.Name, invisibleTempDataInode.Inode, false, fixedPath, cond)
 	if err != nil {
 		log.LogErrorf("PutObject: apply new inode to dentry fail: parentID(%v) name(%v) inode(%v) err(%v)",
 			parentId, lastPathItem.Name, invisibleTempDataInode.Inode, err)
@@ -818,16 +938,41 @@ func (v *Volume) PutObject(path string, reader io.Reader, opt *PutFileOption) (f
 	return fsInfo, nil
 }
 
-func (v *Volume) applyInodeToDEntry(parentId uint64, name string, inode uint64, isCompleteMultipart bool, fullPath string) (err error) {
+// applyInodeToDEntry applies the new inode to the dentry, if cond is not nil, the precondition
+// is checked with the existing object and the dentry is created or updated only if the object
+// is not changed by others after the check.
+func (v *Volume) applyInodeToDEntry(parentId uint64, name string, inode uint64, isCompleteMultipart bool, fullPath string,
+	cond *WriteCondition) (err error) {
+	var existInode uint64
 	var existMode uint32
-	_, existMode, err = v.mw.Lookup_ll(parentId, name) // exist object inode
+	existInode, existMode, err = v.mw.Lookup_ll(parentId, name) // exist object inode
 	if err != nil && err != syscall.ENOENT {
 		log.LogErrorf("applyInodeToDEntry: meta lookup fail: parentID(%v) name(%v) err(%v)", parentId, name, err)
 		return
 	}
 
+	var condInode uint64
+	if cond != nil {
+		if err == syscall.ENOENT {
+			existInode = 0
+		}
+		if err = v.checkWriteCondition(existInode, cond); err != nil {
+			log.LogWarnf("applyInodeToDEntry: precondition failed: parentID(%v) name(%v) inode(%v) cond(%v) err(%v)",
+				parentId, name, existInode, cond, err)
+			return
+		}
+		if existInode == 0 {
+			err = syscall.ENOENT
+		}
+		condInode = existInode
+	}
+
 	if err == syscall.ENOENT {
 		if err = v.applyInodeToNewDentry(parentId, name, inode, fullPath); err != nil {
+			if cond != nil && err == syscall.EEXIST {
+				// the object has been created by others since the precondition checked
+				err = PreconditionFailed
+			}
 			log.LogErrorf("applyInodeToDEntry: apply inode to new dentry fail: parentID(%v) name(%v) inode(%v) err(%v)",
 				parentId, name, inode, err)
 			return
@@ -844,7 +989,7 @@ func (v *Volume) applyInodeToDEntry(parentId uint64, name string, inode uint64,
 		// current implementation doesn't support object versioning, so uploading a object with a key already existed in bucket
 		// is implemented with replacing the old one instead.
 		// refer: https://docs.aws.amazon.com/AmazonS3/latest/userguide/upload-objects.html
-		if err = v.applyInodeToExistDentry(parentId, name, inode, isCompleteMultipart, fullPath); err != nil {
+		if err = v.applyInodeToExistDentry(parentId, name, inode, condInode, isCompleteMultipart, fullPath); err != nil {
 			log.LogErrorf("applyInodeToDEntry: apply inode to exist dentry fail: parentID(%v) name(%v) inode(%v) err(%v)",
 				parentId, name, inode, err)
 			return
@@ -861,6 +1006,16 @@ func (v *Volume) applyInodeToDEntry(parentId uint64, name string, inode uint64,
 // This method will only returns internal system errors.
 // This method will not return syscall.ENOENT error
 func (v *Volume) DeletePath(path string) (err error) {
+	return v.deletePath(path, false)
+}
+
+// DeletePathBypassGovernance deletes the specified path as DeletePath does,
+// except that the GOVERNANCE mode retention of the object lock is bypassed.
+func (v *Volume) DeletePathBypassGovernance(path string) (err error) {
+	return v.deletePath(path, true)
+}
+
+func (v *Volume) deletePath(path string, bypassGovernance bool) (err error) {
 	defer func() {
 		// Audit behavior
 		log.LogInfof("Audit: DeletePath: volume(%v) path(%v), err(%v)", v.name, path, err)
@@ -901,7 +1056,16 @@ func (v *Volume) DeletePath(path string) (err error) {
 
 	// delete dentry with condition when objectlock is open
 	if objetLock != nil {
-		_, err = v.mw.DeleteWithCond_ll(parent, ino, name, mode.IsDir(), path)
+		if !mode.IsDir() {
+			if err = isObjectLocked(v, ino, name, path, bypassGovernance); err != nil {
+				return
+			}
+		}
+		if bypassGovernance {
+			_, err = v.mw.DeleteWithCondBypassGovernance_ll(parent, ino, name, mode.IsDir(), path)
+		} else {
+			_, err = v.mw.DeleteWithCond_ll(parent, ino, name, mode.IsDir(), path)
+		}
 	} else {
 		_, err = v.mw.Delete_ll(parent, name, mode.IsDir(), path)
 	}
@@ -963,6 +1127,10 @@ func (v *Volume) InitMultipart(path string, opt *PutFileOption) (multipartID str
 	if opt != nil && opt.ACL != nil {
 		extend[XAttrKeyOSSACL] = opt.ACL.Encode()
 	}
+	// If checksum algorithm have been specified, the checksum of each part is required.
+	if opt != nil && opt.Checksum != nil {
+		extend[XAttrKeyOSSChecksumAlgorithm] = opt.Checksum.Algorithm
+	}
 
 	if v.mw.EnableQuota {
 		var parentId uint64
@@ -986,7 +1154,7 @@ func (v *Volume) InitMultipart(path string, opt *PutFileOption) (multipartID str
 	return multipartID, nil
 }
 
-func (v *Volume) WritePart(path string, multipartId string, partId uint16, reader io.Reader) (*FSFileInfo, error) {
+func (v *Volume) WritePart(path string, multipartId string, partId uint16, reader io.Reader, checksum *Checksum) (*FSFileInfo, error) {
 	var exist bool
 	var err error
 	defer func() {
@@ -998,6 +1166,29 @@ func (v *Volume) WritePart(path string, multipartId string, partId uint16, reade
 	var fInfo *FSFileInfo
 	_, fileName := splitPath(path)
 
+	// The checksum of each part is required if the checksum algorithm is specified when creating multipart,
+	// it is computed by the server if the part is uploaded without checksum.
+	var multipartInfo *proto.MultipartInfo
+	if multipartInfo, err = v.mw.GetMultipart_ll(path, multipartId); err != nil {
+		log.LogErrorf("WritePart: meta get multipart fail: volume(%v) path(%v) multipartID(%v) partID(%v) err(%v)",
+			v.name, path, multipartId, partId, err)
+		return nil, err
+	}
+	if algorithm := multipartInfo.Extend[XAttrKeyOSSChecksumAlgorithm]; algorithm != "" {
+		if checksum == nil {
+			var errorCode *ErrorCode
+			if checksum, errorCode = NewChecksum(algorithm); errorCode != nil {
+				err = errorCode
+				return nil, err
+			}
+		} else if checksum.Algorithm != algorithm {
+			log.LogErrorf("WritePart: checksum algorithm mismatch: volume(%v) path(%v) multipartID(%v) partID(%v) algorithm(%v) expected(%v)",
+				v.name, path, multipartId, partId, checksum.Algorithm, algorithm)
+			err = ChecksumTypeMismatch
+			return nil, err
+		}
+	}
+
 	// create temp file (inode only, invisible for user)
 	var tempInodeInfo *proto.InodeInfo
 	if tempInodeInfo, err = v.mw.InodeCreate_ll(0, DefaultFileMode, 0, 0, nil, make([]uint64, 0), path); err != nil {
@@ -1046,6 +1237,9 @@ func (v *Volume) WritePart(path string, multipartId string, partId uint16, reade
 				v.name, path, multipartId, partId, tempInodeInfo.Inode, closeErr)
 		}
 	}()
+	if checksum != nil {
+		reader = checksum.Reader(reader)
+	}
 	if proto.IsCold(v.volType) {
 		if size, err = v.ebsWrite(tempInodeInfo.Inode, reader, md5Hash); err != nil {
 			log.LogErrorf("WritePart: ebs write fail: volume(%v) inode(%v) multipartID(%v) partID(%v) err(%v)",
@@ -1069,6 +1263,20 @@ func (v *Volume) WritePart(path string, multipartId string, partId uint16, reade
 	// compute file md5
 	etag = hex.EncodeToString(md5Hash.Sum(nil))
 
+	// verify and store the checksum of part, which is composed when completing multipart
+	if checksum != nil {
+		if err = checksum.Verify(); err != nil {
+			log.LogErrorf("WritePart: verify checksum fail: volume(%v) path(%v) multipartID(%v) partID(%v) inode(%v) algorithm(%v) err(%v)",
+				v.name, path, multipartId, partId, tempInodeInfo.Inode, checksum.Algorithm, err)
+			return nil, err
+		}
+		if err = v.mw.XAttrSet_ll(tempInodeInfo.Inode, []byte(XAttrKeyOSSChecksum), []byte(checksum.Encode())); err != nil {
+			log.LogErrorf("WritePart: meta set checksum fail: volume(%v) path(%v) multipartID(%v) partID(%v) inode(%v) err(%v)",
+				v.name, path, multipartId, partId, tempInodeInfo.Inode, err)
+			return nil, err
+		}
+	}
+
 	// update temp file inode to meta with session, overwrite existing part can result in exist == true
 	oldInode, exist, err = v.mw.AddMultipartPart_ll(path, multipartId, partId, size, etag, tempInodeInfo)
 	if err != nil {
@@ -1088,6 +1296,10 @@ func (v *Volume) WritePart(path string, multipartId string, partId uint16, reade
 		ETag:       etag,
 		Inode:      tempInodeInfo.Inode,
 	}
+	if checksum != nil {
+		fInfo.ChecksumAlgorithm = checksum.Algorithm
+		fInfo.Checksum = checksum.Value
+	}
 	return fInfo, nil
 }
 
@@ -1127,7 +1339,8 @@ func (v *Volume) AbortMultipart(path string, multipartID string) (err error) {
 	return nil
 }
 
-func (v *Volume) CompleteMultipart(path, multipartID string, multipartInfo *proto.MultipartInfo, discardedPartInodes map[uint64]uint16) (fsFileInfo *FSFileInfo, err error) {
+func (v *Volume) CompleteMultipart(path, multipartID string, multipartInfo *proto.MultipartInfo, discardedPartInodes map[uint64]uint16,
+	cond *WriteCondition) (fsFileInfo *FSFileInfo, err error) {
 	defer func() {
 		log.LogInfof("Audit: CompleteMultipart: volume(%v) path(%v) multipartID(%v) err(%v)",
 			v.name, path, multipartID, err)
@@ -1156,6 +1369,12 @@ func (v *Volume) CompleteMultipart(path, multipartID string, multipartInfo *prot
 		err = syscall.EINVAL
 		return
 	}
+	// check the precondition before completing, it is checked again when applying the dentry
+	if cond != nil {
+		if err = v.checkWriteCondition(oldInode, cond); err != nil {
+			return
+		}
+	}
 	// check whether object is protected by object lock
 	objectLock, err := v.metaLoader.loadObjectLock()
 	if err != nil {
@@ -1163,7 +1382,7 @@ func (v *Volume) CompleteMultipart(path, multipartID string, multipartInfo *prot
 		return
 	}
 	if oldInode != 0 && objectLock != nil {
-		err = isObjectLocked(v, oldInode, filename, path)
+		err = isObjectLocked(v, oldInode, filename, path, false)
 		if err != nil {
 			return
 		}
@@ -1278,11 +1497,26 @@ func (v *Volume) CompleteMultipart(path, multipartID string, multipartInfo *prot
 	extend := multipartInfo.Extend
 	if len(extend) > 0 {
 		for key, value := range extend {
+			if key == XAttrKeyOSSChecksumAlgorithm {
+				continue
+			}
 			attrs[key] = value
 		}
 	}
+	// compose checksum of parts if checksum algorithm specified
+	var checksumAlgorithm, checksumValue string
+	if algorithm := extend[XAttrKeyOSSChecksumAlgorithm]; algorithm != "" {
+		if checksumValue, err = v.composePartsChecksum(algorithm, parts); err != nil {
+			log.LogErrorf("CompleteMultipart: compose checksum fail: volume(%v) multipartID(%v) algorithm(%v) err(%v)",
+				v.name, multipartID, algorithm, err)
+			return nil, err
+		}
+		checksumAlgorithm = algorithm
+		attrs[XAttrKeyOSSChecksum] = checksumAlgorithm + ":" + checksumValue
+	}
 	if objectLock != nil && objectLock.ToRetention() != nil {
 		attrs[XAttrKeyOSSLock] = formatRetentionDateStr(finalInode.ModifyTime, objectLock.ToRetention())
+		attrs[XAttrKeyOSSLockMode] = objectLock.ToRetention().Mode
 	}
 	if err = v.mw.BatchSetXAttr_ll(finalInode.Inode, attrs); err != nil {
 		log.LogErrorf("CompleteMultipart: store multipart extend fail: volume(%v) multipartID(%v) inode(%v) "+
@@ -1291,7 +1525,7 @@ func (v *Volume) CompleteMultipart(path, multipartID string, multipartInfo *prot
 	}
 
 	// apply new inode to dentry
-	if err = v.applyInodeToDEntry(parentId, filename, completeInodeInfo.Inode, true, path); err != nil {
+	if err = v.applyInodeToDEntry(parentId, filename, completeInodeInfo.Inode, true, path, cond); err != nil {
 		log.LogErrorf("CompleteMultipart: apply inode to dentry fail: volume(%v) multipartID(%v) parentId(%v) "+
 			"fileName(%v) inode(%v) err(%v)", v.name, multipartID, parentId, filename, completeInodeInfo.Inode, err)
 		return
@@ -1334,18 +1568,38 @@ func (v *Volume) CompleteMultipart(path, multipartID string, multipartInfo *prot
 		v.name, multipartID, path, parentId, finalInode.Inode, etagValue)
 	// create file info
 	fInfo := &FSFileInfo{
-		Path:       path,
-		Size:       int64(size),
-		Mode:       os.FileMode(DefaultFileMode),
-		CreateTime: time.Now(),
-		ModifyTime: time.Now(),
-		ETag:       etagValue.ETag(),
-		Inode:      finalInode.Inode,
+		Path:              path,
+		Size:              int64(size),
+		Mode:              os.FileMode(DefaultFileMode),
+		CreateTime:        time.Now(),
+		ModifyTime:        time.Now(),
+		ETag:              etagValue.ETag(),
+		Inode:             finalInode.Inode,
+		ChecksumAlgorithm: checksumAlgorithm,
+		Checksum:          checksumValue,
 	}
 
 	return fInfo, nil
 }
 
+func (v *Volume) composePartsChecksum(algorithm string, parts []*proto.MultipartPartInfo) (string, error) {
+	values := make([]string, 0, len(parts))
+	for _, part := range parts {
+		xattr, err := v.mw.XAttrGet_ll(part.Inode, XAttrKeyOSSChecksum)
+		if err != nil {
+			return "", err
+		}
+		partAlgorithm, value := ParseChecksumXAttr(string(xattr.Get(XAttrKeyOSSChecksum)))
+		if partAlgorithm != algorithm {
+			log.LogErrorf("composePartsChecksum: part checksum mismatch: volume(%v) partID(%v) inode(%v) algorithm(%v) partAlgorithm(%v)",
+				v.name, part.ID, part.Inode, algorithm, partAlgorithm)
+			return "", InvalidPart
+		}
+		values = append(values, value)
+	}
+	return ComposeChecksum(algorithm, values)
+}
+
 func (v *Volume) ebsWrite(inode uint64, reader io.Reader, h hash.Hash) (size uint64, err error) {
 	ctx := context.Background()
 	size, err = v.getEbsWriter(inode).WriteFromReader(ctx, reader, h)
@@ -1458,9 +1712,22 @@ func (v *Volume) applyInodeToNewDentry(parentID uint64, name string, inode uint6
 	return
 }
 
-func (v *Volume) applyInodeToExistDentry(parentID uint64, name string, inode uint64, isCompleteMultipart bool, fullPath string) (err error) {
+// applyInodeToExistDentry replaces the inode of the dentry, if condInode is not zero, the dentry
+// is updated only if it still refers to the condInode.
+func (v *Volume) applyInodeToExistDentry(parentID uint64, name string, inode, condInode uint64, isCompleteMultipart bool,
+	fullPath string) (err error) {
 	var oldInode uint64
-	oldInode, err = v.mw.DentryUpdate_ll(parentID, name, inode, fullPath)
+	if condInode != 0 {
+		oldInode, err = v.mw.DentryUpdateWithCond_ll(parentID, name, inode, condInode, fullPath)
+		if err == syscall.EINVAL || err == syscall.ENOENT {
+			// the object has been replaced or deleted by others since the precondition checked
+			log.LogWarnf("applyInodeToExistDentry: dentry changed: parentID(%v) name(%v) inode(%v) condInode(%v) err(%v)",
+				parentID, name, inode, condInode, err)
+			return PreconditionFailed
+		}
+	} else {
+		oldInode, err = v.mw.DentryUpdate_ll(parentID, name, inode, fullPath)
+	}
 	if err != nil {
 		log.LogErrorf("applyInodeToExistDentry: meta update dentry fail: parentID(%v) name(%v) inode(%v) err(%v)",
 			parentID, name, inode, err)
@@ -1501,6 +1768,40 @@ func (v *Volume) applyInodeToExistDentry(parentID uint64, name string, inode uin
 	return
 }
 
+// checkWriteCondition checks the precondition of the conditional write with the existing object,
+// the existInode is zero if the object does not exist.
+func (v *Volume) checkWriteCondition(existInode uint64, cond *WriteCondition) (err error) {
+	if existInode == 0 || cond.IfMatch == "" || cond.IfMatch == "*" {
+		return cond.Check(existInode != 0, "")
+	}
+	var inoInfo *proto.InodeInfo
+	if inoInfo, err = v.mw.InodeGet_ll(existInode); err != nil {
+		log.LogErrorf("checkWriteCondition: get inode fail: volume(%v) inode(%v) err(%v)", v.name, existInode, err)
+		return
+	}
+	var xattr *proto.XAttrInfo
+	if xattr, err = v.mw.XAttrGetAll_ll(existInode); err != nil {
+		log.LogErrorf("checkWriteCondition: get xattr fail: volume(%v) inode(%v) err(%v)", v.name, existInode, err)
+		return
+	}
+	var etagValue ETagValue
+	if os.FileMode(inoInfo.Mode).IsDir() {
+		etagValue = DirectoryETagValue()
+	} else {
+		rawETag := string(xattr.Get(XAttrKeyOSSETag))
+		if len(rawETag) == 0 {
+			rawETag = string(xattr.Get(XAttrKeyOSSETagDeprecated))
+		}
+		etagValue = ParseETagValue(rawETag)
+	}
+	// the ETag is stale if the data has been modified after written by objectnode
+	var etag string
+	if etagValue.Valid() && !etagValue.TS.Before(inoInfo.ModifyTime) {
+		etag = etagValue.ETag()
+	}
+	return cond.Check(true, etag)
+}
+
 func (v *Volume) loadUserDefinedMetadata(inode uint64) (metadata map[string]string, err error) {
 	var storedXAttrKeys []string
 	if storedXAttrKeys, err = v.mw.XAttrsList_ll(inode); err != nil {
@@ -1764,11 +2065,19 @@ func (v *Volume) ObjectMeta(path string) (info *FSFileInfo, xattr *proto.XAttrIn
 			retainUntilDate = time.Unix(0, retainUntilDateInt64).UTC().Format(ISO8601Layout)
 		}
 	}
+	var retentionMode string
+	if retainUntilDate != "" {
+		retentionMode = objectRetentionMode(xattr)
+	}
 
 	// Validating ETag value.
+	var checksumAlgorithm, checksum string
 	if !mode.IsDir() && (!etagValue.Valid() || etagValue.TS.Before(inoInfo.ModifyTime)) {
 		log.LogWarnf("ObjectMeta: etag invalid or before inode modTime: volume(%v) path(%v) inoInfo(%v) etagVal(%v)",
 			v.name, path, inoInfo, etagValue)
+	} else if !mode.IsDir() {
+		// The checksum is stale as well if the data has been modified after written by objectnode.
+		checksumAlgorithm, checksum = ParseChecksumXAttr(string(xattr.Get(XAttrKeyOSSChecksum)))
 	}
 
 	info = &FSFileInfo{
@@ -1785,6 +2094,11 @@ func (v *Volume) ObjectMeta(path string) (info *FSFileInfo, xattr *proto.XAttrIn
 		Expires:         expires,
 		Metadata:        metadata,
 		RetainUntilDate: retainUntilDate,
+		RetentionMode:   retentionMode,
+		LegalHold:       string(xattr.Get(XAttrKeyOSSLegalHold)),
+
+		ChecksumAlgorithm: checksumAlgorithm,
+		Checksum:          checksum,
 	}
 	return
 }
@@ -1997,7 +2311,7 @@ func (v *Volume) recursiveMakeDirectory(path string) (partentIno uint64, err err
 		}
 		if err == syscall.ENOENT {
 			var info *proto.InodeInfo
-			info, err = v.mw.Create_ll(partentIno, pathItem.Name, uint32(DefaultDirMode), 0, 0, nil, path[:pathIterator.cursor], false)
+			info, err = v.mw.Create_ll(partentIno, pathItem.Name, uint32(v.dirMode()), 0, 0, nil, path[:pathIterator.cursor], false)
 			if err != nil && err == syscall.EEXIST {
 				existInode, mode, e := v.mw.Lookup_ll(partentIno, pathItem.Name)
 				if e != nil {
@@ -2046,7 +2360,7 @@ func (v *Volume) lookupDirectories(dirs []string, autoCreate bool) (inode uint64
 		if lookupErr == syscall.ENOENT {
 			var inodeInfo *proto.InodeInfo
 			var createErr error
-			inodeInfo, createErr = v.mw.Create_ll(parentId, dir, uint32(DefaultDirMode), 0, 0, nil, "/"+dir, false)
+			inodeInfo, createErr = v.mw.Create_ll(parentId, dir, uint32(v.dirMode()), 0, 0, nil, "/"+dir, false)
 			if createErr != nil && createErr != syscall.EEXIST {
 				log.LogErrorf("lookupDirectories: meta create fail, parentID(%v) name(%v) mode(%v) err(%v)", parentId, dir, os.ModeDir, createErr)
 				return 0, createErr
@@ -2622,9 +2936,14 @@ func (v *Volume) CopyFile(sv *Volume, sourcePath, targetPath, metaDirective stri
 			log.LogInfof("CopyFile: targetPath(%v) is equal with sourcePath(%v),but metaDirective(%v) is not REPLACE",
 				targetPath, sourcePath, metaDirective)
 		} else {
+			if opt != nil && opt.Condition != nil {
+				if err = v.checkWriteCondition(sInode, opt.Condition); err != nil {
+					return
+				}
+			}
 			// check whether target object is protected by object lock
 			if opt != nil && opt.ObjectLock != nil {
-				err = isObjectLocked(v, sInode, sName, sourcePath)
+				err = isObjectLocked(v, sInode, sName, sourcePath, false)
 				if err != nil {
 					return
 				}
@@ -2654,6 +2973,7 @@ func (v *Volume) CopyFile(sv *Volume, sourcePath, targetPath, metaDirective stri
 			}
 			if opt != nil && opt.ObjectLock != nil && opt.ObjectLock.ToRetention() != nil {
 				attr.XAttrs[XAttrKeyOSSLock] = formatRetentionDateStr(time.Now(), opt.ObjectLock.ToRetention())
+				attr.XAttrs[XAttrKeyOSSLockMode] = opt.ObjectLock.ToRetention().Mode
 			}
 			// If user-defined metadata have been specified, use extend attributes for storage.
 			if opt != nil && len(opt.Metadata) > 0 {
@@ -2700,7 +3020,7 @@ func (v *Volume) CopyFile(sv *Volume, sourcePath, targetPath, metaDirective stri
 		return nil, syscall.EINVAL
 	}
 	// if source file mode is directory, return OK, and need't create target directory
-	if sMode == DefaultDirMode {
+	if sMode.IsDir() {
 		// create target directory
 		if !strings.HasSuffix(targetPath, pathSep) {
 			targetPath += pathSep
@@ -2743,7 +3063,7 @@ func (v *Volume) CopyFile(sv *Volume, sourcePath, targetPath, metaDirective stri
 
 	// check whether existing object is protected by object lock
 	if oldtInode != 0 && opt != nil && opt.ObjectLock != nil {
-		err = isObjectLocked(v, oldtInode, tLastName, targetPath)
+		err = isObjectLocked(v, oldtInode, tLastName, targetPath, false)
 		if err != nil {
 			return
 		}
@@ -2882,7 +3202,8 @@ func (v *Volume) CopyFile(sv *Volume, sourcePath, targetPath, metaDirective stri
 			return
 		}
 		for key, val := range xattr.XAttrs {
-			if key == XAttrKeyOSSETag {
+			// the object lock of target is decided by the target bucket
+			if key == XAttrKeyOSSETag || isObjectLockXAttr(key) {
 				continue
 			}
 			targetAttr.XAttrs[key] = val
@@ -2892,6 +3213,7 @@ func (v *Volume) CopyFile(sv *Volume, sourcePath, targetPath, metaDirective stri
 		}
 		if opt != nil && opt.ObjectLock != nil && opt.ObjectLock.ToRetention() != nil {
 			targetAttr.XAttrs[XAttrKeyOSSLock] = formatRetentionDateStr(tInodeInfo.ModifyTime, opt.ObjectLock.ToRetention())
+			targetAttr.XAttrs[XAttrKeyOSSLockMode] = opt.ObjectLock.ToRetention().Mode
 		}
 		if err = v.mw.BatchSetXAttr_ll(tInodeInfo.Inode, targetAttr.XAttrs); err != nil {
 			log.LogErrorf("CopyFile: set target xattr fail: volume(%v) target path(%v) inode(%v) xattr (%v)err(%v)",
@@ -2921,6 +3243,7 @@ func (v *Volume) CopyFile(sv *Volume, sourcePath, targetPath, metaDirective stri
 		}
 		if opt != nil && opt.ObjectLock != nil && opt.ObjectLock.ToRetention() != nil {
 			targetAttr.XAttrs[XAttrKeyOSSLock] = formatRetentionDateStr(tInodeInfo.ModifyTime, opt.ObjectLock.ToRetention())
+			targetAttr.XAttrs[XAttrKeyOSSLockMode] = opt.ObjectLock.ToRetention().Mode
 		}
 
 		// If user-defined metadata have been specified, use extend attributes for storage.
@@ -2956,10 +3279,15 @@ func (v *Volume) CopyFile(sv *Volume, sourcePath, targetPath, metaDirective stri
 	}
 
 	// apply new inode to dentry
-	err = v.applyInodeToDEntry(tParentId, tLastName, tInodeInfo.Inode, false, targetPath)
+	var cond *WriteCondition
+	if opt != nil {
+		cond = opt.Condition
+	}
+	err = v.applyInodeToDEntry(tParentId, tLastName, tInodeInfo.Inode, false, targetPath, cond)
 	if err != nil {
 		log.LogErrorf("CopyFile: apply inode to new dentry fail: path(%v) parentID(%v) name(%v) inode(%v) err(%v)",
 			targetPath, tParentId, tLastName, tInodeInfo.Inode, err)
+		return
 	}
 
 	// force updating dentry and attrs in cache
@@ -3023,6 +3351,7 @@ func NewVolume(config *VolumeConfig) (*Volume, error) {
 		OnSplitExtentKey:  metaWrapper.SplitExtentKey,
 		OnGetExtents:      metaWrapper.GetExtents,
 		OnTruncate:        metaWrapper.Truncate,
+		OnModifyInode:     metaWrapper.InodeModify,
 	}
 	if proto.IsCold(volumeInfo.VolType) {
 		if blockCache != nil {
@@ -3067,6 +3396,8 @@ func NewVolume(config *VolumeConfig) (*Volume, error) {
 		}
 		go v.syncOSSMeta()
 	}
+	v.updatePermission(volumeInfo)
+	go v.syncPermission(mc)
 
 	return v, nil
 }
@@ -26,10 +26,18 @@ type ossMetaLoader interface {
 	loadACL() (p *AccessControlPolicy, err error)
 	loadCORS() (cors *CORSConfiguration, err error)
 	loadObjectLock() (config *ObjectLockConfig, err error)
+	loadWebsite() (website *WebsiteConfiguration, err error)
+	loadLogging() (logging *BucketLoggingStatus, err error)
+	loadPublicAccessBlock() (config *PublicAccessBlockConfiguration, err error)
+	loadMetrics() (metrics *BucketMetricsConfigurations, err error)
 	storePolicy(p *Policy)
 	storeACL(p *AccessControlPolicy)
 	storeCORS(cors *CORSConfiguration)
 	storeObjectLock(config *ObjectLockConfig)
+	storeWebsite(website *WebsiteConfiguration)
+	storeLogging(logging *BucketLoggingStatus)
+	storePublicAccessBlock(config *PublicAccessBlockConfiguration)
+	storeMetrics(metrics *BucketMetricsConfigurations)
 	setSynced()
 }
 
@@ -46,14 +54,22 @@ type cacheMetaLoader struct {
 
 // OSSMeta is bucket policy and ACL metadata.
 type OSSMeta struct {
-	policy     *Policy
-	acl        *AccessControlPolicy
-	corsConfig *CORSConfiguration
-	lockConfig *ObjectLockConfig
-	policyLock sync.RWMutex
-	aclLock    sync.RWMutex
-	corsLock   sync.RWMutex
-	objectLock sync.RWMutex
+	policy        *Policy
+	acl           *AccessControlPolicy
+	corsConfig    *CORSConfiguration
+	lockConfig    *ObjectLockConfig
+	websiteConfig *WebsiteConfiguration
+	loggingConfig *BucketLoggingStatus
+	publicAccess  *PublicAccessBlockConfiguration
+	metricsConfig *BucketMetricsConfigurations
+	policyLock    sync.RWMutex
+	aclLock       sync.RWMutex
+	corsLock      sync.RWMutex
+	objectLock    sync.RWMutex
+	websiteLock   sync.RWMutex
+	loggingLock   sync.RWMutex
+	publicLock    sync.RWMutex
+	metricsLock   sync.RWMutex
 }
 
 func (c *cacheMetaLoader) loadPolicy() (p *Policy, err error) {
@@ -152,6 +168,102 @@ func (c *cacheMetaLoader) storeObjectLock(config *ObjectLockConfig) {
 	c.om.objectLock.Unlock()
 }
 
+func (c *cacheMetaLoader) loadWebsite() (website *WebsiteConfiguration, err error) {
+	c.om.websiteLock.RLock()
+	website = c.om.websiteConfig
+	c.om.websiteLock.RUnlock()
+	if website == nil && atomic.LoadInt32(c.synced) == 0 {
+		ret, err, _ := c.sf.Do(XAttrKeyOSSWebsite, func() (interface{}, error) {
+			w, err := c.sml.loadWebsite()
+			return w, err
+		})
+		if err != nil {
+			return nil, err
+		}
+		website = ret.(*WebsiteConfiguration)
+		c.storeWebsite(website)
+	}
+	return
+}
+
+func (c *cacheMetaLoader) storeWebsite(website *WebsiteConfiguration) {
+	c.om.websiteLock.Lock()
+	c.om.websiteConfig = website
+	c.om.websiteLock.Unlock()
+}
+
+func (c *cacheMetaLoader) loadLogging() (logging *BucketLoggingStatus, err error) {
+	c.om.loggingLock.RLock()
+	logging = c.om.loggingConfig
+	c.om.loggingLock.RUnlock()
+	if logging == nil && atomic.LoadInt32(c.synced) == 0 {
+		ret, err, _ := c.sf.Do(XAttrKeyOSSLogging, func() (interface{}, error) {
+			l, err := c.sml.loadLogging()
+			return l, err
+		})
+		if err != nil {
+			return nil, err
+		}
+		logging = ret.(*BucketLoggingStatus)
+		c.storeLogging(logging)
+	}
+	return
+}
+
+func (c *cacheMetaLoader) storeLogging(logging *BucketLoggingStatus) {
+	c.om.loggingLock.Lock()
+	c.om.loggingConfig = logging
+	c.om.loggingLock.Unlock()
+}
+
+func (c *cacheMetaLoader) loadPublicAccessBlock() (config *PublicAccessBlockConfiguration, err error) {
+	c.om.publicLock.RLock()
+	config = c.om.publicAccess
+	c.om.publicLock.RUnlock()
+	if config == nil && atomic.LoadInt32(c.synced) == 0 {
+		ret, err, _ := c.sf.Do(XAttrKeyOSSPublicAccessBlock, func() (interface{}, error) {
+			p, err := c.sml.loadPublicAccessBlock()
+			return p, err
+		})
+		if err != nil {
+			return nil, err
+		}
+		config = ret.(*PublicAccessBlockConfiguration)
+		c.storePublicAccessBlock(config)
+	}
+	return
+}
+
+func (c *cacheMetaLoader) storePublicAccessBlock(config *PublicAccessBlockConfiguration) {
+	c.om.publicLock.Lock()
+	c.om.publicAccess = config
+	c.om.publicLock.Unlock()
+}
+
+func (c *cacheMetaLoader) loadMetrics() (metrics *BucketMetricsConfigurations, err error) {
+	c.om.metricsLock.RLock()
+	metrics = c.om.metricsConfig
+	c.om.metricsLock.RUnlock()
+	if metrics == nil && atomic.LoadInt32(c.synced) == 0 {
+		ret, err, _ := c.sf.Do(XAttrKeyOSSMetrics, func() (interface{}, error) {
+			m, err := c.sml.loadMetrics()
+			return m, err
+		})
+		if err != nil {
+			return nil, err
+		}
+		metrics = ret.(*BucketMetricsConfigurations)
+		c.storeMetrics(metrics)
+	}
+	return
+}
+
+func (c *cacheMetaLoader) storeMetrics(metrics *BucketMetricsConfigurations) {
+	c.om.metricsLock.Lock()
+	c.om.metricsConfig = metrics
+	c.om.metricsLock.Unlock()
+}
+
 func (c *cacheMetaLoader) setSynced() {
 	atomic.StoreInt32(c.synced, 1)
 }
@@ -188,6 +300,38 @@ func (s *strictMetaLoader) storeObjectLock(cors *ObjectLockConfig) {
 	// do nothing
 }
 
+func (s *strictMetaLoader) loadWebsite() (website *WebsiteConfiguration, err error) {
+	return s.v.loadBucketWebsite()
+}
+
+func (s *strictMetaLoader) storeWebsite(website *WebsiteConfiguration) {
+	// do nothing
+}
+
+func (s *strictMetaLoader) loadLogging() (logging *BucketLoggingStatus, err error) {
+	return s.v.loadBucketLogging()
+}
+
+func (s *strictMetaLoader) storeLogging(logging *BucketLoggingStatus) {
+	// do nothing
+}
+
+func (s *strictMetaLoader) loadPublicAccessBlock() (config *PublicAccessBlockConfiguration, err error) {
+	return s.v.loadBucketPublicAccessBlock()
+}
+
+func (s *strictMetaLoader) storePublicAccessBlock(config *PublicAccessBlockConfiguration) {
+	// do nothing
+}
+
+func (s *strictMetaLoader) loadMetrics() (metrics *BucketMetricsConfigurations, err error) {
+	return s.v.loadBucketMetrics()
+}
+
+func (s *strictMetaLoader) storeMetrics(metrics *BucketMetricsConfigurations) {
+	// do nothing
+}
+
 func (s *strictMetaLoader) setSynced() {
 	// do nothing
 }
@@ -0,0 +1,243 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"encoding/xml"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/cubefs/cubefs/proto"
+)
+
+const (
+	MaxInventorySize             = 1 << 14 // 16KB
+	MaxInventoryConfigurations   = 1000
+	MaxInventoryListResultCounts = 100
+
+	InventoryBucketARNPrefix       = "arn:aws:s3:::"
+	InventoryObjectVersionsAll     = "All"
+	InventoryObjectVersionsCurrent = "Current"
+)
+
+var (
+	InventoryErrIDMismatch       = &ErrorCode{ErrorCode: "InvalidArgument", ErrorMessage: "The inventory configuration ID in the request does not match the ID in the body.", StatusCode: http.StatusBadRequest}
+	InventoryErrInvalidID        = &ErrorCode{ErrorCode: "InvalidArgument", ErrorMessage: "The inventory configuration ID is invalid.", StatusCode: http.StatusBadRequest}
+	InventoryErrInvalidFormat    = &ErrorCode{ErrorCode: "InvalidArgument", ErrorMessage: "The inventory format must be CSV or Parquet.", StatusCode: http.StatusBadRequest}
+	InventoryErrInvalidFrequency = &ErrorCode{ErrorCode: "InvalidArgument", ErrorMessage: "The inventory frequency must be Daily or Weekly.", StatusCode: http.StatusBadRequest}
+	InventoryErrInvalidVersions  = &ErrorCode{ErrorCode: "InvalidArgument", ErrorMessage: "The included object versions of the inventory must be All or Current.", StatusCode: http.StatusBadRequest}
+	InventoryErrInvalidField     = &ErrorCode{ErrorCode: "InvalidArgument", ErrorMessage: "The inventory optional field is invalid or duplicated.", StatusCode: http.StatusBadRequest}
+	InventoryErrInvalidBucket    = &ErrorCode{ErrorCode: "InvalidArgument", ErrorMessage: "The destination bucket of the inventory does not exist or is not owned by you.", StatusCode: http.StatusBadRequest}
+	InventoryErrTooManyConfigs   = &ErrorCode{ErrorCode: "TooManyConfigurations", ErrorMessage: "You are attempting to create a new configuration but have already reached the 1,000-configuration limit.", StatusCode: http.StatusBadRequest}
+	NoSuchInventoryConfiguration = &ErrorCode{ErrorCode: "NoSuchConfiguration", ErrorMessage: "The specified configuration does not exist.", StatusCode: http.StatusNotFound}
+	InventoryErrInvalidToken     = &ErrorCode{ErrorCode: "InvalidArgument", ErrorMessage: "The continuation token provided is incorrect.", StatusCode: http.StatusBadRequest}
+)
+
+var inventoryOptionalFields = map[string]struct{}{
+	proto.InventoryFieldSize:             {},
+	proto.InventoryFieldLastModifiedDate: {},
+	proto.InventoryFieldETag:             {},
+	proto.InventoryFieldStorageClass:     {},
+	proto.InventoryFieldTags:             {},
+}
+
+// InventoryConfiguration is the inventory configuration of the bucket, the inventory reports are
+// generated by lcnode into the destination bucket daily or weekly.
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_InventoryConfiguration.html
+type InventoryConfiguration struct {
+	XMLName                xml.Name                 `xml:"InventoryConfiguration"`
+	Xmlns                  string                   `xml:"xmlns,attr,omitempty"`
+	Destination            *InventoryDestination    `xml:"Destination"`
+	IsEnabled              bool                     `xml:"IsEnabled"`
+	Filter                 *InventoryFilter         `xml:"Filter,omitempty"`
+	ID                     string                   `xml:"Id"`
+	IncludedObjectVersions string                   `xml:"IncludedObjectVersions"`
+	OptionalFields         *InventoryOptionalFields `xml:"OptionalFields,omitempty"`
+	Schedule               *InventorySchedule       `xml:"Schedule"`
+}
+
+type InventoryDestination struct {
+	S3BucketDestination *InventoryS3BucketDestination `xml:"S3BucketDestination"`
+}
+
+type InventoryS3BucketDestination struct {
+	AccountID string `xml:"AccountId,omitempty"`
+	Bucket    string `xml:"Bucket"`
+	Format    string `xml:"Format"`
+	Prefix    string `xml:"Prefix,omitempty"`
+}
+
+type InventoryFilter struct {
+	Prefix string `xml:"Prefix"`
+}
+
+type InventoryOptionalFields struct {
+	Fields []string `xml:"Field"`
+}
+
+type InventorySchedule struct {
+	Frequency string `xml:"Frequency"`
+}
+
+// ListInventoryConfigurationsResult is the result of ListBucketInventoryConfigurations.
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_ListBucketInventoryConfigurations.html
+type ListInventoryConfigurationsResult struct {
+	XMLName                 xml.Name                  `xml:"ListInventoryConfigurationsResult"`
+	Xmlns                   string                    `xml:"xmlns,attr,omitempty"`
+	ContinuationToken       string                    `xml:"ContinuationToken,omitempty"`
+	InventoryConfigurations []*InventoryConfiguration `xml:"InventoryConfiguration"`
+	IsTruncated             bool                      `xml:"IsTruncated"`
+	NextContinuationToken   string                    `xml:"NextContinuationToken,omitempty"`
+}
+
+func parseInventoryConfiguration(body []byte, id string) (*InventoryConfiguration, *ErrorCode) {
+	conf := &InventoryConfiguration{}
+	if err := xml.Unmarshal(body, conf); err != nil {
+		return nil, MalformedXML
+	}
+	if errorCode := conf.validate(id); errorCode != nil {
+		return nil, errorCode
+	}
+	return conf, nil
+}
+
+func (c *InventoryConfiguration) validate(id string) *ErrorCode {
+	if c.ID == "" || len(c.ID) > MaxIdLength || strings.ContainsAny(c.ID, "/:") {
+		return InventoryErrInvalidID
+	}
+	if c.ID != id {
+		return InventoryErrIDMismatch
+	}
+	if c.Destination == nil || c.Destination.S3BucketDestination == nil || c.Schedule == nil {
+		return MalformedXML
+	}
+	dest := c.Destination.S3BucketDestination
+	if c.destinationBucket() == "" {
+		return InventoryErrInvalidBucket
+	}
+	if dest.Format != proto.InventoryFormatCSV && dest.Format != proto.InventoryFormatParquet {
+		return InventoryErrInvalidFormat
+	}
+	if len(dest.Prefix) > MaxKeyLength {
+		return KeyTooLong
+	}
+	if c.Schedule.Frequency != proto.InventoryFrequencyDaily && c.Schedule.Frequency != proto.InventoryFrequencyWeekly {
+		return InventoryErrInvalidFrequency
+	}
+	// there is no object version in the volumes, so the current versions are the all versions
+	if c.IncludedObjectVersions != InventoryObjectVersionsCurrent && c.IncludedObjectVersions != InventoryObjectVersionsAll {
+		return InventoryErrInvalidVersions
+	}
+	if c.Filter != nil && len(c.Filter.Prefix) > MaxKeyLength {
+		return KeyTooLong
+	}
+	if c.OptionalFields != nil {
+		fields := make(map[string]struct{}, len(c.OptionalFields.Fields))
+		for _, field := range c.OptionalFields.Fields {
+			if _, ok := inventoryOptionalFields[field]; !ok {
+				return InventoryErrInvalidField
+			}
+			if _, ok := fields[field]; ok {
+				return InventoryErrInvalidField
+			}
+			fields[field] = struct{}{}
+		}
+	}
+	return nil
+}
+
+// destinationBucket returns the name of the destination bucket in the ARN format "arn:aws:s3:::<bucket>".
+func (c *InventoryConfiguration) destinationBucket() string {
+	if c.Destination == nil || c.Destination.S3BucketDestination == nil {
+		return ""
+	}
+	arn := c.Destination.S3BucketDestination.Bucket
+	if !strings.HasPrefix(arn, InventoryBucketARNPrefix) {
+		return ""
+	}
+	return strings.TrimPrefix(arn, InventoryBucketARNPrefix)
+}
+
+func (c *InventoryConfiguration) toProto() *proto.InventoryConfiguration {
+	conf := &proto.InventoryConfiguration{
+		ID:                c.ID,
+		Enabled:           c.IsEnabled,
+		Frequency:         c.Schedule.Frequency,
+		Format:            c.Destination.S3BucketDestination.Format,
+		DestinationBucket: c.destinationBucket(),
+		DestinationPrefix: c.Destination.S3BucketDestination.Prefix,
+	}
+	if c.Filter != nil {
+		conf.Prefix = c.Filter.Prefix
+	}
+	if c.OptionalFields != nil {
+		conf.OptionalFields = c.OptionalFields.Fields
+	}
+	return conf
+}
+
+func newInventoryConfiguration(conf *proto.InventoryConfiguration) *InventoryConfiguration {
+	c := &InventoryConfiguration{
+		Destination: &InventoryDestination{
+			S3BucketDestination: &InventoryS3BucketDestination{
+				Bucket: InventoryBucketARNPrefix + conf.DestinationBucket,
+				Format: conf.Format,
+				Prefix: conf.DestinationPrefix,
+			},
+		},
+		IsEnabled:              conf.Enabled,
+		ID:                     conf.ID,
+		IncludedObjectVersions: InventoryObjectVersionsCurrent,
+		Schedule:               &InventorySchedule{Frequency: conf.Frequency},
+	}
+	if conf.Prefix != "" {
+		c.Filter = &InventoryFilter{Prefix: conf.Prefix}
+	}
+	if len(conf.OptionalFields) > 0 {
+		c.OptionalFields = &InventoryOptionalFields{Fields: conf.OptionalFields}
+	}
+	return c
+}
+
+// listInventoryConfigurations returns a page of the configurations sorted by ID, the ID of the first
+// configuration of the next page is used as the continuation token.
+func listInventoryConfigurations(confs []*proto.InventoryConfiguration, token string) (*ListInventoryConfigurationsResult, *ErrorCode) {
+	sorted := make([]*proto.InventoryConfiguration, len(confs))
+	copy(sorted, confs)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	start := 0
+	if token != "" {
+		start = sort.Search(len(sorted), func(i int) bool { return sorted[i].ID >= token })
+		if start == len(sorted) || sorted[start].ID != token {
+			return nil, InventoryErrInvalidToken
+		}
+	}
+	result := &ListInventoryConfigurationsResult{
+		Xmlns:                   XMLNS,
+		ContinuationToken:       token,
+		InventoryConfigurations: make([]*InventoryConfiguration, 0),
+	}
+	for i := start; i < len(sorted); i++ {
+		if len(result.InventoryConfigurations) >= MaxInventoryListResultCounts {
+			result.IsTruncated = true
+			result.NextContinuationToken = sorted[i].ID
+			break
+		}
+		result.InventoryConfigurations = append(result.InventoryConfigurations, newInventoryConfiguration(sorted[i]))
+	}
+	return result, nil
+}
@@ -0,0 +1,248 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketInventoryConfiguration.html
+func (o *ObjectNode) getBucketInventoryConfigurationHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		o.errorResponse(w, r, err, errorCode)
+	}()
+
+	param := ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	if _, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("getBucketInventoryConfigurationHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		return
+	}
+
+	var invConf *proto.InventoryConfigurations
+	if invConf, err = o.mc.AdminAPI().GetBucketInventory(param.Bucket()); err != nil {
+		log.LogErrorf("getBucketInventoryConfigurationHandler: get inventory fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		return
+	}
+	id := param.GetVar("id")
+	for _, conf := range invConf.Configurations {
+		if conf.ID != id {
+			continue
+		}
+		result := newInventoryConfiguration(conf)
+		result.Xmlns = XMLNS
+		var data []byte
+		if data, err = MarshalXMLEntity(result); err != nil {
+			log.LogErrorf("getBucketInventoryConfigurationHandler: xml marshal fail: requestID(%v) volume(%v) inventory(%+v) err(%v)",
+				GetRequestID(r), param.Bucket(), result, err)
+			return
+		}
+		writeSuccessResponseXML(w, data)
+		return
+	}
+	errorCode = NoSuchInventoryConfiguration
+}
+
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketInventoryConfiguration.html
+func (o *ObjectNode) putBucketInventoryConfigurationHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		o.errorResponse(w, r, err, errorCode)
+	}()
+
+	param := ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("putBucketInventoryConfigurationHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		return
+	}
+	var body []byte
+	if body, err = io.ReadAll(io.LimitReader(r.Body, MaxInventorySize+1)); err != nil {
+		log.LogErrorf("putBucketInventoryConfigurationHandler: read request body fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), vol.Name(), err)
+		return
+	}
+	if len(body) > MaxInventorySize {
+		errorCode = EntityTooLarge
+		return
+	}
+	if requestMD5 := r.Header.Get(ContentMD5); requestMD5 != "" && requestMD5 != GetMD5(body) {
+		errorCode = InvalidDigest
+		return
+	}
+	var conf *InventoryConfiguration
+	if conf, errorCode = parseInventoryConfiguration(body, param.GetVar("id")); errorCode != nil {
+		log.LogErrorf("putBucketInventoryConfigurationHandler: parse inventory config fail: requestID(%v) volume(%v) config(%v) err(%v)",
+			GetRequestID(r), vol.Name(), string(body), errorCode)
+		return
+	}
+
+	// the reports are written on behalf of the owner by lcnode, which does not support the cold volumes
+	var target *Volume
+	if target, err = o.getVol(conf.destinationBucket()); err != nil || target.Owner() != vol.Owner() || proto.IsCold(target.volType) {
+		log.LogErrorf("putBucketInventoryConfigurationHandler: invalid destination bucket: requestID(%v) volume(%v) destination(%v) err(%v)",
+			GetRequestID(r), vol.Name(), conf.destinationBucket(), err)
+		err, errorCode = nil, InventoryErrInvalidBucket
+		return
+	}
+
+	var invConf *proto.InventoryConfigurations
+	if invConf, err = o.mc.AdminAPI().GetBucketInventory(vol.Name()); err != nil {
+		log.LogErrorf("putBucketInventoryConfigurationHandler: get inventory fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), vol.Name(), err)
+		return
+	}
+	invConf.VolName = vol.Name()
+	replaced := false
+	for i, c := range invConf.Configurations {
+		if c.ID == conf.ID {
+			invConf.Configurations[i] = conf.toProto()
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		if len(invConf.Configurations) >= MaxInventoryConfigurations {
+			errorCode = InventoryErrTooManyConfigs
+			return
+		}
+		invConf.Configurations = append(invConf.Configurations, conf.toProto())
+	}
+	if err = o.mc.AdminAPI().SetBucketInventory(invConf); err != nil {
+		log.LogErrorf("putBucketInventoryConfigurationHandler: set inventory fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), vol.Name(), err)
+		return
+	}
+
+	log.LogInfof("putBucketInventoryConfigurationHandler: put inventory success: requestID(%v) volume(%v) inventory(%v)",
+		GetRequestID(r), vol.Name(), conf.ID)
+}
+
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_DeleteBucketInventoryConfiguration.html
+func (o *ObjectNode) deleteBucketInventoryConfigurationHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		o.errorResponse(w, r, err, errorCode)
+	}()
+
+	param := ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	if _, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("deleteBucketInventoryConfigurationHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		return
+	}
+
+	var invConf *proto.InventoryConfigurations
+	if invConf, err = o.mc.AdminAPI().GetBucketInventory(param.Bucket()); err != nil {
+		log.LogErrorf("deleteBucketInventoryConfigurationHandler: get inventory fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		return
+	}
+	id := param.GetVar("id")
+	confs := make([]*proto.InventoryConfiguration, 0, len(invConf.Configurations))
+	for _, c := range invConf.Configurations {
+		if c.ID != id {
+			confs = append(confs, c)
+		}
+	}
+	if len(confs) == len(invConf.Configurations) {
+		errorCode = NoSuchInventoryConfiguration
+		return
+	}
+
+	if len(confs) == 0 {
+		err = o.mc.AdminAPI().DelBucketInventory(param.Bucket())
+	} else {
+		invConf.VolName = param.Bucket()
+		invConf.Configurations = confs
+		err = o.mc.AdminAPI().SetBucketInventory(invConf)
+	}
+	if err != nil {
+		log.LogErrorf("deleteBucketInventoryConfigurationHandler: delete inventory fail: requestID(%v) volume(%v) inventory(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), id, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_ListBucketInventoryConfigurations.html
+func (o *ObjectNode) listBucketInventoryConfigurationsHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		o.errorResponse(w, r, err, errorCode)
+	}()
+
+	param := ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	if _, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("listBucketInventoryConfigurationsHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		return
+	}
+
+	var invConf *proto.InventoryConfigurations
+	if invConf, err = o.mc.AdminAPI().GetBucketInventory(param.Bucket()); err != nil {
+		log.LogErrorf("listBucketInventoryConfigurationsHandler: get inventory fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		return
+	}
+	var result *ListInventoryConfigurationsResult
+	if result, errorCode = listInventoryConfigurations(invConf.Configurations, r.URL.Query().Get(ParamContToken)); errorCode != nil {
+		return
+	}
+	var data []byte
+	if data, err = MarshalXMLEntity(result); err != nil {
+		log.LogErrorf("listBucketInventoryConfigurationsHandler: xml marshal fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		return
+	}
+
+	writeSuccessResponseXML(w, data)
+}
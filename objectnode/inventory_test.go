@@ -0,0 +1,101 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func inventoryConfigurationXML(id, bucket, format, frequency string, fields ...string) []byte {
+	optional := ""
+	for _, field := range fields {
+		optional += "<Field>" + field + "</Field>"
+	}
+	return []byte(fmt.Sprintf(`<InventoryConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/">`+
+		`<Destination><S3BucketDestination><Bucket>%s</Bucket><Format>%s</Format><Prefix>reports</Prefix></S3BucketDestination></Destination>`+
+		`<IsEnabled>true</IsEnabled><Filter><Prefix>data/</Prefix></Filter><Id>%s</Id>`+
+		`<IncludedObjectVersions>Current</IncludedObjectVersions><OptionalFields>%s</OptionalFields>`+
+		`<Schedule><Frequency>%s</Frequency></Schedule></InventoryConfiguration>`, bucket, format, id, optional, frequency))
+}
+
+func TestParseInventoryConfiguration(t *testing.T) {
+	conf, errorCode := parseInventoryConfiguration(inventoryConfigurationXML("report", "arn:aws:s3:::dst",
+		proto.InventoryFormatCSV, proto.InventoryFrequencyDaily, proto.InventoryFieldSize, proto.InventoryFieldETag), "report")
+	require.Nil(t, errorCode)
+	require.Equal(t, &proto.InventoryConfiguration{
+		ID:                "report",
+		Enabled:           true,
+		Prefix:            "data/",
+		Frequency:         proto.InventoryFrequencyDaily,
+		Format:            proto.InventoryFormatCSV,
+		DestinationBucket: "dst",
+		DestinationPrefix: "reports",
+		OptionalFields:    []string{proto.InventoryFieldSize, proto.InventoryFieldETag},
+	}, conf.toProto())
+	require.Equal(t, conf.Destination, newInventoryConfiguration(conf.toProto()).Destination)
+
+	_, errorCode = parseInventoryConfiguration(inventoryConfigurationXML("report", "arn:aws:s3:::dst",
+		proto.InventoryFormatCSV, proto.InventoryFrequencyDaily), "other")
+	require.Equal(t, InventoryErrIDMismatch, errorCode)
+
+	_, errorCode = parseInventoryConfiguration(inventoryConfigurationXML("report", "dst",
+		proto.InventoryFormatCSV, proto.InventoryFrequencyDaily), "report")
+	require.Equal(t, InventoryErrInvalidBucket, errorCode)
+
+	_, errorCode = parseInventoryConfiguration(inventoryConfigurationXML("report", "arn:aws:s3:::dst",
+		"ORC", proto.InventoryFrequencyDaily), "report")
+	require.Equal(t, InventoryErrInvalidFormat, errorCode)
+
+	_, errorCode = parseInventoryConfiguration(inventoryConfigurationXML("report", "arn:aws:s3:::dst",
+		proto.InventoryFormatParquet, "Monthly"), "report")
+	require.Equal(t, InventoryErrInvalidFrequency, errorCode)
+
+	_, errorCode = parseInventoryConfiguration(inventoryConfigurationXML("report", "arn:aws:s3:::dst",
+		proto.InventoryFormatParquet, proto.InventoryFrequencyWeekly, proto.InventoryFieldSize, proto.InventoryFieldSize), "report")
+	require.Equal(t, InventoryErrInvalidField, errorCode)
+
+	_, errorCode = parseInventoryConfiguration(inventoryConfigurationXML("report", "arn:aws:s3:::dst",
+		proto.InventoryFormatParquet, proto.InventoryFrequencyWeekly, "ReplicationStatus"), "report")
+	require.Equal(t, InventoryErrInvalidField, errorCode)
+
+	_, errorCode = parseInventoryConfiguration([]byte(`<InventoryConfiguration><Id>report</Id>`), "report")
+	require.Equal(t, MalformedXML, errorCode)
+}
+
+func TestListInventoryConfigurations(t *testing.T) {
+	confs := make([]*proto.InventoryConfiguration, 0)
+	for i := MaxInventoryListResultCounts + 9; i >= 0; i-- {
+		confs = append(confs, &proto.InventoryConfiguration{ID: fmt.Sprintf("id-%03d", i)})
+	}
+
+	result, errorCode := listInventoryConfigurations(confs, "")
+	require.Nil(t, errorCode)
+	require.True(t, result.IsTruncated)
+	require.Len(t, result.InventoryConfigurations, MaxInventoryListResultCounts)
+	require.Equal(t, "id-000", result.InventoryConfigurations[0].ID)
+	require.Equal(t, fmt.Sprintf("id-%03d", MaxInventoryListResultCounts), result.NextContinuationToken)
+
+	result, errorCode = listInventoryConfigurations(confs, result.NextContinuationToken)
+	require.Nil(t, errorCode)
+	require.False(t, result.IsTruncated)
+	require.Len(t, result.InventoryConfigurations, 10)
+
+	_, errorCode = listInventoryConfigurations(confs, "unknown")
+	require.Equal(t, InventoryErrInvalidToken, errorCode)
+}
@@ -0,0 +1,399 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	MaxLoggingSize = 1 << 12 // 4KB
+
+	defaultAccessLogFlushInterval = 5 * time.Minute
+	defaultAccessLogBatchSize     = 4 << 20 // 4MB
+
+	accessLogTimeLayout   = "02/Jan/2006:15:04:05 -0700"
+	accessLogObjectLayout = "2006-01-02-15-04-05"
+)
+
+// BucketLoggingStatus is the server access logging configuration of the bucket.
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketLogging.html
+type BucketLoggingStatus struct {
+	XMLName        xml.Name        `xml:"BucketLoggingStatus" json:"xml_name"`
+	Xmlns          string          `xml:"xmlns,attr,omitempty" json:"xmlns,omitempty"`
+	LoggingEnabled *LoggingEnabled `xml:"LoggingEnabled,omitempty" json:"logging_enabled,omitempty"`
+}
+
+type LoggingEnabled struct {
+	TargetBucket          string                 `xml:"TargetBucket" json:"target_bucket"`
+	TargetPrefix          string                 `xml:"TargetPrefix" json:"target_prefix"`
+	TargetObjectKeyFormat *TargetObjectKeyFormat `xml:"TargetObjectKeyFormat,omitempty" json:"target_object_key_format,omitempty"`
+}
+
+// TargetObjectKeyFormat specifies the key format of the log objects, the simple prefix
+// is used if the partitioned prefix is not specified.
+type TargetObjectKeyFormat struct {
+	SimplePrefix      *struct{}          `xml:"SimplePrefix,omitempty" json:"simple_prefix,omitempty"`
+	PartitionedPrefix *PartitionedPrefix `xml:"PartitionedPrefix,omitempty" json:"partitioned_prefix,omitempty"`
+}
+
+type PartitionedPrefix struct {
+	PartitionDateSource string `xml:"PartitionDateSource,omitempty" json:"partition_date_source,omitempty"`
+}
+
+func parseBucketLogging(body []byte) (*BucketLoggingStatus, *ErrorCode) {
+	status := &BucketLoggingStatus{}
+	if err := xml.Unmarshal(body, status); err != nil {
+		return nil, MalformedXML
+	}
+	if enabled := status.LoggingEnabled; enabled != nil {
+		if enabled.TargetBucket == "" {
+			return nil, InvalidTargetBucketForLogging
+		}
+		if len(enabled.TargetPrefix) > MaxKeyLength {
+			return nil, KeyTooLong
+		}
+		if format := enabled.TargetObjectKeyFormat; format != nil && format.SimplePrefix != nil && format.PartitionedPrefix != nil {
+			return nil, MalformedXML
+		}
+	}
+	return status, nil
+}
+
+// objectKey returns the key of the log object delivered at the time t.
+func (e *LoggingEnabled) objectKey(source string, t time.Time) string {
+	t = t.UTC()
+	unique := make([]byte, 8)
+	_, _ = rand.Read(unique)
+	name := t.Format(accessLogObjectLayout) + "-" + strings.ToUpper(hex.EncodeToString(unique))
+	if e.TargetObjectKeyFormat != nil && e.TargetObjectKeyFormat.PartitionedPrefix != nil {
+		// [TargetPrefix][SourceBucket]/[YYYY]/[MM]/[DD]/[YYYY]-[MM]-[DD]-[hh]-[mm]-[ss]-[UniqueString]
+		return e.TargetPrefix + source + "/" + t.Format("2006/01/02") + "/" + name
+	}
+	return e.TargetPrefix + name
+}
+
+func storeBucketLogging(bytes []byte, vol *Volume) (err error) {
+	return vol.store.Put(vol.name, bucketRootPath, XAttrKeyOSSLogging, bytes)
+}
+
+func deleteBucketLogging(vol *Volume) (err error) {
+	return vol.store.Delete(vol.name, bucketRootPath, XAttrKeyOSSLogging)
+}
+
+// accessLogBatchKey identifies the batch of records to be delivered into one log object.
+type accessLogBatchKey struct {
+	source      string
+	target      string
+	prefix      string
+	partitioned bool
+}
+
+type accessLogBatch struct {
+	enabled *LoggingEnabled
+	buf     bytes.Buffer
+}
+
+// AccessLogger batches the server access log records of the buckets with logging enabled,
+// and delivers the batches periodically as objects into the configured target buckets.
+type AccessLogger struct {
+	getVol    func(bucket string) (*Volume, error)
+	interval  time.Duration
+	batchSize int
+
+	mu      sync.Mutex
+	batches map[accessLogBatchKey]*accessLogBatch
+
+	stopC chan struct{}
+	wg    sync.WaitGroup
+}
+
+func NewAccessLogger(getVol func(bucket string) (*Volume, error), interval time.Duration, batchSize int) *AccessLogger {
+	if interval <= 0 {
+		interval = defaultAccessLogFlushInterval
+	}
+	if batchSize <= 0 {
+		batchSize = defaultAccessLogBatchSize
+	}
+	return &AccessLogger{
+		getVol:    getVol,
+		interval:  interval,
+		batchSize: batchSize,
+		batches:   make(map[accessLogBatchKey]*accessLogBatch),
+		stopC:     make(chan struct{}),
+	}
+}
+
+func (l *AccessLogger) Start() {
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		ticker := time.NewTicker(l.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				l.Flush()
+			case <-l.stopC:
+				return
+			}
+		}
+	}()
+}
+
+// Close stops the periodic delivery and delivers the remaining records.
+func (l *AccessLogger) Close() {
+	close(l.stopC)
+	l.wg.Wait()
+	l.Flush()
+}
+
+// Log records the request if the logging of its bucket is enabled.
+func (l *AccessLogger) Log(w http.ResponseWriter, r *http.Request) {
+	param := ParseRequestParam(r)
+	if param.Bucket() == "" {
+		return
+	}
+	vol, err := l.getVol(param.Bucket())
+	if err != nil {
+		return
+	}
+	status, err := vol.metaLoader.loadLogging()
+	if err != nil {
+		log.LogWarnf("AccessLogger: load logging fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), vol.Name(), err)
+		return
+	}
+	if status == nil || status.LoggingEnabled == nil {
+		return
+	}
+	l.append(vol.Name(), status.LoggingEnabled, formatAccessLog(vol.Owner(), w, r))
+}
+
+func (l *AccessLogger) append(source string, enabled *LoggingEnabled, record []byte) {
+	key := accessLogBatchKey{source: source, target: enabled.TargetBucket, prefix: enabled.TargetPrefix}
+	key.partitioned = enabled.TargetObjectKeyFormat != nil && enabled.TargetObjectKeyFormat.PartitionedPrefix != nil
+
+	l.mu.Lock()
+	batch, ok := l.batches[key]
+	if !ok {
+		batch = &accessLogBatch{enabled: enabled}
+		l.batches[key] = batch
+	}
+	batch.buf.Write(record)
+	full := batch.buf.Len() >= l.batchSize
+	if full {
+		delete(l.batches, key)
+	}
+	l.mu.Unlock()
+
+	if full {
+		l.wg.Add(1)
+		go func() {
+			defer l.wg.Done()
+			l.deliver(source, batch)
+		}()
+	}
+}
+
+// Flush delivers all the batched records.
+func (l *AccessLogger) Flush() {
+	l.mu.Lock()
+	batches := l.batches
+	l.batches = make(map[accessLogBatchKey]*accessLogBatch)
+	l.mu.Unlock()
+
+	for key, batch := range batches {
+		l.deliver(key.source, batch)
+	}
+}
+
+// deliver writes the batch as an object into the target bucket, the records are dropped
+// if the delivery fails, as the server access logging is best-effort.
+func (l *AccessLogger) deliver(source string, batch *accessLogBatch) {
+	target, err := l.getVol(batch.enabled.TargetBucket)
+	if err != nil {
+		log.LogWarnf("AccessLogger: load target volume fail: source(%v) target(%v) err(%v)",
+			source, batch.enabled.TargetBucket, err)
+		return
+	}
+	key := batch.enabled.objectKey(source, time.Now())
+	opt := &PutFileOption{MIMEType: ValueContentTypeText}
+	if _, err = target.PutObject(key, bytes.NewReader(batch.buf.Bytes()), opt); err != nil {
+		log.LogWarnf("AccessLogger: put log object fail: source(%v) target(%v) key(%v) size(%v) err(%v)",
+			source, target.Name(), key, batch.buf.Len(), err)
+		return
+	}
+	log.LogDebugf("AccessLogger: deliver log object: source(%v) target(%v) key(%v) size(%v)",
+		source, target.Name(), key, batch.buf.Len())
+}
+
+// formatAccessLog formats the request as a record of the server access log.
+// Reference: https://docs.aws.amazon.com/AmazonS3/latest/userguide/LogFormat.html
+func formatAccessLog(owner string, w http.ResponseWriter, r *http.Request) []byte {
+	param := ParseRequestParam(r)
+	statusCode := http.StatusOK
+	startTime := time.Now()
+	var bytesSent int64
+	if rs, ok := w.(*ResponseStater); ok {
+		statusCode = rs.StatusCode
+		bytesSent = rs.Written
+		startTime = rs.StartTime
+	}
+	var key string
+	if param.Object() != "" {
+		key = (&url.URL{Path: param.Object()}).EscapedPath()
+	}
+	var objectSize string
+	if r.Method == http.MethodPut && r.ContentLength > 0 {
+		objectSize = strconv.FormatInt(r.ContentLength, 10)
+	} else if size := w.Header().Get(ContentLength); size != "" && statusCode < http.StatusBadRequest {
+		objectSize = size
+	}
+	var bytesSentStr string
+	if bytesSent > 0 {
+		bytesSentStr = strconv.FormatInt(bytesSent, 10)
+	}
+	sigVersion, authType := accessLogAuthInfo(r)
+	var cipherSuite, tlsVersion string
+	if r.TLS != nil {
+		cipherSuite = tls.CipherSuiteName(r.TLS.CipherSuite)
+		tlsVersion = accessLogTLSVersion(r.TLS.Version)
+	}
+
+	fields := []string{
+		accessLogField(owner),
+		accessLogField(param.Bucket()),
+		"[" + startTime.Format(accessLogTimeLayout) + "]",
+		accessLogField(getRequestIP(r)),
+		accessLogField(param.Requester()),
+		accessLogField(param.RequestID()),
+		accessLogOperation(r, param.Object()),
+		accessLogField(key),
+		accessLogQuoted(r.Method + " " + r.URL.RequestURI() + " " + r.Proto),
+		strconv.Itoa(statusCode),
+		accessLogField(getResponseErrorCode(r)),
+		accessLogField(bytesSentStr),
+		accessLogField(objectSize),
+		strconv.FormatInt(time.Since(startTime).Milliseconds(), 10),
+		"-", // turn-around time
+		accessLogQuoted(r.Referer()),
+		accessLogQuoted(r.UserAgent()),
+		"-", // version id
+		"-", // host id
+		accessLogField(sigVersion),
+		accessLogField(cipherSuite),
+		accessLogField(authType),
+		accessLogField(r.Host),
+		accessLogField(tlsVersion),
+		"-", // access point arn
+		"-", // acl required
+	}
+	return []byte(strings.Join(fields, " ") + "\n")
+}
+
+func accessLogField(v string) string {
+	if v == "" {
+		return "-"
+	}
+	return strings.ReplaceAll(v, " ", "%20")
+}
+
+func accessLogQuoted(v string) string {
+	if v == "" {
+		return "\"-\""
+	}
+	return "\"" + strings.ReplaceAll(v, "\"", "\\\"") + "\""
+}
+
+// accessLogSubResources are the sub-resources used as the resource type of the operation.
+var accessLogSubResources = []string{
+	"acl", "attributes", "cors", "delete", "legal-hold", "lifecycle", "location", "logging",
+	"object-lock", "policy", "restore", "retention", "tagging", "uploads", "versioning", "website",
+}
+
+// accessLogOperation returns the operation of the request in the form of
+// "REST.HTTP_method.resource_type", such as "REST.GET.OBJECT".
+func accessLogOperation(r *http.Request, object string) string {
+	method := r.Method
+	query := r.URL.Query()
+	resource := "BUCKET"
+	if object != "" {
+		resource = "OBJECT"
+	}
+	if _, ok := query["uploadId"]; ok {
+		resource = "UPLOAD"
+		if method == http.MethodPut {
+			resource = "PART"
+		}
+	} else {
+		for _, sub := range accessLogSubResources {
+			if _, ok := query[sub]; ok {
+				resource = strings.ToUpper(strings.ReplaceAll(sub, "-", "_"))
+				break
+			}
+		}
+	}
+	if method == http.MethodPut && r.Header.Get(XAmzCopySource) != "" {
+		method = "COPY"
+	}
+	return "REST." + method + "." + resource
+}
+
+func accessLogAuthInfo(r *http.Request) (sigVersion, authType string) {
+	if auth := r.Header.Get(Authorization); auth != "" {
+		authType = "AuthHeader"
+		if strings.HasPrefix(auth, signV4Algorithm) {
+			sigVersion = "SigV4"
+		} else {
+			sigVersion = "SigV2"
+		}
+		return
+	}
+	query := r.URL.Query()
+	if query.Get(XAmzAlgorithm) != "" {
+		return "SigV4", "QueryString"
+	}
+	if query.Get(Signature) != "" {
+		return "SigV2", "QueryString"
+	}
+	return
+}
+
+func accessLogTLSVersion(version uint16) string {
+	switch version {
+	case tls.VersionTLS10:
+		return "TLSv1"
+	case tls.VersionTLS11:
+		return "TLSv1.1"
+	case tls.VersionTLS12:
+		return "TLSv1.2"
+	case tls.VersionTLS13:
+		return "TLSv1.3"
+	}
+	return ""
+}
@@ -0,0 +1,141 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketLogging.html
+func (o *ObjectNode) getBucketLoggingHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		o.errorResponse(w, r, err, errorCode)
+	}()
+
+	param := ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("getBucketLoggingHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		return
+	}
+
+	var logging *BucketLoggingStatus
+	if logging, err = vol.metaLoader.loadLogging(); err != nil {
+		log.LogErrorf("getBucketLoggingHandler: load logging fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), vol.Name(), err)
+		return
+	}
+	// an empty status is returned if the logging is not enabled
+	result := &BucketLoggingStatus{Xmlns: XMLNS}
+	if logging != nil {
+		result.LoggingEnabled = logging.LoggingEnabled
+	}
+	var data []byte
+	if data, err = MarshalXMLEntity(result); err != nil {
+		log.LogErrorf("getBucketLoggingHandler: xml marshal fail: requestID(%v) volume(%v) logging(%+v) err(%v)",
+			GetRequestID(r), vol.Name(), result, err)
+		return
+	}
+
+	writeSuccessResponseXML(w, data)
+}
+
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketLogging.html
+func (o *ObjectNode) putBucketLoggingHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		o.errorResponse(w, r, err, errorCode)
+	}()
+
+	param := ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("putBucketLoggingHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		return
+	}
+	var body []byte
+	if body, err = io.ReadAll(io.LimitReader(r.Body, MaxLoggingSize+1)); err != nil {
+		log.LogErrorf("putBucketLoggingHandler: read request body fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), vol.Name(), err)
+		return
+	}
+	if len(body) > MaxLoggingSize {
+		errorCode = EntityTooLarge
+		return
+	}
+	if requestMD5 := r.Header.Get(ContentMD5); requestMD5 != "" && requestMD5 != GetMD5(body) {
+		errorCode = InvalidDigest
+		return
+	}
+	var logging *BucketLoggingStatus
+	if logging, errorCode = parseBucketLogging(body); errorCode != nil {
+		log.LogErrorf("putBucketLoggingHandler: parse logging config fail: requestID(%v) volume(%v) config(%v) err(%v)",
+			GetRequestID(r), vol.Name(), string(body), errorCode)
+		return
+	}
+
+	// an empty status disables the logging
+	if logging.LoggingEnabled == nil {
+		if err = deleteBucketLogging(vol); err != nil {
+			log.LogErrorf("putBucketLoggingHandler: delete logging config fail: requestID(%v) volume(%v) err(%v)",
+				GetRequestID(r), vol.Name(), err)
+			return
+		}
+		vol.metaLoader.storeLogging(nil)
+		return
+	}
+
+	// the log objects are delivered on behalf of the owner, so the target bucket must be owned by the same owner
+	var target *Volume
+	if target, err = o.getVol(logging.LoggingEnabled.TargetBucket); err != nil || target.Owner() != vol.Owner() {
+		log.LogErrorf("putBucketLoggingHandler: invalid target bucket: requestID(%v) volume(%v) target(%v) err(%v)",
+			GetRequestID(r), vol.Name(), logging.LoggingEnabled.TargetBucket, err)
+		err, errorCode = nil, InvalidTargetBucketForLogging
+		return
+	}
+	if body, err = xml.Marshal(logging); err != nil {
+		log.LogErrorf("putBucketLoggingHandler: xml marshal fail: requestID(%v) volume(%v) logging(%+v) err(%v)",
+			GetRequestID(r), vol.Name(), logging, err)
+		return
+	}
+	if err = storeBucketLogging(body, vol); err != nil {
+		log.LogErrorf("putBucketLoggingHandler: store logging config fail: requestID(%v) volume(%v) config(%v) err(%v)",
+			GetRequestID(r), vol.Name(), string(body), err)
+		return
+	}
+	vol.metaLoader.storeLogging(logging)
+}
@@ -0,0 +1,142 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBucketLogging(t *testing.T) {
+	status, errorCode := parseBucketLogging([]byte(`<BucketLoggingStatus xmlns="http://s3.amazonaws.com/doc/2006-03-01/"/>`))
+	require.Nil(t, errorCode)
+	require.Nil(t, status.LoggingEnabled)
+
+	status, errorCode = parseBucketLogging([]byte(`<BucketLoggingStatus><LoggingEnabled>` +
+		`<TargetBucket>logs</TargetBucket><TargetPrefix>access/</TargetPrefix>` +
+		`<TargetObjectKeyFormat><PartitionedPrefix><PartitionDateSource>EventTime</PartitionDateSource></PartitionedPrefix></TargetObjectKeyFormat>` +
+		`</LoggingEnabled></BucketLoggingStatus>`))
+	require.Nil(t, errorCode)
+	require.Equal(t, "logs", status.LoggingEnabled.TargetBucket)
+	require.Equal(t, "access/", status.LoggingEnabled.TargetPrefix)
+	require.NotNil(t, status.LoggingEnabled.TargetObjectKeyFormat.PartitionedPrefix)
+
+	_, errorCode = parseBucketLogging([]byte(`<BucketLoggingStatus><LoggingEnabled><TargetPrefix>access/</TargetPrefix>` +
+		`</LoggingEnabled></BucketLoggingStatus>`))
+	require.Equal(t, InvalidTargetBucketForLogging, errorCode)
+
+	_, errorCode = parseBucketLogging([]byte(`<BucketLoggingStatus><LoggingEnabled>`))
+	require.Equal(t, MalformedXML, errorCode)
+}
+
+func TestLoggingObjectKey(t *testing.T) {
+	now := time.Date(2023, 8, 1, 10, 20, 30, 0, time.UTC)
+	enabled := &LoggingEnabled{TargetBucket: "logs", TargetPrefix: "access/"}
+	key := enabled.objectKey("source", now)
+	require.Regexp(t, regexp.MustCompile(`^access/2023-08-01-10-20-30-[0-9A-F]{16}$`), key)
+	require.NotEqual(t, key, enabled.objectKey("source", now))
+
+	enabled.TargetObjectKeyFormat = &TargetObjectKeyFormat{PartitionedPrefix: &PartitionedPrefix{}}
+	key = enabled.objectKey("source", now)
+	require.Regexp(t, regexp.MustCompile(`^access/source/2023/08/01/2023-08-01-10-20-30-[0-9A-F]{16}$`), key)
+}
+
+func TestFormatAccessLog(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPut, "/source/dir/a%20b.txt?uploadId=1&partNumber=2", strings.NewReader("hello"))
+	req.Header.Set(Authorization, "AWS4-HMAC-SHA256 Credential=ak/20230801/us-east-1/s3/aws4_request")
+	req.Header.Set("User-Agent", "aws-sdk-go")
+	var record string
+	router := mux.NewRouter()
+	router.NewRoute().Path("/{bucket}/{object:.+}").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.Vars(r)[ContextKeyRequester] = "user"
+		SetRequestID(r, "reqid")
+		rs := NewResponseStater(w)
+		NoSuchUpload.ServeResponse(rs, r)
+		record = string(formatAccessLog("owner", rs, r))
+	})
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.True(t, strings.HasSuffix(record, "\n"))
+	fields := strings.Split(strings.TrimSuffix(record, "\n"), " ")
+	require.Equal(t, "owner", fields[0])
+	require.Equal(t, "source", fields[1])
+	require.Equal(t, "user", fields[5])
+	require.Equal(t, "reqid", fields[6])
+	require.Equal(t, "REST.PUT.PART", fields[7])
+	require.Equal(t, "dir/a%20b.txt", fields[8])
+	require.Equal(t, "404", fields[12])
+	require.Equal(t, "NoSuchUpload", fields[13])
+	require.Equal(t, "5", fields[15])
+	require.Contains(t, record, `"PUT /source/dir/a%20b.txt?uploadId=1&partNumber=2 HTTP/1.1"`)
+	require.Contains(t, record, `"-" "aws-sdk-go"`)
+	require.Contains(t, record, "SigV4 - AuthHeader example.com")
+}
+
+func TestAccessLogOperation(t *testing.T) {
+	cases := []struct {
+		method string
+		target string
+		object string
+		copy   bool
+		op     string
+	}{
+		{http.MethodGet, "/bucket", "", false, "REST.GET.BUCKET"},
+		{http.MethodGet, "/bucket/key", "key", false, "REST.GET.OBJECT"},
+		{http.MethodPut, "/bucket/key", "key", true, "REST.COPY.OBJECT"},
+		{http.MethodPut, "/bucket/key?uploadId=1&partNumber=1", "key", true, "REST.COPY.PART"},
+		{http.MethodPost, "/bucket/key?uploadId=1", "key", false, "REST.POST.UPLOAD"},
+		{http.MethodPost, "/bucket/key?uploads", "key", false, "REST.POST.UPLOADS"},
+		{http.MethodGet, "/bucket?object-lock", "", false, "REST.GET.OBJECT_LOCK"},
+		{http.MethodPut, "/bucket/key?tagging", "key", false, "REST.PUT.TAGGING"},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(c.method, c.target, nil)
+		if c.copy {
+			req.Header.Set(XAmzCopySource, "/bucket/src")
+		}
+		require.Equal(t, c.op, accessLogOperation(req, c.object), c.target)
+	}
+}
+
+func TestAccessLoggerBatch(t *testing.T) {
+	var delivered []string
+	logger := NewAccessLogger(func(bucket string) (*Volume, error) {
+		delivered = append(delivered, bucket)
+		return nil, errors.New("volume not exist")
+	}, time.Hour, 16)
+
+	enabled := &LoggingEnabled{TargetBucket: "logs", TargetPrefix: "a/"}
+	logger.append("source", enabled, []byte("record\n"))
+	logger.append("source", &LoggingEnabled{TargetBucket: "logs", TargetPrefix: "b/"}, []byte("record\n"))
+	require.Len(t, logger.batches, 2)
+
+	// the full batch is delivered immediately
+	logger.append("source", enabled, []byte("record-record\n"))
+	logger.wg.Wait()
+	require.Len(t, logger.batches, 1)
+	require.Equal(t, []string{"logs"}, delivered)
+
+	logger.Flush()
+	require.Len(t, logger.batches, 0)
+	require.Equal(t, []string{"logs", "logs"}, delivered)
+}
@@ -17,9 +17,13 @@ package objectnode
 import (
 	"encoding/xml"
 	"errors"
+	"net/http"
 	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
+	"github.com/cubefs/cubefs/proto"
 	"github.com/cubefs/cubefs/util/log"
 )
 
@@ -32,11 +36,16 @@ var (
 	InvalidObjectLockEnabledErr    = errors.New("Only Enabled value is allowd to ObjectLockEnabled element")
 	NilDefaultRetentionErr         = errors.New("Default retention cannot be nil")
 	NilDefaultRuleErr              = errors.New("Default rule cannot be nil")
+	InvalidLegalHoldStatusErr      = errors.New("Only ON or OFF value is allowed to Status element")
+	PastRetainUntilDateErr         = errors.New("The retain until date must be in the future")
 )
 
 const (
 	ComplianceMode = "COMPLIANCE"
+	GovernanceMode = "GOVERNANCE"
 	Enabled        = "Enabled"
+	LegalHoldOn    = "ON"
+	LegalHoldOff   = "OFF"
 
 	MaxObjectLockSize     = 1 << 12 // 16KB
 	maximumRetentionDays  = 70 * 365
@@ -125,7 +134,7 @@ func ParseObjectLockConfigFromXML(data []byte) (*ObjectLockConfig, error) {
 // check valid of DefaultRetention
 func (d DefaultRetention) isValid() error {
 	switch d.Mode {
-	case ComplianceMode:
+	case ComplianceMode, GovernanceMode:
 	default:
 		return InvalidModeErr
 	}
@@ -179,26 +188,160 @@ func storeObjectLock(bytes []byte, vol *Volume) (err error) {
 	return vol.store.Put(vol.name, bucketRootPath, XAttrKeyOSSLock, bytes)
 }
 
-func isObjectLocked(v *Volume, inode uint64, name, path string) error {
-	xattrInfo, err := v.mw.XAttrGet_ll(inode, XAttrKeyOSSLock)
+func isObjectLockXAttr(key string) bool {
+	return key == XAttrKeyOSSLock || key == XAttrKeyOSSLockMode || key == XAttrKeyOSSLegalHold
+}
+
+// checkObjectLock returns AccessDenied if the object cannot be deleted or overwritten,
+// the GOVERNANCE mode retention is skipped if bypassGovernance is set.
+func checkObjectLock(xattr *proto.XAttrInfo, bypassGovernance bool) error {
+	if err := proto.CheckObjectLockXAttr(xattr, bypassGovernance); err != nil {
+		if err == syscall.EPERM {
+			return AccessDenied
+		}
+		return err
+	}
+	return nil
+}
+
+func isObjectLocked(v *Volume, inode uint64, name, path string, bypassGovernance bool) error {
+	xattrInfo, err := v.mw.XAttrGetAll_ll(inode)
 	if err != nil {
 		log.LogErrorf("isObjectLocked: check ObjectLock err(%v) volume(%v) path(%v) name(%v)",
 			err, v.name, path, name)
 		return err
 	}
-	retainUntilDate := xattrInfo.Get(XAttrKeyOSSLock)
-	if len(retainUntilDate) > 0 {
-		retainUntilDateInt64, err := strconv.ParseInt(string(retainUntilDate), 10, 64)
-		if err != nil {
-			return err
+	if err = checkObjectLock(xattrInfo, bypassGovernance); err != nil {
+		log.LogWarnf("isObjectLocked: object is locked, retainUntilDate(%v) mode(%v) legalHold(%v) volume(%v) path(%v) name(%v)",
+			string(xattrInfo.Get(XAttrKeyOSSLock)), string(xattrInfo.Get(XAttrKeyOSSLockMode)),
+			string(xattrInfo.Get(XAttrKeyOSSLegalHold)), v.name, path, name)
+		return err
+	}
+	return nil
+}
+
+// checkObjectLockEnabled returns an error if the object lock is not enabled on the bucket,
+// the retention and legal hold can be placed only on the objects of such buckets.
+func checkObjectLockEnabled(vol *Volume) *ErrorCode {
+	config, err := vol.metaLoader.loadObjectLock()
+	if err != nil {
+		log.LogErrorf("checkObjectLockEnabled: load object lock fail: volume(%v) err(%v)", vol.Name(), err)
+		return InternalErrorCode(err)
+	}
+	if config == nil || config.ObjectLockEnabled != Enabled {
+		return ObjectLockConfigurationNotFound
+	}
+	return nil
+}
+
+func isBypassGovernanceRequest(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get(XAmzBypassGovernanceRetention), "true")
+}
+
+// objectRetentionMode returns the retention mode of the object, the objects locked
+// before the GOVERNANCE mode is supported are in COMPLIANCE mode.
+func objectRetentionMode(xattr *proto.XAttrInfo) string {
+	if mode := string(xattr.Get(XAttrKeyOSSLockMode)); mode != "" {
+		return mode
+	}
+	return ComplianceMode
+}
+
+// parse ObjectRetention from xml
+func ParseObjectRetentionFromXML(data []byte) (*ObjectRetention, error) {
+	retention := ObjectRetention{}
+	if err := xml.Unmarshal(data, &retention); err != nil {
+		return nil, MalformedXML
+	}
+	if retention.Mode == "" && retention.RetainUntilDate.IsZero() {
+		return &retention, nil
+	}
+	if retention.Mode != ComplianceMode && retention.Mode != GovernanceMode {
+		return nil, NewError("InvalidRequest", InvalidModeErr.Error(), 400)
+	}
+	if retention.RetainUntilDate.IsZero() {
+		return nil, NewError("InvalidRequest", "RetainUntilDate must be specified with Mode", 400)
+	}
+	if !retention.RetainUntilDate.After(time.Now()) {
+		return nil, NewError("InvalidRequest", PastRetainUntilDateErr.Error(), 400)
+	}
+	return &retention, nil
+}
+
+// checkRetentionUpdate checks whether the retention of the object can be replaced by the new one.
+// The COMPLIANCE mode retention cannot be shortened, removed or changed to GOVERNANCE,
+// while the GOVERNANCE mode retention can be if bypassGovernance is set.
+func checkRetentionUpdate(xattr *proto.XAttrInfo, retention *ObjectRetention, bypassGovernance bool) *ErrorCode {
+	raw := string(xattr.Get(XAttrKeyOSSLock))
+	if raw == "" {
+		return nil
+	}
+	retainUntilDate, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || retainUntilDate <= time.Now().UnixNano() {
+		return nil
+	}
+	mode := objectRetentionMode(xattr)
+	if mode == GovernanceMode && bypassGovernance {
+		return nil
+	}
+	// extending the retention period, or changing GOVERNANCE to COMPLIANCE, is always allowed
+	if retention.Mode != "" && retention.RetainUntilDate.UnixNano() >= retainUntilDate &&
+		(retention.Mode == mode || retention.Mode == ComplianceMode) {
+		return nil
+	}
+	return AccessDenied
+}
+
+// storeObjectRetention replaces the retention of the object, the meta node refuses to shorten or
+// remove the retention in force unless it is in GOVERNANCE mode and bypassGovernance is set.
+func storeObjectRetention(vol *Volume, path string, retention *ObjectRetention, bypassGovernance bool) (err error) {
+	inode, err := vol.getInodeFromPath(path)
+	if err != nil {
+		return
+	}
+	setXAttr, delXAttr := vol.mw.XAttrSet_ll, vol.mw.XAttrDel_ll
+	if bypassGovernance {
+		setXAttr, delXAttr = vol.mw.XAttrSetBypassGovernance_ll, vol.mw.XAttrDelBypassGovernance_ll
+	}
+	if retention.Mode == "" {
+		for _, key := range []string{XAttrKeyOSSLock, XAttrKeyOSSLockMode} {
+			if err = delXAttr(inode, key); err != nil {
+				return
+			}
+			if objMetaCache != nil {
+				objMetaCache.DeleteAttrWithKey(vol.name, inode, key)
+			}
 		}
-		if retainUntilDateInt64 > time.Now().UnixNano() {
-			log.LogWarnf("isObjectLocked: object is locked, retainUntilDate(%v) volume(%v) path(%v) name(%v)",
-				retainUntilDateInt64, v.name, path, name)
-			return AccessDenied
+		return
+	}
+	for _, attr := range [][2]string{
+		{XAttrKeyOSSLock, strconv.FormatInt(retention.RetainUntilDate.UnixNano(), 10)},
+		{XAttrKeyOSSLockMode, retention.Mode},
+	} {
+		if err = setXAttr(inode, []byte(attr[0]), []byte(attr[1])); err != nil {
+			return
 		}
+		updateAttrCache(inode, attr[0], attr[1], vol.name)
 	}
-	return nil
+	return
+}
+
+type ObjectLegalHold struct {
+	XMLNS   string   `xml:"xmlns,attr,omitempty"`
+	XMLName xml.Name `xml:"LegalHold"`
+	Status  string   `xml:"Status"`
+}
+
+// parse ObjectLegalHold from xml
+func ParseObjectLegalHoldFromXML(data []byte) (*ObjectLegalHold, error) {
+	legalHold := ObjectLegalHold{}
+	if err := xml.Unmarshal(data, &legalHold); err != nil {
+		return nil, MalformedXML
+	}
+	if legalHold.Status != LegalHoldOn && legalHold.Status != LegalHoldOff {
+		return nil, NewError("InvalidRequest", InvalidLegalHoldStatusErr.Error(), 400)
+	}
+	return &legalHold, nil
 }
 
 func formatRetentionDateStr(modifyTime time.Time, retention *Retention) string {
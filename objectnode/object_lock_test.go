@@ -21,6 +21,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/cubefs/cubefs/proto"
 )
 
 func TestParseObjectLockConfig(t *testing.T) {
@@ -149,6 +151,18 @@ func TestParseObjectLockConfig(t *testing.T) {
 					</ObjectLockConfiguration>`,
 			expectedErr: nil,
 		},
+		{
+			value: `<ObjectLockConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+						<ObjectLockEnabled>Enabled</ObjectLockEnabled>
+						<Rule>
+							<DefaultRetention>
+								<Mode>GOVERNANCE</Mode>
+								<Days>30</Days>
+							</DefaultRetention>
+						</Rule>
+					</ObjectLockConfiguration>`,
+			expectedErr: nil,
+		},
 	}
 	for _, tt := range tests {
 		_, err := ParseObjectLockConfigFromXML([]byte(tt.value))
@@ -217,3 +231,86 @@ func TestMarshalXML(t *testing.T) {
 	_, err := xml.Marshal(objectRetention)
 	require.NoError(t, err)
 }
+
+func TestParseObjectRetention(t *testing.T) {
+	future := time.Now().Add(time.Hour).UTC().Format(ISO8601Layout)
+	past := time.Now().Add(-time.Hour).UTC().Format(ISO8601Layout)
+
+	retention, err := ParseObjectRetentionFromXML([]byte(`<Retention><Mode>GOVERNANCE</Mode><RetainUntilDate>` +
+		future + `</RetainUntilDate></Retention>`))
+	require.NoError(t, err)
+	require.Equal(t, GovernanceMode, retention.Mode)
+	require.True(t, retention.RetainUntilDate.After(time.Now()))
+
+	// remove retention
+	retention, err = ParseObjectRetentionFromXML([]byte(`<Retention></Retention>`))
+	require.NoError(t, err)
+	require.Equal(t, "", retention.Mode)
+
+	for _, body := range []string{
+		`<Retention>`,
+		`<Retention><Mode>governance</Mode><RetainUntilDate>` + future + `</RetainUntilDate></Retention>`,
+		`<Retention><Mode>COMPLIANCE</Mode></Retention>`,
+		`<Retention><Mode>COMPLIANCE</Mode><RetainUntilDate>` + past + `</RetainUntilDate></Retention>`,
+	} {
+		_, err = ParseObjectRetentionFromXML([]byte(body))
+		require.Error(t, err, body)
+	}
+}
+
+func TestParseObjectLegalHold(t *testing.T) {
+	legalHold, err := ParseObjectLegalHoldFromXML([]byte(`<LegalHold><Status>ON</Status></LegalHold>`))
+	require.NoError(t, err)
+	require.Equal(t, LegalHoldOn, legalHold.Status)
+
+	_, err = ParseObjectLegalHoldFromXML([]byte(`<LegalHold><Status>on</Status></LegalHold>`))
+	require.Error(t, err)
+}
+
+func TestCheckObjectLock(t *testing.T) {
+	lockXAttr := func(mode string, retainUntil time.Time, legalHold string) *proto.XAttrInfo {
+		xattr := &proto.XAttrInfo{XAttrs: map[string]string{}}
+		if !retainUntil.IsZero() {
+			xattr.XAttrs[XAttrKeyOSSLock] = strconv.FormatInt(retainUntil.UnixNano(), 10)
+		}
+		if mode != "" {
+			xattr.XAttrs[XAttrKeyOSSLockMode] = mode
+		}
+		if legalHold != "" {
+			xattr.XAttrs[XAttrKeyOSSLegalHold] = legalHold
+		}
+		return xattr
+	}
+	future, past := time.Now().Add(time.Hour), time.Now().Add(-time.Hour)
+
+	require.NoError(t, checkObjectLock(lockXAttr("", time.Time{}, ""), false))
+	require.NoError(t, checkObjectLock(lockXAttr(ComplianceMode, past, LegalHoldOff), false))
+	// retention without mode is in COMPLIANCE mode
+	require.Equal(t, AccessDenied, checkObjectLock(lockXAttr("", future, ""), true))
+	require.Equal(t, AccessDenied, checkObjectLock(lockXAttr(ComplianceMode, future, ""), true))
+	require.Equal(t, AccessDenied, checkObjectLock(lockXAttr(GovernanceMode, future, ""), false))
+	require.NoError(t, checkObjectLock(lockXAttr(GovernanceMode, future, ""), true))
+	// legal hold cannot be bypassed
+	require.Equal(t, AccessDenied, checkObjectLock(lockXAttr(GovernanceMode, past, LegalHoldOn), true))
+	require.Equal(t, AccessDenied, checkObjectLock(lockXAttr("", time.Time{}, LegalHoldOn), true))
+
+	// update retention
+	later := &ObjectRetention{Mode: GovernanceMode, RetainUntilDate: RetentionDate{future.Add(time.Hour)}}
+	earlier := &ObjectRetention{Mode: GovernanceMode, RetainUntilDate: RetentionDate{future.Add(-time.Minute)}}
+	compliance := &ObjectRetention{Mode: ComplianceMode, RetainUntilDate: RetentionDate{future.Add(time.Hour)}}
+	removal := &ObjectRetention{}
+	governanceXAttr := lockXAttr(GovernanceMode, future, "")
+	require.Nil(t, checkRetentionUpdate(governanceXAttr, later, false))
+	require.Nil(t, checkRetentionUpdate(governanceXAttr, compliance, false))
+	require.Equal(t, AccessDenied, checkRetentionUpdate(governanceXAttr, earlier, false))
+	require.Equal(t, AccessDenied, checkRetentionUpdate(governanceXAttr, removal, false))
+	require.Nil(t, checkRetentionUpdate(governanceXAttr, earlier, true))
+	require.Nil(t, checkRetentionUpdate(governanceXAttr, removal, true))
+
+	complianceXAttr := lockXAttr(ComplianceMode, future, "")
+	require.Nil(t, checkRetentionUpdate(complianceXAttr, compliance, false))
+	require.Equal(t, AccessDenied, checkRetentionUpdate(complianceXAttr, later, true))
+	require.Equal(t, AccessDenied, checkRetentionUpdate(complianceXAttr, removal, true))
+	// expired retention can be replaced
+	require.Nil(t, checkRetentionUpdate(lockXAttr(ComplianceMode, past, ""), removal, false))
+}
@@ -61,6 +61,28 @@ func (p *Policy) IsEmpty() bool {
 	return len(p.Statements) == 0
 }
 
+// IsPublic reports whether the policy allows the access of everyone, the statements restricted
+// to the source ip addresses are not public.
+// https://docs.aws.amazon.com/AmazonS3/latest/userguide/access-control-block-public-access.html#access-control-block-public-access-policy-status
+func (p *Policy) IsPublic() bool {
+	for _, s := range p.Statements {
+		if s.isPublic() {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) withoutPublicStatements() *Policy {
+	filtered := &Policy{Version: p.Version, Id: p.Id}
+	for _, s := range p.Statements {
+		if !s.isPublic() {
+			filtered.Statements = append(filtered.Statements, s)
+		}
+	}
+	return filtered
+}
+
 func ParsePolicy(data []byte) (*Policy, error) {
 	policy := new(Policy)
 	dec := json.NewDecoder(bytes.NewReader(data))
@@ -280,7 +302,7 @@ func (o *ObjectNode) policyCheck(f http.HandlerFunc) http.HandlerFunc {
 						GetRequestID(r), param.Bucket(), param.Action())
 					return
 				}
-				if acl, err = getObjectACL(vol, param.object, true); err != nil && err != syscall.ENOENT {
+				if acl, err = o.loadObjectACL(vol, param.object); err != nil && err != syscall.ENOENT {
 					log.LogErrorf("acl check: get object acl fail: requestID(%v) volume(%v) action(%v) err(%v)",
 						GetRequestID(r), param.Bucket(), param.Action(), err)
 					return
@@ -312,6 +334,8 @@ func (o *ObjectNode) policyCheck(f http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// loadBucketMeta loads the acl and policy of the bucket in effect, the public grants of the acl and the public
+// statements of the policy are left out if they are ignored by the public access block configuration.
 func (o *ObjectNode) loadBucketMeta(bucket string) (vol *Volume, acl *AccessControlPolicy, policy *Policy, err error) {
 	if vol, err = o.getVol(bucket); err != nil {
 		return
@@ -322,9 +346,28 @@ func (o *ObjectNode) loadBucketMeta(bucket string) (vol *Volume, acl *AccessCont
 	if policy, err = vol.metaLoader.loadPolicy(); err != nil {
 		return
 	}
+	var publicAccessBlock *PublicAccessBlockConfiguration
+	if publicAccessBlock, err = o.loadPublicAccessBlock(vol); err != nil {
+		return
+	}
+	acl = publicAccessBlock.filterACL(acl)
+	policy = publicAccessBlock.filterPolicy(policy)
 	return
 }
 
+// loadObjectACL loads the acl of the object in effect, the public grants are left out if
+// they are ignored by the public access block configuration.
+func (o *ObjectNode) loadObjectACL(vol *Volume, path string) (acl *AccessControlPolicy, err error) {
+	if acl, err = getObjectACL(vol, path, true); err != nil {
+		return
+	}
+	var publicAccessBlock *PublicAccessBlockConfiguration
+	if publicAccessBlock, err = o.loadPublicAccessBlock(vol); err != nil {
+		return
+	}
+	return publicAccessBlock.filterACL(acl), nil
+}
+
 func (o *ObjectNode) allowedBySrcBucketPolicy(param *RequestParam, reqUid string) (err error) {
 	paramCopy := *param
 	srcBucketId, srcKey, _, err := extractSrcBucketKey(paramCopy.r)
@@ -364,7 +407,7 @@ func (o *ObjectNode) allowedBySrcBucketPolicy(param *RequestParam, reqUid string
 
 	isOwner := reqUid == vol.owner
 	var acl *AccessControlPolicy
-	if acl, err = getObjectACL(vol, srcKey, true); err != nil && err != syscall.ENOENT {
+	if acl, err = o.loadObjectACL(vol, srcKey); err != nil && err != syscall.ENOENT {
 		log.LogErrorf("srcBucket acl check: get object acl fail: requestID(%v) volume(%v) path(%v) err(%v)",
 			GetRequestID(paramCopy.r), srcBucketId, srcKey, err)
 		return
@@ -384,3 +427,37 @@ func (o *ObjectNode) allowedBySrcBucketPolicy(param *RequestParam, reqUid string
 		GetRequestID(paramCopy.r), paramCopy.AccessKey(), paramCopy.Bucket(), paramCopy.Action())
 	return
 }
+
+// allowedBypassGovernance checks whether the request is allowed to bypass the GOVERNANCE mode retention,
+// which is allowed for the bucket owner and the users granted the bypass action by bucket policy.
+func (o *ObjectNode) allowedBypassGovernance(param *RequestParam, vol *Volume) bool {
+	if isAnonymous(param.accessKey) {
+		return false
+	}
+	userInfo, err := o.getUserInfoByAccessKey(param.AccessKey())
+	if err != nil {
+		log.LogErrorf("bypass governance check: load user info fail: requestID(%v) accessKey(%v) err(%v)",
+			GetRequestID(param.r), param.AccessKey(), err)
+		return false
+	}
+	if userInfo.UserType == proto.UserTypeRoot || userInfo.UserType == proto.UserTypeAdmin ||
+		userInfo.UserID == vol.GetOwner() {
+		return true
+	}
+	_, _, policy, err := o.loadBucketMeta(vol.Name())
+	if err != nil || policy == nil || policy.IsEmpty() {
+		return false
+	}
+	paramCopy := *param
+	paramCopy.apiName = BYPASS_GOVERNANCE_RETENTION
+	conditionCheck := map[string]string{
+		SOURCEIP: paramCopy.sourceIP,
+		KEYNAME:  paramCopy.object,
+		REFERER:  paramCopy.r.Referer(),
+		HOST:     paramCopy.r.Host,
+	}
+	pcr := policy.IsAllowed(&paramCopy, userInfo.UserID, vol.owner, conditionCheck)
+	log.LogDebugf("bypass governance check: requestID(%v) userID(%v) volume(%v) result(%v)",
+		GetRequestID(param.r), userInfo.UserID, vol.Name(), pcr)
+	return pcr == POLICY_ALLOW
+}
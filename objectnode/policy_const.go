@@ -42,9 +42,14 @@ const (
 // if more s3 api is supported by policy, need extend bucketApiList, objectApiList
 var (
 	bucketApiList = SliceString{LIST_OBJECTS, LIST_OBJECTS_V2, HEAD_BUCKET, DELETE_BUCKET, LIST_MULTIPART_UPLOADS, GET_BUCKET_LOCATION, GET_OBJECT_LOCK_CFG, PUT_OBJECT_LOCK_CFG}
-	objectApiList = SliceString{GET_OBJECT, HEAD_OBJECT, DELETE_OBJECT, PUT_OBJECT, POST_OBJECT, INITIALE_MULTIPART_UPLOAD, UPLOAD_PART, UPLOAD_PART_COPY, COMPLETE_MULTIPART_UPLOAD, COPY_OBJECT, ABORT_MULTIPART_UPLOAD, LIST_PARTS, BATCH_DELETE, GET_OBJECT_RETENTION}
+	objectApiList = SliceString{GET_OBJECT, HEAD_OBJECT, DELETE_OBJECT, PUT_OBJECT, POST_OBJECT, INITIALE_MULTIPART_UPLOAD, UPLOAD_PART, UPLOAD_PART_COPY, COMPLETE_MULTIPART_UPLOAD, COPY_OBJECT, ABORT_MULTIPART_UPLOAD, LIST_PARTS, BATCH_DELETE, GET_OBJECT_RETENTION,
+		PUT_OBJECT_RETENTION, GET_OBJECT_LEGAL_HOLD, PUT_OBJECT_LEGAL_HOLD, BYPASS_GOVERNANCE_RETENTION, GET_OBJECT_ATTRIBUTES}
 )
 
+// BYPASS_GOVERNANCE_RETENTION is not an api, it is checked by the delete and put retention apis
+// when the GOVERNANCE mode retention is requested to be bypassed.
+const BYPASS_GOVERNANCE_RETENTION = "BypassGovernanceRetention"
+
 type SliceString []string
 
 // https://docs.aws.amazon.com/AmazonS3/latest/dev/using-with-s3-actions.html
@@ -56,6 +61,11 @@ const (
 	ACTION_ABORT_MULTIPART_UPLOAD      = "abortmultipartupload"
 	ACTION_LIST_MULTIPART_UPLOAD_PARTS = "listmultipartuploadparts"
 	ACTION_GET_OBJECT_RETENTION        = "getobjectretention"
+	ACTION_PUT_OBJECT_RETENTION        = "putobjectretention"
+	ACTION_GET_OBJECT_LEGAL_HOLD       = "getobjectlegalhold"
+	ACTION_PUT_OBJECT_LEGAL_HOLD       = "putobjectlegalhold"
+	ACTION_BYPASS_GOVERNANCE_RETENTION = "bypassgovernanceretention"
+	ACTION_GET_OBJECT_ATTRIBUTES       = "getobjectattributes"
 
 	// bucket level
 	ACTION_LIST_BUCKET                   = "listbucket"
@@ -83,6 +93,11 @@ var S3ActionToApis = map[string]SliceString{
 	ACTION_GET_OBJECT_LOCK_CFG:           {GET_OBJECT_LOCK_CFG},
 	ACTION_PUT_OBJECT_LOCK_CFG:           {PUT_OBJECT_LOCK_CFG},
 	ACTION_GET_OBJECT_RETENTION:          {GET_OBJECT_RETENTION},
+	ACTION_PUT_OBJECT_RETENTION:          {PUT_OBJECT_RETENTION},
+	ACTION_GET_OBJECT_LEGAL_HOLD:         {GET_OBJECT_LEGAL_HOLD},
+	ACTION_PUT_OBJECT_LEGAL_HOLD:         {PUT_OBJECT_LEGAL_HOLD},
+	ACTION_BYPASS_GOVERNANCE_RETENTION:   {BYPASS_GOVERNANCE_RETENTION},
+	ACTION_GET_OBJECT_ATTRIBUTES:         {GET_OBJECT_ATTRIBUTES},
 }
 
 var allowAnonymousActions = SliceString{ACTION_GET_OBJECT}
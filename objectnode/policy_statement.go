@@ -98,6 +98,18 @@ func (s *Statement) checkRequiredField() error {
 	}
 }
 
+func (s *Statement) isPublic() bool {
+	if s.effect() != POLICY_ALLOW || !s.matchPrincipal(string(Principal_Any)) {
+		return false
+	}
+	for _, op := range s.Condition {
+		if op.operator() == ipAddress {
+			return false
+		}
+	}
+	return true
+}
+
 func (s *Statement) isEffectValid() bool {
 	e := strings.ToLower(s.Effect)
 	if e == "allow" || e == "deny" {
@@ -63,6 +63,15 @@ func (p *PolicyV2) IsAllow(action, bucket, key string) bool {
 	return allow
 }
 
+// IsAllowOnBucket evaluates the session policy for the request to the bucket, nothing is allowed
+// if the account of the session is restricted from the bucket by the public access block.
+func (p *PolicyV2) IsAllowOnBucket(action, bucket, key string, restricted bool) bool {
+	if restricted {
+		return false
+	}
+	return p.IsAllow(action, bucket, key)
+}
+
 func (p *PolicyV2) Validate() error {
 	return p.isValid()
 }
@@ -154,6 +154,8 @@ func TestPolicyV2_IsAllow(t *testing.T) {
 	require.True(t, policy.IsAllow("s3:GetObject", "bucket", "key1"))
 	require.True(t, policy.IsAllow("s3:PutObject", "bucket", "key2"))
 	require.False(t, policy.IsAllow("s3:DeleteObject", "bucket", "key2"))
+	require.True(t, policy.IsAllowOnBucket("s3:GetObject", "bucket", "key1", false))
+	require.False(t, policy.IsAllowOnBucket("s3:GetObject", "bucket", "key1", true))
 
 	policyStr = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"*","Resource":["arn:aws:s3:::bucket1","arn:aws:s3:::bucket2/*"]}]}`
 	policy, err = ParsePolicyV2Config(policyStr)
@@ -0,0 +1,297 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"net/http"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/sdk/master"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// In the unified permission mode, the S3 users are mapped to uid and gid by the uid operations of master,
+// and the S3 ACL of an object is kept in the mode bits and the POSIX ACL of its inode, so the FUSE clients
+// and objectnode check the same permissions:
+//
+//	owner of the object           the owner class, always rw-
+//	CanonicalUser grants          the named user entries of the mapped users
+//	AllUsers/AuthenticatedUsers   the group class and the other class
+//
+// READ maps to r, WRITE to w and FULL_CONTROL to rw, READ_ACP and WRITE_ACP have no POSIX counterpart.
+// The users without mapping are nobody, which falls into the other class.
+
+var (
+	posixReadActions = map[proto.Action]bool{
+		proto.OSSGetObjectAction:           true,
+		proto.OSSHeadObjectAction:          true,
+		proto.OSSGetObjectAttributesAction: true,
+		proto.OSSGetObjectAclAction:        true,
+		proto.OSSGetObjectTaggingAction:    true,
+	}
+	posixWriteActions = map[proto.Action]bool{
+		proto.OSSPutObjectAction:               true,
+		proto.OSSCopyObjectAction:              true,
+		proto.OSSCompleteMultipartUploadAction: true,
+		proto.OSSPutObjectTaggingAction:        true,
+		proto.OSSDeleteObjectTaggingAction:     true,
+	}
+)
+
+func aclPermissionToPosix(permission string) uint16 {
+	switch permission {
+	case PermissionRead:
+		return proto.PosixPermRead
+	case PermissionWrite:
+		return proto.PosixPermWrite
+	case PermissionFullControl:
+		return proto.PosixPermRead | proto.PosixPermWrite
+	default:
+		return 0
+	}
+}
+
+// posixACLOfObject maps the S3 ACL of the object to the POSIX ACL, the ACL is minimal unless any mapped
+// user other than the owner is granted.
+func posixACLOfObject(acl *AccessControlPolicy, users map[string]*proto.UidUserInfo) proto.PosixACL {
+	var public uint16
+	named := make(map[uint32]uint16)
+	if acl != nil {
+		for _, g := range acl.Acl.Grants {
+			perm := aclPermissionToPosix(g.Permission)
+			if perm == 0 {
+				continue
+			}
+			switch g.Grantee.Type {
+			case TypeGroup:
+				public |= perm
+			case TypeCanonicalUser:
+				if g.Grantee.Id == acl.GetOwner() {
+					continue
+				}
+				if user, ok := users[g.Grantee.Id]; ok {
+					named[user.Uid] |= perm
+				}
+			}
+		}
+	}
+	mode := uint32(proto.PosixPermRead|proto.PosixPermWrite)<<6 | uint32(public)<<3 | uint32(public)
+	return proto.NewPosixACL(mode, named)
+}
+
+func (v *Volume) unifiedPermission() bool {
+	v.permLock.RLock()
+	defer v.permLock.RUnlock()
+	return v.permissionMode == proto.PermissionModeUnified
+}
+
+// posixUser returns the uid and gid mapped to the S3 user, the users without mapping are nobody.
+func (v *Volume) posixUser(userID string) (uid, gid uint32) {
+	v.permLock.RLock()
+	defer v.permLock.RUnlock()
+	if user, ok := v.uidUsers[userID]; ok && userID != AnonymousUser {
+		return user.Uid, user.Gid
+	}
+	return proto.NobodyUid, proto.NobodyGid
+}
+
+func (v *Volume) updatePermission(view *proto.SimpleVolView) {
+	users := make(map[string]*proto.UidUserInfo, len(view.UidUsers))
+	for _, user := range view.UidUsers {
+		users[user.UserID] = user
+	}
+	v.permLock.Lock()
+	v.permissionMode = view.PermissionMode
+	v.uidUsers = users
+	v.permLock.Unlock()
+}
+
+func (v *Volume) syncPermission(mc *master.MasterClient) {
+	ticker := time.NewTicker(OSSMetaUpdateDuration)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			view, err := mc.AdminAPI().GetVolumeSimpleInfo(v.name)
+			if err != nil {
+				log.LogWarnf("syncPermission: get volume info from master fail: volume(%v) err(%v)", v.name, err)
+				continue
+			}
+			v.updatePermission(view)
+		case <-v.closeCh:
+			return
+		}
+	}
+}
+
+// dirMode returns the mode of the directories created by objectnode, they are searchable by
+// the FUSE clients in the unified permission mode.
+func (v *Volume) dirMode() os.FileMode {
+	if v.unifiedPermission() {
+		return DefaultDirMode | 0o111
+	}
+	return DefaultDirMode
+}
+
+// setPosixPermission keeps the S3 ACL of the object in the mode bits and the POSIX ACL of the inode,
+// the inode is owned by the user mapped to the object owner unless the owner is empty.
+// It does nothing unless the permission mode of the volume is unified.
+func (v *Volume) setPosixPermission(inode uint64, owner string, acl *AccessControlPolicy) (err error) {
+	if !v.unifiedPermission() {
+		return
+	}
+	v.permLock.RLock()
+	posixACL := posixACLOfObject(acl, v.uidUsers)
+	v.permLock.RUnlock()
+
+	var info *proto.InodeInfo
+	if info, err = v.mw.InodeGet_ll(inode); err != nil {
+		return
+	}
+	valid := proto.AttrMode
+	var uid, gid uint32
+	if owner != "" {
+		valid |= proto.AttrUid | proto.AttrGid
+		uid, gid = v.posixUser(owner)
+	}
+	mode := info.Mode&proto.Mode(os.ModeType) | posixACL.Mode()
+	if err = v.mw.Setattr(inode, valid, mode, uid, gid, 0, 0); err != nil {
+		return
+	}
+	if posixACL.IsMinimal() {
+		var xattr *proto.XAttrInfo
+		if xattr, err = v.mw.XAttrGet_ll(inode, proto.XAttrPosixACLAccess); err != nil || len(xattr.Get(proto.XAttrPosixACLAccess)) == 0 {
+			return
+		}
+		return v.mw.XAttrDel_ll(inode, proto.XAttrPosixACLAccess)
+	}
+	return v.mw.XAttrSet_ll(inode, []byte(proto.XAttrPosixACLAccess), posixACL.Encode())
+}
+
+// checkPosixPermission checks the wanted permissions of the object by its mode bits and POSIX ACL,
+// the objects which do not exist are allowed.
+func (v *Volume) checkPosixPermission(path, userID string, want uint16, ownerOnly bool) (allowed bool, err error) {
+	var inode uint64
+	if _, inode, _, _, err = v.recursiveLookupTarget(path, true); err != nil {
+		if err == syscall.ENOENT {
+			return true, nil
+		}
+		return
+	}
+	var info *proto.InodeInfo
+	if info, err = v.mw.InodeGet_ll(inode); err != nil {
+		if err == syscall.ENOENT {
+			return true, nil
+		}
+		return
+	}
+	uid, gid := v.posixUser(userID)
+	if ownerOnly {
+		return uid == 0 || uid == info.Uid, nil
+	}
+	var acl proto.PosixACL
+	var xattr *proto.XAttrInfo
+	if xattr, err = v.mw.XAttrGet_ll(inode, proto.XAttrPosixACLAccess); err != nil {
+		return
+	}
+	if data := xattr.Get(proto.XAttrPosixACLAccess); len(data) > 0 {
+		if acl, err = proto.DecodePosixACL(data); err != nil {
+			return
+		}
+	}
+	return proto.CheckPosixPermission(info.Mode, info.Uid, info.Gid, acl, uid, []uint32{gid}, want), nil
+}
+
+// posixPermissionMiddleware checks the permissions of the objects in the volumes of the unified permission
+// mode, as the FUSE clients do. The owner of the volume and the admin users are not checked, and creating
+// or deleting the objects is still governed by the bucket ACL and policy.
+func (o *ObjectNode) posixPermissionMiddleware(next http.Handler) http.Handler {
+	var handlerFunc http.HandlerFunc = func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		param := ParseRequestParam(r)
+		action := param.Action()
+		if param.Bucket() == "" || param.Object() == "" ||
+			(!posixReadActions[action] && !posixWriteActions[action] && action != proto.OSSPutObjectAclAction) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		var vol *Volume
+		if vol, err = o.getVol(param.Bucket()); err != nil || !vol.unifiedPermission() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		userID := AnonymousUser
+		if !isAnonymous(param.AccessKey()) {
+			var userInfo *proto.UserInfo
+			if userInfo, err = o.getUserInfoByAccessKey(param.AccessKey()); err != nil {
+				log.LogErrorf("posixPermissionMiddleware: get user info fail: requestID(%v) accessKey(%v) err(%v)",
+					GetRequestID(r), param.AccessKey(), err)
+				o.errorResponse(w, r, err, nil)
+				return
+			}
+			if userInfo.UserType == proto.UserTypeRoot || userInfo.UserType == proto.UserTypeAdmin ||
+				userInfo.UserID == vol.GetOwner() {
+				next.ServeHTTP(w, r)
+				return
+			}
+			userID = userInfo.UserID
+		}
+
+		want := proto.PosixPermRead
+		if posixWriteActions[action] {
+			want = proto.PosixPermWrite
+		}
+		var allowed bool
+		if allowed, err = vol.checkPosixPermission(param.Object(), userID, want,
+			action == proto.OSSPutObjectAclAction); err == nil && allowed && action == proto.OSSCopyObjectAction {
+			// the source object of copy should be readable
+			allowed, err = o.checkCopySourcePermission(r, userID)
+		}
+		if err != nil {
+			log.LogErrorf("posixPermissionMiddleware: check permission fail: requestID(%v) volume(%v) path(%v) err(%v)",
+				GetRequestID(r), vol.Name(), param.Object(), err)
+			o.errorResponse(w, r, err, nil)
+			return
+		}
+		if !allowed {
+			log.LogWarnf("posixPermissionMiddleware: permission denied: requestID(%v) volume(%v) path(%v) userID(%v) action(%v)",
+				GetRequestID(r), vol.Name(), param.Object(), userID, action)
+			o.errorResponse(w, r, nil, AccessDenied)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+	return handlerFunc
+}
+
+func (o *ObjectNode) checkCopySourcePermission(r *http.Request, userID string) (allowed bool, err error) {
+	srcBucket, srcKey, _, err := extractSrcBucketKey(r)
+	if err != nil {
+		// the invalid copy source is responded by the handler
+		return true, nil
+	}
+	var vol *Volume
+	if vol, err = o.getVol(srcBucket); err != nil || !vol.unifiedPermission() {
+		return true, nil
+	}
+	if userID != AnonymousUser && userID == vol.GetOwner() {
+		return true, nil
+	}
+	return vol.checkPosixPermission(srcKey, userID, proto.PosixPermRead, false)
+}
@@ -0,0 +1,79 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPosixACLOfObject(t *testing.T) {
+	users := map[string]*proto.UidUserInfo{
+		"owner": {UserID: "owner", Uid: 1000, Gid: 1000},
+		"alice": {UserID: "alice", Uid: 1001, Gid: 1001},
+	}
+
+	// the private objects are accessible to the owner only
+	acl := posixACLOfObject(nil, users)
+	require.True(t, acl.IsMinimal())
+	require.Equal(t, uint32(0o600), acl.Mode())
+	private, err := ParseCannedAcl(CannedPrivate, "owner")
+	require.NoError(t, err)
+	require.Equal(t, uint32(0o600), posixACLOfObject(private, users).Mode())
+
+	public, err := ParseCannedAcl(CannedPublicRead, "owner")
+	require.NoError(t, err)
+	acl = posixACLOfObject(public, users)
+	require.True(t, acl.IsMinimal())
+	require.Equal(t, uint32(0o644), acl.Mode())
+
+	// the grants to the mapped users are the named user entries, the others are ignored
+	granted := CreateDefaultACL("owner")
+	granted.Acl.Grants = append(granted.Acl.Grants,
+		Grant{Grantee: Grantee{Type: TypeCanonicalUser, Id: "alice"}, Permission: PermissionRead},
+		Grant{Grantee: Grantee{Type: TypeCanonicalUser, Id: "alice"}, Permission: PermissionWrite},
+		Grant{Grantee: Grantee{Type: TypeCanonicalUser, Id: "bob"}, Permission: PermissionFullControl},
+		Grant{Grantee: Grantee{Type: TypeCanonicalUser, Id: "alice"}, Permission: PermissionReadAcp},
+	)
+	acl = posixACLOfObject(granted, users)
+	require.False(t, acl.IsMinimal())
+	require.Equal(t, uint32(0o660), acl.Mode())
+	mode := acl.Mode()
+	require.True(t, proto.CheckPosixPermission(mode, 1000, 1000, acl, 1001, []uint32{1001}, proto.PosixPermRead|proto.PosixPermWrite))
+	require.False(t, proto.CheckPosixPermission(mode, 1000, 1000, acl, proto.NobodyUid, []uint32{proto.NobodyGid}, proto.PosixPermRead))
+}
+
+func TestVolumePosixUser(t *testing.T) {
+	v := &Volume{}
+	require.False(t, v.unifiedPermission())
+	require.Equal(t, DefaultDirMode, v.dirMode())
+
+	v.updatePermission(&proto.SimpleVolView{
+		PermissionMode: proto.PermissionModeUnified,
+		UidUsers:       []*proto.UidUserInfo{{UserID: "alice", Uid: 1001, Gid: 100}},
+	})
+	require.True(t, v.unifiedPermission())
+	require.Equal(t, DefaultDirMode|0o111, v.dirMode())
+	uid, gid := v.posixUser("alice")
+	require.Equal(t, uint32(1001), uid)
+	require.Equal(t, uint32(100), gid)
+	uid, gid = v.posixUser("bob")
+	require.Equal(t, proto.NobodyUid, uid)
+	require.Equal(t, proto.NobodyGid, gid)
+	uid, _ = v.posixUser(AnonymousUser)
+	require.Equal(t, proto.NobodyUid, uid)
+}
@@ -0,0 +1,152 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"encoding/xml"
+	"net/http"
+)
+
+const MaxPublicAccessBlockSize = 1 << 12 // 4KB
+
+var NoSuchPublicAccessBlockConfiguration = &ErrorCode{ErrorCode: "NoSuchPublicAccessBlockConfiguration", ErrorMessage: "The public access block configuration was not found.", StatusCode: http.StatusNotFound}
+
+// PublicAccessBlockConfiguration is the public access block configuration of the bucket,
+// it is also used as the cluster level configuration which applies to all the buckets.
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PublicAccessBlockConfiguration.html
+type PublicAccessBlockConfiguration struct {
+	XMLName               xml.Name `xml:"PublicAccessBlockConfiguration" json:"-"`
+	Xmlns                 string   `xml:"xmlns,attr,omitempty" json:"-"`
+	BlockPublicAcls       bool     `xml:"BlockPublicAcls" json:"blockPublicAcls"`
+	IgnorePublicAcls      bool     `xml:"IgnorePublicAcls" json:"ignorePublicAcls"`
+	BlockPublicPolicy     bool     `xml:"BlockPublicPolicy" json:"blockPublicPolicy"`
+	RestrictPublicBuckets bool     `xml:"RestrictPublicBuckets" json:"restrictPublicBuckets"`
+}
+
+// PolicyStatus reports whether the bucket is public.
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PolicyStatus.html
+type PolicyStatus struct {
+	XMLName  xml.Name `xml:"PolicyStatus"`
+	Xmlns    string   `xml:"xmlns,attr,omitempty"`
+	IsPublic bool     `xml:"IsPublic"`
+}
+
+func parsePublicAccessBlock(body []byte) (*PublicAccessBlockConfiguration, *ErrorCode) {
+	config := &PublicAccessBlockConfiguration{}
+	if err := xml.Unmarshal(body, config); err != nil {
+		return nil, MalformedXML
+	}
+	return config, nil
+}
+
+// merge returns the configuration in effect, each setting is enabled if it is enabled in either configuration.
+func (c *PublicAccessBlockConfiguration) merge(other *PublicAccessBlockConfiguration) *PublicAccessBlockConfiguration {
+	merged := &PublicAccessBlockConfiguration{}
+	for _, config := range []*PublicAccessBlockConfiguration{c, other} {
+		if config == nil {
+			continue
+		}
+		merged.BlockPublicAcls = merged.BlockPublicAcls || config.BlockPublicAcls
+		merged.IgnorePublicAcls = merged.IgnorePublicAcls || config.IgnorePublicAcls
+		merged.BlockPublicPolicy = merged.BlockPublicPolicy || config.BlockPublicPolicy
+		merged.RestrictPublicBuckets = merged.RestrictPublicBuckets || config.RestrictPublicBuckets
+	}
+	return merged
+}
+
+// filterACL returns the acl without the public grants if the public acls are ignored.
+func (c *PublicAccessBlockConfiguration) filterACL(acl *AccessControlPolicy) *AccessControlPolicy {
+	if acl == nil || !c.IgnorePublicAcls || !acl.IsPublic() {
+		return acl
+	}
+	return acl.withoutPublicGrants()
+}
+
+// filterPolicy returns the policy without the public statements if the public buckets are restricted.
+func (c *PublicAccessBlockConfiguration) filterPolicy(policy *Policy) *Policy {
+	if policy == nil || !c.RestrictPublicBuckets || !policy.IsPublic() {
+		return policy
+	}
+	return policy.withoutPublicStatements()
+}
+
+func storeBucketPublicAccessBlock(bytes []byte, vol *Volume) (err error) {
+	return vol.store.Put(vol.name, bucketRootPath, XAttrKeyOSSPublicAccessBlock, bytes)
+}
+
+func deleteBucketPublicAccessBlock(vol *Volume) (err error) {
+	return vol.store.Delete(vol.name, bucketRootPath, XAttrKeyOSSPublicAccessBlock)
+}
+
+// accountPublicAccessBlock returns the public access block configuration in effect for the buckets of
+// the account, which merges the configurations of the cluster and the account.
+func (o *ObjectNode) accountPublicAccessBlock(uid string) *PublicAccessBlockConfiguration {
+	return o.publicAccessBlock.merge(o.accountPublicAccessBlocks[uid])
+}
+
+// loadPublicAccessBlock returns the public access block configuration in effect for the bucket, which
+// merges the configurations of the cluster, the bucket owner's account and the bucket.
+func (o *ObjectNode) loadPublicAccessBlock(vol *Volume) (config *PublicAccessBlockConfiguration, err error) {
+	var bucketConfig *PublicAccessBlockConfiguration
+	if bucketConfig, err = vol.metaLoader.loadPublicAccessBlock(); err != nil {
+		return
+	}
+	return o.accountPublicAccessBlock(vol.GetOwner()).merge(bucketConfig), nil
+}
+
+// checkACL denies the acl granting the public access if the public acls are blocked.
+func (c *PublicAccessBlockConfiguration) checkACL(acl *AccessControlPolicy) error {
+	if c.BlockPublicAcls && acl != nil && acl.IsPublic() {
+		return AccessDenied
+	}
+	return nil
+}
+
+// checkPublicACL denies the acl granting the public access if the public acls are blocked for the bucket.
+func (o *ObjectNode) checkPublicACL(vol *Volume, acl *AccessControlPolicy) (err error) {
+	if acl == nil || !acl.IsPublic() {
+		return
+	}
+	var config *PublicAccessBlockConfiguration
+	if config, err = o.loadPublicAccessBlock(vol); err != nil {
+		return
+	}
+	return config.checkACL(acl)
+}
+
+// restrictedAccount reports whether the account is restricted from the bucket by the public access block,
+// only the bucket owner's account can access the bucket with public policy if the public buckets are restricted.
+// https://docs.aws.amazon.com/AmazonS3/latest/userguide/access-control-block-public-access.html#access-control-block-public-access-options
+func (o *ObjectNode) restrictedAccount(bucket, account string) (restricted bool, err error) {
+	var vol *Volume
+	if vol, err = o.getVol(bucket); err != nil {
+		if err == NoSuchBucket {
+			err = nil
+		}
+		return
+	}
+	if account == vol.GetOwner() {
+		return
+	}
+	var config *PublicAccessBlockConfiguration
+	if config, err = o.loadPublicAccessBlock(vol); err != nil || !config.RestrictPublicBuckets {
+		return
+	}
+	var policy *Policy
+	if policy, err = vol.metaLoader.loadPolicy(); err != nil {
+		return
+	}
+	return policy != nil && policy.IsPublic(), nil
+}
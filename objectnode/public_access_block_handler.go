@@ -0,0 +1,210 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetPublicAccessBlock.html
+func (o *ObjectNode) getPublicAccessBlockHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		o.errorResponse(w, r, err, errorCode)
+	}()
+
+	param := ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("getPublicAccessBlockHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		return
+	}
+
+	var config *PublicAccessBlockConfiguration
+	if config, err = vol.metaLoader.loadPublicAccessBlock(); err != nil {
+		log.LogErrorf("getPublicAccessBlockHandler: load public access block fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), vol.Name(), err)
+		return
+	}
+	if config == nil {
+		errorCode = NoSuchPublicAccessBlockConfiguration
+		return
+	}
+	result := *config
+	result.Xmlns = XMLNS
+	var data []byte
+	if data, err = MarshalXMLEntity(&result); err != nil {
+		log.LogErrorf("getPublicAccessBlockHandler: xml marshal fail: requestID(%v) volume(%v) config(%+v) err(%v)",
+			GetRequestID(r), vol.Name(), result, err)
+		return
+	}
+
+	writeSuccessResponseXML(w, data)
+}
+
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutPublicAccessBlock.html
+func (o *ObjectNode) putPublicAccessBlockHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		o.errorResponse(w, r, err, errorCode)
+	}()
+
+	param := ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("putPublicAccessBlockHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		return
+	}
+	var body []byte
+	if body, err = io.ReadAll(io.LimitReader(r.Body, MaxPublicAccessBlockSize+1)); err != nil {
+		log.LogErrorf("putPublicAccessBlockHandler: read request body fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), vol.Name(), err)
+		return
+	}
+	if len(body) > MaxPublicAccessBlockSize {
+		errorCode = EntityTooLarge
+		return
+	}
+	if requestMD5 := r.Header.Get(ContentMD5); requestMD5 != "" && requestMD5 != GetMD5(body) {
+		errorCode = InvalidDigest
+		return
+	}
+	var config *PublicAccessBlockConfiguration
+	if config, errorCode = parsePublicAccessBlock(body); errorCode != nil {
+		log.LogErrorf("putPublicAccessBlockHandler: parse public access block fail: requestID(%v) volume(%v) config(%v) err(%v)",
+			GetRequestID(r), vol.Name(), string(body), errorCode)
+		return
+	}
+
+	if body, err = xml.Marshal(config); err != nil {
+		log.LogErrorf("putPublicAccessBlockHandler: xml marshal fail: requestID(%v) volume(%v) config(%+v) err(%v)",
+			GetRequestID(r), vol.Name(), config, err)
+		return
+	}
+	if err = storeBucketPublicAccessBlock(body, vol); err != nil {
+		log.LogErrorf("putPublicAccessBlockHandler: store public access block fail: requestID(%v) volume(%v) config(%v) err(%v)",
+			GetRequestID(r), vol.Name(), string(body), err)
+		return
+	}
+	vol.metaLoader.storePublicAccessBlock(config)
+}
+
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_DeletePublicAccessBlock.html
+func (o *ObjectNode) deletePublicAccessBlockHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		o.errorResponse(w, r, err, errorCode)
+	}()
+
+	param := ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("deletePublicAccessBlockHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		return
+	}
+
+	if err = deleteBucketPublicAccessBlock(vol); err != nil {
+		log.LogErrorf("deletePublicAccessBlockHandler: delete public access block fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), vol.Name(), err)
+		return
+	}
+	vol.metaLoader.storePublicAccessBlock(nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketPolicyStatus.html
+func (o *ObjectNode) getBucketPolicyStatusHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		o.errorResponse(w, r, err, errorCode)
+	}()
+
+	param := ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("getBucketPolicyStatusHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		return
+	}
+
+	var (
+		config *PublicAccessBlockConfiguration
+		policy *Policy
+		acl    *AccessControlPolicy
+	)
+	if config, err = o.loadPublicAccessBlock(vol); err != nil {
+		log.LogErrorf("getBucketPolicyStatusHandler: load public access block fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), vol.Name(), err)
+		return
+	}
+	if policy, err = vol.metaLoader.loadPolicy(); err != nil {
+		log.LogErrorf("getBucketPolicyStatusHandler: load policy fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), vol.Name(), err)
+		return
+	}
+	if acl, err = vol.metaLoader.loadACL(); err != nil {
+		log.LogErrorf("getBucketPolicyStatusHandler: load acl fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), vol.Name(), err)
+		return
+	}
+	// the public policy or acl does not take effect if it is restricted or ignored
+	status := &PolicyStatus{Xmlns: XMLNS}
+	status.IsPublic = (policy != nil && policy.IsPublic() && !config.RestrictPublicBuckets) ||
+		(acl != nil && acl.IsPublic() && !config.IgnorePublicAcls)
+	var data []byte
+	if data, err = MarshalXMLEntity(status); err != nil {
+		log.LogErrorf("getBucketPolicyStatusHandler: xml marshal fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), vol.Name(), err)
+		return
+	}
+
+	writeSuccessResponseXML(w, data)
+}
@@ -0,0 +1,110 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePublicAccessBlock(t *testing.T) {
+	config, errorCode := parsePublicAccessBlock([]byte(`<PublicAccessBlockConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/">` +
+		`<BlockPublicAcls>true</BlockPublicAcls><RestrictPublicBuckets>true</RestrictPublicBuckets></PublicAccessBlockConfiguration>`))
+	require.Nil(t, errorCode)
+	require.True(t, config.BlockPublicAcls)
+	require.False(t, config.IgnorePublicAcls)
+	require.False(t, config.BlockPublicPolicy)
+	require.True(t, config.RestrictPublicBuckets)
+
+	_, errorCode = parsePublicAccessBlock([]byte(`<PublicAccessBlockConfiguration><BlockPublicAcls>yes</BlockPublicAcls>`))
+	require.Equal(t, MalformedXML, errorCode)
+}
+
+func TestMergePublicAccessBlock(t *testing.T) {
+	var cluster *PublicAccessBlockConfiguration
+	require.Equal(t, &PublicAccessBlockConfiguration{}, cluster.merge(nil))
+
+	cluster = &PublicAccessBlockConfiguration{BlockPublicPolicy: true}
+	bucket := &PublicAccessBlockConfiguration{IgnorePublicAcls: true}
+	require.Equal(t, &PublicAccessBlockConfiguration{IgnorePublicAcls: true, BlockPublicPolicy: true}, cluster.merge(bucket))
+	require.Equal(t, &PublicAccessBlockConfiguration{BlockPublicPolicy: true}, cluster.merge(nil))
+}
+
+func TestAccountPublicAccessBlock(t *testing.T) {
+	o := &ObjectNode{
+		publicAccessBlock: &PublicAccessBlockConfiguration{BlockPublicPolicy: true},
+		accountPublicAccessBlocks: map[string]*PublicAccessBlockConfiguration{
+			"user1": {BlockPublicAcls: true},
+		},
+	}
+	require.Equal(t, &PublicAccessBlockConfiguration{BlockPublicAcls: true, BlockPublicPolicy: true},
+		o.accountPublicAccessBlock("user1"))
+	require.Equal(t, &PublicAccessBlockConfiguration{BlockPublicPolicy: true}, o.accountPublicAccessBlock("user2"))
+
+	acl := &AccessControlPolicy{}
+	acl.SetPublicRead("user1")
+	require.Equal(t, AccessDenied, o.accountPublicAccessBlock("user1").checkACL(acl))
+	require.NoError(t, o.accountPublicAccessBlock("user2").checkACL(acl))
+	acl = &AccessControlPolicy{}
+	acl.SetPrivate("user1")
+	require.NoError(t, o.accountPublicAccessBlock("user1").checkACL(acl))
+}
+
+func TestPublicAccessBlockFilterACL(t *testing.T) {
+	acl := &AccessControlPolicy{}
+	acl.SetPrivate("owner")
+	require.False(t, acl.IsPublic())
+
+	acl = &AccessControlPolicy{}
+	acl.SetPublicRead("owner")
+	require.True(t, acl.IsPublic())
+	require.True(t, acl.IsAllowed(AnonymousUser, proto.OSSGetObjectAction))
+
+	require.Equal(t, acl, (&PublicAccessBlockConfiguration{BlockPublicAcls: true}).filterACL(acl))
+	filtered := (&PublicAccessBlockConfiguration{IgnorePublicAcls: true}).filterACL(acl)
+	require.False(t, filtered.IsPublic())
+	require.Len(t, filtered.Acl.Grants, 1)
+	require.Equal(t, "owner", filtered.GetOwner())
+	require.Len(t, acl.Acl.Grants, 2)
+}
+
+func TestPublicAccessBlockFilterPolicy(t *testing.T) {
+	public := `{"Version":"2012-10-17","Statement":[` +
+		`{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::bucket/*"},` +
+		`{"Effect":"Allow","Principal":{"AWS":["111122223333"]},"Action":"s3:PutObject","Resource":"arn:aws:s3:::bucket/*"}]}`
+	policy, err := ParsePolicy([]byte(public))
+	require.NoError(t, err)
+	require.True(t, policy.IsPublic())
+
+	require.Equal(t, policy, (&PublicAccessBlockConfiguration{BlockPublicPolicy: true}).filterPolicy(policy))
+	filtered := (&PublicAccessBlockConfiguration{RestrictPublicBuckets: true}).filterPolicy(policy)
+	require.False(t, filtered.IsPublic())
+	require.Len(t, filtered.Statements, 1)
+	require.Len(t, policy.Statements, 2)
+
+	// the statement limited to the source ip addresses is not public
+	restricted := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":"*","Action":"s3:GetObject",` +
+		`"Resource":"arn:aws:s3:::bucket/*","Condition":{"IpAddress":{"aws:SourceIp":["10.0.0.0/8"]}}}]}`
+	policy, err = ParsePolicy([]byte(restricted))
+	require.NoError(t, err)
+	require.False(t, policy.IsPublic())
+
+	denied := `{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::bucket/*"}]}`
+	policy, err = ParsePolicy([]byte(denied))
+	require.NoError(t, err)
+	require.False(t, policy.IsPublic())
+}
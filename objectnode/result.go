@@ -102,6 +102,20 @@ type CompleteMultipartResult struct {
 	Bucket   string   `xml:"Bucket"`
 	Key      string   `xml:"Key"`
 	ETag     string   `xml:"ETag"`
+	ChecksumResult
+}
+
+type GetObjectAttributesResult struct {
+	XMLName      xml.Name           `xml:"GetObjectAttributesResponse"`
+	ETag         string             `xml:"ETag,omitempty"`
+	Checksum     *ChecksumResult    `xml:"Checksum,omitempty"`
+	ObjectParts  *ObjectPartsResult `xml:"ObjectParts,omitempty"`
+	StorageClass string             `xml:"StorageClass,omitempty"`
+	ObjectSize   *int64             `xml:"ObjectSize,omitempty"`
+}
+
+type ObjectPartsResult struct {
+	PartsCount int `xml:"PartsCount"`
 }
 
 type Initiator struct {
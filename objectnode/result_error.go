@@ -63,6 +63,8 @@ var (
 	AccessDeniedBySTS                   = &ErrorCode{ErrorCode: "AccessDeniedBySTS", ErrorMessage: "Access Denied by STS.", StatusCode: http.StatusForbidden}
 	InvalidToken                        = &ErrorCode{ErrorCode: "InvalidToken", ErrorMessage: "The provided token is malformed or otherwise invalid.", StatusCode: http.StatusBadRequest}
 	ExpiredToken                        = &ErrorCode{ErrorCode: "ExpiredToken", ErrorMessage: "The provided token has expired.", StatusCode: http.StatusBadRequest}
+	InvalidIdentityToken                = &ErrorCode{ErrorCode: "InvalidIdentityToken", ErrorMessage: "The web identity token that was passed could not be validated.", StatusCode: http.StatusBadRequest}
+	ExpiredIdentityToken                = &ErrorCode{ErrorCode: "ExpiredTokenException", ErrorMessage: "The web identity token that was passed is expired.", StatusCode: http.StatusBadRequest}
 	MissingSecurityElement              = &ErrorCode{ErrorCode: "MissingSecurityElement", ErrorMessage: "The request is missing a security element.", StatusCode: http.StatusBadRequest}
 	RequestTimeTooSkewed                = &ErrorCode{ErrorCode: "RequestTimeTooSkewed", ErrorMessage: "The difference between the request time and the server's time is too large.", StatusCode: http.StatusBadRequest}
 	NoSuchTagSetError                   = &ErrorCode{ErrorCode: "NoSuchTagSetError", ErrorMessage: "The TagSet does not exist.", StatusCode: http.StatusNotFound}
@@ -71,6 +73,8 @@ var (
 	CORSRuleNotMatch                    = &ErrorCode{ErrorCode: "AccessForbidden", ErrorMessage: "CORSResponse: This CORS request is not allowed.", StatusCode: http.StatusForbidden}
 	ErrCORSNotEnabled                   = &ErrorCode{ErrorCode: "AccessForbidden", ErrorMessage: "CORSResponse: CORS is not enabled for this bucket.", StatusCode: http.StatusForbidden}
 	MissingOriginHeader                 = &ErrorCode{ErrorCode: "MissingOriginHeader", ErrorMessage: "Missing Origin header.", StatusCode: http.StatusBadRequest}
+	InvalidTargetBucketForLogging       = &ErrorCode{ErrorCode: "InvalidTargetBucketForLogging", ErrorMessage: "The target bucket for logging does not exist or is not owned by you.", StatusCode: http.StatusBadRequest}
+	NoSuchWebsiteConfiguration          = &ErrorCode{ErrorCode: "NoSuchWebsiteConfiguration", ErrorMessage: "The specified bucket does not have a website configuration.", StatusCode: http.StatusNotFound}
 	MalformedXML                        = &ErrorCode{ErrorCode: "MalformedXML", ErrorMessage: "The XML you provided was not well-formed or did not validate against our published schema.", StatusCode: http.StatusBadRequest}
 	TooManyCorsRules                    = &ErrorCode{ErrorCode: "TooManyCorsRules", ErrorMessage: "Too many cors rules.", StatusCode: http.StatusBadRequest}
 	InvalidDigest                       = &ErrorCode{ErrorCode: "InvalidDigest", ErrorMessage: "The Content-MD5 you specified is not valid.", StatusCode: http.StatusBadRequest}
@@ -90,6 +94,13 @@ var (
 	ObjectLockConfigurationNotFound     = &ErrorCode{"ObjectLockConfigurationNotFoundError", "Object Lock configuration does not exist for this bucket", http.StatusNotFound}
 	TooManyRequests                     = &ErrorCode{"TooManyRequests", "too many requests, please retry later", http.StatusTooManyRequests}
 	MalformedPOSTRequest                = &ErrorCode{ErrorCode: "MalformedPOSTRequest", ErrorMessage: "The body of your POST request is not well-formed multipart/form-data.", StatusCode: http.StatusBadRequest}
+	InvalidChecksum                     = &ErrorCode{ErrorCode: "InvalidRequest", ErrorMessage: "Value for x-amz-checksum header is invalid.", StatusCode: http.StatusBadRequest}
+	InvalidChecksumAlgorithm            = &ErrorCode{ErrorCode: "InvalidRequest", ErrorMessage: "Checksum algorithm provided is unsupported. Please try again with any of the valid types: [CRC32, CRC32C, SHA1, SHA256]", StatusCode: http.StatusBadRequest}
+	MultipleChecksumTypes               = &ErrorCode{ErrorCode: "InvalidRequest", ErrorMessage: "Expecting a single x-amz-checksum- header. Multiple checksum Types are not allowed.", StatusCode: http.StatusBadRequest}
+	MissingChecksum                     = &ErrorCode{ErrorCode: "InvalidRequest", ErrorMessage: "x-amz-sdk-checksum-algorithm specified, but no corresponding x-amz-checksum-* or x-amz-trailer headers were found.", StatusCode: http.StatusBadRequest}
+	ChecksumTypeMismatch                = &ErrorCode{ErrorCode: "InvalidRequest", ErrorMessage: "Checksum Type mismatch occurred, expected checksum Type of the multipart upload.", StatusCode: http.StatusBadRequest}
+	ChecksumMismatch                    = &ErrorCode{ErrorCode: "BadDigest", ErrorMessage: "The checksum you specified did not match the calculated checksum.", StatusCode: http.StatusBadRequest}
+	InvalidObjectAttributes             = &ErrorCode{ErrorCode: "InvalidArgument", ErrorMessage: "Invalid attribute name specified.", StatusCode: http.StatusBadRequest}
 )
 
 type ErrorCode struct {
@@ -119,6 +130,7 @@ func (ec *ErrorCode) ServeResponse(w http.ResponseWriter, r *http.Request) {
 	// traceMiddleWare send exception request to prometheus via status code
 	SetResponseStatusCode(r, strconv.Itoa(ec.StatusCode))
 	SetResponseErrorMessage(r, ec.ErrorMessage)
+	SetResponseErrorCode(r, ec.ErrorCode)
 
 	errorResponse := ErrorResponse{
 		Code:      ec.ErrorCode,
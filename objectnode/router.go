@@ -24,6 +24,26 @@ import (
 
 // register api routers
 func (o *ObjectNode) registerApiRouters(router *mux.Router) {
+	// Website endpoint
+	// The website routers are registered ahead of the api routers, as the host of
+	// website endpoint may be matched with the api domains as well.
+	// Reference: https://docs.aws.amazon.com/AmazonS3/latest/userguide/WebsiteEndpoints.html
+	var websiteRouters []*mux.Router
+	for _, d := range o.websiteDomains {
+		websiteRouters = append(websiteRouters, router.Host("{bucket:.+}."+d).Subrouter())
+		websiteRouters = append(websiteRouters, router.Host("{bucket:.+}."+d+":{port:[0-9]+}").Subrouter())
+	}
+	for _, r := range websiteRouters {
+		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSHeadObjectAction)).
+			Methods(http.MethodHead).
+			Path("/{object:.*}").
+			HandlerFunc(o.websiteObjectHandler)
+		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSGetObjectAction)).
+			Methods(http.MethodGet).
+			Path("/{object:.*}").
+			HandlerFunc(o.websiteObjectHandler)
+	}
+
 	var bucketRouters []*mux.Router
 	bRouter := router.PathPrefix("/").Subrouter()
 	for _, d := range o.domains {
@@ -96,12 +116,11 @@ func (o *ObjectNode) registerApiRouters(router *mux.Router) {
 
 		// Get object legal hold
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetObjectLegalHold.html
-		// Notes: unsupported operation
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSGetObjectLegalHoldAction)).
 			Methods(http.MethodGet).
 			Path("/{object:.+}").
 			Queries("legal-hold", "").
-			HandlerFunc(o.unsupportedOperationHandler)
+			HandlerFunc(o.getObjectLegalHoldHandler)
 
 		// Get object retention
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetObjectRetention.html
@@ -111,6 +130,14 @@ func (o *ObjectNode) registerApiRouters(router *mux.Router) {
 			Queries("retention", "").
 			HandlerFunc(o.getObjectRetentionHandler)
 
+		// Get object attributes
+		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetObjectAttributes.html
+		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSGetObjectAttributesAction)).
+			Methods(http.MethodGet).
+			Path("/{object:.+}").
+			Queries("attributes", "").
+			HandlerFunc(o.getObjectAttributesHandler)
+
 		// Get object torrent
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetObjectTorrent.html
 		// Notes: unsupported operation
@@ -157,11 +184,10 @@ func (o *ObjectNode) registerApiRouters(router *mux.Router) {
 
 		// Get bucket policy status
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketPolicyStatus.html
-		// Notes: unsupported operation
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSGetBucketPolicyStatusAction)).
 			Methods(http.MethodGet).
 			Queries("policyStatus", "").
-			HandlerFunc(o.unsupportedOperationHandler)
+			HandlerFunc(o.getBucketPolicyStatusHandler)
 
 		// Get bucket acl
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketAcl.html
@@ -193,21 +219,54 @@ func (o *ObjectNode) registerApiRouters(router *mux.Router) {
 			Queries("cors", "").
 			HandlerFunc(o.getBucketCorsHandler)
 
+		// Get bucket logging
+		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketLogging.html
+		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSGetBucketLoggingAction)).
+			Methods(http.MethodGet).
+			Queries("logging", "").
+			HandlerFunc(o.getBucketLoggingHandler)
+
+		// Get bucket inventory configuration
+		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketInventoryConfiguration.html
+		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSGetBucketInventoryConfigurationAction)).
+			Methods(http.MethodGet).
+			Queries("inventory", "", "id", "{id:.+}").
+			HandlerFunc(o.getBucketInventoryConfigurationHandler)
+
+		// List bucket inventory configurations
+		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_ListBucketInventoryConfigurations.html
+		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSListBucketInventoryConfigurationsAction)).
+			Methods(http.MethodGet).
+			Queries("inventory", "").
+			HandlerFunc(o.listBucketInventoryConfigurationsHandler)
+
+		// Get bucket metrics configuration
+		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketMetricsConfiguration.html
+		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSGetBucketMetricsConfigurationAction)).
+			Methods(http.MethodGet).
+			Queries("metrics", "", "id", "{id:.+}").
+			HandlerFunc(o.getBucketMetricsConfigurationHandler)
+
+		// List bucket metrics configurations
+		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_ListBucketMetricsConfigurations.html
+		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSListBucketMetricsConfigurationsAction)).
+			Methods(http.MethodGet).
+			Queries("metrics", "").
+			HandlerFunc(o.listBucketMetricsConfigurationsHandler)
+
 		// Get bucket website
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketWebsite.html
-		// Notes: unsupported operation
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSGetBucketWebsiteAction)).
 			Methods(http.MethodGet).
 			Queries("website", "").
-			HandlerFunc(o.unsupportedOperationHandler)
+			HandlerFunc(o.getBucketWebsiteHandler)
 
 		// Get public access block
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetPublicAccessBlock.html
-		// Notes: unsupported operation
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSGetPublicAccessBlockAction)).
 			Methods(http.MethodGet).
 			Queries("publicAccessBlock", "").
-			HandlerFunc(o.unsupportedOperationHandler)
+			HandlerFunc(o.getPublicAccessBlockHandler)
 
 		// Get bucket request payment
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketRequestPayment.html
@@ -362,21 +421,19 @@ func (o *ObjectNode) registerApiRouters(router *mux.Router) {
 
 		// Put object legal hold
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutObjectLegalHold.html
-		// Notes: unsupported operation
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSPutObjectLegalHoldAction)).
 			Methods(http.MethodPut).
 			Path("/{object:.+}").
 			Queries("legal-hold", "").
-			HandlerFunc(o.unsupportedOperationHandler)
+			HandlerFunc(o.putObjectLegalHoldHandler)
 
 		// Put object retention
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutObjectRetention.html
-		// Notes: unsupported operation
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSPutObjectRetentionAction)).
 			Methods(http.MethodPut).
 			Path("/{object:.+}").
 			Queries("retention", "").
-			HandlerFunc(o.unsupportedOperationHandler)
+			HandlerFunc(o.putObjectRetentionHandler)
 
 		// Put object
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutObject.html
@@ -422,21 +479,40 @@ func (o *ObjectNode) registerApiRouters(router *mux.Router) {
 			Queries("cors", "").
 			HandlerFunc(o.putBucketCorsHandler)
 
+		// Put bucket logging
+		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketLogging.html
+		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSPutBucketLoggingAction)).
+			Methods(http.MethodPut).
+			Queries("logging", "").
+			HandlerFunc(o.putBucketLoggingHandler)
+
+		// Put bucket inventory configuration
+		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketInventoryConfiguration.html
+		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSPutBucketInventoryConfigurationAction)).
+			Methods(http.MethodPut).
+			Queries("inventory", "", "id", "{id:.+}").
+			HandlerFunc(o.putBucketInventoryConfigurationHandler)
+
+		// Put bucket metrics configuration
+		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketMetricsConfiguration.html
+		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSPutBucketMetricsConfigurationAction)).
+			Methods(http.MethodPut).
+			Queries("metrics", "", "id", "{id:.+}").
+			HandlerFunc(o.putBucketMetricsConfigurationHandler)
+
 		// Put bucket website
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketWebsite.html
-		// Notes: unsupported operation
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSPutBucketWebsiteAction)).
 			Methods(http.MethodPut).
 			Queries("website", "").
-			HandlerFunc(o.unsupportedOperationHandler)
+			HandlerFunc(o.putBucketWebsiteHandler)
 
 		// Put public access block
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutPublicAccessBlock.html
-		// Notes: unsupported operation
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSPutPublicAccessBlockAction)).
 			Methods(http.MethodPut).
 			Queries("publicAccessBlock", "").
-			HandlerFunc(o.unsupportedOperationHandler)
+			HandlerFunc(o.putPublicAccessBlockHandler)
 
 		// Put bucket request payment
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketRequestPayment.html
@@ -548,19 +624,17 @@ func (o *ObjectNode) registerApiRouters(router *mux.Router) {
 
 		// Delete bucket website
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_DeleteBucketWebsite.html
-		// Notes: unsupported operation
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSDeleteBucketWebsiteAction)).
 			Methods(http.MethodDelete).
 			Queries("website", "").
-			HandlerFunc(o.unsupportedOperationHandler)
+			HandlerFunc(o.deleteBucketWebsiteHandler)
 
 		// Delete public access block
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_DeletePublicAccessBlock.html
-		// Notes: unsupported operation
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSDeletePublicAccessBlockAction)).
 			Methods(http.MethodDelete).
 			Queries("publicAccessBlock", "").
-			HandlerFunc(o.unsupportedOperationHandler)
+			HandlerFunc(o.deletePublicAccessBlockHandler)
 
 		// Delete bucket replication
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_DeleteBucketReplication.html
@@ -585,6 +659,20 @@ func (o *ObjectNode) registerApiRouters(router *mux.Router) {
 			Queries("lifecycle", "").
 			HandlerFunc(o.deleteBucketLifecycleConfigurationHandler)
 
+		// Delete bucket inventory configuration
+		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_DeleteBucketInventoryConfiguration.html
+		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSDeleteBucketInventoryConfigurationAction)).
+			Methods(http.MethodDelete).
+			Queries("inventory", "", "id", "{id:.+}").
+			HandlerFunc(o.deleteBucketInventoryConfigurationHandler)
+
+		// Delete bucket metrics configuration
+		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_DeleteBucketMetricsConfiguration.html
+		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSDeleteBucketMetricsConfigurationAction)).
+			Methods(http.MethodDelete).
+			Queries("metrics", "", "id", "{id:.+}").
+			HandlerFunc(o.deleteBucketMetricsConfigurationHandler)
+
 		// Delete bucket
 		// API reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_DeleteBucket.html
 		r.NewRoute().Name(ActionToUniqueRouteName(proto.OSSDeleteBucketAction)).
@@ -615,6 +703,30 @@ func (o *ObjectNode) registerApiRouters(router *mux.Router) {
 		Methods(http.MethodGet).
 		HandlerFunc(o.listBucketsHandler)
 
+	// Assume Role (STS)
+	// API reference: https://docs.aws.amazon.com/STS/latest/APIReference/API_AssumeRole.html
+	router.NewRoute().Name(ActionToUniqueRouteName(proto.OSSAssumeRoleAction)).
+		Methods(http.MethodPost).
+		Path("/").
+		MatcherFunc(stsActionMatcher(stsAssumeRoleValue)).
+		HandlerFunc(o.assumeRoleHandler)
+
+	// Assume Role With Web Identity (STS)
+	// API reference: https://docs.aws.amazon.com/STS/latest/APIReference/API_AssumeRoleWithWebIdentity.html
+	router.NewRoute().Name(ActionToUniqueRouteName(proto.OSSAssumeRoleWithWebIdentityAction)).
+		Methods(http.MethodPost).
+		Path("/").
+		MatcherFunc(stsActionMatcher(stsAssumeRoleWithWebIdentityValue)).
+		HandlerFunc(o.assumeRoleWithWebIdentityHandler)
+
+	// Get Metric Statistics (CloudWatch)
+	// API reference: https://docs.aws.amazon.com/AmazonCloudWatch/latest/APIReference/API_GetMetricStatistics.html
+	router.NewRoute().Name(ActionToUniqueRouteName(proto.OSSGetMetricStatisticsAction)).
+		Methods(http.MethodPost).
+		Path("/").
+		MatcherFunc(stsActionMatcher(cloudWatchGetMetricStatisticsValue)).
+		HandlerFunc(o.getMetricStatisticsHandler)
+
 	// Get Federation Token (STS)
 	// API reference: https://docs.aws.amazon.com/STS/latest/APIReference/API_GetFederationToken.html
 	router.NewRoute().Name(ActionToUniqueRouteName(proto.OSSGetFederationTokenAction)).
@@ -22,13 +22,18 @@ package objectnode
 const (
 	UNSUPPORT_API              = "UnSupportAPI"
 	GET_FEDERATION_TOKEN       = "GetFederationToken"         // api:  POST /,  host=s3-cn-east-1.cs.com, create sts token
+	ASSUME_ROLE                = "AssumeRole"                 // api:  POST /,  host=s3-cn-east-1.cs.com, assume role by user
+	ASSUME_ROLE_WEB_IDENTITY   = "AssumeRoleWithWebIdentity"  // api:  POST /,  host=s3-cn-east-1.cs.com, assume role by oidc token
+	GET_METRIC_STATISTICS      = "GetMetricStatistics"        // api:  POST /,  host=s3-cn-east-1.cs.com, get bucket metrics statistics
 	List_BUCKETS               = "ListBuckets"                // api:  GET / , host=s3-cn-east-1.cs.com, list all buckets
 	DELETE_BUCKET              = "DeleteBucket"               // api:  Delete /  , host=<bucket>.domain
 	DELETE_BUCKET_CORS         = "DeleteBucketCors"           // api:  Delete /?cors  , host=<bucket>.domain
 	DELETE_BUCKET_ENCRYPTION   = "DeleteBucketEncryption"     // api:  Delete /?encryption  , host=<bucket>.domain
 	DELETE_BUCKET_LIFECYCLE    = "DeleteBucketLifeCycle"      // api:  Delete /?lifycycle  , host=<bucket>.domain
+	DELETE_BUCKET_INVENTORY    = "DeleteBucketInventory"      // api:  Delete /?inventory&id=<id>  , host=<bucket>.domain
 	DELETE_BUCKET_METRICS      = "DeleteBucketMetrics"        // api:  Delete /?metrics&id=<ID>  , host=<bucket>.domain
 	DELETE_BUCKET_POLICY       = "DeleteBucketPolicy"         // api:  Delete /?policy  , host=<bucket>.domain
+	DELETE_PUBLIC_ACCESS_BLOCK = "DeletePublicAccessBlock"    // api:  Delete /?publicAccessBlock  , host=<bucket>.domain
 	DELETE_BUCKET_REPLICATION  = "DeleteBucketReplication"    // api:  Delete /?replication  , host=<bucket>.domain
 	DELETE_BUCKET_TAGGING      = "DeleteBucketTagging"        // api:  Delete /?tagging  , host=<bucket>.domain
 	DELETE_BUCKET_WEBSITE      = "DeleteBucketWebsite"        // api:  Delete /?website  , host=<bucket>.domain
@@ -42,7 +47,10 @@ const (
 	GET_BUCKET_LOCATION        = "GetBucketLocation"          // api:  GET /?location , host=<bucket>.domain
 	GET_PUBLIC_ACCESS_BLOCK    = "GetPublicAccessBlock"       // api:  Get /?publicAccessBlock  , host=<bucket>.domain
 	GET_BUCKET_LOGGING         = "GetBucketLogging"           // api:  Get /?logging  , host=<bucket>.domain
+	GET_BUCKET_INVENTORY       = "GetBucketInventory"         // api:  Get /?inventory&id=<id>  , host=<bucket>.domain
+	LIST_BUCKET_INVENTORY      = "ListBucketInventory"        // api:  Get /?inventory  , host=<bucket>.domain
 	GET_BUCKET_METRICS         = "GetBucketMetrics"           // api:  Get /?metrics&id=<id>  , host=<bucket>.domain
+	LIST_BUCKET_METRICS        = "ListBucketMetrics"          // api:  Get /?metrics  , host=<bucket>.domain
 	GET_BUCKET_NOTIFICATION    = "GetBucketNotification"      // api:  Get /?notification  , host=<bucket>.domain
 	GET_BUCKET_POLICY_STATUS   = "GetBucketPolicyStatus"      // api:  Get /?policyStatus  , host=<bucket>.domain
 	GET_BUCKET_OBJECT_VERSIONS = "GetBucketObjectVersions"    // api:  Get /?versions  , host=<bucket>.domain
@@ -62,6 +70,7 @@ const (
 	PUT_BUCKET_LIFECYCLE       = "PutBucketLifecycle"         // api:  PUT /?lifecycle , host=<bucket>.domain
 	PUT_PUBLIC_ACCESS_BLOCK    = "PutPublicAccessBlock"       // api:  PUT /<bucketname>?publicAccessBlock , host=<bucket>.domain,
 	PUT_BUCKET_LOGGING         = "PutBucketLogging"           // api:  PUT /?logging , host=<bucket>.domain
+	PUT_BUCKET_INVENTORY       = "PutBucketInventory"         // api:  PUT /?inventory&id=<id> , host=<bucket>.domain
 	PUT_BUCKET_METRICS         = "PutBucketMetrics"           // api:  PUT /?metrics&id=<id> , host=<bucket>.domain
 	PUT_BUCKET_NOTIFICATION    = "PutBucketNotification"      // api:  PUT /?notification , host=<bucket>.domain
 	PUT_BUCKET_POLICY          = "PutBucketPolicy"            // api:  PUT /?policy , host=<bucket>.domain
@@ -78,6 +87,10 @@ const (
 	GET_OBJECT_ACL             = "GetObjectAcl"               // api:  Get /<bucketname>/<objname>?acl   , host=<bucket>.domain
 	GET_OBJECT_TAGGING         = "GetObjectTagging"           // api:  Get /<bucketname>/<objname>?tagging   , host=<bucket>.domain
 	GET_OBJECT_RETENTION       = "GetObjectRetention"         // api:  Get /<bucketname>/<objname>?retention, host=<bucket>.domain
+	PUT_OBJECT_RETENTION       = "PutObjectRetention"         // api:  Put /<bucketname>/<objname>?retention, host=<bucket>.domain
+	GET_OBJECT_LEGAL_HOLD      = "GetObjectLegalHold"         // api:  Get /<bucketname>/<objname>?legal-hold, host=<bucket>.domain
+	PUT_OBJECT_LEGAL_HOLD      = "PutObjectLegalHold"         // api:  Put /<bucketname>/<objname>?legal-hold, host=<bucket>.domain
+	GET_OBJECT_ATTRIBUTES      = "GetObjectAttributes"        // api:  Get /<bucketname>/<objname>?attributes, host=<bucket>.domain
 	HEAD_OBJECT                = "HeadObject"                 // api:  HEAD /<ObjectName> , host=<bucket>.domain
 	OPTIONS_OBJECT             = "OptionsObject"              // api:  OPTIONS /<ObjectName>, host=<bucket>.domain
 	POST_OBJECT                = "PostObject"                 // api:  Post /  , host=<bucket>.domain
@@ -93,6 +93,19 @@ const (
 	// The configuration in the example will allow ObjectNode to automatically resolve "* .object.cube.io".
 	configDomains = "domains"
 
+	// String array configuration item, used to configure the domain names of the website endpoint.
+	// Requests to "<bucket>.<website domain>" are served as anonymous website requests of the bucket,
+	// which resolves index document of "directory" keys, redirect rules and error document according
+	// to the website configuration of the bucket. The website domains must be different from the
+	// domains of the S3 interface.
+	// Example:
+	//		{
+	//			"websiteDomains": [
+	//				"website.cube.io"
+	//			]
+	//		}
+	configWebsiteDomains = "websiteDomains"
+
 	disabledActions               = "disabledActions"
 	configSignatureIgnoredActions = "signatureIgnoredActions"
 
@@ -107,6 +120,47 @@ const (
 	//		}
 	configSTSNotAllowedActions = "stsNotAllowedActions"
 
+	// Array type configuration item, used to configure the OpenID Connect identity providers trusted by
+	// AssumeRoleWithWebIdentity. The token issued by the provider is verified by the JSON web key set
+	// in the local file, and its audience must be one of the client ids if they are configured.
+	// Example:
+	//		{
+	//			"oidcProviders": [
+	//				{
+	//					"issuer": "https://oidc.k8s.cube.io",
+	//					"jwksFile": "/cfs/conf/k8s-jwks.json",
+	//					"clientIds": ["sts.amazonaws.com"]
+	//				}
+	//			]
+	//		}
+	configOIDCProviders = "oidcProviders"
+
+	// Map type configuration item, used to configure the cluster level public access block settings,
+	// which apply to all the buckets in addition to the public access block configurations of the buckets.
+	// Example:
+	//		{
+	//			"publicAccessBlock": {
+	//				"blockPublicAcls": true,
+	//				"ignorePublicAcls": true,
+	//				"blockPublicPolicy": true,
+	//				"restrictPublicBuckets": true
+	//			}
+	//		}
+	configPublicAccessBlock = "publicAccessBlock"
+
+	// Map type configuration item, used to configure the account level public access block settings, which
+	// apply to all the buckets owned by the account in addition to the cluster and bucket level settings.
+	// Example:
+	//		{
+	//			"accountPublicAccessBlocks": {
+	//				"user1": {
+	//					"blockPublicAcls": true,
+	//					"restrictPublicBuckets": true
+	//				}
+	//			}
+	//		}
+	configAccountPublicAccessBlocks = "accountPublicAccessBlocks"
+
 	// Map type configuration item, used to configure ObjectNode to support audit log feature. For detailed
 	// parameters, see the AuditLogConfig structure.
 	// Example:
@@ -154,6 +208,14 @@ const (
 
 	// s3 QoS config refresh interval
 	s3QoSRefreshIntervalSec = "s3QoSRefreshIntervalSec"
+
+	// Interval of delivering the server access logs of the buckets with logging enabled,
+	// the logs are delivered earlier if the batched logs of a bucket exceed 4MB.
+	// Example:
+	//		{
+	//			"accessLogFlushIntervalSec": 300
+	//		}
+	configAccessLogFlushIntervalSec = "accessLogFlushIntervalSec"
 )
 
 // Default of configuration value
@@ -192,14 +254,23 @@ type ObjectNode struct {
 	// state      uint32
 	// wg         sync.WaitGroup
 
+	websiteDomains   []string
+	websiteWildcards Wildcards
+
 	localAuditHandler rpc.ProgressHandler
 	externalAudit     *ExternalAudit
+	accessLogger      *AccessLogger
+	bucketMetrics     *BucketMetrics
 
 	closes []func() // close other resources after http server closed
 
 	signatureIgnoredActions proto.Actions // signature ignored actions
 	disabledActions         proto.Actions // disabled actions
 	stsNotAllowedActions    proto.Actions // actions that are not accessible to STS users
+	oidcProviders           OIDCProviders // identity providers trusted by AssumeRoleWithWebIdentity
+
+	publicAccessBlock         *PublicAccessBlockConfiguration            // cluster level public access block settings
+	accountPublicAccessBlocks map[string]*PublicAccessBlockConfiguration // account level public access block settings
 
 	control                 common.Control
 	rateLimit               RateLimiter
@@ -240,6 +311,14 @@ func (o *ObjectNode) loadConfig(cfg *config.Config) (err error) {
 	}
 	log.LogInfof("loadConfig: setup config: %v(%v)", configDomains, domains)
 
+	// parse website domain
+	websiteDomains := cfg.GetStringSlice(configWebsiteDomains)
+	o.websiteDomains = websiteDomains
+	if o.websiteWildcards, err = NewWildcards(websiteDomains); err != nil {
+		return
+	}
+	log.LogInfof("loadConfig: setup config: %v(%v)", configWebsiteDomains, websiteDomains)
+
 	// parse master config
 	masters := cfg.GetStringSlice(configMasterAddr)
 	if len(masters) == 0 {
@@ -277,6 +356,39 @@ func (o *ObjectNode) loadConfig(cfg *config.Config) (err error) {
 		}
 	}
 
+	// parse oidc providers config
+	if rawOIDCProviders := cfg.GetValue(configOIDCProviders); rawOIDCProviders != nil {
+		var confs []OIDCProviderConfig
+		if err = ParseJSONEntity(rawOIDCProviders, &confs); err != nil {
+			err = fmt.Errorf("invalid %v configuration: %v", configOIDCProviders, err)
+			return
+		}
+		if o.oidcProviders, err = NewOIDCProviders(confs); err != nil {
+			err = fmt.Errorf("invalid %v configuration: %v", configOIDCProviders, err)
+			return
+		}
+		log.LogInfof("loadConfig: setup config: %v(%v)", configOIDCProviders, rawOIDCProviders)
+	}
+
+	// parse public access block config
+	if rawPublicAccessBlock := cfg.GetValue(configPublicAccessBlock); rawPublicAccessBlock != nil {
+		o.publicAccessBlock = &PublicAccessBlockConfiguration{}
+		if err = ParseJSONEntity(rawPublicAccessBlock, o.publicAccessBlock); err != nil {
+			err = fmt.Errorf("invalid %v configuration: %v", configPublicAccessBlock, err)
+			return
+		}
+		log.LogInfof("loadConfig: setup config: %v(%v)", configPublicAccessBlock, rawPublicAccessBlock)
+	}
+
+	// parse account public access blocks config
+	if rawAccountPublicAccessBlocks := cfg.GetValue(configAccountPublicAccessBlocks); rawAccountPublicAccessBlocks != nil {
+		if err = ParseJSONEntity(rawAccountPublicAccessBlocks, &o.accountPublicAccessBlocks); err != nil {
+			err = fmt.Errorf("invalid %v configuration: %v", configAccountPublicAccessBlocks, err)
+			return
+		}
+		log.LogInfof("loadConfig: setup config: %v(%v)", configAccountPublicAccessBlocks, rawAccountPublicAccessBlocks)
+	}
+
 	// parse auditLog config
 	if rawAuditLog := cfg.GetValue(configAuditLog); rawAuditLog != nil {
 		if err = o.setAuditLog(rawAuditLog); err != nil {
@@ -416,6 +528,17 @@ func handleStart(s common.Server, cfg *config.Config) (err error) {
 		o.limitMutex.Unlock()
 	}
 
+	// server access logging of buckets
+	flushInterval := time.Duration(cfg.GetInt64(configAccessLogFlushIntervalSec)) * time.Second
+	o.accessLogger = NewAccessLogger(o.getVol, flushInterval, 0)
+	o.accessLogger.Start()
+	o.closes = append(o.closes, o.accessLogger.Close)
+
+	// request metrics of buckets
+	o.bucketMetrics = NewBucketMetrics(o.getVol)
+	o.bucketMetrics.Start()
+	o.closes = append(o.closes, o.bucketMetrics.Close)
+
 	// start rest api
 	if err = o.startMuxRestAPI(); err != nil {
 		log.LogInfof("handleStart: start rest api fail: err(%v)", err)
@@ -457,9 +580,11 @@ func (o *ObjectNode) startMuxRestAPI() (err error) {
 		o.auditMiddleware,
 		o.expectMiddleware,
 		o.traceMiddleware,
+		o.websiteMiddleware,
 		o.authMiddleware,
 		o.corsMiddleware,
 		o.policyCheckMiddleware,
+		o.posixPermissionMiddleware,
 		o.contentMiddleware,
 	)
 
@@ -15,27 +15,41 @@
 package objectnode
 
 import (
+	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
 	"encoding/base64"
 	"encoding/json"
 	"encoding/xml"
+	"io"
+	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/cubefs/cubefs/proto"
+	"github.com/gorilla/mux"
 )
 
 const (
 	stsAkPrefix = "STS"
 	stsSep      = ";;sts;;"
 
-	stsActionKey          = "Action"
-	stsActionValue        = "GetFederationToken"
-	stsPolicyKey          = "Policy"
-	stsNameKey            = "Name"
-	stsDurationSecondsKey = "DurationSeconds"
+	stsActionKey           = "Action"
+	stsActionValue         = "GetFederationToken"
+	stsPolicyKey           = "Policy"
+	stsNameKey             = "Name"
+	stsDurationSecondsKey  = "DurationSeconds"
+	stsRoleArnKey          = "RoleArn"
+	stsRoleSessionNameKey  = "RoleSessionName"
+	stsWebIdentityTokenKey = "WebIdentityToken"
+
+	stsAssumeRoleValue                = "AssumeRole"
+	stsAssumeRoleWithWebIdentityValue = "AssumeRoleWithWebIdentity"
+
+	// the form of the STS requests is limited, the web identity token is up to 20000 characters
+	maxSTSFormSize = 64 << 10
 )
 
 type FederationTokenResponse struct {
@@ -64,6 +78,40 @@ type FederatedCredentials struct {
 	Expiration      string `xml:"Expiration"`
 }
 
+type AssumeRoleResponse struct {
+	XMLName          *xml.Name         `xml:"AssumeRoleResponse"`
+	AssumeRoleResult *AssumeRoleResult `xml:"AssumeRoleResult"`
+	ResponseMetadata struct {
+		RequestID string `xml:"RequestId,omitempty"`
+	} `xml:"ResponseMetadata,omitempty"`
+}
+
+type AssumeRoleResult struct {
+	Credentials     *FederatedCredentials `xml:"Credentials"`
+	AssumedRoleUser *AssumedRoleUser      `xml:"AssumedRoleUser"`
+}
+
+type AssumeRoleWithWebIdentityResponse struct {
+	XMLName                         *xml.Name                        `xml:"AssumeRoleWithWebIdentityResponse"`
+	AssumeRoleWithWebIdentityResult *AssumeRoleWithWebIdentityResult `xml:"AssumeRoleWithWebIdentityResult"`
+	ResponseMetadata                struct {
+		RequestID string `xml:"RequestId,omitempty"`
+	} `xml:"ResponseMetadata,omitempty"`
+}
+
+type AssumeRoleWithWebIdentityResult struct {
+	Credentials                 *FederatedCredentials `xml:"Credentials"`
+	AssumedRoleUser             *AssumedRoleUser      `xml:"AssumedRoleUser"`
+	SubjectFromWebIdentityToken string                `xml:"SubjectFromWebIdentityToken"`
+	Provider                    string                `xml:"Provider"`
+	Audience                    string                `xml:"Audience,omitempty"`
+}
+
+type AssumedRoleUser struct {
+	Arn           string `xml:"Arn"`
+	AssumedRoleId string `xml:"AssumedRoleId"`
+}
+
 func EncodeFedSessionToken(ownerAk, ownerSk, fedAk, fedSk, name, policy, expireUnix string) (token string, err error) {
 	encoding, err := NewStsEncoding(fedAk, ownerSk)
 	if err != nil {
@@ -138,6 +186,36 @@ func DecodeFedSessionToken(fedAk, session string, getUserInfo func(ak string) (*
 	return &FedDecodeResult{UserInfo: userInfo, FedSK: fedSk, Policy: &policy}, nil
 }
 
+// stsActionMatcher matches the STS requests by the action, which is carried in the url query or the
+// url-encoded form. The form is read in advance and the request body is restored for the signature.
+func stsActionMatcher(action string) mux.MatcherFunc {
+	return func(r *http.Request, rm *mux.RouteMatch) bool {
+		return getSTSAction(r) == action
+	}
+}
+
+func getSTSAction(r *http.Request) string {
+	if action := r.URL.Query().Get(stsActionKey); action != "" {
+		return action
+	}
+	if !strings.HasPrefix(r.Header.Get(ContentType), ValueFormURLEncoded) || r.Body == nil {
+		return ""
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxSTSFormSize))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+	if err != nil {
+		return ""
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return ""
+	}
+	return form.Get(stsActionKey)
+}
+
 func NewStsEncoding(block, key string) (*StsEncoding, error) {
 	bk, err := aes.NewCipher(MakeSha256([]byte(block)))
 	if err != nil {
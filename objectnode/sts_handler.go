@@ -19,8 +19,10 @@ import (
 	"net/http"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/cubefs/cubefs/proto"
 	"github.com/cubefs/cubefs/util"
 	"github.com/cubefs/cubefs/util/log"
 )
@@ -112,3 +114,238 @@ func (o *ObjectNode) getFederationTokenHandler(w http.ResponseWriter, r *http.Re
 
 	writeSuccessResponseXML(w, response)
 }
+
+type assumeRoleRequest struct {
+	account         string
+	roleName        string
+	sessionName     string
+	durationSeconds int64
+}
+
+func parseAssumeRoleRequest(r *http.Request) (*assumeRoleRequest, *ErrorCode) {
+	// the session policy is not supported, the permissions are granted by the role only
+	if r.FormValue(stsPolicyKey) != "" {
+		return nil, UnsupportedOperation
+	}
+	account, roleName, err := parseRoleArn(r.FormValue(stsRoleArnKey))
+	if err != nil {
+		return nil, InvalidArgument
+	}
+	sessionName := r.FormValue(stsRoleSessionNameKey)
+	matched, _ := regexp.MatchString(`^[\w+=,.@-]*$`, sessionName)
+	if len(sessionName) < 2 || len(sessionName) > 64 || !matched {
+		return nil, InvalidArgument
+	}
+	durationSeconds := int64(3600)
+	if seconds := r.FormValue(stsDurationSecondsKey); seconds != "" {
+		if durationSeconds, err = strconv.ParseInt(seconds, 10, 64); err != nil || durationSeconds < 900 {
+			return nil, InvalidArgument
+		}
+	}
+	return &assumeRoleRequest{
+		account:         account,
+		roleName:        roleName,
+		sessionName:     sessionName,
+		durationSeconds: durationSeconds,
+	}, nil
+}
+
+// The role is loaded from master every time, so that the change of the role takes effect immediately.
+func (o *ObjectNode) loadAssumedRole(req *assumeRoleRequest) (role *proto.RoleInfo, trust *TrustPolicy, err error) {
+	if role, err = o.mc.UserAPI().GetRoleInfo(req.roleName); err != nil {
+		if err == proto.ErrRoleNotExists {
+			err = AccessDenied
+		}
+		return
+	}
+	if req.account != "" && req.account != role.UserID {
+		return nil, nil, AccessDenied
+	}
+	if req.durationSeconds > role.MaxSessionDuration {
+		return nil, nil, InvalidArgument
+	}
+	if trust, err = ParseTrustPolicy(role.TrustPolicy); err != nil {
+		return nil, nil, fmt.Errorf("parse trust policy of role %v: %v", role.RoleName, err)
+	}
+	return
+}
+
+// issueRoleCredentials issues the temporary credentials of the role session, the credentials act
+// on behalf of the role owner and are limited by the permission policy of the role, the same as the
+// federation token.
+func (o *ObjectNode) issueRoleCredentials(role *proto.RoleInfo, req *assumeRoleRequest) (
+	cred *FederatedCredentials, roleUser *AssumedRoleUser, err error,
+) {
+	if _, err = ParsePolicyV2Config(role.PermissionPolicy); err != nil {
+		return nil, nil, fmt.Errorf("parse permission policy of role %v: %v", role.RoleName, err)
+	}
+	var owner *proto.UserInfo
+	if owner, err = o.mc.UserAPI().GetUserInfo(role.UserID); err != nil {
+		return
+	}
+	now := time.Now().UTC()
+	expireUnixStr := fmt.Sprint(now.Unix() + req.durationSeconds)
+	fedAk := stsAkPrefix + util.RandomString(13, util.Numeric|util.LowerLetter|util.UpperLetter)
+	fedSk := util.RandomString(32, util.Numeric|util.LowerLetter|util.UpperLetter)
+	sessionToken, err := EncodeFedSessionToken(owner.AccessKey, owner.SecretKey, fedAk, fedSk, req.sessionName,
+		role.PermissionPolicy, expireUnixStr)
+	if err != nil {
+		return
+	}
+	cred = &FederatedCredentials{
+		AccessKeyId:     fedAk,
+		SecretAccessKey: fedSk,
+		SessionToken:    sessionToken,
+		Expiration:      now.Add(time.Duration(req.durationSeconds) * time.Second).Format(time.RFC3339),
+	}
+	roleUser = &AssumedRoleUser{
+		Arn:           fmt.Sprintf("arn:aws:sts::%s:assumed-role/%s/%s", role.UserID, role.RoleName, req.sessionName),
+		AssumedRoleId: fmt.Sprintf("%s:%s", role.RoleName, req.sessionName),
+	}
+	return
+}
+
+// https://docs.aws.amazon.com/STS/latest/APIReference/API_AssumeRole.html
+func (o *ObjectNode) assumeRoleHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err error
+		erc *ErrorCode
+	)
+	defer func() {
+		o.errorResponse(w, r, err, erc)
+	}()
+	// request param check
+	if token := getSecurityToken(r); token != "" {
+		erc = AccessDeniedBySTS
+		return
+	}
+	param := ParseRequestParam(r)
+	if param.AccessKey() == "" {
+		erc = AccessDenied
+		return
+	}
+	var req *assumeRoleRequest
+	if req, erc = parseAssumeRoleRequest(r); erc != nil {
+		log.LogErrorf("assumeRoleHandler: invalid request: requestID(%v) role(%v) session(%v) err(%v)",
+			GetRequestID(r), r.FormValue(stsRoleArnKey), r.FormValue(stsRoleSessionNameKey), erc)
+		return
+	}
+	user, err := o.getUserInfoByAccessKeyV2(param.AccessKey())
+	if err != nil {
+		log.LogErrorf("assumeRoleHandler: get user info fail: requestID(%v) accessKey(%v) err(%v)",
+			GetRequestID(r), param.AccessKey(), err)
+		return
+	}
+	role, trust, err := o.loadAssumedRole(req)
+	if err != nil {
+		log.LogErrorf("assumeRoleHandler: load role fail: requestID(%v) role(%v) err(%v)",
+			GetRequestID(r), req.roleName, err)
+		return
+	}
+	principals := []string{user.UserID, fmt.Sprintf("arn:aws:iam::%s:root", user.UserID)}
+	values := map[string][]string{"aws:username": {user.UserID}}
+	if !trust.IsAllowed(stsAssumeRoleAction, trustPrincipalAWS, principals, values) {
+		log.LogErrorf("assumeRoleHandler: not allowed by trust policy: requestID(%v) role(%v) user(%v)",
+			GetRequestID(r), role.RoleName, user.UserID)
+		erc = AccessDenied
+		return
+	}
+	cred, roleUser, err := o.issueRoleCredentials(role, req)
+	if err != nil {
+		log.LogErrorf("assumeRoleHandler: issue credentials fail: requestID(%v) role(%v) err(%v)",
+			GetRequestID(r), role.RoleName, err)
+		return
+	}
+	// response result return
+	result := AssumeRoleResponse{
+		AssumeRoleResult: &AssumeRoleResult{
+			Credentials:     cred,
+			AssumedRoleUser: roleUser,
+		},
+	}
+	result.ResponseMetadata.RequestID = GetRequestID(r)
+	response, err := MarshalXMLEntity(&result)
+	if err != nil {
+		log.LogErrorf("assumeRoleHandler: xml marshal result fail: requestID(%v) err(%v)", GetRequestID(r), err)
+		return
+	}
+
+	writeSuccessResponseXML(w, response)
+}
+
+// https://docs.aws.amazon.com/STS/latest/APIReference/API_AssumeRoleWithWebIdentity.html
+func (o *ObjectNode) assumeRoleWithWebIdentityHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err error
+		erc *ErrorCode
+	)
+	defer func() {
+		o.errorResponse(w, r, err, erc)
+	}()
+	// request param check
+	var req *assumeRoleRequest
+	if req, erc = parseAssumeRoleRequest(r); erc != nil {
+		log.LogErrorf("assumeRoleWithWebIdentityHandler: invalid request: requestID(%v) role(%v) session(%v) err(%v)",
+			GetRequestID(r), r.FormValue(stsRoleArnKey), r.FormValue(stsRoleSessionNameKey), erc)
+		return
+	}
+	token := r.FormValue(stsWebIdentityTokenKey)
+	if len(token) < 4 || len(token) > 20000 {
+		erc = InvalidIdentityToken
+		return
+	}
+	identity, err := o.oidcProviders.Verify(token, time.Now())
+	if err != nil {
+		log.LogErrorf("assumeRoleWithWebIdentityHandler: verify token fail: requestID(%v) role(%v) err(%v)",
+			GetRequestID(r), req.roleName, err)
+		if err == errWebIdentityTokenExpired {
+			err, erc = nil, ExpiredIdentityToken
+		} else {
+			err, erc = nil, InvalidIdentityToken
+		}
+		return
+	}
+	role, trust, err := o.loadAssumedRole(req)
+	if err != nil {
+		log.LogErrorf("assumeRoleWithWebIdentityHandler: load role fail: requestID(%v) role(%v) err(%v)",
+			GetRequestID(r), req.roleName, err)
+		return
+	}
+	provider := identity.Provider
+	principals := []string{
+		provider.Issuer,
+		provider.Name(),
+		fmt.Sprintf("arn:aws:iam::%s:oidc-provider/%s", role.UserID, provider.Name()),
+	}
+	if !trust.IsAllowed(stsAssumeRoleWithWebIdentityAction, trustPrincipalFederated, principals, identity.ConditionValues()) {
+		log.LogErrorf("assumeRoleWithWebIdentityHandler: not allowed by trust policy: requestID(%v) role(%v) "+
+			"issuer(%v) subject(%v)", GetRequestID(r), role.RoleName, provider.Issuer, identity.Subject)
+		erc = AccessDenied
+		return
+	}
+	cred, roleUser, err := o.issueRoleCredentials(role, req)
+	if err != nil {
+		log.LogErrorf("assumeRoleWithWebIdentityHandler: issue credentials fail: requestID(%v) role(%v) err(%v)",
+			GetRequestID(r), role.RoleName, err)
+		return
+	}
+	// response result return
+	result := AssumeRoleWithWebIdentityResponse{
+		AssumeRoleWithWebIdentityResult: &AssumeRoleWithWebIdentityResult{
+			Credentials:                 cred,
+			AssumedRoleUser:             roleUser,
+			SubjectFromWebIdentityToken: identity.Subject,
+			Provider:                    provider.Issuer,
+			Audience:                    strings.Join(identity.Audience, ","),
+		},
+	}
+	result.ResponseMetadata.RequestID = GetRequestID(r)
+	response, err := MarshalXMLEntity(&result)
+	if err != nil {
+		log.LogErrorf("assumeRoleWithWebIdentityHandler: xml marshal result fail: requestID(%v) err(%v)",
+			GetRequestID(r), err)
+		return
+	}
+
+	writeSuccessResponseXML(w, response)
+}
@@ -0,0 +1,374 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// the tolerated clock skew between the identity provider and ObjectNode
+const oidcClockSkew = time.Minute
+
+var errWebIdentityTokenExpired = errors.New("web identity token expired")
+
+// OIDCProviderConfig is the configuration of an OpenID Connect identity provider trusted by
+// AssumeRoleWithWebIdentity. The JSON web key set of the provider is loaded from the local file,
+// and reloaded once the file is modified, so the signing keys can be rotated without restart.
+type OIDCProviderConfig struct {
+	Issuer    string   `json:"issuer"`
+	JWKSFile  string   `json:"jwksFile"`
+	ClientIDs []string `json:"clientIds"` // the allowed audiences, any audience is allowed if empty
+}
+
+type OIDCProvider struct {
+	Issuer    string
+	ClientIDs []string
+
+	jwksFile string
+	mu       sync.RWMutex
+	modTime  time.Time
+	keys     map[string]crypto.PublicKey // key id -> public key
+}
+
+func NewOIDCProvider(conf OIDCProviderConfig) (*OIDCProvider, error) {
+	if conf.Issuer == "" || conf.JWKSFile == "" {
+		return nil, errors.New("issuer and jwksFile must be specified")
+	}
+	p := &OIDCProvider{Issuer: conf.Issuer, ClientIDs: conf.ClientIDs, jwksFile: conf.JWKSFile}
+	if _, err := p.publicKeys(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Name returns the issuer without the scheme, which is used as the prefix of the condition keys
+// and the name of the federated principal in trust policies, e.g. "oidc.k8s.cube.io/id/1".
+func (p *OIDCProvider) Name() string {
+	name := strings.TrimPrefix(strings.TrimPrefix(p.Issuer, "https://"), "http://")
+	return strings.TrimSuffix(name, "/")
+}
+
+func (p *OIDCProvider) publicKeys() (map[string]crypto.PublicKey, error) {
+	info, err := os.Stat(p.jwksFile)
+	if err != nil {
+		return nil, err
+	}
+	p.mu.RLock()
+	keys, modTime := p.keys, p.modTime
+	p.mu.RUnlock()
+	if keys != nil && modTime.Equal(info.ModTime()) {
+		return keys, nil
+	}
+
+	data, err := os.ReadFile(p.jwksFile)
+	if err != nil {
+		return nil, err
+	}
+	if keys, err = parseJWKS(data); err != nil {
+		return nil, fmt.Errorf("parse jwks file %v: %v", p.jwksFile, err)
+	}
+	p.mu.Lock()
+	p.keys, p.modTime = keys, info.ModTime()
+	p.mu.Unlock()
+	return keys, nil
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]crypto.PublicKey)
+	for _, jwk := range jwks.Keys {
+		// the encryption keys are never used to sign tokens
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("key(%v): %v", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no signing key")
+	}
+	return keys, nil
+}
+
+func (jwk *jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeJWKInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeJWKInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid rsa exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %v", jwk.Crv)
+		}
+		x, err := decodeJWKInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeJWKInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %v", jwk.Kty)
+	}
+}
+
+func decodeJWKInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New("empty integer")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+// OIDCProviders indexes the trusted identity providers by issuer.
+type OIDCProviders map[string]*OIDCProvider
+
+func NewOIDCProviders(confs []OIDCProviderConfig) (OIDCProviders, error) {
+	providers := make(OIDCProviders)
+	for _, conf := range confs {
+		p, err := NewOIDCProvider(conf)
+		if err != nil {
+			return nil, fmt.Errorf("oidc provider(%v): %v", conf.Issuer, err)
+		}
+		providers[p.Issuer] = p
+	}
+	return providers, nil
+}
+
+// WebIdentity is the identity claimed by a verified web identity token.
+type WebIdentity struct {
+	Provider  *OIDCProvider
+	Subject   string
+	Audience  []string
+	ExpiresAt time.Time
+	Claims    map[string]interface{}
+}
+
+// ConditionValues returns the values of the condition keys of the trust policy, which are
+// named as "<provider name>:<claim>", e.g. "oidc.k8s.cube.io:sub".
+func (id *WebIdentity) ConditionValues() map[string][]string {
+	name := id.Provider.Name()
+	values := map[string][]string{
+		name + ":sub": {id.Subject},
+		name + ":aud": id.Audience,
+	}
+	if azp, ok := id.Claims["azp"].(string); ok {
+		values[name+":azp"] = []string{azp}
+	}
+	return values
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// Verify verifies the signature and the registered claims of the JWT token, the token must be
+// issued by one of the trusted providers to one of the allowed client ids of the provider.
+func (ps OIDCProviders) Verify(token string, now time.Time) (*WebIdentity, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+	var header jwtHeader
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("decode header: %v", err)
+	}
+	claims := make(map[string]interface{})
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("decode claims: %v", err)
+	}
+
+	issuer, _ := claims["iss"].(string)
+	provider, ok := ps[issuer]
+	if !ok {
+		return nil, fmt.Errorf("untrusted issuer %v", issuer)
+	}
+	keys, err := provider.publicKeys()
+	if err != nil {
+		return nil, fmt.Errorf("load keys of %v: %v", issuer, err)
+	}
+	key, ok := keys[header.Kid]
+	// the key id can be omitted if the provider has only one signing key
+	if !ok && header.Kid == "" && len(keys) == 1 {
+		for _, k := range keys {
+			key, ok = k, true
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %v", header.Kid)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("decode signature: %v", err)
+	}
+	if err = verifyJWTSignature(header.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	id := &WebIdentity{Provider: provider, Claims: claims}
+	if id.Subject, _ = claims["sub"].(string); id.Subject == "" {
+		return nil, errors.New("missing sub claim")
+	}
+	switch aud := claims["aud"].(type) {
+	case string:
+		id.Audience = []string{aud}
+	case []interface{}:
+		for _, v := range aud {
+			if s, ok := v.(string); ok {
+				id.Audience = append(id.Audience, s)
+			}
+		}
+	}
+	if len(provider.ClientIDs) > 0 &&
+		CreateStringSet(provider.ClientIDs...).Intersection(CreateStringSet(id.Audience...)).IsEmpty() {
+		return nil, fmt.Errorf("audience %v is not allowed", id.Audience)
+	}
+	exp, ok := claims["exp"].(json.Number)
+	if !ok {
+		return nil, errors.New("missing exp claim")
+	}
+	expUnix, err := exp.Int64()
+	if err != nil {
+		return nil, fmt.Errorf("invalid exp claim: %v", err)
+	}
+	id.ExpiresAt = time.Unix(expUnix, 0)
+	if now.After(id.ExpiresAt.Add(oidcClockSkew)) {
+		return nil, errWebIdentityTokenExpired
+	}
+	if nbf, ok := claims["nbf"].(json.Number); ok {
+		nbfUnix, err := nbf.Int64()
+		if err != nil {
+			return nil, fmt.Errorf("invalid nbf claim: %v", err)
+		}
+		if now.Add(oidcClockSkew).Before(time.Unix(nbfUnix, 0)) {
+			return nil, errors.New("token is not valid yet")
+		}
+	}
+	return id, nil
+}
+
+func decodeJWTPart(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// the symmetric and unsecured algorithms can never be trusted
+var jwtAlgorithmHashes = map[string]crypto.Hash{
+	"RS256": crypto.SHA256, "RS384": crypto.SHA384, "RS512": crypto.SHA512,
+	"PS256": crypto.SHA256, "PS384": crypto.SHA384, "PS512": crypto.SHA512,
+	"ES256": crypto.SHA256, "ES384": crypto.SHA384, "ES512": crypto.SHA512,
+}
+
+func verifyJWTSignature(alg string, key crypto.PublicKey, signed string, signature []byte) error {
+	hash, ok := jwtAlgorithmHashes[alg]
+	if !ok {
+		return fmt.Errorf("unsupported algorithm %v", alg)
+	}
+	h := hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+
+	switch alg[:2] {
+	case "RS":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok || rsa.VerifyPKCS1v15(pub, hash, digest, signature) != nil {
+			return errors.New("invalid signature")
+		}
+	case "PS":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok || rsa.VerifyPSS(pub, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}) != nil {
+			return errors.New("invalid signature")
+		}
+	default:
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return errors.New("invalid signature")
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return errors.New("invalid signature")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return errors.New("invalid signature")
+		}
+	}
+	return nil
+}
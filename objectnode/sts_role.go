@@ -0,0 +1,192 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	stsAssumeRoleAction                = "sts:AssumeRole"
+	stsAssumeRoleWithWebIdentityAction = "sts:AssumeRoleWithWebIdentity"
+
+	trustPrincipalAWS       = "AWS"
+	trustPrincipalFederated = "Federated"
+
+	trustStringEquals    = "StringEquals"
+	trustStringNotEquals = "StringNotEquals"
+	trustStringLike      = "StringLike"
+	trustStringNotLike   = "StringNotLike"
+)
+
+// TrustPolicy is the trust policy of a role, which specifies the principals who can assume the role.
+// The users are specified by the "AWS" principal with user id, and the identity providers are
+// specified by the "Federated" principal with issuer, the claims of the web identity token can be
+// checked by the conditions.
+// Example:
+//
+//	{
+//	  "Version": "2012-10-17",
+//	  "Statement": [{
+//	    "Effect": "Allow",
+//	    "Principal": {"Federated": "oidc.k8s.cube.io"},
+//	    "Action": "sts:AssumeRoleWithWebIdentity",
+//	    "Condition": {"StringEquals": {"oidc.k8s.cube.io:sub": "system:serviceaccount:default:app"}}
+//	  }]
+//	}
+type TrustPolicy struct {
+	Version    string           `json:"Version"`
+	Statements []TrustStatement `json:"Statement"`
+}
+
+type TrustStatement struct {
+	Sid       string                          `json:"Sid,omitempty"`
+	Effect    string                          `json:"Effect"`
+	Principal Principal                       `json:"Principal"`
+	Action    StringSet                       `json:"Action"`
+	Condition map[string]map[string]StringSet `json:"Condition,omitempty"`
+}
+
+func ParseTrustPolicy(data string) (*TrustPolicy, error) {
+	policy := new(TrustPolicy)
+	dec := json.NewDecoder(strings.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(policy); err != nil {
+		return nil, err
+	}
+	if policy.Version != defaultPolicyVersion {
+		return nil, errors.New("invalid version expecting 2012-10-17")
+	}
+	if len(policy.Statements) == 0 {
+		return nil, errors.New("statement cannot be empty")
+	}
+	for _, stmt := range policy.Statements {
+		if err := stmt.isValid(); err != nil {
+			return nil, err
+		}
+	}
+	return policy, nil
+}
+
+func (s *TrustStatement) isValid() error {
+	if s.Effect != Allow && s.Effect != Deny {
+		return errors.New("invalid effect")
+	}
+	if len(s.Principal) == 0 {
+		return errors.New("principal must not be empty")
+	}
+	for typ := range s.Principal {
+		if typ != trustPrincipalAWS && typ != trustPrincipalFederated {
+			return fmt.Errorf("invalid principal type %v", typ)
+		}
+	}
+	if s.Action.IsEmpty() {
+		return errors.New("action must not be empty")
+	}
+	for action := range s.Action {
+		if action != stsAssumeRoleAction && action != stsAssumeRoleWithWebIdentityAction && action != "sts:*" {
+			return fmt.Errorf("invalid action %v", action)
+		}
+	}
+	for op := range s.Condition {
+		switch op {
+		case trustStringEquals, trustStringNotEquals, trustStringLike, trustStringNotLike:
+		default:
+			return fmt.Errorf("invalid condition operator %v", op)
+		}
+	}
+	return nil
+}
+
+// IsAllowed checks whether the principal, identified by any of the names of the principal type,
+// is allowed to assume the role by the action. The explicit deny takes precedence over allow.
+func (p *TrustPolicy) IsAllowed(action, principalType string, principals []string, values map[string][]string) bool {
+	var allow bool
+	for _, stmt := range p.Statements {
+		if stmt.match(action, principalType, principals, values) {
+			if stmt.Effect == Deny {
+				return false
+			}
+			allow = true
+		}
+	}
+	return allow
+}
+
+func (s *TrustStatement) match(action, principalType string, principals []string, values map[string][]string) bool {
+	if !s.Action.Contains(action) && !s.Action.Contains("sts:*") {
+		return false
+	}
+	names, ok := s.Principal[principalType]
+	if !ok {
+		return false
+	}
+	if !names.Contains("*") && names.Intersection(CreateStringSet(principals...)).IsEmpty() {
+		return false
+	}
+	for op, conditions := range s.Condition {
+		for key, expects := range conditions {
+			if !evaluateTrustCondition(op, expects, values[key]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// The condition matches if any of the request values matches any of the expected values, and the
+// negated operators match if none of them matches.
+func evaluateTrustCondition(op string, expects StringSet, actuals []string) bool {
+	matched := false
+	for _, actual := range actuals {
+		for expect := range expects {
+			switch op {
+			case trustStringEquals, trustStringNotEquals:
+				matched = actual == expect
+			case trustStringLike, trustStringNotLike:
+				matched = Match(expect, actual)
+			}
+			if matched {
+				break
+			}
+		}
+		if matched {
+			break
+		}
+	}
+	if op == trustStringNotEquals || op == trustStringNotLike {
+		return !matched
+	}
+	return matched
+}
+
+// parseRoleArn parses the role name from the role arn, e.g. "arn:aws:iam::<user id>:role/<name>".
+// The account of the arn is the user id of the role owner, which may be empty.
+func parseRoleArn(arn string) (account, roleName string, err error) {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) != 6 || parts[0] != "arn" || parts[2] != "iam" || !strings.HasPrefix(parts[5], "role/") {
+		return "", "", fmt.Errorf("invalid role arn %v", arn)
+	}
+	resource := strings.TrimPrefix(parts[5], "role/")
+	// the path of the role is ignored
+	roleName = resource[strings.LastIndex(resource, "/")+1:]
+	if roleName == "" {
+		return "", "", fmt.Errorf("invalid role arn %v", arn)
+	}
+	return parts[4], roleName, nil
+}
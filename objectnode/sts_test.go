@@ -15,9 +15,21 @@
 package objectnode
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -61,3 +73,168 @@ func testGetUserInfo(ak string) (*proto.UserInfo, error) {
 	}
 	return &proto.UserInfo{UserID: testUser, AccessKey: testOwnerAK, SecretKey: testOwnerSK}, nil
 }
+
+func testSignJWT(t *testing.T, key crypto.Signer, alg, kid string, claims map[string]interface{}) string {
+	header, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	var signature []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		require.NoError(t, err)
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestVerifyWebIdentityToken(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	jwks := fmt.Sprintf(`{"keys":[`+
+		`{"kty":"RSA","use":"sig","kid":"rsa","n":"%s","e":"AQAB"},`+
+		`{"kty":"EC","kid":"ec","crv":"P-256","x":"%s","y":"%s"},`+
+		`{"kty":"RSA","use":"enc","kid":"enc","n":"%s","e":"AQAB"}]}`,
+		base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+		base64.RawURLEncoding.EncodeToString(ecKey.X.FillBytes(make([]byte, 32))),
+		base64.RawURLEncoding.EncodeToString(ecKey.Y.FillBytes(make([]byte, 32))),
+		base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()))
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(jwksFile, []byte(jwks), 0o600))
+
+	issuer := "https://oidc.k8s.cube.io"
+	providers, err := NewOIDCProviders([]OIDCProviderConfig{
+		{Issuer: issuer, JWKSFile: jwksFile, ClientIDs: []string{"sts.amazonaws.com"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "oidc.k8s.cube.io", providers[issuer].Name())
+
+	now := time.Now()
+	claims := func() map[string]interface{} {
+		return map[string]interface{}{
+			"iss": issuer,
+			"sub": "system:serviceaccount:default:app",
+			"aud": []string{"sts.amazonaws.com"},
+			"exp": now.Add(time.Hour).Unix(),
+			"nbf": now.Add(-time.Minute).Unix(),
+		}
+	}
+
+	id, err := providers.Verify(testSignJWT(t, rsaKey, "RS256", "rsa", claims()), now)
+	require.NoError(t, err)
+	require.Equal(t, "system:serviceaccount:default:app", id.Subject)
+	require.Equal(t, []string{"sts.amazonaws.com"}, id.Audience)
+	require.Equal(t, []string{"system:serviceaccount:default:app"}, id.ConditionValues()["oidc.k8s.cube.io:sub"])
+
+	_, err = providers.Verify(testSignJWT(t, ecKey, "ES256", "ec", claims()), now)
+	require.NoError(t, err)
+
+	// the key of other use is never trusted
+	_, err = providers.Verify(testSignJWT(t, rsaKey, "RS256", "enc", claims()), now)
+	require.Error(t, err)
+	// the signature must be verified by the key of the key id
+	_, err = providers.Verify(testSignJWT(t, rsaKey, "ES256", "ec", claims()), now)
+	require.Error(t, err)
+	token := testSignJWT(t, rsaKey, "RS256", "rsa", claims())
+	_, err = providers.Verify(token[:len(token)-4]+"AAAA", now)
+	require.Error(t, err)
+	_, err = providers.Verify(testSignJWT(t, rsaKey, "none", "rsa", claims()), now)
+	require.Error(t, err)
+
+	c := claims()
+	c["aud"] = "other"
+	_, err = providers.Verify(testSignJWT(t, rsaKey, "RS256", "rsa", c), now)
+	require.Error(t, err)
+	c = claims()
+	c["iss"] = "https://other.cube.io"
+	_, err = providers.Verify(testSignJWT(t, rsaKey, "RS256", "rsa", c), now)
+	require.Error(t, err)
+	c = claims()
+	c["exp"] = now.Add(-time.Hour).Unix()
+	_, err = providers.Verify(testSignJWT(t, rsaKey, "RS256", "rsa", c), now)
+	require.Equal(t, errWebIdentityTokenExpired, err)
+	c = claims()
+	c["nbf"] = now.Add(time.Hour).Unix()
+	_, err = providers.Verify(testSignJWT(t, rsaKey, "RS256", "rsa", c), now)
+	require.Error(t, err)
+	c = claims()
+	delete(c, "sub")
+	_, err = providers.Verify(testSignJWT(t, rsaKey, "RS256", "rsa", c), now)
+	require.Error(t, err)
+}
+
+func TestTrustPolicy(t *testing.T) {
+	_, err := ParseTrustPolicy(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":"user"},"Action":"s3:GetObject"}]}`)
+	require.Error(t, err)
+	_, err = ParseTrustPolicy(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"Service":"ec2"},"Action":"sts:AssumeRole"}]}`)
+	require.Error(t, err)
+
+	policy, err := ParseTrustPolicy(`{"Version":"2012-10-17","Statement":[` +
+		`{"Effect":"Allow","Principal":{"AWS":["user1","user2"]},"Action":"sts:AssumeRole"},` +
+		`{"Effect":"Deny","Principal":{"AWS":"*"},"Action":"sts:AssumeRole","Condition":{"StringEquals":{"aws:username":"user2"}}},` +
+		`{"Effect":"Allow","Principal":{"Federated":"oidc.k8s.cube.io"},"Action":"sts:AssumeRoleWithWebIdentity",` +
+		`"Condition":{"StringLike":{"oidc.k8s.cube.io:sub":"system:serviceaccount:default:*"},"StringEquals":{"oidc.k8s.cube.io:aud":"sts.amazonaws.com"}}}]}`)
+	require.NoError(t, err)
+
+	user := func(uid string) (string, string, []string, map[string][]string) {
+		return stsAssumeRoleAction, trustPrincipalAWS, []string{uid}, map[string][]string{"aws:username": {uid}}
+	}
+	require.True(t, policy.IsAllowed(user("user1")))
+	require.False(t, policy.IsAllowed(user("user2")))
+	require.False(t, policy.IsAllowed(user("user3")))
+
+	federated := []string{"https://oidc.k8s.cube.io", "oidc.k8s.cube.io"}
+	values := map[string][]string{
+		"oidc.k8s.cube.io:sub": {"system:serviceaccount:default:app"},
+		"oidc.k8s.cube.io:aud": {"other", "sts.amazonaws.com"},
+	}
+	require.True(t, policy.IsAllowed(stsAssumeRoleWithWebIdentityAction, trustPrincipalFederated, federated, values))
+	require.False(t, policy.IsAllowed(stsAssumeRoleAction, trustPrincipalFederated, federated, values))
+	require.False(t, policy.IsAllowed(stsAssumeRoleWithWebIdentityAction, trustPrincipalFederated,
+		[]string{"https://other.cube.io"}, values))
+	values["oidc.k8s.cube.io:sub"] = []string{"system:serviceaccount:kube-system:app"}
+	require.False(t, policy.IsAllowed(stsAssumeRoleWithWebIdentityAction, trustPrincipalFederated, federated, values))
+}
+
+func TestParseRoleArn(t *testing.T) {
+	account, roleName, err := parseRoleArn("arn:aws:iam::user:role/path/app")
+	require.NoError(t, err)
+	require.Equal(t, "user", account)
+	require.Equal(t, "app", roleName)
+
+	account, roleName, err = parseRoleArn("arn:aws:iam:::role/app")
+	require.NoError(t, err)
+	require.Equal(t, "", account)
+	require.Equal(t, "app", roleName)
+
+	for _, arn := range []string{"app", "arn:aws:iam::user:user/app", "arn:aws:s3::user:role/app", "arn:aws:iam::user:role/"} {
+		_, _, err = parseRoleArn(arn)
+		require.Error(t, err, arn)
+	}
+}
+
+func TestGetSTSAction(t *testing.T) {
+	form := "Action=AssumeRoleWithWebIdentity&RoleArn=arn%3Aaws%3Aiam%3A%3A%3Arole%2Fapp"
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form))
+	req.Header.Set(ContentType, ValueFormURLEncoded+"; charset=utf-8")
+	require.Equal(t, stsAssumeRoleWithWebIdentityValue, getSTSAction(req))
+	// the body is restored for the signature and the handler
+	require.Equal(t, stsAssumeRoleWithWebIdentityValue, getSTSAction(req))
+	require.Equal(t, "arn:aws:iam:::role/app", req.FormValue(stsRoleArnKey))
+
+	req = httptest.NewRequest(http.MethodPost, "/?Action=AssumeRole", nil)
+	require.Equal(t, stsAssumeRoleValue, getSTSAction(req))
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form))
+	require.Equal(t, "", getSTSAction(req))
+}
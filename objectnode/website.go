@@ -0,0 +1,245 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+// https://docs.aws.amazon.com/AmazonS3/latest/userguide/WebsiteHosting.html
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	MaxWebsiteSize         = 1 << 17 // 128KB
+	MaxWebsiteRoutingRules = 50
+)
+
+type WebsiteConfiguration struct {
+	XMLName               xml.Name              `xml:"WebsiteConfiguration" json:"xml_name"`
+	ErrorDocument         *WebsiteErrorDocument `xml:"ErrorDocument,omitempty" json:"error_document,omitempty"`
+	IndexDocument         *WebsiteIndexDocument `xml:"IndexDocument,omitempty" json:"index_document,omitempty"`
+	RedirectAllRequestsTo *WebsiteRedirectAll   `xml:"RedirectAllRequestsTo,omitempty" json:"redirect_all_requests_to,omitempty"`
+	RoutingRules          *WebsiteRoutingRules  `xml:"RoutingRules,omitempty" json:"routing_rules,omitempty"`
+}
+
+type WebsiteErrorDocument struct {
+	Key string `xml:"Key" json:"key"`
+}
+
+type WebsiteIndexDocument struct {
+	Suffix string `xml:"Suffix" json:"suffix"`
+}
+
+type WebsiteRedirectAll struct {
+	HostName string `xml:"HostName" json:"host_name"`
+	Protocol string `xml:"Protocol,omitempty" json:"protocol,omitempty"`
+}
+
+type WebsiteRoutingRules struct {
+	RoutingRule []*WebsiteRoutingRule `xml:"RoutingRule" json:"routing_rule"`
+}
+
+type WebsiteRoutingRule struct {
+	Condition *WebsiteCondition `xml:"Condition,omitempty" json:"condition,omitempty"`
+	Redirect  *WebsiteRedirect  `xml:"Redirect" json:"redirect"`
+}
+
+type WebsiteCondition struct {
+	HttpErrorCodeReturnedEquals string `xml:"HttpErrorCodeReturnedEquals,omitempty" json:"http_error_code_returned_equals,omitempty"`
+	KeyPrefixEquals             string `xml:"KeyPrefixEquals,omitempty" json:"key_prefix_equals,omitempty"`
+}
+
+type WebsiteRedirect struct {
+	HostName             string `xml:"HostName,omitempty" json:"host_name,omitempty"`
+	HttpRedirectCode     string `xml:"HttpRedirectCode,omitempty" json:"http_redirect_code,omitempty"`
+	Protocol             string `xml:"Protocol,omitempty" json:"protocol,omitempty"`
+	ReplaceKeyPrefixWith string `xml:"ReplaceKeyPrefixWith,omitempty" json:"replace_key_prefix_with,omitempty"`
+	ReplaceKeyWith       string `xml:"ReplaceKeyWith,omitempty" json:"replace_key_with,omitempty"`
+}
+
+func invalidWebsiteRequest(msg string) *ErrorCode {
+	return NewError("InvalidRequest", msg, http.StatusBadRequest)
+}
+
+func validWebsiteProtocol(protocol string) bool {
+	return protocol == "" || protocol == "http" || protocol == "https"
+}
+
+func (website *WebsiteConfiguration) validate() *ErrorCode {
+	if all := website.RedirectAllRequestsTo; all != nil {
+		if website.IndexDocument != nil || website.ErrorDocument != nil || website.RoutingRules != nil {
+			return invalidWebsiteRequest("RedirectAllRequestsTo cannot be provided in conjunction with other Routing/Redirect configuration.")
+		}
+		if all.HostName == "" {
+			return invalidWebsiteRequest("RedirectAllRequestsTo must have a HostName.")
+		}
+		if !validWebsiteProtocol(all.Protocol) {
+			return invalidWebsiteRequest("Invalid protocol, protocol can be http or https: " + all.Protocol)
+		}
+		return nil
+	}
+	if website.IndexDocument == nil || website.IndexDocument.Suffix == "" {
+		return invalidWebsiteRequest("A value for IndexDocument Suffix must be provided if RedirectAllRequestsTo is empty.")
+	}
+	if strings.Contains(website.IndexDocument.Suffix, "/") {
+		return invalidWebsiteRequest("The IndexDocument Suffix is not well formed: " + website.IndexDocument.Suffix)
+	}
+	if website.ErrorDocument != nil && website.ErrorDocument.Key == "" {
+		return invalidWebsiteRequest("The ErrorDocument Key must be provided.")
+	}
+	if website.RoutingRules == nil {
+		return nil
+	}
+	if len(website.RoutingRules.RoutingRule) == 0 {
+		return invalidWebsiteRequest("RoutingRules must have at least one RoutingRule.")
+	}
+	if len(website.RoutingRules.RoutingRule) > MaxWebsiteRoutingRules {
+		return invalidWebsiteRequest("The number of RoutingRules must not exceed 50.")
+	}
+	for _, rule := range website.RoutingRules.RoutingRule {
+		if err := rule.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rule *WebsiteRoutingRule) validate() *ErrorCode {
+	if rule.Condition != nil {
+		if rule.Condition.HttpErrorCodeReturnedEquals == "" && rule.Condition.KeyPrefixEquals == "" {
+			return invalidWebsiteRequest("Condition cannot be empty. To redirect all requests without a condition, the condition element shouldn't be present.")
+		}
+		if code := rule.Condition.HttpErrorCodeReturnedEquals; code != "" {
+			if c, err := strconv.Atoi(code); err != nil || c < 400 || c > 599 {
+				return invalidWebsiteRequest("The provided HTTP error code is not valid: " + code)
+			}
+		}
+	}
+	redirect := rule.Redirect
+	if redirect == nil {
+		return invalidWebsiteRequest("RoutingRule must have a Redirect.")
+	}
+	if redirect.HostName == "" && redirect.HttpRedirectCode == "" && redirect.Protocol == "" &&
+		redirect.ReplaceKeyPrefixWith == "" && redirect.ReplaceKeyWith == "" {
+		return invalidWebsiteRequest("Redirect cannot be empty.")
+	}
+	if redirect.ReplaceKeyPrefixWith != "" && redirect.ReplaceKeyWith != "" {
+		return invalidWebsiteRequest("You can only define ReplaceKeyPrefixWith or ReplaceKeyWith but not both.")
+	}
+	if code := redirect.HttpRedirectCode; code != "" {
+		if c, err := strconv.Atoi(code); err != nil || c < 300 || c > 399 {
+			return invalidWebsiteRequest("The provided HTTP redirect code is not valid: " + code)
+		}
+	}
+	if !validWebsiteProtocol(redirect.Protocol) {
+		return invalidWebsiteRequest("Invalid protocol, protocol can be http or https: " + redirect.Protocol)
+	}
+	return nil
+}
+
+// match reports whether the rule applies to the key. With errCode of zero only the rules
+// without an error code condition are checked, which are evaluated before the object is read.
+func (rule *WebsiteRoutingRule) match(key string, errCode int) bool {
+	cond := rule.Condition
+	if cond == nil {
+		return errCode == 0
+	}
+	if cond.HttpErrorCodeReturnedEquals == "" {
+		if errCode != 0 {
+			return false
+		}
+	} else if cond.HttpErrorCodeReturnedEquals != strconv.Itoa(errCode) {
+		return false
+	}
+	return strings.HasPrefix(key, cond.KeyPrefixEquals)
+}
+
+// indexKey resolves the key of "directory" requests to the index document.
+func (website *WebsiteConfiguration) indexKey(key string) string {
+	if website.IndexDocument == nil {
+		return key
+	}
+	if key == "" || strings.HasSuffix(key, "/") {
+		return key + website.IndexDocument.Suffix
+	}
+	return key
+}
+
+// redirect returns the location and status code the request of key should be redirected to,
+// an empty location means no redirection.
+func (website *WebsiteConfiguration) redirect(r *http.Request, key string, errCode int) (location string, code int) {
+	if all := website.RedirectAllRequestsTo; all != nil {
+		return websiteLocation(r, all.Protocol, all.HostName, key), http.StatusMovedPermanently
+	}
+	if website.RoutingRules == nil {
+		return
+	}
+	for _, rule := range website.RoutingRules.RoutingRule {
+		if !rule.match(key, errCode) {
+			continue
+		}
+		redirect := rule.Redirect
+		target := key
+		if redirect.ReplaceKeyWith != "" {
+			target = redirect.ReplaceKeyWith
+		} else if redirect.ReplaceKeyPrefixWith != "" {
+			var prefix string
+			if rule.Condition != nil {
+				prefix = rule.Condition.KeyPrefixEquals
+			}
+			target = redirect.ReplaceKeyPrefixWith + strings.TrimPrefix(key, prefix)
+		}
+		code = http.StatusMovedPermanently
+		if redirect.HttpRedirectCode != "" {
+			code, _ = strconv.Atoi(redirect.HttpRedirectCode)
+		}
+		return websiteLocation(r, redirect.Protocol, redirect.HostName, target), code
+	}
+	return
+}
+
+func websiteLocation(r *http.Request, protocol, host, key string) string {
+	if protocol == "" {
+		protocol = "http"
+		if r.TLS != nil {
+			protocol = "https"
+		}
+	}
+	if host == "" {
+		host = r.Host
+	}
+	return protocol + "://" + host + "/" + strings.TrimPrefix(key, "/")
+}
+
+func parseWebsiteConfig(bytes []byte) (website *WebsiteConfiguration, errCode *ErrorCode) {
+	website = &WebsiteConfiguration{}
+	if err := xml.Unmarshal(bytes, website); err != nil {
+		return nil, MalformedXML
+	}
+	if err := website.validate(); err != nil {
+		return nil, err
+	}
+	return website, nil
+}
+
+func storeBucketWebsite(bytes []byte, vol *Volume) (err error) {
+	return vol.store.Put(vol.name, bucketRootPath, XAttrKeyOSSWebsite, bytes)
+}
+
+func deleteBucketWebsite(vol *Volume) (err error) {
+	return vol.store.Delete(vol.name, bucketRootPath, XAttrKeyOSSWebsite)
+}
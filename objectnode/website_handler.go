@@ -0,0 +1,334 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"html"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/gorilla/mux"
+
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketWebsite.html
+func (o *ObjectNode) getBucketWebsiteHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		o.errorResponse(w, r, err, errorCode)
+	}()
+
+	param := ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("getBucketWebsiteHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		return
+	}
+
+	var website *WebsiteConfiguration
+	if website, err = vol.metaLoader.loadWebsite(); err != nil {
+		log.LogErrorf("getBucketWebsiteHandler: load website fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), vol.Name(), err)
+		return
+	}
+	if website == nil {
+		errorCode = NoSuchWebsiteConfiguration
+		return
+	}
+	var data []byte
+	if data, err = MarshalXMLEntity(website); err != nil {
+		log.LogErrorf("getBucketWebsiteHandler: xml marshal fail: requestID(%v) volume(%v) website(%+v) err(%v)",
+			GetRequestID(r), vol.Name(), website, err)
+		return
+	}
+
+	writeSuccessResponseXML(w, data)
+}
+
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketWebsite.html
+func (o *ObjectNode) putBucketWebsiteHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		o.errorResponse(w, r, err, errorCode)
+	}()
+
+	param := ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("putBucketWebsiteHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		return
+	}
+	var body []byte
+	if body, err = io.ReadAll(io.LimitReader(r.Body, MaxWebsiteSize+1)); err != nil {
+		log.LogErrorf("putBucketWebsiteHandler: read request body fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), vol.Name(), err)
+		return
+	}
+	if len(body) > MaxWebsiteSize {
+		errorCode = EntityTooLarge
+		return
+	}
+	if requestMD5 := r.Header.Get(ContentMD5); requestMD5 != "" && requestMD5 != GetMD5(body) {
+		errorCode = InvalidDigest
+		return
+	}
+	var website *WebsiteConfiguration
+	if website, errorCode = parseWebsiteConfig(body); errorCode != nil {
+		log.LogErrorf("putBucketWebsiteHandler: parse website config fail: requestID(%v) volume(%v) config(%v) err(%v)",
+			GetRequestID(r), vol.Name(), string(body), errorCode)
+		return
+	}
+	if err = storeBucketWebsite(body, vol); err != nil {
+		log.LogErrorf("putBucketWebsiteHandler: store website config fail: requestID(%v) volume(%v) config(%v) err(%v)",
+			GetRequestID(r), vol.Name(), string(body), err)
+		return
+	}
+	vol.metaLoader.storeWebsite(website)
+}
+
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_DeleteBucketWebsite.html
+func (o *ObjectNode) deleteBucketWebsiteHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		o.errorResponse(w, r, err, errorCode)
+	}()
+
+	param := ParseRequestParam(r)
+	if param.Bucket() == "" {
+		errorCode = InvalidBucketName
+		return
+	}
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		log.LogErrorf("deleteBucketWebsiteHandler: load volume fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), param.Bucket(), err)
+		return
+	}
+	if err = deleteBucketWebsite(vol); err != nil {
+		log.LogErrorf("deleteBucketWebsiteHandler: delete bucket website fail: requestID(%v) volume(%v) err(%v)",
+			GetRequestID(r), vol.Name(), err)
+		return
+	}
+	vol.metaLoader.storeWebsite(nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// isWebsiteRequest reports whether the request is sent to the website endpoint of a bucket.
+func (o *ObjectNode) isWebsiteRequest(r *http.Request) bool {
+	_, is := o.websiteWildcards.Parse(r.Host)
+	return is
+}
+
+// WebsiteMiddleware returns a middleware handler for requests to the website endpoint.
+// Website requests are served anonymously, the handler applies the redirect rules
+// of the bucket and resolves the key of "directory" requests to the index document
+// before the policy check.
+func (o *ObjectNode) websiteMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !o.isWebsiteRequest(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		param := ParseRequestParam(r)
+		vol, err := o.getVol(param.Bucket())
+		if err != nil {
+			o.websiteErrorResponse(w, r, err, nil)
+			return
+		}
+		website, err := vol.metaLoader.loadWebsite()
+		if err != nil {
+			log.LogErrorf("websiteMiddleware: load website fail: requestID(%v) volume(%v) err(%v)",
+				GetRequestID(r), vol.Name(), err)
+			o.websiteErrorResponse(w, r, err, nil)
+			return
+		}
+		if website == nil {
+			o.websiteErrorResponse(w, r, nil, NoSuchWebsiteConfiguration)
+			return
+		}
+		if location, code := website.redirect(r, param.Object(), 0); location != "" {
+			websiteRedirect(w, r, location, code)
+			return
+		}
+		mux.Vars(r)[ContextKeyObject] = website.indexKey(param.Object())
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Get or head object from the website endpoint
+// Reference: https://docs.aws.amazon.com/AmazonS3/latest/userguide/WebsiteEndpoints.html
+func (o *ObjectNode) websiteObjectHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		errorCode *ErrorCode
+	)
+	defer func() {
+		o.websiteErrorResponse(w, r, err, errorCode)
+	}()
+
+	param := ParseRequestParam(r)
+	var vol *Volume
+	if vol, err = o.getVol(param.Bucket()); err != nil {
+		return
+	}
+	var website *WebsiteConfiguration
+	if website, err = vol.metaLoader.loadWebsite(); err != nil {
+		return
+	}
+	if website == nil {
+		errorCode = NoSuchWebsiteConfiguration
+		return
+	}
+
+	key := param.Object()
+	var info *FSFileInfo
+	info, _, err = vol.ObjectMeta(key)
+	if err != nil && err != syscall.ENOENT {
+		log.LogErrorf("websiteObjectHandler: get object meta fail: requestID(%v) volume(%v) key(%v) err(%v)",
+			GetRequestID(r), vol.Name(), key, err)
+		return
+	}
+	if err == nil && !info.Mode.IsDir() {
+		o.serveWebsiteObject(w, r, key, http.StatusOK)
+		return
+	}
+	err = nil
+
+	// a "directory" requested without the trailing slash is redirected to the one with it
+	if website.IndexDocument != nil && !strings.HasSuffix(key, "/") {
+		if _, _, err = vol.ObjectMeta(website.indexKey(key + "/")); err == nil {
+			websiteRedirect(w, r, "/"+key+"/", http.StatusFound)
+			return
+		}
+		if err != syscall.ENOENT {
+			return
+		}
+		err = nil
+	}
+
+	if location, code := website.redirect(r, key, http.StatusNotFound); location != "" {
+		websiteRedirect(w, r, location, code)
+		return
+	}
+	errorCode = NoSuchKey
+	if website.ErrorDocument == nil {
+		return
+	}
+	errorKey := website.ErrorDocument.Key
+	if _, _, err = vol.ObjectMeta(errorKey); err != nil {
+		if err == syscall.ENOENT {
+			err = nil
+		}
+		return
+	}
+	// the error document is served only if it is accessible to anonymous users
+	errorCode = nil
+	mux.Vars(r)[ContextKeyObject] = errorKey
+	o.policyCheck(func(w http.ResponseWriter, r *http.Request) {
+		o.serveWebsiteObject(w, r, errorKey, http.StatusNotFound)
+	})(w, r)
+}
+
+func (o *ObjectNode) serveWebsiteObject(w http.ResponseWriter, r *http.Request, key string, statusCode int) {
+	log.LogDebugf("serveWebsiteObject: requestID(%v) key(%v) status(%v)", GetRequestID(r), key, statusCode)
+	mux.Vars(r)[ContextKeyObject] = key
+	if statusCode != http.StatusOK {
+		w = &websiteStatusWriter{ResponseWriter: w, statusCode: statusCode}
+	}
+	if r.Method == http.MethodHead {
+		o.headObjectHandler(w, r)
+		return
+	}
+	o.getObjectHandler(w, r)
+}
+
+// websiteStatusWriter replaces the success status code of the response,
+// it is used to serve the error document.
+type websiteStatusWriter struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (w *websiteStatusWriter) WriteHeader(statusCode int) {
+	if statusCode == http.StatusOK {
+		statusCode = w.statusCode
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func websiteRedirect(w http.ResponseWriter, r *http.Request, location string, code int) {
+	SetResponseStatusCode(r, strconv.Itoa(code))
+	w.Header().Set(Location, location)
+	w.WriteHeader(code)
+}
+
+// websiteErrorResponse writes the error in html, as the website endpoint is accessed by browsers.
+func (o *ObjectNode) websiteErrorResponse(w http.ResponseWriter, r *http.Request, err error, ec *ErrorCode) {
+	if err == nil && ec == nil {
+		return
+	}
+	log.LogErrorf("websiteErrorResponse: found error: requestID(%v) err(%v) errCode(%v)", GetRequestID(r), err, ec)
+	if ec1, ok := err.(*ErrorCode); ok && ec == nil {
+		ec = ec1
+	}
+	if ec == nil {
+		ec = InternalErrorCode(err)
+	}
+	SetResponseStatusCode(r, strconv.Itoa(ec.StatusCode))
+	SetResponseErrorMessage(r, ec.ErrorMessage)
+	SetResponseErrorCode(r, ec.ErrorCode)
+
+	title := strconv.Itoa(ec.StatusCode) + " " + http.StatusText(ec.StatusCode)
+	sb := strings.Builder{}
+	sb.WriteString("<html>\n<head><title>")
+	sb.WriteString(title)
+	sb.WriteString("</title></head>\n<body>\n<h1>")
+	sb.WriteString(title)
+	sb.WriteString("</h1>\n<ul>\n<li>Code: ")
+	sb.WriteString(html.EscapeString(ec.ErrorCode))
+	sb.WriteString("</li>\n<li>Message: ")
+	sb.WriteString(html.EscapeString(ec.ErrorMessage))
+	sb.WriteString("</li>\n<li>RequestId: ")
+	sb.WriteString(GetRequestID(r))
+	sb.WriteString("</li>\n</ul>\n<hr/>\n</body>\n</html>\n")
+	writeResponse(w, ec.StatusCode, []byte(sb.String()), ValueContentTypeHTML)
+}
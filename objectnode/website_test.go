@@ -0,0 +1,145 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebsiteConfiguration(t *testing.T) {
+	websiteXml := `
+<WebsiteConfiguration>
+    <IndexDocument>
+        <Suffix>index.html</Suffix>
+    </IndexDocument>
+    <ErrorDocument>
+        <Key>error.html</Key>
+    </ErrorDocument>
+    <RoutingRules>
+        <RoutingRule>
+            <Condition>
+                <KeyPrefixEquals>docs/</KeyPrefixEquals>
+            </Condition>
+            <Redirect>
+                <ReplaceKeyPrefixWith>documents/</ReplaceKeyPrefixWith>
+            </Redirect>
+        </RoutingRule>
+        <RoutingRule>
+            <Condition>
+                <HttpErrorCodeReturnedEquals>404</HttpErrorCodeReturnedEquals>
+            </Condition>
+            <Redirect>
+                <HostName>example.com</HostName>
+                <Protocol>https</Protocol>
+                <HttpRedirectCode>302</HttpRedirectCode>
+                <ReplaceKeyWith>404.html</ReplaceKeyWith>
+            </Redirect>
+        </RoutingRule>
+    </RoutingRules>
+</WebsiteConfiguration>
+`
+	website, errCode := parseWebsiteConfig([]byte(websiteXml))
+	require.Nil(t, errCode)
+	require.Equal(t, "index.html", website.IndexDocument.Suffix)
+	require.Equal(t, "error.html", website.ErrorDocument.Key)
+	require.Equal(t, 2, len(website.RoutingRules.RoutingRule))
+
+	// index document
+	require.Equal(t, "index.html", website.indexKey(""))
+	require.Equal(t, "a/b/index.html", website.indexKey("a/b/"))
+	require.Equal(t, "a/b", website.indexKey("a/b"))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Host = "bucket.website.cube.io"
+	location, code := website.redirect(r, "docs/a.html", 0)
+	require.Equal(t, "http://bucket.website.cube.io/documents/a.html", location)
+	require.Equal(t, http.StatusMovedPermanently, code)
+	location, _ = website.redirect(r, "a.html", 0)
+	require.Equal(t, "", location)
+	location, code = website.redirect(r, "a.html", http.StatusNotFound)
+	require.Equal(t, "https://example.com/404.html", location)
+	require.Equal(t, http.StatusFound, code)
+	location, _ = website.redirect(r, "a.html", http.StatusForbidden)
+	require.Equal(t, "", location)
+
+	// redirect all requests
+	website, errCode = parseWebsiteConfig([]byte(`
+<WebsiteConfiguration>
+    <RedirectAllRequestsTo>
+        <HostName>example.com</HostName>
+    </RedirectAllRequestsTo>
+</WebsiteConfiguration>`))
+	require.Nil(t, errCode)
+	location, code = website.redirect(r, "a/b.html", 0)
+	require.Equal(t, "http://example.com/a/b.html", location)
+	require.Equal(t, http.StatusMovedPermanently, code)
+}
+
+func TestWebsiteConfigurationValidate(t *testing.T) {
+	_, errCode := parseWebsiteConfig([]byte("<WebsiteConfiguration>"))
+	require.Equal(t, MalformedXML, errCode)
+
+	for _, body := range []string{
+		// no index document
+		`<WebsiteConfiguration><ErrorDocument><Key>error.html</Key></ErrorDocument></WebsiteConfiguration>`,
+		// suffix with slash
+		`<WebsiteConfiguration><IndexDocument><Suffix>a/index.html</Suffix></IndexDocument></WebsiteConfiguration>`,
+		// redirect all with others
+		`<WebsiteConfiguration><RedirectAllRequestsTo><HostName>example.com</HostName></RedirectAllRequestsTo>
+<IndexDocument><Suffix>index.html</Suffix></IndexDocument></WebsiteConfiguration>`,
+		// invalid protocol
+		`<WebsiteConfiguration><RedirectAllRequestsTo><HostName>example.com</HostName><Protocol>ftp</Protocol>
+</RedirectAllRequestsTo></WebsiteConfiguration>`,
+		// empty redirect
+		`<WebsiteConfiguration><IndexDocument><Suffix>index.html</Suffix></IndexDocument>
+<RoutingRules><RoutingRule><Redirect></Redirect></RoutingRule></RoutingRules></WebsiteConfiguration>`,
+		// both replace key and prefix
+		`<WebsiteConfiguration><IndexDocument><Suffix>index.html</Suffix></IndexDocument>
+<RoutingRules><RoutingRule><Redirect><ReplaceKeyWith>a</ReplaceKeyWith><ReplaceKeyPrefixWith>b</ReplaceKeyPrefixWith>
+</Redirect></RoutingRule></RoutingRules></WebsiteConfiguration>`,
+		// invalid redirect code
+		`<WebsiteConfiguration><IndexDocument><Suffix>index.html</Suffix></IndexDocument>
+<RoutingRules><RoutingRule><Redirect><HttpRedirectCode>200</HttpRedirectCode></Redirect></RoutingRule>
+</RoutingRules></WebsiteConfiguration>`,
+		// invalid error code condition
+		`<WebsiteConfiguration><IndexDocument><Suffix>index.html</Suffix></IndexDocument>
+<RoutingRules><RoutingRule><Condition><HttpErrorCodeReturnedEquals>200</HttpErrorCodeReturnedEquals></Condition>
+<Redirect><HostName>example.com</HostName></Redirect></RoutingRule></RoutingRules></WebsiteConfiguration>`,
+	} {
+		_, errCode = parseWebsiteConfig([]byte(body))
+		require.NotNil(t, errCode, body)
+		require.Equal(t, http.StatusBadRequest, errCode.StatusCode)
+	}
+}
+
+func TestWebsiteErrorResponse(t *testing.T) {
+	o := &ObjectNode{}
+	w := httptest.NewRecorder()
+	r := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/a.html", nil), map[string]string{})
+	o.websiteErrorResponse(w, r, nil, NoSuchKey)
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Equal(t, ValueContentTypeHTML, w.Header().Get(ContentType))
+	require.Contains(t, w.Body.String(), "<li>Code: NoSuchKey</li>")
+
+	w = httptest.NewRecorder()
+	sw := &websiteStatusWriter{ResponseWriter: w, statusCode: http.StatusNotFound}
+	sw.WriteHeader(http.StatusOK)
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,64 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"net/http"
+	"strings"
+)
+
+// WriteCondition is the precondition of the conditional writes, it is checked atomically
+// when the written object is applied to the dentry, so that only one of the concurrent
+// writers with the same precondition can succeed.
+// API reference: https://docs.aws.amazon.com/AmazonS3/latest/userguide/conditional-writes.html
+type WriteCondition struct {
+	IfNoneMatch bool   // write only if the object does not exist, specified by "If-None-Match: *"
+	IfMatch     string // write only if the ETag of the existing object matches
+}
+
+// ParseWriteCondition parses the precondition of PutObject, CopyObject and CompleteMultipartUpload
+// from the request headers, it returns nil if no precondition is specified.
+func ParseWriteCondition(r *http.Request) (*WriteCondition, *ErrorCode) {
+	noneMatch := r.Header.Get(IfNoneMatch)
+	match := strings.Trim(r.Header.Get(IfMatch), "\"")
+	if noneMatch == "" && match == "" {
+		return nil, nil
+	}
+	if noneMatch != "" && noneMatch != "*" {
+		// only the asterisk is supported for writes
+		return nil, UnsupportedOperation
+	}
+	if noneMatch != "" && match != "" {
+		return nil, InvalidArgument
+	}
+	return &WriteCondition{IfNoneMatch: noneMatch != "", IfMatch: match}, nil
+}
+
+// Check checks the precondition with the ETag of the existing object, exist is false
+// if the object does not exist.
+func (c *WriteCondition) Check(exist bool, etag string) error {
+	if c.IfNoneMatch && exist {
+		return PreconditionFailed
+	}
+	if c.IfMatch != "" {
+		if !exist {
+			return NoSuchKey
+		}
+		if c.IfMatch != "*" && c.IfMatch != etag {
+			return PreconditionFailed
+		}
+	}
+	return nil
+}
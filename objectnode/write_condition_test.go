@@ -0,0 +1,68 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package objectnode
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWriteCondition(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPut, "/bucket/key", nil)
+	cond, errorCode := ParseWriteCondition(req)
+	require.Nil(t, errorCode)
+	require.Nil(t, cond)
+
+	req.Header.Set(IfNoneMatch, "\"d41d8cd98f00b204e9800998ecf8427e\"")
+	_, errorCode = ParseWriteCondition(req)
+	require.Equal(t, UnsupportedOperation, errorCode)
+
+	req.Header.Set(IfNoneMatch, "*")
+	cond, errorCode = ParseWriteCondition(req)
+	require.Nil(t, errorCode)
+	require.True(t, cond.IfNoneMatch)
+
+	req.Header.Set(IfMatch, "\"d41d8cd98f00b204e9800998ecf8427e\"")
+	_, errorCode = ParseWriteCondition(req)
+	require.Equal(t, InvalidArgument, errorCode)
+
+	req.Header.Del(IfNoneMatch)
+	cond, errorCode = ParseWriteCondition(req)
+	require.Nil(t, errorCode)
+	require.False(t, cond.IfNoneMatch)
+	require.Equal(t, "d41d8cd98f00b204e9800998ecf8427e", cond.IfMatch)
+}
+
+func TestWriteConditionCheck(t *testing.T) {
+	etag := "d41d8cd98f00b204e9800998ecf8427e"
+
+	cond := &WriteCondition{IfNoneMatch: true}
+	require.NoError(t, cond.Check(false, ""))
+	require.Equal(t, PreconditionFailed, cond.Check(true, etag))
+
+	cond = &WriteCondition{IfMatch: etag}
+	require.Equal(t, NoSuchKey, cond.Check(false, ""))
+	require.NoError(t, cond.Check(true, etag))
+	require.Equal(t, PreconditionFailed, cond.Check(true, "5eb63bbbe01eeed093cb22bb8f5acdc3"))
+	// the stale ETag never matches
+	require.Equal(t, PreconditionFailed, cond.Check(true, ""))
+
+	cond = &WriteCondition{IfMatch: "*"}
+	require.Equal(t, NoSuchKey, cond.Check(false, ""))
+	require.NoError(t, cond.Check(true, ""))
+}
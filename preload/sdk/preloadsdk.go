@@ -147,6 +147,7 @@ func NewClient(config PreloadConfig) *PreLoadClient {
 		OnSplitExtentKey:  mw.SplitExtentKey,
 		OnGetExtents:      mw.GetExtents,
 		OnTruncate:        mw.Truncate,
+		OnModifyInode:     mw.InodeModify,
 		VolumeType:        proto.VolumeTypeCold,
 	}); err != nil {
 		log.LogErrorf("newClient NewExtentClient failed(%v)", err)
@@ -50,6 +50,7 @@ const (
 	AdminRecoverDiskErrorReplica              = "/dataPartition/recoverDiskErrorReplica"
 	AdminDeleteDataReplica                    = "/dataReplica/delete"
 	AdminAddDataReplica                       = "/dataReplica/add"
+	AdminPromoteDataReplica                   = "/dataReplica/promote"
 	AdminDeleteVol                            = "/vol/delete"
 	AdminUpdateVol                            = "/vol/update"
 	AdminVolShrink                            = "/vol/shrink"
@@ -187,6 +188,7 @@ const (
 	AdminChangeMetaPartitionLeader     = "/metaPartition/changeleader"
 	AdminBalanceMetaPartitionLeader    = "/metaPartition/balanceLeader"
 	AdminAddMetaReplica                = "/metaReplica/add"
+	AdminPromoteMetaReplica            = "/metaReplica/promote"
 	AdminDeleteMetaReplica             = "/metaReplica/delete"
 	AdminPutDataPartitions             = "/dataPartitions/set"
 
@@ -203,6 +205,11 @@ const (
 	GetBucketLifecycle    = "/s3/getLifecycle"
 	DeleteBucketLifecycle = "/s3/deleteLifecycle"
 
+	// S3 inventory configuration APIS
+	SetBucketInventory    = "/s3/setInventory"
+	GetBucketInventory    = "/s3/getInventory"
+	DeleteBucketInventory = "/s3/deleteInventory"
+
 	AddLcNode = "/lcNode/add"
 
 	QueryDisableDisk = "/dataNode/queryDisableDisk"
@@ -234,6 +241,14 @@ const (
 	UserTransferVol     = "/user/transferVol"
 	UserList            = "/user/list"
 	UsersOfVol          = "/vol/users"
+
+	// APIs for role management
+	RoleCreate  = "/role/create"
+	RoleDelete  = "/role/delete"
+	RoleUpdate  = "/role/update"
+	RoleGetInfo = "/role/info"
+	RoleList    = "/role/list"
+
 	// graphql api for header
 	HeadAuthorized  = "Authorization"
 	ParamAuthorized = "_authorization"
@@ -269,6 +284,7 @@ var GApiInfo map[string]string = map[string]string{
 	"admindiagnosedatapartition":         AdminDiagnoseDataPartition,
 	"admindeletedatareplica":             AdminDeleteDataReplica,
 	"adminadddatareplica":                AdminAddDataReplica,
+	"adminpromotedatareplica":            AdminPromoteDataReplica,
 	"admindeletevol":                     AdminDeleteVol,
 	"adminupdatevol":                     AdminUpdateVol,
 	"adminvolshrink":                     AdminVolShrink,
@@ -343,6 +359,7 @@ var GApiInfo map[string]string = map[string]string{
 	"adminchangemetapartitionleader":  AdminChangeMetaPartitionLeader,
 	"adminbalancemetapartitionleader": AdminBalanceMetaPartitionLeader,
 	"adminaddmetareplica":             AdminAddMetaReplica,
+	"adminpromotemetareplica":         AdminPromoteMetaReplica,
 	"admindeletemetareplica":          AdminDeleteMetaReplica,
 	"getmetanodetaskresponse":         GetMetaNodeTaskResponse,
 	"getdatanodetaskresponse":         GetDataNodeTaskResponse,
@@ -360,6 +377,11 @@ var GApiInfo map[string]string = map[string]string{
 	"usertransfervol":                 UserTransferVol,
 	"userlist":                        UserList,
 	"usersofvol":                      UsersOfVol,
+	"rolecreate":                      RoleCreate,
+	"roledelete":                      RoleDelete,
+	"roleupdate":                      RoleUpdate,
+	"rolegetinfo":                     RoleGetInfo,
+	"rolelist":                        RoleList,
 }
 
 // const TimeFormat = "2006-01-02 15:04:05"
@@ -474,6 +496,7 @@ type UidSpaceRsp struct {
 	Info        string
 	OK          bool
 	UidSpaceArr []*UidSpaceInfo
+	UidUserArr  []*UidUserInfo
 	Reserve     string
 }
 
@@ -575,6 +598,13 @@ type AddDataPartitionRaftMemberRequest struct {
 	AddPeer     Peer
 }
 
+// PromoteDataPartitionLearnerRequest defines the request of promoting a learner of a data partition to a voter.
+type PromoteDataPartitionLearnerRequest struct {
+	PartitionId  uint64
+	PromotePeer  Peer
+	MaxLagOfLogs uint64
+}
+
 // RemoveDataPartitionRaftMemberRequest defines the request of add raftMember a data partition.
 type RemoveDataPartitionRaftMemberRequest struct {
 	PartitionId uint64
@@ -589,6 +619,13 @@ type AddMetaPartitionRaftMemberRequest struct {
 	AddPeer     Peer
 }
 
+// PromoteMetaPartitionLearnerRequest defines the request of promoting a learner of a meta partition to a voter.
+type PromoteMetaPartitionLearnerRequest struct {
+	PartitionId  uint64
+	PromotePeer  Peer
+	MaxLagOfLogs uint64
+}
+
 // RemoveMetaPartitionRaftMemberRequest defines the request of add raftMember a meta partition.
 type RemoveMetaPartitionRaftMemberRequest struct {
 	PartitionId uint64
@@ -1112,6 +1149,10 @@ type SimpleVolView struct {
 	DeleteLockTime          int64
 	EnableToken             bool
 	EnablePosixAcl          bool
+	PermissionMode          uint8
+	EnableDedup             bool
+	EnableDefrag            bool
+	EnableMirror            bool
 	EnableQuota             bool
 	EnableTransactionV1     string
 	EnableTransaction       string
@@ -1137,6 +1178,7 @@ type SimpleVolView struct {
 	CacheRule        string
 	PreloadCapacity  uint64
 	Uids             []UidSimpleInfo
+	UidUsers         []*UidUserInfo
 	TrashInterval    int64
 
 	// multi version snapshot
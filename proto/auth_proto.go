@@ -225,6 +225,7 @@ const (
 	MsgMasterQosUpdateZoneLimitReq        MsgType = MsgMasterAPIAccessReq + 0x40800
 	MsgMasterQosUpdateMasterLimitReq      MsgType = MsgMasterAPIAccessReq + 0x40900
 	MsgMasterQosUpdateClientParamReq      MsgType = MsgMasterAPIAccessReq + 0x40a00
+	MsgMasterPromoteMetaReplicaReq        MsgType = MsgMasterAPIAccessReq + 0x40b00
 
 	// Master API data partition management
 	MsgMasterCreateDataPartitionReq       MsgType = MsgMasterAPIAccessReq + 0x50100
@@ -235,6 +236,7 @@ const (
 	MsgMasterDeleteDataReplicaReq         MsgType = MsgMasterAPIAccessReq + 0x50600
 	MsgMasterSetDpRdOnlyReq               MsgType = MsgMasterAPIAccessReq + 0x50700
 	MsgMasterReportLackDataPartitions     MsgType = MsgMasterAPIAccessReq + 0x50800
+	MsgMasterPromoteDataReplicaReq        MsgType = MsgMasterAPIAccessReq + 0x50900
 
 	// Master API meta node management
 	MsgMasterAddMetaNodeReq          MsgType = MsgMasterAPIAccessReq + 0x60100
@@ -263,6 +265,9 @@ const (
 	MsgMasterUserRemovePolicyReq    MsgType = MsgMasterAPIAccessReq + 0x80500
 	MsgMasterUserDeleteVolPolicyReq MsgType = MsgMasterAPIAccessReq + 0x80600
 	MsgMasterUserTransferVolReq     MsgType = MsgMasterAPIAccessReq + 0x80700
+	MsgMasterRoleCreateReq          MsgType = MsgMasterAPIAccessReq + 0x80800
+	MsgMasterRoleDeleteReq          MsgType = MsgMasterAPIAccessReq + 0x80900
+	MsgMasterRoleUpdateReq          MsgType = MsgMasterAPIAccessReq + 0x80a00
 
 	// Master API zone management
 	MsgMasterUpdateZoneReq MsgType = MsgMasterAPIAccessReq + 0x90100
@@ -313,6 +318,7 @@ var MsgType2ResourceMap = map[MsgType]string{
 	MsgMasterQosUpdateZoneLimitReq:        "master:qosupdatezonelimit",
 	MsgMasterQosUpdateMasterLimitReq:      "master:qosupdatemasterlimit",
 	MsgMasterQosUpdateClientParamReq:      "master:qosupdateclientparam",
+	MsgMasterPromoteMetaReplicaReq:        "master:promotemetareplica",
 
 	// Master API data partition management
 	MsgMasterCreateDataPartitionReq:       "master:createdatapartition",
@@ -323,6 +329,7 @@ var MsgType2ResourceMap = map[MsgType]string{
 	MsgMasterDeleteDataReplicaReq:         "master:removedatareplica",
 	MsgMasterSetDpRdOnlyReq:               "master:setdprdonly",
 	MsgMasterReportLackDataPartitions:     "master:reportLackDataPartitions",
+	MsgMasterPromoteDataReplicaReq:        "master:promotedatareplica",
 
 	// Master API meta node management
 	MsgMasterAddMetaNodeReq:          "master:addmetanode",
@@ -351,6 +358,9 @@ var MsgType2ResourceMap = map[MsgType]string{
 	MsgMasterUserRemovePolicyReq:    "master:userremotepolicy",
 	MsgMasterUserDeleteVolPolicyReq: "master:userdeletevolpolicy",
 	MsgMasterUserTransferVolReq:     "master:usertransfervol",
+	MsgMasterRoleCreateReq:          "master:rolecreate",
+	MsgMasterRoleDeleteReq:          "master:roledelete",
+	MsgMasterRoleUpdateReq:          "master:roleupdate",
 
 	// Master API zone management
 	MsgMasterUpdateZoneReq: "master:updatezone",
@@ -0,0 +1,48 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto
+
+// Change types recorded in the change log of meta partitions.
+const (
+	ChangeCreateDentry uint8 = iota + 1
+	ChangeDeleteDentry
+	ChangeUpdateDentry
+	ChangeSetAttr
+	ChangeWrite
+	ChangeTruncate
+	ChangeXAttr
+)
+
+// ChangeRecord is a namespace or data change applied by a meta partition at raft index Index.
+// A record only tells what has changed, the consumer reads the current state from the volume.
+type ChangeRecord struct {
+	Index    uint64 `json:"idx"`
+	Type     uint8  `json:"type"`
+	Inode    uint64 `json:"ino"`
+	ParentID uint64 `json:"pid,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Mode     uint32 `json:"mode,omitempty"`
+	Offset   uint64 `json:"off,omitempty"`
+	Size     uint64 `json:"size,omitempty"`
+}
+
+// ChangeLogResponse is the reply of /getChangeLog of meta node.
+// Records after index Since are complete, a consumer whose checkpoint is behind Since has lost changes.
+type ChangeLogResponse struct {
+	PartitionID uint64          `json:"pid"`
+	Since       uint64          `json:"since"`
+	ApplyID     uint64          `json:"applyId"`
+	Records     []*ChangeRecord `json:"records"`
+}
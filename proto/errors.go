@@ -106,6 +106,9 @@ var (
 	ErrPerformingDecommission                  = errors.New("is performing decommission")
 	ErrWaitForAutoAddReplica                   = errors.New("wait for auto add replica")
 	ErrBufferSizeExceedMaximum                 = errors.New("buffer size exceeds maximum")
+	ErrDuplicateRole                           = errors.New("duplicate role")
+	ErrRoleNotExists                           = errors.New("role not exists")
+	ErrInvalidRole                             = errors.New("invalid role")
 )
 
 // http response error code and error message definitions
@@ -175,6 +178,9 @@ const (
 	ErrCodeZoneNumError
 	ErrCodeVersionOpError
 	ErrCodeNodeSetNotExists
+	ErrCodeDuplicateRole
+	ErrCodeRoleNotExists
+	ErrCodeInvalidRole
 )
 
 // Err2CodeMap error map to code
@@ -240,6 +246,9 @@ var Err2CodeMap = map[error]int32{
 	ErrZoneNum:                         ErrCodeZoneNumError,
 	ErrCodeVersionOp:                   ErrCodeVersionOpError,
 	ErrNodeSetNotExists:                ErrCodeNodeSetNotExists,
+	ErrDuplicateRole:                   ErrCodeDuplicateRole,
+	ErrRoleNotExists:                   ErrCodeRoleNotExists,
+	ErrInvalidRole:                     ErrCodeInvalidRole,
 }
 
 func ParseErrorCode(code int32) error {
@@ -314,6 +323,9 @@ var code2ErrMap = map[int32]error{
 	ErrCodeNodeSetNotExists:                ErrNodeSetNotExists,
 	ErrCodeVolNotDelete:                    ErrVolNotDelete,
 	ErrCodeVolHasDeleted:                   ErrVolHasDeleted,
+	ErrCodeDuplicateRole:                   ErrDuplicateRole,
+	ErrCodeRoleNotExists:                   ErrRoleNotExists,
+	ErrCodeInvalidRole:                     ErrInvalidRole,
 }
 
 type GeneralResp struct {
@@ -463,6 +463,7 @@ const (
 	GcNormal GcFlag = iota
 	GcMarkFlag
 	GcDeleteFlag
+	GcDedupRefFlag // add a reference to each extent instead of locking it
 )
 
 func (g GcFlag) String() string {
@@ -472,6 +473,9 @@ func (g GcFlag) String() string {
 	if g == GcDeleteFlag {
 		return "gc_delete"
 	}
+	if g == GcDedupRefFlag {
+		return "dedup_ref"
+	}
 	return "normal"
 }
 
@@ -480,9 +484,52 @@ type GcLockExtents struct {
 	BeforeTime string
 	Eks        []*ExtentKey
 	Flag       GcFlag
+	DedupToken string `json:",omitempty"` // the refs of GcDedupRefFlag with the same token are added once
+}
+
+type DefragFenceAction uint8
+
+const (
+	DefragFenceBegin DefragFenceAction = iota
+	DefragFenceSeal
+	DefragFenceRelease
+)
+
+func (a DefragFenceAction) String() string {
+	switch a {
+	case DefragFenceBegin:
+		return "begin"
+	case DefragFenceSeal:
+		return "seal"
+	case DefragFenceRelease:
+		return "release"
+	}
+	return fmt.Sprintf("unknown(%d)", uint8(a))
+}
+
+// DefragFenceRequest fences the ranges of the extent keys copied by the defragmentation of a file.
+// An overwrite applied to a begun fence makes its seal fail, and an overwrite applied to a sealed
+// fence is rejected until the fence is released or expired.
+type DefragFenceRequest struct {
+	FenceID  string
+	Action   DefragFenceAction
+	Eks      []*ExtentKey `json:",omitempty"`
+	ExpireAt int64        `json:",omitempty"` // unix time set by the raft leader of the data partition
 }
 
 type DelExtentParam struct {
 	*ExtentKey
 	IsSnapshotDeletion bool
+	ReleaseToken       string `json:",omitempty"` // the reference of a shared extent with the same token is released once
+}
+
+// InodeReleaseToken identifies the release of the extent held by the inode at the file offset.
+func InodeReleaseToken(ino uint64, ek *ExtentKey) string {
+	return fmt.Sprintf("ino_%d_%d_%d_%d", ino, ek.FileOffset, ek.PartitionId, ek.ExtentId)
+}
+
+// DelFileReleaseToken identifies the release of the extent key at the offset of the delete
+// extents file, the files and cursors are replicated by the meta partition raft.
+func DelFileReleaseToken(mpID uint64, fileName string, offset uint64) string {
+	return fmt.Sprintf("del_%d_%s_%d", mpID, fileName, offset)
 }
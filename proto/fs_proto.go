@@ -367,6 +367,8 @@ type UnlinkInodeRequest struct {
 	UniqID      uint64 `json:"uid"` // for request dedup
 	VerSeq      uint64 `json:"ver"`
 	DenVerSeq   uint64 `json:"denVer"`
+	// skip the GOVERNANCE mode retention of the inode
+	BypassGovernance bool `json:"bypassGovernance,omitempty"`
 	RequestExtend
 }
 
@@ -461,7 +463,8 @@ type UpdateDentryRequest struct {
 	PartitionID uint64 `json:"pid"`
 	ParentID    uint64 `json:"pino"`
 	Name        string `json:"name"`
-	Inode       uint64 `json:"ino"` // new inode number
+	Inode       uint64 `json:"ino"`              // new inode number
+	OldIno      uint64 `json:"oldIno,omitempty"` // update only if the dentry refers to it, if not zero
 	RequestExtend
 }
 
@@ -780,12 +783,91 @@ type AppendExtentKeysRequest struct {
 	Extents     []ExtentKey `json:"eks"`
 }
 
+// ReplaceExtentKeysRequest defines the request to atomically replace the extents of an inode
+// with the extents written to a source inode in the same partition. The extents are replaced only if
+// the generation and the modify time of the inode are not changed since the old extents were read.
+type ReplaceExtentKeysRequest struct {
+	VolName     string      `json:"vol"`
+	PartitionId uint64      `json:"pid"`
+	Inode       uint64      `json:"ino"`
+	SrcInode    uint64      `json:"srcIno"`
+	OldExtents  []ExtentKey `json:"oeks"`
+	Generation  uint64      `json:"gen"`
+	ModifyTime  int64       `json:"mt"`
+}
+
+// GetFragmentedInodesRequest defines the request to list the inodes with fragmented extents.
+type GetFragmentedInodesRequest struct {
+	VolName        string `json:"vol"`
+	PartitionId    uint64 `json:"pid"`
+	Marker         uint64 `json:"marker"`
+	MinExtents     uint32 `json:"minEks"`
+	MinTinyExtents uint32 `json:"minTinyEks"`
+	Limit          uint32 `json:"limit"`
+	// TinyExtents lists the tiny extents to reclaim by data partition, the inodes referencing
+	// any of them are listed regardless of the fragment thresholds.
+	TinyExtents map[uint64][]uint64 `json:"tinyEks,omitempty"`
+}
+
+type FragmentedInode struct {
+	Inode           uint64 `json:"ino"`
+	Size            uint64 `json:"sz"`
+	ExtentCount     uint32 `json:"eks"`
+	TinyExtentCount uint32 `json:"tinyEks"`
+}
+
+type GetFragmentedInodesResponse struct {
+	Inodes     []*FragmentedInode `json:"inodes"`
+	NextMarker uint64             `json:"next"`
+}
+
+// DedupFingerprintXAttr is the xattr that keeps the content fingerprints of the shared extents
+// of an inode, a JSON object keyed by "<partition id>_<extent id>".
+const DedupFingerprintXAttr = "cfs.dedup.fingerprints"
+
+// ExtentRemap maps an extent key of an inode to another extent with the same content,
+// the fingerprint of the content is recorded in DedupFingerprintXAttr of the inode.
+type ExtentRemap struct {
+	Old         ExtentKey `json:"old"`
+	New         ExtentKey `json:"new"`
+	Fingerprint string    `json:"fp,omitempty"`
+}
+
+// DedupFingerprintKey returns the key of the extent in DedupFingerprintXAttr.
+func DedupFingerprintKey(partitionID, extentID uint64) string {
+	return fmt.Sprintf("%d_%d", partitionID, extentID)
+}
+
+// RemapExtentKeysRequest defines the request to map extent keys of an inode to deduplicated extents.
+// The generation and the modify time are the ones of the inode when its extents were verified.
+type RemapExtentKeysRequest struct {
+	VolName     string        `json:"vol"`
+	PartitionId uint64        `json:"pid"`
+	Inode       uint64        `json:"ino"`
+	Remaps      []ExtentRemap `json:"remaps"`
+	Generation  uint64        `json:"gen"`
+	ModifyTime  int64         `json:"mt"`
+}
+
+// InodeModifyRequest defines the request to report the range of an inode overwritten in place.
+// The overwrites keep the extent keys, so the meta partition knows nothing about them otherwise.
+type InodeModifyRequest struct {
+	VolName     string `json:"vol"`
+	PartitionId uint64 `json:"pid"`
+	Inode       uint64 `json:"ino"`
+	Offset      uint64 `json:"off"`
+	Size        uint64 `json:"size"`
+	ModifyTime  int64  `json:"mt"`
+}
+
 type SetXAttrRequest struct {
 	VolName     string `json:"vol"`
 	PartitionId uint64 `json:"pid"`
 	Inode       uint64 `json:"ino"`
 	Key         string `json:"key"`
 	Value       string `json:"val"`
+	// allow shortening the GOVERNANCE mode retention of the inode
+	BypassGovernance bool `json:"bypassGovernance,omitempty"`
 }
 
 type BatchSetXAttrRequest struct {
@@ -831,6 +913,8 @@ type RemoveXAttrRequest struct {
 	Inode       uint64 `json:"ino"`
 	Key         string `json:"key"`
 	VerSeq      uint64 `json:"seq"`
+	// allow removing the GOVERNANCE mode retention of the inode
+	BypassGovernance bool `json:"bypassGovernance,omitempty"`
 }
 
 type ListXAttrRequest struct {
@@ -0,0 +1,83 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	InventoryFrequencyDaily  = "Daily"
+	InventoryFrequencyWeekly = "Weekly"
+
+	InventoryFormatCSV     = "CSV"
+	InventoryFormatParquet = "Parquet"
+
+	InventoryFieldSize             = "Size"
+	InventoryFieldLastModifiedDate = "LastModifiedDate"
+	InventoryFieldETag             = "ETag"
+	InventoryFieldStorageClass     = "StorageClass"
+	InventoryFieldTags             = "Tags"
+)
+
+// InventoryConfigurations are the inventory configurations of a volume, which are kept by master
+// and scheduled to lcnode together with the lifecycle rules.
+type InventoryConfigurations struct {
+	VolName        string
+	Configurations []*InventoryConfiguration
+}
+
+type InventoryConfiguration struct {
+	ID                string
+	Enabled           bool
+	Prefix            string
+	Frequency         string
+	Format            string
+	DestinationBucket string
+	DestinationPrefix string
+	OptionalFields    []string
+}
+
+// GenInventoryTasks generates the tasks of the enabled inventory configurations to be run at the time,
+// the daily inventories are run every time and the weekly inventories are run on Sundays.
+func (c *InventoryConfigurations) GenInventoryTasks(now time.Time) []*RuleTask {
+	tasks := make([]*RuleTask, 0)
+	for _, inv := range c.Configurations {
+		if !inv.Enabled {
+			log.LogDebugf("GenInventoryTasks: skip disabled inventory(%v) in volume(%v)", inv.ID, c.VolName)
+			continue
+		}
+		if inv.Frequency == InventoryFrequencyWeekly && now.Weekday() != time.Sunday {
+			continue
+		}
+		task := &RuleTask{
+			Id:      fmt.Sprintf("%s:inventory:%s", c.VolName, inv.ID),
+			VolName: c.VolName,
+			// the rule without expiration never expires the objects but limits the scanning with the prefix
+			Rule: &Rule{
+				ID:     inv.ID,
+				Status: RuleEnabled,
+				Filter: &FilterConfig{Prefix: inv.Prefix},
+			},
+			Inventory: inv,
+		}
+		tasks = append(tasks, task)
+		log.LogDebugf("GenInventoryTasks: RuleTask(%v) generated from inventory(%v) in volume(%v)", *task, inv.ID, c.VolName)
+	}
+	return tasks
+}
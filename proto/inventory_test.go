@@ -0,0 +1,40 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenInventoryTasks(t *testing.T) {
+	confs := &InventoryConfigurations{
+		VolName: "vol",
+		Configurations: []*InventoryConfiguration{
+			{ID: "daily", Enabled: true, Frequency: InventoryFrequencyDaily, Prefix: "logs/"},
+			{ID: "weekly", Enabled: true, Frequency: InventoryFrequencyWeekly},
+			{ID: "disabled", Frequency: InventoryFrequencyDaily},
+		},
+	}
+	saturday := time.Date(2023, 8, 5, 1, 0, 0, 0, time.UTC)
+	tasks := confs.GenInventoryTasks(saturday)
+	require.Len(t, tasks, 1)
+	require.Equal(t, "vol:inventory:daily", tasks[0].Id)
+	require.Equal(t, "logs/", tasks[0].Rule.Filter.Prefix)
+	require.Nil(t, tasks[0].Rule.Expire)
+	require.Len(t, confs.GenInventoryTasks(saturday.AddDate(0, 0, 1)), 2)
+}
@@ -80,6 +80,8 @@ type RuleTask struct {
 	Id      string
 	VolName string
 	Rule    *Rule
+	// the inventory report of the volume is generated instead if it is set
+	Inventory *InventoryConfiguration `json:",omitempty"`
 }
 
 type LcNodeRuleTaskResponse struct {
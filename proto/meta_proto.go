@@ -27,10 +27,28 @@ type CreateNameSpaceResponse struct {
 	Result string
 }
 
+// Types of the partition peers, the values are the same as the raft peer types.
+const (
+	PeerNormal uint8 = 0
+	// PeerWitness votes and keeps the raft log of a data partition, but stores no data.
+	PeerWitness uint8 = 1
+	// PeerLearner receives the raft log but does not vote until it is promoted.
+	PeerLearner uint8 = 2
+)
+
 // Peer defines the peer of the node id and address.
 type Peer struct {
 	ID   uint64 `json:"id"`
 	Addr string `json:"addr"`
+	Type uint8  `json:"type,omitempty"`
+}
+
+func (p Peer) IsLearner() bool {
+	return p.Type == PeerLearner
+}
+
+func (p Peer) IsWitness() bool {
+	return p.Type == PeerWitness
 }
 
 // CreateMetaPartitionRequest defines the request to create a meta partition.
@@ -63,6 +81,14 @@ type UidSpaceInfo struct {
 	Rsv       string
 }
 
+// UidUserInfo maps the S3 user to the uid and gid in the volume with the unified permission mode.
+type UidUserInfo struct {
+	VolName string
+	UserID  string
+	Uid     uint32
+	Gid     uint32
+}
+
 type UidReportSpaceInfo struct {
 	Uid   uint32
 	Size  uint64
@@ -0,0 +1,101 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto
+
+import (
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// The object lock attributes are written by the objectnode, the bucket configuration
+// is stored on the root inode and the retention of each object is stored on its inode.
+const (
+	ObjectLockXAttrKey      = "oss:lock"
+	ObjectLockModeXAttrKey  = "oss:lock-mode"
+	ObjectLegalHoldXAttrKey = "oss:legal-hold"
+
+	ObjectLockGovernanceMode = "GOVERNANCE"
+	ObjectLockComplianceMode = "COMPLIANCE"
+	ObjectLegalHoldOn        = "ON"
+)
+
+// IsObjectLockXAttr reports whether the extended attribute is managed by object lock,
+// which must not be modified by the file system clients.
+func IsObjectLockXAttr(name string) bool {
+	return name == ObjectLockXAttrKey || name == ObjectLockModeXAttrKey || name == ObjectLegalHoldXAttrKey
+}
+
+// CheckObjectLockXAttr returns syscall.EPERM if the object is protected by the legal hold
+// or the retention period has not expired yet. The GOVERNANCE mode retention is skipped
+// if bypassGovernance is set, while the COMPLIANCE mode retention can never be bypassed.
+func CheckObjectLockXAttr(xattr *XAttrInfo, bypassGovernance bool) error {
+	if xattr == nil {
+		return nil
+	}
+	if string(xattr.Get(ObjectLegalHoldXAttrKey)) == ObjectLegalHoldOn {
+		return syscall.EPERM
+	}
+	retainUntilDate := xattr.Get(ObjectLockXAttrKey)
+	if len(retainUntilDate) == 0 {
+		return nil
+	}
+	retainUntilDateInt64, err := strconv.ParseInt(string(retainUntilDate), 10, 64)
+	if err != nil {
+		return err
+	}
+	if retainUntilDateInt64 <= time.Now().UnixNano() {
+		return nil
+	}
+	if bypassGovernance && string(xattr.Get(ObjectLockModeXAttrKey)) == ObjectLockGovernanceMode {
+		return nil
+	}
+	return syscall.EPERM
+}
+
+// CheckObjectLockXAttrUpdate returns syscall.EPERM if setting the extended attribute to the
+// value, or removing it if the value is nil, would shorten or weaken the retention in force.
+// The retention may only be extended, or changed from GOVERNANCE to COMPLIANCE mode, unless
+// it is in GOVERNANCE mode and bypassGovernance is set. The retention without a mode is in
+// COMPLIANCE mode. The legal hold is not checked, it is authorized by the objectnode.
+func CheckObjectLockXAttrUpdate(xattr *XAttrInfo, name string, value []byte, bypassGovernance bool) error {
+	if xattr == nil || (name != ObjectLockXAttrKey && name != ObjectLockModeXAttrKey) {
+		return nil
+	}
+	retainUntilDate, err := strconv.ParseInt(string(xattr.Get(ObjectLockXAttrKey)), 10, 64)
+	if err != nil || retainUntilDate <= time.Now().UnixNano() {
+		return nil
+	}
+	mode := string(xattr.Get(ObjectLockModeXAttrKey))
+	if mode == "" {
+		mode = ObjectLockComplianceMode
+	}
+	if bypassGovernance && mode == ObjectLockGovernanceMode {
+		return nil
+	}
+	if value == nil {
+		return syscall.EPERM
+	}
+	if name == ObjectLockXAttrKey {
+		if newDate, err := strconv.ParseInt(string(value), 10, 64); err == nil && newDate >= retainUntilDate {
+			return nil
+		}
+		return syscall.EPERM
+	}
+	if newMode := string(value); newMode == mode || newMode == ObjectLockComplianceMode {
+		return nil
+	}
+	return syscall.EPERM
+}
@@ -105,6 +105,7 @@ const (
 	OpMetaExtentAddWithCheck uint8 = 0x3A // Append extent key with discard extents check
 	OpMetaReadDirLimit       uint8 = 0x3D
 	OpMetaLockDir            uint8 = 0x3E
+	OpMetaExtentsReplace     uint8 = 0x3F // Replace extent keys of an inode with the ones of a source inode
 
 	// Operations: Master -> MetaNode
 	OpCreateMetaPartition           uint8 = 0x40
@@ -116,6 +117,7 @@ const (
 	OpAddMetaPartitionRaftMember    uint8 = 0x46
 	OpRemoveMetaPartitionRaftMember uint8 = 0x47
 	OpMetaPartitionTryToLeader      uint8 = 0x48
+	OpPromoteMetaPartitionLearner   uint8 = 0x49
 
 	// Quota
 	OpMetaBatchSetInodeQuota    uint8 = 0x50
@@ -136,6 +138,9 @@ const (
 	OpBackupRead              uint8 = 0x59
 	OpBackupWrite             uint8 = 0x5A
 
+	// Operations: DataNode -> DataNode Leader
+	OpDefragFenceExtents uint8 = 0x5C // Fence the extents copied by the defragmentation against overwrites
+
 	// Operations: Master -> DataNode
 	OpCreateDataPartition           uint8 = 0x60
 	OpDeleteDataPartition           uint8 = 0x61
@@ -151,6 +156,7 @@ const (
 	OpStopDataPartitionRepair       uint8 = 0x6B
 	OpRecoverDataReplicaMeta        uint8 = 0x6C
 	OpRecoverBackupDataReplica      uint8 = 0x6D
+	OpPromoteDataPartitionLearner   uint8 = 0x6E
 
 	// Operations: MultipartInfo
 	OpCreateMultipart  uint8 = 0x70
@@ -234,6 +240,10 @@ const (
 	OpMetaBatchSetXAttr uint8 = 0xD2
 	OpMetaGetAllXAttr   uint8 = 0xD3
 
+	OpMetaGetFragmentedInodes uint8 = 0xD4
+	OpMetaExtentsRemap        uint8 = 0xD8 // Map extent keys of an inode to other extents with the same content
+	OpMetaInodeModify         uint8 = 0xD9 // Report the in-place overwrite of an inode
+
 	// transaction error
 
 	OpTxInodeInfoNotExistErr  uint8 = 0xE0
@@ -461,6 +471,14 @@ func (p *Packet) GetOpMsg() (m string) {
 		m = "OpMetaExtentsAdd"
 	case OpMetaExtentAddWithCheck:
 		m = "OpMetaExtentAddWithCheck"
+	case OpMetaExtentsReplace:
+		m = "OpMetaExtentsReplace"
+	case OpMetaGetFragmentedInodes:
+		m = "OpMetaGetFragmentedInodes"
+	case OpMetaExtentsRemap:
+		m = "OpMetaExtentsRemap"
+	case OpMetaInodeModify:
+		m = "OpMetaInodeModify"
 	case OpMetaObjExtentAdd:
 		m = "OpMetaObjExtentAdd"
 	case OpMetaExtentsDel:
@@ -545,6 +563,10 @@ func (p *Packet) GetOpMsg() (m string) {
 		m = "OpMetaPartitionTryToLeader"
 	case OpDataPartitionTryToLeader:
 		m = "OpDataPartitionTryToLeader"
+	case OpPromoteMetaPartitionLearner:
+		m = "OpPromoteMetaPartitionLearner"
+	case OpPromoteDataPartitionLearner:
+		m = "OpPromoteDataPartitionLearner"
 	case OpMetaDeleteInode:
 		m = "OpMetaDeleteInode"
 	case OpMetaBatchDeleteInode:
@@ -627,6 +649,8 @@ func (p *Packet) GetOpMsg() (m string) {
 		m = "OpBatchLockNormalExtent"
 	case OpBatchUnlockNormalExtent:
 		m = "OpBatchUnlockNormalExtent"
+	case OpDefragFenceExtents:
+		m = "OpDefragFenceExtents"
 	default:
 		m = fmt.Sprintf("op:%v not found", p.Opcode)
 	}
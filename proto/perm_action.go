@@ -68,7 +68,7 @@ const (
 	OSSGetBucketPolicyAction       Action = OSSActionPrefix + "GetBucketPolicy"
 	OSSPutBucketPolicyAction       Action = OSSActionPrefix + "PutBucketPolicy"
 	OSSDeleteBucketPolicyAction    Action = OSSActionPrefix + "DeleteBucketPolicy"
-	OSSGetBucketPolicyStatusAction Action = OSSActionPrefix + "GetBucketPolicyStatus" // unsupported
+	OSSGetBucketPolicyStatusAction Action = OSSActionPrefix + "GetBucketPolicyStatus"
 
 	// Bucket ACL actions
 	OSSGetBucketAclAction Action = OSSActionPrefix + "GetBucketAcl"
@@ -129,12 +129,15 @@ const (
 	OSSListObjectVersionsAction  Action = OSSActionPrefix + "ListObjectVersions"  // unsupported
 
 	// Object legal hold actions
-	OSSGetObjectLegalHoldAction Action = OSSActionPrefix + "GetObjectLegalHold" // unsupported
-	OSSPutObjectLegalHoldAction Action = OSSActionPrefix + "PutObjectLegalHold" // unsupported
+	OSSGetObjectLegalHoldAction Action = OSSActionPrefix + "GetObjectLegalHold"
+	OSSPutObjectLegalHoldAction Action = OSSActionPrefix + "PutObjectLegalHold"
 
 	// Object retention actions
-	OSSGetObjectRetentionAction Action = OSSActionPrefix + "GetObjectRetention" // unsupported
-	OSSPutObjectRetentionAction Action = OSSActionPrefix + "PutObjectRetention" // unsupported
+	OSSGetObjectRetentionAction Action = OSSActionPrefix + "GetObjectRetention"
+	OSSPutObjectRetentionAction Action = OSSActionPrefix + "PutObjectRetention"
+
+	// Object attributes actions
+	OSSGetObjectAttributesAction Action = OSSActionPrefix + "GetObjectAttributes"
 
 	// Bucket encryption actions
 	OSSGetBucketEncryptionAction    Action = OSSActionPrefix + "GetBucketEncryption"    // unsupported
@@ -142,17 +145,33 @@ const (
 	OSSDeleteBucketEncryptionAction Action = OSSActionPrefix + "DeleteBucketEncryption" // unsupported
 
 	// Bucket website actions
-	OSSGetBucketWebsiteAction    Action = OSSActionPrefix + "GetBucketWebsite"    // unsupported
-	OSSPutBucketWebsiteAction    Action = OSSActionPrefix + "PutBucketWebsite"    // unsupported
-	OSSDeleteBucketWebsiteAction Action = OSSActionPrefix + "DeleteBucketWebsite" // unsupported
+	OSSGetBucketWebsiteAction    Action = OSSActionPrefix + "GetBucketWebsite"
+	OSSPutBucketWebsiteAction    Action = OSSActionPrefix + "PutBucketWebsite"
+	OSSDeleteBucketWebsiteAction Action = OSSActionPrefix + "DeleteBucketWebsite"
+
+	// Bucket logging actions
+	OSSGetBucketLoggingAction Action = OSSActionPrefix + "GetBucketLogging"
+	OSSPutBucketLoggingAction Action = OSSActionPrefix + "PutBucketLogging"
+
+	// Bucket inventory actions
+	OSSGetBucketInventoryConfigurationAction    Action = OSSActionPrefix + "GetBucketInventoryConfiguration"
+	OSSPutBucketInventoryConfigurationAction    Action = OSSActionPrefix + "PutBucketInventoryConfiguration"
+	OSSDeleteBucketInventoryConfigurationAction Action = OSSActionPrefix + "DeleteBucketInventoryConfiguration"
+	OSSListBucketInventoryConfigurationsAction  Action = OSSActionPrefix + "ListBucketInventoryConfigurations"
+
+	// Bucket metrics actions
+	OSSGetBucketMetricsConfigurationAction    Action = OSSActionPrefix + "GetBucketMetricsConfiguration"
+	OSSPutBucketMetricsConfigurationAction    Action = OSSActionPrefix + "PutBucketMetricsConfiguration"
+	OSSDeleteBucketMetricsConfigurationAction Action = OSSActionPrefix + "DeleteBucketMetricsConfiguration"
+	OSSListBucketMetricsConfigurationsAction  Action = OSSActionPrefix + "ListBucketMetricsConfigurations"
 
 	// Object restore actions
 	OSSRestoreObjectAction Action = OSSActionPrefix + "RestoreObject" // unsupported
 
 	// Public access block actions
-	OSSGetPublicAccessBlockAction    Action = OSSActionPrefix + "GetPublicAccessBlock"   // unsupported
-	OSSPutPublicAccessBlockAction    Action = OSSActionPrefix + "PutPublicAccessBlock"   // unsupported
-	OSSDeletePublicAccessBlockAction Action = OSSActionPrefix + "DeletePulicAccessBlock" // unuspported
+	OSSGetPublicAccessBlockAction    Action = OSSActionPrefix + "GetPublicAccessBlock"
+	OSSPutPublicAccessBlockAction    Action = OSSActionPrefix + "PutPublicAccessBlock"
+	OSSDeletePublicAccessBlockAction Action = OSSActionPrefix + "DeletePublicAccessBlock"
 
 	// Bucket request payment actions
 	OSSGetBucketRequestPaymentAction Action = OSSActionPrefix + "GetBucketRequestPayment" // unsupported
@@ -164,7 +183,12 @@ const (
 	OSSDeleteBucketReplicationAction Action = OSSActionPrefix + "DeleteBucketReplicationAction" // unsupported
 
 	// STS actions
-	OSSGetFederationTokenAction Action = OSSActionPrefix + "GetFederationToken"
+	OSSGetFederationTokenAction        Action = OSSActionPrefix + "GetFederationToken"
+	OSSAssumeRoleAction                Action = OSSActionPrefix + "AssumeRole"
+	OSSAssumeRoleWithWebIdentityAction Action = OSSActionPrefix + "AssumeRoleWithWebIdentity"
+
+	// CloudWatch actions
+	OSSGetMetricStatisticsAction Action = OSSActionPrefix + "GetMetricStatistics"
 
 	// constants for POSIX file system interface
 	POSIXReadAction  Action = POSIXActionPrefix + "Read"
@@ -231,6 +255,7 @@ var AllActions = []Action{
 	OSSPutObjectLegalHoldAction,
 	OSSGetObjectRetentionAction,
 	OSSPutObjectRetentionAction,
+	OSSGetObjectAttributesAction,
 	OSSGetBucketEncryptionAction,
 	OSSPutBucketEncryptionAction,
 	OSSDeleteBucketEncryptionAction,
@@ -240,6 +265,16 @@ var AllActions = []Action{
 	OSSGetBucketWebsiteAction,
 	OSSPutBucketWebsiteAction,
 	OSSDeleteBucketWebsiteAction,
+	OSSGetBucketLoggingAction,
+	OSSPutBucketLoggingAction,
+	OSSGetBucketInventoryConfigurationAction,
+	OSSPutBucketInventoryConfigurationAction,
+	OSSDeleteBucketInventoryConfigurationAction,
+	OSSListBucketInventoryConfigurationsAction,
+	OSSGetBucketMetricsConfigurationAction,
+	OSSPutBucketMetricsConfigurationAction,
+	OSSDeleteBucketMetricsConfigurationAction,
+	OSSListBucketMetricsConfigurationsAction,
 	OSSRestoreObjectAction,
 	OSSGetPublicAccessBlockAction,
 	OSSPutPublicAccessBlockAction,
@@ -251,6 +286,9 @@ var AllActions = []Action{
 	OSSDeleteBucketReplicationAction,
 	OSSOptionsObjectAction,
 	OSSGetFederationTokenAction,
+	OSSAssumeRoleAction,
+	OSSAssumeRoleWithWebIdentityAction,
+	OSSGetMetricStatisticsAction,
 
 	// POSIX file system interface actions
 	POSIXReadAction,
@@ -414,6 +452,7 @@ var builtinPermissionActionsMap = map[Permission]Actions{
 		OSSListObjectVersionsAction,
 		OSSGetObjectLegalHoldAction,
 		OSSGetObjectRetentionAction,
+		OSSGetObjectAttributesAction,
 		OSSGetBucketEncryptionAction,
 
 		// file system interface
@@ -448,6 +487,7 @@ var builtinPermissionActionsMap = map[Permission]Actions{
 		OSSPutObjectLegalHoldAction,
 		OSSGetObjectRetentionAction,
 		OSSPutObjectRetentionAction,
+		OSSGetObjectAttributesAction,
 		OSSGetBucketEncryptionAction,
 
 		// POSIX file system interface actions
@@ -0,0 +1,234 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto
+
+import (
+	"encoding/binary"
+	"errors"
+	"sort"
+)
+
+// The permission modes of the volume.
+const (
+	// PermissionModeIndependent checks the S3 ACLs and the POSIX permissions separately.
+	PermissionModeIndependent uint8 = 0
+	// PermissionModeUnified maps the S3 users to uid and gid, and the S3 ACLs to the mode bits and POSIX ACLs,
+	// so that the access from S3 and POSIX is checked by the same permissions.
+	PermissionModeUnified uint8 = 1
+)
+
+func IsValidPermissionMode(mode uint8) bool {
+	return mode == PermissionModeIndependent || mode == PermissionModeUnified
+}
+
+// NobodyUid and NobodyGid are the identity of the S3 users which are not mapped.
+const (
+	NobodyUid uint32 = 65534
+	NobodyGid uint32 = 65534
+)
+
+// XAttrPosixACLAccess is the extended attribute of the access ACL, which is kept in the
+// format of the Linux kernel so that it is shared by the FUSE clients and objectnode.
+const XAttrPosixACLAccess = "system.posix_acl_access"
+
+// The tags of the POSIX ACL entries.
+const (
+	PosixACLUserObj  uint16 = 0x01
+	PosixACLUser     uint16 = 0x02
+	PosixACLGroupObj uint16 = 0x04
+	PosixACLGroup    uint16 = 0x08
+	PosixACLMask     uint16 = 0x10
+	PosixACLOther    uint16 = 0x20
+)
+
+// The permissions of the POSIX ACL entries and the mode bits.
+const (
+	PosixPermRead    uint16 = 0x04
+	PosixPermWrite   uint16 = 0x02
+	PosixPermExecute uint16 = 0x01
+)
+
+const (
+	posixACLVersion     uint32 = 0x0002
+	posixACLUndefinedID uint32 = 0xffffffff
+	posixACLHeaderSize         = 4
+	posixACLEntrySize          = 8
+)
+
+var ErrInvalidPosixACL = errors.New("invalid posix acl")
+
+type PosixACLEntry struct {
+	Tag  uint16
+	Perm uint16
+	ID   uint32
+}
+
+type PosixACL []PosixACLEntry
+
+// NewPosixACL returns the ACL of the mode bits and the named users, the mask is the union of the
+// named users and the owning group as it is computed by setfacl.
+func NewPosixACL(mode uint32, users map[uint32]uint16) PosixACL {
+	acl := PosixACL{
+		{Tag: PosixACLUserObj, Perm: uint16(mode>>6) & 0x07},
+		{Tag: PosixACLGroupObj, Perm: uint16(mode>>3) & 0x07},
+		{Tag: PosixACLOther, Perm: uint16(mode) & 0x07},
+	}
+	if len(users) == 0 {
+		return acl
+	}
+	mask := acl[1].Perm
+	for uid, perm := range users {
+		acl = append(acl, PosixACLEntry{Tag: PosixACLUser, Perm: perm & 0x07, ID: uid})
+		mask |= perm & 0x07
+	}
+	acl = append(acl, PosixACLEntry{Tag: PosixACLMask, Perm: mask})
+	acl.sort()
+	return acl
+}
+
+func (acl PosixACL) sort() {
+	sort.Slice(acl, func(i, j int) bool {
+		if acl[i].Tag != acl[j].Tag {
+			return acl[i].Tag < acl[j].Tag
+		}
+		return acl[i].ID < acl[j].ID
+	})
+}
+
+// IsMinimal returns true if the ACL is equivalent to the mode bits.
+func (acl PosixACL) IsMinimal() bool {
+	for _, e := range acl {
+		if e.Tag != PosixACLUserObj && e.Tag != PosixACLGroupObj && e.Tag != PosixACLOther {
+			return false
+		}
+	}
+	return true
+}
+
+// Mode returns the permission bits in sync with the ACL, the group class bits are the mask if any.
+func (acl PosixACL) Mode() (mode uint32) {
+	group := PosixACLGroupObj
+	if _, ok := acl.find(PosixACLMask); ok {
+		group = PosixACLMask
+	}
+	for _, e := range acl {
+		switch e.Tag {
+		case PosixACLUserObj:
+			mode |= uint32(e.Perm&0x07) << 6
+		case group:
+			mode |= uint32(e.Perm&0x07) << 3
+		case PosixACLOther:
+			mode |= uint32(e.Perm & 0x07)
+		}
+	}
+	return
+}
+
+func (acl PosixACL) find(tag uint16) (PosixACLEntry, bool) {
+	for _, e := range acl {
+		if e.Tag == tag {
+			return e, true
+		}
+	}
+	return PosixACLEntry{}, false
+}
+
+// Encode encodes the ACL in the format of the posix_acl_access extended attribute.
+func (acl PosixACL) Encode() []byte {
+	entries := make(PosixACL, len(acl))
+	copy(entries, acl)
+	entries.sort()
+	data := make([]byte, posixACLHeaderSize+posixACLEntrySize*len(entries))
+	binary.LittleEndian.PutUint32(data, posixACLVersion)
+	for i, e := range entries {
+		off := posixACLHeaderSize + posixACLEntrySize*i
+		id := e.ID
+		if e.Tag != PosixACLUser && e.Tag != PosixACLGroup {
+			id = posixACLUndefinedID
+		}
+		binary.LittleEndian.PutUint16(data[off:], e.Tag)
+		binary.LittleEndian.PutUint16(data[off+2:], e.Perm)
+		binary.LittleEndian.PutUint32(data[off+4:], id)
+	}
+	return data
+}
+
+func DecodePosixACL(data []byte) (acl PosixACL, err error) {
+	if len(data) < posixACLHeaderSize || (len(data)-posixACLHeaderSize)%posixACLEntrySize != 0 ||
+		binary.LittleEndian.Uint32(data) != posixACLVersion {
+		return nil, ErrInvalidPosixACL
+	}
+	for off := posixACLHeaderSize; off < len(data); off += posixACLEntrySize {
+		acl = append(acl, PosixACLEntry{
+			Tag:  binary.LittleEndian.Uint16(data[off:]),
+			Perm: binary.LittleEndian.Uint16(data[off+2:]),
+			ID:   binary.LittleEndian.Uint32(data[off+4:]),
+		})
+	}
+	return
+}
+
+// CheckPosixPermission checks whether the user is granted the wanted permissions of the inode by
+// the mode bits and the ACL, it follows the access check algorithm of acl(5).
+func CheckPosixPermission(mode, ownerUid, ownerGid uint32, acl PosixACL, uid uint32, gids []uint32, want uint16) bool {
+	if uid == 0 {
+		return true
+	}
+	granted := func(perm uint16) bool { return perm&want == want }
+	inGroup := func(gid uint32) bool {
+		for _, g := range gids {
+			if g == gid {
+				return true
+			}
+		}
+		return false
+	}
+	if acl.IsMinimal() {
+		switch {
+		case uid == ownerUid:
+			return granted(uint16(mode>>6) & 0x07)
+		case inGroup(ownerGid):
+			return granted(uint16(mode>>3) & 0x07)
+		default:
+			return granted(uint16(mode) & 0x07)
+		}
+	}
+
+	if uid == ownerUid {
+		return granted(uint16(mode>>6) & 0x07)
+	}
+	mask := uint16(0x07)
+	if e, ok := acl.find(PosixACLMask); ok {
+		mask = e.Perm
+	}
+	for _, e := range acl {
+		if e.Tag == PosixACLUser && e.ID == uid {
+			return granted(e.Perm & mask)
+		}
+	}
+	matched := false
+	for _, e := range acl {
+		if (e.Tag == PosixACLGroupObj && inGroup(ownerGid)) || (e.Tag == PosixACLGroup && inGroup(e.ID)) {
+			if granted(e.Perm & mask) {
+				return true
+			}
+			matched = true
+		}
+	}
+	if matched {
+		return false
+	}
+	return granted(uint16(mode) & 0x07)
+}
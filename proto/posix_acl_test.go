@@ -0,0 +1,85 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPosixACLEncode(t *testing.T) {
+	acl := NewPosixACL(0o640, map[uint32]uint16{1001: PosixPermRead | PosixPermWrite})
+	require.False(t, acl.IsMinimal())
+	require.Equal(t, uint32(0o660), acl.Mode())
+
+	// the same bytes as setfacl -m u:1001:rw- on a file of mode 0640
+	data := acl.Encode()
+	require.Equal(t, []byte{
+		0x02, 0x00, 0x00, 0x00,
+		0x01, 0x00, 0x06, 0x00, 0xff, 0xff, 0xff, 0xff,
+		0x02, 0x00, 0x06, 0x00, 0xe9, 0x03, 0x00, 0x00,
+		0x04, 0x00, 0x04, 0x00, 0xff, 0xff, 0xff, 0xff,
+		0x10, 0x00, 0x06, 0x00, 0xff, 0xff, 0xff, 0xff,
+		0x20, 0x00, 0x00, 0x00, 0xff, 0xff, 0xff, 0xff,
+	}, data)
+
+	decoded, err := DecodePosixACL(data)
+	require.NoError(t, err)
+	require.Len(t, decoded, 5)
+	require.Equal(t, PosixACLEntry{Tag: PosixACLUser, Perm: 6, ID: 1001}, decoded[1])
+
+	_, err = DecodePosixACL(data[:7])
+	require.Equal(t, ErrInvalidPosixACL, err)
+	_, err = DecodePosixACL([]byte{0x01, 0x00, 0x00, 0x00})
+	require.Equal(t, ErrInvalidPosixACL, err)
+
+	minimal := NewPosixACL(0o644, nil)
+	require.True(t, minimal.IsMinimal())
+	require.Equal(t, uint32(0o644), minimal.Mode())
+}
+
+func TestCheckPosixPermission(t *testing.T) {
+	read, write := PosixPermRead, PosixPermWrite
+
+	// mode bits only
+	require.True(t, CheckPosixPermission(0o640, 1000, 100, nil, 1000, []uint32{1000}, read|write))
+	require.True(t, CheckPosixPermission(0o640, 1000, 100, nil, 1001, []uint32{100}, read))
+	require.False(t, CheckPosixPermission(0o640, 1000, 100, nil, 1001, []uint32{100}, write))
+	require.False(t, CheckPosixPermission(0o640, 1000, 100, nil, 1002, []uint32{1002}, read))
+	require.True(t, CheckPosixPermission(0o600, 1000, 100, nil, 0, []uint32{0}, read|write))
+
+	// the named user and the mask
+	acl := NewPosixACL(0o600, map[uint32]uint16{1001: read | write})
+	mode := acl.Mode()
+	require.True(t, CheckPosixPermission(mode, 1000, 100, acl, 1001, []uint32{1001}, read|write))
+	require.False(t, CheckPosixPermission(mode, 1000, 100, acl, 1002, []uint32{100}, read))
+
+	acl[len(acl)-2] = PosixACLEntry{Tag: PosixACLMask, Perm: read}
+	require.True(t, CheckPosixPermission(mode, 1000, 100, acl, 1001, []uint32{1001}, read))
+	require.False(t, CheckPosixPermission(mode, 1000, 100, acl, 1001, []uint32{1001}, write))
+
+	// the matched group entries without the permission deny the others class
+	acl = PosixACL{
+		{Tag: PosixACLUserObj, Perm: 6},
+		{Tag: PosixACLGroupObj, Perm: 0},
+		{Tag: PosixACLGroup, Perm: 4, ID: 200},
+		{Tag: PosixACLMask, Perm: 4},
+		{Tag: PosixACLOther, Perm: 4},
+	}
+	require.True(t, CheckPosixPermission(0o644, 1000, 100, acl, 1003, []uint32{200}, read))
+	require.False(t, CheckPosixPermission(0o644, 1000, 100, acl, 1003, []uint32{100}, read))
+	require.True(t, CheckPosixPermission(0o644, 1000, 100, acl, 1003, []uint32{300}, read))
+}
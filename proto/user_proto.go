@@ -375,3 +375,34 @@ type UserUpdateParam struct {
 	Password    string   `json:"password"`
 	Description string   `json:"description"`
 }
+
+// RoleInfo defines a role which can be assumed through the STS interface of ObjectNode.
+// The temporary credentials of the role act on behalf of the owner user, and are limited by
+// the permission policy of the role. The trust policy specifies who can assume the role.
+type RoleInfo struct {
+	RoleName           string `json:"role_name"`
+	UserID             string `json:"user_id"`
+	TrustPolicy        string `json:"trust_policy"`
+	PermissionPolicy   string `json:"permission_policy"`
+	MaxSessionDuration int64  `json:"max_session_duration"` // seconds
+	CreateTime         string `json:"create_time"`
+	Description        string `json:"description"`
+}
+
+type RoleCreateParam struct {
+	RoleName           string `json:"role_name"`
+	UserID             string `json:"user_id"`
+	TrustPolicy        string `json:"trust_policy"`
+	PermissionPolicy   string `json:"permission_policy"`
+	MaxSessionDuration int64  `json:"max_session_duration"`
+	Description        string `json:"description"`
+}
+
+// RoleUpdateParam updates the non-empty fields of the role.
+type RoleUpdateParam struct {
+	RoleName           string `json:"role_name"`
+	TrustPolicy        string `json:"trust_policy"`
+	PermissionPolicy   string `json:"permission_policy"`
+	MaxSessionDuration int64  `json:"max_session_duration"`
+	Description        string `json:"description"`
+}
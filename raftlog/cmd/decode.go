@@ -0,0 +1,158 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	raftproto "github.com/cubefs/cubefs/depends/tiglabs/raft/proto"
+	"github.com/cubefs/cubefs/proto"
+)
+
+const (
+	fsmTypeDataNode = "datanode"
+	fsmTypeMetaNode = "metanode"
+	fsmTypeMaster   = "master"
+
+	dumpBatchSize = 4 * 1024 * 1024
+
+	// same as datanode.BinaryMarshalMagicVersion
+	dataNodeBinaryMagicVersion = 0xFF
+)
+
+// command is a decoded raft command.
+type command struct {
+	Op    string
+	Key   string
+	Value []byte
+}
+
+func (c *command) String(showValue bool) string {
+	if showValue {
+		return fmt.Sprintf("op(%v) key(%v) value(%s)", c.Op, c.Key, c.Value)
+	}
+	return fmt.Sprintf("op(%v) key(%v) valueSize(%v)", c.Op, c.Key, len(c.Value))
+}
+
+type decoder func(data []byte) (*command, error)
+
+func newDecoder(fsmType string) (decoder, error) {
+	switch fsmType {
+	case fsmTypeDataNode:
+		return decodeDataNodeCmd, nil
+	case fsmTypeMetaNode:
+		return decodeMetaNodeCmd, nil
+	case fsmTypeMaster:
+		return decodeMasterCmd, nil
+	default:
+		return nil, fmt.Errorf("unknown state machine type %v", fsmType)
+	}
+}
+
+func formatEntry(d decoder, entry *raftproto.Entry, showValue bool) string {
+	prefix := fmt.Sprintf("index(%v) term(%v) type(%v)", entry.Index, entry.Term, entry.Type)
+	if entry.Type == raftproto.EntryConfChange {
+		cc := new(raftproto.ConfChange)
+		cc.Decode(entry.Data)
+		return fmt.Sprintf("%v %v peer(%v)", prefix, cc.Type, cc.Peer)
+	}
+	if len(entry.Data) == 0 {
+		return prefix + " empty"
+	}
+	cmd, err := d(entry.Data)
+	if err != nil {
+		return fmt.Sprintf("%v size(%v) decode err(%v)", prefix, len(entry.Data), err)
+	}
+	return prefix + " " + cmd.String(showValue)
+}
+
+func opcodeName(op uint8) string {
+	p := &proto.Packet{Opcode: op}
+	return p.GetOpMsg()
+}
+
+// metaItem is the raft command of metanode, see metanode.MetaItem.
+type metaItem struct {
+	Op uint32 `json:"Op"`
+	K  []byte `json:"k"`
+	V  []byte `json:"v"`
+}
+
+func decodeMetaNodeCmd(data []byte) (cmd *command, err error) {
+	item := new(metaItem)
+	if err = json.Unmarshal(data, item); err != nil {
+		return
+	}
+	return &command{Op: fmt.Sprintf("%d", item.Op), Key: string(item.K), Value: item.V}, nil
+}
+
+// masterCmd is the raft command of master, see master.RaftCmd.
+type masterCmd struct {
+	Op uint32 `json:"op"`
+	K  string `json:"k"`
+	V  []byte `json:"v"`
+}
+
+func decodeMasterCmd(data []byte) (cmd *command, err error) {
+	item := new(masterCmd)
+	if err = json.Unmarshal(data, item); err != nil {
+		return
+	}
+	return &command{Op: fmt.Sprintf("0x%02X", item.Op), Key: item.K, Value: item.V}, nil
+}
+
+// dataNodeCmd is the json raft command of datanode, see datanode.RaftCmdItem.
+type dataNodeCmd struct {
+	Op uint32 `json:"op"`
+	K  []byte `json:"k"`
+	V  []byte `json:"v"`
+}
+
+// decodeDataNodeCmd decodes the binary random write log or the json command of datanode,
+// see datanode.MarshalRandWriteRaftLog.
+func decodeDataNodeCmd(data []byte) (cmd *command, err error) {
+	if len(data) >= 4 && binary.BigEndian.Uint32(data) == dataNodeBinaryMagicVersion {
+		var (
+			version  uint32
+			opcode   uint8
+			extentID uint64
+			offset   int64
+			size     int64
+			crc      uint32
+		)
+		buff := bytes.NewBuffer(data)
+		for _, v := range []interface{}{&version, &opcode, &extentID, &offset, &size, &crc} {
+			if err = binary.Read(buff, binary.BigEndian, v); err != nil {
+				return
+			}
+		}
+		if size < 0 || int64(buff.Len()) < size {
+			return nil, fmt.Errorf("data size %v but %v left", size, buff.Len())
+		}
+		return &command{
+			Op:    opcodeName(opcode),
+			Key:   fmt.Sprintf("extent(%v) offset(%v) size(%v) crc(%v)", extentID, offset, size, crc),
+			Value: buff.Next(int(size)),
+		}, nil
+	}
+	item := new(dataNodeCmd)
+	if err = json.Unmarshal(data, item); err != nil {
+		return
+	}
+	return &command{Op: opcodeName(uint8(item.Op)), Key: string(item.K), Value: item.V}, nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+)
+
+func TestDecodeDataNodeCmd(t *testing.T) {
+	payload := []byte("hello")
+	buff := bytes.NewBuffer(nil)
+	for _, v := range []interface{}{uint32(dataNodeBinaryMagicVersion), proto.OpRandomWrite, uint64(1025),
+		int64(4096), int64(len(payload)), uint32(123)} {
+		binary.Write(buff, binary.BigEndian, v)
+	}
+	buff.Write(payload)
+
+	cmd, err := decodeDataNodeCmd(buff.Bytes())
+	if err != nil {
+		t.Fatalf("decode random write err %v", err)
+	}
+	if cmd.Op != opcodeName(proto.OpRandomWrite) || !bytes.Equal(cmd.Value, payload) {
+		t.Errorf("decode random write got op(%v) value(%s)", cmd.Op, cmd.Value)
+	}
+
+	if _, err = decodeDataNodeCmd(buff.Bytes()[:buff.Len()-1]); err == nil {
+		t.Errorf("truncated random write should fail")
+	}
+
+	data, _ := json.Marshal(&dataNodeCmd{Op: uint32(proto.OpVersionOp), K: []byte("key"), V: []byte("value")})
+	if cmd, err = decodeDataNodeCmd(data); err != nil || cmd.Key != "key" || string(cmd.Value) != "value" {
+		t.Errorf("decode json cmd got %v err %v", cmd, err)
+	}
+}
+
+func TestDecodeMasterAndMetaNodeCmd(t *testing.T) {
+	data, _ := json.Marshal(&masterCmd{Op: 0x04, K: "#vol#test", V: []byte("{}")})
+	cmd, err := decodeMasterCmd(data)
+	if err != nil || cmd.Op != "0x04" || cmd.Key != "#vol#test" {
+		t.Errorf("decode master cmd got %v err %v", cmd, err)
+	}
+
+	data, _ = json.Marshal(&metaItem{Op: 7, K: []byte("k"), V: []byte("v")})
+	if cmd, err = decodeMetaNodeCmd(data); err != nil || cmd.Op != "7" || cmd.Key != "k" {
+		t.Errorf("decode metanode cmd got %v err %v", cmd, err)
+	}
+
+	if _, err = newDecoder("unknown"); err == nil {
+		t.Errorf("unknown fsm type should fail")
+	}
+}
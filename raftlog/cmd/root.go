@@ -0,0 +1,175 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cubefs/cubefs/depends/tiglabs/raft/storage/wal"
+	"github.com/cubefs/cubefs/proto"
+)
+
+var (
+	WalDir  string
+	FsmType string
+)
+
+func NewRootCmd() *cobra.Command {
+	var optShowVersion bool
+	c := &cobra.Command{
+		Use:   path.Base(os.Args[0]),
+		Short: "CubeFS raft log inspection tool",
+		Args:  cobra.MinimumNArgs(0),
+		Run: func(cmd *cobra.Command, args []string) {
+			if optShowVersion {
+				fmt.Fprintln(os.Stdout, proto.DumpVersion("RAFTLOG"))
+				return
+			}
+			cmd.Help()
+		},
+	}
+
+	c.AddCommand(
+		newInfoCmd(),
+		newDumpCmd(),
+	)
+
+	c.PersistentFlags().StringVarP(&WalDir, "dir", "d", "", "wal directory of a raft partition")
+	c.PersistentFlags().StringVarP(&FsmType, "type", "t", fsmTypeMetaNode,
+		fmt.Sprintf("state machine of the raft partition, %v|%v|%v", fsmTypeDataNode, fsmTypeMetaNode, fsmTypeMaster))
+	c.Flags().BoolVarP(&optShowVersion, "version", "v", false, "Show version information")
+	return c
+}
+
+// openWal opens the wal of a stopped raft partition, the wal must not be used by
+// a running server at the same time.
+func openWal() (ws *wal.Storage, err error) {
+	if WalDir == "" {
+		return nil, fmt.Errorf("wal directory is not specified")
+	}
+	if _, err = os.Stat(WalDir); err != nil {
+		return
+	}
+	return wal.NewStorage(WalDir, &wal.Config{})
+}
+
+func newInfoCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "info",
+		Short: "show the index range and hard state of the raft log",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := showInfo(); err != nil {
+				fmt.Fprintf(os.Stderr, "show info failed: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	return c
+}
+
+func showInfo() (err error) {
+	ws, err := openWal()
+	if err != nil {
+		return
+	}
+	defer ws.Close()
+	hs, err := ws.InitialState()
+	if err != nil {
+		return
+	}
+	first, err := ws.FirstIndex()
+	if err != nil {
+		return
+	}
+	last, err := ws.LastIndex()
+	if err != nil {
+		return
+	}
+	fmt.Printf("FirstIndex: %v\nLastIndex : %v\nTerm      : %v\nCommit    : %v\nVote      : %v\n",
+		first, last, hs.Term, hs.Commit, hs.Vote)
+	return
+}
+
+func newDumpCmd() *cobra.Command {
+	var (
+		start     uint64
+		end       uint64
+		limit     uint64
+		showValue bool
+	)
+	c := &cobra.Command{
+		Use:   "dump",
+		Short: "decode and print the raft log entries",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := dump(start, end, limit, showValue); err != nil {
+				fmt.Fprintf(os.Stderr, "dump failed: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	c.Flags().Uint64Var(&start, "start", 0, "first index to dump, default the first index of the log")
+	c.Flags().Uint64Var(&end, "end", 0, "last index to dump, default the last index of the log")
+	c.Flags().Uint64Var(&limit, "limit", 0, "max entries to dump, 0 means no limit")
+	c.Flags().BoolVar(&showValue, "value", false, "print the value of the commands")
+	return c
+}
+
+func dump(start, end, limit uint64, showValue bool) (err error) {
+	decoder, err := newDecoder(FsmType)
+	if err != nil {
+		return
+	}
+	ws, err := openWal()
+	if err != nil {
+		return
+	}
+	defer ws.Close()
+	first, err := ws.FirstIndex()
+	if err != nil {
+		return
+	}
+	last, err := ws.LastIndex()
+	if err != nil {
+		return
+	}
+	if start < first {
+		start = first
+	}
+	if end == 0 || end > last {
+		end = last
+	}
+	var count uint64
+	for lo := start; lo <= end; {
+		entries, _, err := ws.Entries(lo, end+1, dumpBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			break
+		}
+		for _, entry := range entries {
+			fmt.Println(formatEntry(decoder, entry, showValue))
+			if count++; limit > 0 && count >= limit {
+				return nil
+			}
+		}
+		lo = entries[len(entries)-1].Index + 1
+	}
+	return
+}
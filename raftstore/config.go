@@ -18,6 +18,7 @@ import (
 	"fmt"
 
 	"github.com/cubefs/cubefs/depends/tiglabs/raft/proto"
+	"github.com/cubefs/cubefs/depends/tiglabs/raft/storage"
 )
 
 // Constants for network port definition.
@@ -52,8 +53,15 @@ type Config struct {
 	// We suggest to use ElectionTick = 10 * HeartbeatTick to avoid unnecessary leader switching.
 	// The default value is 1s.
 	ElectionTick int
+
+	// NewLogStorage creates the raft log storage of a partition in the given directory.
+	// The file WAL is used if it is not set.
+	NewLogStorage LogStorageFactory
 }
 
+// LogStorageFactory creates the raft log storage of a partition.
+type LogStorageFactory func(walPath string) (storage.Storage, error)
+
 // PeerAddress defines the set of addresses that will be used by the peers.
 type PeerAddress struct {
 	proto.Peer
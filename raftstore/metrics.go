@@ -0,0 +1,197 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package raftstore
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cubefs/cubefs/depends/tiglabs/raft"
+	"github.com/cubefs/cubefs/depends/tiglabs/raft/proto"
+	"github.com/cubefs/cubefs/util/exporter"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	metricsCollectInterval = time.Minute
+
+	MetricRaftApplyLag        = "raft_apply_lag"
+	MetricRaftFollowerLag     = "raft_follower_lag"
+	MetricRaftLogSize         = "raft_log_size"
+	MetricRaftSnapshotApplyMs = "raft_snapshot_apply_ms"
+)
+
+type raftMetrics struct {
+	applyLag        *exporter.GaugeVec
+	followerLag     *exporter.GaugeVec
+	logSize         *exporter.GaugeVec
+	snapshotApplyMs *exporter.GaugeVec
+}
+
+// init registers the gauges lazily, the exporter may not be initialized when the raft store is created.
+func (m *raftMetrics) init() bool {
+	if m.applyLag != nil {
+		return true
+	}
+	applyLag := exporter.NewGaugeVec(MetricRaftApplyLag, "committed but not applied raft log entries", []string{"partitionID"})
+	if applyLag == nil {
+		return false
+	}
+	m.applyLag = applyLag
+	m.followerLag = exporter.NewGaugeVec(MetricRaftFollowerLag, "max log entries a follower is behind the leader", []string{"partitionID"})
+	m.logSize = exporter.NewGaugeVec(MetricRaftLogSize, "bytes of the raft wal", []string{"partitionID"})
+	m.snapshotApplyMs = exporter.NewGaugeVec(MetricRaftSnapshotApplyMs, "duration of the last snapshot applied", []string{"partitionID"})
+	return true
+}
+
+func (m *raftMetrics) set(v *exporter.GaugeVec, val float64, id string) {
+	if v != nil {
+		v.SetWithLabelValues(val, id)
+	}
+}
+
+func (m *raftMetrics) remove(id string) {
+	for _, v := range []*exporter.GaugeVec{m.applyLag, m.followerLag, m.logSize, m.snapshotApplyMs} {
+		if v != nil {
+			v.DeleteLabelValues(id)
+		}
+	}
+}
+
+// metricsFsm records the duration of applying snapshot of the wrapped state machine.
+type metricsFsm struct {
+	PartitionFsm
+	snapshotApplyMs int64
+}
+
+func (f *metricsFsm) ApplySnapshot(peers []proto.Peer, iter proto.SnapIterator) (err error) {
+	start := time.Now()
+	err = f.PartitionFsm.ApplySnapshot(peers, iter)
+	atomic.StoreInt64(&f.snapshotApplyMs, time.Since(start).Milliseconds())
+	return
+}
+
+type lagAlarm struct {
+	mu      sync.Mutex
+	lagging map[uint64]map[uint64]uint64 // partitionID -> peerID -> lag
+}
+
+// followerLag returns the max lag of the followers if this node is the leader, and the
+// followers whose lag exceeds the threshold.
+func followerLag(status *raft.Status, threshold uint64) (maxLag uint64, lagging map[uint64]uint64) {
+	if status == nil || status.Leader != status.NodeID {
+		return
+	}
+	lagging = make(map[uint64]uint64)
+	for id, rs := range status.Replicas {
+		if id == status.NodeID || status.Index < rs.Match {
+			continue
+		}
+		lag := status.Index - rs.Match
+		if lag > maxLag {
+			maxLag = lag
+		}
+		if threshold > 0 && lag > threshold {
+			lagging[id] = lag
+		}
+	}
+	return
+}
+
+// check reports the followers which begin to lag behind the leader.
+func (a *lagAlarm) check(id uint64, lagging map[uint64]uint64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	old := a.lagging[id]
+	for peerID, lag := range lagging {
+		if _, ok := old[peerID]; ok {
+			continue
+		}
+		errMsg := fmt.Sprintf("[RaftFollowerLag] raft follower lag behind, partitionID[%d] peerID[%d] lag[%d] threshold[%d]",
+			id, peerID, lag, gMonConf.FollowerLagThreshold)
+		log.LogError(errMsg)
+		exporter.Warning(errMsg)
+	}
+	if len(lagging) == 0 {
+		delete(a.lagging, id)
+		return
+	}
+	a.lagging[id] = lagging
+}
+
+func (a *lagAlarm) remove(id uint64) {
+	a.mu.Lock()
+	delete(a.lagging, id)
+	a.mu.Unlock()
+}
+
+func walSize(walPath string) (size int64) {
+	filepath.Walk(walPath, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return
+}
+
+func (s *raftStore) collectMetrics() {
+	ticker := time.NewTicker(metricsCollectInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stopC:
+			return
+		case <-ticker.C:
+			s.doCollectMetrics()
+		}
+	}
+}
+
+func (s *raftStore) doCollectMetrics() {
+	enabled := s.metrics.init()
+	s.partitions.Range(func(key, value interface{}) bool {
+		p := value.(*partition)
+		id := strconv.FormatUint(p.id, 10)
+		status := p.Status()
+		if status == nil || status.Stopped {
+			s.partitions.Delete(key)
+			s.lagAlarm.remove(p.id)
+			if enabled {
+				s.metrics.remove(id)
+			}
+			return true
+		}
+		maxLag, lagging := followerLag(status, gMonConf.FollowerLagThreshold)
+		s.lagAlarm.check(p.id, lagging)
+		if !enabled {
+			return true
+		}
+		var applyLag uint64
+		if status.Commit > status.Applied {
+			applyLag = status.Commit - status.Applied
+		}
+		s.metrics.set(s.metrics.applyLag, float64(applyLag), id)
+		s.metrics.set(s.metrics.followerLag, float64(maxLag), id)
+		s.metrics.set(s.metrics.logSize, float64(walSize(p.walPath)), id)
+		s.metrics.set(s.metrics.snapshotApplyMs, float64(atomic.LoadInt64(&p.fsm.snapshotApplyMs)), id)
+		return true
+	})
+}
@@ -0,0 +1,56 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package raftstore
+
+import (
+	"testing"
+
+	"github.com/cubefs/cubefs/depends/tiglabs/raft"
+)
+
+func TestFollowerLag(t *testing.T) {
+	status := &raft.Status{
+		NodeID: 1,
+		Leader: 1,
+		Index:  1000,
+		Replicas: map[uint64]*raft.ReplicaStatus{
+			1: {Match: 1000},
+			2: {Match: 990},
+			3: {Match: 100},
+		},
+	}
+	maxLag, lagging := followerLag(status, 500)
+	if maxLag != 900 {
+		t.Errorf("max lag %v expect 900", maxLag)
+	}
+	if len(lagging) != 1 || lagging[3] != 900 {
+		t.Errorf("lagging followers %v expect only peer 3", lagging)
+	}
+
+	status.Leader = 2
+	if maxLag, lagging = followerLag(status, 500); maxLag != 0 || len(lagging) != 0 {
+		t.Errorf("follower should not report lag, maxLag %v lagging %v", maxLag, lagging)
+	}
+
+	a := &lagAlarm{lagging: make(map[uint64]map[uint64]uint64)}
+	a.check(1, map[uint64]uint64{3: 900})
+	if len(a.lagging[1]) != 1 {
+		t.Errorf("lagging peers %v", a.lagging[1])
+	}
+	a.check(1, map[uint64]uint64{})
+	if _, ok := a.lagging[1]; ok {
+		t.Errorf("partition should be removed after followers catch up")
+	}
+}
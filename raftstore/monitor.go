@@ -15,6 +15,8 @@ const (
 	defaultReportDuration    = time.Minute * 3
 	defaultZombieThreshold   = time.Minute * 3
 	defaultNoLeaderThreshold = time.Second * 30
+	// raft log entries a follower may fall behind the leader before alarm
+	defaultFollowerLagThreshold = 100000
 )
 
 const (
@@ -22,6 +24,7 @@ const (
 	cfgZombieTooLongThresholdSec   = "raftMonZombieTooLongThrSec"
 	cfgNoLeaderThresholdSec        = "raftMonNoLeaderThrSec"
 	cfgNoLeaderTooLongThresholdSec = "raftMonNoLeaderTooLongThrSec"
+	cfgFollowerLagThreshold        = "raftMonFollowerLagThr"
 )
 
 type monitorConf struct {
@@ -29,6 +32,7 @@ type monitorConf struct {
 	ZombieTooLongThreshold   time.Duration
 	NoLeaderThreshold        time.Duration
 	NoLeaderTooLongThreshold time.Duration
+	FollowerLagThreshold     uint64
 }
 
 var gMonConf = monitorConf{
@@ -36,6 +40,7 @@ var gMonConf = monitorConf{
 	ZombieTooLongThreshold:   defaultReportDuration,
 	NoLeaderThreshold:        defaultNoLeaderThreshold,
 	NoLeaderTooLongThreshold: defaultReportDuration,
+	FollowerLagThreshold:     defaultFollowerLagThreshold,
 }
 
 func setMonitorConf(cfg *config.Config) {
@@ -63,10 +68,15 @@ func setMonitorConf(cfg *config.Config) {
 		gMonConf.NoLeaderTooLongThreshold = time.Second * time.Duration(cfgNoLeaderTooLongThr)
 	}
 
+	cfgFollowerLagThr := cfg.GetInt64(cfgFollowerLagThreshold)
+	if cfgFollowerLagThr > 0 {
+		gMonConf.FollowerLagThreshold = uint64(cfgFollowerLagThr)
+	}
+
 	log.LogInfof("set raft monitor cfg: zombieThreshold:[%v], zombieTooLongThreshold:[%v],"+
-		" noLeaderThreshold:[%v], noLeaderTooLongThreshold:[%v]",
+		" noLeaderThreshold:[%v], noLeaderTooLongThreshold:[%v], followerLagThreshold:[%v]",
 		gMonConf.ZombieThreshold, gMonConf.ZombieTooLongThreshold,
-		gMonConf.NoLeaderThreshold, gMonConf.NoLeaderTooLongThreshold)
+		gMonConf.NoLeaderThreshold, gMonConf.NoLeaderTooLongThreshold, gMonConf.FollowerLagThreshold)
 }
 
 type zombiePeer struct {
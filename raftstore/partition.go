@@ -15,6 +15,7 @@
 package raftstore
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/cubefs/cubefs/depends/tiglabs/raft"
@@ -76,6 +77,7 @@ type partition struct {
 	raft    *raft.RaftServer
 	walPath string
 	config  *PartitionConfig
+	fsm     *metricsFsm
 }
 
 // ChangeMember submits member change event and information to raft log.
@@ -182,11 +184,29 @@ func (p *partition) Truncate(index uint64) {
 	}
 }
 
-func newPartition(cfg *PartitionConfig, raft *raft.RaftServer, walPath string) Partition {
+func newPartition(cfg *PartitionConfig, raft *raft.RaftServer, walPath string, fsm *metricsFsm) *partition {
 	return &partition{
 		id:      cfg.ID,
 		raft:    raft,
 		walPath: walPath,
 		config:  cfg,
+		fsm:     fsm,
 	}
 }
+
+// CheckLearnerCaughtUp returns an error if the learner lags more than maxLag raft logs
+// behind the committed index. The status must be the one of the leader.
+func CheckLearnerCaughtUp(status *PartitionStatus, peerID, maxLag uint64) error {
+	if status == nil || status.Leader != status.NodeID {
+		return raft.ErrNotLeader
+	}
+	rs, ok := status.Replicas[peerID]
+	if !ok {
+		return fmt.Errorf("peer(%v) not found in raft(%v)", peerID, status.ID)
+	}
+	if rs.Match+maxLag < status.Commit {
+		return fmt.Errorf("learner(%v) of raft(%v) not caught up, match(%v) commit(%v) maxLag(%v)",
+			peerID, status.ID, rs.Match, status.Commit, maxLag)
+	}
+	return nil
+}
@@ -20,11 +20,13 @@ import (
 	"os"
 	"path"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/cubefs/cubefs/depends/tiglabs/raft"
 	"github.com/cubefs/cubefs/depends/tiglabs/raft/logger"
 	"github.com/cubefs/cubefs/depends/tiglabs/raft/proto"
+	"github.com/cubefs/cubefs/depends/tiglabs/raft/storage"
 	"github.com/cubefs/cubefs/depends/tiglabs/raft/storage/wal"
 	raftlog "github.com/cubefs/cubefs/depends/tiglabs/raft/util/log"
 	utilConfig "github.com/cubefs/cubefs/util/config"
@@ -46,6 +48,13 @@ type raftStore struct {
 	raftConfig *raft.Config
 	raftServer *raft.RaftServer
 	raftPath   string
+
+	newLogStorage LogStorageFactory
+	partitions    sync.Map // partitionID -> *partition
+	metrics       raftMetrics
+	lagAlarm      lagAlarm
+	stopOnce      sync.Once
+	stopC         chan struct{}
 }
 
 // RaftConfig returns the raft configuration.
@@ -69,6 +78,9 @@ func (s *raftStore) DeleteNode(nodeID uint64) {
 
 // Stop stops the raft store server.
 func (s *raftStore) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stopC)
+	})
 	if s.raftServer != nil {
 		s.raftServer.Stop()
 	}
@@ -134,13 +146,18 @@ func NewRaftStore(cfg *Config, extendCfg *utilConfig.Config) (mr RaftStore, err
 	if err != nil {
 		return
 	}
-	mr = &raftStore{
-		nodeID:     cfg.NodeID,
-		resolver:   resolver,
-		raftConfig: rc,
-		raftServer: rs,
-		raftPath:   cfg.RaftPath,
-	}
+	store := &raftStore{
+		nodeID:        cfg.NodeID,
+		resolver:      resolver,
+		raftConfig:    rc,
+		raftServer:    rs,
+		raftPath:      cfg.RaftPath,
+		newLogStorage: cfg.NewLogStorage,
+		lagAlarm:      lagAlarm{lagging: make(map[uint64]map[uint64]uint64)},
+		stopC:         make(chan struct{}),
+	}
+	go store.collectMetrics()
+	mr = store
 	return
 }
 
@@ -162,8 +179,13 @@ func (s *raftStore) CreatePartition(cfg *PartitionConfig) (p Partition, err erro
 		walPath = path.Join(cfg.WalPath, "wal_"+strconv.FormatUint(cfg.ID, 10))
 	}
 
-	wc := &wal.Config{}
-	ws, err := wal.NewStorage(walPath, wc)
+	var ws storage.Storage
+	if s.newLogStorage != nil {
+		ws, err = s.newLogStorage(walPath)
+	} else {
+		wc := &wal.Config{}
+		ws, err = wal.NewStorage(walPath, wc)
+	}
 	if err != nil {
 		return
 	}
@@ -177,6 +199,7 @@ func (s *raftStore) CreatePartition(cfg *PartitionConfig) (p Partition, err erro
 			peerAddress.ReplicaPort,
 		)
 	}
+	fsm := &metricsFsm{PartitionFsm: cfg.SM}
 	logger.Info("action[raftstore:CreatePartition] raft config applied [%v] id:%d", cfg.Applied, cfg.ID)
 	rc := &raft.RaftConfig{
 		ID:           cfg.ID,
@@ -184,13 +207,15 @@ func (s *raftStore) CreatePartition(cfg *PartitionConfig) (p Partition, err erro
 		Leader:       cfg.Leader,
 		Term:         cfg.Term,
 		Storage:      ws,
-		StateMachine: cfg.SM,
+		StateMachine: fsm,
 		Applied:      cfg.Applied,
 		Monitor:      newMonitor(),
 	}
 	if err = s.raftServer.CreateRaft(rc); err != nil {
 		return
 	}
-	p = newPartition(cfg, s.raftServer, walPath)
+	rp := newPartition(cfg, s.raftServer, walPath, fsm)
+	s.partitions.Store(cfg.ID, rp)
+	p = rp
 	return
 }
@@ -451,6 +451,11 @@ func (p *Packet) identificationErrorResultCode(errLog string, errMsg string) {
 		p.ResultCode = proto.OpTryOtherAddr
 	} else if strings.Contains(errMsg, raft.ErrStopped.Error()) {
 		p.ResultCode = proto.OpTryOtherAddr
+	} else if strings.Contains(errMsg, storage.ExtentDefragFencedError.Error()) {
+		// the client reloads the extent keys, which are replaced by the defragmentation soon
+		p.ResultCode = proto.ErrCodeVersionOpError
+	} else if strings.Contains(errMsg, storage.ExtentDefragFenceBrokenError.Error()) {
+		p.ResultCode = proto.OpConflictExtentsErr
 	} else if strings.Contains(errMsg, storage.VerNotConsistentError.Error()) {
 		p.ResultCode = proto.ErrCodeVersionOpError
 		// log.LogDebugf("action[identificationErrorResultCode] not change ver erro code, (%v)", string(debug.Stack()))
@@ -495,6 +500,7 @@ func (p *Packet) IsMasterCommand() bool {
 		proto.OpAddDataPartitionRaftMember,
 		proto.OpRemoveDataPartitionRaftMember,
 		proto.OpDataPartitionTryToLeader,
+		proto.OpPromoteDataPartitionLearner,
 		proto.OpRecoverBackupDataReplica:
 		return true
 	default:
@@ -62,6 +62,7 @@ type (
 	AppendExtentKeyFunc func(parentInode, inode uint64, key proto.ExtentKey, discard []proto.ExtentKey) (int, error)
 	GetExtentsFunc      func(inode uint64) (uint64, uint64, []proto.ExtentKey, error)
 	TruncateFunc        func(inode, size uint64, fullPath string) error
+	ModifyInodeFunc     func(inode, offset, size uint64) error
 	EvictIcacheFunc     func(inode uint64)
 	LoadBcacheFunc      func(key string, buf []byte, offset uint64, size uint32) (int, error)
 	CacheBcacheFunc     func(key string, buf []byte) error
@@ -134,6 +135,7 @@ type ExtentConfig struct {
 	OnSplitExtentKey  SplitExtentKeyFunc
 	OnGetExtents      GetExtentsFunc
 	OnTruncate        TruncateFunc
+	OnModifyInode     ModifyInodeFunc
 	OnEvictIcache     EvictIcacheFunc
 	OnLoadBcache      LoadBcacheFunc
 	OnCacheBcache     CacheBcacheFunc
@@ -171,6 +173,7 @@ type ExtentClient struct {
 	splitExtentKey     SplitExtentKeyFunc
 	getExtents         GetExtentsFunc
 	truncate           TruncateFunc
+	modifyInode        ModifyInodeFunc // May be null, must check before using
 	evictIcache        EvictIcacheFunc // May be null, must check before using
 	loadBcache         LoadBcacheFunc
 	cacheBcache        CacheBcacheFunc
@@ -278,6 +281,7 @@ retry:
 
 	client.appendExtentKey = config.OnAppendExtentKey
 	client.splitExtentKey = config.OnSplitExtentKey
+	client.modifyInode = config.OnModifyInode
 	client.getExtents = config.OnGetExtents
 	client.truncate = config.OnTruncate
 	client.evictIcache = config.OnEvictIcache
@@ -335,6 +339,17 @@ func (client *ExtentClient) GetEnablePosixAcl() bool {
 	return client.dataWrapper.EnablePosixAcl
 }
 
+func (client *ExtentClient) GetPermissionMode() uint8 {
+	return client.dataWrapper.PermissionMode
+}
+
+// shouldReportOverwrite tells whether the in-place overwrites are reported to the meta node.
+// The volume mirror relies on them to find the overwritten ranges in the change log, and the
+// extent defragmentation checks them besides the fence of the copied extents on the data nodes.
+func (client *ExtentClient) shouldReportOverwrite() bool {
+	return client.modifyInode != nil && (client.dataWrapper.EnableDefrag || client.dataWrapper.EnableMirror)
+}
+
 func (client *ExtentClient) GetFlowInfo() (*proto.ClientReportLimitInfo, bool) {
 	log.LogInfof("action[ExtentClient.GetFlowInfo]")
 	return client.LimitManager.GetFlowInfo()
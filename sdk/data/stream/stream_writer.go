@@ -298,10 +298,42 @@ func (s *Streamer) handleRequest(request interface{}) {
 	}
 }
 
+// overwriteRange is the range of a write overwritten in place.
+type overwriteRange struct {
+	start, end int
+}
+
+func (r *overwriteRange) add(offset, size int) {
+	if size <= 0 {
+		return
+	}
+	if r.end == r.start || offset < r.start {
+		r.start = offset
+	}
+	if offset+size > r.end {
+		r.end = offset + size
+	}
+}
+
+// reportOverwrite reports the range overwritten in place to the meta node. The write fails if
+// the overwrite is not reported, otherwise the mirror never copies the overwritten range.
+func (s *Streamer) reportOverwrite(r overwriteRange) (err error) {
+	if r.end <= r.start || !s.client.shouldReportOverwrite() {
+		return
+	}
+	if err = s.client.modifyInode(s.inode, uint64(r.start), uint64(r.end-r.start)); err != nil {
+		log.LogErrorf("Streamer write: ino(%v) report overwrite offset(%v) size(%v) err(%v)",
+			s.inode, r.start, r.end-r.start, err)
+	}
+	return
+}
+
 func (s *Streamer) write(data []byte, offset, size, flags int, checkFunc func() error) (total int, err error) {
 	var (
 		direct     bool
 		retryTimes int8
+		// the range overwritten in place, which is reported to the meta node after writing
+		overwritten overwriteRange
 	)
 
 	if flags&proto.FlagsSyncWrite != 0 {
@@ -373,6 +405,9 @@ begin:
 					goto begin
 				}
 				log.LogDebugf("action[streamer.write] err %v retryTimes %v", err, retryTimes)
+				if err == nil {
+					overwritten.add(req.FileOffset, writeSize)
+				}
 			} else {
 				log.LogDebugf("action[streamer.write] ino %v do OverWriteByAppend extent key (%v) because seq not equal", s.inode, req.ExtentKey)
 				writeSize, _, err, _ = s.doOverWriteByAppend(req, direct)
@@ -396,6 +431,9 @@ begin:
 		}
 		total += writeSize
 	}
+	if mErr := s.reportOverwrite(overwritten); mErr != nil && err == nil {
+		err = mErr
+	}
 	filesize, _ := s.extents.Size()
 	if offset+total > filesize {
 		s.extents.SetSize(uint64(offset+total), false)
@@ -0,0 +1,65 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cubefs/cubefs/sdk/data/wrapper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportOverwrite(t *testing.T) {
+	var reported [][3]uint64
+	var reportErr error
+	client := &ExtentClient{
+		dataWrapper: &wrapper.Wrapper{},
+		modifyInode: func(inode, offset, size uint64) error {
+			reported = append(reported, [3]uint64{inode, offset, size})
+			return reportErr
+		},
+	}
+	s := &Streamer{client: client, inode: 10}
+
+	// the requests of a write overwritten in place, out of order and with an append between them
+	var r overwriteRange
+	r.add(8192, 4096)
+	r.add(16384, 0)
+	r.add(4096, 1024)
+	require.Equal(t, overwriteRange{start: 4096, end: 12288}, r)
+
+	// not reported unless the volume is defragmented or mirrored
+	require.NoError(t, s.reportOverwrite(r))
+	require.Empty(t, reported)
+
+	client.dataWrapper.EnableDefrag = true
+	require.NoError(t, s.reportOverwrite(r))
+	require.Equal(t, [][3]uint64{{10, 4096, 8192}}, reported)
+
+	// nothing is overwritten in place
+	require.NoError(t, s.reportOverwrite(overwriteRange{}))
+	require.Len(t, reported, 1)
+
+	client.dataWrapper.EnableDefrag = false
+	client.dataWrapper.EnableMirror = true
+	reportErr = errors.New("report failed")
+	require.Equal(t, reportErr, s.reportOverwrite(r))
+	require.Len(t, reported, 2)
+
+	// the clients without the report
+	client.modifyInode = nil
+	require.NoError(t, s.reportOverwrite(r))
+}
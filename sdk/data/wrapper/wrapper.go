@@ -58,6 +58,9 @@ type Wrapper struct {
 	volName               string
 	volType               int
 	EnablePosixAcl        bool
+	PermissionMode        uint8
+	EnableDefrag          bool
+	EnableMirror          bool
 	masters               []string
 	partitions            map[uint64]*DataPartition
 	followerRead          bool
@@ -213,6 +216,9 @@ func (w *Wrapper) GetSimpleVolView() (err error) {
 	w.dpSelectorParm = view.DpSelectorParm
 	w.volType = view.VolType
 	w.EnablePosixAcl = view.EnablePosixAcl
+	w.PermissionMode = view.PermissionMode
+	w.EnableDefrag = view.EnableDefrag
+	w.EnableMirror = view.EnableMirror
 	w.UpdateUidsView(view)
 
 	log.LogDebugf("GetSimpleVolView: get volume simple info: ID(%v) name(%v) owner(%v) status(%v) capacity(%v) "+
@@ -329,6 +335,18 @@ func (w *Wrapper) updateSimpleVolView() (err error) {
 
 	w.UpdateUidsView(view)
 
+	if w.EnableDefrag != view.EnableDefrag {
+		log.LogInfof("UpdateSimpleVolView: update enableDefrag from old(%v) to new(%v)",
+			w.EnableDefrag, view.EnableDefrag)
+		w.EnableDefrag = view.EnableDefrag
+	}
+
+	if w.EnableMirror != view.EnableMirror {
+		log.LogInfof("UpdateSimpleVolView: update enableMirror from old(%v) to new(%v)",
+			w.EnableMirror, view.EnableMirror)
+		w.EnableMirror = view.EnableMirror
+	}
+
 	if w.followerRead != view.FollowerRead && !w.followerReadClientCfg {
 		log.LogDebugf("UpdateSimpleVolView: update followerRead from old(%v) to new(%v)",
 			w.followerRead, view.FollowerRead)
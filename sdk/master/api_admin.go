@@ -226,6 +226,24 @@ func (api *AdminAPI) AddMetaReplica(metaPartitionID uint64, nodeAddr string, cli
 	return
 }
 
+func (api *AdminAPI) PromoteDataReplica(dataPartitionID uint64, nodeAddr, clientIDKey string) (err error) {
+	request := newRequest(get, proto.AdminPromoteDataReplica).Header(api.h)
+	request.addParam("id", strconv.FormatUint(dataPartitionID, 10))
+	request.addParam("addr", nodeAddr)
+	request.addParam("clientIDKey", clientIDKey)
+	_, err = api.mc.serveRequest(request)
+	return
+}
+
+func (api *AdminAPI) PromoteMetaReplica(metaPartitionID uint64, nodeAddr string, clientIDKey string) (err error) {
+	request := newRequest(get, proto.AdminPromoteMetaReplica).Header(api.h)
+	request.addParam("id", strconv.FormatUint(metaPartitionID, 10))
+	request.addParam("addr", nodeAddr)
+	request.addParam("clientIDKey", clientIDKey)
+	_, err = api.mc.serveRequest(request)
+	return
+}
+
 func (api *AdminAPI) QueryDataPartitionDecommissionStatus(partitionId uint64) (info *proto.DecommissionDataPartitionInfo, err error) {
 	request := newRequest(get, proto.AdminQueryDataPartitionDecommissionStatus).Header(api.h)
 	request.addParam("id", strconv.FormatUint(partitionId, 10))
@@ -290,6 +308,10 @@ func (api *AdminAPI) UpdateVolume(
 	request.addParam("dpReadOnlyWhenVolFull", strconv.FormatBool(vv.DpReadOnlyWhenVolFull))
 	request.addParam("replicaNum", strconv.FormatUint(uint64(vv.DpReplicaNum), 10))
 	request.addParam("enableQuota", strconv.FormatBool(vv.EnableQuota))
+	request.addParam("permissionMode", strconv.Itoa(int(vv.PermissionMode)))
+	request.addParam("enableDedup", strconv.FormatBool(vv.EnableDedup))
+	request.addParam("enableDefrag", strconv.FormatBool(vv.EnableDefrag))
+	request.addParam("enableMirror", strconv.FormatBool(vv.EnableMirror))
 	request.addParam("deleteLockTime", strconv.FormatInt(vv.DeleteLockTime, 10))
 	request.addParam("autoDpMetaRepair", strconv.FormatBool(vv.EnableAutoDpMetaRepair))
 	request.addParam("clientIDKey", clientIDKey)
@@ -757,6 +779,24 @@ func (api *AdminAPI) DelBucketLifecycle(volume string) (err error) {
 	return
 }
 
+func (api *AdminAPI) SetBucketInventory(req *proto.InventoryConfigurations) (err error) {
+	return api.mc.request(newRequest(post, proto.SetBucketInventory).Header(api.h).Body(req))
+}
+
+func (api *AdminAPI) GetBucketInventory(volume string) (invConf *proto.InventoryConfigurations, err error) {
+	invConf = &proto.InventoryConfigurations{}
+	err = api.mc.requestWith(invConf, newRequest(get, proto.GetBucketInventory).
+		Header(api.h).addParam("name", volume))
+	return
+}
+
+func (api *AdminAPI) DelBucketInventory(volume string) (err error) {
+	request := newRequest(get, proto.DeleteBucketInventory).Header(api.h)
+	request.addParam("name", volume)
+	_, err = api.mc.serveRequest(request)
+	return
+}
+
 func (api *AdminAPI) GetS3QoSInfo() (data []byte, err error) {
 	return api.mc.serveRequest(newRequest(get, proto.S3QoSGet).Header(api.h))
 }
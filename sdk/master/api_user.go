@@ -84,6 +84,22 @@ func (api *UserAPI) UidOperation(volName string, uid string, op uint32, val stri
 	return
 }
 
+// UidUserOperation maps the S3 user to the uid and gid of the volume, or lists the mappings of the volume.
+func (api *UserAPI) UidUserOperation(volName string, userID string, op uint32, uid, gid string) (uidInfo *proto.UidSpaceRsp, err error) {
+	uidInfo = &proto.UidSpaceRsp{}
+	if err = api.mc.requestWith(uidInfo, newRequest(get, proto.AdminUid).Header(api.h).Param(
+		anyParam{"name", volName},
+		anyParam{"user", userID},
+		anyParam{"op", op},
+		anyParam{"uid", uid},
+		anyParam{"gid", gid},
+	)); err != nil {
+		fmt.Fprintf(os.Stdout, "UidUserOperation err %v\n", err)
+		return
+	}
+	return
+}
+
 func (api *UserAPI) GetUserInfo(userID string) (userInfo *proto.UserInfo, err error) {
 	userInfo = &proto.UserInfo{}
 	err = api.mc.requestWith(userInfo, newRequest(get, proto.UserGetInfo).Header(api.h).addParam("user", userID))
@@ -127,3 +143,34 @@ func (api *UserAPI) ListUsersOfVol(vol string) (users []string, err error) {
 	err = api.mc.requestWith(&users, newRequest(get, proto.UsersOfVol).Header(api.h).addParam("name", vol))
 	return
 }
+
+func (api *UserAPI) CreateRole(param *proto.RoleCreateParam, clientIDKey string) (roleInfo *proto.RoleInfo, err error) {
+	roleInfo = &proto.RoleInfo{}
+	err = api.mc.requestWith(roleInfo, newRequest(post, proto.RoleCreate).
+		Header(api.h).Body(param).addParam("clientIDKey", clientIDKey))
+	return
+}
+
+func (api *UserAPI) DeleteRole(roleName string, clientIDKey string) (err error) {
+	return api.mc.request(newRequest(post, proto.RoleDelete).Header(api.h).
+		addParam("role", roleName).addParam("clientIDKey", clientIDKey))
+}
+
+func (api *UserAPI) UpdateRole(param *proto.RoleUpdateParam, clientIDKey string) (roleInfo *proto.RoleInfo, err error) {
+	roleInfo = &proto.RoleInfo{}
+	err = api.mc.requestWith(roleInfo, newRequest(post, proto.RoleUpdate).
+		Header(api.h).Body(param).addParam("clientIDKey", clientIDKey))
+	return
+}
+
+func (api *UserAPI) GetRoleInfo(roleName string) (roleInfo *proto.RoleInfo, err error) {
+	roleInfo = &proto.RoleInfo{}
+	err = api.mc.requestWith(roleInfo, newRequest(get, proto.RoleGetInfo).Header(api.h).addParam("role", roleName))
+	return
+}
+
+func (api *UserAPI) ListRoles(keywords string) (roles []*proto.RoleInfo, err error) {
+	roles = make([]*proto.RoleInfo, 0)
+	err = api.mc.requestWith(&roles, newRequest(get, proto.RoleList).Header(api.h).addParam("keywords", keywords))
+	return
+}
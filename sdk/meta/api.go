@@ -15,7 +15,6 @@
 package meta
 
 import (
-	"errors"
 	"fmt"
 	syslog "log"
 	"math"
@@ -629,6 +628,11 @@ func (mw *MetaWrapper) shouldNotMoveToTrash(parentMP *MetaPartition, parentIno u
  * and the caller should make sure InodeInfo is valid before using it.
  */
 func (mw *MetaWrapper) Delete_ll(parentID uint64, name string, isDir bool, fullPath string) (*proto.InodeInfo, error) {
+	if !isDir && mw.ObjectLockEnabled() {
+		if err := mw.isObjectLockedByName(parentID, name); err != nil {
+			return nil, err
+		}
+	}
 	if mw.enableTx(proto.TxOpMaskRemove) {
 		return mw.txDelete_ll(parentID, name, isDir, fullPath)
 	} else {
@@ -637,7 +641,13 @@ func (mw *MetaWrapper) Delete_ll(parentID uint64, name string, isDir bool, fullP
 }
 
 func (mw *MetaWrapper) DeleteWithCond_ll(parentID, cond uint64, name string, isDir bool, fullPath string) (*proto.InodeInfo, error) {
-	return mw.deletewithcond_ll(parentID, cond, name, isDir, fullPath)
+	return mw.deletewithcond_ll(parentID, cond, name, isDir, false, fullPath)
+}
+
+// DeleteWithCondBypassGovernance_ll is the same as DeleteWithCond_ll except that
+// the GOVERNANCE mode retention of the object lock is bypassed.
+func (mw *MetaWrapper) DeleteWithCondBypassGovernance_ll(parentID, cond uint64, name string, isDir bool, fullPath string) (*proto.InodeInfo, error) {
+	return mw.deletewithcond_ll(parentID, cond, name, isDir, true, fullPath)
 }
 
 func (mw *MetaWrapper) Delete_Ver_ll(parentID uint64, name string, isDir bool, verSeq uint64, fullPath string) (*proto.InodeInfo, error) {
@@ -923,6 +933,10 @@ func (mw *MetaWrapper) Delete_ll_EX(parentID uint64, name string, isDir bool, ve
 	status, info, err = mw.iunlink(mp, inode, verSeq, denVer, fullPath)
 	if err != nil || status != statusOK {
 		log.LogDebugf("action[Delete_ll] parentID %v inode %v name %v verSeq %v err %v", parentID, inode, name, verSeq, err)
+		if status == statusNotPerm {
+			mw.restoreLockedDentry(parentMP, mp, parentID, name, inode, fullPath)
+			return nil, syscall.EPERM
+		}
 		return nil, nil
 	}
 
@@ -939,28 +953,8 @@ func (mw *MetaWrapper) Delete_ll_EX(parentID uint64, name string, isDir bool, ve
 	return info, nil
 }
 
-func isObjectLocked(mw *MetaWrapper, inode uint64, name string) error {
-	xattrInfo, err := mw.XAttrGet_ll(inode, "oss:lock")
-	if err != nil {
-		log.LogErrorf("isObjectLocked: check ObjectLock err(%v) name(%v)", err, name)
-		return err
-	}
-	retainUntilDate := xattrInfo.Get("oss:lock")
-	if len(retainUntilDate) > 0 {
-		retainUntilDateInt64, err := strconv.ParseInt(string(retainUntilDate), 10, 64)
-		if err != nil {
-			return err
-		}
-		if retainUntilDateInt64 > time.Now().UnixNano() {
-			log.LogWarnf("isObjectLocked: object is locked, retainUntilDate(%v) name(%v)", retainUntilDateInt64, name)
-			return errors.New("Access Denied")
-		}
-	}
-	return nil
-}
-
-func (mw *MetaWrapper) deletewithcond_ll(parentID, cond uint64, name string, isDir bool, fullPath string) (*proto.InodeInfo, error) {
-	err := isObjectLocked(mw, cond, name)
+func (mw *MetaWrapper) deletewithcond_ll(parentID, cond uint64, name string, isDir, bypassGovernance bool, fullPath string) (*proto.InodeInfo, error) {
+	err := mw.isObjectLocked(cond, bypassGovernance)
 	if err != nil {
 		return nil, err
 	}
@@ -1040,8 +1034,12 @@ func (mw *MetaWrapper) deletewithcond_ll(parentID, cond uint64, name string, isD
 		return nil, nil
 	}
 
-	status, info, err = mw.iunlink(mp, resp.Items[0].Inode, 0, 0, fullPath)
+	status, info, err = mw.iunlinkWork(mp, resp.Items[0].Inode, 0, 0, fullPath, bypassGovernance)
 	if err != nil || status != statusOK {
+		if status == statusNotPerm {
+			mw.restoreLockedDentry(parentMP, mp, parentID, name, resp.Items[0].Inode, fullPath)
+			return nil, syscall.EPERM
+		}
 		return nil, nil
 	}
 	log.LogDebugf("delete_ll name %v ino %v", name, info.Inode)
@@ -1063,6 +1061,14 @@ func (mw *MetaWrapper) deletewithcond_ll(parentID, cond uint64, name string, isD
 }
 
 func (mw *MetaWrapper) Rename_ll(srcParentID uint64, srcName string, dstParentID uint64, dstName string, srcFullPath string, dstFullPath string, overwritten bool) (err error) {
+	if mw.ObjectLockEnabled() {
+		if err = mw.isObjectLockedByName(srcParentID, srcName); err != nil {
+			return
+		}
+		if err = mw.isObjectLockedByName(dstParentID, dstName); err != nil {
+			return
+		}
+	}
 	if mw.enableTx(proto.TxOpMaskRename) {
 		return mw.txRename_ll(srcParentID, srcName, dstParentID, dstName, srcFullPath, dstFullPath, overwritten)
 	} else {
@@ -1324,7 +1330,7 @@ func (mw *MetaWrapper) rename_ll(srcParentID uint64, srcName string, dstParentID
 			return syscall.EEXIST
 		}
 
-		status, oldInode, err = mw.dupdate(dstParentMP, dstParentID, dstName, inode, dstFullPath)
+		status, oldInode, err = mw.dupdate(dstParentMP, dstParentID, dstName, inode, 0, dstFullPath)
 		if err != nil {
 			return syscall.EAGAIN
 		}
@@ -1353,7 +1359,7 @@ func (mw *MetaWrapper) rename_ll(srcParentID uint64, srcName string, dstParentID
 		if oldInode == 0 {
 			sts, inode, denVer, e = mw.ddelete(dstParentMP, dstParentID, dstName, 0, lastVerSeq, dstFullPath)
 		} else {
-			sts, denVer, e = mw.dupdate(dstParentMP, dstParentID, dstName, oldInode, dstFullPath)
+			sts, denVer, e = mw.dupdate(dstParentMP, dstParentID, dstName, oldInode, 0, dstFullPath)
 		}
 		if e == nil && sts == statusOK {
 			mw.iunlink(srcMP, inode, lastVerSeq, denVer, srcFullPath)
@@ -1506,7 +1512,24 @@ func (mw *MetaWrapper) DentryUpdate_ll(parentID uint64, name string, inode uint6
 		return
 	}
 	var status int
-	status, oldInode, err = mw.dupdate(parentMP, parentID, name, inode, fullPath)
+	status, oldInode, err = mw.dupdate(parentMP, parentID, name, inode, 0, fullPath)
+	if err != nil || status != statusOK {
+		err = statusToErrno(status)
+		return
+	}
+	return
+}
+
+// DentryUpdateWithCond_ll updates the dentry to the inode only if it still refers to the condInode,
+// it returns EINVAL if the dentry has been changed by others, or ENOENT if it has been deleted.
+func (mw *MetaWrapper) DentryUpdateWithCond_ll(parentID uint64, name string, inode, condInode uint64, fullPath string) (oldInode uint64, err error) {
+	parentMP := mw.getPartitionByInode(parentID)
+	if parentMP == nil {
+		err = syscall.ENOENT
+		return
+	}
+	var status int
+	status, oldInode, err = mw.dupdate(parentMP, parentID, name, inode, condInode, fullPath)
 	if err != nil || status != statusOK {
 		err = statusToErrno(status)
 		return
@@ -1593,6 +1616,72 @@ func (mw *MetaWrapper) AppendExtentKeys(inode uint64, eks []proto.ExtentKey) err
 	return nil
 }
 
+// ReplaceExtentKeys moves the extents of srcInode to inode and releases the old extents of inode.
+// Both inodes must be in the same meta partition, and StatusConflictExtents is returned if the
+// extents, the generation or the modify time of inode are no longer the same as read before.
+func (mw *MetaWrapper) ReplaceExtentKeys(inode, srcInode uint64, oldExtents []proto.ExtentKey, gen uint64, modifyTime int64) (int, error) {
+	mp := mw.getPartitionByInode(inode)
+	if mp == nil {
+		return statusError, syscall.ENOENT
+	}
+	if srcMp := mw.getPartitionByInode(srcInode); srcMp == nil || srcMp.PartitionID != mp.PartitionID {
+		return statusError, syscall.EXDEV
+	}
+
+	status, err := mw.replaceExtentKeys(mp, inode, srcInode, oldExtents, gen, modifyTime)
+	if err != nil || status != statusOK {
+		log.LogWarnf("ReplaceExtentKeys: inode(%v) srcInode(%v) err(%v) status(%v)", inode, srcInode, err, status)
+		return status, statusToErrno(status)
+	}
+	log.LogDebugf("ReplaceExtentKeys: ino(%v) srcIno(%v) oldExtents(%v)", inode, srcInode, len(oldExtents))
+	return statusOK, nil
+}
+
+// RemapExtentKeys maps extent keys of the inode to other extents with the same content.
+// StatusConflictExtents is returned if any of the old extent keys no longer exists, or the
+// generation or the modify time of the inode has changed.
+func (mw *MetaWrapper) RemapExtentKeys(inode uint64, remaps []proto.ExtentRemap, gen uint64, modifyTime int64) (int, error) {
+	mp := mw.getPartitionByInode(inode)
+	if mp == nil {
+		return statusError, syscall.ENOENT
+	}
+
+	status, err := mw.remapExtentKeys(mp, inode, remaps, gen, modifyTime)
+	if err != nil || status != statusOK {
+		log.LogWarnf("RemapExtentKeys: inode(%v) err(%v) status(%v)", inode, err, status)
+		return status, statusToErrno(status)
+	}
+	log.LogDebugf("RemapExtentKeys: ino(%v) remaps(%v)", inode, len(remaps))
+	return statusOK, nil
+}
+
+// InodeModify reports the range of the inode overwritten in place, so the modify time of the
+// inode is updated and the change is seen by the change log consumers.
+func (mw *MetaWrapper) InodeModify(inode, offset, size uint64) error {
+	mp := mw.getPartitionByInode(inode)
+	if mp == nil {
+		return syscall.ENOENT
+	}
+
+	status, err := mw.inodeModify(mp, inode, offset, size)
+	if err != nil || status != statusOK {
+		log.LogWarnf("InodeModify: inode(%v) offset(%v) size(%v) err(%v) status(%v)", inode, offset, size, err, status)
+		return statusToErrno(status)
+	}
+	return nil
+}
+
+// GetFragmentedInodes lists the files of the meta partition whose extent count reaches minExtents
+// or whose tiny extent count reaches minTinyExtents, starting from the marker inode. The files
+// referencing any of tinyExtents, which are keyed by data partition, are listed as well.
+func (mw *MetaWrapper) GetFragmentedInodes(pid, marker uint64, minExtents, minTinyExtents, limit uint32, tinyExtents map[uint64][]uint64) (*proto.GetFragmentedInodesResponse, error) {
+	mp := mw.getPartitionByID(pid)
+	if mp == nil {
+		return nil, syscall.ENOENT
+	}
+	return mw.getFragmentedInodes(mp, marker, minExtents, minTinyExtents, limit, tinyExtents)
+}
+
 // AppendObjExtentKeys append multiple obj extent key into specified inode with single request.
 func (mw *MetaWrapper) AppendObjExtentKeys(inode uint64, eks []proto.ObjExtentKey) error {
 	mp := mw.getPartitionByInode(inode)
@@ -1928,6 +2017,21 @@ get_rwmp:
 	return nil, syscall.ENOMEM
 }
 
+// InodeCreateNear_ll creates an inode in the same meta partition as the specified inode.
+func (mw *MetaWrapper) InodeCreateNear_ll(inode uint64, mode, uid, gid uint32) (*proto.InodeInfo, error) {
+	mp := mw.getPartitionByInode(inode)
+	if mp == nil {
+		log.LogErrorf("InodeCreateNear_ll: No such partition, ino(%v)", inode)
+		return nil, syscall.ENOENT
+	}
+	status, info, err := mw.icreate(mp, mode, uid, gid, nil, "")
+	if err != nil || status != statusOK {
+		log.LogErrorf("InodeCreateNear_ll: ino(%v) err(%v) status(%v)", inode, err, status)
+		return nil, statusToErrno(status)
+	}
+	return info, nil
+}
+
 // InodeUnlink_ll is a low-level api that makes specified inode link value +1.
 func (mw *MetaWrapper) InodeLink_ll(inode uint64, fullPath string) (*proto.InodeInfo, error) {
 	mp := mw.getPartitionByInode(inode)
@@ -2159,6 +2263,16 @@ func (mw *MetaWrapper) ListMultipart_ll(prefix, delimiter, keyMarker string, mul
 }
 
 func (mw *MetaWrapper) XAttrSet_ll(inode uint64, name, value []byte) error {
+	return mw.xattrSet(inode, name, value, false)
+}
+
+// XAttrSetBypassGovernance_ll sets the xattr, which is allowed to shorten the GOVERNANCE mode
+// retention of the inode, the caller must be authorized to bypass the governance retention.
+func (mw *MetaWrapper) XAttrSetBypassGovernance_ll(inode uint64, name, value []byte) error {
+	return mw.xattrSet(inode, name, value, true)
+}
+
+func (mw *MetaWrapper) xattrSet(inode uint64, name, value []byte, bypassGovernance bool) error {
 	var err error
 	mp := mw.getPartitionByInode(inode)
 	if mp == nil {
@@ -2166,7 +2280,7 @@ func (mw *MetaWrapper) XAttrSet_ll(inode uint64, name, value []byte) error {
 		return syscall.ENOENT
 	}
 	var status int
-	status, err = mw.setXAttr(mp, inode, name, value)
+	status, err = mw.setXAttr(mp, inode, name, value, bypassGovernance)
 	if err != nil || status != statusOK {
 		return statusToErrno(status)
 	}
@@ -2241,6 +2355,16 @@ func (mw *MetaWrapper) XAttrGet_ll(inode uint64, name string) (*proto.XAttrInfo,
 
 // XAttrDel_ll is a low-level meta api that deletes specified xattr.
 func (mw *MetaWrapper) XAttrDel_ll(inode uint64, name string) error {
+	return mw.xattrDel(inode, name, false)
+}
+
+// XAttrDelBypassGovernance_ll deletes the xattr, which is allowed to remove the GOVERNANCE mode
+// retention of the inode, the caller must be authorized to bypass the governance retention.
+func (mw *MetaWrapper) XAttrDelBypassGovernance_ll(inode uint64, name string) error {
+	return mw.xattrDel(inode, name, true)
+}
+
+func (mw *MetaWrapper) xattrDel(inode uint64, name string, bypassGovernance bool) error {
 	var err error
 	mp := mw.getPartitionByInode(inode)
 	if mp == nil {
@@ -2248,7 +2372,7 @@ func (mw *MetaWrapper) XAttrDel_ll(inode uint64, name string) error {
 		return syscall.ENOENT
 	}
 	var status int
-	status, err = mw.removeXAttr(mp, inode, name)
+	status, err = mw.removeXAttr(mp, inode, name, bypassGovernance)
 	if err != nil || status != statusOK {
 		return statusToErrno(status)
 	}
@@ -109,6 +109,7 @@ type MetaWrapper struct {
 	ossSecure         *OSSSecure
 	volCreateTime     int64
 	volDeleteLockTime int64
+	objectLock        int32
 	owner             string
 	ownerValidation   bool
 	mc                *masterSDK.MasterClient
@@ -310,6 +311,10 @@ func (mw *MetaWrapper) initMetaWrapper() (err error) {
 		return err
 	}
 
+	if err = mw.updateObjectLockEnabled(); err != nil {
+		log.LogWarnf("initMetaWrapper: updateObjectLockEnabled fail: volume(%v) err(%v)", mw.volname, err)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,94 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package meta
+
+import (
+	"sync/atomic"
+	"syscall"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+func (mw *MetaWrapper) isObjectLocked(inode uint64, bypassGovernance bool) error {
+	xattr, err := mw.XAttrGetAll_ll(inode)
+	if err != nil {
+		log.LogErrorf("isObjectLocked: get xattr fail: ino(%v) err(%v)", inode, err)
+		return err
+	}
+	if err = proto.CheckObjectLockXAttr(xattr, bypassGovernance); err != nil {
+		log.LogWarnf("isObjectLocked: object is locked: ino(%v) retainUntilDate(%v) mode(%v) legalHold(%v) err(%v)",
+			inode, string(xattr.Get(proto.ObjectLockXAttrKey)), string(xattr.Get(proto.ObjectLockModeXAttrKey)),
+			string(xattr.Get(proto.ObjectLegalHoldXAttrKey)), err)
+		return err
+	}
+	return nil
+}
+
+func (mw *MetaWrapper) isObjectLockedByName(parentID uint64, name string) error {
+	inode, mode, err := mw.Lookup_ll(parentID, name)
+	if err == syscall.ENOENT {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if proto.IsDir(mode) {
+		return nil
+	}
+	return mw.isObjectLocked(inode, false)
+}
+
+// restoreLockedDentry restores the deleted dentry of the inode, which the meta node refuses
+// to unlink as it is locked after the check of the client.
+func (mw *MetaWrapper) restoreLockedDentry(parentMP, mp *MetaPartition, parentID uint64, name string, inode uint64, fullPath string) {
+	status, info, err := mw.iget(mp, inode, 0)
+	if err == nil && status == statusOK {
+		status, err = mw.dcreate(parentMP, parentID, name, inode, info.Mode, fullPath, false)
+	}
+	if err != nil || status != statusOK {
+		log.LogErrorf("restoreLockedDentry: parentID(%v) name(%v) ino(%v) status(%v) err(%v)",
+			parentID, name, inode, status, err)
+	}
+}
+
+// ObjectLockEnabled reports whether the object lock is configured on the volume.
+func (mw *MetaWrapper) ObjectLockEnabled() bool {
+	return atomic.LoadInt32(&mw.objectLock) == 1
+}
+
+// updateObjectLockEnabled checks whether the object lock is configured on the volume,
+// the files of the volume are checked against object lock only if it is.
+func (mw *MetaWrapper) updateObjectLockEnabled() error {
+	xattr, err := mw.XAttrGet_ll(proto.RootIno, proto.ObjectLockXAttrKey)
+	if err != nil {
+		return err
+	}
+	var enabled int32
+	if len(xattr.Get(proto.ObjectLockXAttrKey)) > 0 {
+		enabled = 1
+	}
+	atomic.StoreInt32(&mw.objectLock, enabled)
+	return nil
+}
+
+// CheckObjectLock returns syscall.EPERM if the content of the inode cannot be modified,
+// as it is protected by object lock.
+func (mw *MetaWrapper) CheckObjectLock(inode uint64) error {
+	if !mw.ObjectLockEnabled() {
+		return nil
+	}
+	return mw.isObjectLocked(inode, false)
+}
@@ -331,6 +331,10 @@ func (mw *MetaWrapper) txIunlink(tx *Transaction, mp *MetaPartition, inode uint6
 }
 
 func (mw *MetaWrapper) iunlink(mp *MetaPartition, inode uint64, verSeq uint64, denVerSeq uint64, fullPath string) (status int, info *proto.InodeInfo, err error) {
+	return mw.iunlinkWork(mp, inode, verSeq, denVerSeq, fullPath, false)
+}
+
+func (mw *MetaWrapper) iunlinkWork(mp *MetaPartition, inode uint64, verSeq uint64, denVerSeq uint64, fullPath string, bypassGovernance bool) (status int, info *proto.InodeInfo, err error) {
 	bgTime := stat.BeginStat()
 	defer func() {
 		stat.EndStat("iunlink", err, bgTime, 1)
@@ -344,12 +348,13 @@ func (mw *MetaWrapper) iunlink(mp *MetaPartition, inode uint64, verSeq uint64, d
 	}
 
 	req := &proto.UnlinkInodeRequest{
-		VolName:     mw.volname,
-		PartitionID: mp.PartitionID,
-		Inode:       inode,
-		UniqID:      uniqID,
-		VerSeq:      verSeq,
-		DenVerSeq:   denVerSeq,
+		VolName:          mw.volname,
+		PartitionID:      mp.PartitionID,
+		Inode:            inode,
+		UniqID:           uniqID,
+		VerSeq:           verSeq,
+		DenVerSeq:        denVerSeq,
+		BypassGovernance: bypassGovernance,
 	}
 	req.FullPaths = []string{fullPath}
 
@@ -675,7 +680,9 @@ func (mw *MetaWrapper) txDupdate(tx *Transaction, mp *MetaPartition, parentID ui
 	return statusOK, resp.Inode, nil
 }
 
-func (mw *MetaWrapper) dupdate(mp *MetaPartition, parentID uint64, name string, newInode uint64, fullPath string) (status int, oldInode uint64, err error) {
+// dupdate updates the dentry to the new inode, the update only succeeds when the dentry
+// still refers to the condInode if it is not zero.
+func (mw *MetaWrapper) dupdate(mp *MetaPartition, parentID uint64, name string, newInode, condInode uint64, fullPath string) (status int, oldInode uint64, err error) {
 	bgTime := stat.BeginStat()
 	defer func() {
 		stat.EndStat("dupdate", err, bgTime, 1)
@@ -691,6 +698,7 @@ func (mw *MetaWrapper) dupdate(mp *MetaPartition, parentID uint64, name string,
 		ParentID:    parentID,
 		Name:        name,
 		Inode:       newInode,
+		OldIno:      condInode,
 	}
 	req.FullPaths = []string{fullPath}
 
@@ -1952,6 +1960,196 @@ func (mw *MetaWrapper) appendExtentKeys(mp *MetaPartition, inode uint64, extents
 	return
 }
 
+func (mw *MetaWrapper) replaceExtentKeys(mp *MetaPartition, inode, srcInode uint64, oldExtents []proto.ExtentKey, gen uint64, modifyTime int64) (status int, err error) {
+	bgTime := stat.BeginStat()
+	defer func() {
+		stat.EndStat("replaceExtentKeys", err, bgTime, 1)
+	}()
+
+	req := &proto.ReplaceExtentKeysRequest{
+		VolName:     mw.volname,
+		PartitionId: mp.PartitionID,
+		Inode:       inode,
+		SrcInode:    srcInode,
+		OldExtents:  oldExtents,
+		Generation:  gen,
+		ModifyTime:  modifyTime,
+	}
+
+	packet := proto.NewPacketReqID()
+	packet.Opcode = proto.OpMetaExtentsReplace
+	packet.PartitionID = mp.PartitionID
+	err = packet.MarshalData(req)
+	if err != nil {
+		log.LogErrorf("replaceExtentKeys: req(%v) err(%v)", *req, err)
+		return
+	}
+
+	metric := exporter.NewTPCnt(packet.GetOpMsg())
+	defer func() {
+		metric.SetWithLabels(err, map[string]string{exporter.Vol: mw.volname})
+	}()
+
+	packet, err = mw.sendToMetaPartition(mp, packet)
+	if err != nil {
+		log.LogErrorf("replaceExtentKeys: packet(%v) mp(%v) ino(%v) srcIno(%v) err(%v)", packet, mp, inode, srcInode, err)
+		return
+	}
+
+	status = parseStatus(packet.ResultCode)
+	if status != statusOK {
+		err = errors.New(packet.GetResultMsg())
+		if status != StatusConflictExtents {
+			log.LogErrorf("replaceExtentKeys: packet(%v) mp(%v) ino(%v) srcIno(%v) result(%v)", packet, mp, inode, srcInode, packet.GetResultMsg())
+		}
+		return
+	}
+	log.LogDebugf("replaceExtentKeys: packet(%v) mp(%v) ino(%v) srcIno(%v) oldExtents(%v)", packet, mp, inode, srcInode, len(oldExtents))
+	return
+}
+
+func (mw *MetaWrapper) remapExtentKeys(mp *MetaPartition, inode uint64, remaps []proto.ExtentRemap, gen uint64, modifyTime int64) (status int, err error) {
+	bgTime := stat.BeginStat()
+	defer func() {
+		stat.EndStat("remapExtentKeys", err, bgTime, 1)
+	}()
+
+	req := &proto.RemapExtentKeysRequest{
+		VolName:     mw.volname,
+		PartitionId: mp.PartitionID,
+		Inode:       inode,
+		Remaps:      remaps,
+		Generation:  gen,
+		ModifyTime:  modifyTime,
+	}
+
+	packet := proto.NewPacketReqID()
+	packet.Opcode = proto.OpMetaExtentsRemap
+	packet.PartitionID = mp.PartitionID
+	err = packet.MarshalData(req)
+	if err != nil {
+		log.LogErrorf("remapExtentKeys: req(%v) err(%v)", *req, err)
+		return
+	}
+
+	metric := exporter.NewTPCnt(packet.GetOpMsg())
+	defer func() {
+		metric.SetWithLabels(err, map[string]string{exporter.Vol: mw.volname})
+	}()
+
+	packet, err = mw.sendToMetaPartition(mp, packet)
+	if err != nil {
+		log.LogErrorf("remapExtentKeys: packet(%v) mp(%v) ino(%v) err(%v)", packet, mp, inode, err)
+		return
+	}
+
+	status = parseStatus(packet.ResultCode)
+	if status != statusOK {
+		err = errors.New(packet.GetResultMsg())
+		if status != StatusConflictExtents {
+			log.LogErrorf("remapExtentKeys: packet(%v) mp(%v) ino(%v) result(%v)", packet, mp, inode, packet.GetResultMsg())
+		}
+		return
+	}
+	log.LogDebugf("remapExtentKeys: packet(%v) mp(%v) ino(%v) remaps(%v)", packet, mp, inode, len(remaps))
+	return
+}
+
+func (mw *MetaWrapper) inodeModify(mp *MetaPartition, inode, offset, size uint64) (status int, err error) {
+	bgTime := stat.BeginStat()
+	defer func() {
+		stat.EndStat("inodeModify", err, bgTime, 1)
+	}()
+
+	req := &proto.InodeModifyRequest{
+		VolName:     mw.volname,
+		PartitionId: mp.PartitionID,
+		Inode:       inode,
+		Offset:      offset,
+		Size:        size,
+	}
+
+	packet := proto.NewPacketReqID()
+	packet.Opcode = proto.OpMetaInodeModify
+	packet.PartitionID = mp.PartitionID
+	err = packet.MarshalData(req)
+	if err != nil {
+		log.LogErrorf("inodeModify: req(%v) err(%v)", *req, err)
+		return
+	}
+
+	metric := exporter.NewTPCnt(packet.GetOpMsg())
+	defer func() {
+		metric.SetWithLabels(err, map[string]string{exporter.Vol: mw.volname})
+	}()
+
+	packet, err = mw.sendToMetaPartition(mp, packet)
+	if err != nil {
+		log.LogErrorf("inodeModify: packet(%v) mp(%v) ino(%v) err(%v)", packet, mp, inode, err)
+		return
+	}
+
+	status = parseStatus(packet.ResultCode)
+	if status != statusOK {
+		err = errors.New(packet.GetResultMsg())
+		log.LogErrorf("inodeModify: packet(%v) mp(%v) ino(%v) result(%v)", packet, mp, inode, packet.GetResultMsg())
+		return
+	}
+	log.LogDebugf("inodeModify: packet(%v) mp(%v) ino(%v) offset(%v) size(%v)", packet, mp, inode, offset, size)
+	return
+}
+
+func (mw *MetaWrapper) getFragmentedInodes(mp *MetaPartition, marker uint64, minExtents, minTinyExtents, limit uint32, tinyExtents map[uint64][]uint64) (resp *proto.GetFragmentedInodesResponse, err error) {
+	bgTime := stat.BeginStat()
+	defer func() {
+		stat.EndStat("getFragmentedInodes", err, bgTime, 1)
+	}()
+
+	req := &proto.GetFragmentedInodesRequest{
+		VolName:        mw.volname,
+		PartitionId:    mp.PartitionID,
+		Marker:         marker,
+		MinExtents:     minExtents,
+		MinTinyExtents: minTinyExtents,
+		Limit:          limit,
+		TinyExtents:    tinyExtents,
+	}
+
+	packet := proto.NewPacketReqID()
+	packet.Opcode = proto.OpMetaGetFragmentedInodes
+	packet.PartitionID = mp.PartitionID
+	err = packet.MarshalData(req)
+	if err != nil {
+		log.LogErrorf("getFragmentedInodes: req(%v) err(%v)", *req, err)
+		return
+	}
+
+	metric := exporter.NewTPCnt(packet.GetOpMsg())
+	defer func() {
+		metric.SetWithLabels(err, map[string]string{exporter.Vol: mw.volname})
+	}()
+
+	packet, err = mw.sendToMetaPartition(mp, packet)
+	if err != nil {
+		log.LogErrorf("getFragmentedInodes: packet(%v) mp(%v) req(%v) err(%v)", packet, mp, *req, err)
+		return
+	}
+
+	status := parseStatus(packet.ResultCode)
+	if status != statusOK {
+		err = errors.New(packet.GetResultMsg())
+		log.LogErrorf("getFragmentedInodes: packet(%v) mp(%v) req(%v) result(%v)", packet, mp, *req, packet.GetResultMsg())
+		return
+	}
+
+	resp = new(proto.GetFragmentedInodesResponse)
+	if err = packet.UnmarshalData(resp); err != nil {
+		log.LogErrorf("getFragmentedInodes: packet(%v) mp(%v) err(%v) PacketData(%v)", packet, mp, err, string(packet.Data))
+		return
+	}
+	return
+}
+
 func (mw *MetaWrapper) appendObjExtentKeys(mp *MetaPartition, inode uint64, extents []proto.ObjExtentKey) (status int, err error) {
 	bgTime := stat.BeginStat()
 	defer func() {
@@ -2047,18 +2245,19 @@ func (mw *MetaWrapper) batchSetXAttr(mp *MetaPartition, inode uint64, attrs map[
 	return
 }
 
-func (mw *MetaWrapper) setXAttr(mp *MetaPartition, inode uint64, name []byte, value []byte) (status int, err error) {
+func (mw *MetaWrapper) setXAttr(mp *MetaPartition, inode uint64, name []byte, value []byte, bypassGovernance bool) (status int, err error) {
 	bgTime := stat.BeginStat()
 	defer func() {
 		stat.EndStat("setXAttr", err, bgTime, 1)
 	}()
 
 	req := &proto.SetXAttrRequest{
-		VolName:     mw.volname,
-		PartitionId: mp.PartitionID,
-		Inode:       inode,
-		Key:         string(name),
-		Value:       string(value),
+		VolName:          mw.volname,
+		PartitionId:      mp.PartitionID,
+		Inode:            inode,
+		Key:              string(name),
+		Value:            string(value),
+		BypassGovernance: bypassGovernance,
 	}
 
 	packet := proto.NewPacketReqID()
@@ -2198,17 +2397,18 @@ func (mw *MetaWrapper) getXAttr(mp *MetaPartition, inode uint64, name string) (v
 	return
 }
 
-func (mw *MetaWrapper) removeXAttr(mp *MetaPartition, inode uint64, name string) (status int, err error) {
+func (mw *MetaWrapper) removeXAttr(mp *MetaPartition, inode uint64, name string, bypassGovernance bool) (status int, err error) {
 	bgTime := stat.BeginStat()
 	defer func() {
 		stat.EndStat("removeXAttr", err, bgTime, 1)
 	}()
 
 	req := &proto.RemoveXAttrRequest{
-		VolName:     mw.volname,
-		PartitionId: mp.PartitionID,
-		Inode:       inode,
-		Key:         name,
+		VolName:          mw.volname,
+		PartitionId:      mp.PartitionID,
+		Inode:            inode,
+		Key:              name,
+		BypassGovernance: bypassGovernance,
 	}
 
 	packet := proto.NewPacketReqID()
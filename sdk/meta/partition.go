@@ -95,6 +95,17 @@ func (mw *MetaWrapper) getPartitionByInode(ino uint64) *MetaPartition {
 	return mp
 }
 
+// GetPartitionIDs returns the IDs of all the meta partitions of the volume.
+func (mw *MetaWrapper) GetPartitionIDs() []uint64 {
+	mw.RLock()
+	defer mw.RUnlock()
+	ids := make([]uint64, 0, len(mw.partitions))
+	for id := range mw.partitions {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
 //func (mw *MetaWrapper) getRWPartitions() []*MetaPartition {
 //	rwPartitions := make([]*MetaPartition, 0)
 //	mw.RLock()
@@ -274,6 +274,9 @@ func (mw *MetaWrapper) refresh() {
 				mw.onAsyncTaskError.OnError(err)
 				log.LogErrorf("updateDirChildrenNumLimit fail cause: %v", err)
 			}
+			if err = mw.updateObjectLockEnabled(); err != nil {
+				log.LogErrorf("updateObjectLockEnabled fail cause: %v", err)
+			}
 			t.Reset(RefreshMetaPartitionsInterval)
 		case <-mw.forceUpdate:
 			log.LogInfof("Start forceUpdateMetaPartitions")
@@ -41,6 +41,8 @@ var (
 	SnapshotNeedNewExtentError       = errors.New("snapshot need new extent error")
 	NoDiskReadRepairExtentTokenError = errors.New("no disk read repair extent token")
 	ReachMaxExtentsCountError        = errors.New("reached max extents count")
+	ExtentDefragFencedError          = errors.New("extent is fenced by defragmentation")
+	ExtentDefragFenceBrokenError     = errors.New("extent defragmentation fence is broken")
 )
 
 func newParameterError(format string, a ...interface{}) error {
@@ -73,6 +73,8 @@ type ExtentInfo struct {
 	SnapPreAllocDataOff uint64 `json:"snapPreAllocSize"`
 	ApplyID             uint64 `json:"applyID"`
 	ApplySize           int64  `json:"ApplySize"`
+
+	Ref *ExtentRef `json:"ref,omitempty"` // extra references of the shared extent, only set in the watermarks
 }
 
 func (ei *ExtentInfo) TotalSize() uint64 {
@@ -0,0 +1,468 @@
+// Copyright 2023 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	// ExtRefFileName is the file that persists the extra references of the normal extents
+	// shared by deduplicated files. An extent without extra references is not recorded.
+	ExtRefFileName = "EXTENT_REF"
+	// ExtRefLogFileName is the file that the changed references are appended to, it is
+	// merged into ExtRefFileName once it grows larger than the references.
+	ExtRefLogFileName = "EXTENT_REF_LOG"
+
+	extRefLogCompactMinCnt = 1024
+)
+
+var ExtentSharedError = fmt.Errorf("extent is shared by deduplicated files")
+
+// ExtentRef is the extra references of a shared extent. Tokens is the live references of
+// each addition token, and Released maps each release token to the addition token whose
+// reference it released, so a replayed addition or release is ignored. A token is dropped
+// from Tokens once all its references are released, and the count is always the sum of
+// the live references. The references are exchanged with the watermarks and merged by the
+// repair of the data partition, a lost update only leaks the extent and never frees the
+// data in use. The record is removed together with the extent.
+type ExtentRef struct {
+	Refs     uint32            `json:"refs"`
+	Tokens   map[string]uint32 `json:"tokens,omitempty"`
+	Released map[string]string `json:"released,omitempty"`
+}
+
+// extentRefRecord is a line of ExtRefLogFileName, a nil ref removes the extent.
+type extentRefRecord struct {
+	ExtentID uint64     `json:"id"`
+	Ref      *ExtentRef `json:"ref,omitempty"`
+}
+
+func (r *ExtentRef) hasToken(token string) bool {
+	if _, ok := r.Tokens[token]; ok {
+		return true
+	}
+	for _, t := range r.Released {
+		if t == token {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ExtentRef) hasReleased(token string) bool {
+	_, ok := r.Released[token]
+	return ok
+}
+
+// added returns the references ever added by each token, both the live and the released ones.
+func (r *ExtentRef) added() map[string]uint32 {
+	added := make(map[string]uint32, len(r.Tokens))
+	for token, cnt := range r.Tokens {
+		added[token] += cnt
+	}
+	for _, token := range r.Released {
+		added[token]++
+	}
+	return added
+}
+
+func (r *ExtentRef) add(token string) {
+	if r.Tokens == nil {
+		r.Tokens = make(map[string]uint32)
+	}
+	r.Tokens[token]++
+	r.Refs++
+}
+
+// release drops a live reference of the smallest token, so the replicas with the same
+// references release the same one, and records the release token if any.
+func (r *ExtentRef) release(releaseToken string) {
+	var token string
+	for t := range r.Tokens {
+		if token == "" || t < token {
+			token = t
+		}
+	}
+	if token == "" {
+		return
+	}
+	if r.Tokens[token]--; r.Tokens[token] == 0 {
+		delete(r.Tokens, token)
+	}
+	r.Refs--
+	if releaseToken != "" {
+		if r.Released == nil {
+			r.Released = make(map[string]string)
+		}
+		r.Released[releaseToken] = token
+	}
+}
+
+func (r *ExtentRef) copy() *ExtentRef {
+	if r == nil {
+		return nil
+	}
+	c := &ExtentRef{Refs: r.Refs}
+	if len(r.Tokens) > 0 {
+		c.Tokens = make(map[string]uint32, len(r.Tokens))
+		for token, cnt := range r.Tokens {
+			c.Tokens[token] = cnt
+		}
+	}
+	if len(r.Released) > 0 {
+		c.Released = make(map[string]string, len(r.Released))
+		for releaseToken, token := range r.Released {
+			c.Released[releaseToken] = token
+		}
+	}
+	return c
+}
+
+// Equal reports whether the references are the same.
+func (r *ExtentRef) Equal(other *ExtentRef) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Refs != other.Refs || len(r.Tokens) != len(other.Tokens) || len(r.Released) != len(other.Released) {
+		return false
+	}
+	for token, cnt := range r.Tokens {
+		if c, ok := other.Tokens[token]; !ok || c != cnt {
+			return false
+		}
+	}
+	for releaseToken, token := range r.Released {
+		if t, ok := other.Released[releaseToken]; !ok || t != token {
+			return false
+		}
+	}
+	return true
+}
+
+// MergeExtentRefs merges the references of an extent on the replicas. The additions of each
+// token are the most ones added on any replica, and the releases are united, the release
+// recorded by the former replica wins if it released another token. The live references are
+// recomputed from them, so a release missed by a replica is applied by the merge. Returns nil
+// if there are no references.
+func MergeExtentRefs(refs ...*ExtentRef) (merged *ExtentRef) {
+	added := make(map[string]uint32)
+	released := make(map[string]string)
+	for _, ref := range refs {
+		if ref == nil {
+			continue
+		}
+		for token, cnt := range ref.added() {
+			if cnt > added[token] {
+				added[token] = cnt
+			}
+		}
+		for releaseToken, token := range ref.Released {
+			if _, ok := released[releaseToken]; !ok {
+				released[releaseToken] = token
+			}
+		}
+	}
+	if len(added) == 0 {
+		return nil
+	}
+	merged = &ExtentRef{}
+	for _, token := range released {
+		// the references released more than added on the replicas are kept, which only leaks
+		if added[token] > 0 {
+			added[token]--
+		}
+	}
+	for token, cnt := range added {
+		if cnt == 0 {
+			continue
+		}
+		if merged.Tokens == nil {
+			merged.Tokens = make(map[string]uint32)
+		}
+		merged.Tokens[token] = cnt
+		merged.Refs += cnt
+	}
+	if len(released) > 0 {
+		merged.Released = released
+	}
+	return
+}
+
+func (s *ExtentStore) loadExtentRefs() (err error) {
+	s.extentRefMap = make(map[uint64]*ExtentRef)
+	data, err := os.ReadFile(path.Join(s.dataPath, ExtRefFileName))
+	if err != nil && !os.IsNotExist(err) {
+		return
+	}
+	if len(data) > 0 {
+		if err = json.Unmarshal(data, &s.extentRefMap); err != nil {
+			return
+		}
+	}
+
+	s.extentRefLogFp, err = os.OpenFile(path.Join(s.dataPath, ExtRefLogFileName), os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o666)
+	if err != nil {
+		return
+	}
+	var offset int64
+	reader := bufio.NewReader(s.extentRefLogFp)
+	for {
+		line, errRead := reader.ReadBytes('\n')
+		if errRead == io.EOF {
+			break
+		}
+		if errRead != nil {
+			return errRead
+		}
+		record := new(extentRefRecord)
+		if err = json.Unmarshal(line, record); err != nil {
+			break
+		}
+		s.restoreExtentRefLocked(record.ExtentID, record.Ref)
+		s.extentRefLogCnt++
+		offset += int64(len(line))
+	}
+	// drop the torn record appended when crashed
+	if err = s.extentRefLogFp.Truncate(offset); err != nil {
+		return
+	}
+	log.LogInfof("[loadExtentRefs] path %s, refs(%v) log records(%v)", s.dataPath, len(s.extentRefMap), s.extentRefLogCnt)
+	return
+}
+
+func (s *ExtentStore) closeExtentRefs() {
+	s.erMutex.Lock()
+	defer s.erMutex.Unlock()
+	if s.extentRefLogFp != nil {
+		s.extentRefLogFp.Close()
+	}
+}
+
+// persistExtentRefsLocked appends the current references of the changed extents to the log,
+// the log is merged into a new snapshot of all the references once it is large enough.
+func (s *ExtentStore) persistExtentRefsLocked(extentIDs ...uint64) (err error) {
+	var data []byte
+	for _, extentID := range extentIDs {
+		line, errMarshal := json.Marshal(&extentRefRecord{ExtentID: extentID, Ref: s.extentRefMap[extentID]})
+		if errMarshal != nil {
+			return errMarshal
+		}
+		data = append(append(data, line...), '\n')
+	}
+	if _, err = s.extentRefLogFp.Write(data); err != nil {
+		return
+	}
+	if err = s.extentRefLogFp.Sync(); err != nil {
+		return
+	}
+	s.extentRefLogCnt += len(extentIDs)
+	if s.extentRefLogCnt < extRefLogCompactMinCnt || s.extentRefLogCnt < 2*len(s.extentRefMap) {
+		return
+	}
+	if err = s.compactExtentRefsLocked(); err != nil {
+		// the references are still in the log
+		log.LogWarnf("[persistExtentRefsLocked] path %s, compact failed, err %v", s.dataPath, err)
+		err = nil
+	}
+	return
+}
+
+func (s *ExtentStore) compactExtentRefsLocked() (err error) {
+	data, err := json.Marshal(s.extentRefMap)
+	if err != nil {
+		return
+	}
+	tmpPath := path.Join(s.dataPath, ExtRefFileName+".tmp")
+	fp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0o666)
+	if err != nil {
+		return
+	}
+	if _, err = fp.Write(data); err == nil {
+		err = fp.Sync()
+	}
+	fp.Close()
+	if err != nil {
+		return
+	}
+	if err = os.Rename(tmpPath, path.Join(s.dataPath, ExtRefFileName)); err != nil {
+		return
+	}
+	if err = s.extentRefLogFp.Truncate(0); err != nil {
+		return
+	}
+	s.extentRefLogCnt = 0
+	log.LogInfof("[compactExtentRefsLocked] path %s, refs(%v)", s.dataPath, len(s.extentRefMap))
+	return
+}
+
+func (s *ExtentStore) copyExtentRefLocked(extentID uint64) *ExtentRef {
+	return s.extentRefMap[extentID].copy()
+}
+
+func (s *ExtentStore) restoreExtentRefLocked(extentID uint64, ref *ExtentRef) {
+	if ref == nil {
+		delete(s.extentRefMap, extentID)
+		return
+	}
+	s.extentRefMap[extentID] = ref
+}
+
+// addExtentRefs adds one reference to the extent of each extent key. Only sealed normal
+// extents which are referenced as a whole can be shared. The references of the same token
+// are added once, so the addition can be retried on all the replicas.
+func (s *ExtentStore) addExtentRefs(eks []*proto.ExtentKey, token string) (err error) {
+	if token == "" {
+		return fmt.Errorf("extent refs are added without token")
+	}
+	for _, ek := range eks {
+		if IsTinyExtent(ek.ExtentId) || ek.ExtentOffset != 0 {
+			return fmt.Errorf("extent(%v) offset(%v) can not be shared", ek.ExtentId, ek.ExtentOffset)
+		}
+		ei, _ := s.GetExtentInfo(ek.ExtentId)
+		if ei == nil || ei.IsDeleted {
+			return ExtentNotFoundError
+		}
+		if ei.Size != uint64(ek.Size) {
+			return fmt.Errorf("extent size not match, path %s, extentID(%v), extentSize(%v), extentKeySize(%v)",
+				s.dataPath, ek.ExtentId, ei.Size, ek.Size)
+		}
+	}
+
+	s.erMutex.Lock()
+	defer s.erMutex.Unlock()
+	origin := make(map[uint64]*ExtentRef)
+	for _, ek := range eks {
+		if _, ok := origin[ek.ExtentId]; !ok {
+			origin[ek.ExtentId] = s.copyExtentRefLocked(ek.ExtentId)
+		}
+	}
+	var added int
+	for _, ek := range eks {
+		if ref := origin[ek.ExtentId]; ref != nil && ref.hasToken(token) {
+			continue
+		}
+		ref := s.extentRefMap[ek.ExtentId]
+		if ref == nil {
+			ref = &ExtentRef{}
+			s.extentRefMap[ek.ExtentId] = ref
+		}
+		ref.add(token)
+		added++
+	}
+	if added == 0 {
+		log.LogInfof("[addExtentRefs] path %s, refs of token(%v) already added", s.dataPath, token)
+		return
+	}
+	extentIDs := make([]uint64, 0, len(origin))
+	for extentID := range origin {
+		extentIDs = append(extentIDs, extentID)
+	}
+	if err = s.persistExtentRefsLocked(extentIDs...); err != nil {
+		for extentID, ref := range origin {
+			s.restoreExtentRefLocked(extentID, ref)
+		}
+		return BrokenDiskError
+	}
+	log.LogInfof("[addExtentRefs] path %s, token(%v) add %v refs", s.dataPath, token, added)
+	return
+}
+
+// releaseExtentRef drops one reference of a shared extent on deletion, the extent itself is
+// kept. Punching a shared extent is ignored since the range is still used by other files.
+// The release of the same token is applied once, so a retried deletion never drops the
+// references of the other files, the released addition token is dropped once it has no live
+// references. The deletion of the last holder removes the record, the tokens are not needed
+// any more since the extent is deleted.
+func (s *ExtentStore) releaseExtentRef(extentID uint64, isPunch bool, token string) (shared bool, err error) {
+	s.erMutex.Lock()
+	defer s.erMutex.Unlock()
+	ref := s.extentRefMap[extentID]
+	if ref == nil {
+		return
+	}
+	if token != "" && ref.hasReleased(token) {
+		log.LogInfof("[releaseExtentRef] path %s, extent(%v) token(%v) already released", s.dataPath, extentID, token)
+		shared = true
+		return
+	}
+	if isPunch {
+		if shared = ref.Refs > 0; shared {
+			log.LogWarnf("[releaseExtentRef] path %s, skip punching shared extent(%v)", s.dataPath, extentID)
+		}
+		return
+	}
+	origin := s.copyExtentRefLocked(extentID)
+	if ref.Refs == 0 {
+		delete(s.extentRefMap, extentID)
+	} else {
+		shared = true
+		ref.release(token)
+	}
+	if err = s.persistExtentRefsLocked(extentID); err != nil {
+		s.restoreExtentRefLocked(extentID, origin)
+		shared = true
+		err = BrokenDiskError
+		return
+	}
+	log.LogInfof("[releaseExtentRef] path %s, extent(%v) token(%v) refs(%v) shared(%v)",
+		s.dataPath, extentID, token, ref.Refs, shared)
+	return
+}
+
+// MergeExtentRef merges the references of the extent from another replica on repair, the
+// releases of the other replica missed by this one are applied, see MergeExtentRefs.
+func (s *ExtentStore) MergeExtentRef(extentID uint64, other *ExtentRef) (err error) {
+	if other == nil {
+		return
+	}
+	s.erMutex.Lock()
+	defer s.erMutex.Unlock()
+	origin := s.copyExtentRefLocked(extentID)
+	merged := MergeExtentRefs(origin, other)
+	if merged == nil || merged.Equal(origin) {
+		return
+	}
+	s.extentRefMap[extentID] = merged
+	if err = s.persistExtentRefsLocked(extentID); err != nil {
+		s.restoreExtentRefLocked(extentID, origin)
+		return BrokenDiskError
+	}
+	log.LogInfof("[MergeExtentRef] path %s, extent(%v) refs(%v) tokens(%v) released(%v)",
+		s.dataPath, extentID, merged.Refs, len(merged.Tokens), len(merged.Released))
+	return
+}
+
+// GetExtentRef returns the extra references of the extent.
+func (s *ExtentStore) GetExtentRef(extentID uint64) uint32 {
+	s.erMutex.RLock()
+	defer s.erMutex.RUnlock()
+	if ref := s.extentRefMap[extentID]; ref != nil {
+		return ref.Refs
+	}
+	return 0
+}
+
+func (s *ExtentStore) isSharedExtent(extentID uint64) bool {
+	return s.GetExtentRef(extentID) > 0
+}
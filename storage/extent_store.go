@@ -147,6 +147,10 @@ type ExtentStore struct {
 	extentLockMap                     map[uint64]proto.GcFlag
 	elMutex                           sync.RWMutex
 	extentLock                        bool
+	extentRefMap                      map[uint64]*ExtentRef // extra references of the extents shared by dedup
+	erMutex                           sync.RWMutex
+	extentRefLogFp                    *os.File
+	extentRefLogCnt                   int
 	stopMutex                         sync.RWMutex
 	stopC                             chan interface{}
 	ApplyId                           uint64
@@ -234,6 +238,10 @@ func NewExtentStore(dataDir string, partitionID uint64, storeSize, dpType int, i
 
 	s.extentInfoMap = make(map[uint64]*ExtentInfo)
 	s.extentLockMap = make(map[uint64]proto.GcFlag)
+	if err = s.loadExtentRefs(); err != nil {
+		err = fmt.Errorf("load extent refs: %v", err)
+		return
+	}
 	s.cache = NewExtentCache(100)
 	if err = s.initBaseFileID(); err != nil {
 		err = fmt.Errorf("init base field ID: %v", err)
@@ -664,6 +672,13 @@ func (s *ExtentStore) Write(param *WriteParam) (status uint8, err error) {
 	}
 	s.elMutex.RUnlock()
 
+	// overwriting a shared extent would change the data of other deduplicated files
+	if param.WriteType != AppendWriteType && !param.IsRepair && s.isSharedExtent(param.ExtentID) {
+		err = ExtentSharedError
+		log.LogErrorf("[Write] store(%v) extent(%v) is shared, param(%v)", s.dataPath, param.ExtentID, param)
+		return
+	}
+
 	s.eiMutex.Lock()
 	status = proto.OpOk
 	ei = s.extentInfoMap[param.ExtentID]
@@ -820,6 +835,12 @@ func (s *ExtentStore) GetGcFlag(extId uint64) proto.GcFlag {
 
 // MarkDelete marks the given extent as deleted.
 func (s *ExtentStore) MarkDelete(extentID uint64, offset, size int64) (err error) {
+	return s.MarkDeleteWithToken(extentID, offset, size, "")
+}
+
+// MarkDeleteWithToken marks the given extent as deleted, the token identifies the holder
+// of the extent, so a retried deletion of a shared extent releases its reference once.
+func (s *ExtentStore) MarkDeleteWithToken(extentID uint64, offset, size int64, token string) (err error) {
 	s.stopMutex.RLock()
 	defer s.stopMutex.RUnlock()
 	if s.IsClosed() {
@@ -861,6 +882,9 @@ func (s *ExtentStore) MarkDelete(extentID uint64, offset, size int64) (err error
 		return false
 	}
 	log.LogInfof("[MarkDelete] store(%v) mark del extent(%v) offset(%v) size(%v), ei size(%v) ei snapshotOff(%v), tiny(%v), funcNeedPunchDel(%v)", s.dataPath, extentID, offset, size, ei.Size, ei.SnapshotDataOff, IsTinyExtent(extentID), funcNeedPunchDel())
+	if shared, err := s.releaseExtentRef(extentID, funcNeedPunchDel(), token); shared {
+		return err
+	}
 	if IsTinyExtent(extentID) || funcNeedPunchDel() {
 		log.LogDebugf("action[MarkDelete] extentID %v offset %v size %v ei(size %v snapshotSize %v), tiny(%v), snapshot punch(%v)",
 			extentID, offset, size, ei.Size, ei.SnapshotDataOff, IsTinyExtent(extentID), funcNeedPunchDel())
@@ -946,6 +970,7 @@ func (s *ExtentStore) Close() {
 			vFp.Close()
 		}
 	}
+	s.closeExtentRefs()
 
 	s.stopMutex.Lock()
 	defer s.stopMutex.Unlock()
@@ -1088,6 +1113,15 @@ func (s *ExtentStore) GetAllWatermarks(filter ExtentFilter) (extents []*ExtentIn
 		if extentInfo.IsDeleted {
 			continue
 		}
+		// the references of the shared extents are repaired with the watermarks
+		s.erMutex.RLock()
+		ref := s.copyExtentRefLocked(extentInfo.FileID)
+		s.erMutex.RUnlock()
+		if ref != nil {
+			withRef := *extentInfo
+			withRef.Ref = ref
+			extentInfo = &withRef
+		}
 		extents = append(extents, extentInfo)
 	}
 	tinyDeleteFileSize, err = s.LoadTinyDeleteFileOffset()
@@ -1329,6 +1363,29 @@ func (s *ExtentStore) GetHasDeleteTinyRecords() (extentDes []ExtentDeleted, err
 	}
 }
 
+type TinyExtentHoleInfo struct {
+	ExtentID  uint64 `json:"extentID"`
+	Size      int64  `json:"size"`
+	Allocated int64  `json:"allocated"`
+}
+
+// GetTinyExtentHoleInfo returns the logical size and the really allocated size of each tiny extent,
+// the difference between them is the space already freed by punching holes.
+func (s *ExtentStore) GetTinyExtentHoleInfo() (infos []*TinyExtentHoleInfo, err error) {
+	var e *Extent
+	for extentID := uint64(TinyExtentStartID); extentID < TinyExtentStartID+TinyExtentCount; extentID++ {
+		if e, err = s.extentWithHeaderByExtentID(extentID); err != nil {
+			return
+		}
+		infos = append(infos, &TinyExtentHoleInfo{
+			ExtentID:  extentID,
+			Size:      e.Size(),
+			Allocated: e.getRealBlockCnt() * 512,
+		})
+	}
+	return
+}
+
 // NextExtentID returns the next extentID. When the client sends the request to create an extent,
 // this function generates an unique extentID within the current partition.
 // This function can only be called by the leader.
@@ -1712,6 +1769,10 @@ func (s *ExtentStore) GetAllExtents(beforeTime int64) (extents []*ExtentInfo, er
 }
 
 func (s *ExtentStore) ExtentBatchLockNormalExtent(gcLockEks *proto.GcLockExtents) (err error) {
+	if gcLockEks.Flag == proto.GcDedupRefFlag {
+		return s.addExtentRefs(gcLockEks.Eks, gcLockEks.DedupToken)
+	}
+
 	s.elMutex.Lock()
 	s.extentLock = true
 	s.elMutex.Unlock()
@@ -127,6 +127,16 @@ func extentMarkDeleteTinyTest(t *testing.T, s *storage.ExtentStore, id uint64) {
 	require.NoError(t, err)
 	// mark delete second file
 	extentStoreMarkDeleteTiny(t, s, id, size, newSize-size)
+	// the space of deleted files is freed
+	infos, err := s.GetTinyExtentHoleInfo()
+	require.NoError(t, err)
+	require.Equal(t, storage.TinyExtentCount, len(infos))
+	for _, info := range infos {
+		if info.ExtentID == id {
+			require.EqualValues(t, newSize, info.Size)
+			require.EqualValues(t, 0, info.Allocated)
+		}
+	}
 }
 
 func extentStoreMarkDeleteTest(t *testing.T, s *storage.ExtentStore, id uint64) {
@@ -294,3 +304,186 @@ func TestExtentStores(t *testing.T) {
 		ExtentStoreTest(t, ty)
 	}
 }
+
+func TestExtentStoreDedupRefs(t *testing.T) {
+	path, clean, err := getTestPathExtentStore()
+	require.NoError(t, err)
+	defer clean()
+	s, err := storage.NewExtentStore(path, 0, 1*util.GB, proto.PartitionTypeNormal, true)
+	require.NoError(t, err)
+
+	id, err := s.NextExtentID()
+	require.NoError(t, err)
+	require.NoError(t, s.Create(id))
+	data := []byte(dataStr)
+	param := &storage.WriteParam{
+		ExtentID:  id,
+		Size:      int64(len(data)),
+		Data:      data,
+		Crc:       crc32.ChecksumIEEE(data),
+		WriteType: storage.AppendWriteType,
+		IsSync:    true,
+	}
+	_, err = s.Write(param)
+	require.NoError(t, err)
+
+	ek := &proto.ExtentKey{ExtentId: id, Size: uint32(len(data))}
+	lockEks := &proto.GcLockExtents{Eks: []*proto.ExtentKey{ek}, Flag: proto.GcDedupRefFlag}
+	require.Error(t, s.ExtentBatchLockNormalExtent(lockEks))
+	lockEks.DedupToken = "dup1"
+	require.NoError(t, s.ExtentBatchLockNormalExtent(lockEks))
+	require.EqualValues(t, 1, s.GetExtentRef(id))
+
+	// the refs of the same token are added once
+	require.NoError(t, s.ExtentBatchLockNormalExtent(lockEks))
+	require.EqualValues(t, 1, s.GetExtentRef(id))
+
+	// the repair keeps the larger count
+	require.NoError(t, s.MergeExtentRef(id, &storage.ExtentRef{Refs: 2, Tokens: map[string]uint32{"dup1": 1, "dup2": 1}}))
+	require.EqualValues(t, 2, s.GetExtentRef(id))
+	lockEks.DedupToken = "dup2"
+	require.NoError(t, s.ExtentBatchLockNormalExtent(lockEks))
+	require.EqualValues(t, 2, s.GetExtentRef(id))
+	watermarks, _, err := s.GetAllWatermarks(func(ei *storage.ExtentInfo) bool { return ei.FileID == id })
+	require.NoError(t, err)
+	require.Equal(t, 1, len(watermarks))
+	require.EqualValues(t, 2, watermarks[0].Ref.Refs)
+	require.Equal(t, 2, len(watermarks[0].Ref.Tokens))
+
+	// a partial extent can not be shared
+	lockEks.Eks = []*proto.ExtentKey{{ExtentId: id, Size: 1}}
+	require.Error(t, s.ExtentBatchLockNormalExtent(lockEks))
+
+	// shared extent can not be overwritten
+	param.WriteType = storage.RandomWriteType
+	_, err = s.Write(param)
+	require.ErrorIs(t, err, storage.ExtentSharedError)
+
+	// refs survive reopening the store
+	s.Close()
+	s, err = storage.NewExtentStore(path, 0, 1*util.GB, proto.PartitionTypeNormal, false)
+	require.NoError(t, err)
+	defer s.Close()
+	require.EqualValues(t, 2, s.GetExtentRef(id))
+
+	// each deletion drops a reference until the last one removes the extent
+	for i := 0; i < 2; i++ {
+		require.NoError(t, s.MarkDelete(id, 0, int64(len(data))))
+		require.True(t, s.HasExtent(id))
+	}
+	require.EqualValues(t, 0, s.GetExtentRef(id))
+	require.NoError(t, s.MarkDelete(id, 0, int64(len(data))))
+	require.False(t, s.HasExtent(id))
+}
+
+func TestExtentStoreDedupRefsRelease(t *testing.T) {
+	path, clean, err := getTestPathExtentStore()
+	require.NoError(t, err)
+	defer clean()
+	s, err := storage.NewExtentStore(path, 0, 1*util.GB, proto.PartitionTypeNormal, true)
+	require.NoError(t, err)
+
+	id, err := s.NextExtentID()
+	require.NoError(t, err)
+	require.NoError(t, s.Create(id))
+	data := []byte(dataStr)
+	_, err = s.Write(&storage.WriteParam{
+		ExtentID:  id,
+		Size:      int64(len(data)),
+		Data:      data,
+		Crc:       crc32.ChecksumIEEE(data),
+		WriteType: storage.AppendWriteType,
+		IsSync:    true,
+	})
+	require.NoError(t, err)
+
+	// the extent is held by three files
+	ek := &proto.ExtentKey{ExtentId: id, Size: uint32(len(data))}
+	for _, token := range []string{"dup1", "dup2"} {
+		lockEks := &proto.GcLockExtents{Eks: []*proto.ExtentKey{ek}, Flag: proto.GcDedupRefFlag, DedupToken: token}
+		require.NoError(t, s.ExtentBatchLockNormalExtent(lockEks))
+	}
+	require.EqualValues(t, 2, s.GetExtentRef(id))
+
+	// a retried deletion releases the reference once
+	for i := 0; i < 3; i++ {
+		require.NoError(t, s.MarkDeleteWithToken(id, 0, 0, "ino_1"))
+		require.EqualValues(t, 1, s.GetExtentRef(id))
+	}
+	require.NoError(t, s.MarkDeleteWithToken(id, 0, 0, "ino_2"))
+	require.EqualValues(t, 0, s.GetExtentRef(id))
+
+	// the released addition tokens are dropped
+	getRef := func() *storage.ExtentRef {
+		watermarks, _, err := s.GetAllWatermarks(func(ei *storage.ExtentInfo) bool { return ei.FileID == id })
+		require.NoError(t, err)
+		require.Equal(t, 1, len(watermarks))
+		return watermarks[0].Ref
+	}
+	require.Equal(t, &storage.ExtentRef{Released: map[string]string{"ino_1": "dup1", "ino_2": "dup2"}}, getRef())
+
+	// the released tokens survive reopening the store
+	s.Close()
+	s, err = storage.NewExtentStore(path, 0, 1*util.GB, proto.PartitionTypeNormal, false)
+	require.NoError(t, err)
+	defer s.Close()
+	require.NoError(t, s.MarkDeleteWithToken(id, 0, 0, "ino_1"))
+	require.NoError(t, s.MarkDeleteWithToken(id, 0, 0, "ino_2"))
+	require.True(t, s.HasExtent(id))
+
+	// the references of the replica missing the releases do not revive the released ones
+	require.NoError(t, s.MergeExtentRef(id, &storage.ExtentRef{Refs: 2, Tokens: map[string]uint32{"dup1": 1, "dup2": 1}}))
+	require.EqualValues(t, 0, s.GetExtentRef(id))
+	require.Equal(t, &storage.ExtentRef{Released: map[string]string{"ino_1": "dup1", "ino_2": "dup2"}}, getRef())
+
+	// the last holder deletes the extent
+	require.NoError(t, s.MarkDeleteWithToken(id, 0, 0, "ino_3"))
+	require.False(t, s.HasExtent(id))
+}
+
+func TestExtentStoreDedupRefsCompact(t *testing.T) {
+	path, clean, err := getTestPathExtentStore()
+	require.NoError(t, err)
+	defer clean()
+	s, err := storage.NewExtentStore(path, 0, 1*util.GB, proto.PartitionTypeNormal, true)
+	require.NoError(t, err)
+
+	data := []byte(dataStr)
+	ids := make([]uint64, 0)
+	for i := 0; i < 8; i++ {
+		id, err := s.NextExtentID()
+		require.NoError(t, err)
+		require.NoError(t, s.Create(id))
+		_, err = s.Write(&storage.WriteParam{
+			ExtentID:  id,
+			Size:      int64(len(data)),
+			Data:      data,
+			Crc:       crc32.ChecksumIEEE(data),
+			WriteType: storage.AppendWriteType,
+			IsSync:    true,
+		})
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+	// the changes are appended to the log and merged into the snapshot
+	for i := 0; i < 1100; i++ {
+		ek := &proto.ExtentKey{ExtentId: ids[i%len(ids)], Size: uint32(len(data))}
+		lockEks := &proto.GcLockExtents{Eks: []*proto.ExtentKey{ek}, Flag: proto.GcDedupRefFlag, DedupToken: fmt.Sprintf("dup%d", i)}
+		require.NoError(t, s.ExtentBatchLockNormalExtent(lockEks))
+	}
+	info, err := os.Stat(filepath.Join(path, storage.ExtRefFileName))
+	require.NoError(t, err)
+	require.True(t, info.Size() > 0)
+
+	s.Close()
+	s, err = storage.NewExtentStore(path, 0, 1*util.GB, proto.PartitionTypeNormal, false)
+	require.NoError(t, err)
+	defer s.Close()
+	for i, id := range ids {
+		expected := 1100 / len(ids)
+		if i < 1100%len(ids) {
+			expected++
+		}
+		require.EqualValues(t, expected, s.GetExtentRef(id))
+	}
+}
@@ -35,6 +35,14 @@ func NewTP(name string) (tp *TimePoint) {
 	return
 }
 
+// NewTPFrom returns a time point which starts at the given time, it is used when the
+// operation is observed after it is done.
+func NewTPFrom(name string, startTime time.Time) (tp *TimePoint) {
+	tp = NewTP(name)
+	tp.startTime = startTime
+	return
+}
+
 func (tp *TimePoint) Set() {
 	if !enabledPrometheus {
 		return
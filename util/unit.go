@@ -68,6 +68,11 @@ const (
 	UidAddLimit  = 1
 	UidDelLimit  = 2
 	UidGetLimit  = 3
+
+	// mapping of the S3 users to the uid and gid
+	UidUserList = 4
+	UidUserAdd  = 5
+	UidUserDel  = 6
 )
 
 const (